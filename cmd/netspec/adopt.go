@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/netspec/netspec/internal/collector"
+	"github.com/netspec/netspec/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// runAdopt implements `netspec adopt <device>`: it reads the device's current
+// interface state via gNMI Get and prints a desired-state stanza that treats
+// that state as intent
+func runAdopt(args []string) int {
+	fs := flag.NewFlagSet("adopt", flag.ExitOnError)
+	configPath := fs.String("config", "/config/desired-state.yaml", "Path to desired state configuration")
+	address := fs.String("address", "", "Device address (defaults to the address in config)")
+	port := fs.Int("port", 0, "gNMI port (defaults to global gnmi_port)")
+	output := fs.String("o", "", "Write the stanza to this file instead of stdout")
+	logLevel := fs.String("log-level", "warn", "Log level (debug, info, warn, error)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: netspec adopt [flags] <device>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	deviceName := fs.Arg(0)
+
+	level, err := zerolog.ParseLevel(*logLevel)
+	if err != nil {
+		level = zerolog.WarnLevel
+	}
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(level).With().
+		Timestamp().
+		Str("device", deviceName).
+		Logger()
+
+	// Config is optional when the address is given explicitly, which lets
+	// adopt baseline devices that are not yet in desired-state.yaml
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		if *address == "" {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		cfg = &config.Config{}
+		cfg.DesiredState.Global.GNMIPort = 9339
+	}
+
+	deviceCfg := cfg.DesiredState.Devices[deviceName]
+	if *address != "" {
+		deviceCfg.Address = *address
+	}
+	if deviceCfg.Address == "" {
+		fmt.Fprintf(os.Stderr, "Device %s not found in configuration; pass -address\n", deviceName)
+		return 1
+	}
+	gnmiPort := cfg.GNMIPortFor(deviceCfg)
+	if *port != 0 {
+		gnmiPort = *port
+	}
+
+	username, password := defaultCredentials()
+	username, password, err = cfg.DeviceCredentials(deviceName, username, password)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Credentials for %s: %v\n", deviceName, err)
+		return 1
+	}
+
+	col := collector.NewCollector(deviceCfg.Address, username, password, gnmiPort, logger)
+	col.SetTLSConfig(collectorTLS(cfg, deviceCfg))
+	defer col.Close()
+
+	observed, err := col.GetInterfaces()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read interface state from %s: %v\n", deviceName, err)
+		return 1
+	}
+	observed = cfg.FilterIgnored(deviceName, observed)
+
+	stanza, err := config.MarshalDeviceStanza(deviceName, config.ProposeDevice(deviceCfg.Address, deviceCfg.Description, observed))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to render desired state: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		os.Stdout.Write(stanza)
+		return 0
+	}
+	if err := os.WriteFile(*output, stanza, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write %s: %v\n", *output, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Wrote desired state for %d interfaces to %s\n", len(observed), *output)
+	return 0
+}
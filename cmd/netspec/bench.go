@@ -0,0 +1,249 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/netspec/netspec/internal/alerter"
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/netspec/netspec/internal/notifier"
+	"github.com/netspec/netspec/internal/types"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/rs/zerolog"
+)
+
+// benchUpdate is one pre-built notification for a device
+type benchUpdate struct {
+	device       string
+	notification *gnmi.Notification
+}
+
+// runBench implements `netspec bench`: it pumps synthetic oper-status
+// notifications through the evaluator and alert engine in-process and
+// reports throughput, allocation rate and per-update latency. Notifications
+// are never sent.
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	devices := fs.Int("devices", 10, "Number of synthetic devices")
+	interfaces := fs.Int("interfaces", 48, "Declared interfaces per device")
+	updates := fs.Int("updates", 500000, "Total notifications to process")
+	leaves := fs.Int("leaves", 0, "Extra non-status leaves per notification (counters, mtu, ...), as in a full state-container sample")
+	downRate := fs.Float64("down-rate", 0.01, "Fraction of notifications reporting an interface down; it reports up again on its next update")
+	workers := fs.Int("workers", 1, "Concurrent producers; devices are partitioned across them")
+	seed := fs.Int64("seed", 1, "Random seed for the update sequence")
+	cpuProfile := fs.String("cpuprofile", "", "Write a CPU profile of the run to this file")
+	memProfile := fs.String("memprofile", "", "Write an allocation profile of the run to this file")
+	logLevel := fs.String("log-level", "error", "Log level (debug, info, warn, error)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: netspec bench [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *devices < 1 || *interfaces < 1 || *updates < 1 || *workers < 1 {
+		fmt.Fprintln(os.Stderr, "devices, interfaces, updates and workers must be positive")
+		return 2
+	}
+	if *workers > *devices {
+		*workers = *devices
+	}
+
+	level, err := zerolog.ParseLevel(*logLevel)
+	if err != nil {
+		level = zerolog.ErrorLevel
+	}
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(level).With().Timestamp().Logger()
+
+	cfg := benchConfig(*devices, *interfaces)
+	eval := evaluator.NewEvaluator(cfg, logger)
+	engine := alerter.NewEngine(cfg, notifier.NewNotifier(logger), logger)
+	var notified int64
+	engine.SetNotifyFunc(func(types.Alert) {
+		atomic.AddInt64(&notified, 1)
+	})
+
+	sequences := benchSequences(*devices, *interfaces, *updates, *workers, *leaves, *downRate, *seed)
+	latencies := make([][]time.Duration, *workers)
+	for w := range sequences {
+		latencies[w] = make([]time.Duration, 0, len(sequences[w]))
+	}
+
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create CPU profile: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start CPU profile: %v\n", err)
+			return 1
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	if *memProfile != "" {
+		runtime.MemProfileRate = 1
+	}
+
+	var changes int64
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for w := range sequences {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for _, u := range sequences[w] {
+				t0 := time.Now()
+				for _, change := range eval.EvaluateNotification(u.device, u.notification) {
+					engine.ProcessStateChangeNow(change)
+					atomic.AddInt64(&changes, 1)
+				}
+				latencies[w] = append(latencies[w], time.Since(t0))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	if *memProfile != "" {
+		f, err := os.Create(*memProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create allocation profile: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write allocation profile: %v\n", err)
+			return 1
+		}
+	}
+
+	var all []time.Duration
+	for _, l := range latencies {
+		all = append(all, l...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	total := len(all)
+	mallocs := float64(after.Mallocs-before.Mallocs) / float64(total)
+	bytes := float64(after.TotalAlloc-before.TotalAlloc) / float64(total)
+
+	fmt.Printf("Benchmark: %d devices x %d interfaces, %d updates, %d worker(s), %.1f%% down\n",
+		*devices, *interfaces, total, *workers, *downRate*100)
+	fmt.Printf("  elapsed        %s\n", elapsed.Round(time.Millisecond))
+	fmt.Printf("  throughput     %.0f updates/sec\n", float64(total)/elapsed.Seconds())
+	fmt.Printf("  state changes  %d (alerts notified %d, active %d)\n", changes, notified, len(engine.GetActiveAlerts()))
+	fmt.Printf("  allocations    %.1f allocs/update, %.0f B/update\n", mallocs, bytes)
+	fmt.Printf("  latency        p50 %s  p99 %s  max %s\n",
+		percentile(all, 0.50), percentile(all, 0.99), all[total-1])
+	return 0
+}
+
+// benchConfig builds a desired state with every interface declared up
+func benchConfig(devices, interfaces int) *config.Config {
+	cfg := &config.Config{}
+	cfg.DesiredState.Devices = make(map[string]config.DeviceConfig, devices)
+	for d := 0; d < devices; d++ {
+		dev := config.DeviceConfig{
+			Address:    fmt.Sprintf("192.0.2.%d", d%254+1),
+			Interfaces: make(map[string]config.InterfaceConfig, interfaces),
+		}
+		for i := 0; i < interfaces; i++ {
+			dev.Interfaces[benchInterfaceName(i)] = config.InterfaceConfig{
+				DesiredState: "up",
+				AdminState:   "enabled",
+			}
+		}
+		cfg.DesiredState.Devices[benchDeviceName(d)] = dev
+	}
+	return cfg
+}
+
+// benchSequences pre-builds the notifications for each worker so generation
+// cost is excluded from the measurement. Interfaces report in round-robin
+// order, each report being DOWN with probability downRate.
+func benchSequences(devices, interfaces, updates, workers, leaves int, downRate float64, seed int64) [][]benchUpdate {
+	rng := rand.New(rand.NewSource(seed))
+
+	// Two shared notifications per interface, one per status
+	type ifaceNotifications struct {
+		up, down *gnmi.Notification
+	}
+	ifaces := make([][]ifaceNotifications, devices)
+	for d := range ifaces {
+		ifaces[d] = make([]ifaceNotifications, interfaces)
+		for i := range ifaces[d] {
+			ifaces[d][i] = ifaceNotifications{
+				up:   operStatusNotification(benchInterfaceName(i), "UP", leaves),
+				down: operStatusNotification(benchInterfaceName(i), "DOWN", leaves),
+			}
+		}
+	}
+
+	sequences := make([][]benchUpdate, workers)
+	for n := 0; n < updates; n++ {
+		slot := n % (devices * interfaces)
+		d, i := slot/interfaces, slot%interfaces
+		notification := ifaces[d][i].up
+		if rng.Float64() < downRate {
+			notification = ifaces[d][i].down
+		}
+		w := d % workers
+		sequences[w] = append(sequences[w], benchUpdate{device: benchDeviceName(d), notification: notification})
+	}
+	return sequences
+}
+
+// operStatusNotification builds an oper-status notification padded with
+// extra counter leaves the evaluator ignores
+func operStatusNotification(ifaceName, status string, leaves int) *gnmi.Notification {
+	leaf := func(name string, val *gnmi.TypedValue) *gnmi.Update {
+		return &gnmi.Update{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+				{Name: "interfaces"},
+				{Name: "interface", Key: map[string]string{"name": ifaceName}},
+				{Name: "state"},
+				{Name: name},
+			}},
+			Val: val,
+		}
+	}
+	n := &gnmi.Notification{}
+	for i := 0; i < leaves; i++ {
+		n.Update = append(n.Update, leaf(fmt.Sprintf("counter-%d", i),
+			&gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: uint64(i)}}))
+	}
+	n.Update = append(n.Update, leaf("oper-status",
+		&gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: status}}))
+	return n
+}
+
+func benchDeviceName(d int) string {
+	return fmt.Sprintf("bench-sw-%03d", d)
+}
+
+func benchInterfaceName(i int) string {
+	return fmt.Sprintf("GigabitEthernet1/0/%d", i+1)
+}
+
+// percentile returns the p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/netspec/netspec/internal/collector"
+	"github.com/netspec/netspec/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// checkStages lists the stages of collector.Check in order, so stages that
+// were never reached can be reported as skipped
+var checkStages = []string{"dial", "capabilities", "subscribe", "sync_response", "first update"}
+
+// runCheck implements `netspec check <device>`: it runs the daemon's
+// connection sequence once and prints a pass/fail report with timings
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	configPath := fs.String("config", "/config/desired-state.yaml", "Path to desired state configuration")
+	address := fs.String("address", "", "Device address (defaults to the address in config)")
+	port := fs.Int("port", 0, "gNMI port (defaults to global gnmi_port)")
+	timeout := fs.Duration("timeout", 30*time.Second, "Give up if the check has not completed after this long")
+	logLevel := fs.String("log-level", "warn", "Log level (debug, info, warn, error)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: netspec check [flags] <device>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	deviceName := fs.Arg(0)
+
+	level, err := zerolog.ParseLevel(*logLevel)
+	if err != nil {
+		level = zerolog.WarnLevel
+	}
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(level).With().
+		Timestamp().
+		Str("device", deviceName).
+		Logger()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		if *address == "" {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+			return 1
+		}
+		cfg = &config.Config{}
+		cfg.DesiredState.Global.GNMIPort = 9339
+	}
+
+	deviceCfg := cfg.DesiredState.Devices[deviceName]
+	if *address != "" {
+		deviceCfg.Address = *address
+	}
+	if deviceCfg.Address == "" {
+		fmt.Fprintf(os.Stderr, "Device %s not found in configuration; pass -address\n", deviceName)
+		return 1
+	}
+	gnmiPort := cfg.GNMIPortFor(deviceCfg)
+	if *port != 0 {
+		gnmiPort = *port
+	}
+
+	username, password := defaultCredentials()
+	username, password, err = cfg.DeviceCredentials(deviceName, username, password)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Credentials for %s: %v\n", deviceName, err)
+		return 1
+	}
+
+	col := collector.NewCollector(deviceCfg.Address, username, password, gnmiPort, logger)
+	col.SetTLSConfig(collectorTLS(cfg, deviceCfg))
+	defer col.Close()
+
+	fmt.Printf("Checking %s (%s:%d)\n", deviceName, deviceCfg.Address, gnmiPort)
+
+	start := time.Now()
+	steps := col.Check(*timeout)
+	elapsed := time.Since(start)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	failed := false
+	for _, step := range steps {
+		result, detail := "PASS", step.Detail
+		if step.Err != nil {
+			result, detail, failed = "FAIL", step.Err.Error(), true
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", result, step.Name, step.Duration.Round(time.Millisecond), detail)
+	}
+	for _, stage := range checkStages[len(steps):] {
+		fmt.Fprintf(tw, "  SKIP\t%s\t-\t\n", stage)
+	}
+	tw.Flush()
+	if cert := col.ServerCertificate(); cert != nil {
+		fmt.Printf("Certificate: %s, expires %s\n", cert.Subject, cert.NotAfter.UTC().Format("2006-01-02"))
+	}
+
+	if failed {
+		fmt.Printf("FAIL: %s after %s\n", deviceName, elapsed.Round(time.Millisecond))
+		return 1
+	}
+	fmt.Printf("PASS: %s streaming in %s\n", deviceName, elapsed.Round(time.Millisecond))
+	return 0
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"io"
 	"os"
@@ -12,19 +13,72 @@ import (
 
 	"github.com/netspec/netspec/internal/alerter"
 	"github.com/netspec/netspec/internal/api"
+	"github.com/netspec/netspec/internal/capabilities"
+	"github.com/netspec/netspec/internal/capacity"
+	"github.com/netspec/netspec/internal/changecal"
+	"github.com/netspec/netspec/internal/capture"
 	"github.com/netspec/netspec/internal/collector"
 	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/enforcer"
+	"github.com/netspec/netspec/internal/enrichment"
 	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/netspec/netspec/internal/eventbus"
+	"github.com/netspec/netspec/internal/exporter"
+	"github.com/netspec/netspec/internal/hotspot"
+	"github.com/netspec/netspec/internal/inventory"
+	"github.com/netspec/netspec/internal/mgmtcheck"
 	"github.com/netspec/netspec/internal/notifier"
+	"github.com/netspec/netspec/internal/prefs"
+	"github.com/netspec/netspec/internal/redact"
+	"github.com/netspec/netspec/internal/remediation"
+	"github.com/netspec/netspec/internal/store"
+	"github.com/netspec/netspec/internal/types"
 	"github.com/netspec/netspec/internal/version"
 	"github.com/netspec/netspec/internal/webui"
 	"github.com/rs/zerolog"
 	"sync"
 )
 
+// scheduleCheckInterval is how often collectors are suspended or resumed as
+// device monitoring windows close and open
+const scheduleCheckInterval = 30 * time.Second
+
+// certCheckInterval is how often the certificate a TLS device presented is
+// checked for approaching expiry
+const certCheckInterval = time.Hour
+
+// skewCheckInterval is how often a device's gNMI stream skew is checked
+// against the stream_skew threshold
+const skewCheckInterval = time.Minute
+
+// sessionCheckInterval is how often the most gNMI sessions held to a device
+// is checked against the session limit of its profile
+const sessionCheckInterval = 10 * time.Second
+
+// reachabilityCheckInterval is how often every device is checked for being
+// unreachable, for connection_failure and devices_reconnecting
+const reachabilityCheckInterval = 10 * time.Second
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "adopt":
+			os.Exit(runAdopt(os.Args[2:]))
+		case "replay":
+			os.Exit(runReplay(os.Args[2:]))
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		case "simulate":
+			os.Exit(runSimulate(os.Args[2:]))
+		}
+	}
+
 	configPath := flag.String("config", "/config/desired-state.yaml", "Path to desired state configuration")
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	watchConfig := flag.Bool("watch-config", false, "Reload when YAML files in the config directory change")
+	watchDebounce := flag.Duration("watch-debounce", 5*time.Second, "How long config files must be left alone before a watched change is reloaded")
 	flag.Parse()
 
 	// Create log buffer for web UI (captures last 1000 log entries)
@@ -38,8 +92,8 @@ func main() {
 	}
 	zerolog.SetGlobalLevel(logLevelParsed)
 
-	// Write to both stdout and the log buffer
-	multiWriter := io.MultiWriter(os.Stdout, logBuffer)
+	// Write to both stdout and the log buffer, with secrets masked
+	multiWriter := redact.Default.Writer(io.MultiWriter(os.Stdout, logBuffer))
 	logger := zerolog.New(multiWriter).With().
 		Timestamp().
 		Str("version", version.GetVersion()).
@@ -59,37 +113,214 @@ func main() {
 			Str("config_path", *configPath).
 			Msg("Failed to load configuration")
 	}
+	redact.Register(cfg.Secrets()...)
 
 	logger.Info().
 		Int("device_count", len(cfg.DesiredState.Devices)).
 		Msg("Configuration loaded")
+	for _, warning := range cfg.Warnings() {
+		logger.Warn().Str("warning", warning).Msg("Configuration warning")
+	}
 
 	// Create notifier
 	notifier := notifier.NewNotifier(logger)
+	notifier.SetConfig(cfg.Alerts)
+	webui.SetOffline(cfg.DesiredState.Global.Offline)
 
 	// Create alert engine
 	alertEngine := alerter.NewEngine(cfg, notifier, logger)
+	// Tickets opened by jira and servicenow channels are shown on their alerts
+	notifier.SetTicketHook(alertEngine.AddTicket)
 
 	// Start alert engine
 	go alertEngine.Run()
 
 	// Create evaluator
 	eval := evaluator.NewEvaluator(cfg, logger)
+	notifier.SetSnapshotFunc(interfaceSnapshots(eval))
 
 	// Create collectors for each device
 	collectors := make(map[string]*collector.Collector)
 	collectorsMu := sync.RWMutex{}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	go alertEngine.RunMaintenance(ctx)
+	go notifier.RunQuietHours(ctx)
 
-	// Get credentials (simplified for MVP - in production, use vault integration)
-	username := os.Getenv("GNMI_USERNAME")
-	if username == "" {
-		username = "gnmi-monitor"
+	// Capacity trending of uplinks from the evaluator's measured bit rates
+	capacityTracker := capacity.NewTracker(cfg, logger)
+	eval.AddRateHook(capacityTracker.Record)
+
+	// Hardware inventory read periodically from every device, attached to
+	// alerts so tickets carry chassis and optic serials
+	inventoryStore := inventory.NewStore()
+	alertEngine.SetAssetFunc(inventoryStore.Asset)
+
+	// gNMI capabilities re-read from every device, a few at a time, to
+	// catch an upgrade changing the models a device can stream
+	capabilityRefresher := capabilities.New(cfg, logger, func(device string) (capabilities.Snapshot, error) {
+		collectorsMu.RLock()
+		col := collectors[device]
+		collectorsMu.RUnlock()
+		if col == nil {
+			return capabilities.Snapshot{}, errors.New("collector not running")
+		}
+		snap, err := col.GetCapabilities()
+		if err != nil {
+			return snap, err
+		}
+		if inv, ok := inventoryStore.Device(device); ok {
+			snap.SoftwareVersion = inv.SoftwareVersion
+		}
+		return snap, nil
+	})
+
+	// Annotations from an external CMDB/IPAM lookup, when configured
+	enricher := enrichment.New(cfg, logger)
+	alertEngine.SetEnrichFunc(enricher.Lookup)
+
+	// Maintenance windows from the external change calendar, when configured
+	changeCalendar := changecal.New(cfg, logger, alertEngine.SetChangeWindows)
+	go changeCalendar.Run(ctx)
+
+	// Per-interface activity for the dashboard's top-N hotspots
+	hotspots := hotspot.NewTracker()
+	hotspots.Register(eval)
+
+	// Web UI preferences, kept per user
+	prefsStore := prefs.NewStore()
+
+	// Optional persistence of dedup state across restarts
+	if persistence := cfg.Alerts.AlertBehavior.StatePersistence; persistence.Enabled && persistence.Path != "" {
+		st, err := store.Open(persistence.Path)
+		if err == nil {
+			err = alertEngine.SetStore(st)
+		}
+		if err != nil {
+			logger.Error().Err(err).Str("path", persistence.Path).Msg("Failed to restore alert state, dedup state will not persist")
+		} else {
+			go alertEngine.RunPersistence(ctx)
+			if err := capacityTracker.SetStore(st); err != nil {
+				logger.Error().Err(err).Str("path", persistence.Path).Msg("Failed to restore capacity history")
+			}
+			if err := capabilityRefresher.SetStore(st); err != nil {
+				logger.Error().Err(err).Str("path", persistence.Path).Msg("Failed to restore capabilities history")
+			}
+			if err := prefsStore.SetStore(st); err != nil {
+				logger.Error().Err(err).Str("path", persistence.Path).Msg("Failed to restore user preferences")
+			}
+			// Restored alerts resolve once the evaluator sees their
+			// condition clear
+			for _, alert := range alertEngine.GetActiveAlerts() {
+				eval.RestoreFiring(alert.Device, alert.Entity, alert.AlertType)
+			}
+		}
+
+		retention := persistence.HistoryRetention
+		if retention == 0 {
+			retention = alerter.DefaultHistoryRetention
+		}
+		if hs, err := alerter.OpenFileHistoryStore(persistence.HistoryFile(), retention); err != nil {
+			logger.Error().Err(err).Str("path", persistence.HistoryFile()).Msg("Failed to open alert history, history will not persist")
+		} else {
+			alertEngine.SetHistoryStore(hs)
+			defer hs.Close()
+		}
+	}
+
+	// Optional line-protocol export of transitions and compliance
+	if influxCfg := cfg.DesiredState.Global.Exporters.Influx; influxCfg != nil {
+		influx := exporter.NewInfluxExporter(*influxCfg, eval, logger)
+		eval.AddTransitionHook(influx.RecordTransition)
+		go influx.Run(ctx)
+	}
+
+	// Optional MQTT publishing of retained per-interface compliance
+	if mqttCfg := cfg.DesiredState.Global.Exporters.MQTT; mqttCfg != nil {
+		mqttPub, err := exporter.NewMQTTPublisher(*mqttCfg, os.Getenv(mqttCfg.PasswordEnv), eval, logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid MQTT configuration")
+		}
+		eval.AddTransitionHook(mqttPub.RecordTransition)
+		go mqttPub.Run(ctx)
+	}
+
+	// Optional relay of raw notifications to an upstream collector
+	var relay *exporter.Relay
+	if relayCfg := cfg.DesiredState.Global.Exporters.Relay; relayCfg != nil {
+		relay = exporter.NewRelay(*relayCfg, logger)
+		go relay.Run(ctx)
 	}
-	password := os.Getenv("GNMI_PASSWORD")
-	if password == "" {
-		logger.Fatal().Msg("GNMI_PASSWORD environment variable is required")
+
+	// Optional event bus publishing of state changes and alert lifecycle
+	var bus *eventbus.Bus
+	if eventsCfg := cfg.DesiredState.Global.Exporters.Events; eventsCfg != nil {
+		var publishers []eventbus.Publisher
+		if eventsCfg.NATS != nil {
+			natsPub, err := eventbus.NewNATSPublisher(eventsCfg.NATS.URL, eventsCfg.NATS.Subject, os.Getenv(eventsCfg.NATS.TokenEnv))
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Invalid NATS event bus configuration")
+			}
+			publishers = append(publishers, natsPub)
+		}
+		if eventsCfg.Kafka != nil {
+			publishers = append(publishers, eventbus.NewKafkaRESTPublisher(eventsCfg.Kafka.RESTURL, eventsCfg.Kafka.Topic))
+		}
+		bus = eventbus.NewBus(logger, publishers...)
+	}
+
+	// Automatic remediation of firing alerts, when enabled in alerts.yaml
+	remediator := remediation.New(cfg.Alerts.Remediation, logger)
+	remediator.SetResultHook(func(attempt remediation.Attempt) {
+		alertEngine.RecordEvent(attempt.AlertID, "remediation", attempt.Action+" "+attempt.Result+": "+attempt.Detail)
+	})
+
+	// Closed-loop restoration of declared admin states, when enabled
+	enforce := enforcer.New(cfg, logger)
+	enforce.SetActionHook(func(action enforcer.Action) {
+		alertEngine.RecordEvent(action.AlertID, "enforced", action.Result+": "+action.Detail)
+	})
+	alertEngine.SetLifecycleHook(func(event string, alert types.Alert) {
+		if bus != nil {
+			bus.Emit(eventbus.AlertEvent("alert_"+event, alert))
+		}
+		remediator.Handle(event, alert)
+		enforce.Handle(event, alert)
+	})
+
+	// Re-evaluate time-of-day desired states as their windows change
+	go eval.RunSchedules(ctx, func(change evaluator.StateChange) {
+		if bus != nil {
+			bus.Emit(eventbus.StateChangeEvent(change))
+		}
+		alertEngine.ProcessStateChange(change)
+	})
+
+	// Evict stale cache entries, resolving the alerts firing on them
+	go eval.RunEviction(ctx, func(change evaluator.StateChange) {
+		if bus != nil {
+			bus.Emit(eventbus.StateChangeEvent(change))
+		}
+		alertEngine.ProcessStateChange(change)
+	})
+
+	// Raise and clear capacity advisories as uplink trends are recomputed
+	go capacityTracker.Run(ctx, func(change evaluator.StateChange) {
+		if bus != nil {
+			bus.Emit(eventbus.StateChangeEvent(change))
+		}
+		alertEngine.ProcessStateChange(change)
+	}, func(device, iface string) {
+		alertEngine.ProcessResolution(device, iface, capacity.AlertType, "Capacity no longer projected to exceed threshold on interface "+iface)
+	})
+
+	// Get credentials (simplified for MVP - in production, use vault integration).
+	// Every device must resolve to a username and password up front, so a
+	// missing secret names its devices instead of failing them at login.
+	username, password := defaultCredentials()
+	redact.Register(password)
+	if err := cfg.ValidateCredentials(username, password); err != nil {
+		logger.Fatal().Err(err).Msg("Credential check failed")
 	}
 
 	// Helper function to start a collector (defined before first use).
@@ -108,32 +339,42 @@ func main() {
 		logger.Info().
 			Str("device", deviceName).
 			Str("address", deviceCfg.Address).
-			Int("port", cfg.DesiredState.Global.GNMIPort).
+			Int("port", cfg.GNMIPortFor(deviceCfg)).
 			Msg("Creating collector")
 
-		cred := cfg.ResolveCredentials(deviceName)
-		credUsername := cred.Username
-		credPassword := ""
-		if cred.PasswordEnv != "" {
-			credPassword = os.Getenv(cred.PasswordEnv)
-		}
-		if credUsername == "" {
-			credUsername = username
-		}
-		if credPassword == "" {
-			credPassword = password
+		credUsername, credPassword, err := cfg.DeviceCredentials(deviceName, username, password)
+		if err != nil {
+			logger.Error().Err(err).Str("device", deviceName).Msg("Missing gNMI credentials, collector not started")
+			delete(collectors, deviceName)
+			return
 		}
 
 		col := collector.NewCollector(
 			deviceCfg.Address,
 			credUsername,
 			credPassword,
-			cfg.DesiredState.Global.GNMIPort,
+			cfg.GNMIPortFor(deviceCfg),
 			logger.With().Str("device", deviceName).Logger(),
 		)
+		updateBuffer := cfg.DesiredState.Global.UpdateBuffer
+		col.SetUpdateBuffer(updateBuffer.Size, updateBuffer.Overflow == config.OverflowDropOldest)
+		col.SetPrechecks(cfg.DesiredState.Global.ConnectPrechecks)
+		col.SetTrunkVLANSubscription(deviceCfg.AssertsTrunkVLANs())
+		if deviceCfg.Polled() {
+			col.SetPolling(cfg.DesiredState.Global.CollectionInterval)
+		}
+		col.SetTLSConfig(collectorTLS(cfg, deviceCfg))
 
 		collectors[deviceName] = col
 
+		// A device expected offline is still dialled so that coming online
+		// is noticed. Clear any alert left over from before a reload that
+		// changed the device back to online.
+		expectOffline := deviceCfg.ExpectedOffline()
+		if !expectOffline {
+			alertEngine.ReportUnexpectedOnline(deviceName, false)
+		}
+
 		// Connection goroutine: connect with retry and auto-reconnect.
 		// Exits when either the main ctx or the collector's own ctx is
 		// cancelled (the latter happens on Close() during reload).
@@ -158,11 +399,18 @@ func main() {
 						}
 					}
 
-					logger.Error().
-						Err(err).
-						Str("device", name).
-						Dur("retry_in", reconnectDelay).
-						Msg("Failed to connect, will retry")
+					if expectOffline {
+						logger.Debug().
+							Err(err).
+							Str("device", name).
+							Msg("Device expected offline is unreachable, as expected")
+					} else {
+						logger.Error().
+							Err(err).
+							Str("device", name).
+							Dur("retry_in", reconnectDelay).
+							Msg("Failed to connect, will retry")
+					}
 
 					select {
 					case <-ctx.Done():
@@ -186,6 +434,12 @@ func main() {
 				logger.Info().
 					Str("device", name).
 					Msg("Connection established, monitoring for errors")
+				if expectOffline {
+					logger.Warn().
+						Str("device", name).
+						Msg("Device expected offline is online")
+					alertEngine.ReportUnexpectedOnline(name, true)
+				}
 
 				// Monitor connection health and reconnect if lost
 				select {
@@ -204,26 +458,59 @@ func main() {
 						default:
 						}
 
-						logger.Warn().
-							Err(err).
-							Str("device", name).
-							Msg("Connection lost, will reconnect after cooldown")
+						// A device refusing the subscription for lack of
+						// permission or capability is retried rarely
+						cooldown := 5 * time.Second
+						var subErr *collector.SubscribeError
+						if errors.As(err, &subErr) && subErr.Permanent() {
+							cooldown = collector.SubscribeRetryInterval
+							logger.Error().
+								Err(err).
+								Str("device", name).
+								Str("class", subErr.Class).
+								Str("hint", subErr.Hint()).
+								Dur("retry_in", cooldown).
+								Msg("Device refused the gNMI subscription")
+						} else {
+							logger.Warn().
+								Err(err).
+								Str("device", name).
+								Msg("Connection lost, will reconnect after cooldown")
+						}
+						if expectOffline {
+							alertEngine.ReportUnexpectedOnline(name, false)
+						}
 
 						select {
 						case <-ctx.Done():
 							return
 						case <-c.Done():
 							return
-						case <-time.After(5 * time.Second):
+						case <-time.After(cooldown):
 						}
 					}
 				}
 			}
 		}(deviceName, deviceCfg.Address, col)
 
+		// Optional capture of received notifications for offline replay
+		var captureWriter *capture.Writer
+		if deviceCfg.CaptureFile != "" {
+			w, err := capture.OpenWriter(deviceCfg.CaptureFile)
+			if err != nil {
+				logger.Error().Err(err).Str("device", deviceName).Str("file", deviceCfg.CaptureFile).Msg("Failed to open capture file")
+			} else {
+				logger.Info().Str("device", deviceName).Str("file", deviceCfg.CaptureFile).Msg("Capturing notifications")
+				captureWriter = w
+			}
+		}
+
 		// Update-processing goroutine: evaluates telemetry against desired
 		// state and feeds changes into the alert engine.
-		go func(name string, c *collector.Collector) {
+		go func(name string, c *collector.Collector, cw *capture.Writer) {
+			if cw != nil {
+				defer cw.Close()
+			}
 			for {
 				select {
 				case <-ctx.Done():
@@ -231,13 +518,140 @@ func main() {
 				case <-c.Done():
 					return
 				case notification := <-c.Updates():
+					if cw != nil {
+						if err := cw.Write(name, notification, time.Now()); err != nil {
+							logger.Warn().Err(err).Str("device", name).Msg("Failed to write capture record")
+						}
+					}
 					changes := eval.EvaluateNotification(name, notification)
 					for _, change := range changes {
+						if bus != nil {
+							bus.Emit(eventbus.StateChangeEvent(change))
+						}
 						alertEngine.ProcessStateChange(change)
 					}
+					if relay != nil {
+						relay.Forward(name, notification)
+					}
+				}
+			}
+		}(deviceName, col, captureWriter)
+
+		// Configuration audit goroutines: periodically read the device's
+		// ACL attachments and NAC state and check them against the
+		// interfaces' required_acls and nac declarations
+		reportAudit := func(name, alertType string, changes []evaluator.StateChange, cleared []string, recovered string) {
+			for _, change := range changes {
+				if bus != nil {
+					bus.Emit(eventbus.StateChangeEvent(change))
+				}
+				alertEngine.ProcessStateChange(change)
+			}
+			for _, iface := range cleared {
+				alertEngine.ProcessResolution(name, iface, alertType, recovered+" on interface "+iface)
+			}
+		}
+		if deviceCfg.RequiresACLs() && !expectOffline {
+			go runAudit(ctx, col, cfg.DesiredState.Global.ACLCheckInterval, func() {
+				bindings, err := col.GetACLBindings()
+				if err != nil {
+					logger.Warn().Err(err).Str("device", deviceName).Msg("Failed to read ACL attachments")
+					return
+				}
+				changes, cleared := eval.EvaluateACLs(deviceName, bindings)
+				reportAudit(deviceName, evaluator.AlertTypeACLMissing, changes, cleared, "Required ACLs are attached")
+			})
+		}
+		if !expectOffline {
+			go runAudit(ctx, col, cfg.DesiredState.Global.InventoryInterval, func() {
+				snap, err := col.GetInventory()
+				if err != nil {
+					logger.Warn().Err(err).Str("device", deviceName).Msg("Failed to read inventory")
+					return
+				}
+				snap.CollectedAt = time.Now()
+				inventoryStore.Set(deviceName, snap)
+			})
+		}
+		if tlsCfg := cfg.TLSFor(deviceCfg); tlsCfg != nil && tlsCfg.Enabled && !expectOffline {
+			go runAudit(ctx, col, certCheckInterval, func() {
+				changes, cleared := eval.EvaluateCertificate(deviceName, col.ServerCertificate())
+				for _, change := range changes {
+					if bus != nil {
+						bus.Emit(eventbus.StateChangeEvent(change))
+					}
+					alertEngine.ProcessStateChange(change)
+				}
+				if cleared {
+					alertEngine.ProcessResolution(deviceName, evaluator.CertEntity, evaluator.AlertTypeCertExpiring, "gNMI TLS certificate renewed")
+				}
+			})
+		}
+		if !expectOffline {
+			go runAudit(ctx, col, skewCheckInterval, func() {
+				health := col.Health()
+				changes, cleared := eval.EvaluateStreamSkew(deviceName, health.StreamSkew, health.StreamSkewMeasured)
+				for _, change := range changes {
+					if bus != nil {
+						bus.Emit(eventbus.StateChangeEvent(change))
+					}
+					alertEngine.ProcessStateChange(change)
+				}
+				if cleared {
+					alertEngine.ProcessResolution(deviceName, evaluator.StreamEntity, evaluator.AlertTypeStreamSkew, "gNMI stream skew back within threshold")
+				}
+			})
+			go runAudit(ctx, col, sessionCheckInterval, func() {
+				changes, cleared := eval.EvaluateSessions(deviceName, col.TakeSessionPeak())
+				for _, change := range changes {
+					if bus != nil {
+						bus.Emit(eventbus.StateChangeEvent(change))
+					}
+					alertEngine.ProcessStateChange(change)
+				}
+				if cleared {
+					alertEngine.ProcessResolution(deviceName, evaluator.SessionsEntity, evaluator.AlertTypeSessionLimit, "gNMI sessions back below the platform limit")
+				}
+			})
+		}
+		if licCfg := cfg.LicensesFor(deviceCfg); licCfg != nil && !expectOffline {
+			_, interval := licCfg.Limits()
+			go runAudit(ctx, col, interval, func() {
+				licenses, err := col.GetLicenses()
+				if err != nil {
+					logger.Warn().Err(err).Str("device", deviceName).Msg("Failed to read licenses")
+					return
+				}
+				changes, cleared := eval.EvaluateLicenses(deviceName, licenses)
+				for _, change := range changes {
+					if bus != nil {
+						bus.Emit(eventbus.StateChangeEvent(change))
+					}
+					alertEngine.ProcessStateChange(change)
+				}
+				for _, entity := range cleared {
+					alertEngine.ProcessResolution(deviceName, entity, evaluator.AlertTypeLicenseExpiring, "License renewed or no longer in use")
+				}
+			})
+		}
+		if deviceCfg.RequiresNAC() && !expectOffline {
+			var dot1x, portSecurity bool
+			for _, ifCfg := range deviceCfg.Interfaces {
+				if ifCfg.NAC != nil {
+					dot1x = dot1x || ifCfg.NAC.Dot1X
+					portSecurity = portSecurity || ifCfg.NAC.PortSecurity
 				}
 			}
-		}(deviceName, col)
+			go runAudit(ctx, col, cfg.DesiredState.Global.NACCheckInterval, func() {
+				states, err := col.GetNACState(dot1x, portSecurity)
+				if err != nil {
+					logger.Warn().Err(err).Str("device", deviceName).Msg("Failed to read NAC state")
+					return
+				}
+				changes, cleared := eval.EvaluateNAC(deviceName, states)
+				reportAudit(deviceName, evaluator.AlertTypeNACDisabled, changes, cleared, "NAC enforcement restored")
+			})
+		}
 	}
 
 	// Start collectors
@@ -246,9 +660,100 @@ func main() {
 		Msg("Starting collectors for devices")
 	
 	for deviceName, deviceCfg := range cfg.DesiredState.Devices {
+		if deviceCfg.Suspended(time.Now()) {
+			logger.Info().Str("device", deviceName).Msg("Outside monitoring window, collector suspended")
+			continue
+		}
 		startCollector(deviceName, deviceCfg, cfg, username, password)
 	}
 
+	// Re-read capabilities once the collectors exist, raising and clearing
+	// gnmi_capabilities_changed
+	go capabilityRefresher.Run(ctx, func(change evaluator.StateChange) {
+		if bus != nil {
+			bus.Emit(eventbus.StateChangeEvent(change))
+		}
+		alertEngine.ProcessStateChange(change)
+	}, func(device string) {
+		alertEngine.ProcessResolution(device, capabilities.Entity, capabilities.AlertType, "gNMI capabilities unchanged since the last refresh")
+	})
+
+	// Suspend and resume the collectors of devices whose monitoring schedule
+	// says outside: suspend as their windows close and open. liveCfg tracks
+	// reloads and is guarded by collectorsMu.
+	liveCfg := cfg
+	go func() {
+		ticker := time.NewTicker(scheduleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			collectorsMu.RLock()
+			current := liveCfg
+			collectorsMu.RUnlock()
+
+			now := time.Now()
+			for name, dev := range current.DesiredState.Devices {
+				if dev.Monitoring == nil || dev.Monitoring.Outside != config.OutsideSuspend {
+					continue
+				}
+				collectorsMu.Lock()
+				col := collectors[name]
+				suspend := dev.Suspended(now) && col != nil
+				if suspend {
+					col.Close()
+					delete(collectors, name)
+				}
+				collectorsMu.Unlock()
+
+				switch {
+				case suspend:
+					logger.Info().Str("device", name).Msg("Monitoring window closed, collector suspended")
+				case !dev.Suspended(now) && col == nil:
+					logger.Info().Str("device", name).Msg("Monitoring window opened, resuming collector")
+					startCollector(name, dev, current, username, password)
+				}
+			}
+		}
+	}()
+
+	// Alert on unreachable devices fleet-wide, so that devices going down
+	// together collapse into one alert
+	go func() {
+		ticker := time.NewTicker(reachabilityCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			down := make(map[string]bool)
+			collectorsMu.RLock()
+			for name, col := range collectors {
+				down[name] = col.Health().Down()
+			}
+			collectorsMu.RUnlock()
+
+			changes, cleared, groupCleared := eval.EvaluateReachability(down)
+			for _, change := range changes {
+				if bus != nil {
+					bus.Emit(eventbus.StateChangeEvent(change))
+				}
+				alertEngine.ProcessStateChange(change)
+			}
+			for _, device := range cleared {
+				alertEngine.ProcessResolution(device, evaluator.ConnectionEntity, evaluator.AlertTypeConnectionFailure, "gNMI connection restored")
+			}
+			if groupCleared {
+				alertEngine.ProcessResolution(evaluator.FleetDevice, evaluator.ConnectionEntity, evaluator.AlertTypeDevicesReconnecting, "Reconnecting devices have returned or are alerted individually")
+			}
+		}
+	}()
+
 	// Start API server with Web UI
 	apiPort := os.Getenv("API_PORT")
 	if apiPort == "" {
@@ -260,27 +765,99 @@ func main() {
 	apiServer.SetLogBuffer(logBuffer)
 	apiServer.SetConfig(cfg, *configPath)
 	apiServer.SetVersion(version.GetVersion(), version.GetCommit(), version.GetBuildDate())
+	apiServer.SetEvaluator(eval)
+
+	mgmtChecker := mgmtcheck.NewChecker(logger)
+	mgmtChecker.SetDevices(cfg.DesiredState.Devices)
+	go mgmtChecker.Run(ctx)
+	apiServer.SetMgmtChecker(mgmtChecker)
+	apiServer.SetCapacityTracker(capacityTracker)
+	apiServer.SetHotspotTracker(hotspots)
+	apiServer.SetInventory(inventoryStore)
+	apiServer.SetCapabilities(capabilityRefresher)
+	apiServer.SetPreferences(prefsStore)
 	apiServer.SetCollectorGetter(func(deviceName string) *collector.Collector {
 		collectorsMu.RLock()
 		defer collectorsMu.RUnlock()
 		return collectors[deviceName]
 	})
+	apiServer.SetDefaultCredentials(username, password)
+	remediator.SetPortBouncer(func(deviceName string) remediation.PortBouncer {
+		collectorsMu.RLock()
+		defer collectorsMu.RUnlock()
+		if col := collectors[deviceName]; col != nil {
+			return col
+		}
+		return nil
+	})
+	enforce.SetSetter(func(deviceName string) enforcer.AdminSetter {
+		collectorsMu.RLock()
+		defer collectorsMu.RUnlock()
+		if col := collectors[deviceName]; col != nil {
+			return col
+		}
+		return nil
+	})
+	apiServer.SetRemediator(remediator)
+	apiServer.SetEnforcer(enforce)
+	apiServer.SetChangeCalendar(changeCalendar)
+	alertEngine.SetDeviceDownFunc(func(deviceName string) bool {
+		collectorsMu.RLock()
+		col := collectors[deviceName]
+		collectorsMu.RUnlock()
+		return col != nil && col.Health().Down()
+	})
+	// A disconnected device's cached state is stale, not gone, so it is
+	// kept past the TTL until the device reconnects
+	eval.SetDeviceDownFunc(func(deviceName string) bool {
+		collectorsMu.RLock()
+		col := collectors[deviceName]
+		collectorsMu.RUnlock()
+		return col != nil && !col.Health().Connected
+	})
+
+	// With -watch-config, YAML changes under the config directory reload
+	var watcher *config.Watcher
+	if *watchConfig {
+		watcher = config.NewWatcher(configDir, *watchDebounce)
+	}
 
 	// Set up config reload function
 	apiServer.SetReloadFunc(func() (*config.Config, error) {
 		logger.Info().Str("config_dir", configDir).Msg("Reloading configuration")
+		files := watcher.Scan()
 		newCfg, err := config.LoadConfigDir(configDir)
 		if err != nil {
 			return nil, err
 		}
+		redact.Register(newCfg.Secrets()...)
+		if err := newCfg.ValidateCredentials(username, password); err != nil {
+			return nil, err
+		}
+		for _, warning := range newCfg.Warnings() {
+			logger.Warn().Str("warning", warning).Msg("Configuration warning")
+		}
 		
-		// Note: We can't easily update evaluator and alert engine without
-		// more complex state management. For now, collectors are restarted
-		// which is the main issue (IP address changes).
-		go alertEngine.Run()
-		
+		// The evaluator keeps its state cache and the alert engine its
+		// active alerts; both evaluate against the new configuration
+		eval.SetConfig(newCfg)
+		notifier.SetConfig(newCfg.Alerts)
+		webui.SetOffline(newCfg.DesiredState.Global.Offline)
+		mgmtChecker.SetDevices(newCfg.DesiredState.Devices)
+		capacityTracker.SetConfig(newCfg)
+		remediator.SetConfig(newCfg.Alerts.Remediation)
+		enforce.SetConfig(newCfg)
+		enricher.SetConfig(newCfg)
+		changeCalendar.SetConfig(newCfg)
+		capabilityRefresher.SetConfig(newCfg)
+		alertEngine.SetConfig(newCfg)
+		inventoryStore.Retain(func(device string) bool {
+			_, ok := newCfg.DesiredState.Devices[device]
+			return ok
+		})
 		// Stop collectors for removed devices
 		collectorsMu.Lock()
+		liveCfg = newCfg
 		for name, col := range collectors {
 			if _, exists := newCfg.DesiredState.Devices[name]; !exists {
 				logger.Info().Str("device", name).Msg("Device removed from config, stopping collector")
@@ -294,6 +871,16 @@ func main() {
 		
 		// Start/restart collectors for all devices (handles new devices and IP changes)
 		for deviceName, deviceCfg := range newCfg.DesiredState.Devices {
+			if deviceCfg.Suspended(time.Now()) {
+				collectorsMu.Lock()
+				if existing := collectors[deviceName]; existing != nil {
+					existing.Close()
+				}
+				delete(collectors, deviceName)
+				collectorsMu.Unlock()
+				continue
+			}
+
 			collectorsMu.RLock()
 			existing := collectors[deviceName]
 			collectorsMu.RUnlock()
@@ -316,6 +903,7 @@ func main() {
 		logger.Info().
 			Int("device_count", len(newCfg.DesiredState.Devices)).
 			Msg("Configuration reloaded and collectors updated")
+		watcher.Loaded(files)
 		
 		return newCfg, nil
 	})
@@ -332,6 +920,36 @@ func main() {
 		Str("port", apiPort).
 		Msg("Web UI available")
 
+	// SIGHUP and, with -watch-config, config file changes reload through
+	// the same path as POST /api/reload
+	reloadOn := func(trigger string) {
+		logger.Info().Str("trigger", trigger).Msg("Config reload requested")
+		newCfg, err := apiServer.Reload()
+		if err != nil {
+			logger.Error().Err(err).Str("trigger", trigger).Msg("Config reload failed")
+			return
+		}
+		logger.Info().
+			Int("device_count", len(newCfg.DesiredState.Devices)).
+			Msg("Config reloaded successfully")
+	}
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			reloadOn("SIGHUP")
+		}
+	}()
+	if watcher != nil {
+		logger.Info().
+			Str("config_dir", configDir).
+			Dur("debounce", *watchDebounce).
+			Msg("Watching config directory for changes")
+		go watcher.Run(ctx, func() {
+			reloadOn("config file change")
+		})
+	}
+
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -352,6 +970,71 @@ func main() {
 		}
 	}
 
+	if err := alertEngine.SaveState(); err != nil {
+		logger.Error().Err(err).Msg("Failed to persist alert state")
+	}
+
 	cancel()
 	logger.Info().Msg("NetSpec stopped")
 }
+
+// runAudit runs a periodic configuration audit of a device now and then
+// every interval, until ctx is cancelled or the collector is closed
+func runAudit(ctx context.Context, c *collector.Collector, interval time.Duration, audit func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		audit()
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// interfaceSnapshots adapts the evaluator's cached state to the notifier's
+// snapshot attachments
+func interfaceSnapshots(eval *evaluator.Evaluator) notifier.SnapshotFunc {
+	return func(device string) []notifier.InterfaceSnapshot {
+		states := eval.GetDeviceState(device)
+		snapshot := make([]notifier.InterfaceSnapshot, 0, len(states))
+		for _, state := range states {
+			snapshot = append(snapshot, notifier.InterfaceSnapshot{
+				Name:        state.Interface,
+				OperStatus:  state.OperStatus,
+				AdminStatus: state.AdminStatus,
+			})
+		}
+		return snapshot
+	}
+}
+
+// collectorTLS converts a device's gNMI TLS settings for its collector,
+// returning nil for plaintext
+func collectorTLS(cfg *config.Config, deviceCfg config.DeviceConfig) *collector.TLSConfig {
+	tlsCfg := cfg.TLSFor(deviceCfg)
+	if tlsCfg == nil {
+		return nil
+	}
+	return &collector.TLSConfig{
+		Enabled:            tlsCfg.Enabled,
+		InsecureSkipVerify: tlsCfg.InsecureSkipVerify,
+		ServerName:         tlsCfg.ServerName,
+		CAFile:             tlsCfg.CAFile,
+		CertFile:           tlsCfg.CertFile,
+		KeyFile:            tlsCfg.KeyFile,
+	}
+}
+
+// defaultCredentials returns the global gNMI username and password from the
+// environment
+func defaultCredentials() (string, string) {
+	username := os.Getenv("GNMI_USERNAME")
+	if username == "" {
+		username = "gnmi-monitor"
+	}
+	return username, os.Getenv("GNMI_PASSWORD")
+}
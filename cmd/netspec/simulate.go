@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/simulator"
+	"github.com/rs/zerolog"
+)
+
+// runSimulate implements `netspec simulate`: it serves a fake gNMI target
+// that plays a scripted interface state scenario, for demos and end-to-end
+// testing without hardware
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	listen := fs.String("listen", ":9339", "Address to serve gNMI on")
+	scenarioPath := fs.String("scenario", "", "Scenario file (defaults to a demo scenario for the device's declared interfaces)")
+	configPath := fs.String("config", "/config/desired-state.yaml", "Path to desired state configuration, used when no scenario is given")
+	logLevel := fs.String("log-level", "info", "Log level (debug, info, warn, error)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: netspec simulate [flags] -scenario <file>")
+		fmt.Fprintln(fs.Output(), "       netspec simulate [flags] <device>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	level, err := zerolog.ParseLevel(*logLevel)
+	if err != nil {
+		level = zerolog.InfoLevel
+	}
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(level).With().Timestamp().Logger()
+
+	var scenario *simulator.Scenario
+	switch {
+	case *scenarioPath != "":
+		scenario, err = simulator.LoadScenario(*scenarioPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load scenario: %v\n", err)
+			return 1
+		}
+	case fs.NArg() == 1:
+		scenario, err = deviceScenario(*configPath, fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	default:
+		fs.Usage()
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := simulator.NewTarget(scenario, logger).Serve(ctx, *listen); err != nil {
+		fmt.Fprintf(os.Stderr, "Simulator failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// deviceScenario builds the demo scenario for a device's declared interfaces
+func deviceScenario(configPath, deviceName string) (*simulator.Scenario, error) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	deviceCfg, ok := cfg.DesiredState.Devices[deviceName]
+	if !ok {
+		return nil, fmt.Errorf("device %q not found in %s", deviceName, configPath)
+	}
+
+	names := make([]string, 0, len(deviceCfg.Interfaces))
+	for name := range deviceCfg.Interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scenario := simulator.DefaultScenario(names)
+	for i := range scenario.Interfaces {
+		scenario.Interfaces[i].Description = deviceCfg.Interfaces[scenario.Interfaces[i].Name].Description
+	}
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return scenario, nil
+}
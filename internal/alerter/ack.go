@@ -0,0 +1,78 @@
+package alerter
+
+import (
+	"time"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// Acknowledge marks the active alert with the given dedup key as
+// acknowledged and cancels its escalation. It returns a copy of the alert,
+// or false if no alert with that key is active.
+func (e *Engine) Acknowledge(dedupKey, by string) (types.Alert, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alert, ok := e.activeAlerts[dedupKey]
+	if !ok || alert.State != "firing" {
+		return types.Alert{}, false
+	}
+	if !alert.Acknowledged {
+		now := time.Now()
+		alert.Acknowledged = true
+		alert.AcknowledgedAt = &now
+		alert.AcknowledgedBy = by
+
+		e.logger.Info().
+			Str("dedup_key", dedupKey).
+			Str("by", by).
+			Msg("alert acknowledged")
+		e.emitLifecycle("acknowledged", alert)
+		e.recordHistory(alert.ID, "acknowledged", "by "+by)
+	}
+
+	if e.escalation != nil {
+		e.escalation.CancelEscalation(alert.Device, alert.Entity, alert.AlertType)
+	}
+	return *alert, true
+}
+
+// ResolveByKey resolves the active alert with the given dedup key on behalf
+// of an external system, sends the recovery notification and returns a copy
+// of the resolved alert, or false if no alert with that key is active
+func (e *Engine) ResolveByKey(dedupKey, by string) (types.Alert, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alert, ok := e.activeAlerts[dedupKey]
+	if !ok || alert.State != "firing" {
+		return types.Alert{}, false
+	}
+
+	now := time.Now()
+	alert.State = "resolved"
+	alert.ResolvedAt = &now
+	if by != "" {
+		alert.Message = alert.Message + " (resolved by " + by + ")"
+	}
+	alert.Message = e.templates.Render(alert)
+
+	e.logger.Info().
+		Str("dedup_key", dedupKey).
+		Str("by", by).
+		Msg("alert resolved externally")
+
+	e.emitLifecycle("resolved", alert)
+	e.recordHistory(alert.ID, "resolved", "by "+by)
+
+	if e.notify != nil {
+		e.notify(*alert)
+		e.recordNotified(alert)
+	}
+	if e.escalation != nil {
+		e.escalation.CancelEscalation(alert.Device, alert.Entity, alert.AlertType)
+	}
+	delete(e.activeAlerts, dedupKey)
+	e.retire(alert)
+	return *alert, true
+}
@@ -0,0 +1,72 @@
+package alerter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// DeviceDownFunc reports whether a device is currently unreachable
+type DeviceDownFunc func(device string) bool
+
+// SetDeviceDownFunc registers the source of device reachability used to
+// mark alerts of devices whose upstreams (depends_on) are down
+func (e *Engine) SetDeviceDownFunc(fn DeviceDownFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deviceDown = fn
+}
+
+// dependencyAffected returns the upstream devices of a device that are down
+// when they leave it dependency affected. The caller must hold e.mu.
+func (e *Engine) dependencyAffected(device string) []string {
+	if e.deviceDown == nil {
+		return nil
+	}
+	return e.config.DesiredState.Devices[device].DependsOn.AffectedBy(e.deviceDown)
+}
+
+// dependencySuppressed reports whether an alert raised while its device was
+// dependency affected should not be notified. The caller must hold e.mu.
+func (e *Engine) dependencySuppressed(alert *types.Alert) bool {
+	deps := e.config.DesiredState.Devices[alert.Device].DependsOn
+	return len(alert.DependencyAffected) > 0 && deps != nil && deps.Suppress
+}
+
+// dependencyDetail describes why an alert is dependency affected
+func dependencyDetail(alert *types.Alert) string {
+	return "upstream " + strings.Join(alert.DependencyAffected, ", ") + " down"
+}
+
+// addImpact estimates what an alert on a link cuts off: the devices reached
+// through it (depends_on.via) and their monitored interfaces. The estimate
+// is appended to the message and kept in the related state as
+// impact_devices and impact_interfaces for templates and payloads. The
+// caller must hold e.mu.
+func (e *Engine) addImpact(alert *types.Alert) {
+	behind := e.config.DesiredState.Behind(alert.Device, alert.Entity)
+	if len(behind) == 0 {
+		return
+	}
+	interfaces := 0
+	for _, name := range behind {
+		interfaces += len(e.config.DesiredState.Devices[name].Interfaces)
+	}
+
+	// The related state is shared with the evaluator's change, so copy it
+	related := make(map[string]string, len(alert.RelatedState)+2)
+	for k, v := range alert.RelatedState {
+		related[k] = v
+	}
+	related["impact_devices"] = strings.Join(behind, ",")
+	related["impact_interfaces"] = strconv.Itoa(interfaces)
+	alert.RelatedState = related
+
+	noun := "devices"
+	if len(behind) == 1 {
+		noun = "device"
+	}
+	alert.Message += fmt.Sprintf(" (impact: %d downstream %s, %d monitored interfaces behind this link)", len(behind), noun, interfaces)
+}
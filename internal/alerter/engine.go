@@ -2,12 +2,15 @@ package alerter
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/netspec/netspec/internal/config"
 	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/netspec/netspec/internal/metrics"
 	"github.com/netspec/netspec/internal/notifier"
+	"github.com/netspec/netspec/internal/store"
 	"github.com/netspec/netspec/internal/types"
 	"github.com/rs/zerolog"
 )
@@ -15,6 +18,16 @@ import (
 // NotifyFunc is called when an alert fires or resolves
 type NotifyFunc func(alert types.Alert)
 
+// LifecycleFunc is called for every alert lifecycle event ("fired",
+// "resolved", "acknowledged"), including silenced alerts. It is called with
+// the engine lock held and must not block.
+type LifecycleFunc func(event string, alert types.Alert)
+
+// AssetFunc returns the asset details of a device's entity to attach to its
+// alerts, such as chassis model and serial. It is called with the engine
+// lock held and must not block.
+type AssetFunc func(device, entity string) map[string]string
+
 // Engine manages alert lifecycle and routing
 type Engine struct {
 	config       *config.Config
@@ -27,6 +40,22 @@ type Engine struct {
 	escalation   *EscalationManager
 	events       chan AlertEvent
 	notify       NotifyFunc
+	templates    *MessageTemplates
+	silences     map[string]Silence
+	lifecycle    LifecycleFunc
+	asset        AssetFunc
+	enrich       EnrichFunc
+	deviceDown   DeviceDownFunc
+	maintenance  config.MaintenanceConfig
+	maintenanceOpen map[string]bool // names of the windows open at the last check
+	changeWindows []config.MaintenanceWindow // from the change calendar
+	store        *store.Store
+	dedupDirty   bool            // lastFired changed since the last SaveState
+	activeDirty  bool            // activeAlerts changed since the last SaveState
+	historyStore HistoryStore
+	restored     map[string]bool // dedup keys restored from the store, not yet re-seen
+	history      map[string][]HistoryEntry // alert ID -> timeline
+	resolved     []*types.Alert            // recently resolved, oldest first
 }
 
 // AlertEvent represents an alert event from the evaluator
@@ -38,6 +67,28 @@ type AlertEvent struct {
 	Firing    bool
 	Message   string
 	Related   map[string]string
+	// ObservedAt is when the underlying state was observed on the device;
+	// when set, the delay until the notification is sent is recorded in
+	// netspec_alert_latency_seconds
+	ObservedAt time.Time
+}
+
+var alertLatency = metrics.NewHistogram(
+	"netspec_alert_latency_seconds",
+	"Time from the gNMI notification timestamp to the alert notification being emitted",
+	[]float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60})
+
+// observeLatency records the detection-to-emission delay of an event. Device
+// clocks can run ahead of ours, so negative delays are recorded as zero.
+func observeLatency(ev AlertEvent) {
+	if ev.ObservedAt.IsZero() {
+		return
+	}
+	latency := time.Since(ev.ObservedAt)
+	if latency < 0 {
+		latency = 0
+	}
+	alertLatency.Observe(latency.Seconds())
 }
 
 
@@ -72,14 +123,17 @@ func NewEngine(cfg *config.Config, notifier *notifier.Notifier, logger zerolog.L
 		escMgr = NewEscalationManager(l, escRules, nil) // Will be set via SetEscalationNotify
 	}
 
+	// Notifications are sent with engine.mu held, so the routing read here
+	// follows SetConfig
+	var engine *Engine
 	notifyFn := func(alert types.Alert) {
-		channels := getChannelsForSeverity(cfg, alert.Severity)
+		channels := getChannelsForAlert(engine.config, alert.Device, alert.Entity, alert.Severity)
 		if err := notifier.SendAlert(&alert, channels); err != nil {
 			l.Error().Err(err).Str("alert_id", alert.ID).Msg("Failed to send alert notification")
 		}
 	}
 
-	engine := &Engine{
+	engine = &Engine{
 		config:       cfg,
 		notifier:     notifier,
 		logger:       l,
@@ -89,17 +143,26 @@ func NewEngine(cfg *config.Config, notifier *notifier.Notifier, logger zerolog.L
 		escalation:   escMgr,
 		events:       make(chan AlertEvent, 500),
 		notify:       notifyFn,
+		templates:    NewMessageTemplates(l, cfg.Alerts.MessageTemplates),
+		silences:     make(map[string]Silence),
+		maintenance:  cfg.Maintenance,
+		restored:     make(map[string]bool),
+		history:      make(map[string][]HistoryEntry),
 	}
 
 	if escMgr != nil {
 		escFn := func(alert types.Alert, channels []string) {
+			engine.mu.Lock()
+			if window := engine.maintenanceFor(alert.Device); window != "" {
+				engine.recordHistory(alert.ID, "suppressed", "escalation by maintenance: "+window)
+				engine.mu.Unlock()
+				return
+			}
+			engine.recordHistory(alert.ID, "escalated", strings.Join(channels, ", "))
+			engine.persistEvent("escalated", strings.Join(channels, ", "), &alert)
+			engine.mu.Unlock()
 			alert.Message = fmt.Sprintf("[ESCALATED] %s", alert.Message)
 			for _, chName := range channels {
-				ch, ok := cfg.Alerts.Channels[chName]
-				if !ok {
-					continue
-				}
-				_ = ch // Use channel config if needed
 				if err := notifier.SendAlert(&alert, []string{chName}); err != nil {
 					l.Error().Err(err).Str("channel", chName).Msg("escalation notification failed")
 				} else {
@@ -113,6 +176,85 @@ func NewEngine(cfg *config.Config, notifier *notifier.Notifier, logger zerolog.L
 	return engine
 }
 
+// SetConfig replaces the configuration after a reload: routing, severity
+// overrides, runbooks, message templates, dependencies and maintenance
+// windows follow it from the next event. Flap detection and escalation
+// delays keep the settings the engine was created with.
+func (e *Engine) SetConfig(cfg *config.Config) {
+	templates := NewMessageTemplates(e.logger, cfg.Alerts.MessageTemplates)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.config = cfg
+	e.templates = templates
+	e.maintenance = cfg.Maintenance
+}
+
+// deduplicates reports whether a firing event for key repeats an alert fired
+// within the deduplication window. A condition that worsened in severity is
+// never deduplicated. The caller must hold e.mu.
+func (e *Engine) deduplicates(key, severity string) bool {
+	last, ok := e.lastFired[key]
+	if !ok || time.Since(last) >= e.dedupWindow() {
+		return false
+	}
+	if existing, active := e.activeAlerts[key]; active {
+		return e.config.Alerts.SeverityRank(severity) >= e.config.Alerts.SeverityRank(existing.Severity)
+	}
+	return true
+}
+
+// relatedChanged reports whether update sets any related state value that
+// differs from current
+func relatedChanged(current, update map[string]string) bool {
+	for k, v := range update {
+		if current[k] != v {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNotifyFunc replaces the function that delivers notifications, e.g. to
+// print alerts instead of sending them
+func (e *Engine) SetNotifyFunc(fn NotifyFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.notify = fn
+}
+
+// SetLifecycleHook registers a function receiving alert lifecycle events
+func (e *Engine) SetLifecycleHook(fn LifecycleFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lifecycle = fn
+}
+
+// SetAssetFunc registers the source of the asset details attached to firing
+// alerts
+func (e *Engine) SetAssetFunc(fn AssetFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.asset = fn
+}
+
+// assetFor returns the asset details of an entity. The caller must hold e.mu.
+func (e *Engine) assetFor(device, entity string) map[string]string {
+	if e.asset == nil {
+		return nil
+	}
+	return e.asset(device, entity)
+}
+
+// emitLifecycle reports a lifecycle event and persists it to the history
+// store. The caller must hold e.mu.
+func (e *Engine) emitLifecycle(event string, alert *types.Alert) {
+	e.activeDirty = true
+	e.persistEvent(event, "", alert)
+	if e.lifecycle != nil {
+		e.lifecycle(event, *alert)
+	}
+}
+
 // Events returns the channel to send alert events to
 func (e *Engine) Events() chan<- AlertEvent {
 	return e.events
@@ -152,9 +294,10 @@ func (e *Engine) ProcessStateChange(change evaluator.StateChange) {
 		Entity:    change.Interface,
 		AlertType: change.AlertType,
 		Severity:  change.Severity,
-		Firing:    true,
+		Firing:    change.Firing,
 		Message:   change.Message,
 		Related:   change.RelatedState,
+		ObservedAt: change.ObservedAt,
 	}
 	select {
 	case e.events <- ev:
@@ -163,11 +306,60 @@ func (e *Engine) ProcessStateChange(change evaluator.StateChange) {
 	}
 }
 
+// ProcessResolution resolves the alert raised by an earlier state change
+// once its condition has cleared
+func (e *Engine) ProcessResolution(device, entity, alertType, message string) {
+	ev := AlertEvent{
+		Device:    device,
+		Entity:    entity,
+		AlertType: alertType,
+		Firing:    false,
+		Message:   message,
+	}
+	select {
+	case e.events <- ev:
+	default:
+		e.logger.Warn().Msg("Alert event channel full, dropping")
+	}
+}
+
+// ProcessStateChangeNow processes a state change synchronously, bypassing
+// the event queue. Offline replay uses it so no change is ever dropped.
+func (e *Engine) ProcessStateChangeNow(change evaluator.StateChange) {
+	e.process(AlertEvent{
+		Device:    change.Device,
+		Entity:    change.Interface,
+		AlertType: change.AlertType,
+		Severity:  change.Severity,
+		Firing:    change.Firing,
+		Message:   change.Message,
+		Related:   change.RelatedState,
+		ObservedAt: change.ObservedAt,
+	})
+}
+
 // process handles an alert event
 func (e *Engine) process(ev AlertEvent) {
 	key := fmt.Sprintf("%s|%s|%s", ev.Device, ev.Entity, ev.AlertType)
 	entityKey := fmt.Sprintf("%s|%s", ev.Device, ev.Entity)
 
+	// Overrides run before dedup and routing so both see the final severity
+	e.mu.RLock()
+	cfg := e.config
+	e.mu.RUnlock()
+	ev.Severity = cfg.ApplySeverityOverrides(ev.Device, ev.AlertType, ev.Severity)
+	// Enrichment may call out over the network, so it runs before locking,
+	// and only for events that raise a new alert rather than repeat one
+	var annotations map[string]string
+	if ev.Firing {
+		e.mu.RLock()
+		repeat := e.deduplicates(key, ev.Severity) && !e.restored[key]
+		e.mu.RUnlock()
+		if !repeat {
+			annotations = e.enrichment(ev)
+		}
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -183,34 +375,58 @@ func (e *Engine) process(ev AlertEvent) {
 						Device:    ev.Device,
 						Entity:    ev.Entity,
 						AlertType: "flapping_detected",
-						Severity:  "warning",
+						Severity:  e.config.ApplySeverityOverrides(ev.Device, "flapping_detected", "warning"),
 						State:     "firing",
 						FiredAt:   time.Now(),
 						Message:   fmt.Sprintf("Flapping detected on %s %s: suppressing individual alerts", ev.Device, ev.Entity),
+						Asset:     e.assetFor(ev.Device, ev.Entity),
+						RunbookURL: e.config.RunbookURL(ev.Device, ev.Entity, "flapping_detected"),
+						DedupKey:  "flap|" + entityKey,
 					}
 					e.activeAlerts["flap|"+entityKey] = flapAlert
+					e.recordHistory(flapAlert.ID, "fired", flapAlert.Severity)
+					e.emitLifecycle("fired", flapAlert)
 					if e.notify != nil {
 						e.notify(*flapAlert)
+						e.recordNotified(flapAlert)
 					}
 				}
 				// Suppress the actual alert
+				if existing, active := e.activeAlerts[key]; active {
+					e.recordHistory(existing.ID, "suppressed", "interface is flapping")
+				}
 				return
 			}
 		}
 
 		// Check dedup
-		dedupWindow := e.config.Alerts.AlertBehavior.DeduplicationWindow
-		if dedupWindow == 0 {
-			dedupWindow = 5 * time.Minute
-		}
-		if last, ok := e.lastFired[key]; ok {
-			if time.Since(last) < dedupWindow {
+		now := time.Now()
+		firedAt := now
+		relearned := false
+		if e.deduplicates(key, ev.Severity) {
+			if !e.restored[key] {
 				e.logger.Debug().Str("key", key).Msg("alert deduplicated")
+				if existing, active := e.activeAlerts[key]; active {
+					if relatedChanged(existing.RelatedState, ev.Related) {
+						// A repeat with new details, such as another
+						// device joining devices_reconnecting, updates
+						// the alert in place without re-sending it
+						existing.RelatedState = mergeRelated(ev.Related, existing.RelatedState)
+						existing.Message = ev.Message
+						existing.Message = e.templates.Render(existing)
+						e.recordHistory(existing.ID, "updated", "related state changed within the deduplication window, not re-sent")
+						return
+					}
+					e.recordHistory(existing.ID, "deduplicated", "repeat within the deduplication window, not re-sent")
+				}
 				return
 			}
+			// Notified before a restart: track it as active again
+			// without re-sending
+			relearned = true
+			firedAt = e.lastFired[key]
 		}
-
-		now := time.Now()
+		delete(e.restored, key)
 		alert := &types.Alert{
 			ID:           fmt.Sprintf("%s-%d", key, now.UnixMilli()),
 			Device:       ev.Device,
@@ -218,12 +434,38 @@ func (e *Engine) process(ev AlertEvent) {
 			AlertType:    ev.AlertType,
 			Severity:     ev.Severity,
 			State:        "firing",
-			FiredAt:      now,
+			FiredAt:      firedAt,
 			Message:      ev.Message,
-			RelatedState: ev.Related,
+			RelatedState: mergeRelated(ev.Related, annotations),
+			Asset:        e.assetFor(ev.Device, ev.Entity),
+			RunbookURL:   e.config.RunbookURL(ev.Device, ev.Entity, ev.AlertType),
+			DedupKey:     key,
+			DependencyAffected: e.dependencyAffected(ev.Device),
+			Maintenance:  e.maintenanceFor(ev.Device),
+		}
+		e.addImpact(alert)
+		alert.Message = e.templates.Render(alert)
+		if previous, active := e.activeAlerts[key]; active && previous.ID != alert.ID {
+			// Re-fired at a worse severity: the new alert carries on the
+			// timeline of the one it replaces
+			e.history[alert.ID] = e.history[previous.ID]
+			delete(e.history, previous.ID)
 		}
 		e.activeAlerts[key] = alert
+		e.recordHistory(alert.ID, "fired", ev.Severity)
+		if len(alert.DependencyAffected) > 0 {
+			e.recordHistory(alert.ID, "dependency affected", dependencyDetail(alert))
+		}
+		if relearned {
+			e.recordHistory(alert.ID, "restored", "notified before a restart, not re-sent")
+			e.logger.Info().
+				Str("key", key).
+				Time("fired_at", firedAt).
+				Msg("alert notified before restart is still within the dedup window, not re-sending")
+			return
+		}
 		e.lastFired[key] = now
+		e.dedupDirty = true
 
 		e.logger.Warn().
 			Str("device", ev.Device).
@@ -232,13 +474,34 @@ func (e *Engine) process(ev AlertEvent) {
 			Str("severity", ev.Severity).
 			Msg("alert fired")
 
+		e.emitLifecycle("fired", alert)
+
+		alert.Silenced = e.matchesSilence(alert, now)
+		if e.isSilenced(alert) {
+			e.logger.Debug().Str("key", key).Msg("alert silenced, notification suppressed")
+			e.recordHistory(alert.ID, "silenced", "notification suppressed")
+			return
+		}
+		if alert.Maintenance != "" {
+			e.logger.Debug().Str("key", key).Str("window", alert.Maintenance).Msg("device in maintenance, notification suppressed")
+			e.recordHistory(alert.ID, "suppressed", maintenanceDetail(alert))
+			return
+		}
+		if e.dependencySuppressed(alert) {
+			e.logger.Debug().Str("key", key).Strs("upstream", alert.DependencyAffected).Msg("upstream device down, notification suppressed")
+			e.recordHistory(alert.ID, "suppressed", dependencyDetail(alert))
+			return
+		}
+
 		if e.notify != nil {
 			e.notify(*alert)
+			e.recordNotified(alert)
+			observeLatency(ev)
 		}
 
 		// Start escalation timer if configured
 		if e.escalation != nil {
-			channels := getChannelsForSeverity(e.config, ev.Severity)
+			channels := getChannelsForAlert(e.config, ev.Device, ev.Entity, ev.Severity)
 			e.escalation.StartEscalation(*alert, channels)
 		}
 	} else {
@@ -251,6 +514,7 @@ func (e *Engine) process(ev AlertEvent) {
 		existing.State = "resolved"
 		existing.ResolvedAt = &now
 		existing.Message = ev.Message
+		existing.Message = e.templates.Render(existing)
 
 		e.logger.Info().
 			Str("device", ev.Device).
@@ -258,8 +522,14 @@ func (e *Engine) process(ev AlertEvent) {
 			Str("type", ev.AlertType).
 			Msg("alert resolved")
 
-		if e.notify != nil {
+		e.emitLifecycle("resolved", existing)
+		e.recordHistory(existing.ID, "resolved", ev.Message)
+
+		inMaintenance := existing.Maintenance != "" || e.maintenanceFor(existing.Device) != ""
+		if e.notify != nil && !e.isSilenced(existing) && !e.dependencySuppressed(existing) && !inMaintenance {
 			e.notify(*existing)
+			e.recordNotified(existing)
+			observeLatency(ev)
 		}
 
 		// Cancel escalation
@@ -268,6 +538,7 @@ func (e *Engine) process(ev AlertEvent) {
 		}
 
 		delete(e.activeAlerts, key)
+		e.retire(existing)
 	}
 }
 
@@ -286,11 +557,15 @@ func (e *Engine) checkFlapRecovery() {
 			alert.State = "resolved"
 			alert.ResolvedAt = &now
 			alert.Message = fmt.Sprintf("Flapping stopped on %s %s", alert.Device, alert.Entity)
+			e.emitLifecycle("resolved", alert)
+			e.recordHistory(alert.ID, "resolved", "flapping stopped")
 
 			if e.notify != nil {
 				e.notify(*alert)
+				e.recordNotified(alert)
 			}
 			delete(e.activeAlerts, key)
+			e.retire(alert)
 		}
 	}
 }
@@ -313,6 +588,9 @@ func (e *Engine) ResolveAlert(device, entity, alertType string) {
 
 	// Update message for recovery
 	alert.Message = fmt.Sprintf("Recovered: %s (was down for %s)", alert.Message, duration.Round(time.Second))
+	alert.Message = e.templates.Render(alert)
+	e.emitLifecycle("resolved", alert)
+	e.recordHistory(alert.ID, "resolved", "")
 
 	e.logger.Info().
 		Str("alert_id", alertID).
@@ -320,7 +598,7 @@ func (e *Engine) ResolveAlert(device, entity, alertType string) {
 		Msg("Alert resolved")
 
 	// Send recovery notification
-	channels := getChannelsForSeverity(e.config, alert.Severity)
+	channels := getChannelsForAlert(e.config, alert.Device, alert.Entity, alert.Severity)
 	if err := e.notifier.SendAlert(alert, channels); err != nil {
 		e.logger.Error().
 			Err(err).
@@ -329,12 +607,29 @@ func (e *Engine) ResolveAlert(device, entity, alertType string) {
 	}
 }
 
-// getChannelsForSeverity returns notification channels for a given severity
+// getChannelsForAlert returns the notification channels for an alert: those
+// of the severity_matrix entry for the interface's role and site, else the
+// alert rule for its severity
+func getChannelsForAlert(cfg *config.Config, device, entity, severity string) []string {
+	if entry, ok := cfg.SeverityMatrixFor(device, entity); ok && len(entry.Channels) > 0 {
+		return entry.Channels
+	}
+	return getChannelsForSeverity(cfg, severity)
+}
+
+// getChannelsForSeverity returns notification channels for a given severity.
+// Rules may be keyed by a severity's name or any of its aliases.
 func getChannelsForSeverity(cfg *config.Config, severity string) []string {
 	// Check for severity-specific rule
 	if rule, ok := cfg.Alerts.AlertRules[severity]; ok {
 		return rule.Channels
 	}
+	level := cfg.Alerts.NormalizeSeverity(severity)
+	for name, rule := range cfg.Alerts.AlertRules {
+		if name != "default" && cfg.Alerts.NormalizeSeverity(name) == level {
+			return rule.Channels
+		}
+	}
 
 	// Fall back to default
 	if rule, ok := cfg.Alerts.AlertRules["default"]; ok {
@@ -344,11 +639,6 @@ func getChannelsForSeverity(cfg *config.Config, severity string) []string {
 	return []string{}
 }
 
-// getChannelURL gets channel URL from environment variable
-func getChannelURL(envVar string) string {
-	return "" // Will be handled by notifier
-}
-
 // GetActiveAlerts returns all active alerts
 func (e *Engine) GetActiveAlerts() []*types.Alert {
 	e.mu.RLock()
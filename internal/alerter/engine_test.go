@@ -0,0 +1,116 @@
+package alerter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/netspec/netspec/internal/notifier"
+	"github.com/netspec/netspec/internal/types"
+	"github.com/rs/zerolog"
+)
+
+func newTestEngine(t *testing.T) (*Engine, *[]types.Alert) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Alerts.AlertBehavior.DeduplicationWindow = 5 * time.Minute
+	engine := NewEngine(cfg, notifier.NewNotifier(zerolog.Nop()), zerolog.Nop())
+	var sent []types.Alert
+	engine.SetNotifyFunc(func(alert types.Alert) {
+		sent = append(sent, alert)
+	})
+	return engine, &sent
+}
+
+func TestDeduplicatedRepeatUpdatesRelatedState(t *testing.T) {
+	engine, sent := newTestEngine(t)
+	change := evaluator.StateChange{
+		Device:       evaluator.FleetDevice,
+		Interface:    evaluator.ConnectionEntity,
+		AlertType:    evaluator.AlertTypeDevicesReconnecting,
+		Severity:     "warning",
+		Firing:       true,
+		Message:      "3 devices reconnecting: sw1, sw2, sw3",
+		RelatedState: map[string]string{"devices": "sw1,sw2,sw3"},
+	}
+	engine.ProcessStateChangeNow(change)
+
+	change.Message = "4 devices reconnecting: sw1, sw2, sw3, sw4"
+	change.RelatedState = map[string]string{"devices": "sw1,sw2,sw3,sw4"}
+	engine.ProcessStateChangeNow(change)
+
+	if len(*sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(*sent))
+	}
+	alerts := engine.GetActiveAlerts()
+	if len(alerts) != 1 {
+		t.Fatalf("%d active alerts, want 1", len(alerts))
+	}
+	if got := alerts[0].RelatedState["devices"]; got != "sw1,sw2,sw3,sw4" {
+		t.Errorf("devices %q, want sw1,sw2,sw3,sw4", got)
+	}
+	if alerts[0].Message != change.Message {
+		t.Errorf("message %q, want %q", alerts[0].Message, change.Message)
+	}
+	_, history, _ := engine.GetAlert(alerts[0].ID)
+	if last := history[len(history)-1]; last.Event != "updated" {
+		t.Errorf("last history event %q, want updated", last.Event)
+	}
+}
+
+func TestDeduplicatedRepeatUnchanged(t *testing.T) {
+	engine, sent := newTestEngine(t)
+	change := evaluator.StateChange{
+		Device:       "sw1",
+		Interface:    "Ethernet1",
+		AlertType:    "interface_state_mismatch",
+		Severity:     "critical",
+		Firing:       true,
+		Message:      "Ethernet1 is down",
+		RelatedState: map[string]string{"expected": "up", "actual": "down"},
+	}
+	engine.ProcessStateChangeNow(change)
+	engine.ProcessStateChangeNow(change)
+
+	if len(*sent) != 1 {
+		t.Fatalf("sent %d notifications, want 1", len(*sent))
+	}
+	alerts := engine.GetActiveAlerts()
+	_, history, _ := engine.GetAlert(alerts[0].ID)
+	if last := history[len(history)-1]; last.Event != "deduplicated" {
+		t.Errorf("last history event %q, want deduplicated", last.Event)
+	}
+}
+
+func TestEnrichmentSkipsDeduplicatedRepeats(t *testing.T) {
+	engine, sent := newTestEngine(t)
+	lookups := 0
+	engine.SetEnrichFunc(func(device, entity, alertType, severity string) map[string]string {
+		lookups++
+		return map[string]string{"circuit_id": "CID-1"}
+	})
+	change := evaluator.StateChange{
+		Device:    "sw1",
+		Interface: "Ethernet1",
+		AlertType: "interface_state_mismatch",
+		Severity:  "warning",
+		Firing:    true,
+		Message:   "Ethernet1 is down",
+	}
+	engine.ProcessStateChangeNow(change)
+	engine.ProcessStateChangeNow(change)
+	if lookups != 1 {
+		t.Fatalf("%d lookups, want 1", lookups)
+	}
+	if got := (*sent)[0].RelatedState["circuit_id"]; got != "CID-1" {
+		t.Errorf("circuit_id %q, want CID-1", got)
+	}
+
+	// An escalation is a new notification, so it is looked up again
+	change.Severity = "critical"
+	engine.ProcessStateChangeNow(change)
+	if lookups != 2 || len(*sent) != 2 {
+		t.Fatalf("%d lookups, %d sent, want 2 each", lookups, len(*sent))
+	}
+}
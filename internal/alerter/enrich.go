@@ -0,0 +1,46 @@
+package alerter
+
+// EnrichFunc returns annotations for a firing alert from an external
+// source, such as a CMDB. It is called for new alerts only, not for repeats
+// within the deduplication window, without the engine lock, and may block
+// for a bounded time.
+type EnrichFunc func(device, entity, alertType, severity string) map[string]string
+
+// SetEnrichFunc registers the source of annotations merged into the related
+// state of firing alerts
+func (e *Engine) SetEnrichFunc(fn EnrichFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enrich = fn
+}
+
+// enrichment looks up the annotations of a firing event. The caller must
+// not hold e.mu.
+func (e *Engine) enrichment(ev AlertEvent) map[string]string {
+	if !ev.Firing {
+		return nil
+	}
+	e.mu.RLock()
+	enrich := e.enrich
+	e.mu.RUnlock()
+	if enrich == nil {
+		return nil
+	}
+	return enrich(ev.Device, ev.Entity, ev.AlertType, ev.Severity)
+}
+
+// mergeRelated returns the evaluator's related state with the annotations
+// added. The evaluator's own keys win over annotations of the same name.
+func mergeRelated(related, annotations map[string]string) map[string]string {
+	if len(annotations) == 0 {
+		return related
+	}
+	merged := make(map[string]string, len(related)+len(annotations))
+	for k, v := range annotations {
+		merged[k] = v
+	}
+	for k, v := range related {
+		merged[k] = v
+	}
+	return merged
+}
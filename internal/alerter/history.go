@@ -0,0 +1,90 @@
+package alerter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+const (
+	// maxAlertHistory bounds the timeline kept for one alert; the oldest
+	// entries after the first (when it fired) are dropped
+	maxAlertHistory = 100
+	// maxResolvedAlerts is how many resolved alerts stay viewable with
+	// their timeline
+	maxResolvedAlerts = 200
+)
+
+// HistoryEntry is one event in an alert's timeline
+type HistoryEntry struct {
+	At     time.Time `json:"at"`
+	Event  string    `json:"event"` // fired, dependency affected, notified, deduplicated, suppressed, silenced, maintenance ended, escalated, acknowledged, remediation, enforced, ticket, resolved, restored
+	Detail string    `json:"detail,omitempty"`
+}
+
+// recordHistory appends an event to an alert's timeline. The caller must
+// hold e.mu.
+func (e *Engine) recordHistory(alertID, event, detail string) {
+	entries := append(e.history[alertID], HistoryEntry{At: time.Now(), Event: event, Detail: detail})
+	if len(entries) > maxAlertHistory {
+		entries = append(entries[:1], entries[len(entries)-maxAlertHistory+1:]...)
+	}
+	e.history[alertID] = entries
+}
+
+// recordNotified records the channels an alert was sent to. The caller must
+// hold e.mu.
+func (e *Engine) recordNotified(alert *types.Alert) {
+	channels := getChannelsForAlert(e.config, alert.Device, alert.Entity, alert.Severity)
+	detail := strings.Join(channels, ", ")
+	if detail == "" {
+		detail = "no channels routed"
+	}
+	e.recordHistory(alert.ID, "notified", detail)
+}
+
+// retire keeps a resolved alert viewable, dropping the oldest resolved
+// alert and its timeline beyond maxResolvedAlerts. The caller must hold
+// e.mu.
+func (e *Engine) retire(alert *types.Alert) {
+	e.resolved = append(e.resolved, alert)
+	if len(e.resolved) > maxResolvedAlerts {
+		delete(e.history, e.resolved[0].ID)
+		e.resolved = e.resolved[1:]
+	}
+}
+
+// RecordEvent adds an event from outside the engine, such as a remediation
+// attempt, to an active or recently resolved alert's timeline
+func (e *Engine) RecordEvent(alertID, event, detail string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.history[alertID]; ok {
+		e.recordHistory(alertID, event, detail)
+	}
+}
+
+// GetAlert returns a copy of the active or recently resolved alert with the
+// given ID and its timeline, oldest first
+func (e *Engine) GetAlert(id string) (types.Alert, []HistoryEntry, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var found *types.Alert
+	for _, alert := range e.activeAlerts {
+		if alert.ID == id {
+			found = alert
+			break
+		}
+	}
+	for i := len(e.resolved) - 1; found == nil && i >= 0; i-- {
+		if e.resolved[i].ID == id {
+			found = e.resolved[i]
+		}
+	}
+	if found == nil {
+		return types.Alert{}, nil, false
+	}
+	return *found, append([]HistoryEntry{}, e.history[id]...), true
+}
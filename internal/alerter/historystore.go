@@ -0,0 +1,308 @@
+package alerter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// DefaultHistoryRetention is how long alert history is kept when
+// state_persistence.history_retention is unset
+const DefaultHistoryRetention = 30 * 24 * time.Hour
+
+// maxHistoryQuery bounds the records one history query returns
+const maxHistoryQuery = 1000
+
+// historyCompactInterval is how often records older than the retention are
+// dropped from the history file
+const historyCompactInterval = time.Hour
+
+// maxHistoryLine bounds one record in the history file; longer lines are
+// skipped
+const maxHistoryLine = 1024 * 1024
+
+// ErrNoHistoryStore is returned by GetAlertHistory when alert history is
+// not persisted
+var ErrNoHistoryStore = errors.New("alert history is not persisted; enable state_persistence")
+
+// HistoryRecord is one persisted alert lifecycle event with a snapshot of
+// the alert as it was at that moment
+type HistoryRecord struct {
+	At     time.Time   `json:"at"`
+	Event  string      `json:"event"` // fired, acknowledged, escalated, resolved
+	Detail string      `json:"detail,omitempty"`
+	Alert  types.Alert `json:"alert"`
+}
+
+// HistoryQuery selects persisted history records. Zero fields match
+// everything.
+type HistoryQuery struct {
+	Device    string
+	AlertType string
+	DedupKey  string
+	Severity  string // compared case-insensitively
+	Since     time.Time
+	Until     time.Time
+	Limit     int // newest records kept; maxHistoryQuery when zero or larger
+}
+
+// matches reports whether a record satisfies the query
+func (q HistoryQuery) matches(rec HistoryRecord) bool {
+	return (q.Device == "" || rec.Alert.Device == q.Device) &&
+		(q.AlertType == "" || rec.Alert.AlertType == q.AlertType) &&
+		(q.DedupKey == "" || rec.Alert.DedupKey == q.DedupKey) &&
+		(q.Severity == "" || strings.EqualFold(rec.Alert.Severity, q.Severity)) &&
+		(q.Since.IsZero() || !rec.At.Before(q.Since)) &&
+		(q.Until.IsZero() || !rec.At.After(q.Until))
+}
+
+// HistoryStore persists alert lifecycle events beyond the in-memory
+// timelines, which are bounded and lost on restart. FileHistoryStore is
+// the built-in implementation, used instead of a database so NetSpec needs
+// no cgo or external service; another backend only needs these methods.
+type HistoryStore interface {
+	Append(rec HistoryRecord) error
+	// Query returns matching records, newest first
+	Query(q HistoryQuery) ([]HistoryRecord, error)
+	Close() error
+}
+
+// FileHistoryStore is a HistoryStore kept as an append-only JSON Lines
+// file. Records older than the retention are dropped when it is opened and
+// every historyCompactInterval after.
+type FileHistoryStore struct {
+	path      string
+	retention time.Duration
+	mu        sync.Mutex
+	file      *os.File
+	done      chan struct{}
+}
+
+// OpenFileHistoryStore opens or creates the history file at path, first
+// rewriting it without records older than retention, and compacts it
+// periodically until closed
+func OpenFileHistoryStore(path string, retention time.Duration) (*FileHistoryStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := compactHistory(path, time.Now().Add(-retention)); err != nil {
+		return nil, err
+	}
+	f, err := openHistoryFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileHistoryStore{path: path, retention: retention, file: f, done: make(chan struct{})}
+	go s.compactLoop()
+	return s, nil
+}
+
+func openHistoryFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+}
+
+// compactLoop drops expired records every historyCompactInterval until the
+// store is closed
+func (s *FileHistoryStore) compactLoop() {
+	ticker := time.NewTicker(historyCompactInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			// A failed compaction leaves the file as it was; the next tick
+			// retries
+			_ = s.compact(now)
+		}
+	}
+}
+
+// compact rewrites the file without the records older than the retention
+// at now, reopening it for appends when it was replaced
+func (s *FileHistoryStore) compact(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := compactHistory(s.path, now.Add(-s.retention)); err != nil {
+		return err
+	}
+	f, err := openHistoryFile(s.path)
+	if err != nil {
+		return err
+	}
+	s.file.Close()
+	s.file = f
+	return nil
+}
+
+// Path returns the history file
+func (s *FileHistoryStore) Path() string {
+	return s.path
+}
+
+// Append writes one record to the end of the file
+func (s *FileHistoryStore) Append(rec HistoryRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Query scans the file for matching records, keeping the newest
+func (s *FileHistoryStore) Query(q HistoryQuery) ([]HistoryRecord, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > maxHistoryQuery {
+		limit = maxHistoryQuery
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matched []HistoryRecord
+	err := scanHistory(s.path, func(rec HistoryRecord) {
+		if q.matches(rec) {
+			matched = append(matched, rec)
+			if len(matched) > limit {
+				matched = matched[1:]
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	return matched, nil
+}
+
+// Close stops compaction and closes the file
+func (s *FileHistoryStore) Close() error {
+	close(s.done)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// scanHistory calls fn for every record in the file in order. Lines that
+// do not decode, such as one cut short by a crash, and lines longer than
+// maxHistoryLine are skipped.
+func scanHistory(path string, fn func(HistoryRecord)) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 64*1024)
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !tooLong {
+			line = append(line, chunk...)
+			tooLong = len(line) > maxHistoryLine
+		}
+		if err == bufio.ErrBufferFull {
+			continue // the rest of the line follows
+		}
+		if !tooLong {
+			var rec HistoryRecord
+			if json.Unmarshal(line, &rec) == nil {
+				fn(rec)
+			}
+		}
+		line, tooLong = line[:0], false
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// compactHistory rewrites the history file without the records before
+// cutoff. The file is left untouched when nothing is dropped.
+func compactHistory(path string, cutoff time.Time) error {
+	var kept []HistoryRecord
+	dropped := 0
+	err := scanHistory(path, func(rec HistoryRecord) {
+		if rec.At.Before(cutoff) {
+			dropped++
+			return
+		}
+		kept = append(kept, rec)
+	})
+	if err != nil || dropped == 0 {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range kept {
+		if err := enc.Encode(rec); err != nil {
+			f.Close()
+			return fmt.Errorf("compacting %s: %w", path, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SetHistoryStore persists every alert lifecycle event to hs from now on
+func (e *Engine) SetHistoryStore(hs HistoryStore) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.historyStore = hs
+}
+
+// persistEvent appends a lifecycle event to the history store, if any.
+// The caller must hold e.mu.
+func (e *Engine) persistEvent(event, detail string, alert *types.Alert) {
+	if e.historyStore == nil {
+		return
+	}
+	rec := HistoryRecord{At: time.Now(), Event: event, Detail: detail, Alert: *alert}
+	if err := e.historyStore.Append(rec); err != nil {
+		e.logger.Error().Err(err).Str("alert", alert.ID).Msg("Failed to persist alert history")
+	}
+}
+
+// GetAlertHistory returns the persisted lifecycle events matching q, newest
+// first, or ErrNoHistoryStore without a history store
+func (e *Engine) GetAlertHistory(q HistoryQuery) ([]HistoryRecord, error) {
+	e.mu.RLock()
+	hs := e.historyStore
+	e.mu.RUnlock()
+	if hs == nil {
+		return nil, ErrNoHistoryStore
+	}
+	return hs.Query(q)
+}
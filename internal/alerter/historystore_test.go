@@ -0,0 +1,79 @@
+package alerter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+func historyRecord(t *testing.T, at time.Time, id string) []byte {
+	t.Helper()
+	line, err := json.Marshal(HistoryRecord{At: at, Event: "fired", Alert: types.Alert{ID: id, Device: "sw1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(line, '\n')
+}
+
+func historyIDs(t *testing.T, s *FileHistoryStore) []string {
+	t.Helper()
+	recs, err := s.Query(HistoryQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, rec := range recs {
+		ids = append(ids, rec.Alert.ID)
+	}
+	return ids
+}
+
+func TestFileHistoryStoreSkipsOversizedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Now()
+	var data []byte
+	data = append(data, historyRecord(t, now, "a")...)
+	huge := HistoryRecord{At: now, Event: "fired", Detail: strings.Repeat("x", maxHistoryLine), Alert: types.Alert{ID: "huge"}}
+	line, _ := json.Marshal(huge)
+	data = append(append(data, line...), '\n')
+	data = append(data, "{cut short\n"...)
+	data = append(data, historyRecord(t, now, "b")...)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := OpenFileHistoryStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got := historyIDs(t, s); len(got) != 2 || got[0] != "b" || got[1] != "a" {
+		t.Fatalf("got %v, want [b a]", got)
+	}
+}
+
+func TestFileHistoryStoreCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Now()
+	s, err := OpenFileHistoryStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.Append(HistoryRecord{At: now.Add(-2 * time.Hour), Event: "fired", Alert: types.Alert{ID: "old"}})
+	s.Append(HistoryRecord{At: now, Event: "fired", Alert: types.Alert{ID: "new"}})
+
+	if err := s.compact(now); err != nil {
+		t.Fatal(err)
+	}
+	// Appends go to the rewritten file
+	s.Append(HistoryRecord{At: now, Event: "resolved", Alert: types.Alert{ID: "newer"}})
+	if got := historyIDs(t, s); len(got) != 2 || got[0] != "newer" || got[1] != "new" {
+		t.Fatalf("got %v, want [newer new]", got)
+	}
+}
@@ -0,0 +1,120 @@
+package alerter
+
+import (
+	"context"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// maintenanceCheckInterval is how often the maintenance scheduler looks for
+// windows opening and closing
+const maintenanceCheckInterval = 30 * time.Second
+
+// SetChangeWindows replaces the maintenance windows created from the change
+// calendar. They apply alongside those of maintenance.yaml, which a reload
+// leaves them out of.
+func (e *Engine) SetChangeWindows(windows []config.MaintenanceWindow) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.changeWindows = windows
+}
+
+// maintenanceWindows returns the configured and change calendar windows.
+// The caller must hold e.mu.
+func (e *Engine) maintenanceWindows() config.MaintenanceConfig {
+	if len(e.changeWindows) == 0 {
+		return e.maintenance
+	}
+	windows := make([]config.MaintenanceWindow, 0, len(e.maintenance.MaintenanceWindows)+len(e.changeWindows))
+	windows = append(windows, e.maintenance.MaintenanceWindows...)
+	windows = append(windows, e.changeWindows...)
+	return config.MaintenanceConfig{MaintenanceWindows: windows}
+}
+
+// maintenanceFor returns the name of the open maintenance window with
+// suppress_alerts covering the device, or "". The caller must hold e.mu.
+func (e *Engine) maintenanceFor(device string) string {
+	if w, ok := e.maintenanceWindows().Suppressing(device, time.Now()); ok {
+		return w.Name
+	}
+	return ""
+}
+
+// maintenanceDetail describes why an alert's notifications are suppressed
+func maintenanceDetail(alert *types.Alert) string {
+	return "by maintenance: " + alert.Maintenance
+}
+
+// RunMaintenance logs maintenance windows opening and closing and, when a
+// window closes, notifies the alerts it suppressed that are still firing.
+// It also clears the silenced flag of alerts whose silence expired. It
+// returns when ctx is done.
+func (e *Engine) RunMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(maintenanceCheckInterval)
+	defer ticker.Stop()
+	e.checkMaintenance(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			e.checkMaintenance(now)
+		}
+	}
+}
+
+// checkMaintenance applies the maintenance windows open at now
+func (e *Engine) checkMaintenance(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	open := make(map[string]bool)
+	for _, w := range e.maintenanceWindows().MaintenanceWindows {
+		if !w.Active(now) {
+			continue
+		}
+		open[w.Name] = true
+		if !e.maintenanceOpen[w.Name] {
+			e.logger.Info().
+				Str("window", w.Name).
+				Strs("devices", w.Devices).
+				Bool("suppress_alerts", w.SuppressAlerts).
+				Msg("maintenance window started")
+		}
+	}
+	for name := range e.maintenanceOpen {
+		if !open[name] {
+			e.logger.Info().Str("window", name).Msg("maintenance window ended")
+		}
+	}
+	e.maintenanceOpen = open
+	e.markSilenced(now)
+
+	for _, alert := range e.activeAlerts {
+		if alert.Maintenance == "" {
+			continue
+		}
+		if name := e.maintenanceFor(alert.Device); name != "" {
+			alert.Maintenance = name
+			continue
+		}
+		// The window closed while the alert is still firing: it is notified
+		// now, as if it had just fired
+		e.recordHistory(alert.ID, "maintenance ended", alert.Maintenance)
+		alert.Maintenance = ""
+		e.activeDirty = true
+		if e.isSilenced(alert) || e.dependencySuppressed(alert) {
+			continue
+		}
+		if e.notify != nil {
+			e.notify(*alert)
+			e.recordNotified(alert)
+		}
+		if e.escalation != nil {
+			channels := getChannelsForAlert(e.config, alert.Device, alert.Entity, alert.Severity)
+			e.escalation.StartEscalation(*alert, channels)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package alerter
+
+// AlertTypeUnexpectedOnline fires when a device whose desired state is
+// offline accepts a gNMI connection
+const AlertTypeUnexpectedOnline = "device_unexpected_online"
+
+// ReportUnexpectedOnline fires (online) or resolves the unexpected-online
+// alert of a device expected to be offline. The alert's entity is "device";
+// its severity defaults to warning and can be changed with severity
+// overrides.
+func (e *Engine) ReportUnexpectedOnline(device string, online bool) {
+	message := "Device expected offline is no longer reachable"
+	if online {
+		message = "Device expected offline accepted a gNMI connection"
+	}
+	ev := AlertEvent{
+		Device:    device,
+		Entity:    "device",
+		AlertType: AlertTypeUnexpectedOnline,
+		Severity:  "warning",
+		Firing:    online,
+		Message:   message,
+	}
+	select {
+	case e.events <- ev:
+	default:
+		e.logger.Warn().Msg("Alert event channel full, dropping")
+	}
+}
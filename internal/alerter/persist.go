@@ -0,0 +1,140 @@
+package alerter
+
+import (
+	"context"
+	"time"
+
+	"github.com/netspec/netspec/internal/store"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// dedupSection is the store section holding last-fired times by dedup key
+const dedupSection = "dedup"
+
+// activeSection is the store section holding the active alerts
+const activeSection = "active"
+
+// persistInterval is how often changed dedup state is written to the store
+const persistInterval = 5 * time.Second
+
+// dedupWindow returns the configured deduplication window, default 5m
+func (e *Engine) dedupWindow() time.Duration {
+	if window := e.config.Alerts.AlertBehavior.DeduplicationWindow; window > 0 {
+		return window
+	}
+	return 5 * time.Minute
+}
+
+// SetStore restores dedup timestamps and active alerts from st and
+// persists them there from now on. Entries older than the deduplication
+// window are dropped, so an alert still within its window when the daemon
+// restarts is tracked as active again but not re-notified. Restored
+// alerts stay active until their condition re-fires or resolves.
+func (e *Engine) SetStore(st *store.Store) error {
+	var lastFired map[string]time.Time
+	if _, err := st.Load(dedupSection, &lastFired); err != nil {
+		return err
+	}
+	var active []*types.Alert
+	if _, err := st.Load(activeSection, &active); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.store = st
+	now := time.Now()
+	window := e.dedupWindow()
+	for key, at := range lastFired {
+		if now.Sub(at) >= window {
+			continue
+		}
+		if existing, ok := e.lastFired[key]; !ok || at.After(existing) {
+			e.lastFired[key] = at
+			e.restored[key] = true
+		}
+	}
+
+	restoredAlerts := 0
+	warnUnknown := e.config.Alerts.AlertBehavior.StatePersistence.OnRestart != "silent"
+	for _, alert := range active {
+		if alert == nil || alert.DedupKey == "" {
+			continue
+		}
+		if _, ok := e.activeAlerts[alert.DedupKey]; ok {
+			continue
+		}
+		e.activeAlerts[alert.DedupKey] = alert
+		e.recordHistory(alert.ID, "restored", "active before a restart; current state unknown until re-evaluated")
+		restoredAlerts++
+		if warnUnknown {
+			e.logger.Warn().
+				Str("device", alert.Device).
+				Str("entity", alert.Entity).
+				Str("type", alert.AlertType).
+				Msg("alert active before restart restored, state unknown until re-evaluated")
+		}
+	}
+
+	e.logger.Info().
+		Str("path", st.Path()).
+		Int("restored", len(e.restored)).
+		Int("active_restored", restoredAlerts).
+		Msg("dedup state restored")
+	return nil
+}
+
+// RunPersistence writes changed dedup state to the store periodically until
+// ctx is cancelled. It is a no-op without a store.
+func (e *Engine) RunPersistence(ctx context.Context) {
+	ticker := time.NewTicker(persistInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.SaveState(); err != nil {
+				e.logger.Error().Err(err).Msg("Failed to persist alert state")
+			}
+		}
+	}
+}
+
+// SaveState writes the dedup timestamps still inside the deduplication
+// window and the active alerts to the store if they changed since the last
+// save
+func (e *Engine) SaveState() error {
+	e.mu.Lock()
+	if e.store == nil || (!e.dedupDirty && !e.activeDirty) {
+		e.mu.Unlock()
+		return nil
+	}
+	st := e.store
+	now := time.Now()
+	window := e.dedupWindow()
+	lastFired := make(map[string]time.Time, len(e.lastFired))
+	for key, at := range e.lastFired {
+		if now.Sub(at) < window {
+			lastFired[key] = at
+		}
+	}
+	active := make([]types.Alert, 0, len(e.activeAlerts))
+	for _, alert := range e.activeAlerts {
+		active = append(active, *alert)
+	}
+	e.dedupDirty, e.activeDirty = false, false
+	e.mu.Unlock()
+
+	err := st.Save(dedupSection, lastFired)
+	if err == nil {
+		err = st.Save(activeSection, active)
+	}
+	if err != nil {
+		e.mu.Lock()
+		e.dedupDirty, e.activeDirty = true, true
+		e.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package alerter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// SilenceMatchers select the alerts a silence applies to. Empty matchers
+// match anything, so a silence with only Device set covers the whole
+// device.
+type SilenceMatchers struct {
+	Device    string
+	Entity    string // glob, case-insensitive, e.g. Ethernet1/*
+	AlertType string
+	Severity  string
+}
+
+// Silence suppresses notifications for matching alerts until it expires.
+// Silenced alerts are still tracked as active, with Silenced set.
+type Silence struct {
+	ID string
+	SilenceMatchers
+	CreatedBy string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// matches reports whether the silence applies to an alert at time now
+func (s Silence) matches(alert *types.Alert, now time.Time) bool {
+	if !now.Before(s.ExpiresAt) {
+		return false
+	}
+	if s.Device != "" && s.Device != alert.Device {
+		return false
+	}
+	if s.Entity != "" && !config.MatchGlob(s.Entity, alert.Entity) {
+		return false
+	}
+	if s.AlertType != "" && s.AlertType != alert.AlertType {
+		return false
+	}
+	return s.Severity == "" || s.Severity == alert.Severity
+}
+
+// AddSilence silences the alerts matching m for the given duration,
+// including those already firing
+func (e *Engine) AddSilence(m SilenceMatchers, duration time.Duration, by string) Silence {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	silence := Silence{
+		ID:              fmt.Sprintf("silence-%d", now.UnixNano()),
+		SilenceMatchers: m,
+		CreatedBy:       by,
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(duration),
+	}
+	e.silences[silence.ID] = silence
+	e.markSilenced(now)
+
+	e.logger.Info().
+		Str("silence_id", silence.ID).
+		Str("device", m.Device).
+		Str("entity", m.Entity).
+		Str("alert_type", m.AlertType).
+		Str("severity", m.Severity).
+		Dur("duration", duration).
+		Str("by", by).
+		Msg("silence added")
+
+	return silence
+}
+
+// GetSilences returns the silences that have not yet expired, soonest
+// expiry first
+func (e *Engine) GetSilences() []Silence {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	now := time.Now()
+	silences := make([]Silence, 0, len(e.silences))
+	for _, s := range e.silences {
+		if now.Before(s.ExpiresAt) {
+			silences = append(silences, s)
+		}
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].ExpiresAt.Before(silences[j].ExpiresAt)
+	})
+	return silences
+}
+
+// isSilenced reports whether any active silence matches the alert, or its
+// device is outside its monitoring schedule, and drops expired silences. The
+// caller must hold e.mu.
+func (e *Engine) isSilenced(alert *types.Alert) bool {
+	now := time.Now()
+	return e.matchesSilence(alert, now) || !e.config.DesiredState.Devices[alert.Device].Monitored(now)
+}
+
+// matchesSilence reports whether any active silence matches the alert and
+// drops expired silences. The caller must hold e.mu.
+func (e *Engine) matchesSilence(alert *types.Alert, now time.Time) bool {
+	matched := false
+	for id, s := range e.silences {
+		if !now.Before(s.ExpiresAt) {
+			delete(e.silences, id)
+			continue
+		}
+		if s.matches(alert, now) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// markSilenced updates the Silenced flag of the active alerts after
+// silences were added, removed or expired. Alerts whose silence ends are
+// not notified then; they were already tracked while silenced. The caller
+// must hold e.mu.
+func (e *Engine) markSilenced(now time.Time) {
+	for _, alert := range e.activeAlerts {
+		silenced := e.matchesSilence(alert, now)
+		if alert.Silenced == silenced {
+			continue
+		}
+		alert.Silenced = silenced
+		e.activeDirty = true
+		if silenced {
+			e.recordHistory(alert.ID, "silenced", "notifications suppressed")
+		} else {
+			e.recordHistory(alert.ID, "unsilenced", "silence ended")
+		}
+	}
+}
+
+// RemoveSilence ends a silence before it expires, reporting whether it
+// existed
+func (e *Engine) RemoveSilence(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	silence, ok := e.silences[id]
+	if !ok {
+		return false
+	}
+	delete(e.silences, id)
+	e.markSilenced(time.Now())
+
+	e.logger.Info().
+		Str("silence_id", id).
+		Str("device", silence.Device).
+		Msg("silence removed")
+
+	return true
+}
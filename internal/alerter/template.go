@@ -0,0 +1,104 @@
+package alerter
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/netspec/netspec/internal/types"
+	"github.com/rs/zerolog"
+)
+
+// resolvedTemplateSuffix selects the template used when an alert resolves,
+// e.g. "interface_state_mismatch_resolved"
+const resolvedTemplateSuffix = "_resolved"
+
+// MessageData is the data available to message templates
+type MessageData struct {
+	Device       string
+	Entity       string
+	AlertType    string
+	Severity     string
+	State        string
+	Expected     string
+	Actual       string
+	Message      string // the built-in message
+	RelatedState map[string]string
+	Asset        map[string]string // chassis_model, chassis_serial, optic_serial, ...
+	FiredAt      time.Time
+	Duration     string // time since the alert fired, set on resolve
+}
+
+// MessageTemplates renders alert messages from per-alert-type templates
+type MessageTemplates struct {
+	templates map[string]*template.Template
+}
+
+// NewMessageTemplates parses the configured templates. Templates that fail to
+// parse are logged and skipped so the built-in message is used instead.
+func NewMessageTemplates(log zerolog.Logger, raw map[string]string) *MessageTemplates {
+	mt := &MessageTemplates{templates: make(map[string]*template.Template)}
+	for name, text := range raw {
+		tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			log.Error().Err(err).Str("template", name).Msg("invalid message template, using default message")
+			continue
+		}
+		mt.templates[name] = tmpl
+	}
+	return mt
+}
+
+// Render returns the templated message for an alert, or the alert's own
+// message when no template applies or rendering fails
+func (mt *MessageTemplates) Render(alert *types.Alert) string {
+	if mt == nil || len(mt.templates) == 0 {
+		return alert.Message
+	}
+	name := alert.AlertType
+	if alert.State == "resolved" {
+		name += resolvedTemplateSuffix
+	}
+	tmpl, ok := mt.templates[name]
+	if !ok {
+		return alert.Message
+	}
+
+	data := MessageData{
+		Device:       alert.Device,
+		Entity:       alert.Entity,
+		AlertType:    alert.AlertType,
+		Severity:     alert.Severity,
+		State:        alert.State,
+		Message:      alert.Message,
+		RelatedState: alert.RelatedState,
+		Asset:        alert.Asset,
+		FiredAt:      alert.FiredAt,
+	}
+	data.Expected, data.Actual = expectedActual(alert.RelatedState)
+	if alert.ResolvedAt != nil {
+		data.Duration = alert.ResolvedAt.Sub(alert.FiredAt).Round(time.Second).String()
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return alert.Message
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// expectedActual picks the expected/actual pair out of related state,
+// whichever kind of check produced the alert
+func expectedActual(related map[string]string) (string, string) {
+	for _, pair := range [][2]string{
+		{"expected_state", "actual_state"},
+		{"expected_admin", "actual_admin"},
+		{"minimum", "active_members"},
+	} {
+		if expected, ok := related[pair[0]]; ok {
+			return expected, related[pair[1]]
+		}
+	}
+	return "", ""
+}
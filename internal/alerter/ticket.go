@@ -0,0 +1,40 @@
+package alerter
+
+import "github.com/netspec/netspec/internal/types"
+
+// AddTicket records a ticket opened for the active or recently resolved
+// alert with the given ID, replacing any earlier ticket from the same
+// channel. Ticket channels open tickets in the background, so the alert
+// may have resolved by the time it is added.
+func (e *Engine) AddTicket(alertID string, ticket types.Ticket) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var found *types.Alert
+	for _, alert := range e.activeAlerts {
+		if alert.ID == alertID {
+			found = alert
+			break
+		}
+	}
+	for i := len(e.resolved) - 1; found == nil && i >= 0; i-- {
+		if e.resolved[i].ID == alertID {
+			found = e.resolved[i]
+		}
+	}
+	if found == nil {
+		return
+	}
+
+	tickets := make([]types.Ticket, 0, len(found.Tickets)+1)
+	for _, t := range found.Tickets {
+		if t.Channel != ticket.Channel {
+			tickets = append(tickets, t)
+		}
+	}
+	// Copies of the alert handed out earlier share the old slice, so it is
+	// replaced rather than appended to
+	found.Tickets = append(tickets, ticket)
+	e.recordHistory(alertID, "ticket", ticket.Channel+": "+ticket.ID)
+	e.persistEvent("ticket", ticket.Channel+": "+ticket.ID, found)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// adoptRequest is the body of POST /api/devices/{name}/adopt
+type adoptRequest struct {
+	Interface string `json:"interface"`
+}
+
+// handleAdoptAPI reads the device's current interface state via gNMI Get and
+// returns a desired-state stanza treating it as intent. The stanza is
+// returned as YAML unless ?format=json is given.
+func (s *Server) handleAdoptAPI(w http.ResponseWriter, r *http.Request, deviceName string) {
+	if r.Method == http.MethodPost {
+		s.handleAdoptInterface(w, r, deviceName)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	if cfg == nil {
+		http.Error(w, "Configuration not loaded", http.StatusInternalServerError)
+		return
+	}
+	deviceCfg, exists := cfg.DesiredState.Devices[deviceName]
+	if !exists {
+		http.Error(w, "Device not found", http.StatusNotFound)
+		return
+	}
+
+	s.collectorMu.RLock()
+	getter := s.collectorGetter
+	s.collectorMu.RUnlock()
+
+	if getter == nil || getter(deviceName) == nil {
+		http.Error(w, "Device not found or collector not running", http.StatusNotFound)
+		return
+	}
+
+	s.logger.Info().Str("device", deviceName).Msg("Adopting current device state")
+
+	observed, err := getter(deviceName).GetInterfaces()
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	proposed := config.ProposeDevice(deviceCfg.Address, deviceCfg.Description, cfg.FilterIgnored(deviceName, observed))
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"device":  deviceName,
+			"config":  proposed,
+		})
+		return
+	}
+
+	stanza, err := config.MarshalDeviceStanza(deviceName, proposed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(stanza)
+}
+
+// handleAdoptInterface rewrites one interface's desired state in
+// desired-state.yaml to match the state currently observed on the device,
+// for deviations that were intentional changes, then reloads configuration
+func (s *Server) handleAdoptInterface(w http.ResponseWriter, r *http.Request, deviceName string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req adoptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Interface == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "interface is required",
+		})
+		return
+	}
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	configPath := s.configPath
+	s.reloadMu.RUnlock()
+
+	if cfg == nil || s.evaluator == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Configuration or evaluator not loaded",
+		})
+		return
+	}
+
+	ifCfg, ok := cfg.DesiredState.Devices[deviceName].Interfaces[req.Interface]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "Interface not declared in desired state",
+		})
+		return
+	}
+
+	observed, ok := s.evaluator.GetInterfaceState(deviceName, req.Interface)
+	if !ok || observed.OperStatus == "" {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "No observed state for this interface yet",
+		})
+		return
+	}
+
+	desired := config.OperToDesiredState(observed.OperStatus)
+	admin := ""
+	if ifCfg.AdminState != "" || (observed.AdminStatus != "" && observed.AdminStatus != "enabled") {
+		admin = config.AdminToDesiredState(observed.AdminStatus)
+	}
+
+	statePath, err := config.DeclaringFile(filepath.Dir(configPath), deviceName, req.Interface, "desired_state", "admin_state")
+	if err == nil {
+		err = config.UpdateInterfaceState(statePath, deviceName, req.Interface, desired, admin)
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("device", deviceName).Str("interface", req.Interface).Msg("Failed to adopt interface state")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	s.audit(r, "adopt_interface_state").
+		Str("device", deviceName).
+		Str("interface", req.Interface).
+		Str("old_desired_state", ifCfg.DesiredState).
+		Str("new_desired_state", desired).
+		Str("old_admin_state", ifCfg.AdminState).
+		Str("new_admin_state", admin).
+		Msg("Desired state updated to match observed state")
+
+	if _, err := s.reload(); err != nil {
+		s.logger.Error().Err(err).Msg("Config reload after adopt failed")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"error":   "desired-state.yaml updated but reload failed: " + err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":       true,
+		"device":        deviceName,
+		"interface":     req.Interface,
+		"desired_state": desired,
+		"admin_state":   admin,
+	})
+}
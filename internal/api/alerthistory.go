@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/netspec/netspec/internal/alerter"
+)
+
+// handleAlertHistoryAPI returns persisted alert lifecycle events, newest
+// first. Filter with device, alert_type, dedup_key, severity, since and
+// until (RFC 3339 times or durations back from now); limit caps the events
+// returned (max 1000).
+func (s *Server) handleAlertHistoryAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	since, err := parseSince(q.Get("since"), time.Now())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	until, err := parseUntil(q.Get("until"), time.Now())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := alerter.HistoryQuery{
+		Device:    q.Get("device"),
+		AlertType: q.Get("alert_type"),
+		DedupKey:  q.Get("dedup_key"),
+		Severity:  q.Get("severity"),
+		Since:     since,
+		Until:     until,
+	}
+	if v := q.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit < 0 {
+			writeJSONError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+	}
+
+	events, err := s.alertEngine.GetAlertHistory(query)
+	if errors.Is(err, alerter.ErrNoHistoryStore) {
+		writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to read alert history")
+		writeJSONError(w, http.StatusInternalServerError, "failed to read alert history")
+		return
+	}
+	if events == nil {
+		events = []alerter.HistoryRecord{}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"events": events,
+		"count":  len(events),
+	})
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/netspec/netspec/internal/alerter"
+	"github.com/netspec/netspec/internal/webui"
+)
+
+// defaultHistoryWindow is how far back the alert history page looks when
+// no since is given
+const defaultHistoryWindow = "24h"
+
+// AlertHistoryRow is one lifecycle event on the alert history page
+type AlertHistoryRow struct {
+	At            time.Time
+	Event         string
+	EventClass    string
+	Detail        string
+	AlertID       string
+	Device        string
+	Entity        string
+	AlertType     string
+	Severity      string
+	Message       string
+	TimeToResolve string
+	Resolved      bool
+}
+
+// AlertHistoryPageData holds data for the alert history page
+type AlertHistoryPageData struct {
+	Rows       []AlertHistoryRow
+	Devices    []string
+	Severities []string
+	AlertTypes []string
+	Device     string
+	Severity   string
+	AlertType  string
+	Since      string
+	Until      string
+	Error      string
+	NoStore    bool
+	Truncated  bool
+	Fired      int
+	Resolved   int
+	MeanTTR    string
+	Version    string
+	Commit     string
+	BuildDate  string
+}
+
+// handleAlertHistoryPage renders the /alerts page: persisted alert events,
+// newest first, filtered by device, severity, alert type and time range,
+// with how long each alert took to resolve
+func (s *Server) handleAlertHistoryPage(w http.ResponseWriter, r *http.Request) {
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	q := r.URL.Query()
+	data := AlertHistoryPageData{
+		Device:    q.Get("device"),
+		Severity:  q.Get("severity"),
+		AlertType: q.Get("alert_type"),
+		Since:     q.Get("since"),
+		Until:     q.Get("until"),
+	}
+	if !q.Has("since") {
+		data.Since = defaultHistoryWindow
+	}
+	if cfg != nil {
+		for name := range cfg.DesiredState.Devices {
+			data.Devices = append(data.Devices, name)
+		}
+		sort.Strings(data.Devices)
+		for _, level := range cfg.Alerts.SeverityLevels() {
+			data.Severities = append(data.Severities, level.Name)
+		}
+		if data.Severity != "" {
+			data.Severity = cfg.Alerts.NormalizeSeverity(data.Severity)
+		}
+	}
+
+	now := time.Now()
+	since, err := parseSince(data.Since, now)
+	if err == nil {
+		var until time.Time
+		if until, err = parseUntil(data.Until, now); err == nil {
+			s.fillAlertHistory(&data, alerter.HistoryQuery{
+				Device:    data.Device,
+				AlertType: data.AlertType,
+				Severity:  data.Severity,
+				Since:     since,
+				Until:     until,
+				Limit:     1000,
+			})
+		}
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+
+	s.versionMu.RLock()
+	data.Version, data.Commit, data.BuildDate = s.version, s.commit, s.buildDate
+	s.versionMu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webui.Templates.ExecuteTemplate(w, "alert-history", data); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to render alert history template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// fillAlertHistory queries the history store and lays out its records as
+// page rows. An alert's time to resolve is shown on each of its events:
+// measured when its resolution is in range, still counting while it fires.
+func (s *Server) fillAlertHistory(data *AlertHistoryPageData, query alerter.HistoryQuery) {
+	records, err := s.alertEngine.GetAlertHistory(query)
+	if errors.Is(err, alerter.ErrNoHistoryStore) {
+		data.NoStore = true
+		return
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to read alert history")
+		data.Error = "failed to read alert history"
+		return
+	}
+	data.Truncated = len(records) >= query.Limit
+
+	resolvedIn := make(map[string]time.Duration)
+	for _, rec := range records {
+		if rec.Event == "resolved" && rec.Alert.ResolvedAt != nil {
+			resolvedIn[rec.Alert.ID] = rec.Alert.ResolvedAt.Sub(rec.Alert.FiredAt)
+		}
+	}
+	firing := make(map[string]time.Time)
+	for _, alert := range s.alertEngine.GetActiveAlerts() {
+		firing[alert.ID] = alert.FiredAt
+	}
+
+	seen := make(map[string]bool)
+	var total time.Duration
+	for _, rec := range records {
+		row := AlertHistoryRow{
+			At:         rec.At,
+			Event:      rec.Event,
+			EventClass: historyEventClass(rec.Event),
+			Detail:     rec.Detail,
+			AlertID:    rec.Alert.ID,
+			Device:     rec.Alert.Device,
+			Entity:     rec.Alert.Entity,
+			AlertType:  rec.Alert.AlertType,
+			Severity:   rec.Alert.Severity,
+			Message:    rec.Alert.Message,
+		}
+		if d, ok := resolvedIn[rec.Alert.ID]; ok {
+			row.TimeToResolve = formatDuration(d)
+			row.Resolved = true
+		} else if firedAt, ok := firing[rec.Alert.ID]; ok {
+			row.TimeToResolve = "firing " + formatDuration(time.Since(firedAt))
+		}
+		switch rec.Event {
+		case "fired":
+			data.Fired++
+		case "resolved":
+			if row.Resolved {
+				data.Resolved++
+				total += resolvedIn[rec.Alert.ID]
+			}
+		}
+		seen[rec.Alert.AlertType] = true
+		data.Rows = append(data.Rows, row)
+	}
+	if data.Resolved > 0 {
+		data.MeanTTR = formatDuration(total / time.Duration(data.Resolved))
+	}
+
+	for t := range seen {
+		data.AlertTypes = append(data.AlertTypes, t)
+	}
+	sort.Strings(data.AlertTypes)
+}
+
+// historyEventClass returns the state pill class for a lifecycle event
+func historyEventClass(event string) string {
+	switch event {
+	case "fired":
+		return "bad"
+	case "resolved":
+		return "good"
+	case "escalated":
+		return "warning"
+	}
+	return "info"
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/alerter"
+	"github.com/netspec/netspec/internal/types"
+	"github.com/netspec/netspec/internal/webui"
+)
+
+// RelatedRow is one row of an alert's related state: an expected and
+// actual pair, a member or device list, a timestamp or a plain value
+type RelatedRow struct {
+	Label    string
+	Expected string
+	Actual   string
+	Paired   bool
+	Members  []string
+	Time     *time.Time
+	Value    string
+}
+
+// AlertPageData holds data for the alert detail page
+type AlertPageData struct {
+	Alert         types.Alert
+	SeverityColor string
+	Duration      string
+	Related       []RelatedRow
+	Asset         []RelatedRow
+	History       []alerter.HistoryEntry
+	IsInterface   bool // the entity is a declared interface of the device
+	ObservedOper  string
+	ObservedAdmin string
+	Version       string
+	Commit        string
+	BuildDate     string
+}
+
+// handleAlertPage renders /alert/{id}: an active or recently resolved
+// alert with its related state and timeline. ?format=json returns the
+// alert and timeline as JSON.
+func (s *Server) handleAlertPage(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/alert/")
+	alert, history, ok := s.alertEngine.GetAlert(id)
+	if id == "" || !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"alert":   alert,
+			"history": history,
+		})
+		return
+	}
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	end := time.Now()
+	if alert.ResolvedAt != nil {
+		end = *alert.ResolvedAt
+	}
+	data := AlertPageData{
+		Alert:    alert,
+		Duration: formatDuration(end.Sub(alert.FiredAt)),
+		Related:  relatedRows(alert.RelatedState),
+		Asset:    relatedRows(alert.Asset),
+		History:  history,
+	}
+	if cfg != nil {
+		data.SeverityColor = cfg.Alerts.SeverityColor(alert.Severity)
+		_, data.IsInterface = cfg.DesiredState.Devices[alert.Device].Interfaces[alert.Entity]
+	}
+	if data.IsInterface && s.evaluator != nil {
+		if observed, ok := s.evaluator.GetInterfaceState(alert.Device, alert.Entity); ok {
+			data.ObservedOper = observed.OperStatus
+			data.ObservedAdmin = observed.AdminStatus
+		}
+	}
+	s.versionMu.RLock()
+	data.Version, data.Commit, data.BuildDate = s.version, s.commit, s.buildDate
+	s.versionMu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webui.Templates.ExecuteTemplate(w, "alert", data); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to render alert template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// relatedRows lays out an alert's related state for display. Keys named
+// expected_X and actual_X become one row comparing the two, the
+// comma-separated values of member and device keys lists and RFC 3339
+// values timestamps.
+func relatedRows(related map[string]string) []RelatedRow {
+	keys := make([]string, 0, len(related))
+	for key := range related {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rows []RelatedRow
+	for _, key := range keys {
+		value := related[key]
+		if suffix, ok := strings.CutPrefix(key, "expected_"); ok {
+			rows = append(rows, RelatedRow{Label: relatedLabel(suffix), Expected: value, Actual: related["actual_"+suffix], Paired: true})
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(key, "actual_"); ok {
+			if _, paired := related["expected_"+suffix]; paired {
+				continue
+			}
+		}
+		row := RelatedRow{Label: relatedLabel(key), Value: value}
+		if _, err := strconv.Atoi(value); (strings.HasSuffix(key, "members") || strings.HasSuffix(key, "_devices")) && value != "" && err != nil {
+			row.Members = strings.Split(value, ",")
+		} else if at, err := time.Parse(time.RFC3339, value); err == nil {
+			row.Time = &at
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// relatedLabel turns a related state key such as down_members into a label
+func relatedLabel(key string) string {
+	label := strings.ReplaceAll(key, "_", " ")
+	if label == "" {
+		return key
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
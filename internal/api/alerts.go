@@ -0,0 +1,232 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// maxAlertsLimit caps the page size of GET /alerts
+const maxAlertsLimit = 1000
+
+// alertFilter selects alerts by device, site, severity, alert type, state
+// and fire time. Empty fields match everything; list fields match any of
+// their values.
+type alertFilter struct {
+	Devices    []string
+	Sites      []string
+	Severities []string
+	AlertTypes []string
+	State      string    // firing, acknowledged or unacknowledged
+	Since      time.Time // fired at or after
+}
+
+// parseAlertFilter reads a filter from query parameters. device, site,
+// severity and alert_type take comma-separated lists; since takes an RFC 3339
+// time or a duration back from now (e.g. 2h).
+func parseAlertFilter(q url.Values, now time.Time) (alertFilter, error) {
+	f := alertFilter{
+		Devices:    splitList(q.Get("device")),
+		Sites:      splitList(q.Get("site")),
+		Severities: splitList(q.Get("severity")),
+		AlertTypes: splitList(q.Get("alert_type")),
+		State:      q.Get("state"),
+	}
+	if err := f.validate(); err != nil {
+		return f, err
+	}
+	since, err := parseSince(q.Get("since"), now)
+	if err != nil {
+		return f, err
+	}
+	f.Since = since
+	return f, nil
+}
+
+// parseSince parses an RFC 3339 time or a duration back from now. An empty
+// value yields the zero time.
+func parseSince(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("since must be an RFC 3339 time or a duration")
+}
+
+// parseUntil reads an until parameter the same way as parseSince
+func parseUntil(v string, now time.Time) (time.Time, error) {
+	t, err := parseSince(v, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("until must be an RFC 3339 time or a duration")
+	}
+	return t, nil
+}
+
+// validate checks the state value
+func (f alertFilter) validate() error {
+	switch f.State {
+	case "", "firing", "acknowledged", "unacknowledged":
+		return nil
+	}
+	return fmt.Errorf("state must be firing, acknowledged or unacknowledged")
+}
+
+// matches reports whether an alert passes the filter. cfg resolves device
+// sites and may be nil, in which case a site filter matches nothing.
+func (f alertFilter) matches(alert *types.Alert, cfg *config.Config) bool {
+	if len(f.Devices) > 0 && !contains(f.Devices, alert.Device) {
+		return false
+	}
+	if len(f.Sites) > 0 {
+		if cfg == nil || !contains(f.Sites, cfg.DesiredState.Devices[alert.Device].Site) {
+			return false
+		}
+	}
+	if len(f.Severities) > 0 && !f.matchesSeverity(alert.Severity, cfg) {
+		return false
+	}
+	if len(f.AlertTypes) > 0 && !contains(f.AlertTypes, alert.AlertType) {
+		return false
+	}
+	switch f.State {
+	case "firing":
+		if alert.State != "firing" {
+			return false
+		}
+	case "acknowledged":
+		if !alert.Acknowledged {
+			return false
+		}
+	case "unacknowledged":
+		if alert.Acknowledged {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && alert.FiredAt.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+// matchesSeverity reports whether a severity is one of the filter's,
+// comparing both through the configured levels so that an alias such as
+// crit matches critical either way round. Without cfg names must match
+// exactly.
+func (f alertFilter) matchesSeverity(severity string, cfg *config.Config) bool {
+	if cfg == nil {
+		return contains(f.Severities, severity)
+	}
+	severity = cfg.Alerts.NormalizeSeverity(severity)
+	for _, s := range f.Severities {
+		if cfg.Alerts.NormalizeSeverity(s) == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// empty reports whether the filter matches every alert
+func (f alertFilter) empty() bool {
+	return len(f.Devices) == 0 && len(f.Sites) == 0 && len(f.Severities) == 0 &&
+		len(f.AlertTypes) == 0 && f.State == "" && f.Since.IsZero()
+}
+
+// sortAlerts orders alerts by fired_at, severity, device or alert_type; a
+// leading "-" reverses the order. Severity sorts most severe first. Ties
+// fall back to newest first, then dedup key, so pages are stable.
+func sortAlerts(alerts []*types.Alert, order string, cfg *config.Config) error {
+	desc := strings.HasPrefix(order, "-")
+	field := strings.TrimPrefix(order, "-")
+
+	var less func(a, b *types.Alert) bool
+	switch field {
+	case "fired_at":
+		less = func(a, b *types.Alert) bool { return a.FiredAt.Before(b.FiredAt) }
+	case "severity":
+		rank := func(severity string) int {
+			if cfg == nil {
+				return 0
+			}
+			return cfg.Alerts.SeverityRank(severity)
+		}
+		less = func(a, b *types.Alert) bool { return rank(a.Severity) < rank(b.Severity) }
+	case "device":
+		less = func(a, b *types.Alert) bool { return a.Device < b.Device }
+	case "alert_type":
+		less = func(a, b *types.Alert) bool { return a.AlertType < b.AlertType }
+	default:
+		return fmt.Errorf("sort must be one of fired_at, severity, device, alert_type (prefix - to reverse)")
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		a, b := alerts[i], alerts[j]
+		if desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		if !alerts[i].FiredAt.Equal(alerts[j].FiredAt) {
+			return alerts[i].FiredAt.After(alerts[j].FiredAt)
+		}
+		return alerts[i].DedupKey < alerts[j].DedupKey
+	})
+	return nil
+}
+
+// parsePage reads limit and offset query parameters. Without a limit
+// parameter limit is 0, meaning every alert, as before paging was added; a
+// given limit of 0 or above maxAlertsLimit is maxAlertsLimit.
+func parsePage(q url.Values) (limit, offset int, err error) {
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return 0, 0, fmt.Errorf("limit must be a non-negative integer")
+		}
+		if limit == 0 || limit > maxAlertsLimit {
+			limit = maxAlertsLimit
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+	return limit, offset, nil
+}
+
+// splitList splits a comma-separated query value, dropping empty items
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// contains reports whether s is in list
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+)
+
+func TestParseAlertFilter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		query   string
+		want    alertFilter
+		wantErr bool
+	}{
+		{name: "empty", query: "", want: alertFilter{}},
+		{
+			name:  "lists",
+			query: "device=sw1,%20sw2,&site=building-a&severity=critical,warning&alert_type=interface_state_mismatch",
+			want: alertFilter{
+				Devices:    []string{"sw1", "sw2"},
+				Sites:      []string{"building-a"},
+				Severities: []string{"critical", "warning"},
+				AlertTypes: []string{"interface_state_mismatch"},
+			},
+		},
+		{name: "state", query: "state=unacknowledged", want: alertFilter{State: "unacknowledged"}},
+		{name: "unknown state", query: "state=open", wantErr: true},
+		{name: "since duration", query: "since=2h", want: alertFilter{Since: now.Add(-2 * time.Hour)}},
+		{
+			name:  "since time",
+			query: "since=2025-12-31T08:00:00Z",
+			want:  alertFilter{Since: time.Date(2025, 12, 31, 8, 0, 0, 0, time.UTC)},
+		},
+		{name: "bad since", query: "since=yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseAlertFilter(q, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertFilterMatchesSeverityAliases(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Alerts.Severities = []config.SeverityLevel{
+		{Name: "p1", Aliases: []string{"critical"}},
+		{Name: "p2", Aliases: []string{"warning"}},
+	}
+	tests := []struct {
+		filter   []string
+		severity string
+		want     bool
+	}{
+		{[]string{"p1"}, "critical", true},
+		{[]string{"critical"}, "p1", true},
+		{[]string{"CRITICAL"}, "p1", true},
+		{[]string{"p2"}, "critical", false},
+		{[]string{"unknown"}, "unknown", true},
+	}
+	for _, tt := range tests {
+		f := alertFilter{Severities: tt.filter}
+		if got := f.matches(&types.Alert{Severity: tt.severity}, cfg); got != tt.want {
+			t.Errorf("filter %v on %q: got %v, want %v", tt.filter, tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestSortAlerts(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	alerts := func() []*types.Alert {
+		return []*types.Alert{
+			{DedupKey: "a", Device: "sw2", AlertType: "port_channel_down", Severity: "warning", FiredAt: base},
+			{DedupKey: "b", Device: "sw1", AlertType: "interface_state_mismatch", Severity: "critical", FiredAt: base.Add(time.Minute)},
+			{DedupKey: "c", Device: "sw3", AlertType: "interface_admin_down", Severity: "info", FiredAt: base.Add(2 * time.Minute)},
+			{DedupKey: "d", Device: "sw1", AlertType: "port_channel_down", Severity: "critical", FiredAt: base.Add(time.Minute)},
+		}
+	}
+	tests := []struct {
+		order   string
+		want    []string
+		wantErr bool
+	}{
+		{order: "fired_at", want: []string{"a", "b", "d", "c"}},
+		{order: "-fired_at", want: []string{"c", "b", "d", "a"}},
+		// Ties fall back to newest first, then dedup key
+		{order: "severity", want: []string{"b", "d", "a", "c"}},
+		{order: "-severity", want: []string{"c", "a", "b", "d"}},
+		{order: "device", want: []string{"b", "d", "a", "c"}},
+		{order: "alert_type", want: []string{"c", "b", "d", "a"}},
+		{order: "updated_at", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			list := alerts()
+			err := sortAlerts(list, tt.order, &config.Config{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, alert := range list {
+				got = append(got, alert.DedupKey)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		query   string
+		limit   int
+		offset  int
+		wantErr bool
+	}{
+		{query: "", limit: 0, offset: 0},
+		{query: "limit=50&offset=100", limit: 50, offset: 100},
+		{query: "limit=0", limit: maxAlertsLimit},
+		{query: "limit=5000", limit: maxAlertsLimit},
+		{query: "limit=-1", wantErr: true},
+		{query: "limit=ten", wantErr: true},
+		{query: "offset=-5", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			limit, offset, err := parsePage(q)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got limit %d offset %d, want an error", limit, offset)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if limit != tt.limit || offset != tt.offset {
+				t.Errorf("got limit %d offset %d, want %d %d", limit, offset, tt.limit, tt.offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/netspec/netspec/internal/alerter"
+)
+
+// bulkRequest is the body accepted by /api/alerts/bulk
+type bulkRequest struct {
+	Action string `json:"action"` // "ack", "silence" or "resolve"
+	Filter struct {
+		Device    []string `json:"device,omitempty"`
+		Site      []string `json:"site,omitempty"`
+		Severity  []string `json:"severity,omitempty"`
+		AlertType []string `json:"alert_type,omitempty"`
+		State     string   `json:"state,omitempty"`
+		Since     string   `json:"since,omitempty"` // RFC 3339 time or duration back from now
+	} `json:"filter"`
+	All      bool   `json:"all,omitempty"`      // required to act on every active alert with an empty filter
+	Duration string `json:"duration,omitempty"` // silence duration, e.g. "2h" or "1d"
+	By       string `json:"by,omitempty"`
+	DryRun   bool   `json:"dry_run,omitempty"` // report what would be affected without acting
+}
+
+// handleBulkAlerts acknowledges, silences or resolves every active alert
+// matching a filter in one call. Silences apply per device, so silencing
+// covers every alert on each matched alert's device for the duration.
+func (s *Server) handleBulkAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var req bulkRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundBody)).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	now := time.Now()
+	since, err := parseSince(req.Filter.Since, now)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter := alertFilter{
+		Devices:    req.Filter.Device,
+		Sites:      req.Filter.Site,
+		Severities: req.Filter.Severity,
+		AlertTypes: req.Filter.AlertType,
+		State:      req.Filter.State,
+		Since:      since,
+	}
+	if err := filter.validate(); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.empty() && !req.All {
+		writeJSONError(w, http.StatusBadRequest, "filter is empty; set \"all\": true to act on every active alert")
+		return
+	}
+
+	var duration time.Duration
+	switch req.Action {
+	case "ack", "acknowledge", "resolve":
+	case "silence":
+		duration, err = parseChatDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "silence requires a positive duration, e.g. \"2h\"")
+			return
+		}
+		if duration > maxSilenceDuration {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("silences are limited to %s", formatDuration(maxSilenceDuration)))
+			return
+		}
+	default:
+		writeJSONError(w, http.StatusBadRequest, "action must be 'ack', 'silence' or 'resolve'")
+		return
+	}
+
+	by := req.By
+	if by == "" {
+		by = "api"
+	}
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	var keys []string
+	devices := make(map[string]bool)
+	for _, alert := range s.alertEngine.GetActiveAlerts() {
+		if filter.matches(alert, cfg) {
+			keys = append(keys, alert.DedupKey)
+			devices[alert.Device] = true
+		}
+	}
+	sort.Strings(keys)
+
+	response := map[string]interface{}{
+		"success":    true,
+		"action":     req.Action,
+		"dry_run":    req.DryRun,
+		"matched":    len(keys),
+		"dedup_keys": keys,
+	}
+	if req.DryRun {
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// Alerts can resolve between matching and acting, so count what changed
+	updated := 0
+	switch req.Action {
+	case "ack", "acknowledge":
+		for _, key := range keys {
+			if _, ok := s.alertEngine.Acknowledge(key, by); ok {
+				updated++
+			}
+		}
+	case "resolve":
+		for _, key := range keys {
+			if _, ok := s.alertEngine.ResolveByKey(key, by); ok {
+				updated++
+			}
+		}
+	case "silence":
+		names := make([]string, 0, len(devices))
+		for device := range devices {
+			names = append(names, device)
+		}
+		sort.Strings(names)
+		silences := make([]alerter.Silence, 0, len(names))
+		for _, device := range names {
+			silences = append(silences, s.alertEngine.AddSilence(alerter.SilenceMatchers{Device: device}, duration, by))
+		}
+		updated = len(keys)
+		response["silences"] = silences
+	}
+	response["updated"] = updated
+
+	s.audit(r, "bulk_"+req.Action).
+		Int("matched", len(keys)).
+		Int("updated", updated).
+		Str("by", by).
+		Msg("Bulk alert operation")
+
+	json.NewEncoder(w).Encode(response)
+}
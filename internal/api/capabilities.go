@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/netspec/netspec/internal/capabilities"
+)
+
+// handleDeviceCapabilitiesAPI returns the gNMI capabilities last read from
+// a device and the changes recorded between refreshes, newest first
+func (s *Server) handleDeviceCapabilitiesAPI(w http.ResponseWriter, r *http.Request, deviceName string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if s.capabilities == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "capabilities refresh not available")
+		return
+	}
+
+	snap, changes, ok := s.capabilities.Device(deviceName)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "no capabilities read for device "+deviceName)
+		return
+	}
+	if changes == nil {
+		changes = []capabilities.Change{}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"device":       deviceName,
+		"capabilities": snap,
+		"changes":      changes,
+	})
+}
+
+// deviceCapabilities returns a device's last capabilities read and its most
+// recent change, if any
+func (s *Server) deviceCapabilities(device string) (*capabilities.Snapshot, *capabilities.Change) {
+	if s.capabilities == nil {
+		return nil, nil
+	}
+	snap, changes, ok := s.capabilities.Device(device)
+	if !ok {
+		return nil, nil
+	}
+	if len(changes) == 0 {
+		return &snap, nil
+	}
+	return &snap, &changes[0]
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/netspec/netspec/internal/capacity"
+	"github.com/netspec/netspec/internal/webui"
+)
+
+// CapacityPageData holds data for the capacity page
+type CapacityPageData struct {
+	Forecasts  []capacity.Forecast
+	Advisories int
+	Version    string
+	Commit     string
+	BuildDate  string
+}
+
+// handleCapacityAPI returns the capacity trend of every tracked uplink as
+// JSON
+func (s *Server) handleCapacityAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	forecasts, advisories := s.capacityForecasts()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"forecasts":  forecasts,
+		"count":      len(forecasts),
+		"advisories": advisories,
+	})
+}
+
+// handleCapacityPage renders the capacity page
+func (s *Server) handleCapacityPage(w http.ResponseWriter, r *http.Request) {
+	forecasts, advisories := s.capacityForecasts()
+
+	s.versionMu.RLock()
+	data := CapacityPageData{
+		Forecasts:  forecasts,
+		Advisories: advisories,
+		Version:    s.version,
+		Commit:     s.commit,
+		BuildDate:  s.buildDate,
+	}
+	s.versionMu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webui.Templates.ExecuteTemplate(w, "capacity", data); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to render capacity template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// capacityForecasts returns the tracker's forecasts and how many of them
+// carry an advisory
+func (s *Server) capacityForecasts() ([]capacity.Forecast, int) {
+	if s.capacity == nil {
+		return []capacity.Forecast{}, 0
+	}
+	forecasts := s.capacity.Forecasts()
+	advisories := 0
+	for _, f := range forecasts {
+		if f.Advisory {
+			advisories++
+		}
+	}
+	return forecasts, advisories
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/notifier"
+)
+
+// maxCalendarBody bounds the size of calendar webhook requests
+const maxCalendarBody = 1 << 20
+
+// calendarWebhookRequest is the JSON body accepted by
+// /api/webhooks/calendar
+type calendarWebhookRequest struct {
+	Events []config.ChangeEvent `json:"events"`
+}
+
+// handleCalendarWebhook takes changes pushed by the change calendar, as
+// {"events": [...]} or an iCal document, and creates maintenance windows
+// for them; a change with "cancelled": true (STATUS:CANCELLED) removes its
+// window. Requests must be signed with the change_calendar
+// webhook_secret_env secret, without which the webhook is disabled.
+func (s *Server) handleCalendarWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	var calendar *config.ChangeCalendarConfig
+	if cfg != nil {
+		calendar = cfg.Maintenance.ChangeCalendar
+	}
+	if calendar == nil || calendar.WebhookSecretEnv == "" || s.changeCalendar == nil {
+		writeJSONError(w, http.StatusNotFound, "calendar webhook is not enabled")
+		return
+	}
+	secret := os.Getenv(calendar.WebhookSecretEnv)
+	if secret == "" {
+		writeJSONError(w, http.StatusNotFound, "calendar webhook is not enabled")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCalendarBody))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+	expected := notifier.Sign(secret, body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(notifier.SignatureHeader))) {
+		writeJSONError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	var events []config.ChangeEvent
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/calendar") ||
+		bytes.HasPrefix(bytes.TrimSpace(body), []byte("BEGIN:VCALENDAR")) {
+		events, err = config.ParseChangeEvents(body)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid iCal: "+err.Error())
+			return
+		}
+	} else {
+		var req calendarWebhookRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		events = req.Events
+	}
+	if len(events) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "no events")
+		return
+	}
+	for _, ev := range events {
+		if err := ev.Validate(); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	now := time.Now()
+	created := s.changeCalendar.Push(events)
+	names := make([]string, 0, len(created))
+	windows := make([]maintenanceWindowInfo, 0, len(created))
+	for _, mw := range created {
+		names = append(names, mw.Name)
+		windows = append(windows, windowInfo(mw, "change_calendar", now))
+	}
+
+	s.audit(r, "calendar_webhook").
+		Int("events", len(events)).
+		Strs("windows", names).
+		Msg("Maintenance updated by change calendar webhook")
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"events":  len(events),
+		"windows": windows,
+	})
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/alerter"
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+)
+
+const (
+	// maxSilenceDuration bounds silences created from chat
+	maxSilenceDuration = 7 * 24 * time.Hour
+	// slackRequestMaxAge rejects replayed Slack requests
+	slackRequestMaxAge = 5 * time.Minute
+)
+
+// chatOpsUsage is returned for help and unknown commands
+const chatOpsUsage = "Usage:\n" +
+	"• `/netspec ack <alert id>` - acknowledge an active alert\n" +
+	"• `/netspec silence <device> <duration>` - silence a device's alerts, e.g. `2h` or `1d`\n" +
+	"• `/netspec status <device>` - show interfaces, deviations and alerts for a device"
+
+// chatOpsResponse is the reply format shared by Slack and Mattermost
+type chatOpsResponse struct {
+	ResponseType string `json:"response_type"` // "in_channel" or "ephemeral"
+	Text         string `json:"text"`
+}
+
+// handleChatOpsCommand implements Slack and Mattermost slash commands so
+// alerts can be acknowledged, devices silenced and status checked from chat
+func (s *Server) handleChatOpsCommand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	if cfg == nil || (cfg.Alerts.ChatOps.TokenEnv == "" && cfg.Alerts.ChatOps.SlackSigningSecretEnv == "") {
+		http.Error(w, "ChatOps not configured", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInboundBody))
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid form body", http.StatusBadRequest)
+		return
+	}
+	if !verifyChatOpsRequest(cfg.Alerts.ChatOps, r.Header, body, form.Get("token")) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	user := form.Get("user_name")
+	if user == "" {
+		user = "chatops"
+	}
+	args := strings.Fields(form.Get("text"))
+
+	var resp chatOpsResponse
+	if len(args) == 0 {
+		resp = ephemeral(chatOpsUsage)
+	} else {
+		switch strings.ToLower(args[0]) {
+		case "ack":
+			resp = s.chatOpsAck(r, args[1:], user)
+		case "silence":
+			resp = s.chatOpsSilence(r, cfg, args[1:], user)
+		case "status":
+			resp = s.chatOpsStatus(cfg, args[1:])
+		default:
+			resp = ephemeral(chatOpsUsage)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// chatOpsAck acknowledges an alert by ID or dedup key
+func (s *Server) chatOpsAck(r *http.Request, args []string, user string) chatOpsResponse {
+	if len(args) != 1 {
+		return ephemeral("Usage: `/netspec ack <alert id>`")
+	}
+	id := args[0]
+
+	dedupKey := ""
+	for _, alert := range s.alertEngine.GetActiveAlerts() {
+		if alert.ID == id || alert.DedupKey == id {
+			dedupKey = alert.DedupKey
+			break
+		}
+	}
+	if dedupKey == "" {
+		return ephemeral(fmt.Sprintf("No active alert with id `%s`", id))
+	}
+	alert, ok := s.alertEngine.Acknowledge(dedupKey, user)
+	if !ok {
+		return ephemeral(fmt.Sprintf("No active alert with id `%s`", id))
+	}
+
+	s.audit(r, "chatops_ack").
+		Str("dedup_key", dedupKey).
+		Str("user", user).
+		Msg("Alert acknowledged from chat")
+
+	return inChannel(fmt.Sprintf("✔ %s acknowledged %s %s: %s", user, alert.Device, alert.Entity, alert.Message))
+}
+
+// chatOpsSilence silences a device for a duration
+func (s *Server) chatOpsSilence(r *http.Request, cfg *config.Config, args []string, user string) chatOpsResponse {
+	if len(args) != 2 {
+		return ephemeral("Usage: `/netspec silence <device> <duration>`")
+	}
+	device := args[0]
+	if _, ok := cfg.DesiredState.Devices[device]; !ok {
+		return ephemeral(fmt.Sprintf("Unknown device `%s`", device))
+	}
+	duration, err := parseChatDuration(args[1])
+	if err != nil || duration <= 0 {
+		return ephemeral(fmt.Sprintf("Invalid duration `%s`, use e.g. `30m`, `2h` or `1d`", args[1]))
+	}
+	if duration > maxSilenceDuration {
+		return ephemeral(fmt.Sprintf("Silences are limited to %s", formatDuration(maxSilenceDuration)))
+	}
+
+	silence := s.alertEngine.AddSilence(alerter.SilenceMatchers{Device: device}, duration, user)
+
+	s.audit(r, "chatops_silence").
+		Str("device", device).
+		Dur("duration", duration).
+		Str("user", user).
+		Msg("Device silenced from chat")
+
+	return inChannel(fmt.Sprintf("🔕 %s silenced %s for %s (until %s)",
+		user, device, formatDuration(duration), silence.ExpiresAt.Format("2006-01-02 15:04 MST")))
+}
+
+// chatOpsStatus summarizes a device's interfaces, deviations and alerts
+func (s *Server) chatOpsStatus(cfg *config.Config, args []string) chatOpsResponse {
+	if len(args) != 1 {
+		return ephemeral("Usage: `/netspec status <device>`")
+	}
+	device := args[0]
+	dev, ok := cfg.DesiredState.Devices[device]
+	if !ok {
+		return ephemeral(fmt.Sprintf("Unknown device `%s`", device))
+	}
+
+	var alerts []*types.Alert
+	for _, alert := range s.alertEngine.GetActiveAlerts() {
+		if alert.Device == device {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s* (%s)", device, dev.Address)
+	if dev.Description != "" {
+		fmt.Fprintf(&b, " - %s", dev.Description)
+	}
+	fmt.Fprintf(&b, "\n%d interfaces monitored", len(dev.Interfaces))
+
+	if s.evaluator != nil {
+		var lines []string
+		for _, d := range s.evaluator.GetDeviations() {
+			if d.Device == device {
+				lines = append(lines, fmt.Sprintf("• %s: expected %s, actual %s (for %s)",
+					d.Interface, d.ExpectedOper, d.ActualOper, formatDuration(time.Since(d.Since))))
+			}
+		}
+		fmt.Fprintf(&b, ", %d deviating", len(lines))
+		for _, line := range lines {
+			b.WriteString("\n" + line)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n%d active alerts", len(alerts))
+	for _, alert := range alerts {
+		ack := ""
+		if alert.Acknowledged {
+			ack = " ✔ acked by " + alert.AcknowledgedBy
+		}
+		fmt.Fprintf(&b, "\n• [%s] %s: %s (`%s`)%s", alert.Severity, alert.Entity, alert.Message, alert.ID, ack)
+	}
+	for _, silence := range s.alertEngine.GetSilences() {
+		if silence.Device == device {
+			fmt.Fprintf(&b, "\n🔕 Silenced by %s until %s", silence.CreatedBy, silence.ExpiresAt.Format("2006-01-02 15:04 MST"))
+		}
+	}
+
+	return ephemeral(b.String())
+}
+
+// verifyChatOpsRequest checks a Slack request signature when a signing
+// secret is configured and the request is signed, otherwise the form token
+func verifyChatOpsRequest(cfg config.ChatOpsConfig, header http.Header, body []byte, token string) bool {
+	if cfg.SlackSigningSecretEnv != "" && header.Get("X-Slack-Signature") != "" {
+		secret := os.Getenv(cfg.SlackSigningSecretEnv)
+		ts := header.Get("X-Slack-Request-Timestamp")
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if secret == "" || err != nil {
+			return false
+		}
+		if age := time.Since(time.Unix(sec, 0)); age > slackRequestMaxAge || age < -slackRequestMaxAge {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + ts + ":"))
+		mac.Write(body)
+		expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature")))
+	}
+	if cfg.TokenEnv != "" {
+		expected := os.Getenv(cfg.TokenEnv)
+		return expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+	}
+	return false
+}
+
+// parseChatDuration parses a Go duration, additionally accepting whole days
+// such as "1d"
+func parseChatDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// ephemeral returns a reply only the invoking user sees
+func ephemeral(text string) chatOpsResponse {
+	return chatOpsResponse{ResponseType: "ephemeral", Text: text}
+}
+
+// inChannel returns a reply visible to the whole channel
+func inChannel(text string) chatOpsResponse {
+	return chatOpsResponse{ResponseType: "in_channel", Text: text}
+}
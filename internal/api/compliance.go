@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/netspec/netspec/internal/evaluator"
+)
+
+// handleDeviceInterfacesAPI returns desired against observed oper and admin
+// state for every monitored interface of a device, so tooling can check
+// compliance without reading alerts
+func (s *Server) handleDeviceInterfacesAPI(w http.ResponseWriter, r *http.Request, deviceName string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if s.evaluator == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "evaluator not available")
+		return
+	}
+
+	interfaces, ok := s.evaluator.GetDeviceCompliance(deviceName)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	deviating := 0
+	for _, iface := range interfaces {
+		if iface.Status == evaluator.ComplianceDeviating {
+			deviating++
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"device":     deviceName,
+		"interfaces": interfaces,
+		"count":      len(interfaces),
+		"deviating":  deviating,
+	})
+}
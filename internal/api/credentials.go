@@ -0,0 +1,257 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/redact"
+)
+
+// Rollouts of updated credentials to the collectors using them
+const (
+	rolloutNextReconnect = "next_reconnect" // sessions switch when they next reconnect
+	rolloutRolling       = "rolling"        // connected sessions reconnect one at a time
+)
+
+// defaultRolloutInterval spaces rolling reconnects when no interval is given
+const defaultRolloutInterval = 10 * time.Second
+
+// rolloutReconnectTimeout is how long a rolling rollout waits, after the
+// interval, for a reconnected device to come back before stopping
+const rolloutReconnectTimeout = time.Minute
+
+// credentialUpdate is the body of POST /api/credentials/{name}
+type credentialUpdate struct {
+	Password string `json:"password,omitempty"`
+	Rollout  string `json:"rollout,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+// SetDefaultCredentials sets the username and password used where a
+// credential entry declares none, as taken from GNMI_USERNAME and
+// GNMI_PASSWORD at startup
+func (s *Server) SetDefaultCredentials(username, password string) {
+	s.credentialsMu.Lock()
+	defer s.credentialsMu.Unlock()
+	s.defaultUsername = username
+	s.defaultPassword = password
+}
+
+// handleCredentialsAPI updates a credential entry without a reload. A new
+// password is stored at the entry's source (its password_env in this
+// process, else its password_file); without one the sources are read
+// again, as after a mounted secret was rotated. The collectors of every
+// device using the entry are handed the result and switch to it on their
+// next reconnect, or with rollout "rolling" are reconnected one at a time,
+// interval apart, so a rotation never drops every session at once. Updates
+// require the bearer token named by update_token_env in credentials.yaml,
+// and are refused when none is configured.
+func (s *Server) handleCredentialsAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	if cfg == nil {
+		writeJSONError(w, http.StatusInternalServerError, "configuration not loaded")
+		return
+	}
+	token := ""
+	if cfg.Credentials.UpdateTokenEnv != "" {
+		token = os.Getenv(cfg.Credentials.UpdateTokenEnv)
+	}
+	if token == "" {
+		writeJSONError(w, http.StatusForbidden, "credential updates are disabled: set update_token_env in credentials.yaml")
+		return
+	}
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		writeJSONError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/api/credentials/")
+	var req credentialUpdate
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundBody)).Decode(&req); err != nil && err != io.EOF {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Rollout == "" {
+		req.Rollout = rolloutNextReconnect
+	}
+	if req.Rollout != rolloutNextReconnect && req.Rollout != rolloutRolling {
+		writeJSONError(w, http.StatusBadRequest, "rollout must be next_reconnect or rolling")
+		return
+	}
+	interval := defaultRolloutInterval
+	if req.Interval != "" {
+		d, err := time.ParseDuration(req.Interval)
+		if err != nil || d <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "interval must be a positive duration")
+			return
+		}
+		interval = d
+	}
+
+	entry, ok := cfg.Credentials.Credentials[name]
+	if name == "" || !ok {
+		writeJSONError(w, http.StatusNotFound, "credential not found")
+		return
+	}
+
+	persisted := false
+	if req.Password != "" {
+		redact.Register(req.Password)
+		if entry.PasswordEnv == "" && entry.PasswordFile == "" {
+			writeJSONError(w, http.StatusConflict, "credential has no password_env or password_file to update")
+			return
+		}
+		var err error
+		if persisted, err = entry.SetPassword(req.Password); err != nil {
+			s.logger.Error().Err(err).Str("credential", name).Msg("Failed to update credential password")
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	s.credentialsMu.Lock()
+	username, password := s.defaultUsername, s.defaultPassword
+	s.credentialsMu.Unlock()
+
+	// Resolve every device before handing anything out, so a credential
+	// that no longer resolves changes no session
+	type resolved struct{ username, password string }
+	devices := cfg.CredentialDevices(name)
+	secrets := make(map[string]resolved, len(devices))
+	for _, device := range devices {
+		u, p, err := cfg.DeviceCredentials(device, username, password)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "device "+device+": "+err.Error())
+			return
+		}
+		secrets[device] = resolved{u, p}
+	}
+
+	s.collectorMu.RLock()
+	getter := s.collectorGetter
+	s.collectorMu.RUnlock()
+	if getter != nil {
+		for _, device := range devices {
+			if col := getter(device); col != nil {
+				col.SetCredentials(secrets[device].username, secrets[device].password)
+			}
+		}
+	}
+
+	s.credentialsMu.Lock()
+	if cancel, ok := s.rollouts[name]; ok {
+		cancel()
+		delete(s.rollouts, name)
+	}
+	if req.Rollout == rolloutRolling && getter != nil && len(devices) > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		if s.rollouts == nil {
+			s.rollouts = make(map[string]context.CancelFunc)
+		}
+		s.rollouts[name] = cancel
+		go s.rollReconnects(ctx, name, devices, interval, getter)
+	}
+	s.credentialsMu.Unlock()
+
+	s.audit(r, "update_credentials").
+		Str("credential", name).
+		Bool("password_changed", req.Password != "").
+		Bool("persisted", persisted).
+		Str("rollout", req.Rollout).
+		Int("devices", len(devices)).
+		Msg("Credential updated")
+
+	resp := map[string]interface{}{
+		"success":    true,
+		"credential": name,
+		"devices":    devices,
+		"persisted":  persisted,
+		"rollout":    req.Rollout,
+	}
+	if req.Rollout == rolloutRolling {
+		resp["interval"] = interval.String()
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// rollReconnects reconnects the connected collectors of devices one at a
+// time, interval apart, until done or cancelled by a newer update of the
+// same credential. Each device must be back before the next is touched, so
+// a wrong password stops the rollout at the first device instead of locking
+// out all of them. Collectors are looked up as their turn comes, so ones
+// replaced by a reload meanwhile already use the new credentials.
+func (s *Server) rollReconnects(ctx context.Context, name string, devices []string, interval time.Duration, getter CollectorGetter) {
+	s.logger.Info().Str("credential", name).Int("devices", len(devices)).Dur("interval", interval).Msg("Rolling reconnects to apply credentials")
+	var previous string
+	var droppedAt time.Time
+	for _, device := range devices {
+		col := getter(device)
+		if col == nil {
+			continue
+		}
+		if previous != "" {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+			if !s.awaitReconnect(ctx, previous, droppedAt, getter) {
+				if ctx.Err() == nil {
+					s.logger.Error().Str("credential", name).Str("device", previous).Msg("Device did not reconnect with the updated credentials, stopping rolling reconnects")
+				}
+				return
+			}
+		}
+		previous, droppedAt = "", time.Now()
+		if col.Reconnect() {
+			previous = device
+		}
+	}
+	s.logger.Info().Str("credential", name).Msg("Rolling reconnects complete")
+
+	s.credentialsMu.Lock()
+	defer s.credentialsMu.Unlock()
+	if ctx.Err() == nil {
+		s.rollouts[name]()
+		delete(s.rollouts, name)
+	}
+}
+
+// awaitReconnect waits up to rolloutReconnectTimeout for a device's
+// collector to connect again after its session was dropped at droppedAt
+func (s *Server) awaitReconnect(ctx context.Context, device string, droppedAt time.Time, getter CollectorGetter) bool {
+	deadline := time.Now().Add(rolloutReconnectTimeout)
+	for {
+		col := getter(device)
+		if col == nil {
+			return true
+		}
+		if health := col.Health(); health.Connected && health.ConnectedSince.After(droppedAt) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(time.Second):
+		}
+	}
+}
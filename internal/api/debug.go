@@ -0,0 +1,130 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/netspec/netspec/internal/alerter"
+)
+
+// injectRequest is the body accepted by /api/debug/inject-state-change
+type injectRequest struct {
+	Device       string            `json:"device"`
+	Interface    string            `json:"interface"`
+	AlertType    string            `json:"alert_type,omitempty"` // default interface_state_mismatch
+	Severity     string            `json:"severity,omitempty"`   // default warning
+	Message      string            `json:"message,omitempty"`
+	RelatedState map[string]string `json:"related_state,omitempty"`
+	Resolve      bool              `json:"resolve,omitempty"` // resolve a previously injected alert instead of firing
+}
+
+// handleInjectStateChange pushes a synthetic state change into the alert
+// engine so routing, escalation and message templates can be verified
+// against the live configuration. It is disabled unless debug.inject_token_env
+// is configured, and requires that token as a bearer token. Injected alerts
+// are prefixed "[synthetic]" and carry related_state synthetic=true.
+func (s *Server) handleInjectStateChange(w http.ResponseWriter, r *http.Request) {
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	token := ""
+	if cfg != nil && cfg.Alerts.Debug.InjectTokenEnv != "" {
+		token = os.Getenv(cfg.Alerts.Debug.InjectTokenEnv)
+	}
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		writeJSONError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInboundBody))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+	var req injectRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Device == "" || req.Interface == "" {
+		writeJSONError(w, http.StatusBadRequest, "device and interface are required")
+		return
+	}
+	if _, ok := cfg.DesiredState.Devices[req.Device]; !ok {
+		writeJSONError(w, http.StatusBadRequest, "unknown device "+req.Device)
+		return
+	}
+	if req.AlertType == "" {
+		req.AlertType = "interface_state_mismatch"
+	}
+	if req.Severity == "" {
+		req.Severity = "warning"
+	}
+	if _, _, ok := cfg.Alerts.SeverityLevel(req.Severity); !ok {
+		writeJSONError(w, http.StatusBadRequest, "unknown severity "+req.Severity)
+		return
+	}
+	if req.Message == "" {
+		req.Message = fmt.Sprintf("interface %s %s test", req.Interface, req.AlertType)
+	}
+
+	related := map[string]string{"synthetic": "true"}
+	for k, v := range req.RelatedState {
+		related[k] = v
+	}
+
+	ev := alerter.AlertEvent{
+		Device:    req.Device,
+		Entity:    req.Interface,
+		AlertType: req.AlertType,
+		Severity:  req.Severity,
+		Firing:    !req.Resolve,
+		Message:   "[synthetic] " + req.Message,
+		Related:   related,
+	}
+	select {
+	case s.alertEngine.Events() <- ev:
+	default:
+		writeJSONError(w, http.StatusServiceUnavailable, "alert event queue full")
+		return
+	}
+
+	action := "fire"
+	if req.Resolve {
+		action = "resolve"
+	}
+	dedupKey := fmt.Sprintf("%s|%s|%s", req.Device, req.Interface, req.AlertType)
+
+	s.audit(r, "debug_inject_"+action).
+		Str("device", req.Device).
+		Str("interface", req.Interface).
+		Str("alert_type", req.AlertType).
+		Str("severity", req.Severity).
+		Msg("Synthetic state change injected")
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"action":    action,
+		"dedup_key": dedupKey,
+	})
+}
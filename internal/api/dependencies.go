@@ -0,0 +1,75 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// DependencyNode is a device in the dashboard's dependency tree, with the
+// devices that declare it upstream in depends_on as its children. A device
+// with two upstreams appears under each.
+type DependencyNode struct {
+	Device   string
+	Down     bool
+	Affected bool // its upstreams leave it dependency affected
+	Children []DependencyNode
+}
+
+// deviceDown reports whether a device's collector has failed to connect
+func (s *Server) deviceDown(deviceName string) bool {
+	s.collectorMu.RLock()
+	getter := s.collectorGetter
+	s.collectorMu.RUnlock()
+	if getter == nil {
+		return false
+	}
+	col := getter(deviceName)
+	return col != nil && col.Health().Down()
+}
+
+// dependencyTree builds the dependency tree of the devices that declare or
+// are named in depends_on, rooted at those with no upstream of their own
+func (s *Server) dependencyTree(cfg *config.Config) []DependencyNode {
+	if cfg == nil {
+		return nil
+	}
+	var roots []string
+	for name, device := range cfg.DesiredState.Devices {
+		if device.DependsOn == nil && len(cfg.DesiredState.Downstream(name)) > 0 {
+			roots = append(roots, name)
+		}
+	}
+	sort.Strings(roots)
+
+	down := make(map[string]bool)
+	isDown := func(name string) bool {
+		d, ok := down[name]
+		if !ok {
+			d = s.deviceDown(name)
+			down[name] = d
+		}
+		return d
+	}
+	var build func(name string, depth int) DependencyNode
+	build = func(name string, depth int) DependencyNode {
+		node := DependencyNode{
+			Device:   name,
+			Down:     isDown(name),
+			Affected: len(cfg.DesiredState.Devices[name].DependsOn.AffectedBy(isDown)) > 0,
+		}
+		// Cycles fail validation; the depth bound only guards the walk
+		if depth < len(cfg.DesiredState.Devices) {
+			for _, child := range cfg.DesiredState.Downstream(name) {
+				node.Children = append(node.Children, build(child, depth+1))
+			}
+		}
+		return node
+	}
+
+	tree := make([]DependencyNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root, 0))
+	}
+	return tree
+}
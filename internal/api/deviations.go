@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/netspec/netspec/internal/webui"
+)
+
+// unassignedSite groups devices that have no site configured
+const unassignedSite = "Unassigned"
+
+// DeviationInfo holds a single deviating interface for the web UI
+type DeviationInfo struct {
+	Device        string
+	Interface     string
+	Description   string
+	ExpectedOper  string
+	ActualOper    string
+	ExpectedAdmin string
+	ActualAdmin   string
+	Since         time.Time
+	Duration      string
+}
+
+// DeviationGroup holds the deviations for one site
+type DeviationGroup struct {
+	Site       string
+	Deviations []DeviationInfo
+}
+
+// DeviationsPageData holds data for the deviations page
+type DeviationsPageData struct {
+	Groups    []DeviationGroup
+	Total     int
+	Sort      string
+	Version   string
+	Commit    string
+	BuildDate string
+}
+
+// handleDeviationsAPI returns the current deviations as JSON
+func (s *Server) handleDeviationsAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groups, total := s.buildDeviationGroups(r.URL.Query().Get("sort"))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"groups": groups,
+		"count":  total,
+	})
+}
+
+// handleDeviationsPage renders the deviations page
+func (s *Server) handleDeviationsPage(w http.ResponseWriter, r *http.Request) {
+	sortOrder := r.URL.Query().Get("sort")
+	if sortOrder != "newest" {
+		sortOrder = "longest"
+	}
+	groups, total := s.buildDeviationGroups(sortOrder)
+
+	s.versionMu.RLock()
+	data := DeviationsPageData{
+		Groups:    groups,
+		Total:     total,
+		Sort:      sortOrder,
+		Version:   s.version,
+		Commit:    s.commit,
+		BuildDate: s.buildDate,
+	}
+	s.versionMu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webui.Templates.ExecuteTemplate(w, "deviations", data); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to render deviations template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// buildDeviationGroups groups the evaluator's current deviations by site.
+// Sort order "newest" lists the most recent deviations first; anything else
+// lists the longest-standing first.
+func (s *Server) buildDeviationGroups(sortOrder string) ([]DeviationGroup, int) {
+	if s.evaluator == nil {
+		return []DeviationGroup{}, 0
+	}
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	deviations := s.evaluator.GetDeviations()
+	if sortOrder == "newest" {
+		for i, j := 0, len(deviations)-1; i < j; i, j = i+1, j-1 {
+			deviations[i], deviations[j] = deviations[j], deviations[i]
+		}
+	}
+
+	bySite := make(map[string][]DeviationInfo)
+	for _, d := range deviations {
+		site := deviceSite(cfg, d.Device)
+		var description string
+		if cfg != nil {
+			description = cfg.DesiredState.Devices[d.Device].Interfaces[d.Interface].Description
+		}
+		bySite[site] = append(bySite[site], deviationInfo(d, description))
+	}
+
+	groups := make([]DeviationGroup, 0, len(bySite))
+	for site, items := range bySite {
+		groups = append(groups, DeviationGroup{Site: site, Deviations: items})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Site == unassignedSite || groups[j].Site == unassignedSite {
+			return groups[j].Site == unassignedSite && groups[i].Site != unassignedSite
+		}
+		return groups[i].Site < groups[j].Site
+	})
+	return groups, len(deviations)
+}
+
+// deviationInfo converts an evaluator deviation for display
+func deviationInfo(d evaluator.Deviation, description string) DeviationInfo {
+	return DeviationInfo{
+		Device:        d.Device,
+		Interface:     d.Interface,
+		Description:   description,
+		ExpectedOper:  d.ExpectedOper,
+		ActualOper:    d.ActualOper,
+		ExpectedAdmin: d.ExpectedAdmin,
+		ActualAdmin:   d.ActualAdmin,
+		Since:         d.Since,
+		Duration:      formatDuration(time.Since(d.Since)),
+	}
+}
+
+// deviceSite returns the configured site for a device, or unassignedSite
+func deviceSite(cfg *config.Config, device string) string {
+	if cfg != nil {
+		if dev, ok := cfg.DesiredState.Devices[device]; ok && dev.Site != "" {
+			return dev.Site
+		}
+	}
+	return unassignedSite
+}
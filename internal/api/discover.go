@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// discoveredInterface is an interface found on a device but not declared
+// in its desired state, with the state proposed for it
+type discoveredInterface struct {
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	Type          string `json:"type,omitempty"`
+	OperStatus    string `json:"oper_status,omitempty"`
+	AdminStatus   string `json:"admin_status,omitempty"`
+	ProposedState string `json:"proposed_desired_state,omitempty"`
+	ProposedAdmin string `json:"proposed_admin_state,omitempty"`
+}
+
+// handleDiscoverAPI reads the device's interfaces via gNMI Get and lists
+// those desired-state.yaml does not declare and no ignore rule covers,
+// with a YAML snippet proposing their current state as desired state. The
+// snippet holds only the new interfaces, so it can be pasted under the
+// device or dropped into desired-state.d/. ?format=yaml returns just the
+// snippet.
+func (s *Server) handleDiscoverAPI(w http.ResponseWriter, r *http.Request, deviceName string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	if cfg == nil {
+		http.Error(w, "Configuration not loaded", http.StatusInternalServerError)
+		return
+	}
+	deviceCfg, exists := cfg.DesiredState.Devices[deviceName]
+	if !exists {
+		http.Error(w, "Device not found", http.StatusNotFound)
+		return
+	}
+
+	s.collectorMu.RLock()
+	getter := s.collectorGetter
+	s.collectorMu.RUnlock()
+
+	if getter == nil || getter(deviceName) == nil {
+		http.Error(w, "Device not found or collector not running", http.StatusNotFound)
+		return
+	}
+
+	s.logger.Info().Str("device", deviceName).Msg("Discovering undeclared interfaces")
+
+	observed, err := getter(deviceName).GetInterfaces()
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeJSONError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	undeclared := cfg.Undeclared(deviceName, observed)
+	proposed := config.ProposeDevice(deviceCfg.Address, deviceCfg.Description, undeclared)
+	stanza, err := config.MarshalInterfacesStanza(deviceName, proposed.Interfaces)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "yaml" {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(stanza)
+		return
+	}
+
+	interfaces := make([]discoveredInterface, 0, len(undeclared))
+	for _, iface := range undeclared {
+		found := discoveredInterface{
+			Name:        iface.Name,
+			Description: iface.Description,
+			Type:        iface.Type,
+			OperStatus:  iface.OperStatus,
+			AdminStatus: iface.AdminStatus,
+		}
+		if p, ok := proposed.Interfaces[iface.Name]; ok {
+			found.ProposedState = p.DesiredState
+			found.ProposedAdmin = p.AdminState
+		}
+		interfaces = append(interfaces, found)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"device":     deviceName,
+		"declared":   len(deviceCfg.Interfaces),
+		"observed":   len(observed),
+		"interfaces": interfaces,
+		"yaml":       string(stanza),
+	})
+}
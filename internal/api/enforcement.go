@@ -0,0 +1,29 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/netspec/netspec/internal/enforcer"
+)
+
+// handleEnforcementAPI returns whether closed-loop enforcement is enabled
+// and the latest admin state restorations, newest first
+func (s *Server) handleEnforcementAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if s.enforcer == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": false,
+			"actions": []enforcer.Action{},
+		})
+		return
+	}
+	cfg := s.enforcer.Config()
+	actions := s.enforcer.Recent()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled": cfg.Enabled,
+		"dry_run": cfg.DryRun,
+		"actions": actions,
+		"count":   len(actions),
+	})
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+const (
+	// metricSampleInterval is how often dashboard metrics are sampled
+	metricSampleInterval = time.Minute
+	// metricRetention is how much sampled history is kept in memory
+	metricRetention = 24 * time.Hour
+
+	// interfaceSeriesPrefix prefixes per-interface oper-state series,
+	// e.g. "interface:core-sw-stack/Ethernet1" (1 = up, 0 = not up)
+	interfaceSeriesPrefix = "interface:"
+	// interfaceTableTarget returns the current state of every interface
+	interfaceTableTarget = "interfaces.table"
+)
+
+// metricSample is one snapshot of the dashboard metrics
+type metricSample struct {
+	At     time.Time
+	Values map[string]float64
+}
+
+// metricHistory is a fixed-retention in-memory store of metric samples
+type metricHistory struct {
+	mu      sync.RWMutex
+	samples []metricSample
+}
+
+// add appends a sample and drops samples older than the retention
+func (h *metricHistory) add(sample metricSample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.samples = append(h.samples, sample)
+	cutoff := sample.At.Add(-metricRetention)
+	drop := 0
+	for drop < len(h.samples) && h.samples[drop].At.Before(cutoff) {
+		drop++
+	}
+	h.samples = h.samples[drop:]
+}
+
+// series returns the [value, unix ms] datapoints of one series in a range
+func (h *metricHistory) series(name string, from, to time.Time) [][2]float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	points := make([][2]float64, 0)
+	for _, sample := range h.samples {
+		if sample.At.Before(from) || sample.At.After(to) {
+			continue
+		}
+		if v, ok := sample.Values[name]; ok {
+			points = append(points, [2]float64{v, float64(sample.At.UnixMilli())})
+		}
+	}
+	return points
+}
+
+// names returns every series name present in the latest sample
+func (h *metricHistory) names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.samples) == 0 {
+		return []string{}
+	}
+	names := make([]string, 0, len(h.samples[len(h.samples)-1].Values))
+	for name := range h.samples[len(h.samples)-1].Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sampleMetrics records dashboard metrics on an interval for as long as the
+// server runs
+func (s *Server) sampleMetrics() {
+	s.history.add(metricSample{At: time.Now(), Values: s.currentMetrics()})
+
+	ticker := time.NewTicker(metricSampleInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		s.history.add(metricSample{At: now, Values: s.currentMetrics()})
+	}
+}
+
+// currentMetrics computes alert counts, compliance and per-interface state
+func (s *Server) currentMetrics() map[string]float64 {
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	values := make(map[string]float64)
+
+	alerts := s.alertEngine.GetActiveAlerts()
+	values["alerts.active"] = float64(len(alerts))
+	if cfg != nil {
+		for _, level := range cfg.Alerts.SeverityLevels() {
+			values["alerts."+level.Name] = 0
+		}
+	}
+	for _, alert := range alerts {
+		values["alerts."+alert.Severity]++
+	}
+
+	if cfg == nil || s.evaluator == nil {
+		return values
+	}
+
+	declared := 0
+	for deviceName, dev := range cfg.DesiredState.Devices {
+		for ifaceName := range dev.Interfaces {
+			declared++
+			state, ok := s.evaluator.GetInterfaceState(deviceName, ifaceName)
+			if !ok {
+				continue
+			}
+			up := 0.0
+			if config.OperToDesiredState(state.OperStatus) == "up" {
+				up = 1
+			}
+			values[interfaceSeriesPrefix+deviceName+"/"+ifaceName] = up
+		}
+	}
+	deviating := len(s.evaluator.GetDeviations())
+	values["interfaces.declared"] = float64(declared)
+	values["interfaces.deviating"] = float64(deviating)
+	if declared > 0 {
+		values["compliance.percent"] = float64(declared-deviating) / float64(declared) * 100
+	}
+	return values
+}
+
+// grafanaQueryRequest is the body of a JSON datasource /query request
+type grafanaQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+		RefID  string `json:"refId"`
+	} `json:"targets"`
+}
+
+// handleGrafana implements the Grafana JSON datasource contract under
+// /api/grafana: GET / (connection test), POST /search and /metrics (target
+// names) and POST /query (time series and the interface table)
+func (s *Server) handleGrafana(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/grafana"), "/")
+
+	switch path {
+	case "":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "ok",
+		})
+	case "/search":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.grafanaTargets())
+	case "/metrics":
+		targets := s.grafanaTargets()
+		metrics := make([]map[string]interface{}, 0, len(targets))
+		for _, target := range targets {
+			metrics = append(metrics, map[string]interface{}{
+				"label": target,
+				"value": target,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(metrics)
+	case "/query":
+		s.handleGrafanaQuery(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// grafanaTargets lists every queryable target
+func (s *Server) grafanaTargets() []string {
+	return append(s.history.names(), interfaceTableTarget)
+}
+
+// handleGrafanaQuery answers a JSON datasource query
+func (s *Server) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req grafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid query: "+err.Error())
+		return
+	}
+	to := req.Range.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := req.Range.From
+	if from.IsZero() {
+		from = to.Add(-time.Hour)
+	}
+
+	results := make([]interface{}, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		if target.Target == "" {
+			continue
+		}
+		if target.Target == interfaceTableTarget {
+			results = append(results, s.interfaceTable())
+			continue
+		}
+		results = append(results, map[string]interface{}{
+			"target":     target.Target,
+			"refId":      target.RefID,
+			"datapoints": s.history.series(target.Target, from, to),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// interfaceTable renders desired and observed state of every declared
+// interface as a Grafana table
+func (s *Server) interfaceTable() map[string]interface{} {
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	rows := make([][]interface{}, 0)
+	if cfg != nil {
+		for deviceName, dev := range cfg.DesiredState.Devices {
+			for ifaceName, ifCfg := range dev.Interfaces {
+				oper, admin := "", ""
+				if s.evaluator != nil {
+					if state, ok := s.evaluator.GetInterfaceState(deviceName, ifaceName); ok {
+						oper, admin = state.OperStatus, state.AdminStatus
+					}
+				}
+				rows = append(rows, []interface{}{
+					deviceName, deviceSite(cfg, deviceName), ifaceName,
+					ifCfg.DesiredStateAt(time.Now()), oper, ifCfg.AdminState, admin,
+				})
+			}
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i][0] != rows[j][0] {
+			return rows[i][0].(string) < rows[j][0].(string)
+		}
+		return rows[i][2].(string) < rows[j][2].(string)
+	})
+
+	columns := make([]map[string]string, 0, 7)
+	for _, name := range []string{"device", "site", "interface", "desired_oper", "actual_oper", "desired_admin", "actual_admin"} {
+		columns = append(columns, map[string]string{"text": name, "type": "string"})
+	}
+	return map[string]interface{}{
+		"type":    "table",
+		"columns": columns,
+		"rows":    rows,
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/netspec/netspec/internal/notifier"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// maxInboundBody bounds the size of inbound webhook requests
+const maxInboundBody = 64 << 10
+
+// inboundRequest is the body accepted by /api/webhooks/inbound
+type inboundRequest struct {
+	DedupKey string `json:"dedup_key"`
+	Action   string `json:"action"` // "ack" or "resolve"
+	Source   string `json:"source,omitempty"`
+}
+
+// handleInboundWebhook lets external systems (ticketing, paging) acknowledge
+// or resolve an active alert by its dedup key
+func (s *Server) handleInboundWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInboundBody))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	if cfg == nil || (cfg.Alerts.InboundWebhook.SecretEnv == "" && !cfg.Alerts.InboundWebhook.AllowUnsigned) {
+		writeJSONError(w, http.StatusNotFound, "inbound webhook is not enabled")
+		return
+	}
+	if cfg.Alerts.InboundWebhook.SecretEnv != "" {
+		secret := os.Getenv(cfg.Alerts.InboundWebhook.SecretEnv)
+		expected := notifier.Sign(secret, body)
+		if secret == "" || !hmac.Equal([]byte(expected), []byte(r.Header.Get(notifier.SignatureHeader))) {
+			writeJSONError(w, http.StatusUnauthorized, "invalid signature")
+			return
+		}
+	}
+
+	var req inboundRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.DedupKey == "" {
+		writeJSONError(w, http.StatusBadRequest, "dedup_key is required")
+		return
+	}
+
+	source := req.Source
+	if source == "" {
+		source = "webhook"
+	}
+
+	var alert types.Alert
+	var ok bool
+	switch req.Action {
+	case "ack", "acknowledge":
+		alert, ok = s.alertEngine.Acknowledge(req.DedupKey, source)
+	case "resolve":
+		alert, ok = s.alertEngine.ResolveByKey(req.DedupKey, source)
+	default:
+		writeJSONError(w, http.StatusBadRequest, "action must be 'ack' or 'resolve'")
+		return
+	}
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "no active alert with dedup_key "+req.DedupKey)
+		return
+	}
+
+	s.audit(r, "inbound_"+req.Action).
+		Str("dedup_key", req.DedupKey).
+		Str("source", source).
+		Msg("Alert updated by inbound webhook")
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"alert":   alert,
+	})
+}
+
+// writeJSONError writes a {"success": false} error response with status
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   msg,
+	})
+}
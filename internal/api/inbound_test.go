@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/netspec/netspec/internal/alerter"
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/notifier"
+	"github.com/rs/zerolog"
+)
+
+func TestInboundWebhookAuthentication(t *testing.T) {
+	t.Setenv("NETSPEC_TEST_INBOUND_SECRET", "s3cret-value")
+	body := `{"dedup_key": "sw1|Ethernet1|interface_state_mismatch", "action": "ack"}`
+	tests := []struct {
+		name      string
+		inbound   config.InboundWebhookConfig
+		signature string
+		status    int
+		errText   string
+	}{
+		{name: "no secret", status: http.StatusNotFound, errText: "not enabled"},
+		{
+			name:    "unsigned",
+			inbound: config.InboundWebhookConfig{SecretEnv: "NETSPEC_TEST_INBOUND_SECRET"},
+			status:  http.StatusUnauthorized,
+			errText: "invalid signature",
+		},
+		{
+			name:      "wrong signature",
+			inbound:   config.InboundWebhookConfig{SecretEnv: "NETSPEC_TEST_INBOUND_SECRET"},
+			signature: notifier.Sign("other", []byte(body)),
+			status:    http.StatusUnauthorized,
+			errText:   "invalid signature",
+		},
+		// Authenticated requests reach the engine, which has no such alert
+		{
+			name:      "signed",
+			inbound:   config.InboundWebhookConfig{SecretEnv: "NETSPEC_TEST_INBOUND_SECRET"},
+			signature: notifier.Sign("s3cret-value", []byte(body)),
+			status:    http.StatusNotFound,
+			errText:   "no active alert",
+		},
+		{
+			name:    "unsigned allowed",
+			inbound: config.InboundWebhookConfig{AllowUnsigned: true},
+			status:  http.StatusNotFound,
+			errText: "no active alert",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Alerts.InboundWebhook = tt.inbound
+			s := &Server{
+				alertEngine: alerter.NewEngine(cfg, notifier.NewNotifier(zerolog.Nop()), zerolog.Nop()),
+				logger:      zerolog.Nop(),
+				config:      cfg,
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/webhooks/inbound", strings.NewReader(body))
+			if tt.signature != "" {
+				req.Header.Set(notifier.SignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			s.handleInboundWebhook(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			var resp struct {
+				Error string `json:"error"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if !strings.Contains(resp.Error, tt.errText) {
+				t.Errorf("error %q, want %q", resp.Error, tt.errText)
+			}
+		})
+	}
+}
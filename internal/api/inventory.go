@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/netspec/netspec/internal/inventory"
+)
+
+// handleInventoryAPI returns the hardware inventory last read from each
+// device, optionally for one device with ?device=
+func (s *Server) handleInventoryAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	all := map[string]inventory.Snapshot{}
+	if s.inventory != nil {
+		all = s.inventory.All()
+	}
+	if device := r.URL.Query().Get("device"); device != "" {
+		snap, ok := all[device]
+		if !ok {
+			writeJSONError(w, http.StatusNotFound, "no inventory for device "+device)
+			return
+		}
+		all = map[string]inventory.Snapshot{device: snap}
+	}
+
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	devices := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		snap := all[name]
+		devices = append(devices, map[string]interface{}{
+			"device":           name,
+			"collected_at":     snap.CollectedAt,
+			"chassis":          snap.Chassis,
+			"modules":          snap.Modules,
+			"software_version": snap.SoftwareVersion,
+			"transceivers":     snap.Transceivers,
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"devices": devices,
+		"count":   len(devices),
+	})
+}
+
+// deviceInventory returns a device's last inventory read, if any
+func (s *Server) deviceInventory(device string) *inventory.Snapshot {
+	if s.inventory == nil {
+		return nil
+	}
+	if snap, ok := s.inventory.Device(device); ok {
+		return &snap
+	}
+	return nil
+}
+
+// deviceTransceiver returns the optic last seen in an interface, if any
+func (s *Server) deviceTransceiver(device, iface string) *inventory.Transceiver {
+	if s.inventory == nil {
+		return nil
+	}
+	snap, ok := s.inventory.Device(device)
+	if !ok {
+		return nil
+	}
+	if t, ok := snap.Transceiver(iface); ok {
+		return &t
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/netspec/netspec/internal/collector"
+	"github.com/netspec/netspec/internal/evaluator"
+)
+
+// liveHealth is the collector state of a device as served by
+// /api/devices/{name}/live. Times are omitted until they first happen.
+type liveHealth struct {
+	Connected      bool       `json:"connected"`
+	ConnectedSince *time.Time `json:"connected_since,omitempty"`
+	ReconnectCount int        `json:"reconnect_count"`
+	UpdateCount    int64      `json:"update_count"`
+	LastUpdate     *time.Time `json:"last_update,omitempty"`
+	SyncReceived   bool       `json:"sync_received"`
+	LastPath       string     `json:"last_path,omitempty"`
+	LastValue      string     `json:"last_value,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	SubscribeError string     `json:"subscribe_error,omitempty"`
+	SubscribeHint  string     `json:"subscribe_hint,omitempty"`
+	Sessions       int        `json:"sessions"`
+	PeakSessions   int        `json:"peak_sessions"`
+}
+
+// handleDeviceLiveAPI returns a device's connection state, update counters
+// and interface compliance in one response, for the device page to poll so
+// a reconnect or recovery shows without reloading
+func (s *Server) handleDeviceLiveAPI(w http.ResponseWriter, r *http.Request, deviceName string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	if cfg == nil {
+		writeJSONError(w, http.StatusInternalServerError, "configuration not loaded")
+		return
+	}
+	if _, exists := cfg.DesiredState.Devices[deviceName]; !exists {
+		writeJSONError(w, http.StatusNotFound, "device not found")
+		return
+	}
+
+	var health collector.DeviceHealth
+	s.collectorMu.RLock()
+	getter := s.collectorGetter
+	s.collectorMu.RUnlock()
+
+	if getter != nil {
+		if col := getter(deviceName); col != nil {
+			health = col.Health()
+		}
+	}
+
+	interfaces := []evaluator.InterfaceCompliance{}
+	deviating := 0
+	if s.evaluator != nil {
+		if compliance, ok := s.evaluator.GetDeviceCompliance(deviceName); ok {
+			interfaces = compliance
+		}
+		for _, iface := range interfaces {
+			if iface.Status == evaluator.ComplianceDeviating {
+				deviating++
+			}
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"device": deviceName,
+		"time":   time.Now().UTC(),
+		"health": liveHealth{
+			Connected:      health.Connected,
+			ConnectedSince: optionalTime(health.ConnectedSince),
+			ReconnectCount: health.ReconnectCount,
+			UpdateCount:    health.UpdateCount,
+			LastUpdate:     optionalTime(health.LastUpdate),
+			SyncReceived:   health.SyncReceived,
+			LastPath:       health.LastPath,
+			LastValue:      health.LastValue,
+			LastError:      health.LastError,
+			SubscribeError: health.SubscribeError,
+			SubscribeHint:  health.SubscribeHint,
+			Sessions:       health.Sessions,
+			PeakSessions:   health.PeakSessions,
+		},
+		"interfaces": interfaces,
+		"deviating":  deviating,
+	})
+}
+
+// optionalTime returns nil for the zero time, so it is left out of JSON
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// maintenanceWindowInfo is a maintenance window as listed by the API
+type maintenanceWindowInfo struct {
+	Name           string   `json:"name"`
+	Devices        []string `json:"devices"`
+	Type           string   `json:"type"` // recurring or one-time
+	Day            string   `json:"day,omitempty"`
+	Start          string   `json:"start"`
+	End            string   `json:"end"`
+	Timezone       string   `json:"timezone,omitempty"`
+	SuppressAlerts bool     `json:"suppress_alerts"`
+	Active         bool     `json:"active"`
+	Source         string   `json:"source"` // maintenance.yaml or change_calendar
+}
+
+// windowInfo describes a maintenance window as of now
+func windowInfo(mw config.MaintenanceWindow, source string, now time.Time) maintenanceWindowInfo {
+	return maintenanceWindowInfo{
+		Name:           mw.Name,
+		Devices:        mw.Devices,
+		Type:           mw.Schedule.Type,
+		Day:            mw.Schedule.Day,
+		Start:          mw.Schedule.Start,
+		End:            mw.Schedule.End,
+		Timezone:       mw.Schedule.Timezone,
+		SuppressAlerts: mw.SuppressAlerts,
+		Active:         mw.Active(now),
+		Source:         source,
+	}
+}
+
+// handleMaintenanceAPI lists the configured maintenance windows and those
+// of the change calendar, and whether each is open now
+func (s *Server) handleMaintenanceAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+	if cfg == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "configuration not loaded")
+		return
+	}
+
+	now := time.Now()
+	windows := make([]maintenanceWindowInfo, 0, len(cfg.Maintenance.MaintenanceWindows))
+	for _, mw := range cfg.Maintenance.MaintenanceWindows {
+		windows = append(windows, windowInfo(mw, "maintenance.yaml", now))
+	}
+	response := map[string]interface{}{}
+	if s.changeCalendar != nil && cfg.Maintenance.ChangeCalendar != nil {
+		for _, mw := range s.changeCalendar.Windows() {
+			windows = append(windows, windowInfo(mw, "change_calendar", now))
+		}
+		response["change_calendar"] = s.changeCalendar.Status()
+	}
+	active := 0
+	for _, info := range windows {
+		if info.Active {
+			active++
+		}
+	}
+	response["windows"] = windows
+	response["count"] = len(windows)
+	response["active"] = active
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/netspec/netspec/internal/prefs"
+)
+
+// requestUser identifies the web UI user from the user_header set by an
+// authenticating reverse proxy. Without one, every request shares the
+// default user's preferences.
+func (s *Server) requestUser(r *http.Request) string {
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+	if cfg != nil && cfg.DesiredState.Global.UserHeader != "" {
+		if user := strings.TrimSpace(r.Header.Get(cfg.DesiredState.Global.UserHeader)); user != "" {
+			return user
+		}
+	}
+	return prefs.DefaultUser
+}
+
+// userPreferences returns the requesting user and their preferences
+func (s *Server) userPreferences(r *http.Request) (string, prefs.Preferences) {
+	user := s.requestUser(r)
+	if s.preferences == nil {
+		return user, prefs.Preferences{FavoriteDevices: []string{}, Watching: []prefs.Watch{}}
+	}
+	return user, s.preferences.Get(user)
+}
+
+// handlePreferencesAPI returns the requesting user's UI preferences on GET
+// and replaces them with the JSON body on PUT
+func (s *Server) handlePreferencesAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		if s.preferences == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "preferences not available")
+			return
+		}
+		var p prefs.Preferences
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+		user := s.requestUser(r)
+		if err := s.preferences.Set(user, p); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.logger.Info().Str("user", user).Msg("UI preferences updated")
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, p := s.userPreferences(r)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user":        user,
+		"preferences": p,
+	})
+}
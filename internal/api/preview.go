@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/evaluator"
+)
+
+// handleReloadPreview loads the configuration on disk without applying it
+// and reports which alerts it would immediately fire or resolve against the
+// currently observed state
+func (s *Server) handleReloadPreview(w http.ResponseWriter) {
+	s.reloadMu.RLock()
+	configPath := s.configPath
+	s.reloadMu.RUnlock()
+
+	s.logger.Info().Msg("Config reload dry run requested via API")
+
+	candidate, err := config.LoadConfigDir(filepath.Dir(configPath))
+	if err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"dry_run": true,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":       true,
+		"dry_run":       true,
+		"device_count":  len(candidate.DesiredState.Devices),
+		"would_fire":    []map[string]interface{}{},
+		"would_resolve": []map[string]interface{}{},
+		"unchanged":     0,
+		"warnings":      candidate.Warnings(),
+	}
+	if s.evaluator != nil {
+		preview := s.evaluator.Preview(candidate)
+		response["would_fire"] = previewChanges(preview.WouldFire)
+		response["would_resolve"] = previewChanges(preview.WouldResolve)
+		response["unchanged"] = preview.Unchanged
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// previewChanges converts evaluator state changes for the JSON response
+func previewChanges(changes []evaluator.StateChange) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, map[string]interface{}{
+			"device":        change.Device,
+			"interface":     change.Interface,
+			"alert_type":    change.AlertType,
+			"severity":      change.Severity,
+			"message":       change.Message,
+			"related_state": change.RelatedState,
+		})
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/netspec/netspec/internal/remediation"
+)
+
+// handleRemediationAPI returns whether remediation is enabled and the
+// latest attempts, newest first: each action run, dry run or skipped by
+// max_attempts or cooldown, with its result
+func (s *Server) handleRemediationAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if s.remediator == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled":  false,
+			"attempts": []remediation.Attempt{},
+		})
+		return
+	}
+	cfg := s.remediator.Config()
+	attempts := s.remediator.Recent()
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled":  cfg.Enabled,
+		"dry_run":  cfg.DryRun,
+		"attempts": attempts,
+		"count":    len(attempts),
+	})
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/alerter"
+	"github.com/netspec/netspec/internal/hotspot"
+	"github.com/netspec/netspec/internal/webui"
+)
+
+// reportHistoryWindow is how far back a device report's history goes
+const reportHistoryWindow = 24 * time.Hour
+
+// ReportInterface is one interface row of a device report: its declared
+// intent next to what was last observed
+type ReportInterface struct {
+	Name         string
+	Description  string
+	Role         string
+	DesiredState string
+	AdminState   string
+	Members      []string
+	MemberPolicy string
+	ActualOper   string
+	ActualAdmin  string
+	Status       string // "compliant", "deviating" or "no data"
+	Since        *time.Time
+	Flaps        int // in the last reportHistoryWindow
+}
+
+// ReportAlert is an open alert in a device report
+type ReportAlert struct {
+	Entity         string
+	AlertType      string
+	Severity       string
+	FiredAt        time.Time
+	Message        string
+	AcknowledgedBy string
+}
+
+// DeviceReportData holds a device report: a snapshot of intent,
+// compliance, open alerts and recent history for change tickets and audits
+type DeviceReportData struct {
+	Device      string
+	Address     string
+	Site        string
+	Description string
+	GeneratedAt time.Time
+	GeneratedBy string
+
+	Connected      bool
+	ConnectedSince time.Time
+	LastUpdate     time.Time
+	LastError      string
+
+	Interfaces []ReportInterface
+	Compliant  int
+	Deviating  int
+	NoData     int
+	Compliance string // percent of interfaces with data that comply
+
+	Alerts   []ReportAlert
+	Silences []alerter.Silence
+	History  []webui.LogEntry
+
+	Version string
+	Commit  string
+}
+
+// handleDeviceReport renders a print-friendly report of one device, for
+// saving as PDF from the browser. ?download=1 serves it as an HTML file
+// attachment instead.
+func (s *Server) handleDeviceReport(w http.ResponseWriter, r *http.Request, deviceName string) {
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+	if cfg == nil {
+		http.Error(w, "Configuration not loaded", http.StatusInternalServerError)
+		return
+	}
+	deviceCfg, exists := cfg.DesiredState.Devices[deviceName]
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	now := time.Now()
+	data := DeviceReportData{
+		Device:      deviceName,
+		Address:     deviceCfg.Address,
+		Site:        deviceCfg.Site,
+		Description: deviceCfg.Description,
+		GeneratedAt: now,
+		GeneratedBy: s.requestUser(r),
+	}
+	s.versionMu.RLock()
+	data.Version, data.Commit = s.version, s.commit
+	s.versionMu.RUnlock()
+
+	s.collectorMu.RLock()
+	getter := s.collectorGetter
+	s.collectorMu.RUnlock()
+	if getter != nil {
+		if col := getter(deviceName); col != nil {
+			health := col.Health()
+			data.Connected = health.Connected
+			data.ConnectedSince = health.ConnectedSince
+			data.LastUpdate = health.LastUpdate
+			data.LastError = health.LastError
+		}
+	}
+
+	flaps := make(map[string]int)
+	if s.hotspots != nil {
+		for _, entry := range s.hotspots.Top(hotspot.MetricFlaps, reportHistoryWindow, 0, now) {
+			if entry.Device == deviceName {
+				flaps[entry.Interface] = entry.Flaps
+			}
+		}
+	}
+
+	for ifName, ifCfg := range deviceCfg.Interfaces {
+		if s.interfaceIgnored(cfg, deviceName, ifName) {
+			continue
+		}
+		row := ReportInterface{
+			Name:         ifName,
+			Description:  ifCfg.Description,
+			Role:         ifCfg.Role,
+			DesiredState: ifCfg.DesiredStateAt(now),
+			AdminState:   ifCfg.AdminState,
+			Status:       "no data",
+			Flaps:        flaps[ifName],
+		}
+		if ifCfg.Members != nil {
+			row.Members = ifCfg.Members.Required
+		}
+		if ifCfg.MemberPolicy != nil {
+			row.MemberPolicy = memberPolicyDisplay(ifCfg.MemberPolicy.Mode, ifCfg.MemberPolicy.Minimum, ifCfg.MemberPolicy.PerStackMinimum)
+		}
+		if s.evaluator != nil {
+			if observed, ok := s.evaluator.GetInterfaceState(deviceName, ifName); ok {
+				row.ActualOper = observed.OperStatus
+				row.ActualAdmin = observed.AdminStatus
+				row.Since = observed.DeviatedSince
+				row.Status = "compliant"
+				if observed.DeviatedSince != nil {
+					row.Status = "deviating"
+				}
+			}
+		}
+		switch row.Status {
+		case "compliant":
+			data.Compliant++
+		case "deviating":
+			data.Deviating++
+		default:
+			data.NoData++
+		}
+		data.Interfaces = append(data.Interfaces, row)
+	}
+	sort.Slice(data.Interfaces, func(i, j int) bool {
+		return data.Interfaces[i].Name < data.Interfaces[j].Name
+	})
+	if observed := data.Compliant + data.Deviating; observed > 0 {
+		data.Compliance = fmt.Sprintf("%.1f%%", float64(data.Compliant)/float64(observed)*100)
+	}
+
+	for _, alert := range s.alertEngine.GetActiveAlerts() {
+		if alert.Device != deviceName {
+			continue
+		}
+		data.Alerts = append(data.Alerts, ReportAlert{
+			Entity:         alert.Entity,
+			AlertType:      alert.AlertType,
+			Severity:       alert.Severity,
+			FiredAt:        alert.FiredAt,
+			Message:        alert.Message,
+			AcknowledgedBy: alert.AcknowledgedBy,
+		})
+	}
+	sort.Slice(data.Alerts, func(i, j int) bool {
+		ri, rj := cfg.Alerts.SeverityRank(data.Alerts[i].Severity), cfg.Alerts.SeverityRank(data.Alerts[j].Severity)
+		if ri != rj {
+			return ri < rj
+		}
+		return data.Alerts[i].FiredAt.Before(data.Alerts[j].FiredAt)
+	})
+	for _, silence := range s.alertEngine.GetSilences() {
+		if silence.Device == deviceName {
+			data.Silences = append(data.Silences, silence)
+		}
+	}
+
+	// History is the device's log lines, matched the way the device page
+	// matches them
+	if s.logBuffer != nil {
+		for _, entry := range s.logBuffer.GetRecentEntries(500) {
+			if now.Sub(entry.Timestamp) > reportHistoryWindow {
+				continue
+			}
+			if strings.Contains(strings.ToLower(entry.Message), strings.ToLower(deviceName)) ||
+				strings.Contains(strings.ToLower(entry.Message), deviceCfg.Address) {
+				data.History = append(data.History, entry)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.URL.Query().Get("download") != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-report-%s.html", deviceName, now.Format("20060102-1504"))))
+	}
+	if err := webui.Templates.ExecuteTemplate(w, "report", data); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to render report template")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// memberPolicyDisplay describes a port-channel member policy for a report
+func memberPolicyDisplay(mode string, minimum, perStack int) string {
+	switch {
+	case mode == "min_active" && minimum > 0:
+		return fmt.Sprintf("min_active (%d)", minimum)
+	case mode == "per_stack_minimum" && perStack > 0:
+		return fmt.Sprintf("per_stack_minimum (%d)", perStack)
+	}
+	return mode
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// handleSchemaAPI serves the JSON Schema of a configuration file at
+// /api/schema/<file> (e.g. /api/schema/desired-state.json), for editors and
+// CI; /api/schema lists the files and their schema URLs
+func (s *Server) handleSchemaAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/schema"), "/")
+	name = strings.TrimSuffix(name, ".json")
+	if name == "" {
+		files := make([]map[string]interface{}, 0)
+		for _, file := range config.SchemaFiles() {
+			files = append(files, map[string]interface{}{
+				"file":   file + ".yaml",
+				"schema": "/api/schema/" + file + ".json",
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"files": files,
+		})
+		return
+	}
+
+	schema, ok := config.Schema(name)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "no schema for "+name)
+		return
+	}
+	w.Header().Set("Content-Type", "application/schema+json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(schema)
+}
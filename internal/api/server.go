@@ -1,15 +1,31 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/netspec/netspec/internal/alerter"
+	"github.com/netspec/netspec/internal/capabilities"
+	"github.com/netspec/netspec/internal/capacity"
+	"github.com/netspec/netspec/internal/changecal"
 	"github.com/netspec/netspec/internal/collector"
 	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/enforcer"
+	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/netspec/netspec/internal/hotspot"
+	"github.com/netspec/netspec/internal/inventory"
+	"github.com/netspec/netspec/internal/prefs"
+	"github.com/netspec/netspec/internal/redact"
+	"github.com/netspec/netspec/internal/remediation"
+	"github.com/netspec/netspec/internal/mgmtcheck"
+	"github.com/netspec/netspec/internal/metrics"
+	"github.com/netspec/netspec/internal/types"
 	"github.com/netspec/netspec/internal/webui"
 	"github.com/rs/zerolog"
 )
@@ -31,12 +47,28 @@ type Server struct {
 	startTime      time.Time
 	reloadFunc     ConfigReloadFunc
 	reloadMu       sync.RWMutex
+	reloading      sync.Mutex // held for the duration of a reload
 	version        string
 	commit         string
 	buildDate      string
 	versionMu      sync.RWMutex
 	collectorGetter CollectorGetter
 	collectorMu     sync.RWMutex
+	evaluator       *evaluator.Evaluator
+	history         *metricHistory
+	mgmtChecker     *mgmtcheck.Checker
+	capacity        *capacity.Tracker
+	hotspots        *hotspot.Tracker
+	inventory       *inventory.Store
+	capabilities    *capabilities.Refresher
+	preferences     *prefs.Store
+	remediator      *remediation.Remediator
+	enforcer        *enforcer.Enforcer
+	changeCalendar  *changecal.Syncer
+	defaultUsername string
+	defaultPassword string
+	rollouts        map[string]context.CancelFunc // rolling reconnects in progress, by credential
+	credentialsMu   sync.Mutex
 }
 
 // NewServer creates a new API server
@@ -46,6 +78,7 @@ func NewServer(alertEngine *alerter.Engine, logger zerolog.Logger, port string)
 		logger:      logger,
 		port:        port,
 		startTime:   time.Now(),
+		history:     &metricHistory{},
 	}
 }
 
@@ -83,32 +116,142 @@ func (s *Server) SetCollectorGetter(getter CollectorGetter) {
 	s.collectorGetter = getter
 }
 
+// SetEvaluator sets the evaluator whose state cache backs the live-state views
+func (s *Server) SetEvaluator(eval *evaluator.Evaluator) {
+	s.evaluator = eval
+}
+
+// SetMgmtChecker sets the source of management-plane check results shown on
+// device pages
+func (s *Server) SetMgmtChecker(checker *mgmtcheck.Checker) {
+	s.mgmtChecker = checker
+}
+
+// SetCapacityTracker sets the source of the uplink trends shown on the
+// capacity page
+func (s *Server) SetCapacityTracker(tracker *capacity.Tracker) {
+	s.capacity = tracker
+}
+
+// SetHotspotTracker sets the source of the top-N interfaces on the dashboard
+// and /api/top
+func (s *Server) SetHotspotTracker(tracker *hotspot.Tracker) {
+	s.hotspots = tracker
+}
+
+// SetInventory sets the source of the hardware inventory shown on device
+// pages and /api/inventory
+func (s *Server) SetInventory(store *inventory.Store) {
+	s.inventory = store
+}
+
+// SetCapabilities sets the source of the gNMI capabilities and their
+// changes shown on device pages and /api/devices/{name}/capabilities
+func (s *Server) SetCapabilities(refresher *capabilities.Refresher) {
+	s.capabilities = refresher
+}
+
+// SetRemediator sets the remediator whose attempts GET /api/remediation
+// lists
+func (s *Server) SetRemediator(r *remediation.Remediator) {
+	s.remediator = r
+}
+
+// SetEnforcer sets the enforcer whose actions GET /api/enforcement lists
+func (s *Server) SetEnforcer(e *enforcer.Enforcer) {
+	s.enforcer = e
+}
+
+// SetChangeCalendar sets the syncer taking changes pushed to
+// /api/webhooks/calendar
+func (s *Server) SetChangeCalendar(syncer *changecal.Syncer) {
+	s.changeCalendar = syncer
+}
+
+// SetPreferences sets the store of per-user web UI preferences
+func (s *Server) SetPreferences(store *prefs.Store) {
+	s.preferences = store
+}
+
+// mgmtResults returns a device's management check results, if any
+func (s *Server) mgmtResults(device string) []mgmtcheck.Result {
+	if s.mgmtChecker == nil {
+		return nil
+	}
+	return s.mgmtChecker.Results(device)
+}
+
+// interfaceIgnored reports whether ignore_interfaces rules hide a declared
+// interface, matching its description as last reported by the device
+func (s *Server) interfaceIgnored(cfg *config.Config, device, iface string) bool {
+	observed := config.ObservedInterface{Name: iface}
+	if s.evaluator != nil {
+		if state, ok := s.evaluator.GetInterfaceState(device, iface); ok {
+			observed.Description = state.Description
+		}
+	}
+	return cfg.IgnoresInterface(device, observed)
+}
+
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	mux := http.NewServeMux()
 
 	// API endpoints
 	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/metrics", s.handleMetrics)
 	mux.HandleFunc("/status", s.handleStatus)
 	mux.HandleFunc("/alerts", s.handleAlerts)
+	mux.HandleFunc("/api/alerts/bulk", s.handleBulkAlerts)
+	mux.HandleFunc("/api/alerts/history", s.handleAlertHistoryAPI)
+	mux.HandleFunc("/api/remediation", s.handleRemediationAPI)
+	mux.HandleFunc("/api/enforcement", s.handleEnforcementAPI)
+	mux.HandleFunc("/api/silences", s.handleSilencesAPI)
+	mux.HandleFunc("/api/maintenance", s.handleMaintenanceAPI)
 	mux.HandleFunc("/api/logs", s.handleLogsAPI)
 	mux.HandleFunc("/api/reload", s.handleReload)
 	mux.HandleFunc("/api/devices", s.handleDevicesAPI)
 	mux.HandleFunc("/api/devices/", s.handleDeviceDetailAPI)
+	mux.HandleFunc("/api/credentials/", s.handleCredentialsAPI)
 	mux.HandleFunc("/api/test/", s.handleTestConnection)
+	mux.HandleFunc("/api/deviations", s.handleDeviationsAPI)
+	mux.HandleFunc("/api/capacity", s.handleCapacityAPI)
+	mux.HandleFunc("/api/top", s.handleTopAPI)
+	mux.HandleFunc("/api/inventory", s.handleInventoryAPI)
+	mux.HandleFunc("/api/state", s.handleStateAPI)
+	mux.HandleFunc("/api/preferences", s.handlePreferencesAPI)
+	mux.HandleFunc("/api/schema", s.handleSchemaAPI)
+	mux.HandleFunc("/api/schema/", s.handleSchemaAPI)
+	mux.HandleFunc("/api/webhooks/inbound", s.handleInboundWebhook)
+	mux.HandleFunc("/api/webhooks/calendar", s.handleCalendarWebhook)
+	mux.HandleFunc("/api/chatops/command", s.handleChatOpsCommand)
+	mux.HandleFunc("/api/grafana", s.handleGrafana)
+	mux.HandleFunc("/api/grafana/", s.handleGrafana)
+	mux.HandleFunc("/api/debug/inject-state-change", s.handleInjectStateChange)
 	
 	// Web UI routes
 	mux.HandleFunc("/device/", s.handleDevicePage)
+	mux.HandleFunc("/alert/", s.handleAlertPage)
+	mux.HandleFunc("/deviations", s.handleDeviationsPage)
+	mux.HandleFunc("/capacity", s.handleCapacityPage)
 
 	// Web UI
 	mux.HandleFunc("/", s.handleWebUI)
 
+	go s.sampleMetrics()
+
 	addr := ":" + s.port
 	s.logger.Info().
 		Str("address", addr).
 		Msg("Starting API server with Web UI")
 
-	return http.ListenAndServe(addr, mux)
+	return http.ListenAndServe(addr, redact.Default.Handler(mux))
+}
+
+// handleMetrics serves self-monitoring metrics in the Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.Default.WriteText(w)
 }
 
 // handleHealth returns service health status
@@ -144,15 +287,67 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(status)
 }
 
-// handleAlerts returns active alerts
+// handleAlerts returns active alerts. Browsers asking for HTML get the
+// alert history page instead.
 func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		s.handleAlertHistoryPage(w, r)
+		return
+	}
+	q := r.URL.Query()
+	filter, err := parseAlertFilter(q, time.Now())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, offset, err := parsePage(q)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	order := q.Get("sort")
+	if order == "" {
+		order = "-fired_at"
+	}
 
-	alerts := s.alertEngine.GetActiveAlerts()
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	alerts := make([]*types.Alert, 0)
+	for _, alert := range s.alertEngine.GetActiveAlerts() {
+		if filter.matches(alert, cfg) {
+			alerts = append(alerts, alert)
+		}
+	}
+	if err := sortAlerts(alerts, order, cfg); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	total := len(alerts)
+	if offset > total {
+		offset = total
+	}
+	alerts = alerts[offset:]
+	if limit > 0 && len(alerts) > limit {
+		alerts = alerts[:limit]
+	}
+
+	// count stays the number of matching alerts, as it was before paging;
+	// next_offset is set while more pages remain
+	resp := map[string]interface{}{
 		"alerts": alerts,
-		"count":  len(alerts),
-	})
+		"count":  total,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	}
+	if next := offset + len(alerts); next < total {
+		resp["next_offset"] = next
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
 }
 
 // handleLogsAPI returns recent log entries as JSON
@@ -202,9 +397,7 @@ func (s *Server) handleDevicesAPI(w http.ResponseWriter, r *http.Request) {
 
 // handleDeviceDetailAPI returns detailed information about a specific device
 func (s *Server) handleDeviceDetailAPI(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	// Extract device name from path: /api/devices/{name}
+	// Extract device name from path: /api/devices/{name}[/{action}]
 	path := strings.TrimPrefix(r.URL.Path, "/api/devices/")
 	if path == "" || path == "/api/devices" {
 		http.Error(w, "Device name required", http.StatusBadRequest)
@@ -212,6 +405,26 @@ func (s *Server) handleDeviceDetailAPI(w http.ResponseWriter, r *http.Request) {
 	}
 	deviceName := path
 
+	if name, action, ok := strings.Cut(path, "/"); ok {
+		switch action {
+		case "adopt":
+			s.handleAdoptAPI(w, r, name)
+		case "interfaces":
+			s.handleDeviceInterfacesAPI(w, r, name)
+		case "discover":
+			s.handleDiscoverAPI(w, r, name)
+		case "live":
+			s.handleDeviceLiveAPI(w, r, name)
+		case "capabilities":
+			s.handleDeviceCapabilitiesAPI(w, r, name)
+		default:
+			http.NotFound(w, r)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
 	s.reloadMu.RLock()
 	cfg := s.config
 	s.reloadMu.RUnlock()
@@ -243,10 +456,14 @@ func (s *Server) handleDeviceDetailAPI(w http.ResponseWriter, r *http.Request) {
 	// Build interface list
 	interfaces := make([]map[string]interface{}, 0)
 	for ifaceName, ifaceCfg := range deviceCfg.Interfaces {
+		if s.interfaceIgnored(cfg, deviceName, ifaceName) {
+			continue
+		}
 		interfaces = append(interfaces, map[string]interface{}{
 			"name":          ifaceName,
 			"description":   ifaceCfg.Description,
-			"desired_state": ifaceCfg.DesiredState,
+			"role":          ifaceCfg.Role,
+			"desired_state": ifaceCfg.DesiredStateAt(time.Now()),
 			"admin_state":   ifaceCfg.AdminState,
 			"alerts":        ifaceCfg.Alerts,
 		})
@@ -273,19 +490,32 @@ func (s *Server) handleDeviceDetailAPI(w http.ResponseWriter, r *http.Request) {
 		"name":        deviceName,
 		"address":     deviceCfg.Address,
 		"description": deviceCfg.Description,
+		"offline":     deviceCfg.ExpectedOffline(),
 		"health": map[string]interface{}{
 			"connected":        health.Connected,
 			"last_update":       health.LastUpdate,
 			"last_error":        health.LastError,
+			"diagnosis":         health.Diagnosis,
+			"subscribe_error":   health.SubscribeError,
+			"subscribe_hint":    health.SubscribeHint,
+			"poll_interval_seconds": health.PollInterval.Seconds(),
 			"reconnect_count":   health.ReconnectCount,
 			"update_count":      health.UpdateCount,
 			"sync_received":     health.SyncReceived,
 			"last_path":         health.LastPath,
 			"last_value":        health.LastValue,
 			"connected_since":   health.ConnectedSince,
+			"cert_subject":      health.CertSubject,
+			"cert_not_after":    health.CertNotAfter,
+			"stream_skew_seconds": health.StreamSkew.Seconds(),
+			"stream_skew_measured": health.StreamSkewMeasured,
+			"sessions":          health.Sessions,
+			"peak_sessions":     health.PeakSessions,
+			"session_limit":     cfg.SessionLimitFor(deviceCfg),
 		},
-		"interfaces": interfaces,
-		"logs":       deviceLogs,
+		"mgmt_checks": s.mgmtResults(deviceName),
+		"interfaces":  interfaces,
+		"logs":        deviceLogs,
 	}
 
 	json.NewEncoder(w).Encode(response)
@@ -364,9 +594,14 @@ func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Get("dry_run") == "true" {
+		s.handleReloadPreview(w)
+		return
+	}
+
 	s.logger.Info().Msg("Config reload requested via API")
 
-	newCfg, err := s.reloadFunc()
+	newCfg, err := s.reload()
 	if err != nil {
 		s.logger.Error().Err(err).Msg("Config reload failed")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -377,10 +612,6 @@ func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.reloadMu.Lock()
-	s.config = newCfg
-	s.reloadMu.Unlock()
-
 	s.logger.Info().
 		Int("device_count", len(newCfg.DesiredState.Devices)).
 		Msg("Config reloaded successfully")
@@ -388,23 +619,72 @@ func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success":      true,
 		"device_count": len(newCfg.DesiredState.Devices),
+		"warnings":     newCfg.Warnings(),
 	})
 }
 
+// Reload reloads the configuration through the same path as POST
+// /api/reload, for reloads triggered elsewhere such as SIGHUP or a config
+// file change
+func (s *Server) Reload() (*config.Config, error) {
+	return s.reload()
+}
+
+// reload runs the configured reload function and swaps in the new config.
+// Reloads are serialized, as the API, SIGHUP and the config watcher can
+// ask for one at the same time.
+func (s *Server) reload() (*config.Config, error) {
+	s.reloading.Lock()
+	defer s.reloading.Unlock()
+	if s.reloadFunc == nil {
+		return nil, fmt.Errorf("config reload not configured")
+	}
+	newCfg, err := s.reloadFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	s.reloadMu.Lock()
+	s.config = newCfg
+	s.reloadMu.Unlock()
+
+	return newCfg, nil
+}
+
+// audit returns a log event for an operator action that changes state, so
+// every such action is recorded with who requested it
+func (s *Server) audit(r *http.Request, action string) *zerolog.Event {
+	return s.logger.Info().
+		Str("component", "audit").
+		Str("action", action).
+		Str("remote_addr", r.RemoteAddr)
+}
+
 // DeviceInfo holds device information for the web UI
 type DeviceInfo struct {
 	Name           string
 	Address        string
 	Description    string
+	Site           string
 	InterfaceCount int
+	Favorite       bool
 }
 
 // AlertInfo holds alert information for the web UI
 type AlertInfo struct {
-	Device   string
-	Entity   string
-	Severity string
-	Message  string
+	ID            string
+	Device        string
+	Entity        string
+	Severity      string
+	SeverityColor string
+	Message       string
+	RunbookURL    string
+	Acknowledged  bool
+	AcknowledgedBy string
+	DependencyAffected []string // upstream devices down when it fired
+	Maintenance   string // maintenance window suppressing its notifications
+	Silenced      bool   // a silence suppresses its notifications
+	Tickets       []types.Ticket // opened by jira and servicenow channels
 }
 
 // ConfigInfo holds configuration summary for the web UI
@@ -425,6 +705,14 @@ type PageData struct {
 	Alerts         []AlertInfo
 	Logs           []webui.LogEntry
 	Config         ConfigInfo
+	Hotspots       HotspotCard
+	Watching       []WatchInfo
+	Silences       []alerter.Silence
+	Dependencies   []DependencyNode
+	User           string
+	Preferences    prefs.Preferences
+	Sites          []string
+	SiteFilter     string // from ?site=, else the user's site_filter; "" shows every site
 	Version        string
 	Commit         string
 	BuildDate      string
@@ -458,6 +746,18 @@ func (s *Server) handleWebUI(w http.ResponseWriter, r *http.Request) {
 		Version:   version,
 		Commit:    commit,
 		BuildDate: buildDate,
+		Hotspots:  s.hotspotCard(r),
+	}
+	data.User, data.Preferences = s.userPreferences(r)
+	data.SiteFilter = data.Preferences.SiteFilter
+	if site, ok := r.URL.Query()["site"]; ok {
+		data.SiteFilter = site[0]
+	}
+	inSite := func(device string) bool {
+		if data.SiteFilter == "" || cfg == nil {
+			return true
+		}
+		return cfg.DesiredState.Devices[device].Site == data.SiteFilter
 	}
 
 	// Add config details
@@ -467,27 +767,76 @@ func (s *Server) handleWebUI(w http.ResponseWriter, r *http.Request) {
 		data.Config.CollectionInterval = cfg.DesiredState.Global.CollectionInterval.String()
 		data.Config.DedupWindow = cfg.Alerts.AlertBehavior.DeduplicationWindow.String()
 
-		// Build device list
+		// Build device list, favorites first
+		sites := make(map[string]bool)
 		for name, dev := range cfg.DesiredState.Devices {
+			if dev.Site != "" {
+				sites[dev.Site] = true
+			}
+			if !inSite(name) {
+				continue
+			}
 			data.Devices = append(data.Devices, DeviceInfo{
 				Name:           name,
 				Address:        dev.Address,
 				Description:    dev.Description,
+				Site:           dev.Site,
 				InterfaceCount: len(dev.Interfaces),
+				Favorite:       data.Preferences.IsFavorite(name),
 			})
 			data.InterfaceCount += len(dev.Interfaces)
 		}
+		sort.Slice(data.Devices, func(i, j int) bool {
+			if data.Devices[i].Favorite != data.Devices[j].Favorite {
+				return data.Devices[i].Favorite
+			}
+			return data.Devices[i].Name < data.Devices[j].Name
+		})
+		for site := range sites {
+			data.Sites = append(data.Sites, site)
+		}
+		sort.Strings(data.Sites)
+		data.Dependencies = s.dependencyTree(cfg)
 	}
 
-	// Get active alerts
-	alerts := s.alertEngine.GetActiveAlerts()
+	// Get active alerts; pinned devices and interfaces are watched whatever
+	// the site filter
+	var alertsCfg config.AlertsConfig
+	if cfg != nil {
+		alertsCfg = cfg.Alerts
+	}
+	active := s.alertEngine.GetActiveAlerts()
+	data.Watching = s.watchingCard(cfg, data.Preferences, active)
+	data.Silences = s.alertEngine.GetSilences()
+	var alerts []*types.Alert
+	for _, alert := range active {
+		if inSite(alert.Device) {
+			alerts = append(alerts, alert)
+		}
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		ri, rj := alertsCfg.SeverityRank(alerts[i].Severity), alertsCfg.SeverityRank(alerts[j].Severity)
+		if ri != rj {
+			return ri < rj
+		}
+		return alerts[i].FiredAt.After(alerts[j].FiredAt)
+	})
 	data.AlertCount = len(alerts)
 	for _, alert := range alerts {
 		data.Alerts = append(data.Alerts, AlertInfo{
-			Device:   alert.Device,
-			Entity:   alert.Entity,
-			Severity: alert.Severity,
-			Message:  alert.Message,
+			ID:            alert.ID,
+			Device:        alert.Device,
+			Entity:        alert.Entity,
+			Severity:      alert.Severity,
+			SeverityColor: alertsCfg.SeverityColor(alert.Severity),
+			Message:       alert.Message,
+			RunbookURL:    alert.RunbookURL,
+			Acknowledged:  alert.Acknowledged,
+			AcknowledgedBy: alert.AcknowledgedBy,
+			DependencyAffected: alert.DependencyAffected,
+			Maintenance:   alert.Maintenance,
+			Silenced:      alert.Silenced,
+			Tickets:       alert.Tickets,
 		})
 	}
 
@@ -506,6 +855,7 @@ func (s *Server) handleWebUI(w http.ResponseWriter, r *http.Request) {
 // DevicePageData holds data for the device detail page
 type DevicePageData struct {
 	Device      DeviceDetailInfo
+	Preferences prefs.Preferences
 	Version     string
 	Commit      string
 	BuildDate   string
@@ -519,12 +869,28 @@ type DeviceDetailInfo struct {
 	Connected      bool
 	LastUpdate     time.Time
 	LastError      string
+	SubscribeError string // permission, capability or transport
+	SubscribeHint  string
 	ReconnectCount int
 	UpdateCount    int64
 	SyncReceived   bool
 	LastPath       string
 	LastValue      string
 	ConnectedSince time.Time
+	CertSubject    string
+	CertNotAfter   time.Time
+	StreamSkew     time.Duration // notification timestamps behind receive time, when measured
+	StreamSkewMeasured bool
+	StreamSkewHigh bool // beyond the stream_skew threshold
+	Sessions       int // gNMI sessions held to the device
+	PeakSessions   int // most held at once since the collector started
+	SessionLimit   int // of the device's profile, 0 when it names none
+	SessionsHigh   bool // the peak reached the gnmi_sessions warn threshold
+	PollInterval   time.Duration // set when polled with gNMI Get instead of subscribed
+	MgmtChecks     []mgmtcheck.Result
+	Inventory      *inventory.Snapshot
+	Capabilities   *capabilities.Snapshot // gNMI capabilities at the last refresh
+	CapabilityChange *capabilities.Change // most recent change between refreshes
 	Interfaces     []InterfaceInfo
 	Logs           []webui.LogEntry
 }
@@ -533,9 +899,14 @@ type DeviceDetailInfo struct {
 type InterfaceInfo struct {
 	Name          string
 	Description   string
+	Role          string
 	DesiredState  string
 	AdminState    string
 	Alerts        config.AlertSeverity
+	ActualOper    string
+	ActualAdmin   string
+	Deviating     bool
+	Optic         *inventory.Transceiver
 }
 
 // handleDevicePage renders the device detail page
@@ -547,6 +918,10 @@ func (s *Server) handleDevicePage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	deviceName := path
+	if name, ok := strings.CutSuffix(path, "/report"); ok {
+		s.handleDeviceReport(w, r, name)
+		return
+	}
 
 	s.reloadMu.RLock()
 	cfg := s.config
@@ -586,13 +961,26 @@ func (s *Server) handleDevicePage(w http.ResponseWriter, r *http.Request) {
 	// Build interface list
 	interfaces := make([]InterfaceInfo, 0)
 	for ifaceName, ifaceCfg := range deviceCfg.Interfaces {
-		interfaces = append(interfaces, InterfaceInfo{
+		if s.interfaceIgnored(cfg, deviceName, ifaceName) {
+			continue
+		}
+		info := InterfaceInfo{
 			Name:         ifaceName,
 			Description:  ifaceCfg.Description,
-			DesiredState: ifaceCfg.DesiredState,
+			Role:         ifaceCfg.Role,
+			DesiredState: ifaceCfg.DesiredStateAt(time.Now()),
 			AdminState:   ifaceCfg.AdminState,
 			Alerts:       ifaceCfg.Alerts,
-		})
+			Optic:        s.deviceTransceiver(deviceName, ifaceName),
+		}
+		if s.evaluator != nil {
+			if observed, ok := s.evaluator.GetInterfaceState(deviceName, ifaceName); ok {
+				info.ActualOper = observed.OperStatus
+				info.ActualAdmin = observed.AdminStatus
+				info.Deviating = observed.DeviatedSince != nil
+			}
+		}
+		interfaces = append(interfaces, info)
 	}
 
 	// Get device-specific logs
@@ -619,21 +1007,44 @@ func (s *Server) handleDevicePage(w http.ResponseWriter, r *http.Request) {
 		Connected:      health.Connected,
 		LastUpdate:     health.LastUpdate,
 		LastError:      health.LastError,
+		SubscribeError: health.SubscribeError,
+		SubscribeHint:  health.SubscribeHint,
 		ReconnectCount: health.ReconnectCount,
 		UpdateCount:    health.UpdateCount,
 		SyncReceived:   health.SyncReceived,
 		LastPath:       health.LastPath,
 		LastValue:      health.LastValue,
 		ConnectedSince: health.ConnectedSince,
+		CertSubject:    health.CertSubject,
+		CertNotAfter:   health.CertNotAfter,
+		StreamSkew:     health.StreamSkew.Round(time.Millisecond),
+		StreamSkewMeasured: health.StreamSkewMeasured,
+		Sessions:       health.Sessions,
+		PeakSessions:   health.PeakSessions,
+		SessionLimit:   cfg.SessionLimitFor(deviceCfg),
+		PollInterval:   health.PollInterval,
+		MgmtChecks:     s.mgmtResults(deviceName),
+		Inventory:      s.deviceInventory(deviceName),
 		Interfaces:     interfaces,
 		Logs:           deviceLogs,
 	}
 
+	deviceDetail.Capabilities, deviceDetail.CapabilityChange = s.deviceCapabilities(deviceName)
+
+	if threshold := cfg.DesiredState.Global.StreamSkew.ThresholdOrDefault(); health.StreamSkew >= threshold || health.StreamSkew <= -threshold {
+		deviceDetail.StreamSkewHigh = health.StreamSkewMeasured
+	}
+	if limit := deviceDetail.SessionLimit; limit > 0 {
+		deviceDetail.SessionsHigh = health.PeakSessions >= cfg.DesiredState.Global.GNMISessions.WarnAt(limit)
+	}
+
+	_, userPrefs := s.userPreferences(r)
 	data := DevicePageData{
-		Device:    deviceDetail,
-		Version:   version,
-		Commit:    commit,
-		BuildDate: buildDate,
+		Device:      deviceDetail,
+		Preferences: userPrefs,
+		Version:     version,
+		Commit:      commit,
+		BuildDate:   buildDate,
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
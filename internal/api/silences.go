@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/netspec/netspec/internal/alerter"
+)
+
+// silenceRequest is the body accepted by POST /api/silences. At least one
+// matcher is required; those left empty match any alert.
+type silenceRequest struct {
+	Device    string `json:"device,omitempty"`
+	Entity    string `json:"entity,omitempty"` // glob, e.g. "Ethernet1/*"
+	AlertType string `json:"alert_type,omitempty"`
+	Severity  string `json:"severity,omitempty"`
+	Duration  string `json:"duration"` // e.g. "2h" or "1d"
+	By        string `json:"by,omitempty"`
+}
+
+// handleSilencesAPI lists the active silences on GET, adds a silence for
+// the alerts matching the given device, entity glob, alert type and
+// severity on POST, and on DELETE ends a silence early, by ?id= or every
+// silence of a ?device=. It backs the web UI's maintenance toggle and the
+// dashboard's silences card.
+func (s *Server) handleSilencesAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		silences := s.alertEngine.GetSilences()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"silences": silences,
+			"count":    len(silences),
+		})
+
+	case http.MethodPost:
+		var req silenceRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxInboundBody)).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		s.reloadMu.RLock()
+		cfg := s.config
+		s.reloadMu.RUnlock()
+		if cfg == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "configuration not loaded")
+			return
+		}
+		if req.Device == "" && req.Entity == "" && req.AlertType == "" && req.Severity == "" {
+			writeJSONError(w, http.StatusBadRequest, "at least one of device, entity, alert_type or severity is required")
+			return
+		}
+		if req.Device != "" {
+			if _, ok := cfg.DesiredState.Devices[req.Device]; !ok {
+				writeJSONError(w, http.StatusNotFound, fmt.Sprintf("unknown device %q", req.Device))
+				return
+			}
+		}
+		if req.Severity != "" {
+			level, _, ok := cfg.Alerts.SeverityLevel(req.Severity)
+			if !ok {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("unknown severity %q", req.Severity))
+				return
+			}
+			req.Severity = level.Name
+		}
+		duration, err := parseChatDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "duration must be positive, e.g. \"2h\"")
+			return
+		}
+		if duration > maxSilenceDuration {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("silences are limited to %s", formatDuration(maxSilenceDuration)))
+			return
+		}
+		by := req.By
+		if by == "" {
+			by = s.requestUser(r)
+		}
+
+		silence := s.alertEngine.AddSilence(alerter.SilenceMatchers{
+			Device:    req.Device,
+			Entity:    req.Entity,
+			AlertType: req.AlertType,
+			Severity:  req.Severity,
+		}, duration, by)
+		s.audit(r, "silence").
+			Str("silence_id", silence.ID).
+			Str("device", req.Device).
+			Str("entity", req.Entity).
+			Str("alert_type", req.AlertType).
+			Str("severity", req.Severity).
+			Dur("duration", duration).
+			Str("by", by).
+			Msg("Alerts silenced")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"silence": silence,
+		})
+
+	case http.MethodDelete:
+		id, device := r.URL.Query().Get("id"), r.URL.Query().Get("device")
+		if id == "" && device == "" {
+			writeJSONError(w, http.StatusBadRequest, "id or device is required")
+			return
+		}
+		removed := 0
+		for _, silence := range s.alertEngine.GetSilences() {
+			if (id != "" && silence.ID == id) || (device != "" && silence.Device == device) {
+				if s.alertEngine.RemoveSilence(silence.ID) {
+					removed++
+				}
+			}
+		}
+		if removed == 0 {
+			writeJSONError(w, http.StatusNotFound, "no matching silence")
+			return
+		}
+		s.audit(r, "unsilence").
+			Str("id", id).
+			Str("device", device).
+			Int("removed", removed).
+			Msg("Silence ended")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"removed": removed,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
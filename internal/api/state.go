@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/netspec/netspec/internal/evaluator"
+)
+
+// handleStateAPI returns the evaluator's raw cached interface state,
+// optionally filtered by ?device= and ?interface=
+func (s *Server) handleStateAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	device := r.URL.Query().Get("device")
+	iface := r.URL.Query().Get("interface")
+
+	states := make([]evaluator.InterfaceState, 0)
+	if s.evaluator != nil {
+		var candidates []evaluator.InterfaceState
+		if device != "" {
+			candidates = s.evaluator.GetDeviceState(device)
+		} else {
+			candidates = s.evaluator.GetAllState()
+		}
+		for _, state := range candidates {
+			if iface != "" && state.Interface != iface {
+				continue
+			}
+			states = append(states, state)
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"state": states,
+		"count": len(states),
+	})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/hotspot"
+)
+
+const (
+	defaultTopWindow = time.Hour
+	defaultTopLimit  = 10
+	maxTopLimit      = 100
+	// dashboardTopLimit is how many hotspots the dashboard card lists
+	dashboardTopLimit = 5
+)
+
+// topWindows are the windows offered on the dashboard hotspot card
+var topWindows = []string{"1h", "6h", "24h"}
+
+// HotspotCard holds the dashboard's top-N card
+type HotspotCard struct {
+	Metric  string
+	Window  string
+	Windows []string
+	Entries []hotspot.Entry
+}
+
+// handleTopAPI returns the interfaces with the most activity of a metric
+// over a window, e.g. /api/top?metric=errors&window=6h&limit=20
+func (s *Server) handleTopAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	metric := query.Get("metric")
+	if metric == "" {
+		metric = hotspot.MetricUtilization
+	}
+	if !hotspot.ValidMetric(metric) {
+		writeJSONError(w, http.StatusBadRequest, "metric must be utilization, errors or flaps")
+		return
+	}
+	window := defaultTopWindow
+	if v := query.Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > hotspot.Retention {
+			writeJSONError(w, http.StatusBadRequest, "window must be a duration up to "+hotspot.Retention.String())
+			return
+		}
+		window = d
+	}
+	limit := defaultTopLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxTopLimit {
+			writeJSONError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxTopLimit))
+			return
+		}
+		limit = n
+	}
+
+	entries := s.topInterfaces(metric, window, limit)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"metric":  metric,
+		"window":  window.String(),
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// topInterfaces ranks interfaces by a metric, filling in utilization for
+// interfaces whose capacity block declares their speed
+func (s *Server) topInterfaces(metric string, window time.Duration, limit int) []hotspot.Entry {
+	if s.hotspots == nil {
+		return []hotspot.Entry{}
+	}
+	s.reloadMu.RLock()
+	cfg := s.config
+	s.reloadMu.RUnlock()
+
+	entries := s.hotspots.Top(metric, window, limit, time.Now())
+	for i := range entries {
+		if speed := declaredSpeed(cfg, entries[i].Device, entries[i].Interface); speed > 0 {
+			busier := entries[i].ReceiveBPS
+			if entries[i].TransmitBPS > busier {
+				busier = entries[i].TransmitBPS
+			}
+			entries[i].Utilization = busier / speed * 100
+		}
+	}
+	return entries
+}
+
+// hotspotCard builds the dashboard card from the hotspot and window query
+// parameters
+func (s *Server) hotspotCard(r *http.Request) HotspotCard {
+	card := HotspotCard{Metric: r.URL.Query().Get("hotspot"), Window: r.URL.Query().Get("window"), Windows: topWindows}
+	if !hotspot.ValidMetric(card.Metric) {
+		card.Metric = hotspot.MetricUtilization
+	}
+	window, err := time.ParseDuration(card.Window)
+	if err != nil || window <= 0 || window > hotspot.Retention {
+		card.Window, window = topWindows[0], defaultTopWindow
+	}
+	card.Entries = s.topInterfaces(card.Metric, window, dashboardTopLimit)
+	return card
+}
+
+// declaredSpeed returns an interface's speed from its capacity block, or 0
+func declaredSpeed(cfg *config.Config, device, iface string) float64 {
+	if cfg == nil {
+		return 0
+	}
+	capacity := cfg.DesiredState.Devices[device].Interfaces[iface].Capacity
+	if capacity == nil {
+		return 0
+	}
+	speed, err := capacity.SpeedBPS()
+	if err != nil {
+		return 0
+	}
+	return speed
+}
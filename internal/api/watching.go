@@ -0,0 +1,90 @@
+package api
+
+import (
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/prefs"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// WatchInfo is one row of the dashboard's Watching card: a device or
+// interface a user pinned while keeping an eye on a change
+type WatchInfo struct {
+	Device        string
+	Interface     string // empty for a whole device
+	Missing       bool   // no longer in the configuration
+	Connected     bool
+	LastUpdate    time.Time
+	DesiredState  string
+	ActualOper    string
+	Deviating     bool
+	DeviatedSince *time.Time
+	Deviations    int // deviating interfaces, for a whole device
+	AlertCount    int
+	Severity      string // most severe active alert
+	SeverityColor string
+}
+
+// watchingCard builds the Watching card rows for a user's pinned devices
+// and interfaces, in the order they were pinned
+func (s *Server) watchingCard(cfg *config.Config, p prefs.Preferences, alerts []*types.Alert) []WatchInfo {
+	rows := make([]WatchInfo, 0, len(p.Watching))
+	if cfg == nil {
+		return rows
+	}
+	s.collectorMu.RLock()
+	getter := s.collectorGetter
+	s.collectorMu.RUnlock()
+
+	for _, w := range p.Watching {
+		row := WatchInfo{Device: w.Device, Interface: w.Interface}
+		device, ok := cfg.DesiredState.Devices[w.Device]
+		ifCfg, ifOK := device.Interfaces[w.Interface]
+		if !ok || (w.Interface != "" && !ifOK) {
+			row.Missing = true
+			rows = append(rows, row)
+			continue
+		}
+		if getter != nil {
+			if col := getter(w.Device); col != nil {
+				health := col.Health()
+				row.Connected = health.Connected
+				row.LastUpdate = health.LastUpdate
+			}
+		}
+
+		if w.Interface != "" {
+			row.DesiredState = ifCfg.DesiredStateAt(time.Now())
+			if s.evaluator != nil {
+				if observed, ok := s.evaluator.GetInterfaceState(w.Device, w.Interface); ok {
+					row.ActualOper = observed.OperStatus
+					row.Deviating = observed.DeviatedSince != nil
+					row.DeviatedSince = observed.DeviatedSince
+				}
+			}
+		} else if s.evaluator != nil {
+			for ifName := range device.Interfaces {
+				if observed, ok := s.evaluator.GetInterfaceState(w.Device, ifName); ok && observed.DeviatedSince != nil {
+					row.Deviations++
+				}
+			}
+			row.Deviating = row.Deviations > 0
+		}
+
+		for _, alert := range alerts {
+			if alert.Device != w.Device || (w.Interface != "" && alert.Entity != w.Interface) {
+				continue
+			}
+			row.AlertCount++
+			if row.Severity == "" || cfg.Alerts.SeverityRank(alert.Severity) < cfg.Alerts.SeverityRank(row.Severity) {
+				row.Severity = alert.Severity
+			}
+		}
+		if row.Severity != "" {
+			row.SeverityColor = cfg.Alerts.SeverityColor(row.Severity)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,465 @@
+// Package capabilities re-reads the gNMI Capabilities of every device on a
+// schedule, a few devices at a time, and records how the models, encodings
+// and gNMI version each advertises change, so a software upgrade that
+// changes what a device can stream is noticed.
+package capabilities
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/netspec/netspec/internal/store"
+	"github.com/rs/zerolog"
+)
+
+// AlertType fires when a refresh finds a device advertising different gNMI
+// capabilities than it did at the previous refresh
+const AlertType = "gnmi_capabilities_changed"
+
+// Entity is the alert entity of a device's gNMI capabilities
+const Entity = "gnmi capabilities"
+
+const (
+	// storeSection is the store section holding snapshots and changes
+	storeSection = "capabilities"
+	// maxChanges bounds the changes kept per device
+	maxChanges = 20
+)
+
+// Model is a YANG model a device supports
+type Model struct {
+	Name         string `json:"name"`
+	Organization string `json:"organization,omitempty"`
+	Version      string `json:"version,omitempty"`
+}
+
+// Snapshot is what a device advertised at one Capabilities read
+type Snapshot struct {
+	CollectedAt     time.Time `json:"collected_at"`
+	GNMIVersion     string    `json:"gnmi_version"`
+	Encodings       []string  `json:"encodings"`                  // sorted
+	Models          []Model   `json:"models"`                     // sorted by name
+	SoftwareVersion string    `json:"software_version,omitempty"` // from the inventory, when read
+}
+
+// ModelUpdate is a model whose advertised version changed
+type ModelUpdate struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Change is how a device's capabilities or software version differ from
+// the previous refresh
+type Change struct {
+	At               time.Time     `json:"at"`
+	SoftwareFrom     string        `json:"software_from,omitempty"`
+	SoftwareTo       string        `json:"software_to,omitempty"`
+	GNMIFrom         string        `json:"gnmi_version_from,omitempty"`
+	GNMITo           string        `json:"gnmi_version_to,omitempty"`
+	EncodingsAdded   []string      `json:"encodings_added,omitempty"`
+	EncodingsRemoved []string      `json:"encodings_removed,omitempty"`
+	ModelsAdded      []Model       `json:"models_added,omitempty"`
+	ModelsRemoved    []Model       `json:"models_removed,omitempty"`
+	ModelsUpdated    []ModelUpdate `json:"models_updated,omitempty"`
+}
+
+// Upgraded reports whether the software version changed
+func (c Change) Upgraded() bool {
+	return c.SoftwareFrom != c.SoftwareTo
+}
+
+// Capabilities reports whether what the device advertises changed, as
+// opposed to only its software version
+func (c Change) Capabilities() bool {
+	return c.GNMIFrom != c.GNMITo || len(c.EncodingsAdded) > 0 || len(c.EncodingsRemoved) > 0 ||
+		len(c.ModelsAdded) > 0 || len(c.ModelsRemoved) > 0 || len(c.ModelsUpdated) > 0
+}
+
+// Summary describes the change in one line
+func (c Change) Summary() string {
+	summary := c.capabilitySummary()
+	if !c.Upgraded() {
+		return summary
+	}
+	upgrade := fmt.Sprintf("software %s -> %s", c.SoftwareFrom, c.SoftwareTo)
+	if summary == "" {
+		return upgrade
+	}
+	return upgrade + ": " + summary
+}
+
+// capabilitySummary describes the change to what the device advertises
+func (c Change) capabilitySummary() string {
+	var parts []string
+	if c.GNMIFrom != c.GNMITo {
+		parts = append(parts, fmt.Sprintf("gNMI %s -> %s", c.GNMIFrom, c.GNMITo))
+	}
+	if n := len(c.ModelsAdded); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d models added", n))
+	}
+	if n := len(c.ModelsRemoved); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d models removed", n))
+	}
+	if n := len(c.ModelsUpdated); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d model versions changed", n))
+	}
+	if len(c.EncodingsAdded) > 0 {
+		parts = append(parts, "encodings added "+strings.Join(c.EncodingsAdded, ","))
+	}
+	if len(c.EncodingsRemoved) > 0 {
+		parts = append(parts, "encodings removed "+strings.Join(c.EncodingsRemoved, ","))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diff returns how cur differs from prev. A software version is compared
+// only once both reads have one, as the inventory may not have been read
+// yet.
+func diff(prev, cur Snapshot) (Change, bool) {
+	change := Change{At: cur.CollectedAt}
+	if prev.SoftwareVersion != "" && cur.SoftwareVersion != "" && prev.SoftwareVersion != cur.SoftwareVersion {
+		change.SoftwareFrom, change.SoftwareTo = prev.SoftwareVersion, cur.SoftwareVersion
+	}
+	if prev.GNMIVersion != cur.GNMIVersion {
+		change.GNMIFrom, change.GNMITo = prev.GNMIVersion, cur.GNMIVersion
+	}
+	change.EncodingsAdded, change.EncodingsRemoved = diffStrings(prev.Encodings, cur.Encodings)
+
+	before := make(map[string]Model, len(prev.Models))
+	for _, m := range prev.Models {
+		before[m.Name] = m
+	}
+	for _, m := range cur.Models {
+		old, ok := before[m.Name]
+		switch {
+		case !ok:
+			change.ModelsAdded = append(change.ModelsAdded, m)
+		case old.Version != m.Version:
+			change.ModelsUpdated = append(change.ModelsUpdated, ModelUpdate{Name: m.Name, From: old.Version, To: m.Version})
+		}
+		delete(before, m.Name)
+	}
+	for _, m := range prev.Models {
+		if _, ok := before[m.Name]; ok {
+			change.ModelsRemoved = append(change.ModelsRemoved, m)
+		}
+	}
+	return change, change.Upgraded() || change.Capabilities()
+}
+
+// diffStrings returns the entries of sorted lists b not in a and a not in b
+func diffStrings(a, b []string) (added, removed []string) {
+	in := func(list []string, s string) bool {
+		i := sort.SearchStrings(list, s)
+		return i < len(list) && list[i] == s
+	}
+	for _, s := range b {
+		if !in(a, s) {
+			added = append(added, s)
+		}
+	}
+	for _, s := range a {
+		if !in(b, s) {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
+
+// ReadFunc reads a device's capabilities
+type ReadFunc func(device string) (Snapshot, error)
+
+// deviceState is a device's last snapshot and recorded changes, as
+// persisted
+type deviceState struct {
+	Snapshot Snapshot `json:"snapshot"`
+	Changes  []Change `json:"changes"`           // oldest first
+	Alerted  bool     `json:"alerted,omitempty"` // a change alert is raised
+}
+
+// Refresher re-reads every device's capabilities and keeps their history
+type Refresher struct {
+	logger  zerolog.Logger
+	read    ReadFunc
+	mu      sync.Mutex
+	config  *config.Config
+	devices map[string]*deviceState
+	store   *store.Store
+	dirty   bool
+}
+
+// New creates a refresher reading devices with read
+func New(cfg *config.Config, logger zerolog.Logger, read ReadFunc) *Refresher {
+	return &Refresher{
+		logger:  logger.With().Str("component", "capabilities").Logger(),
+		read:    read,
+		config:  cfg,
+		devices: make(map[string]*deviceState),
+	}
+}
+
+// SetConfig swaps in a reloaded configuration, dropping devices no longer
+// in it
+func (r *Refresher) SetConfig(cfg *config.Config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.config = cfg
+	for device := range r.devices {
+		if _, ok := cfg.DesiredState.Devices[device]; !ok {
+			delete(r.devices, device)
+			r.dirty = true
+		}
+	}
+}
+
+// SetStore restores snapshots and changes from st and persists them there
+// from now on, so a change across a restart is still noticed
+func (r *Refresher) SetStore(st *store.Store) error {
+	var devices map[string]*deviceState
+	if _, err := st.Load(storeSection, &devices); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.store = st
+	for device, state := range devices {
+		if _, ok := r.devices[device]; !ok && state != nil {
+			r.devices[device] = state
+		}
+	}
+	r.logger.Info().
+		Str("path", st.Path()).
+		Int("devices", len(devices)).
+		Msg("Capabilities history restored")
+	return nil
+}
+
+// Device returns a device's last snapshot and its recorded changes, newest
+// first
+func (r *Refresher) Device(device string) (Snapshot, []Change, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.devices[device]
+	if !ok {
+		return Snapshot{}, nil, false
+	}
+	changes := make([]Change, 0, len(state.Changes))
+	for i := len(state.Changes) - 1; i >= 0; i-- {
+		changes = append(changes, state.Changes[i])
+	}
+	return state.Snapshot, changes, true
+}
+
+// Run refreshes every device now and then every interval until ctx is
+// cancelled. fire is called when a device's capabilities change, and clear
+// at the next refresh that finds them unchanged.
+func (r *Refresher) Run(ctx context.Context, fire func(evaluator.StateChange), clear func(device string)) {
+	for {
+		r.mu.Lock()
+		interval := r.config.DesiredState.Global.CapabilitiesRefresh.IntervalOrDefault()
+		r.mu.Unlock()
+
+		changes, cleared := r.refresh(ctx)
+		for _, change := range changes {
+			fire(change)
+		}
+		for _, device := range cleared {
+			clear(device)
+		}
+		if err := r.save(); err != nil {
+			r.logger.Error().Err(err).Msg("Failed to persist capabilities history")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// refresh reads every monitored device, at most concurrency at once, and
+// returns alerts for devices whose capabilities changed and the devices
+// whose alert clears. Devices expected offline or outside their monitoring
+// window are skipped.
+func (r *Refresher) refresh(ctx context.Context) ([]evaluator.StateChange, []string) {
+	r.mu.Lock()
+	cfg := r.config
+	r.mu.Unlock()
+
+	now := time.Now()
+	var names []string
+	for name, dev := range cfg.DesiredState.Devices {
+		if !dev.ExpectedOffline() && !dev.Suspended(now) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		changes []evaluator.StateChange
+		cleared []string
+	)
+	slots := make(chan struct{}, cfg.DesiredState.Global.CapabilitiesRefresh.ConcurrencyOrDefault())
+	for _, name := range names {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return changes, cleared
+		case slots <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			snap, err := r.read(name)
+			if err != nil {
+				r.logger.Warn().Err(err).Str("device", name).Msg("Failed to refresh gNMI capabilities")
+				return
+			}
+			change, clear := r.record(cfg, name, snap)
+			mu.Lock()
+			defer mu.Unlock()
+			if change != nil {
+				changes = append(changes, *change)
+			}
+			if clear {
+				cleared = append(cleared, name)
+			}
+		}(name)
+	}
+	wg.Wait()
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Device < changes[j].Device })
+	sort.Strings(cleared)
+	return changes, cleared
+}
+
+// record stores a device's new snapshot, recording any change from the
+// previous one. It returns the alert to raise when the capabilities
+// changed, and whether a raised alert clears because they did not.
+func (r *Refresher) record(cfg *config.Config, device string, snap Snapshot) (*evaluator.StateChange, bool) {
+	sort.Strings(snap.Encodings)
+	sort.Slice(snap.Models, func(i, j int) bool { return snap.Models[i].Name < snap.Models[j].Name })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dirty = true
+	state, known := r.devices[device]
+	if !known {
+		r.devices[device] = &deviceState{Snapshot: snap}
+		return nil, false
+	}
+	prev := state.Snapshot
+	state.Snapshot = snap
+	if snap.SoftwareVersion == "" {
+		state.Snapshot.SoftwareVersion = prev.SoftwareVersion
+	}
+
+	change, changed := diff(prev, snap)
+	if changed {
+		state.Changes = append(state.Changes, change)
+		if len(state.Changes) > maxChanges {
+			state.Changes = state.Changes[len(state.Changes)-maxChanges:]
+		}
+		r.logger.Info().
+			Str("device", device).
+			Str("change", change.Summary()).
+			Msg("gNMI capabilities changed")
+	}
+	if !change.Capabilities() {
+		clear := state.Alerted
+		state.Alerted = false
+		return nil, clear
+	}
+	state.Alerted = true
+	return alert(cfg, device, change), false
+}
+
+// alert builds the gnmi_capabilities_changed alert for a change
+func alert(cfg *config.Config, device string, change Change) *evaluator.StateChange {
+	message := "gNMI capabilities changed: " + change.capabilitySummary()
+	if change.Upgraded() {
+		message = fmt.Sprintf("gNMI capabilities changed after upgrade from %s to %s: %s",
+			change.SoftwareFrom, change.SoftwareTo, change.capabilitySummary())
+	}
+	related := map[string]string{
+		"gnmi_version": change.GNMITo,
+	}
+	if change.Upgraded() {
+		related["software_from"] = change.SoftwareFrom
+		related["software_to"] = change.SoftwareTo
+	}
+	if change.GNMIFrom != change.GNMITo {
+		related["gnmi_version_from"] = change.GNMIFrom
+	}
+	if names := modelNames(change.ModelsAdded); names != "" {
+		related["models_added"] = names
+	}
+	if names := modelNames(change.ModelsRemoved); names != "" {
+		related["models_removed"] = names
+	}
+	if len(change.ModelsUpdated) > 0 {
+		updated := make([]string, 0, len(change.ModelsUpdated))
+		for _, u := range change.ModelsUpdated {
+			updated = append(updated, u.Name+" "+u.From+" -> "+u.To)
+		}
+		related["models_updated"] = strings.Join(updated, ", ")
+	}
+	return &evaluator.StateChange{
+		Device:       device,
+		Interface:    Entity,
+		AlertType:    AlertType,
+		Severity:     cfg.DesiredState.Global.CapabilitiesRefresh.SeverityOrDefault(),
+		Firing:       true,
+		Message:      message,
+		RelatedState: related,
+		ObservedAt:   change.At,
+	}
+}
+
+// modelNames joins the names of models
+func modelNames(models []Model) string {
+	names := make([]string, 0, len(models))
+	for _, m := range models {
+		names = append(names, m.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// save writes the snapshots and changes to the store if they changed
+func (r *Refresher) save() error {
+	r.mu.Lock()
+	if r.store == nil || !r.dirty {
+		r.mu.Unlock()
+		return nil
+	}
+	st := r.store
+	devices := make(map[string]deviceState, len(r.devices))
+	for device, state := range r.devices {
+		devices[device] = deviceState{
+			Snapshot: state.Snapshot,
+			Changes:  append([]Change(nil), state.Changes...),
+			Alerted:  state.Alerted,
+		}
+	}
+	r.dirty = false
+	r.mu.Unlock()
+
+	if err := st.Save(storeSection, devices); err != nil {
+		r.mu.Lock()
+		r.dirty = true
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
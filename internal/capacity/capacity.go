@@ -0,0 +1,424 @@
+// Package capacity trends the utilization of uplinks declared with a
+// capacity block. Measured bit rates are averaged per hour, the busiest hour
+// of each day is kept as that day's peak, and a linear fit over the daily
+// peaks projects when the link will cross its threshold. Links projected to
+// cross it within their horizon raise a low-priority advisory.
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/netspec/netspec/internal/store"
+	"github.com/rs/zerolog"
+)
+
+// AlertType is the alert type of capacity advisories
+const AlertType = "capacity_forecast"
+
+const (
+	// storeSection is the store section holding utilization history
+	storeSection = "capacity"
+	// retainDays is how many daily peaks are kept per link and direction
+	retainDays = 90
+	// minDays is how many complete days a trend needs before it is projected
+	minDays = 7
+	// checkInterval is how often forecasts are recomputed and persisted
+	checkInterval = time.Hour
+	// flatSlope is the growth per day, as a fraction of the link speed,
+	// below which a trend is treated as flat
+	flatSlope = 1e-6
+)
+
+// DayPeak is the busiest hourly average bit rate of one day
+type DayPeak struct {
+	Day  time.Time `json:"day"`
+	Peak float64   `json:"peak_bps"`
+}
+
+// history is one link direction's persisted utilization history
+type history struct {
+	Hour  time.Time `json:"hour"`  // start of the hour being averaged
+	Sum   float64   `json:"sum"`   // sum of the hour's bit rate samples
+	Count int       `json:"count"` // number of the hour's samples
+	Days  []DayPeak `json:"days"`  // oldest first
+}
+
+// fold closes the hour being averaged, raising its day's peak
+func (h *history) fold() {
+	if h.Count == 0 {
+		return
+	}
+	avg := h.Sum / float64(h.Count)
+	day := h.Hour.UTC().Truncate(24 * time.Hour)
+	if n := len(h.Days); n > 0 && h.Days[n-1].Day.Equal(day) {
+		h.Days[n-1].Peak = math.Max(h.Days[n-1].Peak, avg)
+	} else {
+		h.Days = append(h.Days, DayPeak{Day: day, Peak: avg})
+		if len(h.Days) > retainDays {
+			h.Days = h.Days[len(h.Days)-retainDays:]
+		}
+	}
+	h.Sum, h.Count = 0, 0
+}
+
+// Forecast is the utilization trend of one link direction
+type Forecast struct {
+	Device           string   `json:"device"`
+	Interface        string   `json:"interface"`
+	Direction        string   `json:"direction"`
+	SpeedBPS         float64  `json:"speed_bps"`
+	Days             int      `json:"days"`                  // complete days of history
+	PeakPercent      float64  `json:"peak_percent"`          // latest complete day's peak
+	TrendPercent     float64  `json:"trend_percent_per_day"` // fitted growth per day
+	ThresholdPercent float64  `json:"threshold_percent"`
+	DaysToThreshold  *float64 `json:"days_to_threshold"` // nil when not projected to cross
+	Advisory         bool     `json:"advisory"`          // crosses within the horizon
+}
+
+// Ready reports whether enough history exists to project a trend
+func (f Forecast) Ready() bool {
+	return f.Days >= minDays
+}
+
+// ETA describes when the threshold is projected to be crossed
+func (f Forecast) ETA() string {
+	switch {
+	case !f.Ready():
+		return fmt.Sprintf("learning (%d/%d days)", f.Days, minDays)
+	case f.DaysToThreshold == nil:
+		return "not projected"
+	case *f.DaysToThreshold == 0:
+		return "now"
+	}
+	return fmt.Sprintf("in %.0f days", math.Ceil(*f.DaysToThreshold))
+}
+
+// Tracker records uplink utilization and projects capacity trends
+type Tracker struct {
+	logger  zerolog.Logger
+	mu      sync.Mutex
+	config  *config.Config
+	links   map[string]*history // device|interface|direction -> history
+	advised map[string]bool     // device|interface with an advisory raised
+	store   *store.Store
+	dirty   bool
+}
+
+// NewTracker creates a tracker for the capacity-tracked interfaces of cfg
+func NewTracker(cfg *config.Config, logger zerolog.Logger) *Tracker {
+	return &Tracker{
+		logger:  logger,
+		config:  cfg,
+		links:   make(map[string]*history),
+		advised: make(map[string]bool),
+	}
+}
+
+// SetConfig replaces the configuration used to find tracked interfaces.
+// History of interfaces no longer tracked is kept until it ages out, so
+// briefly removing a capacity block does not lose the trend.
+func (t *Tracker) SetConfig(cfg *config.Config) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.config = cfg
+}
+
+// SetStore restores utilization history from st and persists it there from
+// now on
+func (t *Tracker) SetStore(st *store.Store) error {
+	var links map[string]*history
+	if _, err := st.Load(storeSection, &links); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store = st
+	for key, h := range links {
+		if _, ok := t.links[key]; !ok && h != nil {
+			t.links[key] = h
+		}
+	}
+	t.logger.Info().
+		Str("path", st.Path()).
+		Int("links", len(links)).
+		Msg("capacity history restored")
+	return nil
+}
+
+// Record adds a measured bit rate. It is registered as an evaluator rate
+// hook and ignores interfaces without a capacity block.
+func (t *Tracker) Record(sample evaluator.RateSample) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.capacityConfig(sample.Device, sample.Interface) == nil {
+		return
+	}
+
+	key := sample.Device + "|" + sample.Interface + "|" + sample.Direction
+	h, ok := t.links[key]
+	if !ok {
+		h = &history{}
+		t.links[key] = h
+	}
+	hour := sample.At.UTC().Truncate(time.Hour)
+	if hour.Before(h.Hour) {
+		return // late sample for an hour already folded
+	}
+	if hour.After(h.Hour) {
+		h.fold()
+		h.Hour = hour
+	}
+	h.Sum += sample.BPS
+	h.Count++
+	t.dirty = true
+}
+
+// capacityConfig returns the capacity block of an interface, if it has one.
+// The caller must hold t.mu.
+func (t *Tracker) capacityConfig(device, iface string) *config.CapacityConfig {
+	if t.config == nil {
+		return nil
+	}
+	return t.config.DesiredState.Devices[device].Interfaces[iface].Capacity
+}
+
+// Forecasts projects the trend of every tracked link direction, sorted by
+// device, interface and direction
+func (t *Tracker) Forecasts() []Forecast {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.forecasts(time.Now())
+}
+
+// forecasts computes the current forecasts. The caller must hold t.mu.
+func (t *Tracker) forecasts(now time.Time) []Forecast {
+	today := now.UTC().Truncate(24 * time.Hour)
+	forecasts := []Forecast{}
+	for key, h := range t.links {
+		parts := strings.SplitN(key, "|", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		capacity := t.capacityConfig(parts[0], parts[1])
+		if capacity == nil {
+			continue
+		}
+		speed, err := capacity.SpeedBPS()
+		if err != nil {
+			continue
+		}
+		threshold, horizon := capacity.Limits()
+
+		// The current day is still accumulating, so only complete days
+		// are fitted
+		var days []DayPeak
+		for _, d := range h.Days {
+			if d.Day.Before(today) {
+				days = append(days, d)
+			}
+		}
+		f := Forecast{
+			Device:           parts[0],
+			Interface:        parts[1],
+			Direction:        parts[2],
+			SpeedBPS:         speed,
+			Days:             len(days),
+			ThresholdPercent: threshold * 100,
+		}
+		if len(days) > 0 {
+			f.PeakPercent = days[len(days)-1].Peak / speed * 100
+		}
+		if f.Ready() {
+			slope, fitted := trend(days, speed)
+			f.TrendPercent = slope * 100
+			var eta float64
+			switch {
+			case fitted >= threshold:
+				eta = 0
+			case slope > flatSlope:
+				eta = (threshold - fitted) / slope
+			default:
+				eta = -1
+			}
+			if eta >= 0 {
+				f.DaysToThreshold = &eta
+				f.Advisory = eta <= horizon.Hours()/24
+			}
+		}
+		forecasts = append(forecasts, f)
+	}
+	sort.Slice(forecasts, func(i, j int) bool {
+		a, b := forecasts[i], forecasts[j]
+		if a.Device != b.Device {
+			return a.Device < b.Device
+		}
+		if a.Interface != b.Interface {
+			return a.Interface < b.Interface
+		}
+		return a.Direction < b.Direction
+	})
+	return forecasts
+}
+
+// trend fits a least-squares line to the daily peak utilization and returns
+// its slope per day and its value on the last day, both as fractions of the
+// link speed
+func trend(days []DayPeak, speed float64) (slope, fitted float64) {
+	first := days[0].Day
+	var sumX, sumY, sumXY, sumXX float64
+	for _, d := range days {
+		x := d.Day.Sub(first).Hours() / 24
+		y := d.Peak / speed
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(days))
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0, sumY / n
+	}
+	slope = (n*sumXY - sumX*sumY) / denom
+	intercept := (sumY - slope*sumX) / n
+	lastX := days[len(days)-1].Day.Sub(first).Hours() / 24
+	return slope, intercept + slope*lastX
+}
+
+// Run recomputes forecasts and persists history every hour until ctx is
+// cancelled. fire is called when an interface is first projected to cross
+// its threshold within the horizon, and clear once it no longer is.
+func (t *Tracker) Run(ctx context.Context, fire func(evaluator.StateChange), clear func(device, iface string)) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			changes, cleared := t.check(now)
+			for _, change := range changes {
+				fire(change)
+			}
+			for _, key := range cleared {
+				device, iface, _ := strings.Cut(key, "|")
+				clear(device, iface)
+			}
+			if err := t.save(); err != nil {
+				t.logger.Error().Err(err).Msg("Failed to persist capacity history")
+			}
+		}
+	}
+}
+
+// check returns advisories for interfaces newly projected to cross their
+// threshold, and the device|interface keys of advisories that no longer
+// apply. An interface's advisory reports its soonest direction.
+func (t *Tracker) check(now time.Time) ([]evaluator.StateChange, []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	soonest := make(map[string]Forecast)
+	for _, f := range t.forecasts(now) {
+		if !f.Advisory {
+			continue
+		}
+		key := f.Device + "|" + f.Interface
+		if prev, ok := soonest[key]; !ok || *f.DaysToThreshold < *prev.DaysToThreshold {
+			soonest[key] = f
+		}
+	}
+
+	var changes []evaluator.StateChange
+	keys := make([]string, 0, len(soonest))
+	for key := range soonest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if t.advised[key] {
+			continue
+		}
+		t.advised[key] = true
+		changes = append(changes, t.advisory(soonest[key], now))
+	}
+
+	var cleared []string
+	for key := range t.advised {
+		if _, ok := soonest[key]; !ok {
+			delete(t.advised, key)
+			cleared = append(cleared, key)
+		}
+	}
+	sort.Strings(cleared)
+	return changes, cleared
+}
+
+// advisory builds the capacity alert for a forecast. The caller must hold
+// t.mu.
+func (t *Tracker) advisory(f Forecast, now time.Time) evaluator.StateChange {
+	severity := "info"
+	if s := t.config.DesiredState.Devices[f.Device].Interfaces[f.Interface].Alerts.CapacityForecast; s != "" {
+		severity = s
+	}
+	_, horizon := t.capacityConfig(f.Device, f.Interface).Limits()
+	return evaluator.StateChange{
+		Device:    f.Device,
+		Interface: f.Interface,
+		AlertType: AlertType,
+		Severity:  severity,
+		Firing:    true,
+		Message: fmt.Sprintf("interface %s %s utilization projected to exceed %.0f%% %s (daily peak %.1f%%, +%.2f%%/day)",
+			f.Interface, f.Direction, f.ThresholdPercent, f.ETA(), f.PeakPercent, f.TrendPercent),
+		RelatedState: map[string]string{
+			"direction":             f.Direction,
+			"peak_percent":          strconv.FormatFloat(f.PeakPercent, 'f', 1, 64),
+			"trend_percent_per_day": strconv.FormatFloat(f.TrendPercent, 'f', 2, 64),
+			"threshold_percent":     strconv.FormatFloat(f.ThresholdPercent, 'f', 0, 64),
+			"days_to_threshold":     strconv.FormatFloat(math.Ceil(*f.DaysToThreshold), 'f', 0, 64),
+			"horizon_days":          strconv.FormatFloat(horizon.Hours()/24, 'f', 0, 64),
+		},
+		ObservedAt: now,
+	}
+}
+
+// save writes the utilization history to the store if it changed
+func (t *Tracker) save() error {
+	t.mu.Lock()
+	if t.store == nil || !t.dirty {
+		t.mu.Unlock()
+		return nil
+	}
+	st := t.store
+	links := make(map[string]history, len(t.links))
+	cutoff := time.Now().Add(-retainDays * 24 * time.Hour)
+	for key, h := range t.links {
+		if h.Hour.Before(cutoff) {
+			delete(t.links, key) // no samples within the retention
+			continue
+		}
+		cp := *h
+		cp.Days = append([]DayPeak(nil), h.Days...)
+		links[key] = cp
+	}
+	t.dirty = false
+	t.mu.Unlock()
+
+	if err := st.Save(storeSection, links); err != nil {
+		t.mu.Lock()
+		t.dirty = true
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// maxRecordSize bounds a single line when reading capture files
+const maxRecordSize = 16 << 20
+
+// Record is one captured notification. Capture files hold one JSON record
+// per line so they can be tailed, grepped and concatenated.
+type Record struct {
+	Device       string          `json:"device"`
+	ReceivedAt   time.Time       `json:"received_at"`
+	Notification json.RawMessage `json:"notification"` // protojson-encoded gnmi.Notification
+}
+
+// Writer appends notifications to a capture file
+type Writer struct {
+	mu sync.Mutex
+	f  *os.File
+	w  *bufio.Writer
+}
+
+// OpenWriter opens path for appending, creating it and its directory
+func OpenWriter(path string) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// Write records a notification received from device. Each record is flushed
+// immediately so a capture is usable while the daemon keeps running.
+func (w *Writer) Write(device string, n *gnmi.Notification, receivedAt time.Time) error {
+	raw, err := protojson.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+	line, err := json.Marshal(Record{Device: device, ReceivedAt: receivedAt, Notification: raw})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// Close flushes and closes the file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.w.Flush(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// ReadFile calls fn for every record in a capture file, in order
+func ReadFile(path string, fn func(rec Record, n *gnmi.Notification) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), maxRecordSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		var n gnmi.Notification
+		if err := protojson.Unmarshal(rec.Notification, &n); err != nil {
+			return fmt.Errorf("line %d: decoding notification: %w", line, err)
+		}
+		if err := fn(rec, &n); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,247 @@
+// Package changecal creates maintenance windows from an external change
+// calendar, polling its iCal feed and taking changes pushed to the calendar
+// webhook, so planned work suppresses alerts without editing
+// maintenance.yaml.
+package changecal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// maxFeed bounds the size of the iCal feed
+const maxFeed = 4 << 20
+
+// checkInterval is how often the syncer looks for a poll being due and
+// drops changes that have ended
+const checkInterval = 30 * time.Second
+
+// WindowsFunc receives the maintenance windows of all current changes
+// whenever they change
+type WindowsFunc func(windows []config.MaintenanceWindow)
+
+// Status describes the feed's last poll
+type Status struct {
+	LastPoll  time.Time `json:"last_poll,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Changes   int       `json:"changes"`
+}
+
+// Syncer keeps the maintenance windows of the change calendar up to date
+type Syncer struct {
+	mu      sync.Mutex
+	cfg     *config.Config
+	logger  zerolog.Logger
+	client  *http.Client
+	apply   WindowsFunc
+	feed    map[string]config.ChangeEvent // from the last poll, by UID
+	pushed  map[string]config.ChangeEvent // from the webhook, by UID
+	windows []config.MaintenanceWindow
+	status  Status
+	polled  time.Time // last successful poll; failed polls are retried at the next check
+}
+
+// New creates a syncer passing the windows to apply
+func New(cfg *config.Config, logger zerolog.Logger, apply WindowsFunc) *Syncer {
+	return &Syncer{
+		cfg:    cfg,
+		logger: logger.With().Str("component", "changecal").Logger(),
+		client: &http.Client{Timeout: 30 * time.Second},
+		apply:  apply,
+		feed:   make(map[string]config.ChangeEvent),
+		pushed: make(map[string]config.ChangeEvent),
+	}
+}
+
+// SetConfig swaps in a reloaded configuration and remaps the changes to
+// devices, whose tags or the category mapping may have changed
+func (s *Syncer) SetConfig(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+	s.update(time.Now())
+}
+
+// Push adds or, when cancelled, removes changes sent to the webhook. Pushed
+// changes take precedence over the feed's version of the same change and
+// are kept in memory only. It returns the windows created.
+func (s *Syncer) Push(events []config.ChangeEvent) []config.MaintenanceWindow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var created []config.MaintenanceWindow
+	for _, ev := range events {
+		s.pushed[ev.UID] = ev
+		if w, ok := s.cfg.ChangeWindow(ev); ok {
+			created = append(created, w)
+		}
+		s.logger.Info().
+			Str("uid", ev.UID).
+			Strs("categories", ev.Categories).
+			Bool("cancelled", ev.Cancelled).
+			Msg("Change received from calendar webhook")
+	}
+	s.update(time.Now())
+	return created
+}
+
+// Windows returns the maintenance windows of the current changes
+func (s *Syncer) Windows() []config.MaintenanceWindow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.windows
+}
+
+// Status returns the state of the feed
+func (s *Syncer) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Run polls the feed, when one is configured, every poll_interval and drops
+// ended changes until ctx is done
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	s.check(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.check(ctx, now)
+		}
+	}
+}
+
+// check polls the feed if due and drops ended changes
+func (s *Syncer) check(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	calendar := s.cfg.Maintenance.ChangeCalendar
+	due := calendar != nil && calendar.ICalURLEnv != "" && now.Sub(s.polled) >= calendar.Interval()
+	if !due {
+		s.update(now)
+		s.mu.Unlock()
+		return
+	}
+	s.status.LastPoll = now
+	s.mu.Unlock()
+
+	events, err := s.fetch(ctx, calendar.ICalURLEnv)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.status.LastError = err.Error()
+		s.logger.Warn().Err(err).Msg("Failed to poll change calendar, keeping the changes last read")
+		s.update(now)
+		return
+	}
+	s.status.LastError = ""
+	s.polled = now
+	s.feed = make(map[string]config.ChangeEvent, len(events))
+	for _, ev := range events {
+		if ev.Validate() == nil && !ev.Cancelled {
+			s.feed[ev.UID] = ev
+		}
+	}
+	s.update(now)
+}
+
+// fetch reads the feed's events
+func (s *Syncer) fetch(ctx context.Context, urlEnv string) ([]config.ChangeEvent, error) {
+	url := os.Getenv(urlEnv)
+	if url == "" {
+		return nil, fmt.Errorf("%s is not set", urlEnv)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeed))
+	if err != nil {
+		return nil, err
+	}
+	return config.ParseChangeEvents(data)
+}
+
+// update drops ended and cancelled changes and applies the windows of the
+// rest. The caller must hold s.mu.
+func (s *Syncer) update(now time.Time) {
+	changes := make(map[string]config.ChangeEvent, len(s.feed)+len(s.pushed))
+	for uid, ev := range s.feed {
+		if !ev.End.After(now) {
+			delete(s.feed, uid)
+			continue
+		}
+		changes[uid] = ev
+	}
+	for uid, ev := range s.pushed {
+		if ev.Cancelled {
+			// Kept only to hide the feed's copy until the feed drops it
+			if _, inFeed := changes[uid]; !inFeed {
+				delete(s.pushed, uid)
+			}
+			delete(changes, uid)
+			continue
+		}
+		if !ev.End.After(now) {
+			delete(s.pushed, uid)
+			continue
+		}
+		changes[uid] = ev
+	}
+	s.status.Changes = len(changes)
+
+	uids := make([]string, 0, len(changes))
+	for uid := range changes {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	var windows []config.MaintenanceWindow
+	for _, uid := range uids {
+		if w, ok := s.cfg.ChangeWindow(changes[uid]); ok {
+			windows = append(windows, w)
+		}
+	}
+	if !sameWindows(windows, s.windows) {
+		s.logger.Info().Int("windows", len(windows)).Msg("Change calendar maintenance windows updated")
+	}
+	s.windows = windows
+	if s.apply != nil {
+		s.apply(windows)
+	}
+}
+
+// sameWindows reports whether two window lists have the same names and
+// times
+func sameWindows(a, b []config.MaintenanceWindow) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Schedule != b[i].Schedule || len(a[i].Devices) != len(b[i].Devices) {
+			return false
+		}
+	}
+	return true
+}
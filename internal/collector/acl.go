@@ -0,0 +1,136 @@
+package collector
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// aclSets collects attached ACL set names per interface and direction
+type aclSets map[string]map[string]map[string]bool
+
+func (s aclSets) add(ifName, direction, setName string) {
+	if ifName == "" || setName == "" {
+		return
+	}
+	if s[ifName] == nil {
+		s[ifName] = map[string]map[string]bool{"ingress": {}, "egress": {}}
+	}
+	s[ifName][direction][setName] = true
+}
+
+// GetACLBindings reads /acl/interfaces (openconfig-acl) from the device and
+// returns the ACL sets attached to each interface, keyed by interface id.
+// Interfaces without attachments are absent from the result.
+func (c *Collector) GetACLBindings() (map[string]config.ACLBindings, error) {
+	notifications, err := c.Get(&gnmi.Path{Elem: []*gnmi.PathElem{{Name: "acl"}, {Name: "interfaces"}}})
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make(aclSets)
+	for _, notif := range notifications {
+		for _, update := range notif.Update {
+			elems := make([]*gnmi.PathElem, 0)
+			if notif.Prefix != nil {
+				elems = append(elems, notif.Prefix.Elem...)
+			}
+			if update.Path != nil {
+				elems = append(elems, update.Path.Elem...)
+			}
+			collectACLUpdate(sets, elems, update.Val)
+		}
+	}
+
+	bindings := make(map[string]config.ACLBindings, len(sets))
+	for ifName, dirs := range sets {
+		bindings[ifName] = config.ACLBindings{
+			Ingress: sortedNames(dirs["ingress"]),
+			Egress:  sortedNames(dirs["egress"]),
+		}
+	}
+	return bindings, nil
+}
+
+// collectACLUpdate records the attachment named by a Get update, either in
+// its path keys or in a JSON subtree
+func collectACLUpdate(sets aclSets, elems []*gnmi.PathElem, val *gnmi.TypedValue) {
+	ifName, direction := "", ""
+	for _, elem := range elems {
+		switch stripModule(elem.Name) {
+		case "interface":
+			ifName = elem.Key["id"]
+		case "ingress-acl-set":
+			direction = "ingress"
+			sets.add(ifName, direction, elem.Key["set-name"])
+		case "egress-acl-set":
+			direction = "egress"
+			sets.add(ifName, direction, elem.Key["set-name"])
+		}
+	}
+
+	var raw []byte
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_JsonIetfVal:
+		raw = v.JsonIetfVal
+	case *gnmi.TypedValue_JsonVal:
+		raw = v.JsonVal
+	}
+	if raw == nil {
+		return
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return
+	}
+	walkACLJSON(sets, decoded, ifName, direction)
+}
+
+// walkACLJSON descends through interfaces/interface/<dir>-acl-sets
+// containers and records each attached set's name
+func walkACLJSON(sets aclSets, node interface{}, ifName, direction string) {
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			walkACLJSON(sets, item, ifName, direction)
+		}
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			fields[stripModule(key)] = value
+		}
+		if id, ok := fields["id"].(string); ok && direction == "" {
+			ifName = id
+		}
+		if name, ok := fields["set-name"].(string); ok && direction != "" {
+			sets.add(ifName, direction, name)
+		}
+		for _, key := range []string{"interfaces", "interface"} {
+			if child, ok := fields[key]; ok {
+				walkACLJSON(sets, child, ifName, direction)
+			}
+		}
+		for _, key := range []string{"ingress-acl-sets", "ingress-acl-set"} {
+			if child, ok := fields[key]; ok {
+				walkACLJSON(sets, child, ifName, "ingress")
+			}
+		}
+		for _, key := range []string{"egress-acl-sets", "egress-acl-set"} {
+			if child, ok := fields[key]; ok {
+				walkACLJSON(sets, child, ifName, "egress")
+			}
+		}
+	}
+}
+
+// sortedNames returns the keys of a set in order
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/netspec/netspec/internal/capabilities"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// GetCapabilities sends a gNMI Capabilities request on a one-shot
+// connection and returns the gNMI version, encodings and models the device
+// advertises
+func (c *Collector) GetCapabilities() (capabilities.Snapshot, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return capabilities.Snapshot{}, err
+	}
+	defer conn.Close()
+
+	capCtx, capCancel := context.WithTimeout(context.Background(), c.dialTimeout)
+	defer capCancel()
+
+	resp, err := gnmi.NewGNMIClient(conn).Capabilities(capCtx, &gnmi.CapabilityRequest{})
+	if err != nil {
+		return capabilities.Snapshot{}, fmt.Errorf("capabilities request failed: %w", err)
+	}
+
+	snap := capabilities.Snapshot{
+		CollectedAt: time.Now(),
+		GNMIVersion: resp.GetGNMIVersion(),
+		Encodings:   make([]string, 0, len(resp.GetSupportedEncodings())),
+		Models:      make([]capabilities.Model, 0, len(resp.GetSupportedModels())),
+	}
+	for _, enc := range resp.GetSupportedEncodings() {
+		snap.Encodings = append(snap.Encodings, enc.String())
+	}
+	for _, m := range resp.GetSupportedModels() {
+		snap.Models = append(snap.Models, capabilities.Model{
+			Name:         m.GetName(),
+			Organization: m.GetOrganization(),
+			Version:      m.GetVersion(),
+		})
+	}
+	return snap, nil
+}
@@ -0,0 +1,130 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+// CheckStep is the outcome of one stage of a device check. Duration is
+// measured from the start of the stage, except for the sync and first-update
+// stages which are measured from when the subscription was sent.
+type CheckStep struct {
+	Name     string
+	Duration time.Duration
+	Detail   string
+	Err      error
+}
+
+// Check runs the daemon's connection sequence once against the device: dial,
+// Capabilities, open the standard subscription, then wait for sync_response
+// and the first update. It stops at the first failing stage; the whole check
+// is bounded by timeout.
+func (c *Collector) Check(timeout time.Duration) []CheckStep {
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+
+	var steps []CheckStep
+	run := func(name string, fn func() (string, error)) bool {
+		start := time.Now()
+		detail, err := fn()
+		steps = append(steps, CheckStep{Name: name, Duration: time.Since(start), Detail: detail, Err: err})
+		return err == nil
+	}
+
+	addr := fmt.Sprintf("%s:%d", c.address, c.port)
+	var conn *grpc.ClientConn
+	ok := run("dial", func() (string, error) {
+		opts, err := c.dialOptions()
+		if err != nil {
+			return addr, fmt.Errorf("dial options: %w", err)
+		}
+		conn, err = grpc.DialContext(ctx, addr, append(opts, grpc.WithBlock())...)
+		return addr, err
+	})
+	if !ok {
+		return steps
+	}
+	defer conn.Close()
+
+	client := gnmi.NewGNMIClient(conn)
+
+	ok = run("capabilities", func() (string, error) {
+		resp, err := client.Capabilities(ctx, &gnmi.CapabilityRequest{})
+		if err != nil {
+			return "", err
+		}
+		encodings := make([]string, 0, len(resp.GetSupportedEncodings()))
+		for _, enc := range resp.GetSupportedEncodings() {
+			encodings = append(encodings, enc.String())
+		}
+		return fmt.Sprintf("gNMI %s, %d models, encodings %s",
+			resp.GetGNMIVersion(), len(resp.GetSupportedModels()), strings.Join(encodings, ",")), nil
+	})
+	if !ok {
+		return steps
+	}
+
+	var stream gnmi.GNMI_SubscribeClient
+	req := c.subscribeRequest()
+	ok = run("subscribe", func() (string, error) {
+		var err error
+		stream, err = client.Subscribe(ctx)
+		if err != nil {
+			return "", err
+		}
+		sub := req.GetSubscribe().Subscription[0]
+		detail := fmt.Sprintf("%s %s every %s", pathToString(sub.Path), sub.Mode, time.Duration(sub.SampleInterval))
+		return detail, stream.Send(req)
+	})
+	if !ok {
+		return steps
+	}
+	defer stream.CloseSend()
+
+	// The device may send its initial updates before or after sync_response;
+	// wait until both have been seen
+	subscribed := time.Now()
+	var syncStep, updateStep *CheckStep
+	for syncStep == nil || updateStep == nil {
+		resp, err := stream.Recv()
+		if err != nil {
+			if syncStep == nil {
+				steps = append(steps, CheckStep{Name: "sync_response", Duration: time.Since(subscribed), Err: err})
+			} else {
+				steps = append(steps, *syncStep)
+			}
+			if updateStep == nil {
+				steps = append(steps, CheckStep{Name: "first update", Duration: time.Since(subscribed), Err: err})
+			} else {
+				steps = append(steps, *updateStep)
+			}
+			return steps
+		}
+		switch v := resp.Response.(type) {
+		case *gnmi.SubscribeResponse_SyncResponse:
+			if syncStep == nil {
+				syncStep = &CheckStep{Name: "sync_response", Duration: time.Since(subscribed)}
+			}
+		case *gnmi.SubscribeResponse_Update:
+			if updateStep == nil && len(v.Update.GetUpdate()) > 0 {
+				update := v.Update.Update[0]
+				updateStep = &CheckStep{
+					Name:     "first update",
+					Duration: time.Since(subscribed),
+					Detail: fmt.Sprintf("%s%s = %s (%d updates)",
+						pathToString(v.Update.Prefix), pathToString(update.Path),
+						typedValueToString(update.Val), len(v.Update.Update)),
+				}
+			}
+		case *gnmi.SubscribeResponse_Error:
+			return append(steps, CheckStep{Name: "sync_response", Duration: time.Since(subscribed),
+				Err: fmt.Errorf("subscribe error: %s", v.Error.Message)})
+		}
+	}
+	return append(steps, *syncStep, *updateStep)
+}
@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Subscribe failure classes reported in DeviceHealth.SubscribeError
+const (
+	SubscribeErrorPermission = "permission" // the device rejected the credentials or the user's access to the paths
+	SubscribeErrorCapability = "capability" // the device does not support a subscribed path, encoding or mode
+	SubscribeErrorTransport  = "transport"  // the stream broke; retrying may succeed
+)
+
+// SubscribeRetryInterval is how long to wait before subscribing again after
+// a permission or capability failure, which retrying sooner will not fix
+const SubscribeRetryInterval = 10 * time.Minute
+
+// Hints shown with each subscribe failure class
+var subscribeHints = map[string]string{
+	SubscribeErrorPermission: "Check the device's gNMI credentials (credentials.yaml or GNMI_USERNAME/GNMI_PASSWORD) and that the user's role may read the interfaces and switched-vlan paths",
+	SubscribeErrorCapability: "Check that the device supports the openconfig-interfaces model with STREAM/SAMPLE subscriptions and JSON encoding, or remove trunk_allowed_vlans assertions if it lacks switched-vlan state",
+	SubscribeErrorTransport:  "The stream was interrupted; NetSpec reconnects with backoff. Repeated failures point at the network or an overloaded gNMI server",
+}
+
+// SubscribeError is a failed or broken gNMI subscription with its class
+type SubscribeError struct {
+	Class string // one of the SubscribeError constants
+	Err   error
+}
+
+func (e *SubscribeError) Error() string {
+	return fmt.Sprintf("subscribe (%s): %v", e.Class, e.Err)
+}
+
+func (e *SubscribeError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent reports whether retrying is futile until the device or the
+// configuration changes
+func (e *SubscribeError) Permanent() bool {
+	return e.Class != SubscribeErrorTransport
+}
+
+// Hint returns what to check to fix the failure
+func (e *SubscribeError) Hint() string {
+	return subscribeHints[e.Class]
+}
+
+// classifySubscribeError classifies an error from the Subscribe stream by
+// its gRPC status code, falling back to the message for errors reported in
+// a SubscribeResponse or with an Unknown code
+func classifySubscribeError(err error) *SubscribeError {
+	var subErr *SubscribeError
+	if errors.As(err, &subErr) {
+		return subErr
+	}
+	class := SubscribeErrorTransport
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		class = SubscribeErrorPermission
+	case codes.Unimplemented, codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition, codes.OutOfRange:
+		class = SubscribeErrorCapability
+	case codes.Unknown:
+		class = classifyMessage(err.Error())
+	}
+	return &SubscribeError{Class: class, Err: err}
+}
+
+// classifyMessage guesses the class of an error from its text, as devices
+// word them
+func classifyMessage(msg string) string {
+	msg = strings.ToLower(msg)
+	for _, word := range []string{"unauthenticated", "authentication", "authorization", "permission", "access denied", "not authorized"} {
+		if strings.Contains(msg, word) {
+			return SubscribeErrorPermission
+		}
+	}
+	for _, word := range []string{"unsupported", "not supported", "unimplemented", "invalid path", "unknown path", "no such", "encoding", "invalid mode"} {
+		if strings.Contains(msg, word) {
+			return SubscribeErrorCapability
+		}
+	}
+	return SubscribeErrorTransport
+}
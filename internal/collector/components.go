@@ -0,0 +1,354 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/netspec/netspec/internal/inventory"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// component is the state read for one entry of /components (openconfig-platform).
+// Leaves are keyed by name without module prefix; transceiver holds the
+// leaves of its transceiver/state container and any wavelength below it.
+type component struct {
+	state       map[string]string
+	transceiver map[string]string
+}
+
+// components collects component leaves by component name
+type components map[string]*component
+
+func (c components) set(name, section, leaf, value string) {
+	if name == "" || value == "" {
+		return
+	}
+	comp, ok := c[name]
+	if !ok {
+		comp = &component{state: make(map[string]string), transceiver: make(map[string]string)}
+		c[name] = comp
+	}
+	if section == "transceiver" {
+		comp.transceiver[leaf] = value
+	} else {
+		comp.state[leaf] = value
+	}
+}
+
+// isTransceiver reports whether a component is a pluggable optic
+func (c *component) isTransceiver() bool {
+	return stripModule(c.state["type"]) == "TRANSCEIVER" || len(c.transceiver) > 0
+}
+
+// moduleTypes are the component types listed as modules even without a
+// serial number. Other components are listed only when they report one, so
+// ports, CPUs and sensors are left out.
+var moduleTypes = map[string]bool{
+	"LINECARD":        true,
+	"CONTROLLER_CARD": true,
+	"FABRIC":          true,
+	"POWER_SUPPLY":    true,
+	"FAN":             true,
+	"FAN_TRAY":        true,
+}
+
+// GetInventory reads /components from the device and returns its chassis,
+// modules, software version and transceivers, each transceiver matched to
+// the interface it serves. Interfaces are matched through their transceiver
+// or hardware-port leaf, else by sharing the transceiver's name.
+func (c *Collector) GetInventory() (inventory.Snapshot, error) {
+	notifications, err := c.Get(&gnmi.Path{Elem: []*gnmi.PathElem{{Name: "components"}}})
+	if err != nil {
+		return inventory.Snapshot{}, fmt.Errorf("reading components: %w", err)
+	}
+	comps := make(components)
+	for _, notif := range notifications {
+		for _, update := range notif.Update {
+			collectComponentUpdate(comps, updateElems(notif, update), update.Val)
+		}
+	}
+
+	// The interface references are optional; without them transceivers
+	// are matched by name only
+	ports := make(map[string]map[string]string)
+	if notifications, err := c.Get(&gnmi.Path{Elem: []*gnmi.PathElem{{Name: "interfaces"}}}); err == nil {
+		for _, notif := range notifications {
+			for _, update := range notif.Update {
+				collectPortUpdate(ports, updateElems(notif, update), update.Val)
+			}
+		}
+	}
+	byTransceiver := make(map[string]string)
+	byPort := make(map[string]string)
+	for ifName, leaves := range ports {
+		if t := leaves["transceiver"]; t != "" {
+			byTransceiver[t] = ifName
+		}
+		if p := leaves["hardware-port"]; p != "" {
+			byPort[p] = ifName
+		}
+	}
+
+	var snap inventory.Snapshot
+	for name, comp := range comps {
+		switch kind := stripModule(comp.state["type"]); {
+		case kind == "CHASSIS":
+			snap.Chassis = append(snap.Chassis, componentInfo(name, comp))
+			if snap.SoftwareVersion == "" {
+				snap.SoftwareVersion = comp.state["software-version"]
+			}
+			continue
+		case kind == "OPERATING_SYSTEM":
+			if v := comp.state["software-version"]; v != "" {
+				snap.SoftwareVersion = v
+			}
+			continue
+		case !comp.isTransceiver():
+			if moduleTypes[kind] || (kind != "PORT" && comp.state["serial-no"] != "") {
+				snap.Modules = append(snap.Modules, componentInfo(name, comp))
+			}
+			continue
+		}
+		iface, ok := byTransceiver[name]
+		if !ok {
+			iface, ok = byPort[comp.state["parent"]]
+		}
+		if !ok {
+			if _, named := ports[name]; named {
+				iface = name
+			}
+		}
+		snap.Transceivers = append(snap.Transceivers, transceiverInfo(name, iface, comp))
+	}
+
+	c.logger.Info().
+		Int("components", len(comps)).
+		Int("modules", len(snap.Modules)).
+		Int("transceivers", len(snap.Transceivers)).
+		Msg("Inventory read via gNMI Get")
+
+	return snap, nil
+}
+
+// componentInfo builds the inventory entry of a chassis or module
+func componentInfo(name string, comp *component) inventory.Component {
+	return inventory.Component{
+		Name:            name,
+		Type:            stripModule(comp.state["type"]),
+		Description:     comp.state["description"],
+		Parent:          comp.state["parent"],
+		Manufacturer:    strings.TrimSpace(comp.state["mfg-name"]),
+		Model:           strings.TrimSpace(comp.state["part-no"]),
+		Serial:          strings.TrimSpace(comp.state["serial-no"]),
+		HardwareVersion: comp.state["hardware-version"],
+	}
+}
+
+// transceiverInfo builds the inventory entry of an optic. The transceiver
+// container's vendor details are preferred over the component's own.
+func transceiverInfo(name, iface string, comp *component) inventory.Transceiver {
+	first := func(values ...string) string {
+		for _, v := range values {
+			if v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	t := comp.transceiver
+	return inventory.Transceiver{
+		Component:   name,
+		Interface:   iface,
+		Vendor:      strings.TrimSpace(first(t["vendor"], comp.state["mfg-name"])),
+		PartNumber:  strings.TrimSpace(first(t["vendor-part"], comp.state["part-no"])),
+		Serial:      strings.TrimSpace(first(t["serial-no"], comp.state["serial-no"])),
+		FormFactor:  stripModule(first(t["form-factor"], t["form-factor-preconf"])),
+		EthernetPMD: stripModule(first(t["ethernet-pmd"], t["ethernet-pmd-preconf"])),
+		Wavelength:  formatWavelength(t["wavelength"]),
+	}
+}
+
+// formatWavelength renders a wavelength in nm, which devices report as a
+// number or a decimal string
+func formatWavelength(value string) string {
+	if value == "" {
+		return ""
+	}
+	if nm, err := strconv.ParseFloat(value, 64); err == nil {
+		return strconv.FormatFloat(nm, 'f', -1, 64)
+	}
+	return value
+}
+
+// updateElems joins a notification's prefix and an update's path
+func updateElems(notif *gnmi.Notification, update *gnmi.Update) []*gnmi.PathElem {
+	elems := make([]*gnmi.PathElem, 0)
+	if notif.Prefix != nil {
+		elems = append(elems, notif.Prefix.Elem...)
+	}
+	if update.Path != nil {
+		elems = append(elems, update.Path.Elem...)
+	}
+	return elems
+}
+
+// collectComponentUpdate records the leaves of a Get update, either a scalar
+// state leaf or a JSON subtree rooted anywhere from /components down
+func collectComponentUpdate(comps components, elems []*gnmi.PathElem, val *gnmi.TypedValue) {
+	name, section := "", ""
+	for _, elem := range elems {
+		switch stripModule(elem.Name) {
+		case "component":
+			name = elem.Key["name"]
+		case "transceiver":
+			section = "transceiver"
+		}
+	}
+
+	var raw []byte
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_JsonIetfVal:
+		raw = v.JsonIetfVal
+	case *gnmi.TypedValue_JsonVal:
+		raw = v.JsonVal
+	}
+	if raw == nil {
+		n := len(elems)
+		if n < 2 || stripModule(elems[n-2].Name) != "state" {
+			return
+		}
+		comps.set(name, section, stripModule(elems[n-1].Name), typedValueToString(val))
+		return
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return
+	}
+	inState := len(elems) > 0 && stripModule(elems[len(elems)-1].Name) == "state"
+	walkComponentJSON(comps, decoded, name, section, inState)
+}
+
+// walkComponentJSON descends through components/component containers and
+// records the leaves of state containers. Wavelength leaves are taken from
+// anywhere below a transceiver, as devices place them in different channels.
+func walkComponentJSON(comps components, node interface{}, name, section string, inState bool) {
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			walkComponentJSON(comps, item, name, section, inState)
+		}
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			fields[stripModule(key)] = value
+		}
+		if n, ok := fields["name"].(string); ok && section == "" && !inState {
+			name = n
+		}
+		for leaf, value := range fields {
+			var s string
+			switch x := value.(type) {
+			case string:
+				s = x
+			case float64:
+				s = strconv.FormatFloat(x, 'f', -1, 64)
+			default:
+				continue
+			}
+			if inState || (section == "transceiver" && leaf == "wavelength") {
+				comps.set(name, section, leaf, s)
+			}
+		}
+		for key, child := range fields {
+			switch key {
+			case "components", "component":
+				walkComponentJSON(comps, child, name, "", false)
+			case "state":
+				walkComponentJSON(comps, child, name, section, true)
+			case "transceiver":
+				walkComponentJSON(comps, child, name, "transceiver", false)
+			case "physical-channels", "channel":
+				if section == "transceiver" {
+					walkComponentJSON(comps, child, name, section, false)
+				}
+			}
+		}
+	}
+}
+
+// collectPortUpdate records the transceiver and hardware-port leaves of
+// /interfaces/interface/state, which name the component serving each
+// interface. Every interface seen is recorded so transceivers can also be
+// matched by name.
+func collectPortUpdate(ports map[string]map[string]string, elems []*gnmi.PathElem, val *gnmi.TypedValue) {
+	ifName := ""
+	for _, elem := range elems {
+		if stripModule(elem.Name) == "interface" {
+			ifName = elem.Key["name"]
+		}
+	}
+
+	var raw []byte
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_JsonIetfVal:
+		raw = v.JsonIetfVal
+	case *gnmi.TypedValue_JsonVal:
+		raw = v.JsonVal
+	}
+	if raw == nil {
+		if ifName != "" && len(elems) > 0 {
+			setPortLeaf(ports, ifName, stripModule(elems[len(elems)-1].Name), typedValueToString(val))
+		}
+		return
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return
+	}
+	walkPortJSON(ports, decoded, ifName)
+}
+
+// walkPortJSON descends through interfaces/interface/state containers like
+// walkInterfaceJSON, recording the component references
+func walkPortJSON(ports map[string]map[string]string, node interface{}, ifName string) {
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			walkPortJSON(ports, item, ifName)
+		}
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			fields[stripModule(key)] = value
+		}
+		if name, ok := fields["name"].(string); ok {
+			if _, hasState := fields["state"]; hasState {
+				ifName = name
+			}
+		}
+		for _, leaf := range []string{"name", "transceiver", "hardware-port"} {
+			if s, ok := fields[leaf].(string); ok && ifName != "" {
+				setPortLeaf(ports, ifName, leaf, s)
+			}
+		}
+		for _, key := range []string{"interfaces", "interface", "state"} {
+			if child, ok := fields[key]; ok {
+				walkPortJSON(ports, child, ifName)
+			}
+		}
+	}
+}
+
+// setPortLeaf records an interface and, for a component reference, its value
+func setPortLeaf(ports map[string]map[string]string, ifName, leaf, value string) {
+	if ports[ifName] == nil {
+		ports[ifName] = make(map[string]string)
+	}
+	if (leaf == "transceiver" || leaf == "hardware-port") && value != "" {
+		ports[ifName][leaf] = value
+	}
+}
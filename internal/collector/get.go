@@ -0,0 +1,191 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+)
+
+// dial opens a one-shot gRPC connection for unary gNMI RPCs
+func (c *Collector) dial() (*session, error) {
+	dialCtx, dialCancel := context.WithTimeout(context.Background(), c.dialTimeout)
+	defer dialCancel()
+
+	opts, err := c.dialOptions()
+	if err != nil {
+		return nil, fmt.Errorf("dial options: %w", err)
+	}
+
+	conn, err := c.openSession(dialCtx, append(opts, grpc.WithBlock())...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial: %w", err)
+	}
+	return conn, nil
+}
+
+// Get performs a one-shot gNMI Get for the given paths using JSON_IETF
+// encoding and returns the resulting notifications
+func (c *Collector) Get(paths ...*gnmi.Path) ([]*gnmi.Notification, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	client := gnmi.NewGNMIClient(conn)
+
+	getCtx, getCancel := context.WithTimeout(context.Background(), c.dialTimeout)
+	defer getCancel()
+
+	resp, err := client.Get(getCtx, &gnmi.GetRequest{
+		Path:     paths,
+		Encoding: gnmi.Encoding_JSON_IETF,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get request failed: %w", err)
+	}
+	return resp.GetNotification(), nil
+}
+
+// GetInterfaces reads /interfaces from the device and returns the current
+// description, type, oper-status and admin-status of every interface, sorted by
+// name. Status values are reported verbatim (e.g. "UP", "LOWER_LAYER_DOWN").
+func (c *Collector) GetInterfaces() ([]config.ObservedInterface, error) {
+	notifications, err := c.Get(&gnmi.Path{Elem: []*gnmi.PathElem{{Name: "interfaces"}}})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make(map[string]*config.ObservedInterface)
+	for _, notif := range notifications {
+		for _, update := range notif.Update {
+			elems := make([]*gnmi.PathElem, 0)
+			if notif.Prefix != nil {
+				elems = append(elems, notif.Prefix.Elem...)
+			}
+			if update.Path != nil {
+				elems = append(elems, update.Path.Elem...)
+			}
+			collectInterfaceUpdate(snapshots, elems, update.Val)
+		}
+	}
+
+	result := make([]config.ObservedInterface, 0, len(snapshots))
+	for _, snap := range snapshots {
+		result = append(result, *snap)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	c.logger.Info().
+		Int("interfaces", len(result)).
+		Msg("Interface state read via gNMI Get")
+
+	return result, nil
+}
+
+// collectInterfaceUpdate merges a single Get update into the snapshot map.
+// Devices answer either with scalar leaves or with a JSON subtree rooted
+// anywhere between /interfaces and the interface state container.
+func collectInterfaceUpdate(snapshots map[string]*config.ObservedInterface, elems []*gnmi.PathElem, val *gnmi.TypedValue) {
+	ifName := ""
+	for _, elem := range elems {
+		if stripModule(elem.Name) == "interface" {
+			ifName = elem.Key["name"]
+		}
+	}
+
+	var raw []byte
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_JsonIetfVal:
+		raw = v.JsonIetfVal
+	case *gnmi.TypedValue_JsonVal:
+		raw = v.JsonVal
+	}
+
+	if raw == nil {
+		if ifName == "" || len(elems) == 0 {
+			return
+		}
+		setInterfaceLeaf(snapshots, ifName, stripModule(elems[len(elems)-1].Name), typedValueToString(val))
+		return
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return
+	}
+	walkInterfaceJSON(snapshots, decoded, ifName)
+}
+
+// walkInterfaceJSON descends through interfaces/interface/state containers
+// and records the leaves of interest. Subinterfaces are deliberately skipped
+// so their state does not overwrite the parent interface.
+func walkInterfaceJSON(snapshots map[string]*config.ObservedInterface, node interface{}, ifName string) {
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			walkInterfaceJSON(snapshots, item, ifName)
+		}
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			fields[stripModule(key)] = value
+		}
+		if name, ok := fields["name"].(string); ok {
+			if _, hasState := fields["state"]; hasState {
+				ifName = name
+			} else if _, hasConfig := fields["config"]; hasConfig {
+				ifName = name
+			}
+		}
+		if ifName != "" {
+			for _, leaf := range []string{"description", "type", "oper-status", "admin-status"} {
+				if s, ok := fields[leaf].(string); ok {
+					setInterfaceLeaf(snapshots, ifName, leaf, s)
+				}
+			}
+		}
+		for _, key := range []string{"interfaces", "interface", "state", "config"} {
+			if child, ok := fields[key]; ok {
+				walkInterfaceJSON(snapshots, child, ifName)
+			}
+		}
+	}
+}
+
+// setInterfaceLeaf records one leaf value for an interface
+func setInterfaceLeaf(snapshots map[string]*config.ObservedInterface, ifName, leaf, value string) {
+	snap, ok := snapshots[ifName]
+	if !ok {
+		snap = &config.ObservedInterface{Name: ifName}
+		snapshots[ifName] = snap
+	}
+	switch leaf {
+	case "description":
+		if value != "" {
+			snap.Description = value
+		}
+	case "type":
+		snap.Type = value
+	case "oper-status":
+		snap.OperStatus = value
+	case "admin-status":
+		snap.AdminStatus = value
+	}
+}
+
+// stripModule removes a YANG module prefix such as "openconfig-interfaces:"
+func stripModule(name string) string {
+	if idx := strings.LastIndex(name, ":"); idx != -1 {
+		return name[idx+1:]
+	}
+	return name
+}
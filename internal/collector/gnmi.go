@@ -5,12 +5,14 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -25,6 +27,14 @@ const (
 	defaultBackoffMin    = 2 * time.Second
 	defaultBackoffMax    = 120 * time.Second
 	defaultUpdatesBuffer = 256
+
+	// sampleInterval bounds how often the last path/value shown in health is
+	// refreshed, which is the only per-notification work done under mu
+	sampleInterval = 100 * time.Millisecond
+
+	// skewSmoothing is the number of notifications over which the stream
+	// skew is averaged, so one delayed notification does not move it much
+	skewSmoothing = 8
 )
 
 // Collector manages gNMI subscriptions to network devices
@@ -34,7 +44,7 @@ type Collector struct {
 	password   string
 	port       int
 	client     gnmi.GNMI_SubscribeClient
-	conn       *grpc.ClientConn
+	conn       *session // guarded by mu, as Reconnect and Close run on other goroutines
 	logger     zerolog.Logger
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -44,7 +54,33 @@ type Collector struct {
 	dialTimeout time.Duration
 	mu         sync.RWMutex
 	health     DeviceHealth
+	lastPrefix *gnmi.Path   // prefix of the notification carrying lastUpdate
+	lastUpdate *gnmi.Update // recent update, formatted into LastPath/LastValue by Health
 	tlsConfig  *TLSConfig
+	serverCert *x509.Certificate // leaf certificate presented in the last TLS handshake
+	dropOldest bool // on a full update channel, discard the oldest queued notification instead of the new one
+	prechecks  bool // probe ICMP, TCP and TLS after a failed connect to classify it
+	trunkVLANs bool // also subscribe to switched-vlan state
+	pollInterval time.Duration // read state with Get at this interval instead of subscribing, when set
+
+	// Per-notification counters are atomics so the receive loop does not
+	// contend with Health readers; lastPrefix/lastUpdate are only refreshed
+	// under mu every sampleInterval
+	updateCount   atomic.Int64
+	lastUpdateAt  atomic.Int64 // unix nanoseconds, 0 before the first update
+	lastSampledAt atomic.Int64 // unix nanoseconds of the last lastUpdate refresh
+
+	// The stream skew is only measured once the current stream has synced,
+	// as the initial state may carry the time each leaf last changed
+	synced      atomic.Bool
+	skew        atomic.Int64 // smoothed receive time minus notification timestamp, nanoseconds
+	skewSamples atomic.Int64 // notifications measured on the current stream
+
+	// gRPC connections open to the device: the subscription and one-shot
+	// RPCs such as audits and capabilities reads
+	sessions           atomic.Int64
+	peakSessions       atomic.Int64 // most held at once since the collector started
+	windowPeakSessions atomic.Int64 // most held at once since TakeSessionPeak
 }
 
 // TLSConfig holds TLS configuration
@@ -68,12 +104,38 @@ type DeviceHealth struct {
 	Connected      bool
 	LastUpdate     time.Time
 	LastError      string
+	Diagnosis      string // class of the last connect failure when prechecks are enabled
+	SubscribeError string // class of the last subscribe failure: permission, capability or transport
+	SubscribeHint  string // what to check to fix it
 	ReconnectCount int
 	UpdateCount    int64
 	SyncReceived   bool
 	LastPath       string
 	LastValue      string
 	ConnectedSince time.Time
+	CertSubject    string    // subject of the device's TLS certificate, when TLS is used
+	CertNotAfter   time.Time // expiry of the device's TLS certificate
+	// StreamSkew is how far notification timestamps lag the time they are
+	// received, averaged over recent notifications; it is negative when the
+	// device's clock runs ahead. It grows when the device is too busy to
+	// stream promptly or its clock drifts.
+	StreamSkew         time.Duration
+	StreamSkewMeasured bool // false until notifications arrive after the sync
+	// PollInterval is set when state is read with gNMI Get at this interval
+	// instead of a subscription (collection_method: poll)
+	PollInterval time.Duration
+	// Sessions is the number of gRPC connections NetSpec holds to the
+	// device: the subscription and any one-shot RPCs in flight
+	Sessions     int
+	PeakSessions int // most held at once since the collector started
+}
+
+// Down reports whether the device is unreachable: not connected, with the
+// last connection attempt having failed. A device refusing the subscription
+// for lack of permission or capability still answers, so is not down.
+func (h DeviceHealth) Down() bool {
+	return !h.Connected && h.LastError != "" &&
+		h.SubscribeError != SubscribeErrorPermission && h.SubscribeError != SubscribeErrorCapability
 }
 
 // NewCollector creates a new gNMI collector
@@ -100,6 +162,50 @@ func (c *Collector) SetTLSConfig(cfg *TLSConfig) {
 	c.tlsConfig = cfg
 }
 
+// SetCredentials replaces the username and password. The current session
+// keeps the credentials it logged in with; the next connection uses these.
+func (c *Collector) SetCredentials(username, password string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.username = username
+	c.password = password
+}
+
+// Reconnect drops the current session so the connection loop dials again,
+// picking up credentials set since it connected. It reports whether there
+// was a session to drop.
+func (c *Collector) Reconnect() bool {
+	c.mu.RLock()
+	connected := c.health.Connected
+	conn := c.conn
+	c.mu.RUnlock()
+	if !connected || conn == nil {
+		return false
+	}
+	c.logger.Info().Msg("Reconnecting to apply new credentials")
+	conn.Close()
+	return true
+}
+
+// SetUpdateBuffer sizes the channel of notifications awaiting evaluation
+// (256 when size is not positive) and, with dropOldest, makes a full channel
+// discard its oldest notification so the most recent state survives a burst.
+// It must be called before Connect.
+func (c *Collector) SetUpdateBuffer(size int, dropOldest bool) {
+	if size <= 0 {
+		size = defaultUpdatesBuffer
+	}
+	c.updateChan = make(chan *gnmi.Notification, size)
+	c.dropOldest = dropOldest
+}
+
+// SetTrunkVLANSubscription adds the switched-vlan state containers to the
+// subscription, for devices with trunk_allowed_vlans assertions. It must be
+// called before Connect.
+func (c *Collector) SetTrunkVLANSubscription(enabled bool) {
+	c.trunkVLANs = enabled
+}
+
 // Errors returns the error channel
 func (c *Collector) Errors() <-chan error {
 	return c.errors
@@ -109,7 +215,35 @@ func (c *Collector) Errors() <-chan error {
 func (c *Collector) Health() DeviceHealth {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.health
+	health := c.health
+	health.UpdateCount = c.updateCount.Load()
+	health.PollInterval = c.pollInterval
+	health.Sessions = int(c.sessions.Load())
+	health.PeakSessions = int(c.peakSessions.Load())
+	if at := c.lastUpdateAt.Load(); at != 0 {
+		health.LastUpdate = time.Unix(0, at)
+	}
+	if c.lastUpdate != nil {
+		health.LastPath = pathToString(c.lastPrefix) + pathToString(c.lastUpdate.Path)
+		health.LastValue = typedValueToString(c.lastUpdate.Val)
+	}
+	if c.serverCert != nil {
+		health.CertSubject = c.serverCert.Subject.String()
+		health.CertNotAfter = c.serverCert.NotAfter
+	}
+	if c.skewSamples.Load() > 0 {
+		health.StreamSkew = time.Duration(c.skew.Load())
+		health.StreamSkewMeasured = true
+	}
+	return health
+}
+
+// ServerCertificate returns the certificate the device presented in the
+// last TLS handshake, or nil before one has completed or without TLS
+func (c *Collector) ServerCertificate() *x509.Certificate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.serverCert
 }
 
 // Connect establishes a gNMI connection to the device with retry logic
@@ -126,20 +260,30 @@ func (c *Collector) Connect() error {
 
 		err := c.connectOnce()
 		if err == nil {
-			c.mu.Lock()
-			c.health.Connected = true
-			c.health.LastError = ""
-			c.health.SyncReceived = false
-			c.health.ConnectedSince = time.Now()
-			c.mu.Unlock()
 			return nil
 		}
 
+		var diagnosis string
+		if c.prechecks && c.ctx.Err() == nil {
+			var detail string
+			diagnosis, detail = c.diagnose()
+			err = fmt.Errorf("%s: %w", detail, err)
+		}
+
 		attempt++
 		backoff := c.backoffDuration(attempt)
+		var subErr *SubscribeError
+		if errors.As(err, &subErr) && subErr.Permanent() {
+			backoff = SubscribeRetryInterval
+		}
 		c.mu.Lock()
 		c.health.Connected = false
 		c.health.LastError = err.Error()
+		c.health.Diagnosis = diagnosis
+		c.health.SubscribeError, c.health.SubscribeHint = "", ""
+		if subErr != nil {
+			c.health.SubscribeError, c.health.SubscribeHint = subErr.Class, subErr.Hint()
+		}
 		c.health.ReconnectCount++
 		c.mu.Unlock()
 
@@ -165,9 +309,12 @@ func (c *Collector) closeExisting() {
 		c.client.CloseSend()
 		c.client = nil
 	}
-	if c.conn != nil {
-		c.conn.Close()
-		c.conn = nil
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+	if conn != nil {
+		conn.Close()
 	}
 }
 
@@ -190,19 +337,28 @@ func (c *Collector) connectOnce() error {
 	// WithBlock ensures the connection is fully established before returning.
 	// Without it, DialContext returns immediately and the deferred context
 	// cancellation tears down the in-progress connection.
-	conn, err := grpc.DialContext(dialCtx, addr, append(opts, grpc.WithBlock())...)
+	conn, err := c.openSession(dialCtx, append(opts, grpc.WithBlock())...)
 	if err != nil {
 		return fmt.Errorf("failed to dial gNMI server: %w", err)
 	}
 
+	c.mu.Lock()
 	c.conn = conn
+	c.mu.Unlock()
 	client := gnmi.NewGNMIClient(conn)
 
+	if c.pollInterval > 0 {
+		c.markConnected()
+		go c.pollUpdates(client)
+		c.logger.Info().Msg("gNMI connection established, polling")
+		return nil
+	}
+
 	// Create subscribe client
 	subClient, err := client.Subscribe(c.ctx)
 	if err != nil {
 		conn.Close()
-		return fmt.Errorf("failed to create subscribe client: %w", err)
+		return classifySubscribeError(fmt.Errorf("failed to create subscribe client: %w", err))
 	}
 
 	c.client = subClient
@@ -211,10 +367,11 @@ func (c *Collector) connectOnce() error {
 	if err := c.startSubscription(); err != nil {
 		subClient.CloseSend()
 		conn.Close()
-		return fmt.Errorf("failed to start subscription: %w", err)
+		return classifySubscribeError(fmt.Errorf("failed to start subscription: %w", err))
 	}
 
 	// Start receiver goroutine
+	c.markConnected()
 	go c.receiveUpdates()
 
 	c.logger.Info().Msg("gNMI connection established")
@@ -233,8 +390,11 @@ func (c *Collector) dialOptions() ([]grpc.DialOption, error) {
 	
 	// Add PerRPCCredentials for basic auth if username/password are provided
 	// This matches gnmic's behavior: --insecure --username --password
-	if c.username != "" || c.password != "" {
-		opts = append(opts, grpc.WithPerRPCCredentials(&basicAuth{username: c.username, password: c.password}))
+	c.mu.RLock()
+	username, password := c.username, c.password
+	c.mu.RUnlock()
+	if username != "" || password != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(&basicAuth{username: username, password: password}))
 	}
 	
 	return opts, nil
@@ -245,7 +405,15 @@ func (c *Collector) transportCredentials() (credentials.TransportCredentials, er
 	if c.tlsConfig == nil || !c.tlsConfig.Enabled {
 		return insecure.NewCredentials(), nil
 	}
+	tlsCfg, err := c.clientTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
 
+// clientTLSConfig builds the TLS client configuration from the TLS settings
+func (c *Collector) clientTLSConfig() (*tls.Config, error) {
 	certPool, err := loadCertPool(c.tlsConfig.CAFile)
 	if err != nil {
 		return nil, err
@@ -254,13 +422,22 @@ func (c *Collector) transportCredentials() (credentials.TransportCredentials, er
 	if err != nil {
 		return nil, err
 	}
-	tlsCfg := &tls.Config{
+	return &tls.Config{
 		RootCAs:            certPool,
 		Certificates:       certs,
 		ServerName:         c.tlsConfig.ServerName,
 		InsecureSkipVerify: c.tlsConfig.InsecureSkipVerify,
-	}
-	return credentials.NewTLS(tlsCfg), nil
+		// Keep the device's certificate so its expiry can be watched,
+		// including when verification is skipped
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) > 0 {
+				c.mu.Lock()
+				c.serverCert = cs.PeerCertificates[0]
+				c.mu.Unlock()
+			}
+			return nil
+		},
+	}, nil
 }
 
 // loadCertPool loads CA certificates
@@ -329,6 +506,12 @@ func (c *Collector) backoffDuration(attempt int) time.Duration {
 
 // startSubscription sets up the gNMI subscription
 func (c *Collector) startSubscription() error {
+	return c.client.Send(c.subscribeRequest())
+}
+
+// subscribeRequest builds the subscription NetSpec opens on every device,
+// plus the switched-vlan containers when trunk VLANs are asserted
+func (c *Collector) subscribeRequest() *gnmi.SubscribeRequest {
 	// Subscribe to interface state container using SAMPLE mode.
 	// IOS-XE does not support ON_CHANGE for interface state leaves,
 	// and does not support subscribing to individual leaves like oper-status.
@@ -346,8 +529,27 @@ func (c *Collector) startSubscription() error {
 			SampleInterval: 10000000000, // 10 seconds in nanoseconds
 		},
 	}
+	if c.trunkVLANs {
+		// Physical ports carry switched-vlan under ethernet, port-channels
+		// under aggregation
+		for _, container := range []string{"ethernet", "aggregation"} {
+			subscriptions = append(subscriptions, &gnmi.Subscription{
+				Path: &gnmi.Path{
+					Elem: []*gnmi.PathElem{
+						{Name: "interfaces"},
+						{Name: "interface", Key: map[string]string{"name": "*"}},
+						{Name: container},
+						{Name: "switched-vlan"},
+						{Name: "state"},
+					},
+				},
+				Mode:           gnmi.SubscriptionMode_SAMPLE,
+				SampleInterval: 60000000000, // 60 seconds; trunk config rarely changes
+			})
+		}
+	}
 
-	req := &gnmi.SubscribeRequest{
+	return &gnmi.SubscribeRequest{
 		Request: &gnmi.SubscribeRequest_Subscribe{
 			Subscribe: &gnmi.SubscriptionList{
 				Subscription: subscriptions,
@@ -356,8 +558,6 @@ func (c *Collector) startSubscription() error {
 			},
 		},
 	}
-
-	return c.client.Send(req)
 }
 
 // receiveUpdates receives updates from the gNMI stream
@@ -369,7 +569,7 @@ func (c *Collector) receiveUpdates() {
 		default:
 			resp, err := c.client.Recv()
 			if err != nil {
-				c.emitError(fmt.Errorf("receive update: %w", err))
+				c.subscribeFailed(fmt.Errorf("receive update: %w", err))
 				// Connection lost, will be retried by Connect()
 				return
 			}
@@ -378,64 +578,130 @@ func (c *Collector) receiveUpdates() {
 			case *gnmi.SubscribeResponse_Update:
 				c.handleNotification(v.Update)
 			case *gnmi.SubscribeResponse_Error:
-				c.emitError(fmt.Errorf("subscribe error: %s", v.Error.Message))
+				c.subscribeFailed(fmt.Errorf("subscribe error: %s", v.Error.Message))
 				return
 			case *gnmi.SubscribeResponse_SyncResponse:
 				c.logger.Info().Msg("gNMI subscription sync complete — stream is active")
-				c.mu.Lock()
-				c.health.LastUpdate = time.Now()
-				c.health.SyncReceived = true
-				c.mu.Unlock()
+				c.markSynced()
 			}
 		}
 	}
 }
 
-// handleNotification processes a gNMI notification
+// handleNotification processes a gNMI notification. Path and value strings
+// are only rendered when debug logging is enabled; the health view keeps a
+// reference to a recent update, sampled at most every sampleInterval, and
+// formats it on demand.
 func (c *Collector) handleNotification(notif *gnmi.Notification) {
 	if notif == nil {
 		return
 	}
+	now := time.Now()
 	ts := time.Unix(0, notif.Timestamp)
 	if notif.Timestamp == 0 {
-		ts = time.Now()
+		ts = now
 	}
 
-	// Build path and value strings for debug logging and health tracking
-	var lastPath, lastValue string
 	for _, update := range notif.Update {
-		fullPath := ""
-		if notif.Prefix != nil {
-			fullPath = pathToString(notif.Prefix)
-		}
-		if update.Path != nil {
-			fullPath += pathToString(update.Path)
+		if ev := c.logger.Debug(); ev.Enabled() {
+			ev.Str("path", pathToString(notif.Prefix)+pathToString(update.Path)).
+				Str("value", typedValueToString(update.Val)).
+				Time("timestamp", ts).
+				Msg("gNMI update received")
 		}
-		val := typedValueToString(update.Val)
-		lastPath = fullPath
-		lastValue = val
-
-		c.logger.Debug().
-			Str("path", fullPath).
-			Str("value", val).
-			Time("timestamp", ts).
-			Msg("gNMI update received")
 	}
 
-	c.mu.Lock()
-	c.health.LastUpdate = ts
-	c.health.UpdateCount++
-	if lastPath != "" {
-		c.health.LastPath = lastPath
-		c.health.LastValue = lastValue
+	c.lastUpdateAt.Store(ts.UnixNano())
+	c.updateCount.Add(1)
+	if notif.Timestamp != 0 && c.synced.Load() {
+		c.observeSkew(now.Sub(ts))
+	}
+	if len(notif.Update) > 0 && now.UnixNano()-c.lastSampledAt.Load() >= int64(sampleInterval) {
+		c.lastSampledAt.Store(now.UnixNano())
+		c.mu.Lock()
+		c.lastPrefix = notif.Prefix
+		c.lastUpdate = notif.Update[len(notif.Update)-1]
+		c.mu.Unlock()
 	}
-	c.mu.Unlock()
 
 	select {
 	case c.updateChan <- notif:
+		return
 	default:
+	}
+
+	if !c.dropOldest {
 		c.logger.Warn().Msg("Update channel full, dropping notification")
+		return
 	}
+	// The receive loop is the only sender, so once the oldest notification
+	// is discarded the send below finds room
+	select {
+	case <-c.updateChan:
+	default:
+	}
+	select {
+	case c.updateChan <- notif:
+	default:
+	}
+	c.logger.Warn().Msg("Update channel full, dropping oldest notification")
+}
+
+// markConnected records a new connection. It is called before updates are
+// received, so the sync and any failure on the new stream are recorded
+// after it.
+func (c *Collector) markConnected() {
+	c.synced.Store(false)
+	c.skewSamples.Store(0)
+	c.mu.Lock()
+	c.health.Connected = true
+	c.health.LastError = ""
+	c.health.Diagnosis = ""
+	c.health.SyncReceived = false
+	c.health.ConnectedSince = time.Now()
+	c.mu.Unlock()
+}
+
+// markSynced records that the initial state has been received, by the sync
+// response or the first poll
+func (c *Collector) markSynced() {
+	c.lastUpdateAt.Store(time.Now().UnixNano())
+	c.synced.Store(true)
+	c.mu.Lock()
+	c.health.SyncReceived = true
+	c.health.SubscribeError = ""
+	c.health.SubscribeHint = ""
+	c.mu.Unlock()
+}
+
+// subscribeFailed records a failed or broken subscription with its class in
+// the health and passes it on to the error channel. After a permission or
+// capability failure the device is no longer streaming, so it is shown
+// disconnected until the next attempt.
+func (c *Collector) subscribeFailed(err error) {
+	subErr := classifySubscribeError(err)
+	if c.ctx.Err() == nil {
+		c.mu.Lock()
+		c.health.LastError = err.Error()
+		c.health.SubscribeError = subErr.Class
+		c.health.SubscribeHint = subErr.Hint()
+		if subErr.Permanent() {
+			c.health.Connected = false
+		}
+		c.mu.Unlock()
+	}
+	c.emitError(subErr)
+}
+
+// observeSkew folds the skew of one notification into the moving average.
+// Only the receive loop calls it, so the load and store need no lock.
+func (c *Collector) observeSkew(skew time.Duration) {
+	if c.skewSamples.Add(1) == 1 {
+		c.skew.Store(int64(skew))
+		return
+	}
+	avg := c.skew.Load()
+	c.skew.Store(avg + (int64(skew)-avg)/skewSmoothing)
 }
 
 // emitError sends an error to the error channel
@@ -462,8 +728,6 @@ func (c *Collector) Done() <-chan struct{} {
 // the device is reachable and responding. Returns the supported models count
 // and any error encountered.
 func (c *Collector) TestConnection() (int, string, error) {
-	addr := fmt.Sprintf("%s:%d", c.address, c.port)
-
 	dialCtx, dialCancel := context.WithTimeout(context.Background(), c.dialTimeout)
 	defer dialCancel()
 
@@ -472,7 +736,7 @@ func (c *Collector) TestConnection() (int, string, error) {
 		return 0, "", fmt.Errorf("dial options: %w", err)
 	}
 
-	conn, err := grpc.DialContext(dialCtx, addr, opts...)
+	conn, err := c.openSession(dialCtx, opts...)
 	if err != nil {
 		return 0, "", fmt.Errorf("failed to dial: %w", err)
 	}
@@ -505,8 +769,11 @@ func (c *Collector) Close() error {
 	if c.client != nil {
 		c.client.CloseSend()
 	}
-	if c.conn != nil {
-		return c.conn.Close()
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+	if conn != nil {
+		return conn.Close()
 	}
 	return nil
 }
@@ -0,0 +1,151 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// GetLicenses reads /system/license/licenses (openconfig-license) from the
+// device. Each license[license-id]/state reports description, in-use,
+// expired and expiration-date, which devices send as seconds since the
+// epoch (some as nanoseconds) or an RFC 3339 timestamp.
+func (c *Collector) GetLicenses() ([]config.License, error) {
+	notifications, err := c.Get(&gnmi.Path{Elem: []*gnmi.PathElem{
+		{Name: "system"}, {Name: "license"}, {Name: "licenses"},
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("reading licenses: %w", err)
+	}
+	leaves := make(map[string]map[string]string)
+	for _, notif := range notifications {
+		for _, update := range notif.Update {
+			collectLicenseUpdate(leaves, updateElems(notif, update), update.Val)
+		}
+	}
+
+	licenses := make([]config.License, 0, len(leaves))
+	for id, state := range leaves {
+		lic := config.License{
+			ID:          id,
+			Description: state["description"],
+			Expired:     state["expired"] == "true",
+			ExpiresAt:   parseLicenseDate(state["expiration-date"]),
+		}
+		if inUse, ok := state["in-use"]; ok {
+			lic.InUse = inUse == "true"
+			lic.InUseKnown = true
+		}
+		licenses = append(licenses, lic)
+	}
+	sort.Slice(licenses, func(i, j int) bool { return licenses[i].ID < licenses[j].ID })
+
+	c.logger.Debug().Int("licenses", len(licenses)).Msg("Licenses read via gNMI Get")
+	return licenses, nil
+}
+
+// collectLicenseUpdate records the leaves of a Get update, either a scalar
+// state leaf or a JSON subtree
+func collectLicenseUpdate(leaves map[string]map[string]string, elems []*gnmi.PathElem, val *gnmi.TypedValue) {
+	id := ""
+	for _, elem := range elems {
+		if stripModule(elem.Name) == "license" && elem.Key["license-id"] != "" {
+			id = elem.Key["license-id"]
+		}
+	}
+
+	var raw []byte
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_JsonIetfVal:
+		raw = v.JsonIetfVal
+	case *gnmi.TypedValue_JsonVal:
+		raw = v.JsonVal
+	}
+	if raw == nil {
+		if id != "" && len(elems) > 0 {
+			setLicenseLeaf(leaves, id, stripModule(elems[len(elems)-1].Name), typedValueToString(val))
+		}
+		return
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return
+	}
+	walkLicenseJSON(leaves, decoded, id)
+}
+
+// walkLicenseJSON descends through licenses/license/state containers and
+// records their leaves
+func walkLicenseJSON(leaves map[string]map[string]string, node interface{}, id string) {
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			walkLicenseJSON(leaves, item, id)
+		}
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			fields[stripModule(key)] = value
+		}
+		if licenseID, ok := fields["license-id"].(string); ok {
+			id = licenseID
+		}
+		if id != "" {
+			for _, leaf := range []string{"description", "in-use", "expired", "expiration-date"} {
+				switch x := fields[leaf].(type) {
+				case string:
+					setLicenseLeaf(leaves, id, leaf, x)
+				case bool:
+					setLicenseLeaf(leaves, id, leaf, strconv.FormatBool(x))
+				case float64:
+					setLicenseLeaf(leaves, id, leaf, strconv.FormatFloat(x, 'f', -1, 64))
+				}
+			}
+		}
+		for _, key := range []string{"license", "licenses", "state"} {
+			if child, ok := fields[key]; ok {
+				walkLicenseJSON(leaves, child, id)
+			}
+		}
+	}
+}
+
+// setLicenseLeaf records one leaf value for a license
+func setLicenseLeaf(leaves map[string]map[string]string, id, leaf, value string) {
+	if leaves[id] == nil {
+		leaves[id] = make(map[string]string)
+	}
+	switch leaf {
+	case "description", "in-use", "expired", "expiration-date":
+		leaves[id][leaf] = strings.TrimSpace(value)
+	}
+}
+
+// parseLicenseDate parses an expiration date, returning the zero time for
+// perpetual licenses (no date, or 0)
+func parseLicenseDate(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+		switch {
+		case n == 0:
+			return time.Time{}
+		case n > 1e15:
+			return time.Unix(0, int64(n))
+		default:
+			return time.Unix(int64(n), 0)
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
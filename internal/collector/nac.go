@@ -0,0 +1,154 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Subtrees read for network access control state. Both are keyed by
+// interface name:
+//
+//	/dot1x/interfaces/interface[name]: authenticator-enabled (bool) or
+//	  port-control ("auto" enforces authentication)
+//	/port-security/interfaces/interface[name]: enabled (bool),
+//	  violation-mode (SHUTDOWN, RESTRICT or PROTECT)
+const (
+	nacDot1X        = "dot1x"
+	nacPortSecurity = "port-security"
+)
+
+// GetNACState reads the 802.1X and/or port-security state of the device's
+// interfaces. Interfaces the device reports nothing for are absent from the
+// result, which a caller requiring enforcement treats as disabled.
+func (c *Collector) GetNACState(dot1x, portSecurity bool) (map[string]config.NACState, error) {
+	states := make(map[string]*config.NACState)
+	for _, root := range []struct {
+		name   string
+		wanted bool
+	}{{nacDot1X, dot1x}, {nacPortSecurity, portSecurity}} {
+		if !root.wanted {
+			continue
+		}
+		notifications, err := c.Get(&gnmi.Path{Elem: []*gnmi.PathElem{{Name: root.name}, {Name: "interfaces"}}})
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", root.name, err)
+		}
+		for _, notif := range notifications {
+			for _, update := range notif.Update {
+				elems := make([]*gnmi.PathElem, 0)
+				if notif.Prefix != nil {
+					elems = append(elems, notif.Prefix.Elem...)
+				}
+				if update.Path != nil {
+					elems = append(elems, update.Path.Elem...)
+				}
+				collectNACUpdate(states, root.name, elems, update.Val)
+			}
+		}
+	}
+
+	result := make(map[string]config.NACState, len(states))
+	for name, state := range states {
+		result[name] = *state
+	}
+	return result, nil
+}
+
+// collectNACUpdate merges a single Get update, either a scalar leaf or a
+// JSON subtree, into the state map
+func collectNACUpdate(states map[string]*config.NACState, root string, elems []*gnmi.PathElem, val *gnmi.TypedValue) {
+	ifName := ""
+	for _, elem := range elems {
+		if stripModule(elem.Name) == "interface" {
+			ifName = elem.Key["name"]
+		}
+	}
+
+	var raw []byte
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_JsonIetfVal:
+		raw = v.JsonIetfVal
+	case *gnmi.TypedValue_JsonVal:
+		raw = v.JsonVal
+	}
+
+	if raw == nil {
+		if ifName == "" || len(elems) == 0 {
+			return
+		}
+		var value interface{} = typedValueToString(val)
+		if b, ok := val.GetValue().(*gnmi.TypedValue_BoolVal); ok {
+			value = b.BoolVal
+		}
+		setNACLeaf(states, root, ifName, stripModule(elems[len(elems)-1].Name), value)
+		return
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return
+	}
+	walkNACJSON(states, root, decoded, ifName)
+}
+
+// walkNACJSON descends through interfaces/interface/{config,state}
+// containers and records the leaves of interest
+func walkNACJSON(states map[string]*config.NACState, root string, node interface{}, ifName string) {
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			walkNACJSON(states, root, item, ifName)
+		}
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			fields[stripModule(key)] = value
+		}
+		if name, ok := fields["name"].(string); ok {
+			if _, hasState := fields["state"]; hasState {
+				ifName = name
+			} else if _, hasConfig := fields["config"]; hasConfig {
+				ifName = name
+			}
+		}
+		if ifName != "" {
+			for _, leaf := range []string{"enabled", "authenticator-enabled", "port-control", "violation-mode"} {
+				if value, ok := fields[leaf]; ok {
+					setNACLeaf(states, root, ifName, leaf, value)
+				}
+			}
+		}
+		// state is walked after config so operational values win
+		for _, key := range []string{"interfaces", "interface", "config", "state"} {
+			if child, ok := fields[key]; ok {
+				walkNACJSON(states, root, child, ifName)
+			}
+		}
+	}
+}
+
+// setNACLeaf records one leaf value for an interface
+func setNACLeaf(states map[string]*config.NACState, root, ifName, leaf string, value interface{}) {
+	state, ok := states[ifName]
+	if !ok {
+		state = &config.NACState{}
+		states[ifName] = state
+	}
+	enabled := value == true || value == "true"
+	switch {
+	case root == nacDot1X && (leaf == "enabled" || leaf == "authenticator-enabled"):
+		state.Dot1X = enabled
+	case root == nacDot1X && leaf == "port-control":
+		s, _ := value.(string)
+		state.Dot1X = strings.EqualFold(stripModule(s), "auto")
+	case root == nacPortSecurity && leaf == "enabled":
+		state.PortSecurity = enabled
+	case root == nacPortSecurity && leaf == "violation-mode":
+		s, _ := value.(string)
+		state.ViolationMode = strings.ToLower(stripModule(s))
+	}
+}
@@ -0,0 +1,188 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// SetPolling makes the collector read the subscribed paths with a gNMI Get
+// every interval instead of subscribing, for devices that handle Subscribe
+// poorly. Each response is split into the leaf updates a subscription would
+// deliver and fed into Updates. It must be called before Connect.
+func (c *Collector) SetPolling(interval time.Duration) {
+	c.pollInterval = interval
+}
+
+// pollUpdates reads the subscribed paths every poll interval until the
+// collector is closed or a Get fails, which is reported like a broken
+// subscription. The first successful Get counts as the sync.
+func (c *Collector) pollUpdates(client gnmi.GNMIClient) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		notifications, err := c.poll(client)
+		if err != nil {
+			c.subscribeFailed(fmt.Errorf("poll: %w", err))
+			return
+		}
+		for _, notif := range notifications {
+			c.handleNotification(notif)
+		}
+		if !c.synced.Load() {
+			c.logger.Info().Dur("interval", c.pollInterval).Msg("gNMI poll complete — polling is active")
+			c.markSynced()
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll issues one Get for the subscribed paths and returns the response as
+// leaf notifications
+func (c *Collector) poll(client gnmi.GNMIClient) ([]*gnmi.Notification, error) {
+	var paths []*gnmi.Path
+	for _, sub := range c.subscribeRequest().GetSubscribe().GetSubscription() {
+		paths = append(paths, sub.Path)
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.dialTimeout)
+	defer cancel()
+	resp, err := client.Get(ctx, &gnmi.GetRequest{
+		Path:     paths,
+		Encoding: gnmi.Encoding_JSON_IETF,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]*gnmi.Notification, 0, len(resp.GetNotification()))
+	for _, notif := range resp.GetNotification() {
+		leaves := &gnmi.Notification{Timestamp: notif.Timestamp}
+		for _, update := range notif.Update {
+			var elems []*gnmi.PathElem
+			elems = append(elems, notif.GetPrefix().GetElem()...)
+			elems = append(elems, update.GetPath().GetElem()...)
+			leaves.Update = append(leaves.Update, leafUpdates(elems, update.Val)...)
+		}
+		notifications = append(notifications, leaves)
+	}
+	return notifications, nil
+}
+
+// leafUpdates splits a Get update into one update per leaf with its full
+// path. Devices answer either with scalar leaves or with a JSON subtree,
+// whose list entries are keyed by their name, or index for subinterfaces.
+func leafUpdates(elems []*gnmi.PathElem, val *gnmi.TypedValue) []*gnmi.Update {
+	var raw []byte
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_JsonIetfVal:
+		raw = v.JsonIetfVal
+	case *gnmi.TypedValue_JsonVal:
+		raw = v.JsonVal
+	default:
+		return []*gnmi.Update{{Path: &gnmi.Path{Elem: elems}, Val: val}}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber() // counters exceed float64 precision
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return nil
+	}
+	var updates []*gnmi.Update
+	walkLeaves(elems, decoded, &updates)
+	return updates
+}
+
+// walkLeaves appends an update for every leaf below a JSON node at path
+// elems
+func walkLeaves(elems []*gnmi.PathElem, node interface{}, updates *[]*gnmi.Update) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			walkLeaves(appendElem(elems, &gnmi.PathElem{Name: stripModule(key)}), child, updates)
+		}
+	case []interface{}:
+		if len(v) == 0 || len(elems) == 0 {
+			return
+		}
+		if _, isList := v[0].(map[string]interface{}); !isList {
+			leaflist := &gnmi.ScalarArray{}
+			for _, item := range v {
+				if val := scalarValue(item); val != nil {
+					leaflist.Element = append(leaflist.Element, val)
+				}
+			}
+			*updates = append(*updates, &gnmi.Update{
+				Path: &gnmi.Path{Elem: elems},
+				Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_LeaflistVal{LeaflistVal: leaflist}},
+			})
+			return
+		}
+		parent, list := elems[:len(elems)-1], elems[len(elems)-1]
+		for _, item := range v {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			elem := &gnmi.PathElem{Name: list.Name, Key: listKey(entry)}
+			walkLeaves(appendElem(parent, elem), entry, updates)
+		}
+	default:
+		if val := scalarValue(v); val != nil {
+			*updates = append(*updates, &gnmi.Update{Path: &gnmi.Path{Elem: elems}, Val: val})
+		}
+	}
+}
+
+// listKey returns the key of a JSON list entry: its name, or its index for
+// subinterfaces
+func listKey(entry map[string]interface{}) map[string]string {
+	for key, value := range entry {
+		name := stripModule(key)
+		if name != "name" && name != "index" {
+			continue
+		}
+		if val := scalarValue(value); val != nil {
+			return map[string]string{name: typedValueToString(val)}
+		}
+	}
+	return nil
+}
+
+// scalarValue converts a decoded JSON scalar into a typed value, integers
+// as the uint or int values devices stream
+func scalarValue(v interface{}) *gnmi.TypedValue {
+	switch x := v.(type) {
+	case string:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: x}}
+	case bool:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: x}}
+	case json.Number:
+		if n, err := strconv.ParseUint(x.String(), 10, 64); err == nil {
+			return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: n}}
+		}
+		if n, err := strconv.ParseInt(x.String(), 10, 64); err == nil {
+			return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: n}}
+		}
+		if f, err := x.Float64(); err == nil {
+			return &gnmi.TypedValue{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: f}}
+		}
+	}
+	return nil
+}
+
+// appendElem returns elems with elem appended, leaving elems untouched
+func appendElem(elems []*gnmi.PathElem, elem *gnmi.PathElem) []*gnmi.PathElem {
+	return append(append(make([]*gnmi.PathElem, 0, len(elems)+1), elems...), elem)
+}
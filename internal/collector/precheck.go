@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Connect failure classes reported in DeviceHealth.Diagnosis
+const (
+	DiagnosisHostUnreachable = "host_unreachable"     // no ICMP reply and no TCP answer
+	DiagnosisPortFiltered    = "port_filtered"        // host answers ping but the gNMI port times out
+	DiagnosisPortClosed      = "port_closed"          // host refuses connections on the gNMI port
+	DiagnosisTLSFailed       = "tls_handshake_failed" // TCP connects but TLS does not
+	DiagnosisGNMI            = "gnmi_error"           // transport is fine; gNMI itself failed (auth, subscribe)
+)
+
+// precheckTimeout bounds each probe
+const precheckTimeout = 2 * time.Second
+
+// SetPrechecks enables probing the device with ICMP, TCP and TLS after a
+// failed connect so the failure can be classified. It must be called before
+// Connect.
+func (c *Collector) SetPrechecks(enabled bool) {
+	c.prechecks = enabled
+}
+
+// diagnose probes the device to explain a connect failure, returning one of
+// the Diagnosis constants and a short human-readable description
+func (c *Collector) diagnose() (string, string) {
+	addr := net.JoinHostPort(c.address, fmt.Sprint(c.port))
+
+	dialer := net.Dialer{Timeout: precheckTimeout}
+	conn, err := dialer.DialContext(c.ctx, "tcp", addr)
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return DiagnosisPortClosed, fmt.Sprintf("gNMI port %d closed", c.port)
+		}
+		replied, pingErr := ping(c.ctx, c.address, precheckTimeout)
+		switch {
+		case replied:
+			return DiagnosisPortFiltered, fmt.Sprintf("host answers ping but gNMI port %d does not respond", c.port)
+		case pingErr != nil:
+			// ICMP sockets need privileges or ping_group_range; fall back
+			// to what TCP alone can tell
+			return DiagnosisHostUnreachable, fmt.Sprintf("host unreachable on port %d (ping unavailable: %v)", c.port, pingErr)
+		}
+		return DiagnosisHostUnreachable, "host unreachable (no ping reply, no TCP answer)"
+	}
+	defer conn.Close()
+
+	if c.tlsConfig == nil || !c.tlsConfig.Enabled {
+		return DiagnosisGNMI, fmt.Sprintf("gNMI port %d open", c.port)
+	}
+	tlsCfg, err := c.clientTLSConfig()
+	if err != nil {
+		return DiagnosisTLSFailed, fmt.Sprintf("TLS config: %v", err)
+	}
+	if tlsCfg.ServerName == "" {
+		tlsCfg.ServerName = c.address
+	}
+	conn.SetDeadline(time.Now().Add(precheckTimeout))
+	if err := tls.Client(conn, tlsCfg).Handshake(); err != nil {
+		return DiagnosisTLSFailed, fmt.Sprintf("TLS handshake failed: %v", err)
+	}
+	return DiagnosisGNMI, "TCP and TLS OK"
+}
+
+// ping sends one ICMP echo and reports whether the host replied. It tries an
+// unprivileged ICMP socket first, then a raw one; the error is set only when
+// neither can be opened.
+func ping(ctx context.Context, host string, timeout time.Duration) (bool, error) {
+	ipAddr, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(ipAddr) == 0 {
+		return false, nil
+	}
+	ip := ipAddr[0].IP
+
+	udpNet, rawNet, listen, proto := "udp4", "ip4:icmp", "0.0.0.0", 1
+	var echo, reply icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if ip.To4() == nil {
+		udpNet, rawNet, listen, proto = "udp6", "ip6:ipv6-icmp", "::", 58
+		echo, reply = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	var dst net.Addr = &net.UDPAddr{IP: ip}
+	conn, err := icmp.ListenPacket(udpNet, listen)
+	if err != nil {
+		dst = &net.IPAddr{IP: ip}
+		conn, err = icmp.ListenPacket(rawNet, listen)
+		if err != nil {
+			return false, err
+		}
+	}
+	defer conn.Close()
+
+	msg := icmp.Message{
+		Type: echo,
+		Body: &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: 1, Data: []byte("netspec")},
+	}
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return false, nil
+	}
+	if _, err := conn.WriteTo(b, dst); err != nil {
+		return false, nil
+	}
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return false, nil
+		}
+		m, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || m.Type != reply {
+			continue
+		}
+		if peerIP(peer).Equal(ip) {
+			return true, nil
+		}
+	}
+}
+
+func peerIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.IPAddr:
+		return a.IP
+	}
+	return nil
+}
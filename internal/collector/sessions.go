@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// session is a gRPC connection to the device, counted in the collector's
+// session usage from dial until its first Close
+type session struct {
+	*grpc.ClientConn
+	c    *Collector
+	once sync.Once
+}
+
+// Close closes the connection, releasing its place in the session count
+func (s *session) Close() error {
+	s.once.Do(func() { s.c.sessions.Add(-1) })
+	return s.ClientConn.Close()
+}
+
+// openSession dials the device and counts the connection as a session
+func (c *Collector) openSession(ctx context.Context, opts ...grpc.DialOption) (*session, error) {
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("%s:%d", c.address, c.port), opts...)
+	if err != nil {
+		return nil, err
+	}
+	open := c.sessions.Add(1)
+	raise(&c.peakSessions, open)
+	raise(&c.windowPeakSessions, open)
+	return &session{ClientConn: conn, c: c}, nil
+}
+
+// TakeSessionPeak returns the most sessions held at once since the last
+// call, and starts a new window at the current count. One-shot RPCs open
+// and close between checks, so a check of the current count alone would
+// miss them.
+func (c *Collector) TakeSessionPeak() int {
+	current := c.sessions.Load()
+	peak := c.windowPeakSessions.Swap(current)
+	return int(max(peak, current))
+}
+
+// raise sets v to n if n is larger
+func raise(v interface {
+	Load() int64
+	CompareAndSwap(old, new int64) bool
+}, n int64) {
+	for {
+		old := v.Load()
+		if n <= old || v.CompareAndSwap(old, n) {
+			return
+		}
+	}
+}
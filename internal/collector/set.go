@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// SetInterfaceEnabled performs a one-shot gNMI Set of an interface's
+// config/enabled leaf
+func (c *Collector) SetInterfaceEnabled(ifaceName string, enabled bool) error {
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	client := gnmi.NewGNMIClient(conn)
+
+	setCtx, setCancel := context.WithTimeout(context.Background(), c.dialTimeout)
+	defer setCancel()
+
+	path := &gnmi.Path{Elem: []*gnmi.PathElem{
+		{Name: "interfaces"},
+		{Name: "interface", Key: map[string]string{"name": ifaceName}},
+		{Name: "config"},
+		{Name: "enabled"},
+	}}
+	_, err = client.Set(setCtx, &gnmi.SetRequest{
+		Update: []*gnmi.Update{{
+			Path: path,
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: enabled}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("set request failed: %w", err)
+	}
+
+	c.logger.Info().
+		Str("interface", ifaceName).
+		Bool("enabled", enabled).
+		Msg("Interface enabled state set via gNMI Set")
+	return nil
+}
+
+// BouncePort disables an interface and re-enables it after downFor. The
+// interface is re-enabled even when ctx is cancelled in between.
+func (c *Collector) BouncePort(ctx context.Context, ifaceName string, downFor time.Duration) error {
+	if err := c.SetInterfaceEnabled(ifaceName, false); err != nil {
+		return fmt.Errorf("disabling %s: %w", ifaceName, err)
+	}
+	select {
+	case <-time.After(downFor):
+	case <-ctx.Done():
+	}
+	if err := c.SetInterfaceEnabled(ifaceName, true); err != nil {
+		return fmt.Errorf("re-enabling %s: %w", ifaceName, err)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package config
+
+// ACLBindings lists ACL sets attached to an interface by direction. As
+// interface configuration it names the sets that must be attached; as
+// observed state it holds what the device reports.
+type ACLBindings struct {
+	Ingress []string `yaml:"ingress,omitempty"`
+	Egress  []string `yaml:"egress,omitempty"`
+}
+
+// RequiresACLs reports whether any interface of the device declares
+// required_acls, so its ACL attachments must be read periodically
+func (d DeviceConfig) RequiresACLs() bool {
+	for _, ifCfg := range d.Interfaces {
+		if ifCfg.RequiredACLs != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ObservedInterface is the state of an interface as read from a device,
+// used to propose desired state that treats current reality as intent
+type ObservedInterface struct {
+	Name        string
+	Description string
+	Type        string // IANA interface type, e.g. "iana-if-type:l3ipvlan"
+	OperStatus  string
+	AdminStatus string
+}
+
+// ProposeDevice builds a device stanza whose interfaces declare their
+// currently observed oper and admin state as desired state. Interfaces
+// without a usable oper-status are omitted.
+func ProposeDevice(address, description string, observed []ObservedInterface) DeviceConfig {
+	dev := DeviceConfig{
+		Address:     address,
+		Description: description,
+		Interfaces:  make(map[string]InterfaceConfig),
+	}
+	for _, iface := range observed {
+		desired := OperToDesiredState(iface.OperStatus)
+		if desired == "" {
+			continue
+		}
+		dev.Interfaces[iface.Name] = InterfaceConfig{
+			Description:  iface.Description,
+			DesiredState: desired,
+			AdminState:   AdminToDesiredState(iface.AdminStatus),
+		}
+	}
+	return dev
+}
+
+// OperToDesiredState maps an OpenConfig oper-status (UP, DOWN,
+// LOWER_LAYER_DOWN, NOT_PRESENT, ...) onto a desired_state value
+func OperToDesiredState(oper string) string {
+	switch strings.ToLower(strings.TrimSpace(oper)) {
+	case "":
+		return ""
+	case "up":
+		return "up"
+	default:
+		return "down"
+	}
+}
+
+// AdminToDesiredState maps an OpenConfig admin-status (UP, DOWN, TESTING)
+// onto an admin_state value, returning "" when there is no equivalent
+func AdminToDesiredState(admin string) string {
+	switch strings.ToLower(strings.TrimSpace(admin)) {
+	case "up", "enabled":
+		return "enabled"
+	case "down", "disabled":
+		return "disabled"
+	default:
+		return ""
+	}
+}
+
+// MarshalDeviceStanza renders a device as a desired-state.yaml "devices:"
+// stanza ready to paste into configuration
+func MarshalDeviceStanza(name string, dev DeviceConfig) ([]byte, error) {
+	return yaml.Marshal(map[string]map[string]DeviceConfig{
+		"devices": {name: dev},
+	})
+}
+
+// Undeclared returns the observed interfaces of a device that neither its
+// interfaces nor an ignore rule cover, in name order
+func (c *Config) Undeclared(deviceName string, observed []ObservedInterface) []ObservedInterface {
+	declared := c.DesiredState.Devices[deviceName].Interfaces
+	var undeclared []ObservedInterface
+	for _, iface := range c.FilterIgnored(deviceName, observed) {
+		if _, ok := declared[iface.Name]; !ok {
+			undeclared = append(undeclared, iface)
+		}
+	}
+	sort.Slice(undeclared, func(i, j int) bool { return undeclared[i].Name < undeclared[j].Name })
+	return undeclared
+}
+
+// MarshalInterfacesStanza renders interfaces to add to an existing device
+// as a "devices:" stanza holding only them. Dropped into the overlay
+// directory (desired-state.d/) it merges into the device's interfaces.
+func MarshalInterfacesStanza(name string, interfaces map[string]InterfaceConfig) ([]byte, error) {
+	return yaml.Marshal(map[string]map[string]map[string]map[string]InterfaceConfig{
+		"devices": {name: {"interfaces": interfaces}},
+	})
+}
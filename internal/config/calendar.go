@@ -0,0 +1,205 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CalendarsConfig holds named holiday and exception calendars from
+// calendars.yaml
+type CalendarsConfig struct {
+	Calendars map[string]CalendarConfig `yaml:"calendars,omitempty"`
+}
+
+// CalendarConfig lists the dates of a calendar, inline and/or from an iCal
+// file. Time windows that reference the calendar treat its dates like
+// weekends.
+type CalendarConfig struct {
+	Dates []string `yaml:"dates,omitempty"` // YYYY-MM-DD
+	ICal  string   `yaml:"ical,omitempty"`  // .ics path, relative to the config directory
+}
+
+// Calendar is a resolved set of dates
+type Calendar struct {
+	dates  map[string]bool // YYYY-MM-DD
+	yearly map[string]bool // MM-DD, from yearly recurring iCal events
+}
+
+// Contains reports whether t's date, in t's location, is in the calendar
+func (c *Calendar) Contains(t time.Time) bool {
+	if c == nil {
+		return false
+	}
+	return c.dates[t.Format("2006-01-02")] || c.yearly[t.Format("01-02")]
+}
+
+// loadCalendars resolves every calendar in cfg and attaches them to the time
+// windows that reference them
+func loadCalendars(dir string, cfg *Config) error {
+	calendars := make(map[string]*Calendar, len(cfg.Calendars.Calendars))
+	for name, calCfg := range cfg.Calendars.Calendars {
+		cal := &Calendar{dates: make(map[string]bool), yearly: make(map[string]bool)}
+		for _, d := range calCfg.Dates {
+			if _, err := time.Parse("2006-01-02", d); err != nil {
+				return fmt.Errorf("calendar %s: date %q is not YYYY-MM-DD", name, d)
+			}
+			cal.dates[d] = true
+		}
+		if calCfg.ICal != "" {
+			path := calCfg.ICal
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			if err := cal.loadICal(path); err != nil {
+				return fmt.Errorf("calendar %s: %w", name, err)
+			}
+		}
+		calendars[name] = cal
+	}
+
+	link := func(w *TimeWindow) error {
+		if w.Calendar == "" {
+			return nil
+		}
+		cal, ok := calendars[w.Calendar]
+		if !ok {
+			return fmt.Errorf("unknown calendar %s", w.Calendar)
+		}
+		w.holidays = cal
+		return nil
+	}
+	for devName, dev := range cfg.DesiredState.Devices {
+		if dev.Monitoring != nil {
+			for i := range dev.Monitoring.Windows {
+				if err := link(&dev.Monitoring.Windows[i]); err != nil {
+					return fmt.Errorf("device %s: monitoring window %d: %w", devName, i+1, err)
+				}
+			}
+		}
+		for ifName, ifCfg := range dev.Interfaces {
+			for i := range ifCfg.Schedule {
+				if err := link(&ifCfg.Schedule[i].TimeWindow); err != nil {
+					return fmt.Errorf("device %s, interface %s: schedule entry %d: %w", devName, ifName, i+1, err)
+				}
+			}
+		}
+	}
+	for name, channel := range cfg.Alerts.Channels {
+		if channel.QuietHours == nil {
+			continue
+		}
+		for i := range channel.QuietHours.Windows {
+			if err := link(&channel.QuietHours.Windows[i]); err != nil {
+				return fmt.Errorf("channel %s: quiet_hours window %d: %w", name, i+1, err)
+			}
+		}
+	}
+	for i := range cfg.Maintenance.MaintenanceWindows {
+		mw := &cfg.Maintenance.MaintenanceWindows[i]
+		mw.Schedule.resolve()
+		if mw.Schedule.Calendar == "" {
+			continue
+		}
+		cal, ok := calendars[mw.Schedule.Calendar]
+		if !ok {
+			return fmt.Errorf("maintenance window %s: unknown calendar %s", mw.Name, mw.Schedule.Calendar)
+		}
+		mw.Schedule.holidays = cal
+	}
+	return nil
+}
+
+// loadICal adds the all-day events of an iCal file. Multi-day events add
+// every day up to DTEND (exclusive), and events with a yearly RRULE recur on
+// the same month and day every year. Other recurrence rules are ignored.
+func (c *Calendar) loadICal(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lines, err := unfoldICal(f)
+	if err != nil {
+		return err
+	}
+
+	var inEvent, yearly bool
+	var start, end time.Time
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(name, ";") // drop parameters such as VALUE=DATE
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if value == "VEVENT" {
+				inEvent, yearly = true, false
+				start, end = time.Time{}, time.Time{}
+			}
+		case "DTSTART":
+			if inEvent {
+				start = parseICalDate(value)
+			}
+		case "DTEND":
+			if inEvent {
+				end = parseICalDate(value)
+			}
+		case "RRULE":
+			yearly = inEvent && strings.Contains(strings.ToUpper(value), "FREQ=YEARLY")
+		case "END":
+			if value != "VEVENT" || !inEvent {
+				continue
+			}
+			inEvent = false
+			if start.IsZero() {
+				continue
+			}
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				if yearly {
+					c.yearly[d.Format("01-02")] = true
+				} else {
+					c.dates[d.Format("2006-01-02")] = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// unfoldICal reads the lines of an iCal document, joining continuation
+// lines, which start with a space or tab, to the line they continue
+func unfoldICal(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICalDate reads the date part of an iCal DATE or DATE-TIME value
+func parseICalDate(v string) time.Time {
+	if len(v) < 8 {
+		return time.Time{}
+	}
+	t, err := time.Parse("20060102", v[:8])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
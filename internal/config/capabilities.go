@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// CapabilitiesRefreshConfig tunes the job that re-reads the gNMI
+// Capabilities of every device, recording changes to the models and
+// encodings it advertises and raising gnmi_capabilities_changed when they
+// change, as after a software upgrade. Without it the job runs at the
+// defaults.
+type CapabilitiesRefreshConfig struct {
+	Interval    time.Duration `yaml:"interval,omitempty"`    // default 6h
+	Concurrency int           `yaml:"concurrency,omitempty"` // devices read at once, default 4
+	Severity    string        `yaml:"severity,omitempty"`    // default warning
+}
+
+// IntervalOrDefault returns the refresh interval with its default applied
+func (c *CapabilitiesRefreshConfig) IntervalOrDefault() time.Duration {
+	if c == nil || c.Interval == 0 {
+		return 6 * time.Hour
+	}
+	return c.Interval
+}
+
+// ConcurrencyOrDefault returns how many devices are read at once with its
+// default applied
+func (c *CapabilitiesRefreshConfig) ConcurrencyOrDefault() int {
+	if c == nil || c.Concurrency == 0 {
+		return 4
+	}
+	return c.Concurrency
+}
+
+// SeverityOrDefault returns the gnmi_capabilities_changed severity, warning
+// unless set
+func (c *CapabilitiesRefreshConfig) SeverityOrDefault() string {
+	if c == nil {
+		return "warning"
+	}
+	return severityOr(c.Severity, "warning")
+}
+
+// Validate checks the interval and concurrency
+func (c *CapabilitiesRefreshConfig) Validate() error {
+	if c.Interval < 0 || (c.Interval > 0 && c.Interval < 5*time.Minute) {
+		return fmt.Errorf("interval must be at least 5m")
+	}
+	if c.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative")
+	}
+	return nil
+}
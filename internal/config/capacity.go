@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CapacityConfig marks an uplink for capacity trending. Its daily peak
+// utilization is recorded and projected linearly; an advisory fires when
+// the projection crosses the threshold within the horizon.
+type CapacityConfig struct {
+	Speed     string        `yaml:"speed"`               // link speed, e.g. 10G, 400M or bits per second
+	Threshold float64       `yaml:"threshold,omitempty"` // percent utilization, default 80
+	Horizon   time.Duration `yaml:"horizon,omitempty"`   // default 720h (30 days)
+}
+
+// SpeedBPS parses the link speed into bits per second
+func (c CapacityConfig) SpeedBPS() (float64, error) {
+	s := strings.ToUpper(strings.TrimSpace(c.Speed))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "BPS"), "B")
+	scale := 1.0
+	switch {
+	case strings.HasSuffix(s, "T"):
+		scale = 1e12
+	case strings.HasSuffix(s, "G"):
+		scale = 1e9
+	case strings.HasSuffix(s, "M"):
+		scale = 1e6
+	case strings.HasSuffix(s, "K"):
+		scale = 1e3
+	}
+	if scale != 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid speed %q", c.Speed)
+	}
+	return n * scale, nil
+}
+
+// Limits returns the threshold (as a fraction) and horizon with defaults
+// applied
+func (c CapacityConfig) Limits() (threshold float64, horizon time.Duration) {
+	threshold, horizon = c.Threshold, c.Horizon
+	if threshold == 0 {
+		threshold = 80
+	}
+	if horizon == 0 {
+		horizon = 30 * 24 * time.Hour
+	}
+	return threshold / 100, horizon
+}
+
+// Validate checks the speed, threshold and horizon
+func (c CapacityConfig) Validate() error {
+	if _, err := c.SpeedBPS(); err != nil {
+		return err
+	}
+	if c.Threshold < 0 || c.Threshold > 100 {
+		return fmt.Errorf("threshold must be a percentage")
+	}
+	if c.Horizon < 0 {
+		return fmt.Errorf("horizon must not be negative")
+	}
+	return nil
+}
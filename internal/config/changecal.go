@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChangeCalendarConfig creates maintenance windows from an external change
+// calendar. Changes come from an iCal feed polled every poll_interval and/or
+// are pushed to POST /api/webhooks/calendar, and each covers the devices
+// carrying a tag its categories map to. Windows from the calendar suppress
+// alerts like suppress_alerts windows in maintenance.yaml.
+type ChangeCalendarConfig struct {
+	ICalURLEnv   string        `yaml:"ical_url_env,omitempty"`  // environment variable holding the feed URL
+	PollInterval time.Duration `yaml:"poll_interval,omitempty"` // default 5m
+	// WebhookSecretEnv holds the secret pushed changes are signed with
+	// (X-NetSpec-Signature); the webhook is disabled without it
+	WebhookSecretEnv string `yaml:"webhook_secret_env,omitempty"`
+	// Categories maps calendar categories (case-insensitive) to the device
+	// tags they cover; changes without a mapped category are ignored
+	Categories map[string][]string `yaml:"categories"`
+}
+
+// Interval returns the feed poll interval with its default applied
+func (c ChangeCalendarConfig) Interval() time.Duration {
+	if c.PollInterval == 0 {
+		return 5 * time.Minute
+	}
+	return c.PollInterval
+}
+
+// Validate checks that there is a source and a category mapping
+func (c ChangeCalendarConfig) Validate() error {
+	if c.ICalURLEnv == "" && c.WebhookSecretEnv == "" {
+		return fmt.Errorf("ical_url_env or webhook_secret_env is required")
+	}
+	if c.PollInterval != 0 && c.PollInterval < 30*time.Second {
+		return fmt.Errorf("poll_interval must be at least 30s")
+	}
+	if len(c.Categories) == 0 {
+		return fmt.Errorf("categories must map at least one category to device tags")
+	}
+	for category, tags := range c.Categories {
+		if len(tags) == 0 {
+			return fmt.Errorf("category %s: no device tags", category)
+		}
+	}
+	return nil
+}
+
+// ChangeEvent is a change from the change calendar
+type ChangeEvent struct {
+	UID        string    `json:"uid"`
+	Summary    string    `json:"summary,omitempty"`
+	Categories []string  `json:"categories"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Cancelled  bool      `json:"cancelled,omitempty"`
+}
+
+// Validate checks that the change can be identified and has a time span
+func (ev ChangeEvent) Validate() error {
+	if ev.UID == "" {
+		return fmt.Errorf("uid is required")
+	}
+	if ev.Cancelled {
+		return nil
+	}
+	if ev.Start.IsZero() || !ev.End.After(ev.Start) {
+		return fmt.Errorf("change %s: end must be after start", ev.UID)
+	}
+	return nil
+}
+
+// ChangeWindow returns the maintenance window of a change, covering the
+// devices tagged with a tag its categories map to. It reports false when
+// the change covers no device.
+func (c *Config) ChangeWindow(ev ChangeEvent) (MaintenanceWindow, bool) {
+	calendar := c.Maintenance.ChangeCalendar
+	if calendar == nil || ev.Cancelled {
+		return MaintenanceWindow{}, false
+	}
+	tags := make(map[string]bool)
+	for category, mapped := range calendar.Categories {
+		for _, evCategory := range ev.Categories {
+			if strings.EqualFold(strings.TrimSpace(evCategory), category) {
+				for _, tag := range mapped {
+					tags[tag] = true
+				}
+			}
+		}
+	}
+	var devices []string
+	for name, dev := range c.DesiredState.Devices {
+		for _, tag := range dev.Tags {
+			if tags[tag] {
+				devices = append(devices, name)
+				break
+			}
+		}
+	}
+	if len(devices) == 0 {
+		return MaintenanceWindow{}, false
+	}
+	sort.Strings(devices)
+
+	name := ev.UID
+	if ev.Summary != "" {
+		name += " " + ev.Summary
+	}
+	return MaintenanceWindow{
+		Name:    name,
+		Devices: devices,
+		Schedule: Schedule{
+			Type:  ScheduleOneTime,
+			Start: ev.Start.UTC().Format(time.RFC3339),
+			End:   ev.End.UTC().Format(time.RFC3339),
+			start: ev.Start,
+			end:   ev.End,
+		},
+		SuppressAlerts: true,
+	}, true
+}
+
+// ParseChangeEvents reads the events of an iCal document, such as a change
+// calendar feed. Times with a TZID are read in that zone and floating times
+// in local time; all-day events span whole days. Events marked
+// STATUS:CANCELLED are returned with Cancelled set, and recurring events
+// only as their first occurrence.
+func ParseChangeEvents(data []byte) ([]ChangeEvent, error) {
+	lines, err := unfoldICal(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var events []ChangeEvent
+	var ev ChangeEvent
+	var inEvent bool
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if value == "VEVENT" {
+				inEvent = true
+				ev = ChangeEvent{}
+			}
+		case "UID":
+			if inEvent {
+				ev.UID = value
+			}
+		case "SUMMARY":
+			if inEvent {
+				ev.Summary = unescapeICal(value)
+			}
+		case "CATEGORIES":
+			if inEvent {
+				for _, category := range strings.Split(value, ",") {
+					if category = strings.TrimSpace(unescapeICal(category)); category != "" {
+						ev.Categories = append(ev.Categories, category)
+					}
+				}
+			}
+		case "STATUS":
+			if inEvent {
+				ev.Cancelled = strings.EqualFold(value, "CANCELLED")
+			}
+		case "DTSTART", "DTEND":
+			if !inEvent {
+				continue
+			}
+			t, err := parseICalTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("event %s: %s: %w", ev.UID, strings.ToUpper(name), err)
+			}
+			if strings.EqualFold(name, "DTSTART") {
+				ev.Start = t
+			} else {
+				ev.End = t
+			}
+		case "END":
+			if value != "VEVENT" || !inEvent {
+				continue
+			}
+			inEvent = false
+			if ev.End.IsZero() && !ev.Start.IsZero() {
+				ev.End = ev.Start.AddDate(0, 0, 1)
+			}
+			events = append(events, ev)
+		}
+	}
+	return events, nil
+}
+
+// parseICalTime reads an iCal DATE or DATE-TIME value with its parameters
+func parseICalTime(params, value string) (time.Time, error) {
+	loc := time.Local
+	for _, param := range strings.Split(params, ";") {
+		if key, tz, ok := strings.Cut(param, "="); ok && strings.EqualFold(key, "TZID") {
+			l, err := time.LoadLocation(strings.Trim(tz, `"`))
+			if err != nil {
+				return time.Time{}, err
+			}
+			loc = l
+		}
+	}
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	case strings.Contains(value, "T"):
+		return time.ParseInLocation("20060102T150405", value, loc)
+	default:
+		return time.ParseInLocation("20060102", value, loc)
+	}
+}
+
+// unescapeICal undoes iCal text escaping
+func unescapeICal(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,56 @@
+package config
+
+import "fmt"
+
+// ComplianceAlertConfig enables the device_compliance_low rollup alert,
+// raised when a large share of a device's monitored interfaces deviate at
+// once. That usually means a failed line card, module or stack member,
+// which the rollup points at better than dozens of interface alerts.
+type ComplianceAlertConfig struct {
+	// DeviatingPercent is the share of monitored interfaces that must be
+	// deviating for the alert to fire, default 20
+	DeviatingPercent float64 `yaml:"deviating_percent,omitempty"`
+	// MinInterfaces is the fewest deviating interfaces that fire it, so two
+	// of a handful of ports do not, default 3
+	MinInterfaces int    `yaml:"min_interfaces,omitempty"`
+	Severity      string `yaml:"severity,omitempty"` // default critical
+}
+
+// Exceeded reports whether deviating of monitored interfaces is beyond the
+// configured share
+func (c ComplianceAlertConfig) Exceeded(deviating, monitored int) bool {
+	percent, minimum := c.DeviatingPercent, c.MinInterfaces
+	if percent == 0 {
+		percent = 20
+	}
+	if minimum == 0 {
+		minimum = 3
+	}
+	return monitored > 0 && deviating >= minimum && float64(deviating)*100 > percent*float64(monitored)
+}
+
+// SeverityOrDefault returns the device_compliance_low severity, critical
+// unless set
+func (c ComplianceAlertConfig) SeverityOrDefault() string {
+	return severityOr(c.Severity, "critical")
+}
+
+// Validate checks the threshold and minimum
+func (c ComplianceAlertConfig) Validate() error {
+	if c.DeviatingPercent < 0 || c.DeviatingPercent >= 100 {
+		return fmt.Errorf("deviating_percent must be between 0 and 100")
+	}
+	if c.MinInterfaces < 0 {
+		return fmt.Errorf("min_interfaces must be >= 0")
+	}
+	return nil
+}
+
+// ComplianceAlertFor returns the compliance rollup settings of a device:
+// its own, else the global default, else nil when it has no rollup alert
+func (c *Config) ComplianceAlertFor(deviceCfg DeviceConfig) *ComplianceAlertConfig {
+	if deviceCfg.ComplianceAlert != nil {
+		return deviceCfg.ComplianceAlert
+	}
+	return c.DesiredState.Global.ComplianceAlert
+}
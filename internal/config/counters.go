@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrorRateConfig alerts when input errors per million received packets,
+// over a rolling window, exceed a threshold. It catches dirty fiber and
+// failing optics that corrupt frames without taking the link down.
+type ErrorRateConfig struct {
+	ThresholdPPM float64       `yaml:"threshold_ppm"`
+	Window       time.Duration `yaml:"window,omitempty"`      // default 15m
+	MinPackets   uint64        `yaml:"min_packets,omitempty"` // packets the window needs before a rate is judged, default 10000
+}
+
+// Limits returns the window and minimum packet count with defaults applied
+func (c ErrorRateConfig) Limits() (window time.Duration, minPackets uint64) {
+	window, minPackets = c.Window, c.MinPackets
+	if window == 0 {
+		window = 15 * time.Minute
+	}
+	if minPackets == 0 {
+		minPackets = 10000
+	}
+	return window, minPackets
+}
+
+// Validate checks the threshold and window
+func (c ErrorRateConfig) Validate() error {
+	if c.ThresholdPPM <= 0 {
+		return fmt.Errorf("threshold_ppm must be > 0")
+	}
+	if c.Window < 0 || (c.Window > 0 && c.Window < time.Minute) {
+		return fmt.Errorf("window must be at least 1m")
+	}
+	return nil
+}
+
+// ErrorRateFor returns the error-rate settings of an interface: its own,
+// else the global default, else nil when error rates are not tracked
+func (c *Config) ErrorRateFor(ifCfg InterfaceConfig) *ErrorRateConfig {
+	if ifCfg.ErrorRate != nil {
+		return ifCfg.ErrorRate
+	}
+	return c.DesiredState.Global.ErrorRate
+}
+
+// TrafficBaselineConfig enables the experimental traffic anomaly detector.
+// Each declared interface learns an exponentially weighted mean and
+// standard deviation of its receive and transmit bit rates; a rate that
+// stays far outside that baseline, such as a dead uplink pinned at zero or
+// a saturation event, raises an info alert.
+type TrafficBaselineConfig struct {
+	Alpha     float64 `yaml:"alpha,omitempty"`     // EWMA weight of each new sample, default 0.05
+	Deviation float64 `yaml:"deviation,omitempty"` // standard deviations from the mean that count as anomalous, default 4
+	Warmup    int     `yaml:"warmup,omitempty"`    // samples learned before judging, default 120
+	Sustain   int     `yaml:"sustain,omitempty"`   // consecutive anomalous samples before alerting, default 3
+	MinBPS    float64 `yaml:"min_bps,omitempty"`   // baselines below this mean rate are not judged, default 1e6
+}
+
+// WithDefaults returns the configuration with unset fields defaulted
+func (c TrafficBaselineConfig) WithDefaults() TrafficBaselineConfig {
+	if c.Alpha == 0 {
+		c.Alpha = 0.05
+	}
+	if c.Deviation == 0 {
+		c.Deviation = 4
+	}
+	if c.Warmup == 0 {
+		c.Warmup = 120
+	}
+	if c.Sustain == 0 {
+		c.Sustain = 3
+	}
+	if c.MinBPS == 0 {
+		c.MinBPS = 1e6
+	}
+	return c
+}
+
+// Validate checks the detector's parameters
+func (c TrafficBaselineConfig) Validate() error {
+	if c.Alpha < 0 || c.Alpha >= 1 {
+		return fmt.Errorf("alpha must be between 0 and 1")
+	}
+	if c.Deviation < 0 || c.Warmup < 0 || c.Sustain < 0 || c.MinBPS < 0 {
+		return fmt.Errorf("deviation, warmup, sustain and min_bps must not be negative")
+	}
+	return nil
+}
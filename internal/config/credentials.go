@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Secret resolves the entry's username and password. The username comes
+// from username, else the username_env variable; the password from the
+// password_env variable, else password_file (a Docker or Kubernetes secret
+// mount), with trailing newlines removed. A source that is declared but
+// empty is an error rather than a reason to fall back, and sources left
+// undeclared resolve to "".
+func (e CredentialEntry) Secret() (username, password string, err error) {
+	username = e.Username
+	if username == "" && e.UsernameEnv != "" {
+		if username = os.Getenv(e.UsernameEnv); username == "" {
+			return "", "", fmt.Errorf("username_env %s is not set", e.UsernameEnv)
+		}
+	}
+	switch {
+	case e.PasswordEnv != "":
+		if password = os.Getenv(e.PasswordEnv); password == "" {
+			return "", "", fmt.Errorf("password_env %s is not set", e.PasswordEnv)
+		}
+	case e.PasswordFile != "":
+		data, err := os.ReadFile(e.PasswordFile)
+		if err != nil {
+			return "", "", fmt.Errorf("password_file: %w", err)
+		}
+		if password = strings.TrimRight(string(data), "\r\n"); password == "" {
+			return "", "", fmt.Errorf("password_file %s is empty", e.PasswordFile)
+		}
+	}
+	return username, password, nil
+}
+
+// SetPassword replaces the entry's password at its source, following the
+// precedence of Secret: the password_env variable of this process, else the
+// password_file. It reports whether the change outlives a restart, which
+// only a password_file does; an environment variable must also be updated
+// wherever NetSpec is deployed.
+func (e CredentialEntry) SetPassword(password string) (persisted bool, err error) {
+	if password == "" {
+		return false, fmt.Errorf("password must not be empty")
+	}
+	switch {
+	case e.PasswordEnv != "":
+		return false, os.Setenv(e.PasswordEnv, password)
+	case e.PasswordFile != "":
+		if err := writeFileAtomic(e.PasswordFile, []byte(password+"\n")); err != nil {
+			return false, fmt.Errorf("password_file: %w", err)
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("credential entry has no password_env or password_file to update")
+}
+
+// CredentialDevices returns the devices whose credentials resolve to the
+// named entry, through credentials_ref or default_credentials, sorted
+func (c *Config) CredentialDevices(name string) []string {
+	var devices []string
+	for deviceName, device := range c.DesiredState.Devices {
+		ref := device.CredentialsRef
+		if ref == "" {
+			ref = c.DesiredState.Global.DefaultCredentials
+		}
+		if ref == name {
+			devices = append(devices, deviceName)
+		}
+	}
+	sort.Strings(devices)
+	return devices
+}
+
+// DeviceCredentials resolves the gNMI username and password of a device
+// from its credential entry (credentials_ref, else default_credentials).
+// Fields the entry does not declare, or every field when no entry applies,
+// come from the fallback pair taken from GNMI_USERNAME and GNMI_PASSWORD.
+// It fails when a declared source is unset or no password results.
+func (c *Config) DeviceCredentials(deviceName, fallbackUsername, fallbackPassword string) (username, password string, err error) {
+	entry := c.ResolveCredentials(deviceName)
+	username, password, err = entry.Secret()
+	if err != nil {
+		return "", "", err
+	}
+	if username == "" {
+		username = fallbackUsername
+	}
+	if password == "" {
+		password = fallbackPassword
+	}
+	if password == "" {
+		if entry == (CredentialEntry{}) {
+			return "", "", fmt.Errorf("no credentials entry and GNMI_PASSWORD is not set")
+		}
+		return "", "", fmt.Errorf("credentials entry has no password source and GNMI_PASSWORD is not set")
+	}
+	return username, password, nil
+}
+
+// ValidateCredentials checks that every device resolves to a username and
+// password, naming each device that does not and why
+func (c *Config) ValidateCredentials(fallbackUsername, fallbackPassword string) error {
+	var problems []string
+	for name := range c.DesiredState.Devices {
+		if _, _, err := c.DeviceCredentials(name, fallbackUsername, fallbackPassword); err != nil {
+			problems = append(problems, fmt.Sprintf("%s (%v)", name, err))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("missing gNMI credentials for %d device(s): %s", len(problems), strings.Join(problems, "; "))
+}
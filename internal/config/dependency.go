@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DependencyConfig declares the upstream devices a device reaches the
+// network through, such as an access switch's distribution pair. While its
+// upstreams are down the device's alerts are marked dependency affected.
+type DependencyConfig struct {
+	Upstream []string `yaml:"upstream"`
+	// Mode is "any" (default): affected when any upstream is down, or
+	// "all": only when every upstream is, for a redundant pair
+	Mode     string `yaml:"mode,omitempty"`
+	Suppress bool   `yaml:"suppress,omitempty"` // do not notify alerts raised while affected
+	// Via names, per upstream, the interface of the upstream the device
+	// hangs off, so alerts on that link carry an impact estimate
+	Via map[string]string `yaml:"via,omitempty"`
+}
+
+// AffectedBy returns the upstream devices that are down when they leave the
+// device dependency affected, or nil when they do not
+func (d *DependencyConfig) AffectedBy(down func(device string) bool) []string {
+	if d == nil {
+		return nil
+	}
+	var affected []string
+	for _, upstream := range d.Upstream {
+		if down(upstream) {
+			affected = append(affected, upstream)
+		}
+	}
+	if len(affected) == 0 || (d.Mode == "all" && len(affected) < len(d.Upstream)) {
+		return nil
+	}
+	return affected
+}
+
+// Downstream returns the devices that declare deviceName as an upstream,
+// sorted by name
+func (c *DesiredStateConfig) Downstream(deviceName string) []string {
+	var downstream []string
+	for name, device := range c.Devices {
+		if device.DependsOn == nil {
+			continue
+		}
+		for _, upstream := range device.DependsOn.Upstream {
+			if upstream == deviceName {
+				downstream = append(downstream, name)
+				break
+			}
+		}
+	}
+	sort.Strings(downstream)
+	return downstream
+}
+
+// Behind returns the devices reached through an interface: those whose
+// depends_on.via names it, and every device depending on them in turn,
+// sorted by name
+func (c *DesiredStateConfig) Behind(deviceName, ifaceName string) []string {
+	seen := make(map[string]bool)
+	var queue []string
+	for name, device := range c.Devices {
+		if device.DependsOn != nil && device.DependsOn.Via[deviceName] == ifaceName {
+			seen[name] = true
+			queue = append(queue, name)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, downstream := range c.Downstream(current) {
+			if !seen[downstream] && downstream != deviceName {
+				seen[downstream] = true
+				queue = append(queue, downstream)
+			}
+		}
+	}
+
+	behind := make([]string, 0, len(seen))
+	for name := range seen {
+		behind = append(behind, name)
+	}
+	sort.Strings(behind)
+	return behind
+}
+
+// validateDependencies checks that every upstream is a declared device and
+// that no device depends on itself, directly or through its upstreams
+func validateDependencies(devices map[string]DeviceConfig) error {
+	for name, device := range devices {
+		deps := device.DependsOn
+		if deps == nil {
+			continue
+		}
+		if len(deps.Upstream) == 0 {
+			return fmt.Errorf("device %s: depends_on requires at least one upstream device", name)
+		}
+		if deps.Mode != "" && deps.Mode != "any" && deps.Mode != "all" {
+			return fmt.Errorf("device %s: depends_on.mode must be 'any' or 'all'", name)
+		}
+		for _, upstream := range deps.Upstream {
+			if _, ok := devices[upstream]; !ok {
+				return fmt.Errorf("device %s: depends_on references unknown device %s", name, upstream)
+			}
+		}
+		for upstream, iface := range deps.Via {
+			if !containsString(deps.Upstream, upstream) {
+				return fmt.Errorf("device %s: depends_on.via names %s, which is not an upstream", name, upstream)
+			}
+			if _, ok := devices[upstream].Interfaces[iface]; !ok {
+				return fmt.Errorf("device %s: depends_on.via references undeclared interface %s on %s", name, iface, upstream)
+			}
+		}
+	}
+
+	// Walk the upstreams of each device looking for a way back to it
+	for name := range devices {
+		seen := map[string]bool{}
+		queue := []string{name}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			deps := devices[current].DependsOn
+			if deps == nil {
+				continue
+			}
+			for _, upstream := range deps.Upstream {
+				if upstream == name {
+					return fmt.Errorf("device %s: depends_on forms a cycle through %s", name, current)
+				}
+				if !seen[upstream] {
+					seen[upstream] = true
+					queue = append(queue, upstream)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateInterfaceState rewrites desired_state (and admin_state when non-empty)
+// for one interface in a desired-state.yaml file. The file is edited through
+// the YAML node tree so comments and key order are preserved (blank lines
+// are not), and replaced atomically so a concurrent reload never sees a
+// partial write.
+func UpdateInterfaceState(path, deviceName, ifaceName, desiredState, adminState string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parsing %s: %w", filepath.Base(path), err)
+	}
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("%s is empty", filepath.Base(path))
+	}
+
+	iface := mappingValue(doc.Content[0], "devices", deviceName, "interfaces", ifaceName)
+	if iface == nil || iface.Kind != yaml.MappingNode {
+		return fmt.Errorf("device %s, interface %s: not declared in %s", deviceName, ifaceName, filepath.Base(path))
+	}
+
+	if desiredState != "" {
+		setMappingScalar(iface, "desired_state", desiredState)
+	}
+	if adminState != "" {
+		setMappingScalar(iface, "admin_state", adminState)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("encoding %s: %w", filepath.Base(path), err)
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// mappingValue walks nested mapping nodes by key and returns the value node
+// at the end of the path, or nil if any key is missing
+func mappingValue(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		node = next
+	}
+	return node
+}
+
+// setMappingScalar sets key to a scalar value, appending the key if absent
+func setMappingScalar(node *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1].Kind = yaml.ScalarNode
+			node.Content[i+1].Tag = "!!str"
+			node.Content[i+1].Value = value
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames
+// it into place, keeping the original file mode
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// EnforcementConfig enables closed-loop mode: interfaces with enforce: true
+// whose admin state deviates from admin_state are restored with a gNMI Set
+// rather than only alerted on
+type EnforcementConfig struct {
+	Enabled bool `yaml:"enabled"`
+	DryRun  bool `yaml:"dry_run,omitempty"` // log the Set that would be issued without issuing it
+	// Cooldown is the minimum time between Sets on the same interface, so a
+	// device fighting back is not written to in a loop; default 5m
+	Cooldown time.Duration `yaml:"cooldown,omitempty"`
+}
+
+// CooldownOrDefault returns the cooldown with its default applied
+func (c EnforcementConfig) CooldownOrDefault() time.Duration {
+	if c.Cooldown == 0 {
+		return 5 * time.Minute
+	}
+	return c.Cooldown
+}
+
+// Validate checks the cooldown
+func (c EnforcementConfig) Validate() error {
+	if c.Cooldown < 0 {
+		return fmt.Errorf("cooldown must not be negative")
+	}
+	return nil
+}
+
+// Enforced reports whether an interface's admin state is restored when it
+// deviates: enforcement is enabled and the interface has enforce: true
+func (c *DesiredStateConfig) Enforced(device, iface string) bool {
+	if c.Global.Enforcement == nil || !c.Global.Enforcement.Enabled {
+		return false
+	}
+	ifCfg, ok := c.Devices[device].Interfaces[iface]
+	return ok && ifCfg.Enforce && ifCfg.AdminState != ""
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// EnrichmentConfig configures alert enrichment: when an alert fires, its
+// device, entity, type and severity are POSTed to an HTTP endpoint, such as
+// a CMDB or IPAM lookup, and the string values of the JSON object it
+// returns are merged into the alert's related state before notification
+type EnrichmentConfig struct {
+	URLEnv    string `yaml:"url_env"`              // environment variable holding the endpoint URL
+	SecretEnv string `yaml:"secret_env,omitempty"` // bearer token sent in Authorization
+	// AlertTypes limits enrichment to these alert types; empty means all
+	AlertTypes []string      `yaml:"alert_types,omitempty"`
+	Timeout    time.Duration `yaml:"timeout,omitempty"`   // per lookup, default 2s
+	CacheTTL   time.Duration `yaml:"cache_ttl,omitempty"` // per device and entity, default 5m
+}
+
+// Enabled reports whether an endpoint is configured
+func (c EnrichmentConfig) Enabled() bool {
+	return c.URLEnv != ""
+}
+
+// Applies reports whether alerts of the type are enriched
+func (c EnrichmentConfig) Applies(alertType string) bool {
+	return c.Enabled() && (len(c.AlertTypes) == 0 || containsString(c.AlertTypes, alertType))
+}
+
+// Limits returns the timeout and cache TTL with defaults applied
+func (c EnrichmentConfig) Limits() (timeout, cacheTTL time.Duration) {
+	timeout, cacheTTL = c.Timeout, c.CacheTTL
+	if timeout == 0 {
+		timeout = 2 * time.Second
+	}
+	if cacheTTL == 0 {
+		cacheTTL = 5 * time.Minute
+	}
+	return timeout, cacheTTL
+}
+
+// Validate checks the timeout and cache TTL
+func (c EnrichmentConfig) Validate() error {
+	if c.Timeout < 0 || c.Timeout > 30*time.Second {
+		return fmt.Errorf("timeout must be between 0 and 30s")
+	}
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("cache_ttl must not be negative")
+	}
+	if !c.Enabled() && (c.SecretEnv != "" || len(c.AlertTypes) > 0) {
+		return fmt.Errorf("url_env is required")
+	}
+	return nil
+}
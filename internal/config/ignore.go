@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// IgnoreRules exclude interfaces from discovery (adopt suggestions) and
+// from evaluation, e.g. SVIs, loopbacks or NAC-managed access ports
+type IgnoreRules struct {
+	Names        []string `yaml:"names,omitempty"`        // glob patterns, case-insensitive, e.g. Vlan*, Loopback*
+	Types        []string `yaml:"types,omitempty"`        // IANA interface types or an alias: ethernet, lag, vlan, loopback, tunnel
+	Descriptions []string `yaml:"descriptions,omitempty"` // regular expressions matched against the device's description
+}
+
+// interfaceTypeAliases maps friendly type names onto IANA interface types
+var interfaceTypeAliases = map[string]string{
+	"ethernet": "ethernetcsmacd",
+	"lag":      "ieee8023adlag",
+	"vlan":     "l3ipvlan",
+	"svi":      "l3ipvlan",
+	"loopback": "softwareloopback",
+	"tunnel":   "tunnel",
+}
+
+// patternCache holds compiled ignore patterns, which are matched for every
+// notification during evaluation
+var patternCache sync.Map // string -> *regexp.Regexp
+
+func cachedPattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(expr, re)
+	return re, nil
+}
+
+// globPattern converts a name glob into an anchored, case-insensitive
+// regular expression. Unlike path.Match, * also matches "/".
+func globPattern(glob string) string {
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// MatchGlob reports whether a name matches a glob the way ignore names
+// do: case-insensitive, with * also matching "/"
+func MatchGlob(glob, name string) bool {
+	re, err := cachedPattern(globPattern(glob))
+	return err == nil && re.MatchString(name)
+}
+
+// Validate checks the description expressions compile
+func (r IgnoreRules) Validate() error {
+	for _, expr := range r.Descriptions {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("description pattern %q: %w", expr, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether an interface is ignored. Fields that are not
+// known, such as the type during evaluation, never match.
+func (r IgnoreRules) Matches(iface ObservedInterface) bool {
+	for _, glob := range r.Names {
+		if re, err := cachedPattern(globPattern(glob)); err == nil && re.MatchString(iface.Name) {
+			return true
+		}
+	}
+	if iface.Type != "" {
+		ifType := strings.ToLower(iface.Type)
+		if idx := strings.LastIndex(ifType, ":"); idx != -1 {
+			ifType = ifType[idx+1:]
+		}
+		for _, t := range r.Types {
+			want := strings.ToLower(t)
+			if alias, ok := interfaceTypeAliases[want]; ok {
+				want = alias
+			}
+			if want == ifType {
+				return true
+			}
+		}
+	}
+	if iface.Description != "" {
+		for _, expr := range r.Descriptions {
+			if re, err := cachedPattern(expr); err == nil && re.MatchString(iface.Description) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IgnoresInterface reports whether the global or the device's ignore rules
+// exclude an interface of the device
+func (c *Config) IgnoresInterface(deviceName string, iface ObservedInterface) bool {
+	if c.DesiredState.Global.IgnoreInterfaces.Matches(iface) {
+		return true
+	}
+	return c.DesiredState.Devices[deviceName].IgnoreInterfaces.Matches(iface)
+}
+
+// FilterIgnored drops the interfaces the ignore rules exclude from a
+// discovery result
+func (c *Config) FilterIgnored(deviceName string, observed []ObservedInterface) []ObservedInterface {
+	kept := make([]ObservedInterface, 0, len(observed))
+	for _, iface := range observed {
+		if !c.IgnoresInterface(deviceName, iface) {
+			kept = append(kept, iface)
+		}
+	}
+	return kept
+}
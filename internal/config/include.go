@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration files can be split up. A file such as desired-state.yaml
+// may list glob patterns, relative to the config directory, under a
+// top-level include key, and every *.yaml file in its overlay directory
+// (desired-state.d/) is read after those. Mappings are merged key by key,
+// so devices can live in one file per site; any other value defined in two
+// files, including a list, is reported as a conflict naming both files
+// rather than one silently winning.
+
+// layerFiles returns the files merged into name.yaml after the file itself:
+// each include pattern's matches in lexical order, then the overlay
+// directory's files in lexical order. A pattern without wildcards must
+// match a file.
+func layerFiles(dir, name string, includes []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{filepath.Join(dir, name+".yaml"): true}
+	add := func(matches []string) {
+		sort.Strings(matches)
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	for _, pattern := range includes {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("include %q: file not found", pattern)
+		}
+		add(matches)
+	}
+	var overlay []string
+	for _, ext := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, name+".d", ext))
+		overlay = append(overlay, matches...)
+	}
+	add(overlay)
+	return files, nil
+}
+
+// readMapping parses a YAML file into its top-level mapping node, an empty
+// mapping for an empty file
+func readMapping(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level must be a mapping")
+	}
+	return root, nil
+}
+
+// takeIncludes removes the include key from a mapping and returns its
+// patterns
+func takeIncludes(root *yaml.Node) ([]string, error) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "include" {
+			continue
+		}
+		var patterns []string
+		if err := root.Content[i+1].Decode(&patterns); err != nil {
+			return nil, fmt.Errorf("include must be a list of file patterns")
+		}
+		root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		return patterns, nil
+	}
+	return nil, nil
+}
+
+// loadLayered loads name.yaml merged with its includes and overlay
+// directory into out. A missing name.yaml is an error when required;
+// otherwise its overlay directory alone may provide the configuration. It
+// returns the schema warnings of the merged file.
+func loadLayered(dir, name string, out interface{}, required bool) ([]string, error) {
+	mainPath := filepath.Join(dir, name+".yaml")
+	root, err := readMapping(mainPath)
+	switch {
+	case os.IsNotExist(err) && !required:
+		root = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	case err != nil:
+		return nil, err
+	}
+	includes, err := takeIncludes(root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := layerFiles(dir, name, includes)
+	if err != nil {
+		return nil, err
+	}
+
+	origins := make(map[string]string)
+	for _, file := range files {
+		rel, _ := filepath.Rel(dir, file)
+		layer, err := readMapping(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rel, err)
+		}
+		if nested, _ := takeIncludes(layer); nested != nil {
+			return nil, fmt.Errorf("%s: include is only allowed in %s.yaml", rel, name)
+		}
+		if err := mergeMapping(root, layer, nil, rel, name+".yaml", origins); err != nil {
+			return nil, err
+		}
+	}
+	warnings, err := validateSchema(name, root, origins)
+	if err != nil {
+		return nil, err
+	}
+	return warnings, root.Decode(out)
+}
+
+// mergeMapping merges the keys of src, read from file, into dst. origins
+// records which file each merged key path came from; paths not recorded
+// come from the main file.
+func mergeMapping(dst, src *yaml.Node, path []string, file, mainFile string, origins map[string]string) error {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		keyPath := append(append([]string(nil), path...), key.Value)
+		var existing *yaml.Node
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == key.Value {
+				existing = dst.Content[j+1]
+				break
+			}
+		}
+		switch {
+		case existing == nil:
+			dst.Content = append(dst.Content, key, value)
+			origins[strings.Join(keyPath, "\x00")] = file
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			if err := mergeMapping(existing, value, keyPath, file, mainFile, origins); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: defined in both %s and %s", strings.Join(keyPath, "."), originOf(keyPath, mainFile, origins), file)
+		}
+	}
+	return nil
+}
+
+// originOf returns the file a key path was merged from
+func originOf(path []string, mainFile string, origins map[string]string) string {
+	for n := len(path); n > 0; n-- {
+		if file, ok := origins[strings.Join(path[:n], "\x00")]; ok {
+			return file
+		}
+	}
+	return mainFile
+}
+
+// DeclaringFile returns the file of a layered desired-state.yaml in the
+// config directory to edit keys of a device's interface in, so edits land
+// where the interface is defined. When the interface's entry is split
+// across files, it is the one file already holding any of keys, or the
+// first declaring file when none does; keys held by different files, or an
+// interface declared only by a range key, cannot be edited and are an
+// error.
+func DeclaringFile(dir, deviceName, ifaceName string, keys ...string) (string, error) {
+	var declaring []string
+	holding := make(map[string]bool) // files holding any of keys
+	var holders []string
+	check := func(root *yaml.Node, file string) error {
+		iface := mappingValue(root, "devices", deviceName, "interfaces", ifaceName)
+		if iface == nil {
+			if key := rangeKeyCovering(mappingValue(root, "devices", deviceName, "interfaces"), ifaceName); key != "" {
+				return fmt.Errorf("device %s, interface %s: declared by range %s in %s; give it an entry of its own to edit it", deviceName, ifaceName, key, filepath.Base(file))
+			}
+			return nil
+		}
+		declaring = append(declaring, file)
+		for _, key := range keys {
+			if mappingValue(iface, key) != nil && !holding[file] {
+				holding[file] = true
+				rel, _ := filepath.Rel(dir, file)
+				holders = append(holders, rel)
+			}
+		}
+		return nil
+	}
+
+	mainPath := filepath.Join(dir, "desired-state.yaml")
+	root, err := readMapping(mainPath)
+	if err != nil {
+		return "", fmt.Errorf("desired-state.yaml: %w", err)
+	}
+	includes, err := takeIncludes(root)
+	if err != nil {
+		return "", fmt.Errorf("desired-state.yaml: %w", err)
+	}
+	if err := check(root, mainPath); err != nil {
+		return "", err
+	}
+	files, err := layerFiles(dir, "desired-state", includes)
+	if err != nil {
+		return "", fmt.Errorf("desired-state.yaml: %w", err)
+	}
+	for _, file := range files {
+		layer, err := readMapping(file)
+		if err != nil {
+			continue
+		}
+		if err := check(layer, file); err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case len(declaring) == 0:
+		return "", fmt.Errorf("device %s, interface %s: not declared in desired-state.yaml or its includes", deviceName, ifaceName)
+	case len(holders) > 1:
+		return "", fmt.Errorf("device %s, interface %s: %s split across %s; edit them by hand", deviceName, ifaceName, strings.Join(keys, " and "), strings.Join(holders, " and "))
+	}
+	for _, file := range declaring {
+		if holding[file] {
+			return file, nil
+		}
+	}
+	return declaring[0], nil
+}
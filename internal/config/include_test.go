@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDeclaringFile(t *testing.T) {
+	main := `
+devices:
+  sw1:
+    interfaces:
+      Ethernet1:
+        description: uplink
+      Ethernet2:
+        desired_state: up
+      Ethernet3:
+        description: server
+        desired_state: up
+      Ethernet[10-12]:
+        desired_state: up
+`
+	overlay := `
+devices:
+  sw1:
+    interfaces:
+      Ethernet1:
+        desired_state: up
+      Ethernet3:
+        admin_state: enabled
+      Ethernet4:
+        description: spare
+`
+	dir := writeConfigFiles(t, map[string]string{
+		"desired-state.yaml":            main,
+		"desired-state.d/building.yaml": overlay,
+	})
+	overlayPath := filepath.Join(dir, "desired-state.d", "building.yaml")
+	mainPath := filepath.Join(dir, "desired-state.yaml")
+
+	tests := []struct {
+		iface   string
+		want    string
+		wantErr string
+	}{
+		// The keys live in the overlay, though the entry starts in the main file
+		{iface: "Ethernet1", want: overlayPath},
+		{iface: "Ethernet2", want: mainPath},
+		{iface: "Ethernet3", wantErr: "split across desired-state.yaml and desired-state.d/building.yaml"},
+		{iface: "Ethernet4", want: overlayPath},
+		{iface: "Ethernet11", wantErr: "declared by range"},
+		{iface: "Ethernet9", wantErr: "not declared"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.iface, func(t *testing.T) {
+			got, err := DeclaringFile(dir, "sw1", tt.iface, "desired_state", "admin_state")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %q, %v, want an error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		layers  []string // merged in order into the first, the main file
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "devices per file",
+			layers: []string{"devices:\n  sw1: {site: a}\n", "devices:\n  sw2: {site: b}\n"},
+			want:   "devices:\n  sw1: {site: a}\n  sw2: {site: b}\n",
+		},
+		{
+			name:   "keys of one device across files",
+			layers: []string{"devices:\n  sw1:\n    site: a\n", "devices:\n  sw1:\n    role: access\n"},
+			want:   "devices:\n  sw1:\n    site: a\n    role: access\n",
+		},
+		{
+			name:    "scalar in two files",
+			layers:  []string{"devices:\n  sw1:\n    site: a\n", "devices:\n  sw1:\n    site: b\n"},
+			wantErr: "devices.sw1.site: defined in both desired-state.yaml and layer1.yaml",
+		},
+		{
+			name:    "list in two files",
+			layers:  []string{"global:\n  tags: [a]\n", "global:\n  tags: [b]\n"},
+			wantErr: "global.tags: defined in both desired-state.yaml and layer1.yaml",
+		},
+		{
+			name:    "conflict between two layers names both",
+			layers:  []string{"", "devices:\n  sw2:\n    site: a\n", "devices:\n  sw2:\n    site: b\n"},
+			wantErr: "devices.sw2.site: defined in both layer1.yaml and layer2.yaml",
+		},
+		{
+			name:    "mapping against scalar",
+			layers:  []string{"devices:\n  sw1: none\n", "devices:\n  sw1:\n    site: a\n"},
+			wantErr: "devices.sw1: defined in both desired-state.yaml and layer1.yaml",
+		},
+	}
+	parse := func(t *testing.T, src string) *yaml.Node {
+		t.Helper()
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Content) == 0 {
+			return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		return doc.Content[0]
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parse(t, tt.layers[0])
+			origins := make(map[string]string)
+			var err error
+			for i, layer := range tt.layers[1:] {
+				file := fmt.Sprintf("layer%d.yaml", i+1)
+				if err = mergeMapping(root, parse(t, layer), nil, file, "desired-state.yaml", origins); err != nil {
+					break
+				}
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			if err := root.Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
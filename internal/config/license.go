@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// LicenseConfig enables checks of the licenses and feature terms a device
+// reports through openconfig-license, alerting ahead of their expiry
+type LicenseConfig struct {
+	WarnDays int           `yaml:"warn_days,omitempty"` // days before expiry to warn, default 30
+	Interval time.Duration `yaml:"interval,omitempty"`  // how often licenses are read, default 6h
+}
+
+// Limits returns the warning lead time and read interval with defaults
+// applied
+func (c LicenseConfig) Limits() (warn, interval time.Duration) {
+	days, interval := c.WarnDays, c.Interval
+	if days == 0 {
+		days = 30
+	}
+	if interval == 0 {
+		interval = 6 * time.Hour
+	}
+	return time.Duration(days) * 24 * time.Hour, interval
+}
+
+// Validate checks the lead time and interval
+func (c LicenseConfig) Validate() error {
+	if c.WarnDays < 0 {
+		return fmt.Errorf("warn_days must be >= 0")
+	}
+	if c.Interval < 0 || (c.Interval > 0 && c.Interval < time.Minute) {
+		return fmt.Errorf("interval must be at least 1m")
+	}
+	return nil
+}
+
+// LicensesFor returns the license check settings of a device: its own,
+// else the global default, else nil when licenses are not checked
+func (c *Config) LicensesFor(deviceCfg DeviceConfig) *LicenseConfig {
+	if deviceCfg.Licenses != nil {
+		return deviceCfg.Licenses
+	}
+	return c.DesiredState.Global.Licenses
+}
+
+// License is a license or feature term a device reports
+type License struct {
+	ID          string
+	Description string
+	ExpiresAt   time.Time // zero for perpetual licenses
+	Expired     bool      // the device reports the license as expired
+	InUse       bool
+	// InUseKnown is set when the device reports whether the license is in
+	// use; licenses reported as unused are not alerted on
+	InUseKnown bool
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// validateConflicts rejects configuration that contradicts itself: two
+// devices at the same address and gNMI port, which would be polled and
+// alerted on twice,
+// and an interface required as a member of more than one port-channel on a
+// device, whose member policies could never all be met
+func validateConflicts(cfg *Config) error {
+	names := make([]string, 0, len(cfg.DesiredState.Devices))
+	for name := range cfg.DesiredState.Devices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	byAddress := make(map[string]string)
+	for _, name := range names {
+		device := cfg.DesiredState.Devices[name]
+		addr := strings.ToLower(strings.TrimSpace(device.Address))
+		target := net.JoinHostPort(addr, strconv.Itoa(cfg.GNMIPortFor(device)))
+		if other, ok := byAddress[target]; ok {
+			return fmt.Errorf("devices %s and %s both have address %s", other, name, target)
+		}
+		byAddress[target] = name
+	}
+
+	for _, name := range names {
+		device := cfg.DesiredState.Devices[name]
+		channels := make([]string, 0, len(device.Interfaces))
+		for ifName := range device.Interfaces {
+			channels = append(channels, ifName)
+		}
+		sort.Strings(channels)
+		memberOf := make(map[string]string)
+		for _, ifName := range channels {
+			ifCfg := device.Interfaces[ifName]
+			if ifCfg.Members == nil {
+				continue
+			}
+			for _, member := range ifCfg.Members.Required {
+				if other, ok := memberOf[member]; ok && other != ifName {
+					return fmt.Errorf("device %s, interface %s: required by both %s (%s) and %s (%s)",
+						name, member, other, memberPolicyMode(device.Interfaces[other]), ifName, memberPolicyMode(ifCfg))
+				}
+				memberOf[member] = ifName
+			}
+		}
+	}
+	return nil
+}
+
+// memberPolicyMode names a port-channel's member policy for messages
+func memberPolicyMode(ifCfg InterfaceConfig) string {
+	if ifCfg.MemberPolicy == nil || ifCfg.MemberPolicy.Mode == "" {
+		return "no member_policy"
+	}
+	return ifCfg.MemberPolicy.Mode
+}
+
+// Warnings returns problems that do not stop the configuration loading but
+// are probably mistakes: a required port-channel member declared down,
+// credentials and member groups no device uses, channels no alert rule
+// routes to, and keys the schema does not know. They are logged at startup
+// and reload and returned by the reload API.
+func (c *Config) Warnings() []string {
+	warnings := append([]string{}, c.schemaWarnings...)
+
+	usedCredentials := make(map[string]bool)
+	if ref := c.DesiredState.Global.DefaultCredentials; ref != "" {
+		usedCredentials[ref] = true
+	}
+	for name, device := range c.DesiredState.Devices {
+		if device.CredentialsRef != "" {
+			usedCredentials[device.CredentialsRef] = true
+		}
+		for ifName, ifCfg := range device.Interfaces {
+			if ifCfg.Members == nil || ifCfg.DesiredState != "up" {
+				continue
+			}
+			for _, member := range ifCfg.Members.Required {
+				if memberCfg, ok := device.Interfaces[member]; ok && memberCfg.DesiredState == "down" && len(memberCfg.Schedule) == 0 {
+					warnings = append(warnings, fmt.Sprintf("device %s, interface %s: required member of %s but desired_state is down", name, member, ifName))
+				}
+			}
+		}
+	}
+	for name := range c.Credentials.Credentials {
+		if !usedCredentials[name] {
+			warnings = append(warnings, fmt.Sprintf("credentials %s: not referenced by any device or default_credentials", name))
+		}
+	}
+
+	usedGroups := make(map[string]bool)
+	for _, device := range c.DesiredState.Devices {
+		for _, ifCfg := range device.Interfaces {
+			if ifCfg.Members != nil {
+				for _, group := range ifCfg.Members.Groups {
+					usedGroups[group] = true
+				}
+			}
+		}
+	}
+	for name := range c.DesiredState.Global.MemberGroups {
+		if !usedGroups[name] {
+			warnings = append(warnings, fmt.Sprintf("member group %s: not referenced by any port-channel", name))
+		}
+	}
+
+	usedChannels := make(map[string]bool)
+	for _, rule := range c.Alerts.AlertRules {
+		for _, ch := range rule.Channels {
+			usedChannels[ch] = true
+		}
+	}
+	for _, entry := range c.Alerts.SeverityMatrix {
+		for _, ch := range entry.Channels {
+			usedChannels[ch] = true
+		}
+	}
+	for name := range c.Alerts.Channels {
+		if !usedChannels[name] {
+			warnings = append(warnings, fmt.Sprintf("channel %s: not used by any alert rule or severity_matrix entry", name))
+		}
+	}
+
+	if inbound := c.Alerts.InboundWebhook; inbound.AllowUnsigned && inbound.SecretEnv == "" {
+		warnings = append(warnings, "inbound_webhook: allow_unsigned is set, anyone who can reach /api/webhooks/inbound can acknowledge or resolve alerts")
+	}
+
+	sort.Strings(warnings)
+	return warnings
+}
@@ -2,11 +2,11 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"net/url"
 	"path/filepath"
+	"regexp"
+	"text/template"
 	"time"
-
-	"gopkg.in/yaml.v3"
 )
 
 // LoadConfig loads configuration from a single file (legacy method)
@@ -18,33 +18,36 @@ func LoadConfig(path string) (*Config, error) {
 func LoadConfigDir(dir string) (*Config, error) {
 	cfg := &Config{}
 
-	// Load desired-state.yaml
-	if err := loadYAML(filepath.Join(dir, "desired-state.yaml"), &cfg.DesiredState); err != nil {
-		return nil, fmt.Errorf("loading desired-state.yaml: %w", err)
-	}
-
-	// Load alerts.yaml (optional)
-	alertsPath := filepath.Join(dir, "alerts.yaml")
-	if _, err := os.Stat(alertsPath); err == nil {
-		if err := loadYAML(alertsPath, &cfg.Alerts); err != nil {
-			return nil, fmt.Errorf("loading alerts.yaml: %w", err)
+	// Each file may be split with include patterns and a <name>.d overlay
+	// directory (see include.go). Only desired-state.yaml is required.
+	for _, file := range []struct {
+		name     string
+		out      interface{}
+		required bool
+	}{
+		{"desired-state", &cfg.DesiredState, true},
+		{"alerts", &cfg.Alerts, false},
+		{"credentials", &cfg.Credentials, false},
+		{"maintenance", &cfg.Maintenance, false},
+		{"calendars", &cfg.Calendars, false},
+	} {
+		warnings, err := loadLayered(dir, file.name, file.out, file.required)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s.yaml: %w", file.name, err)
 		}
+		cfg.schemaWarnings = append(cfg.schemaWarnings, warnings...)
 	}
-
-	// Load credentials.yaml (optional)
-	credentialsPath := filepath.Join(dir, "credentials.yaml")
-	if _, err := os.Stat(credentialsPath); err == nil {
-		if err := loadYAML(credentialsPath, &cfg.Credentials); err != nil {
-			return nil, fmt.Errorf("loading credentials.yaml: %w", err)
-		}
+	if err := loadCalendars(dir, cfg); err != nil {
+		return nil, fmt.Errorf("loading calendars: %w", err)
 	}
-
-	// Load maintenance.yaml (optional)
-	maintenancePath := filepath.Join(dir, "maintenance.yaml")
-	if _, err := os.Stat(maintenancePath); err == nil {
-		if err := loadYAML(maintenancePath, &cfg.Maintenance); err != nil {
-			return nil, fmt.Errorf("loading maintenance.yaml: %w", err)
-		}
+	if err := expandMemberGroups(&cfg.DesiredState); err != nil {
+		return nil, fmt.Errorf("loading desired-state.yaml: %w", err)
+	}
+	if err := expandInterfaceRanges(&cfg.DesiredState); err != nil {
+		return nil, fmt.Errorf("loading desired-state.yaml: %w", err)
+	}
+	if err := applyRoles(cfg); err != nil {
+		return nil, fmt.Errorf("loading desired-state.yaml: %w", err)
 	}
 
 	// Set defaults
@@ -57,6 +60,17 @@ func LoadConfigDir(dir string) (*Config, error) {
 	if cfg.Alerts.AlertBehavior.DeduplicationWindow == 0 {
 		cfg.Alerts.AlertBehavior.DeduplicationWindow = 5 * time.Minute
 	}
+	if cfg.DesiredState.Global.ACLCheckInterval == 0 {
+		cfg.DesiredState.Global.ACLCheckInterval = 5 * time.Minute
+	}
+	if cfg.DesiredState.Global.NACCheckInterval == 0 {
+		cfg.DesiredState.Global.NACCheckInterval = 5 * time.Minute
+	}
+	if cfg.DesiredState.Global.InventoryInterval == 0 {
+		cfg.DesiredState.Global.InventoryInterval = time.Hour
+	}
+
+	resolveZones(cfg)
 
 	// Validate configuration
 	if err := ValidateConfig(cfg); err != nil {
@@ -66,15 +80,6 @@ func LoadConfigDir(dir string) (*Config, error) {
 	return cfg, nil
 }
 
-// loadYAML loads a YAML file into a struct
-func loadYAML(path string, out interface{}) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return err
-	}
-	return yaml.Unmarshal(data, out)
-}
-
 // ResolveCredentials resolves credentials for a device
 func (c *Config) ResolveCredentials(deviceName string) CredentialEntry {
 	dev, ok := c.DesiredState.Devices[deviceName]
@@ -105,16 +110,156 @@ func (c *Config) ResolveCredentials(deviceName string) CredentialEntry {
 	return CredentialEntry{}
 }
 
+// RunbookURL resolves the runbook for an alert: the interface's runbook_url
+// wins, then the global runbook for the alert type, then the global default
+func (c *Config) RunbookURL(deviceName, ifaceName, alertType string) string {
+	if dev, ok := c.DesiredState.Devices[deviceName]; ok {
+		if ifCfg, ok := dev.Interfaces[ifaceName]; ok && ifCfg.RunbookURL != "" {
+			return ifCfg.RunbookURL
+		}
+	}
+	if url, ok := c.Alerts.Runbooks[alertType]; ok {
+		return url
+	}
+	return c.Alerts.Runbooks["default"]
+}
+
 // ValidateConfig validates the configuration
 func ValidateConfig(cfg *Config) error {
 	if len(cfg.DesiredState.Devices) == 0 {
 		return fmt.Errorf("no devices configured")
 	}
 
+	if interval := cfg.DesiredState.Global.ACLCheckInterval; interval < 0 || (interval > 0 && interval < 30*time.Second) {
+		return fmt.Errorf("acl_check_interval must be at least 30s")
+	}
+	if interval := cfg.DesiredState.Global.NACCheckInterval; interval < 0 || (interval > 0 && interval < 30*time.Second) {
+		return fmt.Errorf("nac_check_interval must be at least 30s")
+	}
+	if interval := cfg.DesiredState.Global.InventoryInterval; interval < 0 || (interval > 0 && interval < time.Minute) {
+		return fmt.Errorf("inventory_interval must be at least 1m")
+	}
+
+	if rate := cfg.DesiredState.Global.ErrorRate; rate != nil {
+		if err := rate.Validate(); err != nil {
+			return fmt.Errorf("error_rate: %w", err)
+		}
+	}
+	if baseline := cfg.DesiredState.Global.TrafficBaseline; baseline != nil {
+		if err := baseline.Validate(); err != nil {
+			return fmt.Errorf("traffic_baseline: %w", err)
+		}
+	}
+	if tlsCfg := cfg.DesiredState.Global.TLS; tlsCfg != nil {
+		if err := tlsCfg.Validate(); err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+	}
+	if licenses := cfg.DesiredState.Global.Licenses; licenses != nil {
+		if err := licenses.Validate(); err != nil {
+			return fmt.Errorf("licenses: %w", err)
+		}
+	}
+	if rollup := cfg.DesiredState.Global.ComplianceAlert; rollup != nil {
+		if err := rollup.Validate(); err != nil {
+			return fmt.Errorf("compliance_alert: %w", err)
+		}
+	}
+	if skew := cfg.DesiredState.Global.StreamSkew; skew != nil {
+		if err := skew.Validate(); err != nil {
+			return fmt.Errorf("stream_skew: %w", err)
+		}
+	}
+	if conn := cfg.DesiredState.Global.ConnectionAlert; conn != nil {
+		if err := conn.Validate(); err != nil {
+			return fmt.Errorf("connection_alert: %w", err)
+		}
+	}
+	if sessions := cfg.DesiredState.Global.GNMISessions; sessions != nil {
+		if err := sessions.Validate(); err != nil {
+			return fmt.Errorf("gnmi_sessions: %w", err)
+		}
+	}
+	if refresh := cfg.DesiredState.Global.CapabilitiesRefresh; refresh != nil {
+		if err := refresh.Validate(); err != nil {
+			return fmt.Errorf("capabilities_refresh: %w", err)
+		}
+	}
+	if err := cfg.DesiredState.Global.IgnoreInterfaces.Validate(); err != nil {
+		return fmt.Errorf("ignore_interfaces: %w", err)
+	}
+	for name, role := range cfg.DesiredState.Global.Roles {
+		if err := role.Validate(); err != nil {
+			return fmt.Errorf("roles.%s: %w", name, err)
+		}
+	}
+
+	if enforcement := cfg.DesiredState.Global.Enforcement; enforcement != nil {
+		if err := enforcement.Validate(); err != nil {
+			return fmt.Errorf("enforcement: %w", err)
+		}
+	}
+	if err := cfg.Alerts.AlertBehavior.StatePersistence.Validate(); err != nil {
+		return fmt.Errorf("state_persistence: %w", err)
+	}
+	if err := cfg.Alerts.Enrichment.Validate(); err != nil {
+		return fmt.Errorf("enrichment: %w", err)
+	}
+	for alertType, action := range cfg.Alerts.Remediation.Actions {
+		if err := action.Validate(); err != nil {
+			return fmt.Errorf("remediation action %s: %w", alertType, err)
+		}
+	}
+	if err := validateDependencies(cfg.DesiredState.Devices); err != nil {
+		return err
+	}
+	for _, mw := range cfg.Maintenance.MaintenanceWindows {
+		if err := mw.Validate(cfg.DesiredState.Devices); err != nil {
+			return err
+		}
+	}
+	if calendar := cfg.Maintenance.ChangeCalendar; calendar != nil {
+		if err := calendar.Validate(); err != nil {
+			return fmt.Errorf("change_calendar: %w", err)
+		}
+	}
+
 	for name, device := range cfg.DesiredState.Devices {
 		if device.Address == "" {
 			return fmt.Errorf("device %s: address is required", name)
 		}
+		if device.DesiredState != "" && device.DesiredState != "online" && device.DesiredState != "offline" {
+			return fmt.Errorf("device %s: desired_state must be 'online' or 'offline'", name)
+		}
+
+		if device.Port < 0 || device.Port > 65535 {
+			return fmt.Errorf("device %s: port must be between 1 and 65535", name)
+		}
+		if device.CollectionMethod != "" && device.CollectionMethod != CollectionSubscribe && device.CollectionMethod != CollectionPoll {
+			return fmt.Errorf("device %s: collection_method must be '%s' or '%s'", name, CollectionSubscribe, CollectionPoll)
+		}
+		if device.Polled() && cfg.DesiredState.Global.CollectionInterval < time.Second {
+			return fmt.Errorf("device %s: collection_interval must be at least 1s to poll", name)
+		}
+		if tlsCfg := device.TLS; tlsCfg != nil {
+			if err := tlsCfg.Validate(); err != nil {
+				return fmt.Errorf("device %s: tls: %w", name, err)
+			}
+		}
+		if licenses := device.Licenses; licenses != nil {
+			if err := licenses.Validate(); err != nil {
+				return fmt.Errorf("device %s: licenses: %w", name, err)
+			}
+		}
+		if rollup := device.ComplianceAlert; rollup != nil {
+			if err := rollup.Validate(); err != nil {
+				return fmt.Errorf("device %s: compliance_alert: %w", name, err)
+			}
+		}
+
+		if device.Profile != "" && cfg.SessionLimitFor(device) == 0 {
+			return fmt.Errorf("device %s: references unknown profile %s", name, device.Profile)
+		}
 
 		// Validate credential references
 		if device.CredentialsRef != "" {
@@ -123,6 +268,33 @@ func ValidateConfig(cfg *Config) error {
 			}
 		}
 
+		if sched := device.Monitoring; sched != nil {
+			if len(sched.Windows) == 0 {
+				return fmt.Errorf("device %s: monitoring requires at least one window", name)
+			}
+			for i, w := range sched.Windows {
+				if err := w.Validate(); err != nil {
+					return fmt.Errorf("device %s: monitoring window %d: %w", name, i+1, err)
+				}
+			}
+			if sched.Outside != "" && sched.Outside != OutsideMute && sched.Outside != OutsideSuspend {
+				return fmt.Errorf("device %s: monitoring.outside must be 'mute' or 'suspend'", name)
+			}
+		}
+
+		if err := device.IgnoreInterfaces.Validate(); err != nil {
+			return fmt.Errorf("device %s: ignore_interfaces: %w", name, err)
+		}
+
+		if checks := device.MgmtChecks; checks != nil {
+			if checks.Interval < 0 || (checks.Interval > 0 && checks.Interval < 10*time.Second) {
+				return fmt.Errorf("device %s: mgmt_checks.interval must be at least 10s", name)
+			}
+			if checks.SSHPort < 0 || checks.SSHPort > 65535 {
+				return fmt.Errorf("device %s: mgmt_checks.ssh_port must be a valid port", name)
+			}
+		}
+
 		// Validate interfaces
 		for ifName, ifCfg := range device.Interfaces {
 			if ifCfg.DesiredState == "" {
@@ -132,6 +304,54 @@ func ValidateConfig(cfg *Config) error {
 				return fmt.Errorf("device %s, interface %s: desired_state must be 'up' or 'down'", name, ifName)
 			}
 
+			if ifCfg.Enforce && ifCfg.AdminState != "enabled" && ifCfg.AdminState != "disabled" {
+				return fmt.Errorf("device %s, interface %s: enforce requires admin_state 'enabled' or 'disabled'", name, ifName)
+			}
+
+			for i, entry := range ifCfg.Schedule {
+				if err := entry.Validate(); err != nil {
+					return fmt.Errorf("device %s, interface %s: schedule entry %d: %w", name, ifName, i+1, err)
+				}
+				if entry.DesiredState != "up" && entry.DesiredState != "down" {
+					return fmt.Errorf("device %s, interface %s: schedule entry %d: desired_state must be 'up' or 'down'", name, ifName, i+1)
+				}
+			}
+
+			if _, err := ParseVLANs(ifCfg.TrunkAllowedVLANs); err != nil {
+				return fmt.Errorf("device %s, interface %s: trunk_allowed_vlans: %w", name, ifName, err)
+			}
+
+			if acls := ifCfg.RequiredACLs; acls != nil && len(acls.Ingress) == 0 && len(acls.Egress) == 0 {
+				return fmt.Errorf("device %s, interface %s: required_acls must list ingress or egress ACL sets", name, ifName)
+			}
+
+			if rate := ifCfg.ErrorRate; rate != nil {
+				if err := rate.Validate(); err != nil {
+					return fmt.Errorf("device %s, interface %s: error_rate: %w", name, ifName, err)
+				}
+			}
+
+			if capacity := ifCfg.Capacity; capacity != nil {
+				if err := capacity.Validate(); err != nil {
+					return fmt.Errorf("device %s, interface %s: capacity: %w", name, ifName, err)
+				}
+			}
+
+			if nac := ifCfg.NAC; nac != nil {
+				if !nac.Dot1X && !nac.PortSecurity {
+					return fmt.Errorf("device %s, interface %s: nac must require dot1x or port_security", name, ifName)
+				}
+				if nac.ViolationMode != "" && (!nac.PortSecurity || !portSecurityViolationModes[nac.ViolationMode]) {
+					return fmt.Errorf("device %s, interface %s: nac.violation_mode must be 'shutdown', 'restrict' or 'protect' and requires port_security", name, ifName)
+				}
+			}
+
+			if pattern, ok := ifCfg.DescriptionPattern(); ok {
+				if _, err := regexp.Compile(pattern); err != nil {
+					return fmt.Errorf("device %s, interface %s: expected_description: %w", name, ifName, err)
+				}
+			}
+
 			if ifCfg.AdminState != "" && ifCfg.AdminState != "enabled" && ifCfg.AdminState != "disabled" {
 				return fmt.Errorf("device %s, interface %s: admin_state must be 'enabled' or 'disabled'", name, ifName)
 			}
@@ -149,14 +369,81 @@ func ValidateConfig(cfg *Config) error {
 				if ifCfg.MemberPolicy.Mode == "min_active" && ifCfg.MemberPolicy.Minimum <= 0 {
 					return fmt.Errorf("device %s, interface %s: member_policy.minimum must be > 0 for min_active mode", name, ifName)
 				}
+				if ifCfg.MemberPolicy.Mode == "per_stack_minimum" {
+					if err := validatePerStackMinimum(*ifCfg.MemberPolicy, ifCfg.Members.Required); err != nil {
+						return fmt.Errorf("device %s, interface %s: %w", name, ifName, err)
+					}
+				}
 			}
 		}
 	}
 
+	if err := validateConflicts(cfg); err != nil {
+		return err
+	}
+
+	if u := cfg.Alerts.ExternalURL; u != "" {
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("external_url must be an http(s) URL")
+		}
+	}
+
 	// Validate alert channels
 	for name, channel := range cfg.Alerts.Channels {
-		if channel.Type != "apprise" {
-			return fmt.Errorf("channel %s: only 'apprise' type is supported", name)
+		switch channel.Type {
+		case "apprise", "discord", "slack", "msteams", "ntfy":
+		case "webhook":
+			if err := validateWebhook(channel); err != nil {
+				return fmt.Errorf("channel %s: %w", name, err)
+			}
+		case "gotify":
+			if channel.SecretEnv == "" {
+				return fmt.Errorf("channel %s: gotify requires secret_env holding the application token", name)
+			}
+		case "sms":
+			if err := validateSMS(channel); err != nil {
+				return fmt.Errorf("channel %s: %w", name, err)
+			}
+		case "syslog":
+		case "smtp":
+			if smtp := channel.SMTP; smtp == nil || smtp.From == "" || len(smtp.To) == 0 {
+				return fmt.Errorf("channel %s: smtp requires smtp.from and at least one smtp.to address", name)
+			}
+		case "jira", "servicenow":
+			if err := validateTicket(channel); err != nil {
+				return fmt.Errorf("channel %s: %w", name, err)
+			}
+		default:
+			return fmt.Errorf("channel %s: type must be 'apprise', 'webhook', 'discord', 'slack', 'msteams', 'ntfy', 'gotify', 'sms', 'syslog', 'smtp', 'jira' or 'servicenow'", name)
+		}
+		if cfg.DesiredState.Global.Offline && !onNetChannelTypes[channel.Type] {
+			return fmt.Errorf("channel %s: offline mode only allows webhook, syslog and smtp channels", name)
+		}
+		if len(channel.Priorities) > 0 {
+			lo, hi := 1, 5
+			switch channel.Type {
+			case "gotify":
+				lo, hi = 0, 10
+			case "ntfy":
+			default:
+				return fmt.Errorf("channel %s: priorities only apply to ntfy and gotify channels", name)
+			}
+			for sev, p := range channel.Priorities {
+				if p < lo || p > hi {
+					return fmt.Errorf("channel %s: priority for %s must be between %d and %d", name, sev, lo, hi)
+				}
+			}
+		}
+		if channel.Webhook != nil && channel.Type != "webhook" {
+			return fmt.Errorf("channel %s: webhook only applies to webhook channels", name)
+		}
+		if quiet := channel.QuietHours; quiet != nil {
+			if channel.Type == "jira" || channel.Type == "servicenow" {
+				return fmt.Errorf("channel %s: quiet_hours does not apply to ticket channels", name)
+			}
+			if err := quiet.Validate(); err != nil {
+				return fmt.Errorf("channel %s: quiet_hours: %w", name, err)
+			}
 		}
 		if channel.URLEnv == "" {
 			return fmt.Errorf("channel %s: url_env is required", name)
@@ -164,6 +451,55 @@ func ValidateConfig(cfg *Config) error {
 		// Note: We don't validate env var exists here as it may be set at runtime
 	}
 
+	if buf := cfg.DesiredState.Global.UpdateBuffer; buf.Size < 0 {
+		return fmt.Errorf("update_buffer: size must not be negative")
+	} else if buf.Overflow != "" && buf.Overflow != OverflowDropNewest && buf.Overflow != OverflowDropOldest {
+		return fmt.Errorf("update_buffer: overflow must be '%s' or '%s'", OverflowDropNewest, OverflowDropOldest)
+	}
+
+	if influx := cfg.DesiredState.Global.Exporters.Influx; influx != nil {
+		if influx.URL == "" {
+			return fmt.Errorf("exporters.influx: url is required")
+		}
+		if influx.Bucket == "" && influx.Database == "" {
+			return fmt.Errorf("exporters.influx: bucket (v2) or database (v1) is required")
+		}
+	}
+
+	if events := cfg.DesiredState.Global.Exporters.Events; events != nil {
+		if events.NATS != nil && (events.NATS.URL == "" || events.NATS.Subject == "") {
+			return fmt.Errorf("exporters.events.nats: url and subject are required")
+		}
+		if events.Kafka != nil && (events.Kafka.RESTURL == "" || events.Kafka.Topic == "") {
+			return fmt.Errorf("exporters.events.kafka: rest_url and topic are required")
+		}
+	}
+
+	if mqtt := cfg.DesiredState.Global.Exporters.MQTT; mqtt != nil && mqtt.Broker == "" {
+		return fmt.Errorf("exporters.mqtt: broker is required")
+	}
+
+	if relay := cfg.DesiredState.Global.Exporters.Relay; relay != nil && relay.Address == "" {
+		return fmt.Errorf("exporters.relay: address is required")
+	}
+
+	if err := validateSeverities(cfg); err != nil {
+		return err
+	}
+	if err := validateSeverityOverrides(cfg); err != nil {
+		return err
+	}
+	if err := validateSeverityMatrix(cfg); err != nil {
+		return err
+	}
+
+	// Validate message templates parse
+	for alertType, tmpl := range cfg.Alerts.MessageTemplates {
+		if _, err := template.New(alertType).Parse(tmpl); err != nil {
+			return fmt.Errorf("message template %s: %w", alertType, err)
+		}
+	}
+
 	// Validate alert rules reference valid channels
 	for ruleName, rule := range cfg.Alerts.AlertRules {
 		for _, chName := range rule.Channels {
@@ -175,3 +511,32 @@ func ValidateConfig(cfg *Config) error {
 
 	return nil
 }
+
+// onNetChannelTypes are the channel types that can be delivered without
+// reaching outside an isolated network, given on-net endpoints
+var onNetChannelTypes = map[string]bool{
+	"webhook": true,
+	"syslog":  true,
+	"smtp":    true,
+}
+
+// validateSMS checks an sms channel's recipients and provider settings
+func validateSMS(channel ChannelConfig) error {
+	sms := channel.SMS
+	if sms == nil || len(sms.To) == 0 {
+		return fmt.Errorf("sms.to must list at least one number")
+	}
+	switch sms.Provider {
+	case "", "twilio":
+		if sms.From == "" {
+			return fmt.Errorf("sms.from is required for twilio")
+		}
+		if channel.SecretEnv == "" {
+			return fmt.Errorf("twilio requires secret_env holding the auth token")
+		}
+	case "http":
+	default:
+		return fmt.Errorf("sms.provider must be 'twilio' or 'http'")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Maintenance window schedule types
+const (
+	ScheduleRecurring = "recurring"
+	ScheduleOneTime   = "one-time"
+)
+
+// oneTimeLayout is the layout of one-time schedule start and end times,
+// read in the schedule's timezone. RFC 3339 times are accepted too.
+const oneTimeLayout = "2006-01-02 15:04"
+
+// Active reports whether the window is open at t
+func (w MaintenanceWindow) Active(t time.Time) bool {
+	if w.Schedule.Type == ScheduleOneTime {
+		start, end, err := w.Schedule.oneTimeBounds()
+		return err == nil && !t.Before(start) && t.Before(end)
+	}
+	return w.Schedule.window().Contains(t)
+}
+
+// Covers reports whether the window lists the device
+func (w MaintenanceWindow) Covers(device string) bool {
+	return containsString(w.Devices, device)
+}
+
+// Suppressing returns the first window with suppress_alerts that covers the
+// device and is open at t
+func (c MaintenanceConfig) Suppressing(device string, t time.Time) (MaintenanceWindow, bool) {
+	for _, w := range c.MaintenanceWindows {
+		if w.SuppressAlerts && w.Covers(device) && w.Active(t) {
+			return w, true
+		}
+	}
+	return MaintenanceWindow{}, false
+}
+
+// window returns a recurring schedule as a time window. Day is a day name,
+// "weekdays" or "weekends"; empty or "daily" means every day.
+func (s Schedule) window() TimeWindow {
+	w := TimeWindow{Start: s.Start, End: s.End, Timezone: s.Timezone, Calendar: s.Calendar, holidays: s.holidays, loc: s.loc}
+	if s.Day != "" && s.Day != "daily" {
+		w.Days = []string{s.Day}
+	}
+	return w
+}
+
+// oneTimeBounds returns a one-time schedule's start and end, parsing them
+// unless that was done at load
+func (s Schedule) oneTimeBounds() (start, end time.Time, err error) {
+	if !s.start.IsZero() {
+		return s.start, s.end, nil
+	}
+	loc := time.Local
+	if s.loc != nil {
+		loc = s.loc
+	} else if s.Timezone != "" {
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			return start, end, fmt.Errorf("timezone: %w", err)
+		}
+	}
+	parse := func(v string) (time.Time, error) {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		t, err := time.ParseInLocation(oneTimeLayout, v, loc)
+		if err != nil {
+			return t, fmt.Errorf("%q is not \"YYYY-MM-DD HH:MM\" or RFC 3339", v)
+		}
+		return t, nil
+	}
+	if start, err = parse(s.Start); err != nil {
+		return start, end, fmt.Errorf("start: %w", err)
+	}
+	if end, err = parse(s.End); err != nil {
+		return start, end, fmt.Errorf("end: %w", err)
+	}
+	return start, end, nil
+}
+
+// resolve looks up the schedule's timezone and parses one-time bounds, so
+// Active, which runs on every maintenance check, does neither. Errors are
+// left for Validate to report.
+func (s *Schedule) resolve() {
+	if s.Timezone != "" {
+		if loc, err := time.LoadLocation(s.Timezone); err == nil {
+			s.loc = loc
+		}
+	}
+	if s.Type == ScheduleOneTime {
+		if start, end, err := s.oneTimeBounds(); err == nil {
+			s.start, s.end = start, end
+		}
+	}
+}
+
+// Validate checks the window's name, devices and schedule
+func (w MaintenanceWindow) Validate(devices map[string]DeviceConfig) error {
+	if w.Name == "" {
+		return fmt.Errorf("maintenance window without a name")
+	}
+	if len(w.Devices) == 0 {
+		return fmt.Errorf("maintenance window %s: no devices", w.Name)
+	}
+	for _, name := range w.Devices {
+		if _, ok := devices[name]; !ok {
+			return fmt.Errorf("maintenance window %s: unknown device %s", w.Name, name)
+		}
+	}
+	switch w.Schedule.Type {
+	case ScheduleOneTime:
+		start, end, err := w.Schedule.oneTimeBounds()
+		if err != nil {
+			return fmt.Errorf("maintenance window %s: %w", w.Name, err)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("maintenance window %s: end must be after start", w.Name)
+		}
+	case ScheduleRecurring:
+		if err := w.Schedule.window().Validate(); err != nil {
+			return fmt.Errorf("maintenance window %s: %w", w.Name, err)
+		}
+	default:
+		return fmt.Errorf("maintenance window %s: schedule type must be '%s' or '%s'", w.Name, ScheduleRecurring, ScheduleOneTime)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import "fmt"
+
+// SeverityMatrixEntry sets the severity and notification channels of alerts
+// on interfaces of a role at a site, e.g. uplinks in the datacenter are
+// critical and go to PagerDuty while branch access points are warnings sent
+// by email
+type SeverityMatrixEntry struct {
+	Role     string   `yaml:"role"`
+	Site     string   `yaml:"site,omitempty"`     // any site when empty
+	Severity string   `yaml:"severity,omitempty"` // for each state deviation the interface does not set itself
+	Channels []string `yaml:"channels,omitempty"` // instead of the alert rule for the severity
+}
+
+// SeverityMatrixFor returns the severity_matrix entry for an interface: the
+// first entry for its role and its device's site, else the first for its
+// role at any site. Interfaces without a role match nothing.
+func (c *Config) SeverityMatrixFor(deviceName, ifaceName string) (SeverityMatrixEntry, bool) {
+	device, ok := c.DesiredState.Devices[deviceName]
+	if !ok {
+		return SeverityMatrixEntry{}, false
+	}
+	ifCfg, ok := device.Interfaces[ifaceName]
+	if !ok || ifCfg.Role == "" {
+		return SeverityMatrixEntry{}, false
+	}
+	return c.Alerts.severityMatrixEntry(ifCfg.Role, device.Site)
+}
+
+func (a *AlertsConfig) severityMatrixEntry(role, site string) (SeverityMatrixEntry, bool) {
+	var anySite *SeverityMatrixEntry
+	for i, entry := range a.SeverityMatrix {
+		if entry.Role != role {
+			continue
+		}
+		if entry.Site != "" && entry.Site == site {
+			return entry, true
+		}
+		if entry.Site == "" && anySite == nil {
+			anySite = &a.SeverityMatrix[i]
+		}
+	}
+	if anySite != nil {
+		return *anySite, true
+	}
+	return SeverityMatrixEntry{}, false
+}
+
+// validateSeverityMatrix checks that every entry names a defined role, a
+// known severity and defined channels
+func validateSeverityMatrix(cfg *Config) error {
+	for i, entry := range cfg.Alerts.SeverityMatrix {
+		if entry.Role == "" {
+			return fmt.Errorf("severity_matrix entry %d: role is required", i+1)
+		}
+		if _, ok := cfg.DesiredState.Global.Roles[entry.Role]; !ok {
+			return fmt.Errorf("severity_matrix entry %d: unknown role %s", i+1, entry.Role)
+		}
+		if entry.Severity == "" && len(entry.Channels) == 0 {
+			return fmt.Errorf("severity_matrix entry %d: severity or channels is required", i+1)
+		}
+		if entry.Severity != "" {
+			if _, _, ok := cfg.Alerts.SeverityLevel(entry.Severity); !ok {
+				return fmt.Errorf("severity_matrix entry %d: unknown severity %s", i+1, entry.Severity)
+			}
+		}
+		for _, ch := range entry.Channels {
+			if _, ok := cfg.Alerts.Channels[ch]; !ok {
+				return fmt.Errorf("severity_matrix entry %d: references unknown channel %s", i+1, ch)
+			}
+		}
+	}
+	return nil
+}
+
+// withSeverity returns the severities with each unset state deviation
+// (state_mismatch, member_down, channel_down and admin_down) set to
+// severity. Configuration drift such as description_mismatch and measured
+// conditions such as traffic_anomaly keep their own severities.
+func (a AlertSeverity) withSeverity(severity string) AlertSeverity {
+	for _, field := range []*string{&a.StateMismatch, &a.MemberDown, &a.ChannelDown, &a.AdminDown} {
+		if *field == "" {
+			*field = severity
+		}
+	}
+	return a
+}
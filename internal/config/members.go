@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StackMember returns the stack member (switch number) of an interface of a
+// stacked switch: the first number of a three-part name such as
+// TenGigabitEthernet2/0/48. It reports false for other names.
+func StackMember(ifName string) (int, bool) {
+	i := strings.IndexAny(ifName, "0123456789")
+	if i < 0 {
+		return 0, false
+	}
+	parts := strings.Split(ifName[i:], "/")
+	if len(parts) < 3 {
+		return 0, false
+	}
+	member, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	return member, true
+}
+
+// MembersByStack groups port-channel members by stack member. Every member
+// must name its stack member.
+func MembersByStack(members []string) (map[int][]string, error) {
+	stacks := make(map[int][]string)
+	for _, member := range members {
+		stack, ok := StackMember(member)
+		if !ok {
+			return nil, fmt.Errorf("member %s has no stack member number (e.g. GigabitEthernet2/0/1)", member)
+		}
+		stacks[stack] = append(stacks[stack], member)
+	}
+	return stacks, nil
+}
+
+// validatePerStackMinimum checks a per_stack_minimum policy against the
+// members it applies to: each stack member must have at least the minimum
+// listed, or the channel would never be healthy
+func validatePerStackMinimum(policy MemberPolicy, members []string) error {
+	if policy.PerStackMinimum <= 0 {
+		return fmt.Errorf("member_policy.per_stack_minimum must be > 0 for per_stack_minimum mode")
+	}
+	stacks, err := MembersByStack(members)
+	if err != nil {
+		return err
+	}
+	for stack, listed := range stacks {
+		if len(listed) < policy.PerStackMinimum {
+			return fmt.Errorf("stack member %d has %d members, fewer than per_stack_minimum %d", stack, len(listed), policy.PerStackMinimum)
+		}
+	}
+	return nil
+}
+
+// expandMemberGroups adds the members of each port-channel's members.groups
+// to its required members, after those listed directly and without
+// repeating any. A group is looked up in the device's member_groups, then
+// the global ones; an unknown group is an error.
+func expandMemberGroups(ds *DesiredStateConfig) error {
+	for deviceName, device := range ds.Devices {
+		for ifName, ifCfg := range device.Interfaces {
+			if ifCfg.Members == nil || len(ifCfg.Members.Groups) == 0 {
+				continue
+			}
+			required := append([]string(nil), ifCfg.Members.Required...)
+			seen := make(map[string]bool, len(required))
+			for _, member := range required {
+				seen[member] = true
+			}
+			for _, group := range ifCfg.Members.Groups {
+				members, ok := device.MemberGroups[group]
+				if !ok {
+					members, ok = ds.Global.MemberGroups[group]
+				}
+				if !ok {
+					return fmt.Errorf("device %s, interface %s: members.groups references unknown member group %s", deviceName, ifName, group)
+				}
+				for _, member := range members {
+					if !seen[member] {
+						seen[member] = true
+						required = append(required, member)
+					}
+				}
+			}
+			ifCfg.Members = &MemberConfig{Required: required, Groups: ifCfg.Members.Groups}
+			device.Interfaces[ifName] = ifCfg
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestValidatePerStackMinimum(t *testing.T) {
+	tests := []struct {
+		name    string
+		minimum int
+		members []string
+		wantErr bool
+	}{
+		{name: "valid", minimum: 1, members: []string{"TenGigabitEthernet1/0/1", "TenGigabitEthernet2/0/1"}},
+		{name: "two per stack", minimum: 2, members: []string{"Gi1/0/1", "Gi1/0/2", "Gi2/0/1", "Gi2/0/2"}},
+		{name: "short stack", minimum: 2, members: []string{"Gi1/0/1", "Gi1/0/2", "Gi2/0/1"}, wantErr: true},
+		{name: "no stack number", minimum: 1, members: []string{"Gi1/0/1", "Ethernet1"}, wantErr: true},
+		{name: "two-part name", minimum: 1, members: []string{"Ethernet1/1"}, wantErr: true},
+		{name: "zero minimum", minimum: 0, members: []string{"Gi1/0/1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := MemberPolicy{Mode: "per_stack_minimum", PerStackMinimum: tt.minimum}
+			err := validatePerStackMinimum(policy, tt.members)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
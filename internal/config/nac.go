@@ -0,0 +1,34 @@
+package config
+
+// Port-security violation modes
+var portSecurityViolationModes = map[string]bool{
+	"shutdown": true,
+	"restrict": true,
+	"protect":  true,
+}
+
+// NACConfig declares the network access control an access interface must
+// enforce
+type NACConfig struct {
+	Dot1X         bool   `yaml:"dot1x,omitempty"`          // 802.1X authenticator enabled
+	PortSecurity  bool   `yaml:"port_security,omitempty"`  // port-security enabled
+	ViolationMode string `yaml:"violation_mode,omitempty"` // shutdown, restrict or protect; any if unset
+}
+
+// NACState is the access control state an interface reports
+type NACState struct {
+	Dot1X         bool
+	PortSecurity  bool
+	ViolationMode string
+}
+
+// RequiresNAC reports whether any interface of the device declares nac, so
+// its access control state must be read periodically
+func (d DeviceConfig) RequiresNAC() bool {
+	for _, ifCfg := range d.Interfaces {
+		if ifCfg.NAC != nil {
+			return true
+		}
+	}
+	return false
+}
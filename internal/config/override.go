@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"path"
+)
+
+// SeverityOverride adjusts the severity of matching alerts. Exactly one of
+// Set, Min or Max is given: Set replaces the severity, Min raises it to at
+// least that level and Max caps it at that level.
+type SeverityOverride struct {
+	Name  string        `yaml:"name,omitempty"`
+	Match OverrideMatch `yaml:"match"`
+	Set   string        `yaml:"set,omitempty"`
+	Min   string        `yaml:"min,omitempty"`
+	Max   string        `yaml:"max,omitempty"`
+}
+
+// OverrideMatch selects alerts for a severity override. Every non-empty field
+// must match; within a field any listed value matches. Device names may be
+// glob patterns.
+type OverrideMatch struct {
+	Devices    []string `yaml:"devices,omitempty"`
+	Tags       []string `yaml:"tags,omitempty"`
+	Sites      []string `yaml:"sites,omitempty"`
+	AlertTypes []string `yaml:"alert_types,omitempty"`
+}
+
+// ApplySeverityOverrides runs the configured overrides in order against an
+// alert and returns the resulting severity, normalized to a level name
+func (c *Config) ApplySeverityOverrides(deviceName, alertType, severity string) string {
+	severity = c.Alerts.NormalizeSeverity(severity)
+	dev := c.DesiredState.Devices[deviceName]
+	for _, rule := range c.Alerts.SeverityOverrides {
+		if !rule.Match.matches(deviceName, dev, alertType) {
+			continue
+		}
+		switch {
+		case rule.Set != "":
+			severity = c.Alerts.NormalizeSeverity(rule.Set)
+		case rule.Min != "":
+			if c.Alerts.SeverityRank(severity) > c.Alerts.SeverityRank(rule.Min) {
+				severity = c.Alerts.NormalizeSeverity(rule.Min)
+			}
+		case rule.Max != "":
+			if c.Alerts.SeverityRank(severity) < c.Alerts.SeverityRank(rule.Max) {
+				severity = c.Alerts.NormalizeSeverity(rule.Max)
+			}
+		}
+	}
+	return severity
+}
+
+// matches reports whether an alert on the given device satisfies the match
+func (m OverrideMatch) matches(deviceName string, dev DeviceConfig, alertType string) bool {
+	if len(m.Devices) > 0 && !matchAny(m.Devices, func(pattern string) bool {
+		ok, _ := path.Match(pattern, deviceName)
+		return ok
+	}) {
+		return false
+	}
+	if len(m.Tags) > 0 && !matchAny(m.Tags, func(tag string) bool {
+		return containsString(dev.Tags, tag)
+	}) {
+		return false
+	}
+	if len(m.Sites) > 0 && !containsString(m.Sites, dev.Site) {
+		return false
+	}
+	if len(m.AlertTypes) > 0 && !containsString(m.AlertTypes, alertType) {
+		return false
+	}
+	return true
+}
+
+// validateSeverityOverrides checks that every override names known severities
+// and exactly one action
+func validateSeverityOverrides(cfg *Config) error {
+	for i, rule := range cfg.Alerts.SeverityOverrides {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		actions := 0
+		for _, sev := range []string{rule.Set, rule.Min, rule.Max} {
+			if sev == "" {
+				continue
+			}
+			actions++
+			if _, _, ok := cfg.Alerts.SeverityLevel(sev); !ok {
+				return fmt.Errorf("severity override %s: unknown severity %s", name, sev)
+			}
+		}
+		if actions != 1 {
+			return fmt.Errorf("severity override %s: exactly one of set, min or max is required", name)
+		}
+		for _, pattern := range rule.Match.Devices {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("severity override %s: invalid device pattern %q", name, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// matchAny reports whether fn holds for any value
+func matchAny(values []string, fn func(string) bool) bool {
+	for _, v := range values {
+		if fn(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// HistoryFile returns the alert history file: history_path, else
+// alert-history.jsonl next to the state file
+func (p StatePersistence) HistoryFile() string {
+	if p.HistoryPath != "" {
+		return p.HistoryPath
+	}
+	return filepath.Join(filepath.Dir(p.Path), "alert-history.jsonl")
+}
+
+// Validate checks on_restart and the history retention
+func (p StatePersistence) Validate() error {
+	if p.OnRestart != "" && p.OnRestart != "warn_unknown" && p.OnRestart != "silent" {
+		return fmt.Errorf("on_restart must be 'warn_unknown' or 'silent'")
+	}
+	if p.HistoryRetention < 0 || (p.HistoryRetention > 0 && p.HistoryRetention < time.Hour) {
+		return fmt.Errorf("history_retention must be at least 1h")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuietHoursConfig holds back a channel's notifications during its
+// windows, e.g. overnight, except those of the most severe level. Held
+// notifications are kept in memory and sent as one digest when quiet hours
+// end.
+type QuietHoursConfig struct {
+	Windows []TimeWindow `yaml:"windows"`
+}
+
+// Active reports whether t falls in one of the quiet windows
+func (q QuietHoursConfig) Active(t time.Time) bool {
+	for _, w := range q.Windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that there is at least one window and each is valid
+func (q QuietHoursConfig) Validate() error {
+	if len(q.Windows) == 0 {
+		return fmt.Errorf("at least one window is required")
+	}
+	for i, w := range q.Windows {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("window %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
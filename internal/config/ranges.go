@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxRangeExpansion bounds how many interfaces one range key may declare
+const maxRangeExpansion = 4096
+
+// ExpandInterfaceRange expands the bracketed numeric ranges in an interface
+// name into the names they cover, in order: GigabitEthernet1/0/[1-24] is
+// GigabitEthernet1/0/1 to GigabitEthernet1/0/24, and Ethernet[1-2]/[1,3-4]
+// is Ethernet1/1, Ethernet1/3, Ethernet1/4, Ethernet2/1 and so on. A bound
+// written with leading zeros, as in [01-12], keeps its width. A name
+// without brackets expands to itself.
+func ExpandInterfaceRange(name string) ([]string, error) {
+	open := strings.IndexByte(name, '[')
+	if open < 0 {
+		if strings.IndexByte(name, ']') >= 0 {
+			return nil, fmt.Errorf("range %q has ] without [", name)
+		}
+		return []string{name}, nil
+	}
+	end := strings.IndexByte(name[open:], ']')
+	if end < 0 {
+		return nil, fmt.Errorf("range %q has [ without ]", name)
+	}
+	prefix, spec, rest := name[:open], name[open+1:open+end], name[open+end+1:]
+
+	var values []string
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("range %q: invalid number %q", name, lo)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil || last < 0 {
+				return nil, fmt.Errorf("range %q: invalid number %q", name, hi)
+			}
+			if last < first {
+				return nil, fmt.Errorf("range %q: %s is reversed", name, part)
+			}
+		}
+		if last-first >= maxRangeExpansion {
+			return nil, fmt.Errorf("range %q: %s covers more than %d interfaces", name, part, maxRangeExpansion)
+		}
+		width := 0
+		if len(lo) > 1 && lo[0] == '0' {
+			width = len(lo)
+		}
+		for n := first; n <= last; n++ {
+			values = append(values, fmt.Sprintf("%0*d", width, n))
+		}
+	}
+
+	suffixes, err := ExpandInterfaceRange(rest)
+	if err != nil {
+		return nil, err
+	}
+	if len(values)*len(suffixes) > maxRangeExpansion {
+		return nil, fmt.Errorf("range %q covers more than %d interfaces", name, maxRangeExpansion)
+	}
+	names := make([]string, 0, len(values)*len(suffixes))
+	for _, v := range values {
+		for _, s := range suffixes {
+			names = append(names, prefix+v+s)
+		}
+	}
+	return names, nil
+}
+
+// expandInterfaceRanges replaces range keys in every device's interfaces,
+// and ranges in port-channel member lists, with the interfaces they cover.
+// An interface declared by name overrides the range covering it, so a range
+// can set the defaults of a switch's access ports and the exceptions be
+// listed on their own; two ranges covering one interface are an error.
+func expandInterfaceRanges(ds *DesiredStateConfig) error {
+	for deviceName, device := range ds.Devices {
+		if !hasRangeKeys(device.Interfaces) {
+			continue
+		}
+		keys := make([]string, 0, len(device.Interfaces))
+		for key := range device.Interfaces {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		expanded := make(map[string]InterfaceConfig, len(device.Interfaces))
+		fromRange := make(map[string]string)
+		for _, key := range keys {
+			if !strings.ContainsAny(key, "[]") {
+				continue
+			}
+			names, err := ExpandInterfaceRange(key)
+			if err != nil {
+				return fmt.Errorf("device %s: %w", deviceName, err)
+			}
+			for _, name := range names {
+				if other, ok := fromRange[name]; ok {
+					return fmt.Errorf("device %s, interface %s: covered by both %s and %s", deviceName, name, other, key)
+				}
+				fromRange[name] = key
+				expanded[name] = device.Interfaces[key]
+			}
+		}
+		for _, key := range keys {
+			if !strings.ContainsAny(key, "[]") {
+				expanded[key] = device.Interfaces[key]
+			}
+		}
+		device.Interfaces = expanded
+		ds.Devices[deviceName] = device
+	}
+
+	for deviceName, device := range ds.Devices {
+		for ifName, ifCfg := range device.Interfaces {
+			if ifCfg.Members == nil || !hasRanges(ifCfg.Members.Required) {
+				continue
+			}
+			var members []string
+			seen := make(map[string]bool)
+			for _, member := range ifCfg.Members.Required {
+				names, err := ExpandInterfaceRange(member)
+				if err != nil {
+					return fmt.Errorf("device %s, interface %s: members: %w", deviceName, ifName, err)
+				}
+				for _, name := range names {
+					if !seen[name] {
+						seen[name] = true
+						members = append(members, name)
+					}
+				}
+			}
+			ifCfg.Members = &MemberConfig{Required: members, Groups: ifCfg.Members.Groups}
+			device.Interfaces[ifName] = ifCfg
+		}
+	}
+	return nil
+}
+
+func hasRangeKeys(interfaces map[string]InterfaceConfig) bool {
+	for key := range interfaces {
+		if strings.ContainsAny(key, "[]") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRanges(names []string) bool {
+	for _, name := range names {
+		if strings.ContainsAny(name, "[]") {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeKeyCovering returns the range key of a device's interfaces mapping
+// that declares ifaceName, if any
+func rangeKeyCovering(interfaces *yaml.Node, ifaceName string) string {
+	if interfaces == nil || interfaces.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(interfaces.Content); i += 2 {
+		key := interfaces.Content[i].Value
+		if !strings.ContainsAny(key, "[]") {
+			continue
+		}
+		names, err := ExpandInterfaceRange(key)
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			if name == ifaceName {
+				return key
+			}
+		}
+	}
+	return ""
+}
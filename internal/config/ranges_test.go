@@ -0,0 +1,125 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandInterfaceRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    []string
+		wantErr string
+	}{
+		{name: "Ethernet1", want: []string{"Ethernet1"}},
+		{name: "GigabitEthernet1/0/[1-3]", want: []string{"GigabitEthernet1/0/1", "GigabitEthernet1/0/2", "GigabitEthernet1/0/3"}},
+		{name: "Ethernet[1-2]/[1,3-4]", want: []string{"Ethernet1/1", "Ethernet1/3", "Ethernet1/4", "Ethernet2/1", "Ethernet2/3", "Ethernet2/4"}},
+		{name: "Ethernet[08-10]", want: []string{"Ethernet08", "Ethernet09", "Ethernet10"}},
+		{name: "Ethernet[1, 5]", want: []string{"Ethernet1", "Ethernet5"}},
+		{name: "Ethernet[1-4", wantErr: "[ without ]"},
+		{name: "Ethernet1-4]", wantErr: "] without ["},
+		{name: "Ethernet[4-1]", wantErr: "reversed"},
+		{name: "Ethernet[a-4]", wantErr: "invalid number"},
+		{name: "Ethernet[1-5000]", wantErr: "more than 4096"},
+		{name: "Ethernet[1-100]/[1-100]", wantErr: "more than 4096"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandInterfaceRange(tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, %v, want an error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandInterfaceRanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		interfaces map[string]InterfaceConfig
+		want       map[string]InterfaceConfig
+		wantErr    string
+	}{
+		{
+			name: "no ranges",
+			interfaces: map[string]InterfaceConfig{
+				"Ethernet1": {DesiredState: "up"},
+			},
+			want: map[string]InterfaceConfig{
+				"Ethernet1": {DesiredState: "up"},
+			},
+		},
+		{
+			name: "named interface overrides its range",
+			interfaces: map[string]InterfaceConfig{
+				"Ethernet[1-3]": {DesiredState: "up"},
+				"Ethernet2":     {DesiredState: "down"},
+			},
+			want: map[string]InterfaceConfig{
+				"Ethernet1": {DesiredState: "up"},
+				"Ethernet2": {DesiredState: "down"},
+				"Ethernet3": {DesiredState: "up"},
+			},
+		},
+		{
+			name: "overlapping ranges",
+			interfaces: map[string]InterfaceConfig{
+				"Ethernet[1-3]": {DesiredState: "up"},
+				"Ethernet[3-4]": {DesiredState: "down"},
+			},
+			wantErr: "interface Ethernet3: covered by both Ethernet[1-3] and Ethernet[3-4]",
+		},
+		{
+			name: "invalid range",
+			interfaces: map[string]InterfaceConfig{
+				"Ethernet[3-1]": {DesiredState: "up"},
+			},
+			wantErr: "device sw1: range",
+		},
+		{
+			name: "member ranges",
+			interfaces: map[string]InterfaceConfig{
+				"Port-Channel1": {
+					DesiredState: "up",
+					Members:      &MemberConfig{Required: []string{"Ethernet[1-2]", "Ethernet2", "Ethernet5"}},
+				},
+			},
+			want: map[string]InterfaceConfig{
+				"Port-Channel1": {
+					DesiredState: "up",
+					Members:      &MemberConfig{Required: []string{"Ethernet1", "Ethernet2", "Ethernet5"}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &DesiredStateConfig{Devices: map[string]DeviceConfig{
+				"sw1": {Interfaces: tt.interfaces},
+			}}
+			err := expandInterfaceRanges(ds)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ds.Devices["sw1"].Interfaces; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
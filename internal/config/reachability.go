@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ConnectionAlertConfig tunes the connection_failure alert, raised when a
+// device stays unreachable, and the collapse of correlated outages: when
+// many devices drop together, as in a firmware campaign, they are reported
+// as one devices_reconnecting alert listing them rather than one alert
+// each. Without it both work at the defaults.
+type ConnectionAlertConfig struct {
+	// HoldDown is how long a device must stay unreachable before it is
+	// alerted on, riding out ordinary reconnects, default 1m
+	HoldDown time.Duration `yaml:"hold_down,omitempty"`
+	Severity string        `yaml:"severity,omitempty"` // default critical
+	// CollapseDevices is the fewest devices going down within
+	// CollapseWindow of each other that are collapsed into one alert,
+	// default 3; -1 alerts on every device separately
+	CollapseDevices int           `yaml:"collapse_devices,omitempty"`
+	CollapseWindow  time.Duration `yaml:"collapse_window,omitempty"` // default 2m
+	// StragglerTimeout is how long a collapsed device may stay unreachable
+	// before it leaves the group for an alert of its own, default 10m
+	StragglerTimeout time.Duration `yaml:"straggler_timeout,omitempty"`
+}
+
+// HoldDownOrDefault returns the hold-down with its default applied
+func (c *ConnectionAlertConfig) HoldDownOrDefault() time.Duration {
+	if c == nil || c.HoldDown == 0 {
+		return time.Minute
+	}
+	return c.HoldDown
+}
+
+// SeverityOrDefault returns the connection_failure severity, critical
+// unless set
+func (c *ConnectionAlertConfig) SeverityOrDefault() string {
+	if c == nil {
+		return "critical"
+	}
+	return severityOr(c.Severity, "critical")
+}
+
+// Collapse returns how many devices going down within how long of each
+// other are collapsed, and how long they may stay down collapsed. devices
+// is 0 when collapsing is disabled.
+func (c *ConnectionAlertConfig) Collapse() (devices int, window, straggler time.Duration) {
+	devices, window, straggler = 3, 2*time.Minute, 10*time.Minute
+	if c == nil {
+		return devices, window, straggler
+	}
+	switch {
+	case c.CollapseDevices < 0:
+		devices = 0
+	case c.CollapseDevices > 0:
+		devices = c.CollapseDevices
+	}
+	if c.CollapseWindow > 0 {
+		window = c.CollapseWindow
+	}
+	if c.StragglerTimeout > 0 {
+		straggler = c.StragglerTimeout
+	}
+	return devices, window, straggler
+}
+
+// Validate checks the durations and the collapse threshold
+func (c *ConnectionAlertConfig) Validate() error {
+	if c.HoldDown < 0 || c.CollapseWindow < 0 || c.StragglerTimeout < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	if c.CollapseDevices < -1 || c.CollapseDevices == 1 {
+		return fmt.Errorf("collapse_devices must be at least 2, or -1 to disable collapsing")
+	}
+	if c.StragglerTimeout > 0 && c.StragglerTimeout <= c.HoldDownOrDefault() {
+		return fmt.Errorf("straggler_timeout must be longer than hold_down")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Remediation action types
+const (
+	RemediationWebhook    = "webhook"     // POST the alert as JSON to url_env
+	RemediationScript     = "script"      // run command with the alert as JSON on stdin
+	RemediationBouncePort = "bounce_port" // gNMI Set the interface disabled, then enabled
+)
+
+// RemediationConfig enables automatic remediation: when an alert of a
+// listed type fires, its action runs, within the attempt limits. Nothing
+// runs unless enabled is set, and dry_run only logs what would run.
+type RemediationConfig struct {
+	Enabled bool                         `yaml:"enabled"`
+	DryRun  bool                         `yaml:"dry_run,omitempty"`
+	Actions map[string]RemediationAction `yaml:"actions,omitempty"` // alert type -> action
+}
+
+// RemediationAction is the fix run for one alert type
+type RemediationAction struct {
+	Type    string   `yaml:"type"`              // webhook, script or bounce_port
+	URLEnv  string   `yaml:"url_env,omitempty"` // webhook: environment variable holding the URL
+	Command []string `yaml:"command,omitempty"` // script: program and arguments
+	// bounce_port: how long the interface stays disabled, default 5s
+	DownFor time.Duration `yaml:"down_for,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"` // per attempt, default 30s
+	// MaxAttempts bounds the attempts per alert (dedup key) within a day,
+	// default 1
+	MaxAttempts int `yaml:"max_attempts,omitempty"`
+	// Cooldown is the minimum time between attempts on the same alert,
+	// default 10m
+	Cooldown time.Duration `yaml:"cooldown,omitempty"`
+	DryRun   bool          `yaml:"dry_run,omitempty"` // only log, whatever the global dry_run
+}
+
+// Limits returns the action's timeout, attempt limit, cooldown and
+// bounce duration with defaults applied
+func (a RemediationAction) Limits() (timeout time.Duration, maxAttempts int, cooldown, downFor time.Duration) {
+	timeout, maxAttempts, cooldown, downFor = a.Timeout, a.MaxAttempts, a.Cooldown, a.DownFor
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	if maxAttempts == 0 {
+		maxAttempts = 1
+	}
+	if cooldown == 0 {
+		cooldown = 10 * time.Minute
+	}
+	if downFor == 0 {
+		downFor = 5 * time.Second
+	}
+	return timeout, maxAttempts, cooldown, downFor
+}
+
+// Validate checks the action type, its required fields and the limits
+func (a RemediationAction) Validate() error {
+	switch a.Type {
+	case RemediationWebhook:
+		if a.URLEnv == "" {
+			return fmt.Errorf("webhook requires url_env")
+		}
+	case RemediationScript:
+		if len(a.Command) == 0 {
+			return fmt.Errorf("script requires command")
+		}
+	case RemediationBouncePort:
+		if a.DownFor < 0 || a.DownFor > 5*time.Minute {
+			return fmt.Errorf("down_for must be between 0 and 5m")
+		}
+	default:
+		return fmt.Errorf("type must be '%s', '%s' or '%s'", RemediationWebhook, RemediationScript, RemediationBouncePort)
+	}
+	if a.MaxAttempts < 0 {
+		return fmt.Errorf("max_attempts must be >= 0")
+	}
+	if a.Timeout < 0 || a.Cooldown < 0 {
+		return fmt.Errorf("timeout and cooldown must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RoleDefaults are the settings an interface inherits from its role, such
+// as uplink, server, ap or camera, for every field it leaves unset
+type RoleDefaults struct {
+	DesiredState string           `yaml:"desired_state,omitempty"` // "up" or "down"
+	AdminState   string           `yaml:"admin_state,omitempty"`   // "enabled" or "disabled"
+	Alerts       AlertSeverity    `yaml:"alerts,omitempty"`        // per condition; unset conditions keep the interface's
+	ErrorRate    *ErrorRateConfig `yaml:"error_rate,omitempty"`
+	RunbookURL   string           `yaml:"runbook_url,omitempty"`
+}
+
+// Validate checks the role's states and error-rate thresholds
+func (r RoleDefaults) Validate() error {
+	if r.DesiredState != "" && r.DesiredState != "up" && r.DesiredState != "down" {
+		return fmt.Errorf("desired_state must be 'up' or 'down'")
+	}
+	if r.AdminState != "" && r.AdminState != "enabled" && r.AdminState != "disabled" {
+		return fmt.Errorf("admin_state must be 'enabled' or 'disabled'")
+	}
+	if r.ErrorRate != nil {
+		if err := r.ErrorRate.Validate(); err != nil {
+			return fmt.Errorf("error_rate: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyRoles fills the fields each interface with a role leaves unset from
+// the role's defaults in global roles. Alert severities come from the
+// interface itself, then for state deviations the severity_matrix entry for
+// its role and site, then the role. An unknown role is an error.
+func applyRoles(cfg *Config) error {
+	ds := &cfg.DesiredState
+	for deviceName, device := range ds.Devices {
+		for ifName, ifCfg := range device.Interfaces {
+			if ifCfg.Role == "" {
+				continue
+			}
+			role, ok := ds.Global.Roles[ifCfg.Role]
+			if !ok {
+				return fmt.Errorf("device %s, interface %s: unknown role %s", deviceName, ifName, ifCfg.Role)
+			}
+			if ifCfg.DesiredState == "" {
+				ifCfg.DesiredState = role.DesiredState
+			}
+			if ifCfg.AdminState == "" {
+				ifCfg.AdminState = role.AdminState
+			}
+			if ifCfg.ErrorRate == nil {
+				ifCfg.ErrorRate = role.ErrorRate
+			}
+			if ifCfg.RunbookURL == "" {
+				ifCfg.RunbookURL = role.RunbookURL
+			}
+			if entry, ok := cfg.Alerts.severityMatrixEntry(ifCfg.Role, device.Site); ok && entry.Severity != "" {
+				ifCfg.Alerts = ifCfg.Alerts.withSeverity(entry.Severity)
+			}
+			ifCfg.Alerts = ifCfg.Alerts.withDefaults(role.Alerts)
+			device.Interfaces[ifName] = ifCfg
+		}
+	}
+	return nil
+}
+
+// withDefaults returns the severities with every unset condition taken
+// from defaults
+func (a AlertSeverity) withDefaults(defaults AlertSeverity) AlertSeverity {
+	v := reflect.ValueOf(&a).Elem()
+	d := reflect.ValueOf(defaults)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).Kind() == reflect.String && v.Field(i).String() == "" {
+			v.Field(i).SetString(d.Field(i).String())
+		}
+	}
+	return a
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeWindow is a recurring daily window, optionally restricted to certain
+// weekdays, evaluated in a timezone. Dates in the referenced calendar count
+// as weekends.
+type TimeWindow struct {
+	Days     []string `yaml:"days,omitempty"`     // mon..sun, "weekdays" or "weekends"; empty means every day
+	Start    string   `yaml:"start"`              // HH:MM
+	End      string   `yaml:"end"`                // HH:MM; an end at or before start runs past midnight
+	Timezone string   `yaml:"timezone,omitempty"` // IANA name, default local time
+	Calendar string   `yaml:"calendar,omitempty"` // holiday calendar from calendars.yaml
+
+	holidays *Calendar      // resolved from Calendar when the config is loaded
+	loc      *time.Location // resolved from Timezone when the config is loaded
+}
+
+// MonitoringSchedule restricts when a device is monitored
+type MonitoringSchedule struct {
+	Windows []TimeWindow `yaml:"windows"`
+	// Outside is what happens outside every window: "mute" (default) keeps
+	// collecting and evaluating but suppresses notifications; "suspend"
+	// disconnects the collector until the next window opens
+	Outside string `yaml:"outside,omitempty"`
+}
+
+// Monitoring schedule outside-window behaviours
+const (
+	OutsideMute    = "mute"
+	OutsideSuspend = "suspend"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Contains reports whether t falls inside the window. A window running past
+// midnight belongs to the day it starts on.
+func (w TimeWindow) Contains(t time.Time) bool {
+	if loc, err := w.location(); err == nil {
+		t = t.In(loc)
+	}
+	start, err1 := parseClock(w.Start)
+	end, err2 := parseClock(w.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return now >= start && now < end && w.onDay(t)
+	}
+	// Overnight: the evening part belongs to today, the morning part to
+	// yesterday
+	if now >= start {
+		return w.onDay(t)
+	}
+	if now < end {
+		return w.onDay(t.AddDate(0, 0, -1))
+	}
+	return false
+}
+
+// onDay reports whether the window applies on t's date. A calendar date
+// matches "weekends", "sat" and "sun" but no weekday.
+func (w TimeWindow) onDay(t time.Time) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	day := t.Weekday()
+	holiday := w.holidays.Contains(t)
+	for _, name := range w.Days {
+		switch strings.ToLower(name) {
+		case "weekdays":
+			if !holiday && day >= time.Monday && day <= time.Friday {
+				return true
+			}
+		case "weekends":
+			if holiday || day == time.Saturday || day == time.Sunday {
+				return true
+			}
+		default:
+			d, ok := parseWeekday(name)
+			if !ok {
+				continue
+			}
+			if holiday && (d == time.Saturday || d == time.Sunday) {
+				return true
+			}
+			if !holiday && d == day {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// location returns the window's timezone. Contains runs for every state
+// update, so the zone is looked up once at load rather than read from the
+// zoneinfo database on each call.
+func (w TimeWindow) location() (*time.Location, error) {
+	if w.loc != nil {
+		return w.loc, nil
+	}
+	if w.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(w.Timezone)
+}
+
+// resolveZones looks up the timezone of every time window in cfg. An unknown
+// zone is left for Validate to report.
+func resolveZones(cfg *Config) {
+	resolve := func(w *TimeWindow) {
+		if w.Timezone == "" {
+			return
+		}
+		if loc, err := time.LoadLocation(w.Timezone); err == nil {
+			w.loc = loc
+		}
+	}
+	for _, dev := range cfg.DesiredState.Devices {
+		if dev.Monitoring != nil {
+			for i := range dev.Monitoring.Windows {
+				resolve(&dev.Monitoring.Windows[i])
+			}
+		}
+		for _, ifCfg := range dev.Interfaces {
+			for i := range ifCfg.Schedule {
+				resolve(&ifCfg.Schedule[i].TimeWindow)
+			}
+		}
+	}
+	for _, channel := range cfg.Alerts.Channels {
+		if channel.QuietHours == nil {
+			continue
+		}
+		for i := range channel.QuietHours.Windows {
+			resolve(&channel.QuietHours.Windows[i])
+		}
+	}
+}
+
+// Validate checks the window's times, days and timezone
+func (w TimeWindow) Validate() error {
+	if _, err := parseClock(w.Start); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	if _, err := parseClock(w.End); err != nil {
+		return fmt.Errorf("end: %w", err)
+	}
+	for _, name := range w.Days {
+		lower := strings.ToLower(name)
+		if lower == "weekdays" || lower == "weekends" {
+			continue
+		}
+		if _, ok := parseWeekday(name); !ok {
+			return fmt.Errorf("unknown day %q", name)
+		}
+	}
+	if _, err := w.location(); err != nil {
+		return fmt.Errorf("timezone: %w", err)
+	}
+	return nil
+}
+
+// parseWeekday accepts a day's three-letter abbreviation or full name
+func parseWeekday(name string) (time.Weekday, bool) {
+	lower := strings.ToLower(name)
+	if len(lower) < 3 {
+		return 0, false
+	}
+	day, ok := weekdayNames[lower[:3]]
+	if !ok || !strings.HasPrefix(strings.ToLower(day.String()), lower) {
+		return 0, false
+	}
+	return day, true
+}
+
+// parseClock parses HH:MM into minutes after midnight
+func parseClock(v string) (int, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", v)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Active reports whether t falls inside any window of the schedule
+func (s *MonitoringSchedule) Active(t time.Time) bool {
+	for _, w := range s.Windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScheduledState is a desired oper state that applies during a time window
+type ScheduledState struct {
+	TimeWindow   `yaml:",inline"`
+	DesiredState string `yaml:"desired_state"` // "up" or "down"
+}
+
+// DesiredStateAt returns the interface's desired oper state at t: that of
+// the first schedule entry whose window contains t, otherwise desired_state
+func (i InterfaceConfig) DesiredStateAt(t time.Time) string {
+	for _, entry := range i.Schedule {
+		if entry.Contains(t) {
+			return entry.DesiredState
+		}
+	}
+	return i.DesiredState
+}
+
+// Monitored reports whether the device is inside its monitoring schedule at
+// t. Devices without a schedule are always monitored.
+func (d DeviceConfig) Monitored(t time.Time) bool {
+	return d.Monitoring == nil || d.Monitoring.Active(t)
+}
+
+// Suspended reports whether the device's collector should be disconnected
+// at t because it is outside a schedule with outside: suspend
+func (d DeviceConfig) Suspended(t time.Time) bool {
+	return d.Monitoring != nil && d.Monitoring.Outside == OutsideSuspend && !d.Monitoring.Active(t)
+}
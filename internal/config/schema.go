@@ -0,0 +1,403 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The JSON Schema of each configuration file is generated from the types
+// the file decodes into, so it cannot drift from what the loader accepts.
+// It is served at /api/schema for editors and CI, and every file is checked
+// against it before decoding, so a misspelt key is an error naming the file,
+// line and key path rather than a setting silently ignored.
+
+// schemaTypes maps each configuration file to the type it decodes into
+var schemaTypes = map[string]reflect.Type{
+	"desired-state": reflect.TypeOf(DesiredStateConfig{}),
+	"alerts":        reflect.TypeOf(AlertsConfig{}),
+	"credentials":   reflect.TypeOf(CredentialsConfig{}),
+	"maintenance":   reflect.TypeOf(MaintenanceConfig{}),
+	"calendars":     reflect.TypeOf(CalendarsConfig{}),
+}
+
+// durationPattern matches Go duration strings such as 90s or 1h30m
+const durationPattern = `^-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`
+
+// maxSchemaErrors bounds how many violations one file reports
+const maxSchemaErrors = 10
+
+var (
+	schemaOnce  sync.Once
+	schemas     map[string]map[string]interface{}
+	patternMu   sync.Mutex
+	patternByRE = map[string]*regexp.Regexp{}
+)
+
+// SchemaFiles returns the names of the configuration files with a schema
+func SchemaFiles() []string {
+	names := make([]string, 0, len(schemaTypes))
+	for name := range schemaTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Schema returns the JSON Schema (draft 2020-12) of a configuration file by
+// name, such as desired-state. The schema is shared and must not be
+// modified.
+func Schema(name string) (map[string]interface{}, bool) {
+	schemaOnce.Do(func() {
+		schemas = make(map[string]map[string]interface{}, len(schemaTypes))
+		for file, t := range schemaTypes {
+			schemas[file] = buildSchema(file, t)
+		}
+	})
+	s, ok := schemas[name]
+	return s, ok
+}
+
+// buildSchema generates the schema of a file decoding into t. Named struct
+// types become $defs; the top level also allows include.
+func buildSchema(file string, t reflect.Type) map[string]interface{} {
+	defs := make(map[string]interface{})
+	root := structSchema(t, defs)
+	props := root["properties"].(map[string]interface{})
+	props["include"] = map[string]interface{}{
+		"type":        "array",
+		"items":       map[string]interface{}{"type": "string"},
+		"description": "file patterns, relative to the config directory, merged into this file",
+	}
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = "https://netspec.local/schema/" + file + ".json"
+	root["title"] = "NetSpec " + file + ".yaml"
+	if len(defs) > 0 {
+		root["$defs"] = defs
+	}
+	return root
+}
+
+// typeSchema returns the schema of a field type, a $ref for named structs
+func typeSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return map[string]interface{}{
+			"type":    []interface{}{"string", "integer"},
+			"pattern": durationPattern,
+		}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), defs)
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, defs)
+		}
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = map[string]interface{}{} // placeholder for recursive types
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), defs),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), defs),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema returns the object schema of a struct from its yaml tags,
+// flattening inline fields. Unknown keys are not allowed.
+func structSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	props := make(map[string]interface{})
+	addStructFields(t, props, defs)
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+func addStructFields(t reflect.Type, props, defs map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			addStructFields(ft, props, defs)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		props[name] = typeSchema(f.Type, defs)
+	}
+}
+
+// schemaError is one schema violation: the mapping keys leading to it, the
+// full path including list indexes, and the offending node's line
+type schemaError struct {
+	keys    []string
+	path    string
+	line    int
+	msg     string
+	unknown bool // an unknown key, which is only warned about for now
+}
+
+// validateSchema checks a merged configuration file against its schema.
+// Each violation names the file it came from, its line and its key path.
+// Unknown keys were silently ignored before files were checked, so for now
+// they are returned as warnings rather than failing the load.
+func validateSchema(name string, root *yaml.Node, origins map[string]string) (warnings []string, err error) {
+	schema, ok := Schema(name)
+	if !ok {
+		return nil, nil
+	}
+	var errs []schemaError
+	checkNode(schema, schema, root, nil, "", &errs)
+	var problems []string
+	for _, e := range errs {
+		problem := fmt.Sprintf("%s:%d: %s: %s", originOf(e.keys, name+".yaml", origins), e.line, e.path, e.msg)
+		if e.unknown {
+			warnings = append(warnings, problem+", ignored (unknown keys will fail to load in a future release)")
+			continue
+		}
+		if len(problems) == maxSchemaErrors {
+			problems = append(problems, "and more")
+			break
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) > 0 {
+		return warnings, fmt.Errorf("schema: %s", strings.Join(problems, "; "))
+	}
+	return warnings, nil
+}
+
+// checkNode validates node against schema s, appending violations to errs
+func checkNode(root, s map[string]interface{}, node *yaml.Node, keys []string, path string, errs *[]schemaError) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		defs, _ := root["$defs"].(map[string]interface{})
+		def, _ := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		s = def
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		p := path
+		if p == "" {
+			p = "(top level)"
+		}
+		*errs = append(*errs, schemaError{keys: keys, path: p, line: node.Line, msg: fmt.Sprintf(format, args...)})
+	}
+
+	types := schemaTypeNames(s["type"])
+	if len(types) > 0 && !matchesAnyType(node, types) {
+		fail("expected %s, got %s", strings.Join(types, " or "), describeNode(node))
+		return
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		props, _ := s["properties"].(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue // YAML merge key
+			}
+			childKeys := append(append([]string(nil), keys...), key.Value)
+			childPath := key.Value
+			if path != "" {
+				childPath = path + "." + key.Value
+			}
+			if prop, ok := props[key.Value].(map[string]interface{}); ok {
+				checkNode(root, prop, value, childKeys, childPath, errs)
+				continue
+			}
+			switch extra := s["additionalProperties"].(type) {
+			case map[string]interface{}:
+				checkNode(root, extra, value, childKeys, childPath, errs)
+			case bool:
+				if !extra {
+					*errs = append(*errs, schemaError{keys: childKeys, path: childPath, line: key.Line, msg: "unknown field" + suggestField(key.Value, props), unknown: true})
+				}
+			}
+		}
+	case yaml.SequenceNode:
+		items, _ := s["items"].(map[string]interface{})
+		if items == nil {
+			return
+		}
+		for i, item := range node.Content {
+			checkNode(root, items, item, keys, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case yaml.ScalarNode:
+		if pattern, ok := s["pattern"].(string); ok && node.Tag == "!!str" && !schemaPattern(pattern).MatchString(node.Value) {
+			if pattern == durationPattern {
+				fail("%q is not a duration such as 90s or 1h30m", node.Value)
+			} else {
+				fail("%q does not match %s", node.Value, pattern)
+			}
+		}
+		if min, ok := s["minimum"].(int); ok && node.Tag == "!!int" {
+			if v, err := strconv.ParseInt(node.Value, 0, 64); err == nil && v < int64(min) {
+				fail("%s is less than %d", node.Value, min)
+			}
+		}
+	}
+}
+
+// schemaTypeNames returns a schema's type keyword as a list
+func schemaTypeNames(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		names := make([]string, 0, len(t))
+		for _, n := range t {
+			if s, ok := n.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// matchesAnyType reports whether a node can decode as one of the schema
+// types. Any scalar decodes into a string, and YAML 1.1 words such as yes
+// and off decode into a bool, as they do for the loader.
+func matchesAnyType(node *yaml.Node, types []string) bool {
+	for _, t := range types {
+		switch t {
+		case "object":
+			if node.Kind == yaml.MappingNode {
+				return true
+			}
+		case "array":
+			if node.Kind == yaml.SequenceNode {
+				return true
+			}
+		case "string":
+			if node.Kind == yaml.ScalarNode {
+				return true
+			}
+		case "integer":
+			if node.Kind == yaml.ScalarNode && node.Tag == "!!int" {
+				return true
+			}
+		case "number":
+			if node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float") {
+				return true
+			}
+		case "boolean":
+			if node.Kind == yaml.ScalarNode && (node.Tag == "!!bool" || isYAML11Bool(node.Value)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isYAML11Bool(v string) bool {
+	switch strings.ToLower(v) {
+	case "y", "yes", "n", "no", "on", "off":
+		return true
+	}
+	return false
+}
+
+// describeNode names what a node is for an error message
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
+
+// suggestField points at the known key a misspelt one most likely meant
+func suggestField(key string, props map[string]interface{}) string {
+	best, bestDist := "", 3
+	for name := range props {
+		if d := editDistance(key, name); d < bestDist || (d == bestDist && best != "" && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", best)
+}
+
+// editDistance is the Levenshtein distance between two keys
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// schemaPattern compiles a schema pattern once
+func schemaPattern(expr string) *regexp.Regexp {
+	patternMu.Lock()
+	defer patternMu.Unlock()
+	re, ok := patternByRE[expr]
+	if !ok {
+		re = regexp.MustCompile(expr)
+		patternByRE[expr] = re
+	}
+	return re
+}
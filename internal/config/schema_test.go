@@ -0,0 +1,74 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSchemaWarnings(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name: "valid",
+			files: map[string]string{
+				"desired-state.yaml": "devices:\n  sw1:\n    interfaces:\n      Ethernet1:\n        desired_state: up\n",
+			},
+		},
+		{
+			name: "unknown key in the main file",
+			files: map[string]string{
+				"desired-state.yaml": "devices:\n  sw1:\n    interfaces:\n      Ethernet1:\n        desired_state: up\n        desried_state: down\n",
+			},
+			wantWarnings: []string{"desired-state.yaml:6: devices.sw1.interfaces.Ethernet1.desried_state"},
+		},
+		{
+			name: "unknown key in an overlay",
+			files: map[string]string{
+				"desired-state.yaml":       "devices:\n  sw1:\n    site: a\n",
+				"desired-state.d/sw2.yaml": "devices:\n  sw2:\n    site: b\n    colour: blue\n",
+				"desired-state.d/sw3.yaml": "devices:\n  sw3:\n    site: c\n",
+			},
+			wantWarnings: []string{"desired-state.d/sw2.yaml:4: devices.sw2.colour"},
+		},
+		{
+			name: "unknown top-level keys",
+			files: map[string]string{
+				"desired-state.yaml": "devics: {}\nglobals: {}\n",
+			},
+			wantWarnings: []string{"desired-state.yaml:1: devics", "desired-state.yaml:2: globals"},
+		},
+		{
+			name: "wrong type fails the load",
+			files: map[string]string{
+				"desired-state.yaml": "devices:\n  sw1:\n    site: [a, b]\n",
+			},
+			wantErr: "desired-state.yaml:3: devices.sw1.site",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, tt.files)
+			var ds DesiredStateConfig
+			warnings, err := loadLayered(dir, "desired-state", &ds, true)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("got warnings %q, want %q", warnings, tt.wantWarnings)
+			}
+			for i, want := range tt.wantWarnings {
+				if !strings.HasPrefix(warnings[i], want+":") || !strings.Contains(warnings[i], "ignored") {
+					t.Errorf("warning %q, want %q", warnings[i], want)
+				}
+			}
+		})
+	}
+}
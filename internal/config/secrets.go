@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Secrets returns the values the configuration resolves from the
+// environment through its *_env settings (usernames aside), and the
+// password of every credential entry, including those read from a
+// password_file, so they can be kept out of logs and API responses
+func (c *Config) Secrets() []string {
+	var secrets []string
+	collectEnvSecrets(reflect.ValueOf(c).Elem(), &secrets)
+	for _, entry := range c.Credentials.Credentials {
+		if _, password, err := entry.Secret(); err == nil && password != "" {
+			secrets = append(secrets, password)
+		}
+	}
+	return secrets
+}
+
+// collectEnvSecrets walks v and appends the value of the environment
+// variable named by each string field whose YAML key ends in _env, or by
+// each value of such a map, as in a webhook's header_env
+func collectEnvSecrets(v reflect.Value, secrets *[]string) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			collectEnvSecrets(v.Elem(), secrets)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if field.Type.Kind() == reflect.String && strings.HasSuffix(key, "_env") && !strings.Contains(key, "username") {
+				if name := v.Field(i).String(); name != "" {
+					if value := os.Getenv(name); value != "" {
+						*secrets = append(*secrets, value)
+					}
+				}
+				continue
+			}
+			collectEnvSecrets(v.Field(i), secrets)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			collectEnvSecrets(iter.Value(), secrets)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectEnvSecrets(v.Index(i), secrets)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"math"
+)
+
+// GNMISessionsConfig sets the gNMI session limits of device platforms, for
+// the gnmi_session_limit alert raised when NetSpec holds nearly as many
+// concurrent sessions to a device as its platform accepts. Some IOS-XE
+// releases accept only a handful, so an extra Get or a lingering stream can
+// lock NetSpec out. Devices name their platform with profile.
+type GNMISessionsConfig struct {
+	WarnPercent int                       `yaml:"warn_percent,omitempty"` // share of the limit that fires the alert, default 80
+	Severity    string                    `yaml:"severity,omitempty"`     // default warning
+	Profiles    map[string]SessionProfile `yaml:"profiles,omitempty"`
+}
+
+// SessionProfile is the gNMI session limit of a vendor platform
+type SessionProfile struct {
+	MaxSessions int `yaml:"max_sessions"`
+}
+
+// WarnPercentOrDefault returns the alert threshold with its default applied
+func (c *GNMISessionsConfig) WarnPercentOrDefault() int {
+	if c == nil || c.WarnPercent == 0 {
+		return 80
+	}
+	return c.WarnPercent
+}
+
+// SeverityOrDefault returns the gnmi_session_limit severity, warning unless
+// set
+func (c *GNMISessionsConfig) SeverityOrDefault() string {
+	if c == nil {
+		return "warning"
+	}
+	return severityOr(c.Severity, "warning")
+}
+
+// WarnAt returns the session count that fires the alert for a limit: the
+// warn_percent share of it rounded up, so a limit of 2 at 80% warns at 2
+func (c *GNMISessionsConfig) WarnAt(limit int) int {
+	return int(math.Ceil(float64(limit) * float64(c.WarnPercentOrDefault()) / 100))
+}
+
+// Validate checks the threshold and every profile's limit
+func (c *GNMISessionsConfig) Validate() error {
+	if c.WarnPercent < 0 || c.WarnPercent > 100 {
+		return fmt.Errorf("warn_percent must be between 1 and 100")
+	}
+	for name, profile := range c.Profiles {
+		if profile.MaxSessions < 1 {
+			return fmt.Errorf("profile %s: max_sessions must be at least 1", name)
+		}
+	}
+	return nil
+}
+
+// SessionLimitFor returns the gNMI session limit of a device's profile, or
+// 0 when the device names none
+func (c *Config) SessionLimitFor(deviceCfg DeviceConfig) int {
+	if deviceCfg.Profile == "" || c.DesiredState.Global.GNMISessions == nil {
+		return 0
+	}
+	return c.DesiredState.Global.GNMISessions.Profiles[deviceCfg.Profile].MaxSessions
+}
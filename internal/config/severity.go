@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SeverityLevel defines a user-facing severity. Levels are listed from most
+// to least severe and the order is used wherever severities are compared.
+type SeverityLevel struct {
+	Name    string   `yaml:"name"`
+	Aliases []string `yaml:"aliases,omitempty"` // other names (e.g. built-in "critical") that map to this level
+	Color   string   `yaml:"color,omitempty"`   // badge color: red, orange, yellow, blue, purple, gray
+	Emoji   string   `yaml:"emoji,omitempty"`   // notification emoji
+}
+
+// DefaultSeverityLevels are used when alerts.yaml does not define severities
+var DefaultSeverityLevels = []SeverityLevel{
+	{Name: "critical", Color: "red", Emoji: "🔴"},
+	{Name: "warning", Color: "yellow", Emoji: "⚠️"},
+	{Name: "info", Color: "blue", Emoji: "ℹ️"},
+}
+
+// severityColors are the badge colors the web UI knows how to render
+var severityColors = map[string]struct{}{
+	"red":    {},
+	"orange": {},
+	"yellow": {},
+	"blue":   {},
+	"purple": {},
+	"gray":   {},
+}
+
+// SeverityLevels returns the configured severity levels, most severe first
+func (a *AlertsConfig) SeverityLevels() []SeverityLevel {
+	if len(a.Severities) == 0 {
+		return DefaultSeverityLevels
+	}
+	return a.Severities
+}
+
+// SeverityLevel looks up a severity by name or alias, case-insensitively,
+// and returns the level together with its rank (0 is most severe)
+func (a *AlertsConfig) SeverityLevel(name string) (SeverityLevel, int, bool) {
+	for i, level := range a.SeverityLevels() {
+		if strings.EqualFold(level.Name, name) {
+			return level, i, true
+		}
+		for _, alias := range level.Aliases {
+			if strings.EqualFold(alias, name) {
+				return level, i, true
+			}
+		}
+	}
+	return SeverityLevel{}, 0, false
+}
+
+// severityOr returns severity, or def when it is unset
+func severityOr(severity, def string) string {
+	if severity == "" {
+		return def
+	}
+	return severity
+}
+
+// NormalizeSeverity maps a severity name or alias onto its configured level
+// name. Unknown severities are returned unchanged.
+func (a *AlertsConfig) NormalizeSeverity(name string) string {
+	if level, _, ok := a.SeverityLevel(name); ok {
+		return level.Name
+	}
+	return name
+}
+
+// SeverityRank returns the position of a severity in the ordered levels,
+// 0 being most severe. Unknown severities rank below every known level.
+func (a *AlertsConfig) SeverityRank(name string) int {
+	if _, rank, ok := a.SeverityLevel(name); ok {
+		return rank
+	}
+	return len(a.SeverityLevels())
+}
+
+// SeverityColor returns the badge color for a severity, "gray" if unknown
+func (a *AlertsConfig) SeverityColor(name string) string {
+	if level, _, ok := a.SeverityLevel(name); ok && level.Color != "" {
+		return level.Color
+	}
+	return "gray"
+}
+
+// SeverityEmoji returns the notification emoji for a severity
+func (a *AlertsConfig) SeverityEmoji(name string) string {
+	if level, _, ok := a.SeverityLevel(name); ok && level.Emoji != "" {
+		return level.Emoji
+	}
+	return "ℹ️"
+}
+
+// validateSeverities checks the severity levels themselves and every place
+// in the configuration that refers to a severity by name
+func validateSeverities(cfg *Config) error {
+	seen := make(map[string]string)
+	for _, level := range cfg.Alerts.Severities {
+		if level.Name == "" {
+			return fmt.Errorf("severities: every level needs a name")
+		}
+		if level.Color != "" {
+			if _, ok := severityColors[level.Color]; !ok {
+				return fmt.Errorf("severity %s: unknown color %q", level.Name, level.Color)
+			}
+		}
+		for _, name := range append([]string{level.Name}, level.Aliases...) {
+			key := strings.ToLower(name)
+			if other, ok := seen[key]; ok {
+				return fmt.Errorf("severity %s: name %q already used by %s", level.Name, name, other)
+			}
+			seen[key] = level.Name
+		}
+	}
+
+	known := func(name string) bool {
+		_, _, ok := cfg.Alerts.SeverityLevel(name)
+		return ok
+	}
+	// Built-in checks fall back to these names, so custom levels must cover them
+	for _, builtin := range DefaultSeverityLevels {
+		if !known(builtin.Name) {
+			return fmt.Errorf("severities: no level has name or alias %q", builtin.Name)
+		}
+	}
+	for ruleName := range cfg.Alerts.AlertRules {
+		if ruleName != "default" && !known(ruleName) {
+			return fmt.Errorf("alert rule %s: unknown severity", ruleName)
+		}
+	}
+	for chName, ch := range cfg.Alerts.Channels {
+		for _, sev := range ch.SeverityFilter {
+			if !known(sev) {
+				return fmt.Errorf("channel %s: severity_filter references unknown severity %s", chName, sev)
+			}
+		}
+		for sev := range ch.Tags {
+			if sev != "default" && !known(sev) {
+				return fmt.Errorf("channel %s: tags references unknown severity %s", chName, sev)
+			}
+		}
+		for sev := range ch.Priorities {
+			if !known(sev) {
+				return fmt.Errorf("channel %s: priorities references unknown severity %s", chName, sev)
+			}
+		}
+	}
+	for devName, dev := range cfg.DesiredState.Devices {
+		for ifName, iface := range dev.Interfaces {
+			for _, sev := range []string{iface.Alerts.StateMismatch, iface.Alerts.MemberDown, iface.Alerts.ChannelDown, iface.Alerts.AdminDown, iface.Alerts.DescriptionMismatch, iface.Alerts.TrunkVLANMismatch, iface.Alerts.ACLMissing, iface.Alerts.NACDisabled, iface.Alerts.ErrorRate, iface.Alerts.TrafficAnomaly, iface.Alerts.CapacityForecast} {
+				if sev != "" && !known(sev) {
+					return fmt.Errorf("device %s, interface %s: unknown alert severity %s", devName, ifName, sev)
+				}
+			}
+		}
+	}
+	return nil
+}
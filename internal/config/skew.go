@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// StreamSkewConfig tunes the gnmi_stream_skew alert, raised when the
+// timestamps of a device's notifications drift from the time they arrive:
+// the device is too busy to stream promptly, or its clock is wrong. Without
+// it the alert fires at the defaults.
+type StreamSkewConfig struct {
+	Threshold time.Duration `yaml:"threshold,omitempty"` // skew either way that fires the alert, default 30s
+	Severity  string        `yaml:"severity,omitempty"`  // default warning
+}
+
+// ThresholdOrDefault returns the alert threshold with its default applied
+func (c *StreamSkewConfig) ThresholdOrDefault() time.Duration {
+	if c == nil || c.Threshold == 0 {
+		return 30 * time.Second
+	}
+	return c.Threshold
+}
+
+// SeverityOrDefault returns the gnmi_stream_skew severity, warning unless set
+func (c *StreamSkewConfig) SeverityOrDefault() string {
+	if c == nil {
+		return "warning"
+	}
+	return severityOr(c.Severity, "warning")
+}
+
+// Validate checks the threshold is long enough to stay clear of ordinary
+// network delay
+func (c *StreamSkewConfig) Validate() error {
+	if c.Threshold < 0 || (c.Threshold > 0 && c.Threshold < time.Second) {
+		return fmt.Errorf("threshold must be at least 1s")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import "fmt"
+
+// What ticket channels do when an alert resolves
+const (
+	TicketResolveClose   = "close"   // comment, then close the ticket (default)
+	TicketResolveComment = "comment" // only comment, leaving the ticket to people
+)
+
+// TicketConfig configures a jira or servicenow channel, which opens a ticket
+// when an alert fires and comments on and closes it when the alert
+// resolves. url_env holds the base URL (https://example.atlassian.net or
+// https://example.service-now.com) and secret_env the credentials: user:token
+// for basic auth, or a bare token sent as a bearer token (a Jira personal
+// access token or ServiceNow OAuth token).
+type TicketConfig struct {
+	// Jira: project key (required), issue type (default Task), labels and
+	// the workflow transition that closes an issue (default Done)
+	Project         string   `yaml:"project,omitempty"`
+	IssueType       string   `yaml:"issue_type,omitempty"`
+	Labels          []string `yaml:"labels,omitempty"`
+	CloseTransition string   `yaml:"close_transition,omitempty"`
+	// ServiceNow: table (default incident), assignment group, and the
+	// state and close code set on resolve (default 6, Resolved, and
+	// "Solved (Permanently)")
+	Table           string `yaml:"table,omitempty"`
+	AssignmentGroup string `yaml:"assignment_group,omitempty"`
+	ResolveState    string `yaml:"resolve_state,omitempty"`
+	CloseCode       string `yaml:"close_code,omitempty"`
+	OnResolve       string `yaml:"on_resolve,omitempty"` // close (default) or comment
+}
+
+// WithDefaults returns the configuration with defaults applied
+func (c TicketConfig) WithDefaults() TicketConfig {
+	if c.IssueType == "" {
+		c.IssueType = "Task"
+	}
+	if c.CloseTransition == "" {
+		c.CloseTransition = "Done"
+	}
+	if c.Table == "" {
+		c.Table = "incident"
+	}
+	if c.ResolveState == "" {
+		c.ResolveState = "6"
+	}
+	if c.CloseCode == "" {
+		c.CloseCode = "Solved (Permanently)"
+	}
+	if c.OnResolve == "" {
+		c.OnResolve = TicketResolveClose
+	}
+	return c
+}
+
+// validateTicket checks a jira or servicenow channel's ticket settings
+func validateTicket(channel ChannelConfig) error {
+	var t TicketConfig
+	if channel.Ticket != nil {
+		t = *channel.Ticket
+	}
+	if channel.Type == "jira" && t.Project == "" {
+		return fmt.Errorf("jira requires ticket.project")
+	}
+	if channel.SecretEnv == "" {
+		return fmt.Errorf("%s requires secret_env holding the credentials", channel.Type)
+	}
+	if t.OnResolve != "" && t.OnResolve != TicketResolveClose && t.OnResolve != TicketResolveComment {
+		return fmt.Errorf("ticket.on_resolve must be '%s' or '%s'", TicketResolveClose, TicketResolveComment)
+	}
+	return nil
+}
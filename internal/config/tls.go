@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// TLSConfig secures a device's gNMI connection. The certificate the device
+// presents is checked for expiry, since an expired certificate takes the
+// telemetry channel down with it.
+type TLSConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+	ServerName         string `yaml:"server_name,omitempty"` // name verified in the certificate, default the address
+	CAFile             string `yaml:"ca_file,omitempty"`     // default system roots
+	CertFile           string `yaml:"cert_file,omitempty"`   // client certificate for mutual TLS
+	KeyFile            string `yaml:"key_file,omitempty"`
+	CertWarnDays       int    `yaml:"cert_warn_days,omitempty"` // warn this many days before the device certificate expires, default 30
+}
+
+// CertWarning returns how long before expiry a device certificate is
+// warned about
+func (c TLSConfig) CertWarning() time.Duration {
+	days := c.CertWarnDays
+	if days == 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Validate checks the client certificate pair and warning lead time
+func (c TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("cert_file and key_file must be set together")
+	}
+	if c.CertWarnDays < 0 {
+		return fmt.Errorf("cert_warn_days must be >= 0")
+	}
+	return nil
+}
+
+// GNMIPortFor returns the gNMI port of a device: its own, else the global
+// gnmi_port
+func (c *Config) GNMIPortFor(deviceCfg DeviceConfig) int {
+	if deviceCfg.Port != 0 {
+		return deviceCfg.Port
+	}
+	return c.DesiredState.Global.GNMIPort
+}
+
+// TLSFor returns the gNMI TLS settings of a device: its own, else the
+// global default, else nil for plaintext connections
+func (c *Config) TLSFor(deviceCfg DeviceConfig) *TLSConfig {
+	if deviceCfg.TLS != nil {
+		return deviceCfg.TLS
+	}
+	return c.DesiredState.Global.TLS
+}
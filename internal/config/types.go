@@ -1,6 +1,10 @@
 package config
 
-import "time"
+import (
+	"regexp"
+	"strings"
+	"time"
+)
 
 // Config represents the complete NetSpec configuration
 type Config struct {
@@ -8,6 +12,9 @@ type Config struct {
 	Alerts       AlertsConfig      `yaml:"alerts"`
 	Credentials  CredentialsConfig `yaml:"credentials"`
 	Maintenance  MaintenanceConfig `yaml:"maintenance"`
+	Calendars    CalendarsConfig   `yaml:"calendars"`
+
+	schemaWarnings []string // unknown keys found in the files, see validateSchema
 }
 
 // DesiredStateConfig contains device and interface monitoring configuration
@@ -21,53 +28,341 @@ type AlertsConfig struct {
 	Channels      map[string]ChannelConfig `yaml:"channels"`
 	AlertRules    map[string]AlertRule    `yaml:"alert_rules"`
 	AlertBehavior AlertBehavior           `yaml:"alert_behavior"`
+	Runbooks      map[string]string       `yaml:"runbooks,omitempty"` // alert type (or "default") -> runbook URL
+	MessageTemplates map[string]string    `yaml:"message_templates,omitempty"` // alert type (or "<type>_resolved") -> text/template
+	Severities    []SeverityLevel         `yaml:"severities,omitempty"` // most severe first; defaults to critical, warning, info
+	SeverityOverrides []SeverityOverride  `yaml:"severity_overrides,omitempty"`
+	SeverityMatrix []SeverityMatrixEntry  `yaml:"severity_matrix,omitempty"` // severity and channels by interface role and site
+	InboundWebhook InboundWebhookConfig   `yaml:"inbound_webhook,omitempty"`
+	ChatOps       ChatOpsConfig           `yaml:"chatops,omitempty"`
+	Remediation   RemediationConfig       `yaml:"remediation,omitempty"` // actions run automatically when alerts fire
+	Enrichment    EnrichmentConfig        `yaml:"enrichment,omitempty"` // external lookup merged into related state
+	Debug         DebugConfig             `yaml:"debug,omitempty"`
+	// ExternalURL is the web UI's address as reached by notification
+	// readers, e.g. https://netspec.example.com; Slack and Teams messages
+	// link back to the device and alert pages under it
+	ExternalURL   string                  `yaml:"external_url,omitempty"`
+}
+
+// DebugConfig configures the debug endpoints. They are disabled unless a
+// token is configured and present in the environment.
+type DebugConfig struct {
+	InjectTokenEnv string `yaml:"inject_token_env,omitempty"` // bearer token for POST /api/debug/inject-state-change
+}
+
+// InboundWebhookConfig configures POST /api/webhooks/inbound. The endpoint
+// is disabled unless a secret is set or unsigned requests are allowed.
+type InboundWebhookConfig struct {
+	SecretEnv     string `yaml:"secret_env,omitempty"`     // requests must carry a valid X-NetSpec-Signature
+	AllowUnsigned bool   `yaml:"allow_unsigned,omitempty"` // accept requests without a signature when secret_env is unset
+}
+
+// ChatOpsConfig configures the Slack/Mattermost slash-command endpoint.
+// The endpoint is disabled unless at least one verification method is set.
+type ChatOpsConfig struct {
+	TokenEnv              string `yaml:"token_env,omitempty"`                // Mattermost (or legacy Slack) verification token
+	SlackSigningSecretEnv string `yaml:"slack_signing_secret_env,omitempty"` // Slack request signing secret
 }
 
 // CredentialsConfig defines credential storage
 type CredentialsConfig struct {
 	Credentials map[string]CredentialEntry `yaml:"credentials"`
+	// UpdateTokenEnv names the variable holding the bearer token required by
+	// POST /api/credentials/{name}; without it updates are refused
+	UpdateTokenEnv string `yaml:"update_token_env,omitempty"`
 }
 
 // CredentialEntry defines a credential set
 type CredentialEntry struct {
 	Username     string `yaml:"username"`
+	UsernameEnv  string `yaml:"username_env,omitempty"`  // used when username is empty
 	PasswordEnv  string `yaml:"password_env,omitempty"`
+	PasswordFile string `yaml:"password_file,omitempty"` // e.g. a Docker or Kubernetes secret mount; password_env wins
 	PasswordVault string `yaml:"password_vault,omitempty"`
 }
 
 // MaintenanceConfig defines maintenance windows
 type MaintenanceConfig struct {
 	MaintenanceWindows []MaintenanceWindow `yaml:"maintenance_windows,omitempty"`
+	ChangeCalendar     *ChangeCalendarConfig `yaml:"change_calendar,omitempty"` // windows from an external change calendar
 }
 
 // GlobalConfig contains global settings
 type GlobalConfig struct {
 	DefaultCredentials string        `yaml:"default_credentials,omitempty"`
 	GNMIPort           int           `yaml:"gnmi_port,omitempty"`
+	TLS                *TLSConfig    `yaml:"tls,omitempty"` // gNMI over TLS for every device without its own tls
 	CollectionInterval time.Duration `yaml:"collection_interval,omitempty"`
+	Exporters          ExportersConfig `yaml:"exporters,omitempty"`
+	StateCache         StateCacheConfig `yaml:"state_cache,omitempty"`
+	UpdateBuffer       UpdateBufferConfig `yaml:"update_buffer,omitempty"`
+	// ConnectPrechecks probes a device with ICMP ping, a TCP connect and a
+	// TLS handshake after a failed gNMI connect, reporting host unreachable,
+	// port closed or TLS failure in the device's last error
+	ConnectPrechecks bool `yaml:"connect_prechecks,omitempty"`
+	// ACLCheckInterval is how often devices with required_acls have their
+	// ACL attachments read with a gNMI Get, default 5m
+	ACLCheckInterval time.Duration `yaml:"acl_check_interval,omitempty"`
+	// NACCheckInterval is how often devices with nac interfaces have their
+	// 802.1X and port-security state read, default 5m
+	NACCheckInterval time.Duration `yaml:"nac_check_interval,omitempty"`
+	// InventoryInterval is how often every device's /components inventory
+	// is read, default 1h
+	InventoryInterval time.Duration `yaml:"inventory_interval,omitempty"`
+	// IgnoreInterfaces excludes interfaces on every device from discovery
+	// and evaluation
+	IgnoreInterfaces IgnoreRules `yaml:"ignore_interfaces,omitempty"`
+	// ErrorRate applies input error-rate alerting to every declared
+	// interface without its own error_rate
+	ErrorRate *ErrorRateConfig `yaml:"error_rate,omitempty"`
+	// TrafficBaseline opts in to experimental traffic anomaly alerts on
+	// every declared interface
+	TrafficBaseline *TrafficBaselineConfig `yaml:"traffic_baseline,omitempty"`
+	// Licenses checks every device's license expiry; devices may set
+	// their own licenses
+	Licenses *LicenseConfig `yaml:"licenses,omitempty"`
+	// Offline is for isolated networks: the web UI loads no external fonts
+	// and only on-net notification channels (webhook, syslog, smtp) are
+	// accepted
+	Offline bool `yaml:"offline,omitempty"`
+	// MemberGroups names sets of port-channel members that members.groups
+	// can reference on any device, e.g. uplink-pair-a: [Te1/1/1, Te2/1/1]
+	MemberGroups map[string][]string `yaml:"member_groups,omitempty"`
+	// Roles defines the defaults interfaces inherit by role:, e.g. uplink
+	// or camera, for desired and admin state, alert severities and
+	// error-rate thresholds; an interface's own settings win
+	Roles map[string]RoleDefaults `yaml:"roles,omitempty"`
+	// UserHeader names the request header, such as X-Forwarded-User, in
+	// which an authenticating reverse proxy passes the web UI user, whose
+	// preferences are then kept per user rather than shared
+	UserHeader string `yaml:"user_header,omitempty"`
+	// Enforcement opts in to restoring the declared admin_state of
+	// interfaces with enforce: true via gNMI Set
+	Enforcement *EnforcementConfig `yaml:"enforcement,omitempty"`
+	// ComplianceAlert raises a device-wide alert when too many of a
+	// device's interfaces deviate at once; devices may set their own
+	ComplianceAlert *ComplianceAlertConfig `yaml:"compliance_alert,omitempty"`
+	// StreamSkew tunes the alert on notification timestamps drifting from
+	// their receive time
+	StreamSkew *StreamSkewConfig `yaml:"stream_skew,omitempty"`
+	// ConnectionAlert tunes the alert on unreachable devices and the
+	// collapse of many devices dropping at once into one alert
+	ConnectionAlert *ConnectionAlertConfig `yaml:"connection_alert,omitempty"`
+	// CapabilitiesRefresh tunes the periodic re-read of every device's gNMI
+	// Capabilities
+	CapabilitiesRefresh *CapabilitiesRefreshConfig `yaml:"capabilities_refresh,omitempty"`
+	// GNMISessions sets the session limits of device platforms, which
+	// devices name with profile
+	GNMISessions *GNMISessionsConfig `yaml:"gnmi_sessions,omitempty"`
+}
+
+// Update buffer overflow policies
+const (
+	OverflowDropNewest = "drop_newest" // discard the incoming notification (default)
+	OverflowDropOldest = "drop_oldest" // discard the oldest queued notification
+)
+
+// UpdateBufferConfig sizes each collector's queue of notifications awaiting
+// evaluation and chooses what is discarded when a burst fills it
+type UpdateBufferConfig struct {
+	Size     int    `yaml:"size,omitempty"`     // default 256
+	Overflow string `yaml:"overflow,omitempty"` // drop_newest (default) or drop_oldest
+}
+
+// StateCacheConfig bounds the evaluator's cache of observed interface state
+type StateCacheConfig struct {
+	MaxEntries int           `yaml:"max_entries,omitempty"` // default 100000
+	TTL        time.Duration `yaml:"ttl,omitempty"`         // evict entries not updated for this long, default 24h
+}
+
+// Limits returns the configured bounds with defaults applied
+func (c StateCacheConfig) Limits() (maxEntries int, ttl time.Duration) {
+	maxEntries, ttl = c.MaxEntries, c.TTL
+	if maxEntries <= 0 {
+		maxEntries = 100000
+	}
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return maxEntries, ttl
+}
+
+// ExportersConfig configures optional exports to external systems
+type ExportersConfig struct {
+	Influx *InfluxExporterConfig `yaml:"influx,omitempty"`
+	Events *EventBusConfig       `yaml:"events,omitempty"`
+	MQTT   *MQTTConfig           `yaml:"mqtt,omitempty"`
+	Relay  *RelayConfig          `yaml:"relay,omitempty"`
+}
+
+// RelayConfig configures forwarding of raw gNMI notifications to an upstream
+// collector over a gNMI dial-out stream
+type RelayConfig struct {
+	Address string `yaml:"address"`          // host:port of the upstream collector
+	Method  string `yaml:"method,omitempty"` // full gRPC method, default "/gnmi_dialout.gNMIDialOut/Publish"
+	TLS     bool   `yaml:"tls,omitempty"`    // use TLS with system roots
+}
+
+// MQTTConfig configures the MQTT publisher, which keeps a retained message
+// with the current compliance of every interface on <topic_prefix>/<device>/<interface>
+type MQTTConfig struct {
+	Broker      string `yaml:"broker"`                 // tcp://host:1883
+	TopicPrefix string `yaml:"topic_prefix,omitempty"` // default "netspec"
+	ClientID    string `yaml:"client_id,omitempty"`    // default "netspec"
+	Username    string `yaml:"username,omitempty"`
+	PasswordEnv string `yaml:"password_env,omitempty"`
+}
+
+// EventBusConfig configures publishing of state changes and alert lifecycle
+// events as JSON. Either or both publishers may be set.
+type EventBusConfig struct {
+	NATS  *NATSConfig      `yaml:"nats,omitempty"`
+	Kafka *KafkaRESTConfig `yaml:"kafka,omitempty"`
+}
+
+// NATSConfig configures the NATS publisher
+type NATSConfig struct {
+	URL      string `yaml:"url"`     // e.g. nats://nats:4222
+	Subject  string `yaml:"subject"` // e.g. netspec.events
+	TokenEnv string `yaml:"token_env,omitempty"`
+}
+
+// KafkaRESTConfig configures publishing to Kafka through a REST Proxy
+type KafkaRESTConfig struct {
+	RESTURL string `yaml:"rest_url"` // Confluent-compatible REST Proxy, e.g. http://kafka-rest:8082
+	Topic   string `yaml:"topic"`
+}
+
+// InfluxExporterConfig configures the line-protocol exporter. Setting Bucket
+// selects the InfluxDB v2 write API, otherwise Database selects the v1 API.
+type InfluxExporterConfig struct {
+	URL         string        `yaml:"url"`
+	Org         string        `yaml:"org,omitempty"`
+	Bucket      string        `yaml:"bucket,omitempty"`
+	Database    string        `yaml:"database,omitempty"`
+	TokenEnv    string        `yaml:"token_env,omitempty"`
+	Interval    time.Duration `yaml:"interval,omitempty"`    // default 1m
+	Measurement string        `yaml:"measurement,omitempty"` // prefix, default "netspec"
 }
 
 // DeviceConfig defines a device to monitor
 type DeviceConfig struct {
 	Address       string                 `yaml:"address"`
 	Description   string                 `yaml:"description,omitempty"`
+	Site          string                 `yaml:"site,omitempty"`
+	Tags          []string               `yaml:"tags,omitempty"`
+	CaptureFile   string                 `yaml:"capture_file,omitempty"` // record received notifications for `netspec replay`
 	CredentialsRef string                `yaml:"credentials_ref,omitempty"`
+	Port          int                    `yaml:"port,omitempty"` // gNMI port, overriding the global gnmi_port
+	TLS           *TLSConfig             `yaml:"tls,omitempty"` // gNMI over TLS, overriding the global tls
+	// CollectionMethod is "subscribe" (default) to stream state, or "poll"
+	// for devices that handle Subscribe poorly: their state is read with a
+	// gNMI Get every collection_interval instead
+	CollectionMethod string              `yaml:"collection_method,omitempty"`
+	Profile       string                 `yaml:"profile,omitempty"` // platform profile in gnmi_sessions, for its session limit
+	MgmtChecks    *MgmtChecksConfig      `yaml:"mgmt_checks,omitempty"`
+	Licenses      *LicenseConfig         `yaml:"licenses,omitempty"` // license expiry checks, overriding the global ones
+	ComplianceAlert *ComplianceAlertConfig `yaml:"compliance_alert,omitempty"` // compliance rollup alert, overriding the global one
+	// DesiredState is "online" (default) or "offline" for devices that are
+	// expected to be unreachable, such as a seasonal site or a cold spare;
+	// their interfaces are not evaluated and coming online raises an alert
+	DesiredState  string                 `yaml:"desired_state,omitempty"`
+	Monitoring    *MonitoringSchedule    `yaml:"monitoring,omitempty"` // when the device is monitored; always if unset
+	DependsOn     *DependencyConfig      `yaml:"depends_on,omitempty"` // upstream devices it reaches the network through
+	IgnoreInterfaces IgnoreRules         `yaml:"ignore_interfaces,omitempty"` // in addition to the global rules
+	MemberGroups  map[string][]string    `yaml:"member_groups,omitempty"` // overriding global member_groups of the same name
 	Interfaces    map[string]InterfaceConfig `yaml:"interfaces,omitempty"`
 }
 
+// ExpectedOffline reports whether the device's desired state is offline
+func (d DeviceConfig) ExpectedOffline() bool {
+	return d.DesiredState == "offline"
+}
+
+// Collection methods
+const (
+	CollectionSubscribe = "subscribe"
+	CollectionPoll      = "poll"
+)
+
+// Polled reports whether the device's state is read with periodic gNMI Get
+// rather than a subscription
+func (d DeviceConfig) Polled() bool {
+	return d.CollectionMethod == CollectionPoll
+}
+
+// MgmtChecksConfig enables auxiliary management-plane checks for a device,
+// shown as badges on its device page
+type MgmtChecksConfig struct {
+	DNS      bool          `yaml:"dns,omitempty"`       // resolve the address (hostnames only)
+	SSH      bool          `yaml:"ssh,omitempty"`       // TCP connect to ssh_port
+	SSHPort  int           `yaml:"ssh_port,omitempty"`  // default 22
+	HTTPS    bool          `yaml:"https,omitempty"`     // GET https_url
+	HTTPSURL string        `yaml:"https_url,omitempty"` // default https://<address>/
+	Interval time.Duration `yaml:"interval,omitempty"`  // default 1m
+}
+
 // InterfaceConfig defines interface monitoring requirements
 type InterfaceConfig struct {
 	Description   string            `yaml:"description,omitempty"`
-	DesiredState  string            `yaml:"desired_state"` // "up" or "down"
+	Role          string            `yaml:"role,omitempty"` // global roles entry supplying defaults for unset fields
+	DesiredState  string            `yaml:"desired_state"` // "up" or "down"; the default when a schedule is set
+	Schedule      []ScheduledState  `yaml:"schedule,omitempty"` // time-of-day desired states, first match wins
 	AdminState    string            `yaml:"admin_state,omitempty"` // "enabled" or "disabled"
 	Members       *MemberConfig     `yaml:"members,omitempty"`
 	MemberPolicy  *MemberPolicy     `yaml:"member_policy,omitempty"`
 	Alerts        AlertSeverity     `yaml:"alerts,omitempty"`
+	RunbookURL    string            `yaml:"runbook_url,omitempty"`
+	// ExpectedDescription is the description the device itself must have
+	// configured on the interface; wrap it in slashes for a regular
+	// expression, e.g. /^UPLINK-/
+	ExpectedDescription string `yaml:"expected_description,omitempty"`
+	// TrunkAllowedVLANs is the exact set of VLANs the trunk must carry, as
+	// IDs and ranges, e.g. [10, 20, "100-110"]
+	TrunkAllowedVLANs []string `yaml:"trunk_allowed_vlans,omitempty"`
+	// RequiredACLs names the ACL sets that must be attached to the
+	// interface, checked periodically against the device's configuration
+	RequiredACLs *ACLBindings `yaml:"required_acls,omitempty"`
+	// NAC declares that the access port must enforce 802.1X and/or
+	// port-security
+	NAC *NACConfig `yaml:"nac,omitempty"`
+	// ErrorRate alerts on CRC and input errors per million packets
+	ErrorRate *ErrorRateConfig `yaml:"error_rate,omitempty"`
+	// Capacity tracks the uplink's utilization trend on the Capacity page
+	Capacity *CapacityConfig `yaml:"capacity,omitempty"`
+	// Enforce restores admin_state with a gNMI Set when the device deviates
+	// from it, e.g. a port that must stay disabled is no-shut; requires
+	// global enforcement to be enabled
+	Enforce bool `yaml:"enforce,omitempty"`
+}
+
+// DescriptionPattern returns the regular expression of an expected
+// description written as /pattern/, if it is one
+func (i InterfaceConfig) DescriptionPattern() (string, bool) {
+	d := i.ExpectedDescription
+	if len(d) >= 2 && strings.HasPrefix(d, "/") && strings.HasSuffix(d, "/") {
+		return d[1 : len(d)-1], true
+	}
+	return "", false
+}
+
+// DescriptionMatches reports whether the device's configured description
+// satisfies expected_description. Without an expectation anything matches.
+func (i InterfaceConfig) DescriptionMatches(actual string) bool {
+	if i.ExpectedDescription == "" {
+		return true
+	}
+	if pattern, ok := i.DescriptionPattern(); ok {
+		re, err := regexp.Compile(pattern)
+		return err == nil && re.MatchString(actual)
+	}
+	return strings.TrimSpace(actual) == strings.TrimSpace(i.ExpectedDescription)
 }
 
 // MemberConfig defines port-channel member requirements
 type MemberConfig struct {
 	Required []string `yaml:"required,omitempty"`
+	Groups   []string `yaml:"groups,omitempty"` // member_groups whose members are also required
 }
 
 // MemberPolicy defines port-channel member policies
@@ -83,6 +378,13 @@ type AlertSeverity struct {
 	MemberDown    string `yaml:"member_down,omitempty"`
 	ChannelDown   string `yaml:"channel_down,omitempty"`
 	AdminDown     string `yaml:"admin_down,omitempty"`
+	DescriptionMismatch string `yaml:"description_mismatch,omitempty"`
+	TrunkVLANMismatch   string `yaml:"trunk_vlan_mismatch,omitempty"`
+	ACLMissing          string `yaml:"acl_missing,omitempty"`
+	NACDisabled         string `yaml:"nac_disabled,omitempty"`
+	ErrorRate           string `yaml:"error_rate,omitempty"`
+	TrafficAnomaly      string `yaml:"traffic_anomaly,omitempty"`
+	CapacityForecast    string `yaml:"capacity_forecast,omitempty"`
 }
 
 // AlertConfig defines alert routing and behavior
@@ -94,10 +396,45 @@ type AlertConfig struct {
 
 // ChannelConfig defines a notification channel
 type ChannelConfig struct {
-	Type           string   `yaml:"type"`
+	Type           string   `yaml:"type"` // "apprise", "webhook", "discord", "slack", "msteams", "ntfy", "gotify", "sms", "syslog", "smtp", "jira" or "servicenow"
 	URLEnv         string   `yaml:"url_env"`
+	SecretEnv      string   `yaml:"secret_env,omitempty"` // webhook HMAC signing secret, ntfy access token, Gotify app token or ticketing credentials
 	SeverityFilter []string `yaml:"severity_filter,omitempty"`
 	EscalationDelay int     `yaml:"escalation_delay,omitempty"`
+	// Apprise only: tag expression sent to the Apprise API per severity
+	// ("default" for the rest), so one Apprise config key can notify a
+	// different subset of its endpoints for each severity
+	Tags           map[string]string `yaml:"tags,omitempty"`
+	AttachSnapshot bool              `yaml:"attach_snapshot,omitempty"` // Apprise only: attach a PNG of the device's interface states
+	// ntfy and Gotify: push priority per severity, overriding the default
+	// mapping by severity rank
+	Priorities map[string]int `yaml:"priorities,omitempty"`
+	SMS        *SMSConfig     `yaml:"sms,omitempty"` // sms only
+	SMTP       *SMTPConfig    `yaml:"smtp,omitempty"` // smtp only
+	Ticket     *TicketConfig  `yaml:"ticket,omitempty"` // jira and servicenow only
+	Webhook    *WebhookConfig `yaml:"webhook,omitempty"` // webhook only
+	// QuietHours holds back all but the most severe notifications during
+	// its windows and sends them as one digest when they end
+	QuietHours *QuietHoursConfig `yaml:"quiet_hours,omitempty"`
+}
+
+// SMTPConfig configures an smtp channel. url_env holds the relay as
+// smtp://[user@]host[:port] (port 25 by default) and secret_env, if set,
+// the user's password; STARTTLS is used when the relay offers it.
+type SMTPConfig struct {
+	From string   `yaml:"from"`
+	To   []string `yaml:"to"`
+}
+
+// SMSConfig configures an sms channel. For Twilio, url_env holds the
+// account's Messages endpoint
+// (https://api.twilio.com/2010-04-01/Accounts/<SID>/Messages.json) and
+// secret_env the auth token; a generic HTTP gateway receives a JSON POST of
+// from, to and message, with secret_env sent as a bearer token.
+type SMSConfig struct {
+	Provider string   `yaml:"provider"` // "twilio" (default) or "http"
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
 }
 
 // AlertRule defines routing rules for alerts
@@ -124,6 +461,12 @@ type StatePersistence struct {
 	Enabled  bool   `yaml:"enabled"`
 	Path     string `yaml:"path"`
 	OnRestart string `yaml:"on_restart"` // "warn_unknown" or "silent"
+	// Every alert fire, acknowledgement, escalation and resolution is
+	// appended to HistoryPath, a JSON Lines file rather than a SQLite
+	// database (default alert-history.jsonl next to Path), and kept for
+	// HistoryRetention (default 30d), compacted hourly
+	HistoryPath      string        `yaml:"history_path,omitempty"`
+	HistoryRetention time.Duration `yaml:"history_retention,omitempty"`
 }
 
 // MaintenanceWindow defines maintenance window configuration
@@ -141,4 +484,11 @@ type Schedule struct {
 	Start    string `yaml:"start"`
 	End      string `yaml:"end"`
 	Timezone string `yaml:"timezone,omitempty"`
+	Calendar string `yaml:"calendar,omitempty"` // holiday calendar from calendars.yaml
+
+	holidays *Calendar      // resolved from Calendar when the config is loaded
+	loc      *time.Location // resolved from Timezone when the config is loaded
+	// start and end of a one-time schedule, parsed when the config is
+	// loaded or the window created
+	start, end time.Time
 }
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseVLANs expands VLAN IDs and ranges ("10", "100-110", "200..210") into
+// a sorted, de-duplicated list
+func ParseVLANs(items []string) ([]int, error) {
+	set := make(map[int]bool)
+	for _, item := range items {
+		for _, part := range strings.Split(item, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			lo, hi, isRange := strings.Cut(strings.Replace(part, "..", "-", 1), "-")
+			first, err := parseVLANID(lo)
+			if err != nil {
+				return nil, err
+			}
+			last := first
+			if isRange {
+				if last, err = parseVLANID(hi); err != nil {
+					return nil, err
+				}
+				if last < first {
+					return nil, fmt.Errorf("VLAN range %q is reversed", part)
+				}
+			}
+			for id := first; id <= last; id++ {
+				set[id] = true
+			}
+		}
+	}
+	vlans := make([]int, 0, len(set))
+	for id := range set {
+		vlans = append(vlans, id)
+	}
+	sort.Ints(vlans)
+	return vlans, nil
+}
+
+func parseVLANID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || id < 1 || id > 4094 {
+		return 0, fmt.Errorf("invalid VLAN ID %q", s)
+	}
+	return id, nil
+}
+
+// FormatVLANs renders a sorted VLAN list compactly, collapsing runs into
+// ranges: "10,20,100-110"
+func FormatVLANs(vlans []int) string {
+	var parts []string
+	for i := 0; i < len(vlans); {
+		j := i
+		for j+1 < len(vlans) && vlans[j+1] == vlans[j]+1 {
+			j++
+		}
+		if j == i {
+			parts = append(parts, strconv.Itoa(vlans[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", vlans[i], vlans[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// AssertsTrunkVLANs reports whether any interface of the device declares
+// trunk_allowed_vlans, so its collector must subscribe to switched-vlan state
+func (d DeviceConfig) AssertsTrunkVLANs() bool {
+	for _, ifCfg := range d.Interfaces {
+		if len(ifCfg.TrunkAllowedVLANs) > 0 {
+			return true
+		}
+	}
+	return false
+}
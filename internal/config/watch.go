@@ -0,0 +1,135 @@
+package config
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// watchPollInterval is how often a Watcher scans the config directory
+const watchPollInterval = 2 * time.Second
+
+// fileStamp identifies a version of a watched file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// FileSnapshot is the state of the config files at one moment
+type FileSnapshot map[string]fileStamp
+
+// Watcher notices changes to the YAML files under a config directory,
+// including include targets and overlay directories below it. It polls
+// rather than subscribing to file system events, which also sees files
+// replaced through symlinks, as in a Kubernetes ConfigMap mount.
+//
+// Reloads from elsewhere, such as the API after an adopt, report the files
+// they read with Scan and Loaded, so the watcher does not reload those
+// changes a second time. A nil Watcher does nothing.
+type Watcher struct {
+	dir      string
+	debounce time.Duration
+
+	mu     sync.Mutex
+	loaded FileSnapshot // the files as last loaded or seen
+	seenAt time.Time    // when a change not yet loaded was last seen
+}
+
+// NewWatcher creates a watcher for dir that waits until changed files have
+// been left alone for debounce, so a push of several files reloads once
+func NewWatcher(dir string, debounce time.Duration) *Watcher {
+	return &Watcher{dir: dir, debounce: debounce, loaded: scanConfigFiles(dir)}
+}
+
+// Run calls onChange once for every settled change until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context, onChange func()) {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			current := scanConfigFiles(w.dir)
+			w.mu.Lock()
+			settled := false
+			if !sameFiles(w.loaded, current) {
+				w.loaded = current
+				w.seenAt = now
+			} else if !w.seenAt.IsZero() && now.Sub(w.seenAt) >= w.debounce {
+				w.seenAt = time.Time{}
+				settled = true
+			}
+			w.mu.Unlock()
+			// onChange reloads, which calls Loaded
+			if settled {
+				onChange()
+			}
+		}
+	}
+}
+
+// Scan returns the current state of the config files. A reload takes it
+// before reading the config and passes it to Loaded once it succeeds.
+func (w *Watcher) Scan() FileSnapshot {
+	if w == nil {
+		return nil
+	}
+	return scanConfigFiles(w.dir)
+}
+
+// Loaded records the files a successful reload read, dropping any pending
+// change they include. Files changed since the snapshot are still seen.
+func (w *Watcher) Loaded(snapshot FileSnapshot) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.loaded = snapshot
+	w.seenAt = time.Time{}
+}
+
+// scanConfigFiles stamps every YAML file under dir. Files are stat'ed
+// through symlinks; directories whose names start with "..", the
+// timestamped targets of a ConfigMap mount, are not descended into since
+// the files are seen through their links.
+func scanConfigFiles(dir string) FileSnapshot {
+	stamps := make(FileSnapshot)
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), "..") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+	return stamps
+}
+
+// sameFiles reports whether two snapshots hold the same files unchanged
+func sameFiles(a, b FileSnapshot) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		if other, ok := b[path]; !ok || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
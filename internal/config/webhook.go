@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+)
+
+// WebhookConfig shapes the requests of a webhook channel, for receivers
+// such as internal ticketing systems that expect their own payload rather
+// than NetSpec's. Requests are still signed when secret_env is set.
+type WebhookConfig struct {
+	// Template is a text/template rendered against the alert as the request
+	// body, in place of the default JSON payload
+	Template    string `yaml:"template,omitempty"`
+	ContentType string `yaml:"content_type,omitempty"` // default application/json
+	// Headers are set on every request; HeaderEnv sets headers from
+	// environment variables, for tokens kept out of the configuration
+	Headers   map[string]string `yaml:"headers,omitempty"`
+	HeaderEnv map[string]string `yaml:"header_env,omitempty"`
+	// Retry resends a notification the receiver failed with a 5xx or 429
+	// response, or did not answer; without it each is sent once
+	Retry *WebhookRetryConfig `yaml:"retry,omitempty"`
+}
+
+// WebhookRetryConfig sets how a webhook notification is retried: after
+// backoff, doubling with each attempt up to max_backoff
+type WebhookRetryConfig struct {
+	Attempts   int           `yaml:"attempts,omitempty"`    // including the first, default 5
+	Backoff    time.Duration `yaml:"backoff,omitempty"`     // before the first retry, default 1s
+	MaxBackoff time.Duration `yaml:"max_backoff,omitempty"` // default 1m
+}
+
+// WithDefaults returns the retry settings with defaults applied
+func (c WebhookRetryConfig) WithDefaults() WebhookRetryConfig {
+	if c.Attempts == 0 {
+		c.Attempts = 5
+	}
+	if c.Backoff == 0 {
+		c.Backoff = time.Second
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = time.Minute
+	}
+	return c
+}
+
+// Delay returns the backoff before the given retry, counted from 1
+func (c WebhookRetryConfig) Delay(retry int) time.Duration {
+	delay := c.Backoff
+	for i := 1; i < retry && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, c.MaxBackoff)
+}
+
+// webhookFuncs are the functions available to webhook templates; json
+// writes a value as JSON, such as a message as a quoted, escaped string
+var webhookFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseTemplate parses the body template; fields missing from the data
+// render as their zero value
+func (c *WebhookConfig) ParseTemplate(name string) (*template.Template, error) {
+	return template.New(name).Funcs(webhookFuncs).Option("missingkey=zero").Parse(c.Template)
+}
+
+// validateWebhook checks a webhook channel's template parses and its
+// headers and retry settings are usable
+func validateWebhook(channel ChannelConfig) error {
+	w := channel.Webhook
+	if w == nil {
+		return nil
+	}
+	if w.Template != "" {
+		if _, err := w.ParseTemplate("webhook"); err != nil {
+			return fmt.Errorf("webhook.template: %w", err)
+		}
+	}
+	for _, headers := range []map[string]string{w.Headers, w.HeaderEnv} {
+		for name := range headers {
+			if http.CanonicalHeaderKey(name) == "Content-Type" {
+				return fmt.Errorf("webhook: set the Content-Type header with content_type")
+			}
+		}
+	}
+	if r := w.Retry; r != nil {
+		if r.Attempts < 0 || r.Backoff < 0 || r.MaxBackoff < 0 {
+			return fmt.Errorf("webhook.retry: attempts, backoff and max_backoff must not be negative")
+		}
+		if d := r.WithDefaults(); d.MaxBackoff < d.Backoff {
+			return fmt.Errorf("webhook.retry: max_backoff must not be shorter than backoff")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Package enforcer implements closed-loop mode: when an admin state alert
+// fires on an interface with enforce: true, its declared admin_state is
+// restored with a gNMI Set. Every action,
+// issued, dry run or held back by the cooldown, is audit logged.
+package enforcer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+	"github.com/rs/zerolog"
+)
+
+// adminAlertType is the evaluator's alert type for an interface whose admin
+// state differs from admin_state
+const adminAlertType = "interface_admin_down"
+
+// maxRecent is how many actions are kept for the API
+const maxRecent = 200
+
+// Action results
+const (
+	ResultOK      = "ok"
+	ResultFailed  = "failed"
+	ResultDryRun  = "dry_run"
+	ResultSkipped = "skipped"
+)
+
+// AdminSetter sets an interface's admin state on a device
+type AdminSetter interface {
+	SetInterfaceEnabled(ifaceName string, enabled bool) error
+}
+
+// SetterFunc returns the admin setter of a device, or nil when it has no
+// collector
+type SetterFunc func(device string) AdminSetter
+
+// Action is one attempt to restore an interface's admin state
+type Action struct {
+	At        time.Time `json:"at"`
+	AlertID   string    `json:"alert_id"`
+	Device    string    `json:"device"`
+	Interface string    `json:"interface"`
+	Observed  string    `json:"observed"` // admin state seen on the device
+	Restored  string    `json:"restored"` // declared admin state set
+	Result    string    `json:"result"`   // ok, failed, dry_run or skipped
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// Enforcer restores declared admin states
+type Enforcer struct {
+	mu       sync.Mutex
+	cfg      *config.Config
+	logger   zerolog.Logger
+	setter   SetterFunc
+	onAction func(Action)
+	last     map[string]time.Time // device|interface -> last Set issued
+	recent   []Action             // oldest first
+}
+
+// New creates an enforcer for the given configuration
+func New(cfg *config.Config, logger zerolog.Logger) *Enforcer {
+	return &Enforcer{
+		cfg:    cfg,
+		logger: logger,
+		last:   make(map[string]time.Time),
+	}
+}
+
+// SetConfig swaps in a reloaded configuration
+func (e *Enforcer) SetConfig(cfg *config.Config) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cfg = cfg
+}
+
+// SetSetter registers the source of device admin setters
+func (e *Enforcer) SetSetter(fn SetterFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.setter = fn
+}
+
+// SetActionHook registers a function receiving every action once it
+// completes or is skipped
+func (e *Enforcer) SetActionHook(fn func(Action)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onAction = fn
+}
+
+// Config returns the enforcement settings; the zero value when not enabled
+func (e *Enforcer) Config() config.EnforcementConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if enforcement := e.cfg.DesiredState.Global.Enforcement; enforcement != nil {
+		return *enforcement
+	}
+	return config.EnforcementConfig{}
+}
+
+// Recent returns the latest actions, newest first
+func (e *Enforcer) Recent() []Action {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	recent := make([]Action, len(e.recent))
+	for i, a := range e.recent {
+		recent[len(e.recent)-1-i] = a
+	}
+	return recent
+}
+
+// Handle is an alert lifecycle hook: when an admin state alert fires on an
+// enforced interface, the declared state is restored in the background,
+// unless the device is in a maintenance window or the interface is in its
+// cooldown. It never blocks.
+func (e *Enforcer) Handle(event string, alert types.Alert) {
+	if event != "fired" || alert.AlertType != adminAlertType {
+		return
+	}
+	e.mu.Lock()
+	if !e.cfg.DesiredState.Enforced(alert.Device, alert.Entity) {
+		e.mu.Unlock()
+		return
+	}
+	enforcement := *e.cfg.DesiredState.Global.Enforcement
+	desired := e.cfg.DesiredState.Devices[alert.Device].Interfaces[alert.Entity].AdminState
+
+	now := time.Now()
+	action := Action{
+		At:        now,
+		AlertID:   alert.ID,
+		Device:    alert.Device,
+		Interface: alert.Entity,
+		Observed:  alert.RelatedState["actual_admin"],
+		Restored:  desired,
+	}
+	key := alert.Device + "|" + alert.Entity
+	cooldown := enforcement.CooldownOrDefault()
+	switch last, ok := e.last[key]; {
+	case alert.Maintenance != "":
+		action.Result = ResultSkipped
+		action.Detail = "in maintenance: " + alert.Maintenance
+	case ok && now.Sub(last) < cooldown:
+		action.Result = ResultSkipped
+		action.Detail = fmt.Sprintf("in cooldown until %s", last.Add(cooldown).Format(time.RFC3339))
+	case enforcement.DryRun:
+		action.Result = ResultDryRun
+		action.Detail = "would set admin state " + desired
+	default:
+		e.last[key] = now
+	}
+	setter := e.setter
+	e.mu.Unlock()
+
+	// Handle runs inside the alert engine's lock, which the action hook may
+	// need, so every action finishes in the background
+	go func() {
+		if action.Result == "" {
+			var s AdminSetter
+			if setter != nil {
+				s = setter(action.Device)
+			}
+			switch {
+			case s == nil:
+				action.Result, action.Detail = ResultFailed, "no collector for "+action.Device
+			default:
+				if err := s.SetInterfaceEnabled(action.Interface, desired == "enabled"); err != nil {
+					action.Result, action.Detail = ResultFailed, err.Error()
+				} else {
+					action.Result, action.Detail = ResultOK, "admin state set to "+desired
+				}
+			}
+		}
+		e.finish(action)
+	}()
+}
+
+// finish audit logs an action, keeps it for the API and reports it
+func (e *Enforcer) finish(action Action) {
+	e.logger.Info().
+		Str("component", "audit").
+		Str("action", "enforce_admin_state").
+		Str("device", action.Device).
+		Str("interface", action.Interface).
+		Str("observed", action.Observed).
+		Str("restored", action.Restored).
+		Str("result", action.Result).
+		Str("detail", action.Detail).
+		Msg("Admin state enforcement " + action.Result)
+
+	e.mu.Lock()
+	e.recent = append(e.recent, action)
+	if len(e.recent) > maxRecent {
+		e.recent = e.recent[len(e.recent)-maxRecent:]
+	}
+	onAction := e.onAction
+	e.mu.Unlock()
+	if onAction != nil {
+		onAction(action)
+	}
+}
@@ -0,0 +1,176 @@
+// Package enrichment annotates firing alerts from an external HTTP
+// endpoint, such as a CMDB or IPAM lookup, so notifications carry circuit
+// IDs and contacts. Lookups are cached per device and entity, and a failed
+// lookup pauses all lookups for a minute.
+package enrichment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// maxResponse bounds the endpoint's response body
+const maxResponse = 64 * 1024
+
+// failureTTL is how long a failed lookup is remembered, and how long all
+// lookups pause after one fails, so an endpoint that is down is not called
+// for every alert
+const failureTTL = time.Minute
+
+// request is the body POSTed to the endpoint
+type request struct {
+	Device    string `json:"device"`
+	Entity    string `json:"entity"`
+	AlertType string `json:"alert_type"`
+	Severity  string `json:"severity"`
+	Site      string `json:"site,omitempty"`
+}
+
+type cacheEntry struct {
+	values  map[string]string
+	expires time.Time
+}
+
+// Client looks up alert annotations
+type Client struct {
+	mu     sync.Mutex
+	cfg    *config.Config
+	logger zerolog.Logger
+	client *http.Client
+	cache  map[string]cacheEntry // device|entity -> annotations
+	// pausedUntil is when lookups resume after a failure
+	pausedUntil time.Time
+}
+
+// New creates a client for the given configuration
+func New(cfg *config.Config, logger zerolog.Logger) *Client {
+	return &Client{
+		cfg:    cfg,
+		logger: logger.With().Str("component", "enrichment").Logger(),
+		client: &http.Client{},
+		cache:  make(map[string]cacheEntry),
+	}
+}
+
+// SetConfig swaps in a reloaded configuration and clears the cache
+func (c *Client) SetConfig(cfg *config.Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+	c.cache = make(map[string]cacheEntry)
+	c.pausedUntil = time.Time{}
+}
+
+// Lookup returns the annotations for an alert, from the cache when fresh.
+// It returns nil when enrichment is off for the alert type or the lookup
+// fails; failures are logged and never hold up the alert beyond the
+// timeout. After a failure, lookups for every alert return nil without
+// calling the endpoint until failureTTL has passed.
+func (c *Client) Lookup(device, entity, alertType, severity string) map[string]string {
+	c.mu.Lock()
+	cfg := c.cfg.Alerts.Enrichment
+	site := c.cfg.DesiredState.Devices[device].Site
+	if !cfg.Applies(alertType) {
+		c.mu.Unlock()
+		return nil
+	}
+	key := device + "|" + entity
+	now := time.Now()
+	if entry, ok := c.cache[key]; ok && now.Before(entry.expires) {
+		c.mu.Unlock()
+		return entry.values
+	}
+	if now.Before(c.pausedUntil) {
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Unlock()
+
+	timeout, cacheTTL := cfg.Limits()
+	values, err := c.fetch(cfg, timeout, request{
+		Device:    device,
+		Entity:    entity,
+		AlertType: alertType,
+		Severity:  severity,
+		Site:      site,
+	})
+	ttl := cacheTTL
+	if err != nil {
+		c.logger.Warn().Err(err).Str("device", device).Str("entity", entity).Dur("paused_for", failureTTL).Msg("Alert enrichment lookup failed, pausing lookups")
+		if failureTTL < ttl {
+			ttl = failureTTL
+		}
+	}
+
+	c.mu.Lock()
+	c.cache[key] = cacheEntry{values: values, expires: now.Add(ttl)}
+	if err != nil {
+		c.pausedUntil = time.Now().Add(failureTTL)
+	}
+	c.mu.Unlock()
+	return values
+}
+
+// fetch calls the endpoint and reads the flat JSON object it returns.
+// Values that are not strings are formatted; nested objects and arrays are
+// kept as JSON.
+func (c *Client) fetch(cfg config.EnrichmentConfig, timeout time.Duration, req request) (map[string]string, error) {
+	url := os.Getenv(cfg.URLEnv)
+	if url == "" {
+		return nil, fmt.Errorf("%s is not set", cfg.URLEnv)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if cfg.SecretEnv != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+os.Getenv(cfg.SecretEnv))
+	}
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil // nothing known about the entity
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+
+	var raw map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponse)).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	values := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			values[k] = v
+		case map[string]interface{}, []interface{}:
+			encoded, _ := json.Marshal(v)
+			values[k] = string(encoded)
+		default:
+			values[k] = fmt.Sprint(v)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,47 @@
+package enrichment
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/rs/zerolog"
+)
+
+func TestLookupPausesAfterFailure(t *testing.T) {
+	calls := 0
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"circuit_id": "CID-1"}`))
+	}))
+	defer srv.Close()
+	t.Setenv("NETSPEC_TEST_ENRICHMENT_URL", srv.URL)
+
+	cfg := &config.Config{}
+	cfg.Alerts.Enrichment = config.EnrichmentConfig{URLEnv: "NETSPEC_TEST_ENRICHMENT_URL"}
+	c := New(cfg, zerolog.Nop())
+
+	if got := c.Lookup("sw1", "Ethernet1", "interface_state_mismatch", "critical"); got != nil {
+		t.Fatalf("got %v from a failing endpoint", got)
+	}
+	// Other entities are not looked up while paused
+	fail = false
+	if got := c.Lookup("sw2", "Ethernet1", "interface_state_mismatch", "critical"); got != nil {
+		t.Fatalf("got %v while paused", got)
+	}
+	if calls != 1 {
+		t.Fatalf("%d calls to the endpoint, want 1", calls)
+	}
+
+	// A reload resumes lookups
+	c.SetConfig(cfg)
+	if got := c.Lookup("sw2", "Ethernet1", "interface_state_mismatch", "critical"); got["circuit_id"] != "CID-1" {
+		t.Fatalf("got %v, want circuit_id CID-1", got)
+	}
+}
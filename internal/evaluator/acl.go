@@ -0,0 +1,100 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// AlertTypeACLMissing fires when an interface lacks an ACL set listed in
+// its required_acls, including when the set was replaced by one with
+// another name
+const AlertTypeACLMissing = "interface_acl_missing"
+
+// EvaluateACLs compares the ACL sets attached on a device, as read from its
+// configuration, with each interface's required_acls. It returns a state
+// change for every interface whose finding is new or differs from the
+// previous call, and the interfaces that have become compliant since.
+func (e *Evaluator) EvaluateACLs(deviceName string, observed map[string]config.ACLBindings) (changes []StateChange, cleared []string) {
+	cfg := e.Config()
+	if cfg == nil {
+		return nil, nil
+	}
+	deviceCfg := cfg.DesiredState.Devices[deviceName]
+	now := time.Now()
+
+	audited := make(map[string]bool)
+	for ifaceName, ifCfg := range deviceCfg.Interfaces {
+		if ifCfg.RequiredACLs == nil || deviceCfg.ExpectedOffline() {
+			continue
+		}
+		audited[ifaceName] = true
+
+		attached := observed[ifaceName]
+		related := make(map[string]string)
+		var problems []string
+		for _, dir := range []struct {
+			name               string
+			required, attached []string
+		}{
+			{"ingress", ifCfg.RequiredACLs.Ingress, attached.Ingress},
+			{"egress", ifCfg.RequiredACLs.Egress, attached.Egress},
+		} {
+			missing := missingNames(dir.required, dir.attached)
+			if len(missing) == 0 {
+				continue
+			}
+			problem := fmt.Sprintf("%s ACL %s missing", dir.name, strings.Join(missing, ", "))
+			if len(dir.attached) > 0 {
+				problem += fmt.Sprintf(" (attached: %s)", strings.Join(dir.attached, ", "))
+			}
+			problems = append(problems, problem)
+			related["required_"+dir.name] = strings.Join(dir.required, ",")
+			related["attached_"+dir.name] = strings.Join(dir.attached, ",")
+			related["missing_"+dir.name] = strings.Join(missing, ",")
+		}
+
+		var message string
+		if len(problems) > 0 {
+			message = fmt.Sprintf("interface %s: %s", ifaceName, strings.Join(problems, "; "))
+		}
+		changed, wasCleared := e.recordFinding(findingKey(deviceName, AlertTypeACLMissing, ifaceName), message)
+		if wasCleared {
+			cleared = append(cleared, ifaceName)
+		}
+		if !changed {
+			continue
+		}
+		changes = append(changes, StateChange{
+			Device:       deviceName,
+			Interface:    ifaceName,
+			AlertType:    AlertTypeACLMissing,
+			Severity:     severityForAlert(ifCfg, "acl_missing", "critical"),
+			Firing:       true,
+			Message:      message,
+			RelatedState: related,
+			ObservedAt:   now,
+		})
+	}
+
+	// Interfaces that no longer require ACLs clear their findings
+	cleared = append(cleared, e.sweepFindings(deviceName, AlertTypeACLMissing, audited)...)
+	return changes, cleared
+}
+
+// missingNames returns the required names absent from attached
+func missingNames(required, attached []string) []string {
+	have := make(map[string]bool, len(attached))
+	for _, name := range attached {
+		have[name] = true
+	}
+	var missing []string
+	for _, name := range required {
+		if !have[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
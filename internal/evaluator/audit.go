@@ -0,0 +1,50 @@
+package evaluator
+
+import "strings"
+
+// Periodic configuration audits, such as ACL attachments and NAC
+// enforcement, re-read the same configuration every cycle. Their findings
+// are tracked per device|alert type|interface so that a finding is only
+// reported when it is new or changed, and its clearing only once.
+
+// findingKey builds the key of an audit finding
+func findingKey(deviceName, alertType, ifaceName string) string {
+	return deviceName + "|" + alertType + "|" + ifaceName
+}
+
+// recordFinding stores the current finding for key, "" meaning compliant.
+// It reports whether the finding is new or changed, and whether a previous
+// finding has cleared.
+func (e *Evaluator) recordFinding(key, finding string) (changed, cleared bool) {
+	e.findingsMu.Lock()
+	defer e.findingsMu.Unlock()
+	if e.findings == nil {
+		e.findings = make(map[string]string)
+	}
+	previous, known := e.findings[key]
+	if finding == "" {
+		if known {
+			delete(e.findings, key)
+		}
+		return false, known
+	}
+	e.findings[key] = finding
+	return finding != previous, false
+}
+
+// sweepFindings clears the findings of a device and alert type whose
+// interfaces were not audited this cycle, returning those interfaces
+func (e *Evaluator) sweepFindings(deviceName, alertType string, audited map[string]bool) []string {
+	e.findingsMu.Lock()
+	defer e.findingsMu.Unlock()
+	prefix := findingKey(deviceName, alertType, "")
+	var cleared []string
+	for key := range e.findings {
+		ifaceName := strings.TrimPrefix(key, prefix)
+		if strings.HasPrefix(key, prefix) && !audited[ifaceName] {
+			delete(e.findings, key)
+			cleared = append(cleared, ifaceName)
+		}
+	}
+	return cleared
+}
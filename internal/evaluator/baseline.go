@@ -0,0 +1,144 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// octetCounters are the counters the traffic baseline learns from, by
+// direction
+var octetCounters = [2]string{"in-octets", "out-octets"}
+
+var directionNames = [2]string{"receive", "transmit"}
+
+// ewma is an exponentially weighted moving mean and variance of a bit rate,
+// plus the anomaly state judged against it
+type ewma struct {
+	mean, variance float64
+	samples        int
+	anomalous      int // consecutive anomalous samples
+	alerting       bool
+}
+
+// update folds a sample into the mean and variance
+func (w *ewma) update(x, alpha float64) {
+	if w.samples == 0 {
+		w.mean = x
+	} else {
+		diff := x - w.mean
+		incr := alpha * diff
+		w.mean += incr
+		w.variance = (1 - alpha) * (w.variance + diff*incr)
+	}
+	w.samples++
+}
+
+// octetBaseline is one direction's last counter reading and learned rate
+type octetBaseline struct {
+	octets uint64
+	at     time.Time
+	rate   ewma
+}
+
+// octetRates turns the octet counters a notification carried into bit rates
+// per direction. A direction has no rate on its first reading, an
+// out-of-order sample or a counter reset.
+func (h *counterHistory) octetRates(values map[string]uint64, at time.Time) (rates [2]float64, ok [2]bool) {
+	for dir, leaf := range octetCounters {
+		octets, seen := values[leaf]
+		if !seen {
+			continue
+		}
+		b := &h.baselines[dir]
+		prevOctets, prevAt := b.octets, b.at
+		b.octets, b.at = octets, at
+		if prevAt.IsZero() || !at.After(prevAt) || octets < prevOctets {
+			continue
+		}
+		rates[dir] = float64(octets-prevOctets) * 8 / at.Sub(prevAt).Seconds()
+		ok[dir] = true
+	}
+	return rates, ok
+}
+
+// evaluateBaseline judges each direction's bit rate against its learned
+// baseline. A direction fires once after staying anomalous for the sustain
+// count, and re-arms once back within its baseline.
+func (e *Evaluator) evaluateBaseline(deviceName, ifaceName string, ifCfg config.InterfaceConfig, bc config.TrafficBaselineConfig, hist *counterHistory, rates [2]float64, ok [2]bool) []StateChange {
+	var changes []StateChange
+	for dir := range octetCounters {
+		if !ok[dir] {
+			continue
+		}
+		rate := rates[dir]
+
+		w := &hist.baselines[dir].rate
+		anomalous := false
+		if w.samples >= bc.Warmup && w.mean >= bc.MinBPS {
+			stddev := math.Sqrt(w.variance)
+			anomalous = rate == 0 || (stddev > 0 && math.Abs(rate-w.mean) > bc.Deviation*stddev)
+		}
+		if !anomalous {
+			w.anomalous = 0
+			w.alerting = false
+		} else {
+			w.anomalous++
+			if w.anomalous >= bc.Sustain && !w.alerting {
+				w.alerting = true
+				changes = append(changes, baselineChange(deviceName, ifaceName, ifCfg, dir, rate, *w))
+			}
+		}
+		// Samples still being confirmed as anomalous are not learned, or a
+		// single outlier would widen the deviation enough to hide the rest.
+		// Once alerted, learning resumes so a lasting change becomes the
+		// new baseline.
+		if !anomalous || w.alerting {
+			w.update(rate, bc.Alpha)
+		}
+	}
+	return changes
+}
+
+// baselineChange builds the anomaly alert for one direction
+func baselineChange(deviceName, ifaceName string, ifCfg config.InterfaceConfig, dir int, rate float64, w ewma) StateChange {
+	stddev := math.Sqrt(w.variance)
+	what := "far below its baseline"
+	switch {
+	case rate == 0:
+		what = "dropped to zero"
+	case rate > w.mean:
+		what = "far above its baseline"
+	}
+	return StateChange{
+		Device:    deviceName,
+		Interface: ifaceName,
+		AlertType: alertTypeTrafficAnomaly,
+		Severity:  severityForAlert(ifCfg, "traffic_anomaly", "info"),
+		Firing:    true,
+		Message: fmt.Sprintf("interface %s %s traffic %s: %s (baseline %s ± %s)",
+			ifaceName, directionNames[dir], what, formatBPS(rate), formatBPS(w.mean), formatBPS(stddev)),
+		RelatedState: map[string]string{
+			"direction":    directionNames[dir],
+			"rate_bps":     strconv.FormatFloat(rate, 'f', 0, 64),
+			"baseline_bps": strconv.FormatFloat(w.mean, 'f', 0, 64),
+			"stddev_bps":   strconv.FormatFloat(stddev, 'f', 0, 64),
+		},
+	}
+}
+
+// formatBPS renders a bit rate with an SI unit
+func formatBPS(bps float64) string {
+	for _, unit := range []struct {
+		scale float64
+		name  string
+	}{{1e9, "Gbps"}, {1e6, "Mbps"}, {1e3, "kbps"}} {
+		if bps >= unit.scale {
+			return fmt.Sprintf("%.1f %s", bps/unit.scale, unit.name)
+		}
+	}
+	return fmt.Sprintf("%.0f bps", bps)
+}
@@ -0,0 +1,263 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/metrics"
+)
+
+// Eviction reasons reported in netspec_state_cache_evictions_total
+const (
+	evictTTL        = "ttl"
+	evictCapacity   = "capacity"
+	evictUndeclared = "undeclared"
+)
+
+var (
+	cacheEntries = metrics.NewGauge(
+		"netspec_state_cache_entries",
+		"Interfaces held in the evaluator state cache")
+	cacheEvictions = metrics.NewCounterVec(
+		"netspec_state_cache_evictions_total",
+		"State cache entries evicted, by reason (ttl, capacity, undeclared)",
+		"reason")
+)
+
+// deviceShard holds the cached state of one device's interfaces. Each device
+// has its own lock so a chassis streaming thousands of leaves only
+// serializes its own evaluation.
+type deviceShard struct {
+	mu       sync.RWMutex
+	states   map[string]interfaceState  // interface name -> state
+	counters map[string]*counterHistory // interface name -> counter history
+	firing   map[string]bool            // alert type|interface -> condition holds
+}
+
+// shard returns the cache shard for a device, creating it on first use
+func (e *Evaluator) shard(deviceName string) *deviceShard {
+	e.shardsMu.RLock()
+	s, ok := e.shards[deviceName]
+	e.shardsMu.RUnlock()
+	if ok {
+		return s
+	}
+
+	e.shardsMu.Lock()
+	defer e.shardsMu.Unlock()
+	if s, ok = e.shards[deviceName]; !ok {
+		s = &deviceShard{states: make(map[string]interfaceState)}
+		e.shards[deviceName] = s
+	}
+	return s
+}
+
+// lookupShard returns the cache shard for a device without creating one, so
+// queries for unknown devices do not grow the shard map
+func (e *Evaluator) lookupShard(deviceName string) (*deviceShard, bool) {
+	e.shardsMu.RLock()
+	defer e.shardsMu.RUnlock()
+	s, ok := e.shards[deviceName]
+	return s, ok
+}
+
+// allShards returns every shard keyed by device name. The shards themselves
+// must still be locked before reading them.
+func (e *Evaluator) allShards() map[string]*deviceShard {
+	e.shardsMu.RLock()
+	defer e.shardsMu.RUnlock()
+	shards := make(map[string]*deviceShard, len(e.shards))
+	for name, s := range e.shards {
+		shards[name] = s
+	}
+	return shards
+}
+
+// snapshot copies every cached state, keyed by device then interface
+func (e *Evaluator) snapshot() map[string]map[string]interfaceState {
+	shards := e.allShards()
+	cache := make(map[string]map[string]interfaceState, len(shards))
+	for deviceName, s := range shards {
+		s.mu.RLock()
+		states := make(map[string]interfaceState, len(s.states))
+		for ifaceName, state := range s.states {
+			states[ifaceName] = state
+		}
+		s.mu.RUnlock()
+		cache[deviceName] = states
+	}
+	return cache
+}
+
+// cachedStates copies every cached state into a flat slice
+func (e *Evaluator) cachedStates() []interfaceState {
+	states := make([]interfaceState, 0, e.entries.Load())
+	for _, s := range e.allShards() {
+		s.mu.RLock()
+		for _, state := range s.states {
+			states = append(states, state)
+		}
+		s.mu.RUnlock()
+	}
+	return states
+}
+
+// evictionMessages describe, by eviction reason, why the alerts of an
+// evicted interface resolve
+var evictionMessages = map[string]string{
+	evictTTL:        "interface %s stopped reporting state",
+	evictCapacity:   "interface %s evicted from the full state cache",
+	evictUndeclared: "interface %s is no longer declared",
+}
+
+// SetDeviceDownFunc registers the source of device reachability. While a
+// device is down its interfaces stop reporting because the device did, so
+// sweep leaves them to outlive the TTL rather than resolve their alerts.
+func (e *Evaluator) SetDeviceDownFunc(fn func(device string) bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deviceDown = fn
+}
+
+// RunEviction periodically drops cache entries that have not been updated
+// within the configured TTL or whose interface is no longer declared, until
+// ctx is cancelled. The resolutions of alerts firing on evicted interfaces
+// are passed to resolve.
+func (e *Evaluator) RunEviction(ctx context.Context, resolve func(StateChange)) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, change := range e.sweep(e.Config(), now) {
+				resolve(change)
+			}
+		}
+	}
+}
+
+// sweep evicts expired and undeclared entries. Entries of devices that are
+// down do not expire. Shards themselves are kept, since a collector may
+// still hold one. It locks one shard at a time, so the caller must not hold
+// any shard lock. It returns the resolutions of the alerts firing on
+// evicted interfaces.
+func (e *Evaluator) sweep(cfg *config.Config, now time.Time) []StateChange {
+	e.mu.RLock()
+	deviceDown := e.deviceDown
+	e.mu.RUnlock()
+
+	var resolved []StateChange
+	_, ttl := cfg.DesiredState.Global.StateCache.Limits()
+	for deviceName, s := range e.allShards() {
+		down := deviceDown != nil && deviceDown(deviceName)
+		s.mu.Lock()
+		for ifaceName, state := range s.states {
+			switch {
+			case !down && now.Sub(state.UpdatedAt) > ttl:
+				resolved = append(resolved, e.evict(s, deviceName, ifaceName, evictTTL)...)
+			case !isDeclared(cfg, deviceName, ifaceName):
+				resolved = append(resolved, e.evict(s, deviceName, ifaceName, evictUndeclared)...)
+			}
+		}
+		s.mu.Unlock()
+	}
+	cacheEntries.Set(float64(e.entries.Load()))
+	return resolved
+}
+
+// ensureCapacity makes room for one new entry. When the cache is full it
+// first sweeps, then evicts the least recently updated entries across all
+// devices down to 90% of the limit so the cost is amortised over many
+// inserts. The caller must not hold any shard lock. Like sweep it returns
+// the resolutions of the alerts firing on evicted interfaces.
+func (e *Evaluator) ensureCapacity(cfg *config.Config, now time.Time) []StateChange {
+	maxEntries, _ := cfg.DesiredState.Global.StateCache.Limits()
+	if e.entries.Load() < int64(maxEntries) {
+		return nil
+	}
+	resolved := e.sweep(cfg, now)
+	if e.entries.Load() < int64(maxEntries) {
+		return resolved
+	}
+
+	type entry struct {
+		shard     *deviceShard
+		device    string
+		iface     string
+		updatedAt time.Time
+	}
+	var entries []entry
+	for deviceName, s := range e.allShards() {
+		s.mu.RLock()
+		for ifaceName, state := range s.states {
+			entries = append(entries, entry{shard: s, device: deviceName, iface: ifaceName, updatedAt: state.UpdatedAt})
+		}
+		s.mu.RUnlock()
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].updatedAt.Before(entries[j].updatedAt)
+	})
+	target := maxEntries * 9 / 10
+	if len(entries) > target {
+		for _, ent := range entries[:len(entries)-target] {
+			ent.shard.mu.Lock()
+			// Skip entries refreshed since they were collected
+			if state, ok := ent.shard.states[ent.iface]; ok && state.UpdatedAt.Equal(ent.updatedAt) {
+				resolved = append(resolved, e.evict(ent.shard, ent.device, ent.iface, evictCapacity)...)
+			}
+			ent.shard.mu.Unlock()
+		}
+	}
+	cacheEntries.Set(float64(e.entries.Load()))
+
+	e.logger.Warn().
+		Int("max_entries", maxEntries).
+		Int64("entries", e.entries.Load()).
+		Msg("State cache full, evicted least recently updated interfaces")
+	return resolved
+}
+
+// evict removes one entry from a shard together with the firing flags of
+// its alerts, returning their resolutions as trackFiring would, since the
+// evaluator no longer sees the interface to clear them. The caller must
+// hold s.mu.
+func (e *Evaluator) evict(s *deviceShard, deviceName, ifaceName, reason string) []StateChange {
+	delete(s.states, ifaceName)
+	delete(s.counters, ifaceName)
+	e.entries.Add(-1)
+	cacheEvictions.With(reason).Inc()
+	return clearFiring(s, deviceName, ifaceName, fmt.Sprintf(evictionMessages[reason], ifaceName))
+}
+
+// clearFiring drops the firing flags of an interface's alerts and returns
+// their resolutions with the given message. The caller must hold s.mu.
+func clearFiring(s *deviceShard, deviceName, ifaceName, message string) []StateChange {
+	var resolved []StateChange
+	for key := range s.firing {
+		alertType, entity, _ := strings.Cut(key, "|")
+		if entity != ifaceName {
+			continue
+		}
+		delete(s.firing, key)
+		resolved = append(resolved, StateChange{
+			Device:    deviceName,
+			Interface: ifaceName,
+			AlertType: alertType,
+			Message:   message,
+		})
+	}
+	return resolved
+}
+
+// isDeclared reports whether the interface is in the desired state
+func isDeclared(cfg *config.Config, deviceName, ifaceName string) bool {
+	_, ok := cfg.DesiredState.Devices[deviceName].Interfaces[ifaceName]
+	return ok
+}
@@ -0,0 +1,142 @@
+package evaluator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/rs/zerolog"
+)
+
+func newCacheEvaluator(cache config.StateCacheConfig, devices map[string][]string) *Evaluator {
+	cfg := &config.Config{}
+	cfg.DesiredState.Global.StateCache = cache
+	cfg.DesiredState.Devices = make(map[string]config.DeviceConfig)
+	for name, ifaces := range devices {
+		dev := config.DeviceConfig{Interfaces: make(map[string]config.InterfaceConfig)}
+		for _, iface := range ifaces {
+			dev.Interfaces[iface] = config.InterfaceConfig{DesiredState: "up"}
+		}
+		cfg.DesiredState.Devices[name] = dev
+	}
+	return NewEvaluator(cfg, zerolog.Nop())
+}
+
+// cacheState adds an entry to the cache as if the interface had reported at
+// updatedAt, with the given alert types firing on it
+func cacheState(e *Evaluator, device, iface string, updatedAt time.Time, firing ...string) {
+	s := e.shard(device)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[iface] = interfaceState{Device: device, Interface: iface, UpdatedAt: updatedAt}
+	e.entries.Add(1)
+	for _, alertType := range firing {
+		if s.firing == nil {
+			s.firing = make(map[string]bool)
+		}
+		s.firing[alertType+"|"+iface] = true
+	}
+}
+
+func cached(e *Evaluator, device, iface string) bool {
+	s, ok := e.lookupShard(device)
+	if !ok {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok = s.states[iface]
+	return ok
+}
+
+func TestSweep(t *testing.T) {
+	e := newCacheEvaluator(config.StateCacheConfig{TTL: time.Hour}, map[string][]string{
+		"sw1": {"Ethernet1", "Ethernet2"},
+		"sw2": {"Ethernet1"},
+	})
+	e.SetDeviceDownFunc(func(device string) bool { return device == "sw2" })
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cacheState(e, "sw1", "Ethernet1", now.Add(-2*time.Hour), alertTypeInterfaceMismatch)
+	cacheState(e, "sw1", "Ethernet2", now.Add(-time.Minute), alertTypeInterfaceMismatch)
+	cacheState(e, "sw1", "Ethernet3", now, alertTypeInterfaceAdminDown)
+	cacheState(e, "sw2", "Ethernet1", now.Add(-48*time.Hour), alertTypeInterfaceMismatch)
+
+	resolved := e.sweep(e.Config(), now)
+
+	if cached(e, "sw1", "Ethernet1") {
+		t.Error("sw1 Ethernet1 outlived the TTL")
+	}
+	if !cached(e, "sw1", "Ethernet2") {
+		t.Error("sw1 Ethernet2 evicted within the TTL")
+	}
+	if cached(e, "sw1", "Ethernet3") {
+		t.Error("undeclared sw1 Ethernet3 kept")
+	}
+	if !cached(e, "sw2", "Ethernet1") {
+		t.Error("sw2 Ethernet1 evicted while sw2 is down")
+	}
+	if got := e.entries.Load(); got != 2 {
+		t.Errorf("%d entries, want 2", got)
+	}
+
+	want := map[string]string{
+		"sw1|Ethernet1|" + alertTypeInterfaceMismatch:  "interface Ethernet1 stopped reporting state",
+		"sw1|Ethernet3|" + alertTypeInterfaceAdminDown: "interface Ethernet3 is no longer declared",
+	}
+	if len(resolved) != len(want) {
+		t.Fatalf("got %d resolutions, want %d: %v", len(resolved), len(want), resolved)
+	}
+	for _, change := range resolved {
+		key := fmt.Sprintf("%s|%s|%s", change.Device, change.Interface, change.AlertType)
+		if msg, ok := want[key]; !ok || change.Message != msg || change.Firing {
+			t.Errorf("unexpected resolution %+v", change)
+		}
+	}
+
+	// The firing flags went with the entries
+	s, _ := e.lookupShard("sw1")
+	if s.firing[alertTypeInterfaceMismatch+"|Ethernet1"] || !s.firing[alertTypeInterfaceMismatch+"|Ethernet2"] {
+		t.Errorf("firing flags %v", s.firing)
+	}
+
+	// Once sw2 is back its stale entry expires
+	e.SetDeviceDownFunc(func(string) bool { return false })
+	resolved = e.sweep(e.Config(), now)
+	if cached(e, "sw2", "Ethernet1") || len(resolved) != 1 || resolved[0].Device != "sw2" {
+		t.Errorf("sw2 Ethernet1 not evicted after reconnecting: %v", resolved)
+	}
+}
+
+func TestEnsureCapacity(t *testing.T) {
+	ifaces := make([]string, 10)
+	for i := range ifaces {
+		ifaces[i] = fmt.Sprintf("Ethernet%d", i+1)
+	}
+	e := newCacheEvaluator(config.StateCacheConfig{MaxEntries: 10}, map[string][]string{"sw1": ifaces})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, iface := range ifaces[:9] {
+		cacheState(e, "sw1", iface, now.Add(time.Duration(i)*time.Second), alertTypeInterfaceMismatch)
+	}
+	if resolved := e.ensureCapacity(e.Config(), now); resolved != nil || e.entries.Load() != 9 {
+		t.Fatalf("evicted below the limit: %v, %d entries", resolved, e.entries.Load())
+	}
+
+	// Full: the least recently updated entries go, down to 90%
+	cacheState(e, "sw1", ifaces[9], now.Add(9*time.Second))
+	resolved := e.ensureCapacity(e.Config(), now.Add(10*time.Second))
+	if got := e.entries.Load(); got != 9 {
+		t.Fatalf("%d entries, want 9", got)
+	}
+	if cached(e, "sw1", "Ethernet1") || !cached(e, "sw1", "Ethernet2") {
+		t.Error("evicted other than the oldest entry")
+	}
+	if len(resolved) != 1 || resolved[0].Interface != "Ethernet1" || resolved[0].AlertType != alertTypeInterfaceMismatch {
+		t.Fatalf("resolutions %v, want Ethernet1's mismatch", resolved)
+	}
+	if want := "interface Ethernet1 evicted from the full state cache"; resolved[0].Message != want {
+		t.Errorf("message %q, want %q", resolved[0].Message, want)
+	}
+}
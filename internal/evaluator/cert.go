@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"crypto/x509"
+	"fmt"
+	"math"
+	"time"
+)
+
+// AlertTypeCertExpiring fires as a warning when the certificate a device
+// presents for gNMI over TLS is within cert_warn_days of expiry, and as
+// critical once it has expired
+const AlertTypeCertExpiring = "device_cert_expiring"
+
+// CertEntity is the alert entity of a device's gNMI certificate
+const CertEntity = "certificate"
+
+// EvaluateCertificate checks the certificate a device presented in its
+// last TLS handshake against its cert_warn_days. It returns a state change
+// when the finding is new or changed, such as a warning becoming critical,
+// and reports whether a previous finding has cleared, as when the device
+// presents a renewed certificate. A nil certificate, before any handshake,
+// changes nothing.
+func (e *Evaluator) EvaluateCertificate(deviceName string, cert *x509.Certificate) (changes []StateChange, cleared bool) {
+	cfg := e.Config()
+	if cfg == nil {
+		return nil, false
+	}
+	deviceCfg := cfg.DesiredState.Devices[deviceName]
+	tlsCfg := cfg.TLSFor(deviceCfg)
+	key := findingKey(deviceName, AlertTypeCertExpiring, CertEntity)
+	if tlsCfg == nil || !tlsCfg.Enabled {
+		_, cleared = e.recordFinding(key, "")
+		return nil, cleared
+	}
+	if cert == nil {
+		return nil, false
+	}
+	now := time.Now()
+
+	var severity, message string
+	expires := cert.NotAfter.UTC().Format("2006-01-02")
+	remaining := cert.NotAfter.Sub(now)
+	switch {
+	case remaining <= 0:
+		severity = "critical"
+		message = fmt.Sprintf("gNMI TLS certificate %s expired on %s", cert.Subject, expires)
+	case remaining <= tlsCfg.CertWarning():
+		severity = "warning"
+		days := int(math.Ceil(remaining.Hours() / 24))
+		message = fmt.Sprintf("gNMI TLS certificate %s expires on %s (in %d days)", cert.Subject, expires, days)
+	}
+	var finding string
+	if severity != "" {
+		finding = severity + "|" + cert.SerialNumber.String()
+	}
+
+	changed, cleared := e.recordFinding(key, finding)
+	if !changed {
+		return nil, cleared
+	}
+	return []StateChange{{
+		Device:    deviceName,
+		Interface: CertEntity,
+		AlertType: AlertTypeCertExpiring,
+		Severity:  severity,
+		Firing:    true,
+		Message:   message,
+		RelatedState: map[string]string{
+			"subject":   cert.Subject.String(),
+			"issuer":    cert.Issuer.String(),
+			"serial":    cert.SerialNumber.String(),
+			"not_after": cert.NotAfter.UTC().Format(time.RFC3339),
+		},
+		ObservedAt: now,
+	}}, cleared
+}
@@ -0,0 +1,81 @@
+package evaluator
+
+import (
+	"sort"
+	"time"
+)
+
+// Interface compliance statuses
+const (
+	ComplianceCompliant = "compliant"
+	ComplianceDeviating = "deviating"
+	ComplianceUnknown   = "unknown" // not yet reported by the device
+)
+
+// InterfaceCompliance compares a monitored interface's desired state with
+// its cached observed state
+type InterfaceCompliance struct {
+	Interface     string     `json:"interface"`
+	DesiredOper   string     `json:"desired_oper"`
+	ActualOper    string     `json:"actual_oper,omitempty"`
+	DesiredAdmin  string     `json:"desired_admin,omitempty"`
+	ActualAdmin   string     `json:"actual_admin,omitempty"`
+	Status        string     `json:"status"` // compliant, deviating or unknown
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+	DeviatedSince *time.Time `json:"deviated_since,omitempty"`
+}
+
+// GetDeviceCompliance returns the desired and observed state of every
+// interface declared for a device, sorted by interface name. Interfaces
+// matched by an ignore rule are left out. The second result is false for a
+// device not in the configuration.
+func (e *Evaluator) GetDeviceCompliance(deviceName string) ([]InterfaceCompliance, bool) {
+	deviceCfg, ok := e.Config().DesiredState.Devices[deviceName]
+	if !ok {
+		return nil, false
+	}
+	now := time.Now()
+
+	cached := make(map[string]interfaceState)
+	if s, ok := e.lookupShard(deviceName); ok {
+		s.mu.RLock()
+		for name, state := range s.states {
+			cached[name] = state
+		}
+		s.mu.RUnlock()
+	}
+
+	result := make([]InterfaceCompliance, 0, len(deviceCfg.Interfaces))
+	for ifaceName, ifCfg := range deviceCfg.Interfaces {
+		entry := InterfaceCompliance{
+			Interface:    ifaceName,
+			DesiredOper:  normalizeState(ifCfg.DesiredStateAt(now)),
+			DesiredAdmin: normalizeState(ifCfg.AdminState),
+			Status:       ComplianceUnknown,
+		}
+		if state, ok := cached[ifaceName]; ok {
+			if state.Ignored {
+				continue
+			}
+			entry.ActualOper = state.OperStatus
+			entry.ActualAdmin = state.AdminStatus
+			updated := state.UpdatedAt
+			entry.UpdatedAt = &updated
+			switch {
+			case isDeviating(ifCfg, state, now):
+				entry.Status = ComplianceDeviating
+				if !state.DeviatedSince.IsZero() {
+					since := state.DeviatedSince
+					entry.DeviatedSince = &since
+				}
+			case state.OperStatus != "":
+				entry.Status = ComplianceCompliant
+			}
+		}
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Interface < result[j].Interface
+	})
+	return result, true
+}
@@ -0,0 +1,237 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// trackedCounters are the /interfaces/interface/state/counters leaves the
+// evaluator keeps history for
+var trackedCounters = map[string]bool{
+	"in-pkts":           true,
+	"in-unicast-pkts":   true,
+	"in-multicast-pkts": true,
+	"in-broadcast-pkts": true,
+	"in-errors":         true,
+	"in-fcs-errors":     true,
+	"in-octets":         true,
+	"out-octets":        true,
+}
+
+// counterSample is one reading of an interface's receive counters
+type counterSample struct {
+	at      time.Time
+	packets uint64
+	errors  uint64 // in-errors, which include FCS errors, else in-fcs-errors
+	fcs     uint64
+}
+
+// counterHistory is the rolling counter state of one interface, guarded by
+// its shard's lock
+type counterHistory struct {
+	latest        map[string]uint64 // last value of every tracked leaf
+	samples       []counterSample   // oldest first; samples[0] is the window's baseline
+	errorRateHigh bool
+	baselines     [2]octetBaseline // receive, transmit
+}
+
+// counterLeaf reports the interface and leaf of an interface counter
+// update, e.g. /interfaces/interface[name=X]/state/counters/in-errors
+func counterLeaf(prefix, path *gnmi.Path) (ifaceName, leaf string, ok bool) {
+	elems := path.GetElem()
+	if prefix != nil && len(prefix.Elem) > 0 {
+		elems = append(append([]*gnmi.PathElem{}, prefix.Elem...), elems...)
+	}
+	if len(elems) != 5 || elems[0].Name != "interfaces" || elems[1].Name != "interface" ||
+		elems[2].Name != "state" || elems[3].Name != "counters" || !trackedCounters[elems[4].Name] {
+		return "", "", false
+	}
+	return elems[1].Key["name"], elems[4].Name, elems[1].Key["name"] != ""
+}
+
+// counterValue reads a counter leaf, which devices send as a uint64, int64
+// or decimal string
+func counterValue(val *gnmi.TypedValue) (uint64, bool) {
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_UintVal:
+		return v.UintVal, true
+	case *gnmi.TypedValue_IntVal:
+		return uint64(v.IntVal), v.IntVal >= 0
+	case *gnmi.TypedValue_StringVal:
+		n, err := strconv.ParseUint(v.StringVal, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// evaluateCounters records the counters one notification carried for each
+// interface and evaluates their rolling error rate and traffic baseline. It
+// also returns the bit rates derived from the octet counters and the packets
+// and errors received since the previous reading.
+func (e *Evaluator) evaluateCounters(cfg *config.Config, deviceName string, readings map[string]map[string]uint64, at time.Time) ([]StateChange, []RateSample, []ErrorSample) {
+	deviceCfg, ok := cfg.DesiredState.Devices[deviceName]
+	if !ok || deviceCfg.ExpectedOffline() {
+		return nil, nil, nil
+	}
+	shard := e.shard(deviceName)
+
+	var changes []StateChange
+	var samples []RateSample
+	var errorSamples []ErrorSample
+	for ifaceName, values := range readings {
+		ifCfg, declared := deviceCfg.Interfaces[ifaceName]
+		if !declared {
+			continue
+		}
+		rate := cfg.ErrorRateFor(ifCfg)
+		baseline := cfg.DesiredState.Global.TrafficBaseline
+
+		shard.mu.Lock()
+		if state, cached := shard.states[ifaceName]; cached && state.Ignored {
+			shard.mu.Unlock()
+			continue
+		}
+		if shard.counters == nil {
+			shard.counters = make(map[string]*counterHistory)
+		}
+		hist, ok := shard.counters[ifaceName]
+		if !ok {
+			hist = &counterHistory{latest: make(map[string]uint64)}
+			shard.counters[ifaceName] = hist
+		}
+		prev, hadPrev := receiveTotals(hist.latest, at)
+		for leaf, v := range values {
+			hist.latest[leaf] = v
+		}
+		if cur, ok := receiveTotals(hist.latest, at); ok && hadPrev &&
+			cur.packets >= prev.packets && cur.errors >= prev.errors && cur.packets > prev.packets {
+			errorSamples = append(errorSamples, ErrorSample{
+				Device:    deviceName,
+				Interface: ifaceName,
+				Packets:   cur.packets - prev.packets,
+				Errors:    cur.errors - prev.errors,
+				At:        at,
+			})
+		}
+		if rate != nil {
+			if change := e.evaluateErrorRate(deviceName, ifaceName, ifCfg, *rate, hist, at); change != nil {
+				changes = append(changes, *change)
+			}
+		}
+		rates, measured := hist.octetRates(values, at)
+		if baseline != nil {
+			changes = append(changes, e.evaluateBaseline(deviceName, ifaceName, ifCfg, baseline.WithDefaults(), hist, rates, measured)...)
+		}
+		shard.mu.Unlock()
+
+		for dir := range octetCounters {
+			if measured[dir] {
+				samples = append(samples, RateSample{
+					Device:    deviceName,
+					Interface: ifaceName,
+					Direction: directionNames[dir],
+					BPS:       rates[dir],
+					At:        at,
+				})
+			}
+		}
+	}
+	return changes, samples, errorSamples
+}
+
+// receiveTotals reads the received packet and error totals from the latest
+// counter values. Packets are in-pkts, else the sum of the unicast,
+// multicast and broadcast counters.
+func receiveTotals(latest map[string]uint64, at time.Time) (counterSample, bool) {
+	packets, ok := latest["in-pkts"]
+	if !ok {
+		unicast, ok := latest["in-unicast-pkts"]
+		if !ok {
+			return counterSample{}, false
+		}
+		packets = unicast + latest["in-multicast-pkts"] + latest["in-broadcast-pkts"]
+	}
+	inErrors, ok := latest["in-errors"]
+	if !ok {
+		if inErrors, ok = latest["in-fcs-errors"]; !ok {
+			return counterSample{}, false
+		}
+	}
+	return counterSample{at: at, packets: packets, errors: inErrors, fcs: latest["in-fcs-errors"]}, true
+}
+
+// evaluateErrorRate adds a sample to the interface's window and fires when
+// the error rate first exceeds the threshold. It resolves and re-arms once
+// the rate is back under the threshold.
+func (e *Evaluator) evaluateErrorRate(deviceName, ifaceName string, ifCfg config.InterfaceConfig, rate config.ErrorRateConfig, hist *counterHistory, at time.Time) *StateChange {
+	sample, ok := receiveTotals(hist.latest, at)
+	if !ok {
+		return nil
+	}
+
+	// A counter that went backwards was cleared or the device restarted
+	if n := len(hist.samples); n > 0 {
+		last := hist.samples[n-1]
+		if sample.packets < last.packets || sample.errors < last.errors || sample.fcs < last.fcs {
+			hist.samples = hist.samples[:0]
+		}
+	}
+	hist.samples = append(hist.samples, sample)
+
+	window, minPackets := rate.Limits()
+	cutoff := at.Add(-window)
+	drop := 0
+	for drop+1 < len(hist.samples) && !hist.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	hist.samples = hist.samples[drop:]
+
+	first := hist.samples[0]
+	packetDelta := sample.packets - first.packets
+	if packetDelta < minPackets {
+		return nil
+	}
+	errorDelta := sample.errors - first.errors
+	ppm := float64(errorDelta) / float64(packetDelta) * 1e6
+
+	if ppm <= rate.ThresholdPPM {
+		if !hist.errorRateHigh {
+			return nil
+		}
+		hist.errorRateHigh = false
+		return &StateChange{
+			Device:    deviceName,
+			Interface: ifaceName,
+			AlertType: alertTypeErrorRate,
+			Message: fmt.Sprintf("interface %s input errors back to %.1f per million packets (threshold %g)",
+				ifaceName, ppm, rate.ThresholdPPM),
+		}
+	}
+	if hist.errorRateHigh {
+		return nil
+	}
+	hist.errorRateHigh = true
+
+	span := sample.at.Sub(first.at).Round(time.Second)
+	return &StateChange{
+		Device:    deviceName,
+		Interface: ifaceName,
+		AlertType: alertTypeErrorRate,
+		Severity:  severityForAlert(ifCfg, "error_rate", "warning"),
+		Firing:    true,
+		Message: fmt.Sprintf("interface %s input errors at %.1f per million packets over %s (threshold %g)",
+			ifaceName, ppm, span, rate.ThresholdPPM),
+		RelatedState: map[string]string{
+			"error_ppm":     strconv.FormatFloat(ppm, 'f', 1, 64),
+			"threshold_ppm": strconv.FormatFloat(rate.ThresholdPPM, 'f', -1, 64),
+			"errors":        strconv.FormatUint(errorDelta, 10),
+			"crc_errors":    strconv.FormatUint(sample.fcs-first.fcs, 10),
+			"packets":       strconv.FormatUint(packetDelta, 10),
+			"window":        span.String(),
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package evaluator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// Deviation describes a monitored interface whose observed state currently
+// differs from its desired state
+type Deviation struct {
+	Device        string
+	Interface     string
+	ExpectedOper  string
+	ActualOper    string
+	ExpectedAdmin string
+	ActualAdmin   string
+	Since         time.Time
+	UpdatedAt     time.Time
+}
+
+// GetDeviations returns every cached interface that is currently out of
+// compliance, longest-deviating first
+func (e *Evaluator) GetDeviations() []Deviation {
+	cfg := e.Config()
+	now := time.Now()
+
+	var deviations []Deviation
+	for _, state := range e.cachedStates() {
+		deviceCfg, ok := cfg.DesiredState.Devices[state.Device]
+		if !ok {
+			continue
+		}
+		ifCfg, ok := deviceCfg.Interfaces[state.Interface]
+		if !ok || state.Ignored || !isDeviating(ifCfg, state, now) {
+			continue
+		}
+		since := state.DeviatedSince
+		if since.IsZero() {
+			since = state.UpdatedAt
+		}
+		deviations = append(deviations, Deviation{
+			Device:        state.Device,
+			Interface:     state.Interface,
+			ExpectedOper:  normalizeState(ifCfg.DesiredStateAt(now)),
+			ActualOper:    state.OperStatus,
+			ExpectedAdmin: normalizeState(ifCfg.AdminState),
+			ActualAdmin:   state.AdminStatus,
+			Since:         since,
+			UpdatedAt:     state.UpdatedAt,
+		})
+	}
+
+	sort.Slice(deviations, func(i, j int) bool {
+		if !deviations[i].Since.Equal(deviations[j].Since) {
+			return deviations[i].Since.Before(deviations[j].Since)
+		}
+		if deviations[i].Device != deviations[j].Device {
+			return deviations[i].Device < deviations[j].Device
+		}
+		return deviations[i].Interface < deviations[j].Interface
+	})
+	return deviations
+}
+
+// isDeviating reports whether the observed state differs from the desired
+// oper state at now or the desired admin state. Unknown (not yet reported)
+// values never deviate.
+func isDeviating(ifCfg config.InterfaceConfig, state interfaceState, now time.Time) bool {
+	if ifCfg.AdminState != "" && state.AdminStatus != "" &&
+		state.AdminStatus != normalizeState(ifCfg.AdminState) {
+		return true
+	}
+	desired := ifCfg.DesiredStateAt(now)
+	if desired != "" && state.OperStatus != "" &&
+		state.OperStatus != normalizeState(desired) {
+		return true
+	}
+	return false
+}
@@ -1,12 +1,16 @@
 package evaluator
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/metrics"
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/rs/zerolog"
 )
@@ -15,8 +19,30 @@ import (
 type Evaluator struct {
 	config     *config.Config
 	logger     zerolog.Logger
-	stateCache map[string]interfaceState
-	mu         sync.RWMutex
+	mu         sync.RWMutex // guards config and hooks
+	onTransition []TransitionFunc
+	onRate       []RateFunc
+	onErrors     []ErrorFunc
+	deviceDown   func(device string) bool
+
+	// Observed state is sharded per device so devices evaluate concurrently
+	shards   map[string]*deviceShard
+	shardsMu sync.RWMutex
+	entries  atomic.Int64 // cached interfaces across all shards
+
+	// Desired states last seen by EvaluateSchedules, keyed device|interface
+	scheduled   map[string]string
+	scheduledMu sync.Mutex
+
+	// Last configuration audit finding per device|alert type|interface
+	findings   map[string]string
+	findingsMu sync.Mutex
+
+	// Unreachable devices by name, and the devices_reconnecting list last
+	// reported, kept by EvaluateReachability
+	outages      map[string]*outage
+	reconnecting string
+	outagesMu    sync.Mutex
 }
 
 // interfaceState represents the current state of an interface
@@ -26,7 +52,22 @@ type interfaceState struct {
 	OperStatus  string
 	AdminStatus string
 	Members     []string
+	// Description is the description configured on the device, valid once
+	// DescriptionSeen is set (an empty description is a real value)
+	Description     string
+	DescriptionSeen bool
+	// TrunkVLANs is the trunk's allowed VLAN set in compact form, valid once
+	// TrunkVLANsSeen is set
+	TrunkVLANs     string
+	TrunkVLANsSeen bool
+	// Ignored is set while the interface matches an ignore_interfaces rule;
+	// it stays cached, so a description match is remembered, but is neither
+	// evaluated nor listed
+	Ignored bool
 	UpdatedAt   time.Time
+	// DeviatedSince is when the interface first diverged from its desired
+	// state; zero while the interface is compliant
+	DeviatedSince time.Time
 }
 
 var (
@@ -34,6 +75,10 @@ var (
 	alertTypeInterfaceAdminDown = "interface_admin_down"
 	alertTypeChannelDown       = "port_channel_down"
 	alertTypeMemberDown        = "port_channel_member_down"
+	alertTypeDescriptionMismatch = "interface_description_mismatch"
+	alertTypeTrunkVLANMismatch = "interface_trunk_vlan_mismatch"
+	alertTypeErrorRate         = "interface_error_rate"
+	alertTypeTrafficAnomaly    = "interface_traffic_anomaly"
 )
 
 var supportedOperStates = map[string]struct{}{
@@ -54,24 +99,60 @@ type StateChange struct {
 	Severity    string
 	Message     string
 	RelatedState map[string]string
+	// Firing is false for a resolution: the alert's condition has cleared
+	// and the alert should resolve
+	Firing bool
+	// ObservedAt is the timestamp of the notification that caused the
+	// change, or the time it was evaluated if the device sent none
+	ObservedAt time.Time
 }
 
+var evaluationDuration = metrics.NewHistogram(
+	"netspec_evaluation_duration_seconds",
+	"Time spent evaluating one gNMI notification against desired state",
+	[]float64{0.000005, 0.00001, 0.000025, 0.00005, 0.0001, 0.00025, 0.0005, 0.001, 0.005, 0.01})
+
 // NewEvaluator creates a new state evaluator
 func NewEvaluator(cfg *config.Config, logger zerolog.Logger) *Evaluator {
 	return &Evaluator{
-		config:     cfg,
-		logger:     logger,
-		stateCache: make(map[string]interfaceState),
+		config: cfg,
+		logger: logger,
+		shards: make(map[string]*deviceShard),
 	}
 }
 
 // EvaluateNotification processes a gNMI notification and returns state changes
 func (e *Evaluator) EvaluateNotification(deviceName string, notification *gnmi.Notification) []StateChange {
 	var changes []StateChange
+	start := time.Now()
+
+	e.mu.RLock()
+	cfg := e.config
+	onTransition := e.onTransition
+	onRate := e.onRate
+	onErrors := e.onErrors
+	e.mu.RUnlock()
+
+	var shard *deviceShard
+	var counters map[string]map[string]uint64 // interface -> counter leaf -> value
+	stateChanged := false // an oper or admin status changed, for the compliance rollup
 
 	// Extract interface information from notification
 	for _, update := range notification.Update {
 		path := update.Path
+
+		if ifaceName, leaf, ok := counterLeaf(notification.Prefix, path); ok {
+			if v, ok := counterValue(update.Val); ok {
+				if counters == nil {
+					counters = make(map[string]map[string]uint64)
+				}
+				if counters[ifaceName] == nil {
+					counters[ifaceName] = make(map[string]uint64)
+				}
+				counters[ifaceName][leaf] = v
+			}
+			continue
+		}
 		
 		// Parse interface path: /interfaces/interface[name="X"]/state/oper-status
 		ifaceName, stateType, err := e.parseInterfacePath(path)
@@ -91,17 +172,19 @@ func (e *Evaluator) EvaluateNotification(deviceName string, notification *gnmi.N
 			}
 			
 			if err != nil || ifaceName == "" {
-				e.logger.Debug().
-					Err(err).
-					Str("path", path.String()).
-					Msg("Skipping non-interface path")
+				// Most leaves of a state-container sample land here, so only
+				// render the path when debug logging is on
+				if ev := e.logger.Debug(); ev.Enabled() {
+					ev.Err(err).Str("path", path.String()).Msg("Skipping non-interface path")
+				}
 				continue
 			}
 		}
 
-		// Get interface config for this device
-		deviceCfg, ok := e.config.DesiredState.Devices[deviceName]
-		if !ok {
+		// Get interface config for this device. A device expected offline
+		// has no interface expectations while it is unexpectedly up.
+		deviceCfg, ok := cfg.DesiredState.Devices[deviceName]
+		if !ok || deviceCfg.ExpectedOffline() {
 			continue
 		}
 
@@ -119,38 +202,135 @@ func (e *Evaluator) EvaluateNotification(deviceName string, notification *gnmi.N
 				stateValue = strVal
 			}
 		}
+		var vlans []int
+		if stateType == "trunk-vlans" {
+			if vlans, err = trunkVLANs(update.Val); err != nil {
+				e.logger.Debug().Err(err).Str("device", deviceName).Str("interface", ifaceName).Msg("Unparseable trunk-vlans value")
+				continue
+			}
+		}
 
 		// Update state cache
-		e.mu.Lock()
-		cacheKey := fmt.Sprintf("%s:%s", deviceName, ifaceName)
-		state := e.stateCache[cacheKey]
-		state.Device = deviceName
-		state.Interface = ifaceName
-		state.UpdatedAt = time.Now()
+		if shard == nil {
+			shard = e.shard(deviceName)
+		}
+		now := time.Now()
+		shard.mu.Lock()
+		state, cached := shard.states[ifaceName]
+		if !cached {
+			// Making room may evict from any shard, including this one
+			shard.mu.Unlock()
+			changes = append(changes, e.ensureCapacity(cfg, now)...)
+			shard.mu.Lock()
+			state, cached = shard.states[ifaceName]
+		}
+		state.UpdatedAt = now
+		if !cached {
+			state.Device = deviceName
+			state.Interface = ifaceName
+		}
+
+		prevState := state
 
 		// Update appropriate state field
+		var previous, current string
+		firstSeen := false
 		switch stateType {
 		case "oper-status":
+			previous = state.OperStatus
 			state.OperStatus = normalizeState(stateValue)
+			current = state.OperStatus
 		case "admin-status":
-			state.AdminStatus = normalizeState(stateValue)
+			previous = state.AdminStatus
+			state.AdminStatus = normalizeAdminState(stateValue)
+			current = state.AdminStatus
+		case "description":
+			previous = state.Description
+			firstSeen = !state.DescriptionSeen
+			state.Description = stateValue
+			state.DescriptionSeen = true
+			current = state.Description
+		case "trunk-vlans":
+			previous = state.TrunkVLANs
+			firstSeen = !state.TrunkVLANsSeen
+			state.TrunkVLANs = config.FormatVLANs(vlans)
+			state.TrunkVLANsSeen = true
+			current = state.TrunkVLANs
 		}
 
-		e.stateCache[cacheKey] = state
-		prevState := state
-		e.mu.Unlock()
+		state.Ignored = cfg.IgnoresInterface(deviceName, config.ObservedInterface{Name: ifaceName, Description: state.Description})
+		if state.Ignored {
+			// Alerts raised before the interface became ignored would
+			// otherwise never resolve
+			state.DeviatedSince = time.Time{}
+			changes = append(changes, clearFiring(shard, deviceName, ifaceName,
+				fmt.Sprintf("interface %s is ignored by ignore_interfaces", ifaceName))...)
+			shard.states[ifaceName] = state
+			shard.mu.Unlock()
+			if !cached {
+				cacheEntries.Set(float64(e.entries.Add(1)))
+			}
+			continue
+		}
+
+		if isDeviating(deviceCfg.Interfaces[ifaceName], state, now) {
+			if state.DeviatedSince.IsZero() {
+				state.DeviatedSince = state.UpdatedAt
+			}
+		} else {
+			state.DeviatedSince = time.Time{}
+		}
+
+		shard.states[ifaceName] = state
+		shard.mu.Unlock()
+		if !cached {
+			cacheEntries.Set(float64(e.entries.Add(1)))
+		}
+
+		if current != previous {
+			if stateType == "oper-status" || stateType == "admin-status" {
+				stateChanged = true
+			}
+			for _, hook := range onTransition {
+				hook(Transition{
+					Device:    deviceName,
+					Interface: ifaceName,
+					Field:     stateType,
+					Previous:  previous,
+					Current:   current,
+					At:        state.UpdatedAt,
+				})
+			}
+		}
 
 		// Evaluate state against desired state
 		if ifCfg, ok := deviceCfg.Interfaces[ifaceName]; ok {
-			if stateType == "admin-status" {
-				if adminChange := e.evaluateAdminChange(deviceName, ifaceName, ifCfg, prevState, state); adminChange != nil {
-					changes = append(changes, *adminChange)
-				}
+			var change, resolved *StateChange
+			switch {
+			case stateType == "admin-status":
+				change = e.evaluateAdminChange(deviceName, ifaceName, ifCfg, prevState, state)
+				resolved = trackFiring(shard, deviceName, ifaceName, alertTypeInterfaceAdminDown, adminMismatch(ifCfg, state),
+					fmt.Sprintf("interface %s admin state back to %s", ifaceName, state.AdminStatus))
+			case stateType == "oper-status":
+				change = e.evaluateOperChange(deviceName, ifaceName, ifCfg, state, now)
+				// An interface taken down administratively keeps its oper
+				// alert until it is back in service
+				resolved = trackFiring(shard, deviceName, ifaceName, alertTypeInterfaceMismatch, change != nil || adminMismatch(ifCfg, state),
+					fmt.Sprintf("interface %s back to desired state %s", ifaceName, state.OperStatus))
+			case stateType == "description" && (firstSeen || current != previous):
+				change = e.evaluateDescription(deviceName, ifaceName, ifCfg, state)
+				resolved = trackFiring(shard, deviceName, ifaceName, alertTypeDescriptionMismatch, change != nil,
+					fmt.Sprintf("interface %s description matches expected", ifaceName))
+			case stateType == "trunk-vlans" && (firstSeen || current != previous):
+				change = e.evaluateTrunkVLANs(deviceName, ifaceName, ifCfg, state)
+				resolved = trackFiring(shard, deviceName, ifaceName, alertTypeTrunkVLANMismatch, change != nil,
+					fmt.Sprintf("interface %s trunk VLANs match expected", ifaceName))
 			}
-			if stateType == "oper-status" {
-				if operChange := e.evaluateOperChange(deviceName, ifaceName, ifCfg, state); operChange != nil {
-					changes = append(changes, *operChange)
-				}
+			if change != nil {
+				changes = append(changes, *change)
+			}
+			if resolved != nil {
+				changes = append(changes, *resolved)
 			}
 		}
 
@@ -161,20 +341,59 @@ func (e *Evaluator) EvaluateNotification(deviceName string, notification *gnmi.N
 		}
 	}
 
+	if stateChanged {
+		changes = append(changes, e.evaluateDeviceCompliance(cfg, deviceName, time.Now())...)
+	}
+
+	observedAt := start
+	if notification.Timestamp != 0 {
+		observedAt = time.Unix(0, notification.Timestamp)
+	}
+	if counters != nil && cfg != nil {
+		counterChanges, rates, errorSamples := e.evaluateCounters(cfg, deviceName, counters, observedAt)
+		changes = append(changes, counterChanges...)
+		for _, sample := range rates {
+			for _, hook := range onRate {
+				hook(sample)
+			}
+		}
+		for _, sample := range errorSamples {
+			for _, hook := range onErrors {
+				hook(sample)
+			}
+		}
+	}
+
+	if len(changes) > 0 {
+		for i := range changes {
+			changes[i].ObservedAt = observedAt
+		}
+	}
+	evaluationDuration.Observe(time.Since(start).Seconds())
 	return changes
 }
 
+// Path parse errors are static so skipping the many leaves the evaluator
+// does not care about costs no allocation
+var (
+	errPathTooShort     = errors.New("path too short")
+	errNotInterfacePath = errors.New("not an interface path")
+	errNoInterfaceName  = errors.New("interface name not found in path")
+	errNoStateType      = errors.New("state type not found in path")
+	errUnknownStateType = errors.New("unknown state type")
+)
+
 // parseInterfacePath extracts interface name and state type from gNMI path
 // Supports both OpenConfig format (/interfaces/interface[name="X"]/state/oper-status)
 // and vendor-specific format (/interfaces/interface[name="X"]/oper-status)
 func (e *Evaluator) parseInterfacePath(path *gnmi.Path) (ifaceName string, stateType string, err error) {
 	if len(path.Elem) < 3 {
-		return "", "", fmt.Errorf("path too short")
+		return "", "", errPathTooShort
 	}
 
 	// Expected: /interfaces/interface[name="X"]/state/oper-status or /interfaces/interface[name="X"]/oper-status
 	if path.Elem[0].Name != "interfaces" || path.Elem[1].Name != "interface" {
-		return "", "", fmt.Errorf("not an interface path")
+		return "", "", errNotInterfacePath
 	}
 
 	// Extract interface name from key
@@ -182,7 +401,7 @@ func (e *Evaluator) parseInterfacePath(path *gnmi.Path) (ifaceName string, state
 	if ifaceName == "" {
 		// Try to extract from origin or other fields
 		// For wildcard subscriptions, we need to get it from the update itself
-		return "", "", fmt.Errorf("interface name not found in path")
+		return "", "", errNoInterfaceName
 	}
 
 	// Check for OpenConfig format (with /state/) or vendor-specific format (without /state/)
@@ -190,20 +409,26 @@ func (e *Evaluator) parseInterfacePath(path *gnmi.Path) (ifaceName string, state
 	if len(path.Elem) >= 3 && path.Elem[2].Name == "state" {
 		// OpenConfig format: /interfaces/interface[name="X"]/state/oper-status
 		if len(path.Elem) < 4 {
-			return "", "", fmt.Errorf("state type not found in path")
+			return "", "", errNoStateType
 		}
 		stateTypeIndex = 3
 	} else {
 		// Vendor-specific format: /interfaces/interface[name="X"]/oper-status
 		if len(path.Elem) < 3 {
-			return "", "", fmt.Errorf("state type not found in path")
+			return "", "", errNoStateType
 		}
 		stateTypeIndex = 2
 	}
 	
+	// openconfig-vlan: /interfaces/interface[name="X"]/ethernet/switched-vlan/state/trunk-vlans
+	// (or aggregation/... for port-channels)
+	if len(path.Elem) == 6 && path.Elem[3].Name == "switched-vlan" && path.Elem[5].Name == "trunk-vlans" {
+		return ifaceName, "trunk-vlans", nil
+	}
+
 	stateType = path.Elem[stateTypeIndex].Name
-	if stateType != "oper-status" && stateType != "admin-status" {
-		return "", "", fmt.Errorf("unknown state type: %s", stateType)
+	if stateType != "oper-status" && stateType != "admin-status" && stateType != "description" {
+		return "", "", errUnknownStateType
 	}
 
 	return ifaceName, stateType, nil
@@ -230,6 +455,7 @@ func (e *Evaluator) evaluateAdminChange(deviceName, ifaceName string, ifCfg conf
 		Interface: ifaceName,
 		AlertType: alertTypeInterfaceAdminDown,
 		Severity:  severity,
+		Firing:    true,
 		Message:   fmt.Sprintf("interface %s admin state %s", ifaceName, ifaceState.AdminStatus),
 		RelatedState: map[string]string{
 			"expected_admin": desiredAdmin,
@@ -238,12 +464,47 @@ func (e *Evaluator) evaluateAdminChange(deviceName, ifaceName string, ifCfg conf
 	}
 }
 
-// evaluateOperChange evaluates operational status changes
-func (e *Evaluator) evaluateOperChange(deviceName, ifaceName string, ifCfg config.InterfaceConfig, ifaceState interfaceState) *StateChange {
-	if ifCfg.DesiredState == "" {
+// adminMismatch reports whether an interface's observed admin state differs
+// from its declared admin_state
+func adminMismatch(ifCfg config.InterfaceConfig, ifaceState interfaceState) bool {
+	if ifCfg.AdminState == "" {
+		return false
+	}
+	desiredAdmin := normalizeState(ifCfg.AdminState)
+	if _, ok := supportedAdminStates[desiredAdmin]; !ok {
+		return false
+	}
+	return ifaceState.AdminStatus != "" && ifaceState.AdminStatus != desiredAdmin
+}
+
+// evaluateDescription checks the device's configured description against
+// expected_description
+func (e *Evaluator) evaluateDescription(deviceName, ifaceName string, ifCfg config.InterfaceConfig, ifaceState interfaceState) *StateChange {
+	if !ifaceState.DescriptionSeen || ifCfg.DescriptionMatches(ifaceState.Description) {
 		return nil
 	}
-	desired := normalizeState(ifCfg.DesiredState)
+	return &StateChange{
+		Device:    deviceName,
+		Interface: ifaceName,
+		AlertType: alertTypeDescriptionMismatch,
+		Severity:  severityForAlert(ifCfg, "description_mismatch", "warning"),
+		Firing:    true,
+		Message:   fmt.Sprintf("interface %s description %q does not match expected %q", ifaceName, ifaceState.Description, ifCfg.ExpectedDescription),
+		RelatedState: map[string]string{
+			"expected_description": ifCfg.ExpectedDescription,
+			"actual_description":   ifaceState.Description,
+		},
+	}
+}
+
+// evaluateOperChange evaluates operational status against the desired state
+// in effect at now
+func (e *Evaluator) evaluateOperChange(deviceName, ifaceName string, ifCfg config.InterfaceConfig, ifaceState interfaceState, now time.Time) *StateChange {
+	desired := ifCfg.DesiredStateAt(now)
+	if desired == "" {
+		return nil
+	}
+	desired = normalizeState(desired)
 	if _, ok := supportedOperStates[desired]; !ok {
 		return nil
 	}
@@ -269,6 +530,7 @@ func (e *Evaluator) evaluateOperChange(deviceName, ifaceName string, ifCfg confi
 			Interface: ifaceName,
 			AlertType: alertTypeInterfaceMismatch,
 			Severity:  severity,
+			Firing:    true,
 			Message:   fmt.Sprintf("interface %s expected %s got %s", ifaceName, desired, ifaceState.OperStatus),
 			RelatedState: map[string]string{
 				"expected_state": desired,
@@ -294,6 +556,20 @@ func (e *Evaluator) evaluatePortChannel(deviceName, ifaceName string, deviceCfg
 		}
 		channelAlerts := e.evaluateChannelMembers(deviceName, channelName, channelCfg, ifaceState)
 		changes = append(changes, channelAlerts...)
+
+		firing := make(map[string]bool, len(channelAlerts))
+		for _, alert := range channelAlerts {
+			firing[alert.AlertType] = true
+		}
+		shard := e.shard(deviceName)
+		if resolved := trackFiring(shard, deviceName, channelName, alertTypeMemberDown, firing[alertTypeMemberDown],
+			fmt.Sprintf("port-channel %s members all up", channelName)); resolved != nil {
+			changes = append(changes, *resolved)
+		}
+		if resolved := trackFiring(shard, deviceName, channelName, alertTypeChannelDown, firing[alertTypeChannelDown],
+			fmt.Sprintf("port-channel %s active members back at minimum", channelName)); resolved != nil {
+			changes = append(changes, *resolved)
+		}
 	}
 	return changes
 }
@@ -315,19 +591,39 @@ func (e *Evaluator) evaluateChannelMembers(deviceName, channelName string, iface
 		}
 	}
 
-	e.mu.RLock()
 	active := 0
 	var downMembers []string
+	var activeByStack map[int]int // per_stack_minimum only
+	if mode == "per_stack_minimum" {
+		activeByStack = make(map[int]int)
+	}
+	shard := e.shard(deviceName)
+	shard.mu.RLock()
 	for _, member := range ifaceCfg.Members.Required {
-		cacheKey := fmt.Sprintf("%s:%s", deviceName, member)
-		memberState := e.stateCache[cacheKey]
-		if normalizeState(memberState.OperStatus) == "up" {
+		memberState := shard.states[member]
+		up := normalizeState(memberState.OperStatus) == "up"
+		if up {
 			active++
 		} else {
 			downMembers = append(downMembers, member)
 		}
+		if activeByStack != nil {
+			stack, ok := config.StackMember(member)
+			if !ok {
+				// ValidateConfig rejects these; a member without a stack
+				// number must not be counted as stack member 0
+				e.logger.Warn().Str("device", deviceName).Str("interface", channelName).Str("member", member).
+					Msg("Port-channel member has no stack member number, not counted for per_stack_minimum")
+				continue
+			}
+			if up {
+				activeByStack[stack]++
+			} else if _, seen := activeByStack[stack]; !seen {
+				activeByStack[stack] = 0
+			}
+		}
 	}
-	e.mu.RUnlock()
+	shard.mu.RUnlock()
 
 	if mode == "all_active" && len(downMembers) > 0 {
 		severity := severityForAlert(ifaceCfg, "member_down", "critical")
@@ -336,6 +632,7 @@ func (e *Evaluator) evaluateChannelMembers(deviceName, channelName string, iface
 			Interface: channelName,
 			AlertType: alertTypeMemberDown,
 			Severity:  severity,
+			Firing:    true,
 			Message:   fmt.Sprintf("port-channel %s members down: %s", channelName, strings.Join(downMembers, ", ")),
 			RelatedState: map[string]string{
 				"down_members": strings.Join(downMembers, ","),
@@ -350,6 +647,7 @@ func (e *Evaluator) evaluateChannelMembers(deviceName, channelName string, iface
 			Interface: channelName,
 			AlertType: alertTypeChannelDown,
 			Severity:  severity,
+			Firing:    true,
 			Message:   fmt.Sprintf("port-channel %s active members %d below minimum %d", channelName, active, minimum),
 			RelatedState: map[string]string{
 				"active_members": fmt.Sprintf("%d", active),
@@ -358,6 +656,41 @@ func (e *Evaluator) evaluateChannelMembers(deviceName, channelName string, iface
 		}}
 	}
 
+	if activeByStack != nil && memberPolicy.PerStackMinimum > 0 {
+		// A stack member losing its links leaves the channel up on the
+		// others, but without the redundancy the design relies on
+		stacks := make([]int, 0, len(activeByStack))
+		for stack := range activeByStack {
+			stacks = append(stacks, stack)
+		}
+		sort.Ints(stacks)
+		var short, counts []string
+		for _, stack := range stacks {
+			counts = append(counts, fmt.Sprintf("%d:%d", stack, activeByStack[stack]))
+			if activeByStack[stack] < memberPolicy.PerStackMinimum {
+				short = append(short, fmt.Sprintf("%d (%d active)", stack, activeByStack[stack]))
+			}
+		}
+		if len(short) > 0 {
+			severity := severityForAlert(ifaceCfg, "channel_down", "critical")
+			return []StateChange{{
+				Device:    deviceName,
+				Interface: channelName,
+				AlertType: alertTypeChannelDown,
+				Severity:  severity,
+				Firing:    true,
+				Message: fmt.Sprintf("port-channel %s below %d active members on stack member %s",
+					channelName, memberPolicy.PerStackMinimum, strings.Join(short, ", ")),
+				RelatedState: map[string]string{
+					"active_members":    fmt.Sprintf("%d", active),
+					"active_by_stack":   strings.Join(counts, ","),
+					"per_stack_minimum": fmt.Sprintf("%d", memberPolicy.PerStackMinimum),
+					"down_members":      strings.Join(downMembers, ","),
+				},
+			}}
+		}
+	}
+
 	return nil
 }
 
@@ -378,11 +711,27 @@ func (e *Evaluator) channelNamesForMember(deviceCfg config.DeviceConfig, member
 	return channels
 }
 
-// normalizeState normalizes state values to lowercase
+// normalizeState normalizes state values to lowercase. The common values
+// are matched first so they do not allocate.
 func normalizeState(value string) string {
+	switch value {
+	case "UP", "up":
+		return "up"
+	case "DOWN", "down":
+		return "down"
+	}
 	return strings.ToLower(strings.TrimSpace(value))
 }
 
+// normalizeAdminState maps OpenConfig admin-status values (UP/DOWN) onto the
+// enabled/disabled vocabulary used by admin_state in desired state
+func normalizeAdminState(value string) string {
+	if mapped := config.AdminToDesiredState(value); mapped != "" {
+		return mapped
+	}
+	return normalizeState(value)
+}
+
 // severityForAlert gets severity from config or returns fallback
 func severityForAlert(ifaceCfg config.InterfaceConfig, alertName, fallback string) string {
 	if ifaceCfg.Alerts.StateMismatch != "" && alertName == "state_mismatch" {
@@ -397,5 +746,23 @@ func severityForAlert(ifaceCfg config.InterfaceConfig, alertName, fallback strin
 	if ifaceCfg.Alerts.AdminDown != "" && alertName == "admin_down" {
 		return ifaceCfg.Alerts.AdminDown
 	}
+	if ifaceCfg.Alerts.DescriptionMismatch != "" && alertName == "description_mismatch" {
+		return ifaceCfg.Alerts.DescriptionMismatch
+	}
+	if ifaceCfg.Alerts.TrunkVLANMismatch != "" && alertName == "trunk_vlan_mismatch" {
+		return ifaceCfg.Alerts.TrunkVLANMismatch
+	}
+	if ifaceCfg.Alerts.ACLMissing != "" && alertName == "acl_missing" {
+		return ifaceCfg.Alerts.ACLMissing
+	}
+	if ifaceCfg.Alerts.NACDisabled != "" && alertName == "nac_disabled" {
+		return ifaceCfg.Alerts.NACDisabled
+	}
+	if ifaceCfg.Alerts.ErrorRate != "" && alertName == "error_rate" {
+		return ifaceCfg.Alerts.ErrorRate
+	}
+	if ifaceCfg.Alerts.TrafficAnomaly != "" && alertName == "traffic_anomaly" {
+		return ifaceCfg.Alerts.TrafficAnomaly
+	}
 	return fallback
 }
@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/rs/zerolog"
+)
+
+// interfaceUpdate builds a notification setting one state leaf of an
+// interface, as a device streams it
+func interfaceUpdate(iface, leaf, value string) *gnmi.Notification {
+	return &gnmi.Notification{
+		Update: []*gnmi.Update{{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+				{Name: "interfaces"},
+				{Name: "interface", Key: map[string]string{"name": iface}},
+				{Name: "state"},
+				{Name: leaf},
+			}},
+			Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: value}},
+		}},
+	}
+}
+
+func newInterfaceEvaluator(ifaces map[string]config.InterfaceConfig) *Evaluator {
+	cfg := &config.Config{}
+	cfg.DesiredState.Devices = map[string]config.DeviceConfig{
+		"sw1": {Interfaces: ifaces},
+	}
+	return NewEvaluator(cfg, zerolog.Nop())
+}
+
+func TestIgnoredInterfaceResolvesItsAlerts(t *testing.T) {
+	e := newInterfaceEvaluator(map[string]config.InterfaceConfig{
+		"Ethernet1": {DesiredState: "up"},
+	})
+	e.Config().DesiredState.Global.IgnoreInterfaces.Descriptions = []string{"^spare"}
+
+	changes := e.EvaluateNotification("sw1", interfaceUpdate("Ethernet1", "oper-status", "DOWN"))
+	if len(changes) != 1 || changes[0].AlertType != alertTypeInterfaceMismatch || !changes[0].Firing {
+		t.Fatalf("got %+v, want a firing interface_state_mismatch", changes)
+	}
+
+	// Relabelled as a spare port: ignored from now on, so its alert resolves
+	changes = e.EvaluateNotification("sw1", interfaceUpdate("Ethernet1", "description", "spare port"))
+	if len(changes) != 1 || changes[0].AlertType != alertTypeInterfaceMismatch || changes[0].Firing {
+		t.Fatalf("got %+v, want the interface_state_mismatch resolved", changes)
+	}
+	if want := "interface Ethernet1 is ignored by ignore_interfaces"; changes[0].Message != want {
+		t.Errorf("message %q, want %q", changes[0].Message, want)
+	}
+
+	// Resolved once only
+	if changes = e.EvaluateNotification("sw1", interfaceUpdate("Ethernet1", "oper-status", "DOWN")); len(changes) != 0 {
+		t.Fatalf("got %+v from an ignored interface", changes)
+	}
+}
+
+// benchmarkInterfaces declares a 48-port switch whose ports are all meant to
+// be up
+func benchmarkInterfaces() ([]string, map[string]config.InterfaceConfig) {
+	names := make([]string, 48)
+	ifaces := make(map[string]config.InterfaceConfig, len(names))
+	for i := range names {
+		names[i] = fmt.Sprintf("Ethernet%d", i+1)
+		ifaces[names[i]] = config.InterfaceConfig{DesiredState: "up"}
+	}
+	return names, ifaces
+}
+
+// BenchmarkEvaluateNotification measures the steady stream of unchanged
+// oper-status updates an on-change subscription sends
+func BenchmarkEvaluateNotification(b *testing.B) {
+	names, ifaces := benchmarkInterfaces()
+	e := newInterfaceEvaluator(ifaces)
+	notifications := make([]*gnmi.Notification, len(names))
+	for i, name := range names {
+		notifications[i] = interfaceUpdate(name, "oper-status", "UP")
+		e.EvaluateNotification("sw1", notifications[i])
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.EvaluateNotification("sw1", notifications[i%len(notifications)])
+	}
+}
+
+// BenchmarkEvaluateNotificationCounters measures the sampled counter
+// updates that make up most of a high-rate stream
+func BenchmarkEvaluateNotificationCounters(b *testing.B) {
+	names, ifaces := benchmarkInterfaces()
+	e := newInterfaceEvaluator(ifaces)
+	notifications := make([][]*gnmi.Notification, 2)
+	for round := range notifications {
+		for _, name := range names {
+			n := interfaceUpdate(name, "counters", "")
+			elems := n.Update[0].Path.Elem
+			n.Update = nil
+			for _, leaf := range []string{"in-octets", "out-octets", "in-errors", "out-errors"} {
+				path := append(append([]*gnmi.PathElem{}, elems...), &gnmi.PathElem{Name: leaf})
+				value := strconv.Itoa(1000 * (round + 1))
+				n.Update = append(n.Update, &gnmi.Update{
+					Path: &gnmi.Path{Elem: path},
+					Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: value}},
+				})
+			}
+			notifications[round] = append(notifications[round], n)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		round := notifications[(i/len(names))%2]
+		e.EvaluateNotification("sw1", round[i%len(names)])
+	}
+}
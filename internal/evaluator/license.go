@@ -0,0 +1,94 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// AlertTypeLicenseExpiring fires as a warning when a license or feature
+// term is within its device's warn_days of expiry, and as critical once it
+// has expired
+const AlertTypeLicenseExpiring = "license_expiring"
+
+// LicenseEntity returns the alert entity of a license
+func LicenseEntity(id string) string {
+	return "license:" + id
+}
+
+// EvaluateLicenses checks the licenses read from a device against its
+// license settings. Like EvaluateACLs it returns the state changes for new
+// or changed findings and the entities whose findings have cleared, such
+// as a renewed license. A finding only changes when the license's expiry
+// or severity does, so the countdown does not re-alert every cycle.
+func (e *Evaluator) EvaluateLicenses(deviceName string, licenses []config.License) (changes []StateChange, cleared []string) {
+	cfg := e.Config()
+	if cfg == nil {
+		return nil, nil
+	}
+	deviceCfg := cfg.DesiredState.Devices[deviceName]
+	licCfg := cfg.LicensesFor(deviceCfg)
+	audited := make(map[string]bool)
+	if licCfg == nil || deviceCfg.ExpectedOffline() {
+		return nil, e.sweepFindings(deviceName, AlertTypeLicenseExpiring, audited)
+	}
+	warn, _ := licCfg.Limits()
+	now := time.Now()
+
+	for _, lic := range licenses {
+		entity := LicenseEntity(lic.ID)
+		audited[entity] = true
+
+		expired := lic.Expired || (!lic.ExpiresAt.IsZero() && !lic.ExpiresAt.After(now))
+		var severity, finding, message string
+		name := lic.ID
+		if lic.Description != "" && lic.Description != lic.ID {
+			name = fmt.Sprintf("%s (%s)", lic.ID, lic.Description)
+		}
+		switch {
+		case lic.InUseKnown && !lic.InUse:
+			// Unused licenses, such as lapsed evaluations, are not alerted on
+		case expired:
+			severity = "critical"
+			message = fmt.Sprintf("license %s has expired", name)
+			if !lic.ExpiresAt.IsZero() {
+				message = fmt.Sprintf("license %s expired on %s", name, lic.ExpiresAt.UTC().Format("2006-01-02"))
+			}
+		case !lic.ExpiresAt.IsZero() && lic.ExpiresAt.Sub(now) <= warn:
+			severity = "warning"
+			days := int(math.Ceil(lic.ExpiresAt.Sub(now).Hours() / 24))
+			message = fmt.Sprintf("license %s expires on %s (in %d days)", name, lic.ExpiresAt.UTC().Format("2006-01-02"), days)
+		}
+		if severity != "" {
+			finding = severity + "|" + lic.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+
+		changed, wasCleared := e.recordFinding(findingKey(deviceName, AlertTypeLicenseExpiring, entity), finding)
+		if wasCleared {
+			cleared = append(cleared, entity)
+		}
+		if !changed {
+			continue
+		}
+		related := map[string]string{"license_id": lic.ID}
+		if !lic.ExpiresAt.IsZero() {
+			related["expires_at"] = lic.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		changes = append(changes, StateChange{
+			Device:       deviceName,
+			Interface:    entity,
+			AlertType:    AlertTypeLicenseExpiring,
+			Severity:     severity,
+			Firing:       true,
+			Message:      message,
+			RelatedState: related,
+			ObservedAt:   now,
+		})
+	}
+
+	// Licenses the device no longer reports clear their findings
+	cleared = append(cleared, e.sweepFindings(deviceName, AlertTypeLicenseExpiring, audited)...)
+	return changes, cleared
+}
@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// AlertTypeNACDisabled fires when an interface declaring nac does not
+// enforce 802.1X or port-security as required
+const AlertTypeNACDisabled = "interface_nac_disabled"
+
+// EvaluateNAC compares the 802.1X and port-security state read from a
+// device with each interface's nac declaration. Like EvaluateACLs it
+// returns the state changes for new or changed findings and the interfaces
+// that have become compliant.
+func (e *Evaluator) EvaluateNAC(deviceName string, observed map[string]config.NACState) (changes []StateChange, cleared []string) {
+	cfg := e.Config()
+	if cfg == nil {
+		return nil, nil
+	}
+	deviceCfg := cfg.DesiredState.Devices[deviceName]
+	now := time.Now()
+
+	audited := make(map[string]bool)
+	for ifaceName, ifCfg := range deviceCfg.Interfaces {
+		nac := ifCfg.NAC
+		if nac == nil || deviceCfg.ExpectedOffline() {
+			continue
+		}
+		audited[ifaceName] = true
+
+		state := observed[ifaceName]
+		var problems []string
+		if nac.Dot1X && !state.Dot1X {
+			problems = append(problems, "802.1X authenticator disabled")
+		}
+		if nac.PortSecurity && !state.PortSecurity {
+			problems = append(problems, "port-security disabled")
+		}
+		if nac.PortSecurity && state.PortSecurity && nac.ViolationMode != "" && state.ViolationMode != nac.ViolationMode {
+			problems = append(problems, fmt.Sprintf("port-security violation mode %q, expected %q", state.ViolationMode, nac.ViolationMode))
+		}
+
+		var message string
+		if len(problems) > 0 {
+			message = fmt.Sprintf("interface %s NAC enforcement: %s", ifaceName, strings.Join(problems, "; "))
+		}
+		changed, wasCleared := e.recordFinding(findingKey(deviceName, AlertTypeNACDisabled, ifaceName), message)
+		if wasCleared {
+			cleared = append(cleared, ifaceName)
+		}
+		if !changed {
+			continue
+		}
+		changes = append(changes, StateChange{
+			Device:    deviceName,
+			Interface: ifaceName,
+			AlertType: AlertTypeNACDisabled,
+			Severity:  severityForAlert(ifCfg, "nac_disabled", "critical"),
+			Firing:    true,
+			Message:   message,
+			RelatedState: map[string]string{
+				"dot1x":          strconv.FormatBool(state.Dot1X),
+				"port_security":  strconv.FormatBool(state.PortSecurity),
+				"violation_mode": state.ViolationMode,
+			},
+			ObservedAt: now,
+		})
+	}
+
+	cleared = append(cleared, e.sweepFindings(deviceName, AlertTypeNACDisabled, audited)...)
+	return changes, cleared
+}
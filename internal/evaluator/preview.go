@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// PreviewResult lists the alerts a configuration change would immediately
+// fire or resolve given the currently cached observed state
+type PreviewResult struct {
+	WouldFire    []StateChange
+	WouldResolve []StateChange
+	Unchanged    int
+}
+
+// Preview evaluates a candidate configuration against the cached observed
+// state without applying it, and diffs the result against the active
+// configuration. Interfaces that have not reported state yet are ignored.
+func (e *Evaluator) Preview(candidate *config.Config) PreviewResult {
+	e.mu.RLock()
+	current := e.config
+	e.mu.RUnlock()
+
+	before := e.evaluateAll(current)
+	after := e.evaluateAll(candidate)
+
+	var result PreviewResult
+	for key, change := range after {
+		if _, ok := before[key]; ok {
+			result.Unchanged++
+			continue
+		}
+		result.WouldFire = append(result.WouldFire, change)
+	}
+	for key, change := range before {
+		if _, ok := after[key]; !ok {
+			result.WouldResolve = append(result.WouldResolve, change)
+		}
+	}
+	sortChanges(result.WouldFire)
+	sortChanges(result.WouldResolve)
+	return result
+}
+
+// evaluateAll evaluates every declared interface in cfg against the cached
+// state and returns the conditions that would be alerting, keyed by
+// device|interface|alert type
+func (e *Evaluator) evaluateAll(cfg *config.Config) map[string]StateChange {
+	cache := e.snapshot()
+	now := time.Now()
+
+	firing := make(map[string]StateChange)
+	add := func(change *StateChange) {
+		if change != nil {
+			firing[change.Device+"|"+change.Interface+"|"+change.AlertType] = *change
+		}
+	}
+
+	if cfg == nil {
+		return firing
+	}
+	for deviceName, deviceCfg := range cfg.DesiredState.Devices {
+		for ifaceName, ifCfg := range deviceCfg.Interfaces {
+			state, ok := cache[deviceName][ifaceName]
+			if cfg.IgnoresInterface(deviceName, config.ObservedInterface{Name: ifaceName, Description: state.Description}) {
+				continue
+			}
+			if ok {
+				// A zero previous state makes any admin mismatch count as a transition
+				add(e.evaluateAdminChange(deviceName, ifaceName, ifCfg, interfaceState{}, state))
+				add(e.evaluateOperChange(deviceName, ifaceName, ifCfg, state, now))
+				add(e.evaluateDescription(deviceName, ifaceName, ifCfg, state))
+				add(e.evaluateTrunkVLANs(deviceName, ifaceName, ifCfg, state))
+			}
+			if ifCfg.Members != nil && membersObserved(cache[deviceName], ifCfg.Members.Required) {
+				for _, change := range e.evaluateChannelMembers(deviceName, ifaceName, ifCfg, state) {
+					change := change
+					add(&change)
+				}
+			}
+		}
+	}
+	return firing
+}
+
+// membersObserved reports whether any required member has reported state,
+// so channels on devices that never streamed are not counted as down
+func membersObserved(states map[string]interfaceState, members []string) bool {
+	for _, member := range members {
+		if _, ok := states[member]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sortChanges orders state changes by device, interface and alert type
+func sortChanges(changes []StateChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Device != changes[j].Device {
+			return changes[i].Device < changes[j].Device
+		}
+		if changes[i].Interface != changes[j].Interface {
+			return changes[i].Interface < changes[j].Interface
+		}
+		return changes[i].AlertType < changes[j].AlertType
+	})
+}
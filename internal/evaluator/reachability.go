@@ -0,0 +1,169 @@
+package evaluator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AlertTypeConnectionFailure fires when a device has been unreachable for
+// longer than the connection_alert hold-down
+const AlertTypeConnectionFailure = "connection_failure"
+
+// AlertTypeDevicesReconnecting fires instead of connection_failure while
+// many devices that went down together, as in a firmware campaign, are
+// reconnecting. It lists them and resolves once they have all returned or
+// been alerted on individually.
+const AlertTypeDevicesReconnecting = "devices_reconnecting"
+
+// ConnectionEntity is the alert entity of a device's gNMI connection
+const ConnectionEntity = "gnmi connection"
+
+// FleetDevice is the device of alerts about many devices at once
+const FleetDevice = "fleet"
+
+// outage is a device seen unreachable by EvaluateReachability
+type outage struct {
+	since     time.Time
+	collapsed bool // listed in the devices_reconnecting alert
+	alerted   bool // connection_failure raised
+}
+
+// EvaluateReachability checks which monitored devices are down, as reported
+// by their collectors, and is called for the whole fleet at once so that
+// correlated outages can be recognised. A device down past the hold-down
+// raises connection_failure, unless enough devices went down within the
+// collapse window of each other: those are collapsed into a single
+// devices_reconnecting alert. Collapsed devices that stay down past the
+// straggler timeout leave it for an alert of their own. It returns the
+// state changes, the devices whose connection_failure has cleared, and
+// whether devices_reconnecting has.
+func (e *Evaluator) EvaluateReachability(down map[string]bool) (changes []StateChange, cleared []string, groupCleared bool) {
+	return e.evaluateReachability(down, time.Now())
+}
+
+func (e *Evaluator) evaluateReachability(down map[string]bool, now time.Time) (changes []StateChange, cleared []string, groupCleared bool) {
+	cfg := e.Config()
+	if cfg == nil {
+		return nil, nil, false
+	}
+	settings := cfg.DesiredState.Global.ConnectionAlert
+	holdDown := settings.HoldDownOrDefault()
+	collapseAt, window, straggler := settings.Collapse()
+
+	e.outagesMu.Lock()
+	defer e.outagesMu.Unlock()
+	if e.outages == nil {
+		e.outages = make(map[string]*outage)
+	}
+
+	// Devices that returned, or are no longer monitored, end their outage
+	for name, o := range e.outages {
+		if down[name] && !cfg.DesiredState.Devices[name].ExpectedOffline() {
+			continue
+		}
+		delete(e.outages, name)
+		if o.alerted {
+			cleared = append(cleared, name)
+		}
+	}
+	for name, isDown := range down {
+		if !isDown || cfg.DesiredState.Devices[name].ExpectedOffline() {
+			continue
+		}
+		if _, known := e.outages[name]; !known {
+			e.outages[name] = &outage{since: now}
+		}
+	}
+
+	names := make([]string, 0, len(e.outages))
+	for name := range e.outages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		o := e.outages[name]
+		switch {
+		case o.alerted:
+		case o.collapsed:
+			if now.Sub(o.since) >= straggler {
+				o.collapsed, o.alerted = false, true
+				changes = append(changes, connectionFailure(name, settings.SeverityOrDefault(), o.since, now,
+					fmt.Sprintf("%s has not returned %s after going down with other devices", name, now.Sub(o.since).Round(time.Second))))
+			}
+		case now.Sub(o.since) < holdDown:
+		default:
+			// Outages still pending or collapsed that began close to this
+			// one are correlated with it
+			var correlated []*outage
+			joinGroup := false
+			for _, other := range e.outages {
+				if other.alerted || other.since.Sub(o.since) > window || o.since.Sub(other.since) > window {
+					continue
+				}
+				correlated = append(correlated, other)
+				joinGroup = joinGroup || other.collapsed
+			}
+			if collapseAt > 0 && (joinGroup || len(correlated) >= collapseAt) {
+				for _, other := range correlated {
+					other.collapsed = true
+				}
+				continue
+			}
+			o.alerted = true
+			changes = append(changes, connectionFailure(name, settings.SeverityOrDefault(), o.since, now,
+				fmt.Sprintf("%s unreachable for %s", name, now.Sub(o.since).Round(time.Second))))
+		}
+	}
+
+	// The group alert changes as devices join and leave it
+	var members []string
+	var since time.Time
+	for _, name := range names {
+		if o := e.outages[name]; o != nil && o.collapsed {
+			members = append(members, name)
+			if since.IsZero() || o.since.Before(since) {
+				since = o.since
+			}
+		}
+	}
+	list := strings.Join(members, ",")
+	if list == e.reconnecting {
+		return changes, cleared, false
+	}
+	groupCleared = list == ""
+	e.reconnecting = list
+	if !groupCleared {
+		changes = append(changes, StateChange{
+			Device:    FleetDevice,
+			Interface: ConnectionEntity,
+			AlertType: AlertTypeDevicesReconnecting,
+			Severity:  "warning",
+			Firing:    true,
+			Message:   fmt.Sprintf("%d devices reconnecting: %s", len(members), strings.Join(members, ", ")),
+			RelatedState: map[string]string{
+				"devices":    list,
+				"down_since": since.UTC().Format(time.RFC3339),
+			},
+			ObservedAt: now,
+		})
+	}
+	return changes, cleared, groupCleared
+}
+
+// connectionFailure returns the connection_failure change of a device down
+// since the given time
+func connectionFailure(device, severity string, since, now time.Time, message string) StateChange {
+	return StateChange{
+		Device:       device,
+		Interface:    ConnectionEntity,
+		AlertType:    AlertTypeConnectionFailure,
+		Severity:     severity,
+		Firing:       true,
+		Message:      message,
+		RelatedState: map[string]string{"down_since": since.UTC().Format(time.RFC3339)},
+		ObservedAt:   now,
+	}
+}
@@ -0,0 +1,179 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/rs/zerolog"
+)
+
+func newReachabilityEvaluator(conn *config.ConnectionAlertConfig, devices ...string) *Evaluator {
+	cfg := &config.Config{}
+	cfg.DesiredState.Global.ConnectionAlert = conn
+	cfg.DesiredState.Devices = make(map[string]config.DeviceConfig)
+	for _, name := range devices {
+		cfg.DesiredState.Devices[name] = config.DeviceConfig{}
+	}
+	return NewEvaluator(cfg, zerolog.Nop())
+}
+
+func downSet(names ...string) map[string]bool {
+	down := make(map[string]bool, len(names))
+	for _, name := range names {
+		down[name] = true
+	}
+	return down
+}
+
+func alertTypes(changes []StateChange) []string {
+	var types []string
+	for _, c := range changes {
+		types = append(types, c.AlertType+":"+c.Device)
+	}
+	return types
+}
+
+func TestReachabilityHoldDown(t *testing.T) {
+	e := newReachabilityEvaluator(nil, "sw1")
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if changes, _, _ := e.evaluateReachability(downSet("sw1"), t0); len(changes) != 0 {
+		t.Fatalf("alerted at once: %v", alertTypes(changes))
+	}
+	if changes, _, _ := e.evaluateReachability(downSet("sw1"), t0.Add(30*time.Second)); len(changes) != 0 {
+		t.Fatalf("alerted within hold-down: %v", alertTypes(changes))
+	}
+	// Back before the hold-down ended: nothing fired, nothing to clear
+	changes, cleared, _ := e.evaluateReachability(downSet(), t0.Add(50*time.Second))
+	if len(changes) != 0 || len(cleared) != 0 {
+		t.Fatalf("got changes %v, cleared %v", alertTypes(changes), cleared)
+	}
+
+	e.evaluateReachability(downSet("sw1"), t0.Add(time.Minute))
+	changes, _, _ = e.evaluateReachability(downSet("sw1"), t0.Add(2*time.Minute))
+	if len(changes) != 1 || changes[0].AlertType != AlertTypeConnectionFailure || changes[0].Device != "sw1" {
+		t.Fatalf("got %v, want connection_failure:sw1", alertTypes(changes))
+	}
+	if changes[0].Severity != "critical" {
+		t.Errorf("severity %q, want critical", changes[0].Severity)
+	}
+	// Fired once only
+	if changes, _, _ := e.evaluateReachability(downSet("sw1"), t0.Add(3*time.Minute)); len(changes) != 0 {
+		t.Fatalf("fired again: %v", alertTypes(changes))
+	}
+	_, cleared, _ = e.evaluateReachability(downSet(), t0.Add(4*time.Minute))
+	if len(cleared) != 1 || cleared[0] != "sw1" {
+		t.Fatalf("cleared %v, want [sw1]", cleared)
+	}
+}
+
+func TestReachabilityCollapse(t *testing.T) {
+	e := newReachabilityEvaluator(nil, "sw1", "sw2", "sw3", "sw4")
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	e.evaluateReachability(downSet("sw1"), t0)
+	e.evaluateReachability(downSet("sw1", "sw2"), t0.Add(10*time.Second))
+	e.evaluateReachability(downSet("sw1", "sw2", "sw3"), t0.Add(20*time.Second))
+
+	changes, _, _ := e.evaluateReachability(downSet("sw1", "sw2", "sw3"), t0.Add(90*time.Second))
+	if len(changes) != 1 || changes[0].AlertType != AlertTypeDevicesReconnecting || changes[0].Device != FleetDevice {
+		t.Fatalf("got %v, want one devices_reconnecting", alertTypes(changes))
+	}
+	if got := changes[0].RelatedState["devices"]; got != "sw1,sw2,sw3" {
+		t.Errorf("devices %q, want sw1,sw2,sw3", got)
+	}
+	if changes[0].Severity != "warning" {
+		t.Errorf("severity %q, want warning", changes[0].Severity)
+	}
+
+	// A device going down within the window of the group joins it
+	e.evaluateReachability(downSet("sw1", "sw2", "sw3", "sw4"), t0.Add(100*time.Second))
+	changes, _, _ = e.evaluateReachability(downSet("sw1", "sw2", "sw3", "sw4"), t0.Add(170*time.Second))
+	if len(changes) != 1 || changes[0].RelatedState["devices"] != "sw1,sw2,sw3,sw4" {
+		t.Fatalf("got %v %v, want the group to list sw1-sw4", alertTypes(changes), changes)
+	}
+
+	// Devices returning shrink the list; the last one back clears it
+	changes, cleared, groupCleared := e.evaluateReachability(downSet("sw4"), t0.Add(3*time.Minute))
+	if len(changes) != 1 || changes[0].RelatedState["devices"] != "sw4" || groupCleared || len(cleared) != 0 {
+		t.Fatalf("got %v, cleared %v, group cleared %v", changes, cleared, groupCleared)
+	}
+	changes, cleared, groupCleared = e.evaluateReachability(downSet(), t0.Add(4*time.Minute))
+	if len(changes) != 0 || len(cleared) != 0 || !groupCleared {
+		t.Fatalf("got %v, cleared %v, group cleared %v", alertTypes(changes), cleared, groupCleared)
+	}
+}
+
+func TestReachabilityBelowCollapseThreshold(t *testing.T) {
+	e := newReachabilityEvaluator(nil, "sw1", "sw2", "sw3")
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Two devices together, and a third outside the collapse window
+	e.evaluateReachability(downSet("sw1", "sw2"), t0)
+	changes, _, _ := e.evaluateReachability(downSet("sw1", "sw2", "sw3"), t0.Add(5*time.Minute))
+	later, _, _ := e.evaluateReachability(downSet("sw1", "sw2", "sw3"), t0.Add(6*time.Minute))
+	changes = append(changes, later...)
+	want := []string{"connection_failure:sw1", "connection_failure:sw2", "connection_failure:sw3"}
+	if got := alertTypes(changes); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestReachabilityCollapseDisabled(t *testing.T) {
+	e := newReachabilityEvaluator(&config.ConnectionAlertConfig{CollapseDevices: -1}, "sw1", "sw2", "sw3")
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	e.evaluateReachability(downSet("sw1", "sw2", "sw3"), t0)
+	changes, _, _ := e.evaluateReachability(downSet("sw1", "sw2", "sw3"), t0.Add(2*time.Minute))
+	if len(changes) != 3 {
+		t.Fatalf("got %v, want a connection_failure per device", alertTypes(changes))
+	}
+	for _, c := range changes {
+		if c.AlertType != AlertTypeConnectionFailure {
+			t.Errorf("got %s", c.AlertType)
+		}
+	}
+}
+
+func TestReachabilityStraggler(t *testing.T) {
+	e := newReachabilityEvaluator(nil, "sw1", "sw2", "sw3")
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	e.evaluateReachability(downSet("sw1", "sw2", "sw3"), t0)
+	changes, _, _ := e.evaluateReachability(downSet("sw1", "sw2", "sw3"), t0.Add(2*time.Minute))
+	if len(changes) != 1 || changes[0].AlertType != AlertTypeDevicesReconnecting {
+		t.Fatalf("got %v, want devices_reconnecting", alertTypes(changes))
+	}
+	e.evaluateReachability(downSet("sw3"), t0.Add(5*time.Minute))
+
+	// Still down after the straggler timeout: its own alert, and the group
+	// is left empty
+	changes, cleared, groupCleared := e.evaluateReachability(downSet("sw3"), t0.Add(10*time.Minute))
+	if len(changes) != 1 || changes[0].AlertType != AlertTypeConnectionFailure || changes[0].Device != "sw3" {
+		t.Fatalf("got %v, want connection_failure:sw3", alertTypes(changes))
+	}
+	if !groupCleared || len(cleared) != 0 {
+		t.Fatalf("cleared %v, group cleared %v", cleared, groupCleared)
+	}
+	if got := changes[0].RelatedState["down_since"]; got != t0.Format(time.RFC3339) {
+		t.Errorf("down_since %q, want %s", got, t0.Format(time.RFC3339))
+	}
+
+	_, cleared, _ = e.evaluateReachability(downSet(), t0.Add(11*time.Minute))
+	if len(cleared) != 1 || cleared[0] != "sw3" {
+		t.Fatalf("cleared %v, want [sw3]", cleared)
+	}
+}
+
+func TestReachabilityExpectedOffline(t *testing.T) {
+	e := newReachabilityEvaluator(nil, "sw1")
+	cfg := e.Config()
+	cfg.DesiredState.Devices["sw1"] = config.DeviceConfig{DesiredState: "offline"}
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	e.evaluateReachability(downSet("sw1"), t0)
+	if changes, _, _ := e.evaluateReachability(downSet("sw1"), t0.Add(time.Hour)); len(changes) != 0 {
+		t.Fatalf("alerted on a device expected offline: %v", alertTypes(changes))
+	}
+}
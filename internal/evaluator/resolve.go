@@ -0,0 +1,41 @@
+package evaluator
+
+// trackFiring records whether an interface alert's condition holds after an
+// evaluation. When a condition recorded as holding has cleared, it returns
+// the resolution to pass to the alerter so the alert resolves and recovery
+// notifications go out. The caller must not hold shard.mu.
+func trackFiring(shard *deviceShard, deviceName, ifaceName, alertType string, firing bool, message string) *StateChange {
+	key := alertType + "|" + ifaceName
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if firing {
+		if shard.firing == nil {
+			shard.firing = make(map[string]bool)
+		}
+		shard.firing[key] = true
+		return nil
+	}
+	if !shard.firing[key] {
+		return nil
+	}
+	delete(shard.firing, key)
+	return &StateChange{
+		Device:    deviceName,
+		Interface: ifaceName,
+		AlertType: alertType,
+		Message:   message,
+	}
+}
+
+// RestoreFiring records an alert restored from before a restart as firing,
+// so its resolution is emitted once the condition is seen to have cleared
+func (e *Evaluator) RestoreFiring(deviceName, ifaceName, alertType string) {
+	shard := e.shard(deviceName)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if shard.firing == nil {
+		shard.firing = make(map[string]bool)
+	}
+	shard.firing[alertType+"|"+ifaceName] = true
+}
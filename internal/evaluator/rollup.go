@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// AlertTypeDeviceCompliance fires when more of a device's monitored
+// interfaces deviate at once than its compliance_alert allows
+const AlertTypeDeviceCompliance = "device_compliance_low"
+
+// DeviceEntity is the alert entity of alerts on a device as a whole
+const DeviceEntity = "device"
+
+// evaluateDeviceCompliance counts a device's deviating interfaces after
+// their state changed. It returns the rollup alert when the device crosses
+// its compliance_alert threshold and the resolution once it is back under
+// it; the interface alerts are raised as usual either way. Interfaces not
+// yet reported count as monitored but not deviating.
+func (e *Evaluator) evaluateDeviceCompliance(cfg *config.Config, deviceName string, now time.Time) []StateChange {
+	deviceCfg, ok := cfg.DesiredState.Devices[deviceName]
+	if !ok {
+		return nil
+	}
+	shard, ok := e.lookupShard(deviceName)
+	if !ok {
+		return nil
+	}
+	rollup := cfg.ComplianceAlertFor(deviceCfg)
+
+	monitored := 0
+	var deviating []string
+	shard.mu.RLock()
+	for ifaceName := range deviceCfg.Interfaces {
+		state, cached := shard.states[ifaceName]
+		if cached && state.Ignored {
+			continue
+		}
+		monitored++
+		if cached && !state.DeviatedSince.IsZero() {
+			deviating = append(deviating, ifaceName)
+		}
+	}
+	wasFiring := shard.firing[AlertTypeDeviceCompliance+"|"+DeviceEntity]
+	shard.mu.RUnlock()
+
+	firing := rollup != nil && rollup.Exceeded(len(deviating), monitored)
+	resolved := trackFiring(shard, deviceName, DeviceEntity, AlertTypeDeviceCompliance, firing,
+		fmt.Sprintf("%d of %d monitored interfaces deviating", len(deviating), monitored))
+	if resolved != nil {
+		resolved.ObservedAt = now
+		return []StateChange{*resolved}
+	}
+	if !firing || wasFiring {
+		return nil
+	}
+
+	sort.Strings(deviating)
+	percent := float64(len(deviating)) * 100 / float64(monitored)
+	return []StateChange{{
+		Device:    deviceName,
+		Interface: DeviceEntity,
+		AlertType: AlertTypeDeviceCompliance,
+		Severity:  rollup.SeverityOrDefault(),
+		Firing:    true,
+		Message: fmt.Sprintf("%d of %d monitored interfaces deviating (%.0f%%), possibly a failed module or stack member",
+			len(deviating), monitored, percent),
+		RelatedState: map[string]string{
+			"deviating_count":      strconv.Itoa(len(deviating)),
+			"monitored_count":      strconv.Itoa(monitored),
+			"deviating_interfaces": strings.Join(deviating, ","),
+		},
+		ObservedAt: now,
+	}}
+}
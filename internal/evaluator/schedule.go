@@ -0,0 +1,119 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// scheduleInterval is how often time-of-day desired states are checked for
+// a change of window
+const scheduleInterval = 30 * time.Second
+
+// RunSchedules re-evaluates interfaces with time-of-day desired states as
+// their windows open and close, passing resulting state changes to handle,
+// until ctx is cancelled
+func (e *Evaluator) RunSchedules(ctx context.Context, handle func(StateChange)) {
+	e.EvaluateSchedules(time.Now())
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, change := range e.EvaluateSchedules(now) {
+				handle(change)
+			}
+		}
+	}
+}
+
+// EvaluateSchedules re-evaluates the cached state of every scheduled
+// interface whose desired state at now differs from the one seen on the
+// previous call. Without it, a window boundary would go unnoticed until the
+// interface next reported state. The first sighting of an interface only
+// records its desired state.
+func (e *Evaluator) EvaluateSchedules(now time.Time) []StateChange {
+	cfg := e.Config()
+	if cfg == nil {
+		return nil
+	}
+
+	e.scheduledMu.Lock()
+	defer e.scheduledMu.Unlock()
+	if e.scheduled == nil {
+		e.scheduled = make(map[string]string)
+	}
+
+	var changes []StateChange
+	seen := make(map[string]bool)
+	rescheduled := make(map[string]bool) // devices to re-check the compliance rollup of
+	for deviceName, deviceCfg := range cfg.DesiredState.Devices {
+		if deviceCfg.ExpectedOffline() {
+			continue
+		}
+		for ifaceName, ifCfg := range deviceCfg.Interfaces {
+			if len(ifCfg.Schedule) == 0 {
+				continue
+			}
+			key := deviceName + "|" + ifaceName
+			desired := ifCfg.DesiredStateAt(now)
+			previous, known := e.scheduled[key]
+			e.scheduled[key] = desired
+			seen[key] = true
+			if !known || previous == desired {
+				continue
+			}
+
+			s, ok := e.lookupShard(deviceName)
+			if !ok {
+				continue
+			}
+			s.mu.Lock()
+			state, cached := s.states[ifaceName]
+			cached = cached && !state.Ignored
+			if cached {
+				if isDeviating(ifCfg, state, now) {
+					if state.DeviatedSince.IsZero() {
+						state.DeviatedSince = now
+					}
+				} else {
+					state.DeviatedSince = time.Time{}
+				}
+				s.states[ifaceName] = state
+			}
+			s.mu.Unlock()
+			if !cached {
+				continue
+			}
+			rescheduled[deviceName] = true
+
+			e.logger.Info().
+				Str("device", deviceName).
+				Str("interface", ifaceName).
+				Str("previous", previous).
+				Str("desired", desired).
+				Msg("Scheduled desired state changed")
+			change := e.evaluateOperChange(deviceName, ifaceName, ifCfg, state, now)
+			if change != nil {
+				change.ObservedAt = now
+				changes = append(changes, *change)
+			}
+			if resolved := trackFiring(s, deviceName, ifaceName, alertTypeInterfaceMismatch, change != nil || adminMismatch(ifCfg, state),
+				fmt.Sprintf("interface %s matches its scheduled desired state", ifaceName)); resolved != nil {
+				resolved.ObservedAt = now
+				changes = append(changes, *resolved)
+			}
+		}
+	}
+	for key := range e.scheduled {
+		if !seen[key] {
+			delete(e.scheduled, key)
+		}
+	}
+	for deviceName := range rescheduled {
+		changes = append(changes, e.evaluateDeviceCompliance(cfg, deviceName, now)...)
+	}
+	return changes
+}
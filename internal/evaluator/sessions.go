@@ -0,0 +1,63 @@
+package evaluator
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/netspec/netspec/internal/metrics"
+)
+
+// AlertTypeSessionLimit fires when NetSpec holds nearly as many concurrent
+// gNMI sessions to a device as its platform profile accepts
+const AlertTypeSessionLimit = "gnmi_session_limit"
+
+// SessionsEntity is the alert entity of a device's gNMI sessions
+const SessionsEntity = "gnmi sessions"
+
+var gnmiSessions = metrics.NewGaugeVec(
+	"netspec_gnmi_sessions",
+	"Most concurrent gNMI sessions held to the device over the last check, by device",
+	"device")
+
+// EvaluateSessions checks the most gNMI sessions held to a device at once
+// since the last check against the session limit of its profile. Like
+// EvaluateStreamSkew it returns a state change when the finding is new and
+// reports whether a previous finding has cleared. Devices without a
+// profile are only measured.
+func (e *Evaluator) EvaluateSessions(deviceName string, peak int) (changes []StateChange, cleared bool) {
+	cfg := e.Config()
+	if cfg == nil {
+		return nil, false
+	}
+	gnmiSessions.With(deviceName).Set(float64(peak))
+
+	deviceCfg := cfg.DesiredState.Devices[deviceName]
+	limit := cfg.SessionLimitFor(deviceCfg)
+	key := findingKey(deviceName, AlertTypeSessionLimit, SessionsEntity)
+	settings := cfg.DesiredState.Global.GNMISessions
+	if limit == 0 || peak < settings.WarnAt(limit) {
+		_, cleared = e.recordFinding(key, "")
+		return nil, cleared
+	}
+
+	changed, _ := e.recordFinding(key, "near_limit")
+	if !changed {
+		return nil, false
+	}
+	return []StateChange{{
+		Device:    deviceName,
+		Interface: SessionsEntity,
+		AlertType: AlertTypeSessionLimit,
+		Severity:  settings.SeverityOrDefault(),
+		Firing:    true,
+		Message: fmt.Sprintf("NetSpec held %d of the %d gNMI sessions %s accepts: further Gets or reconnects may be refused",
+			peak, limit, deviceCfg.Profile),
+		RelatedState: map[string]string{
+			"sessions":     strconv.Itoa(peak),
+			"max_sessions": strconv.Itoa(limit),
+			"profile":      deviceCfg.Profile,
+		},
+		ObservedAt: time.Now(),
+	}}, false
+}
@@ -0,0 +1,65 @@
+package evaluator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/netspec/netspec/internal/metrics"
+)
+
+// AlertTypeStreamSkew fires when the timestamps of a device's gNMI
+// notifications drift from the time they arrive by more than the
+// stream_skew threshold
+const AlertTypeStreamSkew = "gnmi_stream_skew"
+
+// StreamEntity is the alert entity of a device's gNMI stream
+const StreamEntity = "gnmi stream"
+
+var streamSkew = metrics.NewGaugeVec(
+	"netspec_gnmi_stream_skew_seconds",
+	"Receive time minus notification timestamp, averaged over recent notifications, by device",
+	"device")
+
+// EvaluateStreamSkew checks a device's stream skew, as reported in its
+// collector's health, against the stream_skew threshold in either
+// direction. Like EvaluateCertificate it returns a state change when the
+// finding is new and reports whether a previous finding has cleared. An
+// unmeasured skew, before notifications arrive on a synced stream, changes
+// nothing.
+func (e *Evaluator) EvaluateStreamSkew(deviceName string, skew time.Duration, measured bool) (changes []StateChange, cleared bool) {
+	cfg := e.Config()
+	if cfg == nil || !measured {
+		return nil, false
+	}
+	streamSkew.With(deviceName).Set(skew.Seconds())
+
+	settings := cfg.DesiredState.Global.StreamSkew
+	threshold := settings.ThresholdOrDefault()
+	key := findingKey(deviceName, AlertTypeStreamSkew, StreamEntity)
+	if skew < threshold && skew > -threshold {
+		_, cleared = e.recordFinding(key, "")
+		return nil, cleared
+	}
+
+	changed, _ := e.recordFinding(key, "skewed")
+	if !changed {
+		return nil, false
+	}
+	message := fmt.Sprintf("gNMI notifications arrive %s after their timestamps: the device may be overloaded or its clock behind", skew.Round(time.Second))
+	if skew < 0 {
+		message = fmt.Sprintf("gNMI notification timestamps are %s ahead of their arrival: the device clock is ahead", (-skew).Round(time.Second))
+	}
+	return []StateChange{{
+		Device:    deviceName,
+		Interface: StreamEntity,
+		AlertType: AlertTypeStreamSkew,
+		Severity:  settings.SeverityOrDefault(),
+		Firing:    true,
+		Message:   message,
+		RelatedState: map[string]string{
+			"stream_skew": skew.Round(time.Millisecond).String(),
+			"threshold":   threshold.String(),
+		},
+		ObservedAt: time.Now(),
+	}}, false
+}
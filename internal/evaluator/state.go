@@ -0,0 +1,106 @@
+package evaluator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// InterfaceState is a snapshot of the cached observed state of an interface
+type InterfaceState struct {
+	Device        string     `json:"device"`
+	Interface     string     `json:"interface"`
+	OperStatus    string     `json:"oper_status"`
+	AdminStatus   string     `json:"admin_status"`
+	Description   string     `json:"description,omitempty"`
+	TrunkVLANs    string     `json:"trunk_vlans,omitempty"`
+	Ignored       bool       `json:"ignored,omitempty"`
+	Members       []string   `json:"members"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeviatedSince *time.Time `json:"deviated_since,omitempty"`
+}
+
+// SetConfig swaps in a reloaded configuration. Cached state is kept so
+// evaluation continues seamlessly across reloads.
+func (e *Evaluator) SetConfig(cfg *config.Config) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.config = cfg
+}
+
+// GetAllState returns every cached interface state, sorted by device and
+// interface name
+func (e *Evaluator) GetAllState() []InterfaceState {
+	cached := e.cachedStates()
+	states := make([]InterfaceState, 0, len(cached))
+	for _, state := range cached {
+		if !state.Ignored {
+			states = append(states, exportState(state))
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Device != states[j].Device {
+			return states[i].Device < states[j].Device
+		}
+		return states[i].Interface < states[j].Interface
+	})
+	return states
+}
+
+// GetDeviceState returns the cached state of every observed interface on a
+// device, sorted by interface name
+func (e *Evaluator) GetDeviceState(deviceName string) []InterfaceState {
+	states := make([]InterfaceState, 0)
+	s, ok := e.lookupShard(deviceName)
+	if !ok {
+		return states
+	}
+	s.mu.RLock()
+	for _, state := range s.states {
+		if !state.Ignored {
+			states = append(states, exportState(state))
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Interface < states[j].Interface
+	})
+	return states
+}
+
+// GetInterfaceState returns the cached state of a single interface,
+// including one that is ignored
+func (e *Evaluator) GetInterfaceState(deviceName, ifaceName string) (InterfaceState, bool) {
+	s, ok := e.lookupShard(deviceName)
+	if !ok {
+		return InterfaceState{}, false
+	}
+	s.mu.RLock()
+	state, ok := s.states[ifaceName]
+	s.mu.RUnlock()
+	if !ok {
+		return InterfaceState{}, false
+	}
+	return exportState(state), true
+}
+
+// exportState copies a cache entry into its exported form
+func exportState(state interfaceState) InterfaceState {
+	exported := InterfaceState{
+		Device:      state.Device,
+		Interface:   state.Interface,
+		OperStatus:  state.OperStatus,
+		AdminStatus: state.AdminStatus,
+		Description: state.Description,
+		TrunkVLANs:  state.TrunkVLANs,
+		Ignored:     state.Ignored,
+		Members:     append([]string{}, state.Members...),
+		UpdatedAt:   state.UpdatedAt,
+	}
+	if !state.DeviatedSince.IsZero() {
+		since := state.DeviatedSince
+		exported.DeviatedSince = &since
+	}
+	return exported
+}
@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+)
+
+// Transition is an observed change of an interface's oper or admin status
+type Transition struct {
+	Device    string
+	Interface string
+	Field     string // "oper-status" or "admin-status"
+	Previous  string // empty on first observation
+	Current   string
+	At        time.Time
+}
+
+// TransitionFunc receives interface status transitions as they are observed
+type TransitionFunc func(Transition)
+
+// AddTransitionHook registers a function called for every status transition
+// of a declared interface. Hooks are called outside the evaluator's lock, on
+// the collector's goroutine, so they must not block.
+func (e *Evaluator) AddTransitionHook(fn TransitionFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onTransition = append(e.onTransition, fn)
+}
+
+// RateSample is one direction's bit rate, derived from consecutive octet
+// counter readings of a declared interface
+type RateSample struct {
+	Device    string
+	Interface string
+	Direction string // "receive" or "transmit"
+	BPS       float64
+	At        time.Time
+}
+
+// RateFunc receives interface bit rates as they are measured
+type RateFunc func(RateSample)
+
+// AddRateHook registers a function called for every measured bit rate. Like
+// transition hooks, rate hooks run outside the shard lock on the collector's
+// goroutine and must not block.
+func (e *Evaluator) AddRateHook(fn RateFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onRate = append(e.onRate, fn)
+}
+
+// ErrorSample is the packets and input errors an interface received between
+// two counter readings
+type ErrorSample struct {
+	Device    string
+	Interface string
+	Packets   uint64
+	Errors    uint64
+	At        time.Time
+}
+
+// ErrorFunc receives interface error counts as they are measured
+type ErrorFunc func(ErrorSample)
+
+// AddErrorHook registers a function called with the packets and errors
+// received since each interface's previous counter reading. It runs like a
+// rate hook.
+func (e *Evaluator) AddErrorHook(fn ErrorFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onErrors = append(e.onErrors, fn)
+}
+
+// Config returns the configuration the evaluator currently checks against
+func (e *Evaluator) Config() *config.Config {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.config
+}
@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// trunkVLANs reads an openconfig-vlan trunk-vlans leaf-list, whose members
+// are VLAN IDs or "lo..hi" ranges, as a leaf-list, scalar or JSON value
+func trunkVLANs(val *gnmi.TypedValue) ([]int, error) {
+	var items []string
+	add := func(v *gnmi.TypedValue) {
+		switch x := v.GetValue().(type) {
+		case *gnmi.TypedValue_UintVal:
+			items = append(items, strconv.FormatUint(x.UintVal, 10))
+		case *gnmi.TypedValue_IntVal:
+			items = append(items, strconv.FormatInt(x.IntVal, 10))
+		case *gnmi.TypedValue_StringVal:
+			items = append(items, x.StringVal)
+		}
+	}
+	switch x := val.GetValue().(type) {
+	case *gnmi.TypedValue_LeaflistVal:
+		for _, element := range x.LeaflistVal.GetElement() {
+			add(element)
+		}
+	case *gnmi.TypedValue_JsonIetfVal:
+		return jsonVLANs(x.JsonIetfVal)
+	case *gnmi.TypedValue_JsonVal:
+		return jsonVLANs(x.JsonVal)
+	default:
+		add(val)
+	}
+	return config.ParseVLANs(items)
+}
+
+// jsonVLANs reads a JSON array of VLAN IDs and range strings
+func jsonVLANs(data []byte) ([]int, error) {
+	var raw []interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	items := make([]string, 0, len(raw))
+	for _, v := range raw {
+		items = append(items, fmt.Sprint(v))
+	}
+	return config.ParseVLANs(items)
+}
+
+// evaluateTrunkVLANs compares the trunk's allowed VLANs against
+// trunk_allowed_vlans, reporting missing and extra VLANs
+func (e *Evaluator) evaluateTrunkVLANs(deviceName, ifaceName string, ifCfg config.InterfaceConfig, ifaceState interfaceState) *StateChange {
+	if len(ifCfg.TrunkAllowedVLANs) == 0 || !ifaceState.TrunkVLANsSeen {
+		return nil
+	}
+	expected, err := config.ParseVLANs(ifCfg.TrunkAllowedVLANs)
+	if err != nil {
+		return nil
+	}
+	actual, _ := config.ParseVLANs([]string{ifaceState.TrunkVLANs})
+
+	want := make(map[int]bool, len(expected))
+	for _, id := range expected {
+		want[id] = true
+	}
+	have := make(map[int]bool, len(actual))
+	var extra []int
+	for _, id := range actual {
+		have[id] = true
+		if !want[id] {
+			extra = append(extra, id)
+		}
+	}
+	var missing []int
+	for _, id := range expected {
+		if !have[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 && len(extra) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("interface %s trunk VLANs differ:", ifaceName)
+	if len(missing) > 0 {
+		message += " missing " + config.FormatVLANs(missing)
+	}
+	if len(extra) > 0 {
+		if len(missing) > 0 {
+			message += ";"
+		}
+		message += " extra " + config.FormatVLANs(extra)
+	}
+	return &StateChange{
+		Device:    deviceName,
+		Interface: ifaceName,
+		AlertType: alertTypeTrunkVLANMismatch,
+		Severity:  severityForAlert(ifCfg, "trunk_vlan_mismatch", "warning"),
+		Firing:    true,
+		Message:   message,
+		RelatedState: map[string]string{
+			"expected_vlans": config.FormatVLANs(expected),
+			"actual_vlans":   ifaceState.TrunkVLANs,
+			"missing_vlans":  config.FormatVLANs(missing),
+			"extra_vlans":    config.FormatVLANs(extra),
+		},
+	}
+}
@@ -0,0 +1,135 @@
+package eventbus
+
+import (
+	"time"
+
+	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/netspec/netspec/internal/types"
+	"github.com/rs/zerolog"
+)
+
+// Event types
+const (
+	TypeStateChange       = "state_change"
+	TypeAlertFired        = "alert_fired"
+	TypeAlertResolved     = "alert_resolved"
+	TypeAlertAcknowledged = "alert_acknowledged"
+)
+
+// Event is the JSON document published for every state change and alert
+// lifecycle event
+type Event struct {
+	Type         string            `json:"type"`
+	Time         time.Time         `json:"time"`
+	Device       string            `json:"device"`
+	Entity       string            `json:"entity"`
+	AlertType    string            `json:"alert_type"`
+	Severity     string            `json:"severity"`
+	Message      string            `json:"message"`
+	RelatedState map[string]string `json:"related_state,omitempty"`
+	Asset        map[string]string `json:"asset,omitempty"`
+	AlertID      string            `json:"alert_id,omitempty"`
+	DedupKey     string            `json:"dedup_key,omitempty"`
+	State        string            `json:"state,omitempty"`
+}
+
+// StateChangeEvent builds an event from an evaluator state change. A
+// resolution carries state "resolved".
+func StateChangeEvent(change evaluator.StateChange) Event {
+	state := "firing"
+	if !change.Firing {
+		state = "resolved"
+	}
+	return Event{
+		Type:         TypeStateChange,
+		Time:         time.Now().UTC(),
+		Device:       change.Device,
+		Entity:       change.Interface,
+		AlertType:    change.AlertType,
+		Severity:     change.Severity,
+		Message:      change.Message,
+		RelatedState: change.RelatedState,
+		State:        state,
+	}
+}
+
+// AlertEvent builds a lifecycle event of the given type from an alert
+func AlertEvent(eventType string, alert types.Alert) Event {
+	return Event{
+		Type:         eventType,
+		Time:         time.Now().UTC(),
+		Device:       alert.Device,
+		Entity:       alert.Entity,
+		AlertType:    alert.AlertType,
+		Severity:     alert.Severity,
+		Message:      alert.Message,
+		RelatedState: alert.RelatedState,
+		Asset:        alert.Asset,
+		AlertID:      alert.ID,
+		DedupKey:     alert.DedupKey,
+		State:        alert.State,
+	}
+}
+
+// Publisher delivers events to one external system
+type Publisher interface {
+	Name() string
+	Publish(ev Event) error
+	Close() error
+}
+
+// Bus fans events out to publishers from a single background goroutine so
+// callers never block on the network
+type Bus struct {
+	publishers []Publisher
+	events     chan Event
+	logger     zerolog.Logger
+	done       chan struct{}
+}
+
+// NewBus creates a bus and starts delivering to the given publishers
+func NewBus(logger zerolog.Logger, publishers ...Publisher) *Bus {
+	b := &Bus{
+		publishers: publishers,
+		events:     make(chan Event, 1000),
+		logger:     logger.With().Str("component", "eventbus").Logger(),
+		done:       make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Emit queues an event for publishing, dropping it if the queue is full
+func (b *Bus) Emit(ev Event) {
+	select {
+	case b.events <- ev:
+	default:
+		b.logger.Warn().Str("type", ev.Type).Msg("Event queue full, dropping event")
+	}
+}
+
+// Close stops delivery and closes all publishers. Emit must not be called
+// after Close.
+func (b *Bus) Close() {
+	close(b.events)
+	<-b.done
+	for _, p := range b.publishers {
+		p.Close()
+	}
+}
+
+// run delivers queued events until the bus is closed
+func (b *Bus) run() {
+	defer close(b.done)
+	for ev := range b.events {
+		for _, p := range b.publishers {
+			if err := p.Publish(ev); err != nil {
+				b.logger.Error().
+					Err(err).
+					Str("publisher", p.Name()).
+					Str("type", ev.Type).
+					Msg("Failed to publish event")
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package eventbus
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsDialTimeout bounds connecting to the NATS server
+const natsDialTimeout = 5 * time.Second
+
+// NATSPublisher publishes events to a NATS subject using the core NATS text
+// protocol. It connects lazily and reconnects after any error.
+type NATSPublisher struct {
+	addr    string
+	subject string
+	token   string
+	mu      sync.Mutex
+	conn    net.Conn
+}
+
+// NewNATSPublisher creates a publisher for a nats:// URL
+func NewNATSPublisher(rawURL, subject, token string) (*NATSPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("invalid NATS URL %q: scheme must be nats://", rawURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	if strings.ContainsAny(subject, " \t\r\n") {
+		return nil, fmt.Errorf("invalid NATS subject %q", subject)
+	}
+	return &NATSPublisher{addr: addr, subject: subject, token: token}, nil
+}
+
+// Name identifies the publisher in logs
+func (p *NATSPublisher) Name() string {
+	return "nats"
+}
+
+// Publish sends one event as a JSON message
+func (p *NATSPublisher) Publish(ev Event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\n", p.subject, len(payload), payload)
+	p.conn.SetWriteDeadline(time.Now().Add(natsDialTimeout))
+	if _, err := p.conn.Write([]byte(msg)); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+// connect dials the server, reads INFO, sends CONNECT and starts answering
+// server PINGs. The caller must hold p.mu.
+func (p *NATSPublisher) connect() error {
+	conn, err := net.DialTimeout("tcp", p.addr, natsDialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(natsDialTimeout))
+	info, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected NATS greeting: %q", strings.TrimSpace(info))
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	opts := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "netspec",
+		"lang":     "go",
+	}
+	if p.token != "" {
+		opts["auth_token"] = p.token
+	}
+	connect, _ := json.Marshal(opts)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", connect); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	p.conn = conn
+	go p.readLoop(conn, reader)
+	return nil
+}
+
+// readLoop answers PINGs so the server keeps the connection open, and drops
+// the connection on -ERR or read failure so the next publish reconnects
+func (p *NATSPublisher) readLoop(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err == nil && strings.HasPrefix(line, "PING") {
+			p.mu.Lock()
+			_, err = conn.Write([]byte("PONG\r\n"))
+			p.mu.Unlock()
+		}
+		if err == nil && !strings.HasPrefix(line, "-ERR") {
+			continue
+		}
+
+		p.mu.Lock()
+		if p.conn == conn {
+			p.conn.Close()
+			p.conn = nil
+		}
+		p.mu.Unlock()
+		return
+	}
+}
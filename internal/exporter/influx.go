@@ -0,0 +1,204 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultInterval    = time.Minute
+	defaultMeasurement = "netspec"
+	// maxPendingLines bounds the buffer kept while the database is unreachable
+	maxPendingLines = 10000
+)
+
+// InfluxExporter writes interface status transitions and per-device
+// compliance to InfluxDB (or anything accepting line protocol, such as a
+// Telegraf listener in front of TimescaleDB) on an interval
+type InfluxExporter struct {
+	cfg       config.InfluxExporterConfig
+	evaluator *evaluator.Evaluator
+	logger    zerolog.Logger
+	client    *http.Client
+	mu        sync.Mutex
+	pending   []string
+}
+
+// NewInfluxExporter creates an exporter reading compliance from eval
+func NewInfluxExporter(cfg config.InfluxExporterConfig, eval *evaluator.Evaluator, logger zerolog.Logger) *InfluxExporter {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Measurement == "" {
+		cfg.Measurement = defaultMeasurement
+	}
+	return &InfluxExporter{
+		cfg:       cfg,
+		evaluator: eval,
+		logger:    logger.With().Str("component", "influx-exporter").Logger(),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// RecordTransition buffers an interface status transition for the next write.
+// It matches evaluator.TransitionFunc.
+func (x *InfluxExporter) RecordTransition(t evaluator.Transition) {
+	line := fmt.Sprintf("%s_interface_transition,device=%s,interface=%s,field=%s previous=%s,current=%s %d",
+		x.cfg.Measurement,
+		escapeTag(t.Device), escapeTag(t.Interface), escapeTag(t.Field),
+		quoteField(t.Previous), quoteField(t.Current),
+		t.At.UnixNano())
+	x.buffer([]string{line})
+}
+
+// Run samples compliance and flushes buffered lines every interval until ctx
+// is cancelled
+func (x *InfluxExporter) Run(ctx context.Context) {
+	x.logger.Info().
+		Str("url", x.cfg.URL).
+		Dur("interval", x.cfg.Interval).
+		Msg("Influx exporter started")
+
+	ticker := time.NewTicker(x.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			x.buffer(x.complianceLines(now))
+			x.flush()
+		}
+	}
+}
+
+// complianceLines renders per-device compliance at time now
+func (x *InfluxExporter) complianceLines(now time.Time) []string {
+	cfg := x.evaluator.Config()
+	if cfg == nil {
+		return nil
+	}
+
+	deviating := make(map[string]int)
+	for _, d := range x.evaluator.GetDeviations() {
+		deviating[d.Device]++
+	}
+
+	lines := make([]string, 0, len(cfg.DesiredState.Devices))
+	for name, dev := range cfg.DesiredState.Devices {
+		declared := len(dev.Interfaces)
+		percent := 100.0
+		if declared > 0 {
+			percent = float64(declared-deviating[name]) / float64(declared) * 100
+		}
+		tags := "device=" + escapeTag(name)
+		if dev.Site != "" {
+			tags += ",site=" + escapeTag(dev.Site)
+		}
+		lines = append(lines, fmt.Sprintf("%s_compliance,%s declared=%di,deviating=%di,percent=%s %d",
+			x.cfg.Measurement, tags, declared, deviating[name],
+			strconv.FormatFloat(percent, 'f', -1, 64), now.UnixNano()))
+	}
+	return lines
+}
+
+// buffer appends lines, dropping the oldest beyond maxPendingLines
+func (x *InfluxExporter) buffer(lines []string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.pending = append(x.pending, lines...)
+	if over := len(x.pending) - maxPendingLines; over > 0 {
+		x.pending = x.pending[over:]
+		x.logger.Warn().Int("dropped", over).Msg("Export buffer full, dropping oldest lines")
+	}
+}
+
+// flush writes all buffered lines, keeping them for the next attempt on error
+func (x *InfluxExporter) flush() {
+	x.mu.Lock()
+	lines := x.pending
+	x.pending = nil
+	x.mu.Unlock()
+
+	if len(lines) == 0 {
+		return
+	}
+	if err := x.write(lines); err != nil {
+		x.logger.Error().Err(err).Int("lines", len(lines)).Msg("Failed to write to InfluxDB, will retry")
+		x.mu.Lock()
+		x.pending = append(lines, x.pending...)
+		x.mu.Unlock()
+		x.buffer(nil)
+		return
+	}
+	x.logger.Debug().Int("lines", len(lines)).Msg("Exported to InfluxDB")
+}
+
+// write posts lines to the v2 or v1 write endpoint
+func (x *InfluxExporter) write(lines []string) error {
+	base := strings.TrimSuffix(x.cfg.URL, "/")
+	params := url.Values{"precision": {"ns"}}
+	var endpoint string
+	if x.cfg.Bucket != "" {
+		params.Set("bucket", x.cfg.Bucket)
+		if x.cfg.Org != "" {
+			params.Set("org", x.cfg.Org)
+		}
+		endpoint = base + "/api/v2/write?" + params.Encode()
+	} else {
+		params.Set("db", x.cfg.Database)
+		endpoint = base + "/write?" + params.Encode()
+	}
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewBufferString(strings.Join(lines, "\n")))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if x.cfg.TokenEnv != "" {
+		if token := os.Getenv(x.cfg.TokenEnv); token != "" {
+			req.Header.Set("Authorization", "Token "+token)
+		}
+	}
+
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("write error: %d - %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// escapeTag escapes a line-protocol tag key or value
+func escapeTag(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `).Replace(s)
+}
+
+// quoteField renders a line-protocol string field value
+func quoteField(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,271 @@
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/evaluator"
+	"github.com/rs/zerolog"
+)
+
+const (
+	mqttKeepAlive   = 60 * time.Second
+	mqttDialTimeout = 5 * time.Second
+	mqttRetryDelay  = 10 * time.Second
+)
+
+// InterfaceCompliance is the retained MQTT payload for one interface
+type InterfaceCompliance struct {
+	Device       string    `json:"device"`
+	Interface    string    `json:"interface"`
+	DesiredOper  string    `json:"desired_oper"`
+	ActualOper   string    `json:"actual_oper"`
+	DesiredAdmin string    `json:"desired_admin,omitempty"`
+	ActualAdmin  string    `json:"actual_admin,omitempty"`
+	Compliant    bool      `json:"compliant"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// mqttMessage is a queued retained publish
+type mqttMessage struct {
+	topic   string
+	payload []byte
+}
+
+// MQTTPublisher keeps a retained message per interface on an MQTT broker so
+// subscribers always receive the latest compliance on subscribe. It speaks
+// MQTT 3.1.1 at QoS 0 and reconnects on failure, republishing every
+// interface after each connect.
+type MQTTPublisher struct {
+	cfg       config.MQTTConfig
+	addr      string
+	password  string
+	evaluator *evaluator.Evaluator
+	logger    zerolog.Logger
+	queue     chan mqttMessage
+}
+
+// NewMQTTPublisher creates a publisher for a tcp:// (or mqtt://) broker URL
+func NewMQTTPublisher(cfg config.MQTTConfig, password string, eval *evaluator.Evaluator, logger zerolog.Logger) (*MQTTPublisher, error) {
+	u, err := url.Parse(cfg.Broker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MQTT broker URL: %w", err)
+	}
+	if u.Scheme != "tcp" && u.Scheme != "mqtt" {
+		return nil, fmt.Errorf("invalid MQTT broker URL %q: scheme must be tcp:// or mqtt://", cfg.Broker)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "1883")
+	}
+	if cfg.TopicPrefix == "" {
+		cfg.TopicPrefix = "netspec"
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = "netspec"
+	}
+	return &MQTTPublisher{
+		cfg:       cfg,
+		addr:      addr,
+		password:  password,
+		evaluator: eval,
+		logger:    logger.With().Str("component", "mqtt").Logger(),
+		queue:     make(chan mqttMessage, 1000),
+	}, nil
+}
+
+// RecordTransition queues the interface's new compliance for publishing.
+// It matches evaluator.TransitionFunc.
+func (p *MQTTPublisher) RecordTransition(t evaluator.Transition) {
+	msg, ok := p.message(p.evaluator.Config(), t.Device, t.Interface)
+	if !ok {
+		return
+	}
+	select {
+	case p.queue <- msg:
+	default:
+		p.logger.Warn().Str("topic", msg.topic).Msg("MQTT queue full, dropping update")
+	}
+}
+
+// Run connects to the broker and publishes queued updates until ctx is
+// cancelled, reconnecting after errors
+func (p *MQTTPublisher) Run(ctx context.Context) {
+	for {
+		err := p.session(ctx.Done())
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		p.logger.Error().Err(err).Dur("retry_in", mqttRetryDelay).Msg("MQTT connection lost")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(mqttRetryDelay):
+		}
+	}
+}
+
+// session runs one broker connection: connect, publish a full snapshot, then
+// forward queued updates and keep-alives until an error occurs
+func (p *MQTTPublisher) session(stop <-chan struct{}) error {
+	conn, err := net.DialTimeout("tcp", p.addr, mqttDialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write(p.connectPacket()); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(mqttDialTimeout))
+	reader := bufio.NewReader(conn)
+	ack := make([]byte, 4)
+	if _, err := io.ReadFull(reader, ack); err != nil {
+		return fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if ack[0] != 0x20 || ack[3] != 0 {
+		return fmt.Errorf("broker refused connection (return code %d)", ack[3])
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	p.logger.Info().Str("broker", p.addr).Msg("Connected to MQTT broker")
+
+	// Drain whatever the broker sends (PINGRESP); a read error ends the session
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, reader)
+		readErr <- fmt.Errorf("connection closed by broker: %v", err)
+	}()
+
+	for _, msg := range p.snapshot() {
+		if _, err := conn.Write(publishPacket(msg.topic, msg.payload)); err != nil {
+			return fmt.Errorf("failed to publish: %w", err)
+		}
+	}
+
+	keepAlive := time.NewTicker(mqttKeepAlive / 2)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-stop:
+			conn.Write([]byte{0xE0, 0x00}) // DISCONNECT
+			return nil
+		case err := <-readErr:
+			return err
+		case <-keepAlive.C:
+			if _, err := conn.Write([]byte{0xC0, 0x00}); err != nil { // PINGREQ
+				return fmt.Errorf("failed to send PINGREQ: %w", err)
+			}
+		case msg := <-p.queue:
+			if _, err := conn.Write(publishPacket(msg.topic, msg.payload)); err != nil {
+				return fmt.Errorf("failed to publish: %w", err)
+			}
+		}
+	}
+}
+
+// snapshot builds a message for every declared interface with known state
+func (p *MQTTPublisher) snapshot() []mqttMessage {
+	cfg := p.evaluator.Config()
+	if cfg == nil {
+		return nil
+	}
+	var msgs []mqttMessage
+	for deviceName, dev := range cfg.DesiredState.Devices {
+		for ifaceName := range dev.Interfaces {
+			if msg, ok := p.message(cfg, deviceName, ifaceName); ok {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	return msgs
+}
+
+// message renders the retained message for one interface
+func (p *MQTTPublisher) message(cfg *config.Config, deviceName, ifaceName string) (mqttMessage, bool) {
+	if cfg == nil {
+		return mqttMessage{}, false
+	}
+	ifCfg, declared := cfg.DesiredState.Devices[deviceName].Interfaces[ifaceName]
+	state, observed := p.evaluator.GetInterfaceState(deviceName, ifaceName)
+	if !declared || !observed {
+		return mqttMessage{}, false
+	}
+	payload, err := json.Marshal(InterfaceCompliance{
+		Device:       deviceName,
+		Interface:    ifaceName,
+		DesiredOper:  ifCfg.DesiredStateAt(time.Now()),
+		ActualOper:   state.OperStatus,
+		DesiredAdmin: ifCfg.AdminState,
+		ActualAdmin:  state.AdminStatus,
+		Compliant:    state.DeviatedSince == nil,
+		UpdatedAt:    state.UpdatedAt,
+	})
+	if err != nil {
+		return mqttMessage{}, false
+	}
+	return mqttMessage{
+		topic:   p.cfg.TopicPrefix + "/" + topicLevel(deviceName) + "/" + topicLevel(ifaceName),
+		payload: payload,
+	}, true
+}
+
+// connectPacket builds an MQTT 3.1.1 CONNECT packet with a clean session
+func (p *MQTTPublisher) connectPacket() []byte {
+	flags := byte(0x02) // clean session
+	payload := mqttString(p.cfg.ClientID)
+	if p.cfg.Username != "" {
+		flags |= 0x80
+		payload = append(payload, mqttString(p.cfg.Username)...)
+		if p.password != "" {
+			flags |= 0x40
+			payload = append(payload, mqttString(p.password)...)
+		}
+	}
+	keepAlive := uint16(mqttKeepAlive / time.Second)
+	body := append(mqttString("MQTT"), 0x04, flags, byte(keepAlive>>8), byte(keepAlive))
+	body = append(body, payload...)
+	return append(append([]byte{0x10}, remainingLength(len(body))...), body...)
+}
+
+// publishPacket builds a retained QoS 0 PUBLISH packet
+func publishPacket(topic string, payload []byte) []byte {
+	body := append(mqttString(topic), payload...)
+	return append(append([]byte{0x31}, remainingLength(len(body))...), body...)
+}
+
+// mqttString encodes a length-prefixed UTF-8 string
+func mqttString(s string) []byte {
+	return append([]byte{byte(len(s) >> 8), byte(len(s))}, s...)
+}
+
+// remainingLength encodes the MQTT variable-length remaining length
+func remainingLength(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+// topicLevel makes a name safe for use as a single MQTT topic level
+func topicLevel(s string) string {
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(s)
+}
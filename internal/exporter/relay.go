@@ -0,0 +1,141 @@
+package exporter
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	// defaultRelayMethod is the gNMI dial-out Publish RPC understood by
+	// common collectors: a client stream of gnmi.SubscribeResponse
+	defaultRelayMethod = "/gnmi_dialout.gNMIDialOut/Publish"
+	relayRetryDelay    = 10 * time.Second
+	relayDialTimeout   = 10 * time.Second
+)
+
+// relayStreamDesc describes the bidirectional dial-out Publish stream
+var relayStreamDesc = &grpc.StreamDesc{
+	StreamName:    "Publish",
+	ClientStreams: true,
+	ServerStreams: true,
+}
+
+// Relay forwards raw gNMI notifications to an upstream collector so NetSpec
+// can be the single subscriber on devices that limit concurrent sessions.
+// Notifications are tagged with the device name as the prefix target.
+type Relay struct {
+	cfg    config.RelayConfig
+	logger zerolog.Logger
+	queue  chan *gnmi.SubscribeResponse
+}
+
+// NewRelay creates a relay to the configured upstream
+func NewRelay(cfg config.RelayConfig, logger zerolog.Logger) *Relay {
+	if cfg.Method == "" {
+		cfg.Method = defaultRelayMethod
+	}
+	return &Relay{
+		cfg:    cfg,
+		logger: logger.With().Str("component", "relay").Str("upstream", cfg.Address).Logger(),
+		queue:  make(chan *gnmi.SubscribeResponse, 5000),
+	}
+}
+
+// Forward queues a notification received from a device. It never blocks;
+// notifications are dropped while the queue is full.
+func (r *Relay) Forward(deviceName string, notification *gnmi.Notification) {
+	n := proto.Clone(notification).(*gnmi.Notification)
+	if n.Prefix == nil {
+		n.Prefix = &gnmi.Path{}
+	}
+	if n.Prefix.Target == "" {
+		n.Prefix.Target = deviceName
+	}
+	resp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{Update: n},
+	}
+	select {
+	case r.queue <- resp:
+	default:
+		r.logger.Warn().Str("device", deviceName).Msg("Relay queue full, dropping notification")
+	}
+}
+
+// Run keeps a stream to the upstream open and forwards queued notifications
+// until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		err := r.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		r.logger.Error().Err(err).Dur("retry_in", relayRetryDelay).Msg("Relay stream failed")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(relayRetryDelay):
+		}
+	}
+}
+
+// stream runs one upstream connection until an error occurs
+func (r *Relay) stream(ctx context.Context) error {
+	creds := insecure.NewCredentials()
+	if r.cfg.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	dialCtx, dialCancel := context.WithTimeout(ctx, relayDialTimeout)
+	defer dialCancel()
+	conn, err := grpc.DialContext(dialCtx, r.cfg.Address, grpc.WithTransportCredentials(creds), grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("failed to dial: %w", err)
+	}
+	defer conn.Close()
+
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	defer streamCancel()
+	stream, err := conn.NewStream(streamCtx, relayStreamDesc, r.cfg.Method)
+	if err != nil {
+		return fmt.Errorf("failed to open stream: %w", err)
+	}
+
+	r.logger.Info().Str("method", r.cfg.Method).Msg("Relay stream established")
+
+	// The upstream's responses carry nothing we need; reading them surfaces
+	// stream errors
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+				recvErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			stream.CloseSend()
+			return ctx.Err()
+		case err := <-recvErr:
+			return fmt.Errorf("upstream closed stream: %w", err)
+		case resp := <-r.queue:
+			if err := stream.SendMsg(resp); err != nil {
+				return fmt.Errorf("failed to send: %w", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Package hotspot keeps a day of per-interface traffic, error and flap
+// activity in five-minute buckets so the busiest, most errored and most
+// flapping interfaces over a window can be listed at a glance.
+package hotspot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/evaluator"
+)
+
+// Metrics interfaces can be ranked by
+const (
+	MetricUtilization = "utilization"
+	MetricErrors      = "errors"
+	MetricFlaps       = "flaps"
+)
+
+const (
+	bucketSize = 5 * time.Minute
+	// Retention is the longest window activity can be ranked over
+	Retention = 24 * time.Hour
+	// minPackets is how many packets an interface must receive within the
+	// window before its error rate is ranked, so a handful of errors on an
+	// idle port does not top the list
+	minPackets = 10000
+)
+
+// bucket is five minutes of one interface's activity
+type bucket struct {
+	start    time.Time
+	rxSum    float64 // sum of receive bit rate samples
+	txSum    float64
+	rxN, txN int
+	packets  uint64
+	errors   uint64
+	flaps    int
+}
+
+// series is one interface's buckets, oldest first
+type series struct {
+	device, iface string
+	buckets       []bucket
+}
+
+// current returns the bucket for at, appending one if needed, and drops
+// buckets past the retention
+func (s *series) current(at time.Time) *bucket {
+	start := at.Truncate(bucketSize)
+	n := len(s.buckets)
+	if n > 0 && !start.After(s.buckets[n-1].start) {
+		// Late samples are folded into the newest bucket
+		return &s.buckets[n-1]
+	}
+	s.buckets = append(s.buckets, bucket{start: start})
+	s.expire(at)
+	return &s.buckets[len(s.buckets)-1]
+}
+
+// expire drops buckets older than the retention
+func (s *series) expire(now time.Time) {
+	cutoff := now.Add(-Retention)
+	drop := 0
+	for drop < len(s.buckets) && s.buckets[drop].start.Before(cutoff) {
+		drop++
+	}
+	s.buckets = s.buckets[drop:]
+}
+
+// Entry is one interface's activity over a window
+type Entry struct {
+	Device      string  `json:"device"`
+	Interface   string  `json:"interface"`
+	Metric      string  `json:"metric"`
+	Value       float64 `json:"value"`               // the ranked value
+	ReceiveBPS  float64 `json:"receive_bps"`         // average receive bit rate
+	TransmitBPS float64 `json:"transmit_bps"`        // average transmit bit rate
+	Packets     uint64  `json:"packets"`             // packets received
+	Errors      uint64  `json:"errors"`              // input errors
+	ErrorPPM    float64 `json:"error_ppm"`           // input errors per million packets
+	Flaps       int     `json:"flaps"`               // times oper status left up
+	Utilization float64 `json:"utilization_percent"` // busier direction, when the speed is declared
+}
+
+// Display formats the ranked value for the dashboard
+func (e Entry) Display() string {
+	switch e.Metric {
+	case MetricUtilization:
+		if e.Utilization > 0 {
+			return fmt.Sprintf("%.1f%% (%s)", e.Utilization, formatBPS(e.Value))
+		}
+		return formatBPS(e.Value)
+	case MetricErrors:
+		return fmt.Sprintf("%.1f ppm (%d errors)", e.ErrorPPM, e.Errors)
+	case MetricFlaps:
+		if e.Flaps == 1 {
+			return "1 flap"
+		}
+		return fmt.Sprintf("%d flaps", e.Flaps)
+	}
+	return fmt.Sprintf("%g", e.Value)
+}
+
+// Tracker records interface activity from evaluator hooks
+type Tracker struct {
+	mu     sync.Mutex
+	series map[string]*series // device|interface -> activity
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{series: make(map[string]*series)}
+}
+
+// Register adds the tracker's hooks to an evaluator
+func (t *Tracker) Register(eval *evaluator.Evaluator) {
+	eval.AddRateHook(t.RecordRate)
+	eval.AddErrorHook(t.RecordErrors)
+	eval.AddTransitionHook(t.RecordTransition)
+}
+
+// lookup returns an interface's series, creating it on first use. The
+// caller must hold t.mu.
+func (t *Tracker) lookup(device, iface string) *series {
+	key := device + "|" + iface
+	s, ok := t.series[key]
+	if !ok {
+		s = &series{device: device, iface: iface}
+		t.series[key] = s
+	}
+	return s
+}
+
+// RecordRate adds a measured bit rate
+func (t *Tracker) RecordRate(sample evaluator.RateSample) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.lookup(sample.Device, sample.Interface).current(sample.At)
+	if sample.Direction == "transmit" {
+		b.txSum += sample.BPS
+		b.txN++
+	} else {
+		b.rxSum += sample.BPS
+		b.rxN++
+	}
+}
+
+// RecordErrors adds received packets and input errors
+func (t *Tracker) RecordErrors(sample evaluator.ErrorSample) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.lookup(sample.Device, sample.Interface).current(sample.At)
+	b.packets += sample.Packets
+	b.errors += sample.Errors
+}
+
+// RecordTransition counts an oper status leaving up as a flap
+func (t *Tracker) RecordTransition(tr evaluator.Transition) {
+	if tr.Field != "oper-status" || !strings.EqualFold(tr.Previous, "up") || strings.EqualFold(tr.Current, "up") {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lookup(tr.Device, tr.Interface).current(tr.At).flaps++
+}
+
+// Top returns up to n interfaces with the most activity of a metric within
+// the window ending now, highest first. Interfaces with no activity of the
+// metric are not listed.
+func (t *Tracker) Top(metric string, window time.Duration, n int, now time.Time) []Entry {
+	from := now.Add(-window).Truncate(bucketSize)
+
+	t.mu.Lock()
+	entries := make([]Entry, 0)
+	for key, s := range t.series {
+		s.expire(now)
+		if len(s.buckets) == 0 {
+			delete(t.series, key)
+			continue
+		}
+		e := Entry{Device: s.device, Interface: s.iface, Metric: metric}
+		var rxSum, txSum float64
+		var rxN, txN int
+		for _, b := range s.buckets {
+			if b.start.Before(from) {
+				continue
+			}
+			rxSum += b.rxSum
+			txSum += b.txSum
+			rxN += b.rxN
+			txN += b.txN
+			e.Packets += b.packets
+			e.Errors += b.errors
+			e.Flaps += b.flaps
+		}
+		if rxN > 0 {
+			e.ReceiveBPS = rxSum / float64(rxN)
+		}
+		if txN > 0 {
+			e.TransmitBPS = txSum / float64(txN)
+		}
+		if e.Packets > 0 {
+			e.ErrorPPM = float64(e.Errors) / float64(e.Packets) * 1e6
+		}
+
+		switch metric {
+		case MetricUtilization:
+			if rxN == 0 && txN == 0 {
+				continue
+			}
+			e.Value = math.Max(e.ReceiveBPS, e.TransmitBPS)
+		case MetricErrors:
+			if e.Errors == 0 || e.Packets < minPackets {
+				continue
+			}
+			e.Value = e.ErrorPPM
+		case MetricFlaps:
+			if e.Flaps == 0 {
+				continue
+			}
+			e.Value = float64(e.Flaps)
+		default:
+			continue
+		}
+		entries = append(entries, e)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		if entries[i].Device != entries[j].Device {
+			return entries[i].Device < entries[j].Device
+		}
+		return entries[i].Interface < entries[j].Interface
+	})
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// ValidMetric reports whether interfaces can be ranked by metric
+func ValidMetric(metric string) bool {
+	switch metric {
+	case MetricUtilization, MetricErrors, MetricFlaps:
+		return true
+	}
+	return false
+}
+
+// formatBPS renders a bit rate with an SI unit
+func formatBPS(bps float64) string {
+	for _, unit := range []struct {
+		scale float64
+		name  string
+	}{{1e9, "Gbps"}, {1e6, "Mbps"}, {1e3, "kbps"}} {
+		if bps >= unit.scale {
+			return fmt.Sprintf("%.1f %s", bps/unit.scale, unit.name)
+		}
+	}
+	return fmt.Sprintf("%.0f bps", bps)
+}
@@ -0,0 +1,160 @@
+// Package inventory holds the hardware inventory last read from each
+// device's /components tree: the chassis, its modules and which optic is
+// plugged into which port.
+package inventory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Component is a chassis or module with its asset details
+type Component struct {
+	Name            string `json:"name"`
+	Type            string `json:"type,omitempty"` // e.g. CHASSIS, LINECARD, POWER_SUPPLY
+	Description     string `json:"description,omitempty"`
+	Parent          string `json:"parent,omitempty"`
+	Manufacturer    string `json:"manufacturer,omitempty"`
+	Model           string `json:"model,omitempty"` // part number
+	Serial          string `json:"serial_number,omitempty"`
+	HardwareVersion string `json:"hardware_version,omitempty"`
+}
+
+// Transceiver is a pluggable optic and the interface it serves
+type Transceiver struct {
+	Component   string `json:"component"`
+	Interface   string `json:"interface,omitempty"` // empty when no interface references the port
+	Vendor      string `json:"vendor,omitempty"`
+	PartNumber  string `json:"part_number,omitempty"`
+	Serial      string `json:"serial_number,omitempty"`
+	FormFactor  string `json:"form_factor,omitempty"`  // e.g. QSFP28
+	EthernetPMD string `json:"ethernet_pmd,omitempty"` // e.g. ETH_100GBASE_LR4
+	Wavelength  string `json:"wavelength,omitempty"`   // nm, when the device reports it
+}
+
+// Snapshot is one device's inventory as of its last read
+type Snapshot struct {
+	CollectedAt     time.Time     `json:"collected_at"`
+	Chassis         []Component   `json:"chassis"` // more than one for stacks
+	Modules         []Component   `json:"modules"` // sorted by name
+	SoftwareVersion string        `json:"software_version,omitempty"`
+	Transceivers    []Transceiver `json:"transceivers"` // sorted by interface, then component
+}
+
+// Asset returns the asset details support contracts ask for: the chassis
+// model and serial, software version and, for an interface with an optic,
+// the optic's part number and serial. Stacked chassis are comma-separated.
+func (s Snapshot) Asset(iface string) map[string]string {
+	asset := make(map[string]string)
+	var models, serials []string
+	for _, c := range s.Chassis {
+		if c.Model != "" {
+			models = append(models, c.Model)
+		}
+		if c.Serial != "" {
+			serials = append(serials, c.Serial)
+		}
+	}
+	if len(models) > 0 {
+		asset["chassis_model"] = strings.Join(models, ", ")
+	}
+	if len(serials) > 0 {
+		asset["chassis_serial"] = strings.Join(serials, ", ")
+	}
+	if s.SoftwareVersion != "" {
+		asset["software_version"] = s.SoftwareVersion
+	}
+	if t, ok := s.Transceiver(iface); ok && iface != "" {
+		if t.PartNumber != "" {
+			asset["optic_part_number"] = t.PartNumber
+		}
+		if t.Serial != "" {
+			asset["optic_serial"] = t.Serial
+		}
+	}
+	return asset
+}
+
+// Transceiver returns the optic serving an interface, if any
+func (s Snapshot) Transceiver(iface string) (Transceiver, bool) {
+	for _, t := range s.Transceivers {
+		if t.Interface == iface {
+			return t, true
+		}
+	}
+	return Transceiver{}, false
+}
+
+// Store holds the latest snapshot of every device
+type Store struct {
+	mu      sync.RWMutex
+	devices map[string]Snapshot
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{devices: make(map[string]Snapshot)}
+}
+
+// Set replaces a device's snapshot
+func (s *Store) Set(device string, snap Snapshot) {
+	for _, list := range [][]Component{snap.Chassis, snap.Modules} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	}
+	sort.Slice(snap.Transceivers, func(i, j int) bool {
+		a, b := snap.Transceivers[i], snap.Transceivers[j]
+		if a.Interface != b.Interface {
+			return a.Interface < b.Interface
+		}
+		return a.Component < b.Component
+	})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices[device] = snap
+}
+
+// Device returns a device's snapshot, if it has been read
+func (s *Store) Device(device string) (Snapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap, ok := s.devices[device]
+	return snap, ok
+}
+
+// Asset returns the asset details of a device and interface for alert
+// payloads, or nil before the device's inventory has been read
+func (s *Store) Asset(device, iface string) map[string]string {
+	snap, ok := s.Device(device)
+	if !ok {
+		return nil
+	}
+	asset := snap.Asset(iface)
+	if len(asset) == 0 {
+		return nil
+	}
+	return asset
+}
+
+// All returns every device's snapshot
+func (s *Store) All() map[string]Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := make(map[string]Snapshot, len(s.devices))
+	for device, snap := range s.devices {
+		all[device] = snap
+	}
+	return all
+}
+
+// Retain drops the snapshots of devices no longer configured
+func (s *Store) Retain(keep func(device string) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for device := range s.devices {
+		if !keep(device) {
+			delete(s.devices, device)
+		}
+	}
+}
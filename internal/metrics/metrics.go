@@ -0,0 +1,327 @@
+// Package metrics is a minimal registry of self-monitoring counters and
+// gauges rendered in the Prometheus text exposition format at /metrics.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// metric is anything that can render itself into the exposition format
+type metric interface {
+	name() string
+	write(w io.Writer)
+}
+
+// Registry holds metrics in registration order
+type Registry struct {
+	mu      sync.RWMutex
+	metrics []metric
+	names   map[string]bool
+}
+
+// Default is the registry served at /metrics
+var Default = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// register adds a metric, panicking on duplicate names since metrics are
+// declared once at package init
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[m.name()] {
+		panic("metrics: duplicate metric " + m.name())
+	}
+	r.names[m.name()] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteText renders every metric in the Prometheus text format
+func (r *Registry) WriteText(w io.Writer) {
+	r.mu.RLock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.RUnlock()
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	n    string
+	help string
+	v    atomic.Uint64
+}
+
+// NewCounter registers a counter in the default registry
+func NewCounter(name, help string) *Counter {
+	c := &Counter{n: name, help: help}
+	Default.register(c)
+	return c
+}
+
+// Inc adds one
+func (c *Counter) Inc() { c.v.Add(1) }
+
+// Add adds n
+func (c *Counter) Add(n uint64) { c.v.Add(n) }
+
+// Value returns the current count
+func (c *Counter) Value() uint64 { return c.v.Load() }
+
+func (c *Counter) name() string { return c.n }
+
+func (c *Counter) write(w io.Writer) {
+	writeHeader(w, c.n, c.help, "counter")
+	fmt.Fprintf(w, "%s %d\n", c.n, c.v.Load())
+}
+
+// Gauge is a value that can go up and down
+type Gauge struct {
+	n    string
+	help string
+	bits atomic.Uint64
+}
+
+// NewGauge registers a gauge in the default registry
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{n: name, help: help}
+	Default.register(g)
+	return g
+}
+
+// Set replaces the value
+func (g *Gauge) Set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+// Value returns the current value
+func (g *Gauge) Value() float64 { return math.Float64frombits(g.bits.Load()) }
+
+func (g *Gauge) name() string { return g.n }
+
+func (g *Gauge) write(w io.Writer) {
+	writeHeader(w, g.n, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.n, formatFloat(g.Value()))
+}
+
+// GaugeFunc is a gauge whose value is computed at scrape time
+type GaugeFunc struct {
+	n    string
+	help string
+	fn   func() float64
+}
+
+// NewGaugeFunc registers a computed gauge in the default registry
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{n: name, help: help, fn: fn}
+	Default.register(g)
+	return g
+}
+
+func (g *GaugeFunc) name() string { return g.n }
+
+func (g *GaugeFunc) write(w io.Writer) {
+	writeHeader(w, g.n, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.n, formatFloat(g.fn()))
+}
+
+// CounterVec is a set of counters partitioned by label values
+type CounterVec struct {
+	n      string
+	help   string
+	labels []string
+	mu     sync.RWMutex
+	series map[string]*Counter
+}
+
+// NewCounterVec registers a labelled counter in the default registry
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{n: name, help: help, labels: labels, series: make(map[string]*Counter)}
+	Default.register(v)
+	return v
+}
+
+// With returns the counter for the given label values, in label order
+func (v *CounterVec) With(values ...string) *Counter {
+	key := labelString(v.labels, values)
+	v.mu.RLock()
+	c, ok := v.series[key]
+	v.mu.RUnlock()
+	if ok {
+		return c
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c, ok = v.series[key]; !ok {
+		c = &Counter{n: v.n}
+		v.series[key] = c
+	}
+	return c
+}
+
+func (v *CounterVec) name() string { return v.n }
+
+func (v *CounterVec) write(w io.Writer) {
+	writeHeader(w, v.n, v.help, "counter")
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s{%s} %d\n", v.n, key, v.series[key].Value())
+	}
+}
+
+// GaugeVec is a set of gauges partitioned by label values
+type GaugeVec struct {
+	n      string
+	help   string
+	labels []string
+	mu     sync.RWMutex
+	series map[string]*Gauge
+}
+
+// NewGaugeVec registers a labelled gauge in the default registry
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	v := &GaugeVec{n: name, help: help, labels: labels, series: make(map[string]*Gauge)}
+	Default.register(v)
+	return v
+}
+
+// With returns the gauge for the given label values, in label order
+func (v *GaugeVec) With(values ...string) *Gauge {
+	key := labelString(v.labels, values)
+	v.mu.RLock()
+	g, ok := v.series[key]
+	v.mu.RUnlock()
+	if ok {
+		return g
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if g, ok = v.series[key]; !ok {
+		g = &Gauge{n: v.n}
+		v.series[key] = g
+	}
+	return g
+}
+
+// Delete removes the series for the given label values
+func (v *GaugeVec) Delete(values ...string) {
+	key := labelString(v.labels, values)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.series, key)
+}
+
+func (v *GaugeVec) name() string { return v.n }
+
+func (v *GaugeVec) write(w io.Writer) {
+	writeHeader(w, v.n, v.help, "gauge")
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s{%s} %s\n", v.n, key, formatFloat(v.series[key].Value()))
+	}
+}
+
+// Histogram counts observations into cumulative buckets
+type Histogram struct {
+	n       string
+	help    string
+	bounds  []float64       // bucket upper bounds, ascending
+	buckets []atomic.Uint64 // per-bucket (non-cumulative) counts, plus +Inf
+	count   atomic.Uint64
+	sumBits atomic.Uint64
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds in
+// the default registry
+func NewHistogram(name, help string, bounds []float64) *Histogram {
+	bounds = append([]float64(nil), bounds...)
+	sort.Float64s(bounds)
+	h := &Histogram{n: name, help: help, bounds: bounds, buckets: make([]atomic.Uint64, len(bounds)+1)}
+	Default.register(h)
+	return h
+}
+
+// Observe records one value
+func (h *Histogram) Observe(v float64) {
+	h.buckets[sort.SearchFloat64s(h.bounds, v)].Add(1)
+	h.count.Add(1)
+	for {
+		old := h.sumBits.Load()
+		if h.sumBits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 { return h.count.Load() }
+
+func (h *Histogram) name() string { return h.n }
+
+func (h *Histogram) write(w io.Writer) {
+	writeHeader(w, h.n, h.help, "histogram")
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.buckets[i].Load()
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.n, formatFloat(bound), cumulative)
+	}
+	cumulative += h.buckets[len(h.bounds)].Load()
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.n, cumulative)
+	fmt.Fprintf(w, "%s_sum %s\n", h.n, formatFloat(math.Float64frombits(h.sumBits.Load())))
+	fmt.Fprintf(w, "%s_count %d\n", h.n, cumulative)
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric family
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// labelString renders label pairs as name="value",... in label order
+func labelString(labels, values []string) string {
+	var b strings.Builder
+	for i, label := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		b.WriteString(label)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(value))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+// escapeLabel escapes a label value for the exposition format
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// formatFloat renders a sample value
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
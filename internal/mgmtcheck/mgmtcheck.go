@@ -0,0 +1,233 @@
+// Package mgmtcheck runs auxiliary management-plane checks against devices:
+// DNS resolution of the configured address, SSH port reachability and HTTPS
+// management interface reachability. Results complement gNMI health on the
+// device page.
+package mgmtcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// Check names
+const (
+	CheckDNS   = "dns"
+	CheckSSH   = "ssh"
+	CheckHTTPS = "https"
+)
+
+const (
+	defaultInterval = time.Minute
+	defaultSSHPort  = 22
+	checkTimeout    = 5 * time.Second
+	tickInterval    = time.Second
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Name      string        `json:"name"`
+	OK        bool          `json:"ok"`
+	Detail    string        `json:"detail"`
+	Latency   time.Duration `json:"latency_ns"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Checker runs each device's configured checks on its interval
+type Checker struct {
+	logger  zerolog.Logger
+	client  *http.Client
+	mu      sync.RWMutex
+	devices map[string]config.DeviceConfig
+	results map[string][]Result
+	nextRun map[string]time.Time
+}
+
+// NewChecker creates a checker with no devices
+func NewChecker(logger zerolog.Logger) *Checker {
+	return &Checker{
+		logger: logger,
+		client: &http.Client{
+			Timeout: checkTimeout,
+			// Management interfaces commonly serve self-signed certificates;
+			// the check is for reachability, not trust
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		devices: make(map[string]config.DeviceConfig),
+		results: make(map[string][]Result),
+		nextRun: make(map[string]time.Time),
+	}
+}
+
+// SetDevices replaces the set of devices to check. Devices without
+// mgmt_checks, or removed from the config, lose their results.
+func (c *Checker) SetDevices(devices map[string]config.DeviceConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.devices = make(map[string]config.DeviceConfig)
+	for name, dev := range devices {
+		if dev.MgmtChecks != nil {
+			c.devices[name] = dev
+		}
+	}
+	for name := range c.results {
+		if _, ok := c.devices[name]; !ok {
+			delete(c.results, name)
+			delete(c.nextRun, name)
+		}
+	}
+}
+
+// Results returns the latest results for a device in check order
+func (c *Checker) Results(device string) []Result {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Result(nil), c.results[device]...)
+}
+
+// Run checks devices as they fall due until ctx is cancelled
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		c.runDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue starts the checks of every device whose interval has elapsed. Each
+// device runs in its own goroutine so a slow device doesn't delay the rest.
+func (c *Checker) runDue(ctx context.Context, now time.Time) {
+	c.mu.Lock()
+	var due []string
+	for name, dev := range c.devices {
+		if next, ok := c.nextRun[name]; ok && now.Before(next) {
+			continue
+		}
+		interval := dev.MgmtChecks.Interval
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+		c.nextRun[name] = now.Add(interval)
+		due = append(due, name)
+	}
+	devices := c.devices
+	c.mu.Unlock()
+
+	sort.Strings(due)
+	for _, name := range due {
+		go func(name string, dev config.DeviceConfig) {
+			results := c.checkDevice(ctx, dev)
+			c.mu.Lock()
+			if _, ok := c.devices[name]; ok {
+				c.results[name] = results
+			}
+			c.mu.Unlock()
+			for _, r := range results {
+				if !r.OK {
+					c.logger.Debug().
+						Str("device", name).
+						Str("check", r.Name).
+						Str("detail", r.Detail).
+						Msg("Management check failed")
+				}
+			}
+		}(name, devices[name])
+	}
+}
+
+// checkDevice runs a device's enabled checks in order
+func (c *Checker) checkDevice(ctx context.Context, dev config.DeviceConfig) []Result {
+	checks := dev.MgmtChecks
+	var results []Result
+	// DNS only means something for hostnames
+	if checks.DNS && net.ParseIP(dev.Address) == nil {
+		results = append(results, c.timed(CheckDNS, func() (string, error) {
+			return checkDNS(ctx, dev.Address)
+		}))
+	}
+	if checks.SSH {
+		port := checks.SSHPort
+		if port == 0 {
+			port = defaultSSHPort
+		}
+		results = append(results, c.timed(CheckSSH, func() (string, error) {
+			return checkTCP(ctx, dev.Address, port)
+		}))
+	}
+	if checks.HTTPS {
+		url := checks.HTTPSURL
+		if url == "" {
+			url = "https://" + net.JoinHostPort(dev.Address, "443") + "/"
+		}
+		results = append(results, c.timed(CheckHTTPS, func() (string, error) {
+			return c.checkHTTPS(ctx, url)
+		}))
+	}
+	return results
+}
+
+func (c *Checker) timed(name string, fn func() (string, error)) Result {
+	start := time.Now()
+	detail, err := fn()
+	r := Result{Name: name, OK: err == nil, Detail: detail, Latency: time.Since(start), CheckedAt: start}
+	if err != nil {
+		r.Detail = err.Error()
+	}
+	return r
+}
+
+// checkDNS resolves a hostname and reports its addresses
+func checkDNS(ctx context.Context, host string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("resolves to %v", addrs), nil
+}
+
+// checkTCP connects to a port and closes the connection
+func checkTCP(ctx context.Context, host string, port int) (string, error) {
+	dialer := net.Dialer{Timeout: checkTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, fmt.Sprint(port)))
+	if err != nil {
+		return "", err
+	}
+	conn.Close()
+	return fmt.Sprintf("port %d open", port), nil
+}
+
+// checkHTTPS requests the management URL; any HTTP response counts as
+// reachable, but server errors fail the check
+func (c *Checker) checkHTTPS(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return fmt.Sprintf("HTTP %d", resp.StatusCode), nil
+}
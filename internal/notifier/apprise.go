@@ -5,57 +5,169 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/metrics"
 	"github.com/netspec/netspec/internal/types"
 	"github.com/rs/zerolog"
 )
 
+// notificationsFiltered counts the notifications channels did not receive
+// because of their severity_filter
+var notificationsFiltered = metrics.NewCounterVec(
+	"netspec_notifications_filtered_total",
+	"Notifications not sent to a channel because its severity_filter excludes their severity, by channel and severity",
+	"channel", "severity")
+
 // Notifier handles sending alerts via Apprise
 type Notifier struct {
-	logger zerolog.Logger
-	client *http.Client
+	logger     zerolog.Logger
+	client     *http.Client
+	alerts     config.AlertsConfig
+	snapshot   SnapshotFunc
+	ticketHook TicketHook
+	ticketJobs chan ticketJob
+	// openTickets are the tickets opened per dedup key and channel; only
+	// the ticket worker uses it
+	openTickets map[string]types.Ticket
+	// held are the notifications held back per channel during quiet hours
+	held       map[string]*quietDigest
+	heldMu     sync.Mutex
+	// webhookRetries are the notifications waiting per webhook channel
+	// while one of them is retried, oldest first
+	webhookRetries map[string][]*webhookJob
+	webhookMu      sync.Mutex
+	mu         sync.RWMutex
 }
 
 // NewNotifier creates a new Apprise notifier
 func NewNotifier(logger zerolog.Logger) *Notifier {
-	return &Notifier{
+	n := &Notifier{
 		logger: logger,
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		ticketJobs:  make(chan ticketJob, ticketQueueSize),
+		openTickets: make(map[string]types.Ticket),
+		held:        make(map[string]*quietDigest),
+		webhookRetries: make(map[string][]*webhookJob),
 	}
+	go n.runTickets()
+	return n
+}
+
+// SetConfig sets the alerts configuration used for channel lookup, severity
+// filtering and emoji selection
+func (n *Notifier) SetConfig(alerts config.AlertsConfig) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = alerts
 }
 
-// SendAlert sends an alert to the specified channels
+// SendAlert sends an alert to the specified channels. Channels are resolved
+// through their alerts.yaml configuration: the URL comes from url_env, and a
+// channel whose severity_filter excludes the alert's severity is skipped. A
+// channel in quiet hours holds the alert for its digest unless it is of the
+// most severe level.
 func (n *Notifier) SendAlert(alert *types.Alert, channelNames []string) error {
-	// Get channel configs
+	n.mu.RLock()
+	alerts := n.alerts
+	n.mu.RUnlock()
+
+	now := time.Now()
 	channels := make([]Channel, 0, len(channelNames))
 	for _, name := range channelNames {
-		// For MVP, we'll use Apprise API directly
-		// In production, this would look up channel config
-		url := os.Getenv(fmt.Sprintf("APPRISE_%s_URL", name))
-		if url == "" {
+		chCfg, ok := alerts.Channels[name]
+		if !ok {
 			n.logger.Warn().
 				Str("channel", name).
-				Msg("Channel URL not found, skipping")
+				Msg("Channel not defined in alerts.yaml, skipping")
 			continue
 		}
-
-		channels = append(channels, Channel{
-			Name: name,
-			URL:  url,
-		})
+		if !acceptsSeverity(&alerts, chCfg, alert.Severity) {
+			filtered := notificationsFiltered.With(name, alerts.NormalizeSeverity(alert.Severity))
+			filtered.Inc()
+			n.logger.Debug().
+				Str("channel", name).
+				Str("severity", alert.Severity).
+				Str("alert_id", alert.ID).
+				Uint64("filtered_total", filtered.Value()).
+				Msg("Severity excluded by channel filter, skipping")
+			continue
+		}
+		channel, ok := channelFromConfig(name, chCfg)
+		if !ok {
+			n.logger.Warn().
+				Str("channel", name).
+				Str("url_env", chCfg.URLEnv).
+				Msg("Channel URL not set, skipping")
+			continue
+		}
+		if quiet := chCfg.QuietHours; quiet != nil {
+			if !quiet.Active(now) {
+				// Quiet hours are over: the digest goes out before anything newer
+				n.flushQuiet(&alerts, name)
+			} else if alerts.SeverityRank(alert.Severity) > 0 {
+				n.holdQuiet(name, alert, now)
+				continue
+			}
+		}
+		channel.Tag = appriseTag(&alerts, chCfg, alert.Severity)
+		channels = append(channels, channel)
 	}
 
+	n.deliver(channels, alert)
+	return nil
+}
+
+// deliver sends an alert to resolved channels, logging the outcome per
+// channel
+func (n *Notifier) deliver(channels []Channel, alert *types.Alert) {
 	// Format message
 	message := n.formatMessage(alert)
 
 	// Send to each channel
 	for _, channel := range channels {
-		if err := n.sendToApprise(channel.URL, message, alert.Severity); err != nil {
+		var err error
+		switch channel.Type {
+		case "webhook":
+			var retrying bool
+			if retrying, err = n.deliverWebhook(channel, alert); retrying {
+				// Logged by the retry worker once done
+				continue
+			}
+		case "discord":
+			err = n.sendDiscord(channel, alert)
+		case "slack":
+			err = n.sendSlack(channel, alert)
+		case "msteams":
+			err = n.sendTeams(channel, alert)
+		case "ntfy":
+			err = n.sendNtfy(channel, alert)
+		case "gotify":
+			err = n.sendGotify(channel, alert)
+		case "sms":
+			err = n.sendSMS(channel, alert)
+		case "syslog":
+			err = n.sendSyslog(channel, alert)
+		case "smtp":
+			err = n.sendSMTP(channel, alert)
+		case "jira", "servicenow":
+			// Logged by the ticket worker once done
+			n.queueTicket(channel, alert)
+			continue
+		default:
+			err = n.sendToApprise(channel, message, alert)
+		}
+		if err != nil {
 			n.logger.Error().
 				Err(err).
 				Str("channel", channel.Name).
@@ -68,27 +180,97 @@ func (n *Notifier) SendAlert(alert *types.Alert, channelNames []string) error {
 				Msg("Notification sent")
 		}
 	}
-
-	return nil
 }
 
 // Channel represents a notification channel
 type Channel struct {
-	Name string
-	URL  string
+	Name           string
+	Type           string
+	URL            string
+	Secret         string         // webhook signing secret or ntfy/Gotify token, optional
+	Tag            string         // Apprise API tag expression, optional
+	AttachSnapshot bool           // attach a PNG of the device's interface states (Apprise)
+	Priorities     map[string]int // push priority per severity (ntfy, Gotify)
+	SMS            *config.SMSConfig
+	SMTP           *config.SMTPConfig
+	Ticket         *config.TicketConfig
+	Webhook        *config.WebhookConfig
+	Headers        map[string]string // webhook headers, with header_env resolved
+}
+
+// channelFromConfig resolves a configured channel's URL and secret from the
+// environment. It reports false if the URL variable is unset.
+func channelFromConfig(name string, cfg config.ChannelConfig) (Channel, bool) {
+	url := os.Getenv(cfg.URLEnv)
+	if url == "" {
+		return Channel{}, false
+	}
+	channel := Channel{
+		Name:           name,
+		Type:           cfg.Type,
+		URL:            url,
+		AttachSnapshot: cfg.AttachSnapshot,
+		Priorities:     cfg.Priorities,
+		SMS:            cfg.SMS,
+		SMTP:           cfg.SMTP,
+		Ticket:         cfg.Ticket,
+		Webhook:        cfg.Webhook,
+	}
+	if cfg.SecretEnv != "" {
+		channel.Secret = os.Getenv(cfg.SecretEnv)
+	}
+	if w := cfg.Webhook; w != nil && len(w.Headers)+len(w.HeaderEnv) > 0 {
+		channel.Headers = make(map[string]string, len(w.Headers)+len(w.HeaderEnv))
+		for name, value := range w.Headers {
+			channel.Headers[name] = value
+		}
+		for name, env := range w.HeaderEnv {
+			channel.Headers[name] = os.Getenv(env)
+		}
+	}
+	return channel, true
+}
+
+// acceptsSeverity reports whether a channel's severity_filter admits a
+// severity. Names and aliases are compared by their configured level. An
+// empty filter admits everything, except on sms channels, which then only
+// carry the most severe level.
+func acceptsSeverity(alerts *config.AlertsConfig, cfg config.ChannelConfig, severity string) bool {
+	if len(cfg.SeverityFilter) == 0 {
+		if cfg.Type == "sms" {
+			return alerts.SeverityRank(severity) == 0
+		}
+		return true
+	}
+	level := alerts.NormalizeSeverity(severity)
+	for _, allowed := range cfg.SeverityFilter {
+		if alerts.NormalizeSeverity(allowed) == level {
+			return true
+		}
+	}
+	return false
+}
+
+// appriseTag returns the channel's tag expression for a severity, falling
+// back to its "default" entry
+func appriseTag(alerts *config.AlertsConfig, cfg config.ChannelConfig, severity string) string {
+	if len(cfg.Tags) == 0 {
+		return ""
+	}
+	level := alerts.NormalizeSeverity(severity)
+	for sev, tag := range cfg.Tags {
+		if sev != "default" && alerts.NormalizeSeverity(sev) == level {
+			return tag
+		}
+	}
+	return cfg.Tags["default"]
 }
 
 // formatMessage formats an alert into a notification message
 func (n *Notifier) formatMessage(alert *types.Alert) string {
-	var emoji string
-	switch alert.Severity {
-	case "critical":
-		emoji = "🔴"
-	case "warning":
-		emoji = "⚠️"
-	default:
-		emoji = "ℹ️"
-	}
+	n.mu.RLock()
+	emoji := n.alerts.SeverityEmoji(alert.Severity)
+	n.mu.RUnlock()
 
 	if alert.State == "resolved" {
 		emoji = "🟢"
@@ -101,69 +283,156 @@ func (n *Notifier) formatMessage(alert *types.Alert) string {
 	if alert.ResolvedAt != nil {
 		body += fmt.Sprintf("\nResolved at: %s", alert.ResolvedAt.Format(time.RFC3339))
 	}
+	if line := assetLine(alert.Asset); line != "" {
+		body += "\nAsset: " + line
+	}
+	if alert.RunbookURL != "" {
+		body += fmt.Sprintf("\nRunbook: %s", alert.RunbookURL)
+	}
 
 	return fmt.Sprintf("%s\n\n%s", title, body)
 }
 
-// sendToApprise sends a message to Apprise API
-func (n *Notifier) sendToApprise(url, message, severity string) error {
-	// For MVP, we'll use Apprise API endpoint
-	// Apprise API expects: POST /notify/{service} with body
-	// For simplicity, we'll use the URL directly as Apprise service URL
-	
-	// If URL contains "://", it's already an Apprise service URL
-	// Otherwise, assume it's an Apprise API endpoint
-	
-	// Simple implementation: if it looks like an Apprise service URL, use it directly
-	// Otherwise, POST to Apprise API
-	
-	// For MVP, we'll assume Apprise service URLs are provided
-	// Format: slack://tokenA/tokenB/tokenC
-	// We'll use Apprise library or HTTP API
-	
-	// Simple HTTP POST to Apprise API (if running as service)
-	// For MVP, we'll use direct Apprise service URLs
-	
-	// Create request body
-	payload := map[string]string{
-		"body": message,
-		"title": fmt.Sprintf("NetSpec: %s", severity),
+// assetLine summarizes an alert's asset details on one line, e.g.
+// "chassis DCS-7280SR S/N JPE1234, software 4.28.1F, optic QSFP-100G-LR4 S/N X1"
+func assetLine(asset map[string]string) string {
+	var parts []string
+	for _, item := range []struct{ label, model, serial string }{
+		{"chassis", asset["chassis_model"], asset["chassis_serial"]},
+		{"software", asset["software_version"], ""},
+		{"optic", asset["optic_part_number"], asset["optic_serial"]},
+	} {
+		part := strings.TrimSpace(item.model)
+		if item.serial != "" {
+			part = strings.TrimSpace(part + " S/N " + item.serial)
+		}
+		if part != "" {
+			parts = append(parts, item.label+" "+part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// relatedPair is one related-state key and value
+type relatedPair struct {
+	Key, Value string
+}
+
+// relatedPairs returns an alert's related state sorted by key
+func relatedPairs(related map[string]string) []relatedPair {
+	pairs := make([]relatedPair, 0, len(related))
+	for k, v := range related {
+		pairs = append(pairs, relatedPair{k, v})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key < pairs[j].Key })
+	return pairs
+}
+
+// uiLinks returns the web UI pages of an alert's device and of the alert
+// itself under external_url, or empty strings when it is not set
+func uiLinks(externalURL string, alert *types.Alert) (device, alertPage string) {
+	base := strings.TrimSuffix(externalURL, "/")
+	if base == "" {
+		return "", ""
+	}
+	if alert.Device != "" {
+		device = base + "/device/" + url.PathEscape(alert.Device)
+	}
+	if alert.ID != "" {
+		alertPage = base + "/alert/" + url.PathEscape(alert.ID)
+	}
+	return device, alertPage
+}
+
+// sendToApprise sends a message to the Apprise API, with the channel's tag
+// and, if enabled, a PNG snapshot of the device's interfaces. Attachments
+// are uploaded as multipart form data; otherwise the payload is JSON.
+func (n *Notifier) sendToApprise(channel Channel, message string, alert *types.Alert) error {
+	fields := map[string]string{
+		"body":   message,
+		"title":  fmt.Sprintf("NetSpec: %s", alert.Severity),
 		"format": "text",
 	}
-	
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+	if channel.Tag != "" {
+		fields["tag"] = channel.Tag
 	}
 
 	// Try Apprise API endpoint first (if APPRISE_API_URL is set)
 	apiURL := os.Getenv("APPRISE_API_URL")
-	if apiURL != "" {
-		req, err := http.NewRequest("POST", fmt.Sprintf("%s/notify/%s", apiURL, url), bytes.NewBuffer(jsonData))
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("Content-Type", "application/json")
+	if apiURL == "" {
+		// Fallback: log that we would send (for MVP without Apprise service)
+		n.logger.Info().
+			Str("channel", channel.Name).
+			Str("tag", channel.Tag).
+			Str("message", message).
+			Msg("Would send notification (Apprise not configured)")
+		return nil
+	}
 
-		resp, err := n.client.Do(req)
+	var body bytes.Buffer
+	contentType := "application/json"
+	if attachment := n.snapshotFor(channel, alert); attachment != nil {
+		form := multipart.NewWriter(&body)
+		for key, value := range fields {
+			if err := form.WriteField(key, value); err != nil {
+				return fmt.Errorf("failed to build form: %w", err)
+			}
+		}
+		part, err := form.CreateFormFile("attach", fmt.Sprintf("netspec-%s.png", alert.Device))
 		if err != nil {
-			return fmt.Errorf("failed to send request: %w", err)
+			return fmt.Errorf("failed to build form: %w", err)
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode >= 400 {
-			body, _ := io.ReadAll(resp.Body)
-			return fmt.Errorf("Apprise API error: %d - %s", resp.StatusCode, string(body))
+		if _, err := part.Write(attachment); err != nil {
+			return fmt.Errorf("failed to build form: %w", err)
+		}
+		if err := form.Close(); err != nil {
+			return fmt.Errorf("failed to build form: %w", err)
 		}
+		contentType = form.FormDataContentType()
+	} else if err := json.NewEncoder(&body).Encode(fields); err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
 
-		return nil
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/notify/%s", apiURL, channel.URL), &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("Content-Type", contentType)
 
-	// Fallback: log that we would send (for MVP without Apprise service)
-	n.logger.Info().
-		Str("url", url).
-		Str("message", message).
-		Msg("Would send notification (Apprise not configured)")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Apprise API error: %d - %s", resp.StatusCode, string(body))
+	}
 
 	return nil
 }
+
+// snapshotFor renders the interface snapshot attachment for an alert, or
+// returns nil if the channel does not attach one or it cannot be rendered
+func (n *Notifier) snapshotFor(channel Channel, alert *types.Alert) []byte {
+	if !channel.AttachSnapshot {
+		return nil
+	}
+	n.mu.RLock()
+	snapshot := n.snapshot
+	n.mu.RUnlock()
+	if snapshot == nil {
+		return nil
+	}
+	states := snapshot(alert.Device)
+	if len(states) == 0 {
+		return nil
+	}
+	image, err := renderSnapshot(states, alert.Entity)
+	if err != nil {
+		n.logger.Warn().Err(err).Str("device", alert.Device).Msg("Failed to render state snapshot, sending without it")
+		return nil
+	}
+	return image
+}
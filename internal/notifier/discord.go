@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// Discord limits embed descriptions to 4096 characters and field values to
+// 1024
+const (
+	discordMaxDescription = 4096
+	discordMaxFieldValue  = 1024
+)
+
+// badgeColors maps severity badge colors onto the RGB colors of Discord
+// embed and Slack attachment sidebars
+var badgeColors = map[string]int{
+	"red":    0xd73a49,
+	"orange": 0xf0883e,
+	"yellow": 0xe3b341,
+	"blue":   0x388bfd,
+	"purple": 0xa371f7,
+	"gray":   0x8b949e,
+}
+
+// resolvedColor is used for every resolved alert
+const resolvedColor = 0x2ea043
+
+// discordPayload is the body of a Discord webhook execution
+type discordPayload struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+// sendDiscord posts the alert to a Discord webhook as an embed whose color
+// follows the severity's configured badge color, or green once resolved
+func (n *Notifier) sendDiscord(channel Channel, alert *types.Alert) error {
+	n.mu.RLock()
+	emoji := n.alerts.SeverityEmoji(alert.Severity)
+	color := badgeColors[n.alerts.SeverityColor(alert.Severity)]
+	n.mu.RUnlock()
+
+	at := alert.FiredAt
+	status := "Firing"
+	if alert.State == "resolved" {
+		emoji = "🟢"
+		color = resolvedColor
+		status = "Resolved"
+		if alert.ResolvedAt != nil {
+			at = *alert.ResolvedAt
+		}
+	}
+
+	fields := []discordField{
+		{Name: "Device", Value: orDash(alert.Device), Inline: true},
+		{Name: "Interface", Value: orDash(alert.Entity), Inline: true},
+		{Name: "Severity", Value: orDash(alert.Severity), Inline: true},
+	}
+	if alert.Acknowledged {
+		fields = append(fields, discordField{Name: "Acknowledged by", Value: orDash(alert.AcknowledgedBy), Inline: true})
+	}
+	if line := assetLine(alert.Asset); line != "" {
+		fields = append(fields, discordField{Name: "Asset", Value: truncate(line, discordMaxFieldValue)})
+	}
+	if alert.RunbookURL != "" {
+		fields = append(fields, discordField{Name: "Runbook", Value: truncate(alert.RunbookURL, discordMaxFieldValue)})
+	}
+
+	embed := discordEmbed{
+		Title:       fmt.Sprintf("%s %s: %s", emoji, status, alert.AlertType),
+		Description: truncate(alert.Message, discordMaxDescription),
+		URL:         alert.RunbookURL,
+		Color:       color,
+		Fields:      fields,
+	}
+	if alert.DedupKey != "" {
+		embed.Footer = &discordFooter{Text: alert.DedupKey}
+	}
+	if !at.IsZero() {
+		embed.Timestamp = at.UTC().Format(time.RFC3339)
+	}
+
+	body, err := json.Marshal(discordPayload{Username: "NetSpec", Embeds: []discordEmbed{embed}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("discord error: %d - %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// orDash substitutes "-" for empty values, which Discord rejects in fields
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// truncate shortens s to at most max runes, marking the cut with an ellipsis
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
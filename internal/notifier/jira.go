@@ -0,0 +1,70 @@
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// openJiraIssue creates an issue for a firing alert through the Jira REST
+// API v2, which takes descriptions as plain text on both Cloud and Data
+// Center
+func (n *Notifier) openJiraIssue(channel Channel, cfg config.TicketConfig, alert *types.Alert) (types.Ticket, error) {
+	labels := append([]string{"netspec"}, cfg.Labels...)
+	body := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": cfg.Project},
+			"issuetype":   map[string]string{"name": cfg.IssueType},
+			"summary":     ticketSummary(alert),
+			"description": n.ticketText(alert),
+			"labels":      labels,
+		},
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := n.ticketRequest(channel, "POST", "/rest/api/2/issue", body, &created); err != nil {
+		return types.Ticket{}, err
+	}
+	if created.Key == "" {
+		return types.Ticket{}, fmt.Errorf("jira returned no issue key")
+	}
+	return types.Ticket{
+		ID:  created.Key,
+		URL: strings.TrimSuffix(channel.URL, "/") + "/browse/" + created.Key,
+	}, nil
+}
+
+// commentJiraIssue adds a comment to an issue
+func (n *Notifier) commentJiraIssue(channel Channel, ticket types.Ticket, text string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(ticket.ID) + "/comment"
+	return n.ticketRequest(channel, "POST", path, map[string]string{"body": text}, nil)
+}
+
+// transitionJiraIssue moves an issue through the workflow transition with
+// the given name, or the one leading to the status of that name
+func (n *Notifier) transitionJiraIssue(channel Channel, ticket types.Ticket, name string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(ticket.ID) + "/transitions"
+	var available struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := n.ticketRequest(channel, "GET", path, nil, &available); err != nil {
+		return err
+	}
+	for _, t := range available.Transitions {
+		if strings.EqualFold(t.Name, name) || strings.EqualFold(t.To.Name, name) {
+			body := map[string]interface{}{"transition": map[string]string{"id": t.ID}}
+			return n.ticketRequest(channel, "POST", path, body, nil)
+		}
+	}
+	return fmt.Errorf("issue %s has no %q transition", ticket.ID, name)
+}
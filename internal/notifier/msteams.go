@@ -0,0 +1,158 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// teamsStyles maps severity badge colors onto Adaptive Card container
+// styles, the nearest a Teams card gets to a colored sidebar
+var teamsStyles = map[string]string{
+	"red":    "attention",
+	"orange": "warning",
+	"yellow": "warning",
+	"blue":   "accent",
+	"purple": "accent",
+	"gray":   "emphasis",
+}
+
+// teamsMessage is the body of a Teams incoming webhook or Workflows
+// webhook carrying one Adaptive Card
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string            `json:"$schema"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	MSTeams map[string]string `json:"msteams,omitempty"`
+	Body    []interface{}     `json:"body"`
+	Actions []teamsOpenURL    `json:"actions,omitempty"`
+}
+
+type teamsContainer struct {
+	Type  string           `json:"type"`
+	Style string           `json:"style,omitempty"`
+	Bleed bool             `json:"bleed,omitempty"`
+	Items []teamsTextBlock `json:"items"`
+}
+
+type teamsTextBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Wrap     bool   `json:"wrap,omitempty"`
+	Weight   string `json:"weight,omitempty"`
+	Size     string `json:"size,omitempty"`
+	IsSubtle bool   `json:"isSubtle,omitempty"`
+}
+
+type teamsFactSet struct {
+	Type  string      `json:"type"`
+	Facts []teamsFact `json:"facts"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type teamsOpenURL struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// sendTeams posts the alert to a Microsoft Teams webhook as an Adaptive
+// Card whose header is styled by the severity's badge color (good once
+// resolved), with device, interface, severity and related state as facts
+// and, when external_url is set, buttons back to the device and alert
+// pages
+func (n *Notifier) sendTeams(channel Channel, alert *types.Alert) error {
+	n.mu.RLock()
+	emoji := n.alerts.SeverityEmoji(alert.Severity)
+	style := teamsStyles[n.alerts.SeverityColor(alert.Severity)]
+	deviceURL, alertURL := uiLinks(n.alerts.ExternalURL, alert)
+	n.mu.RUnlock()
+
+	status := "Firing"
+	if alert.State == "resolved" {
+		emoji = "🟢"
+		style = "good"
+		status = "Resolved"
+	}
+
+	facts := []teamsFact{
+		{Title: "Device", Value: orDash(alert.Device)},
+		{Title: "Interface", Value: orDash(alert.Entity)},
+		{Title: "Severity", Value: orDash(alert.Severity)},
+	}
+	if alert.Acknowledged {
+		facts = append(facts, teamsFact{Title: "Acknowledged by", Value: orDash(alert.AcknowledgedBy)})
+	}
+	for _, pair := range relatedPairs(alert.RelatedState) {
+		facts = append(facts, teamsFact{Title: pair.Key, Value: orDash(pair.Value)})
+	}
+	if line := assetLine(alert.Asset); line != "" {
+		facts = append(facts, teamsFact{Title: "Asset", Value: line})
+	}
+
+	body := []interface{}{
+		teamsContainer{
+			Type:  "Container",
+			Style: style,
+			Bleed: true,
+			Items: []teamsTextBlock{{
+				Type:   "TextBlock",
+				Text:   fmt.Sprintf("%s %s: %s", emoji, status, alert.AlertType),
+				Wrap:   true,
+				Weight: "Bolder",
+				Size:   "Medium",
+			}},
+		},
+		teamsTextBlock{Type: "TextBlock", Text: orDash(alert.Message), Wrap: true},
+		teamsFactSet{Type: "FactSet", Facts: facts},
+	}
+	if alert.DedupKey != "" {
+		body = append(body, teamsTextBlock{Type: "TextBlock", Text: alert.DedupKey, Wrap: true, Size: "Small", IsSubtle: true})
+	}
+
+	var actions []teamsOpenURL
+	for _, link := range []struct{ label, url string }{
+		{"Device", deviceURL},
+		{"Alert", alertURL},
+		{"Runbook", alert.RunbookURL},
+	} {
+		if link.url != "" {
+			actions = append(actions, teamsOpenURL{Type: "Action.OpenUrl", Title: link.label, URL: link.url})
+		}
+	}
+
+	msg := teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				MSTeams: map[string]string{"width": "Full"},
+				Body:    body,
+				Actions: actions,
+			},
+		}},
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return n.postJSON(channel.URL, payload, "teams")
+}
@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// Default push priorities by severity rank, most severe first. Resolved
+// alerts use the lowest priority so recoveries do not break through
+// do-not-disturb.
+var (
+	ntfyPriorities   = []int{5, 4, 3} // ntfy: 1 (min) to 5 (urgent)
+	gotifyPriorities = []int{8, 5, 3} // Gotify: 0 to 10
+)
+
+const (
+	ntfyLowPriority   = 2
+	gotifyLowPriority = 1
+)
+
+// pushPriority maps an alert onto a push priority: the channel's priorities
+// entry for the severity if set, otherwise by severity rank
+func (n *Notifier) pushPriority(channel Channel, alert *types.Alert, defaults []int, low int) int {
+	if alert.State == "resolved" {
+		return low
+	}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	level := n.alerts.NormalizeSeverity(alert.Severity)
+	for sev, priority := range channel.Priorities {
+		if n.alerts.NormalizeSeverity(sev) == level {
+			return priority
+		}
+	}
+	if rank := n.alerts.SeverityRank(alert.Severity); rank < len(defaults) {
+		return defaults[rank]
+	}
+	return low
+}
+
+// pushTitle is the notification title shown on the phone
+func pushTitle(alert *types.Alert) string {
+	if alert.State == "resolved" {
+		return fmt.Sprintf("Resolved: %s %s", alert.Device, alert.Entity)
+	}
+	return fmt.Sprintf("%s: %s %s", strings.ToUpper(alert.Severity), alert.Device, alert.Entity)
+}
+
+// sendNtfy publishes the alert to an ntfy topic URL. The channel secret, if
+// set, is sent as a bearer access token.
+func (n *Notifier) sendNtfy(channel Channel, alert *types.Alert) error {
+	req, err := http.NewRequest("POST", channel.URL, strings.NewReader(n.formatMessage(alert)))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Title", pushTitle(alert))
+	req.Header.Set("Priority", fmt.Sprint(n.pushPriority(channel, alert, ntfyPriorities, ntfyLowPriority)))
+	tags := []string{"netspec", alert.Severity}
+	if alert.State == "resolved" {
+		tags = append(tags, "white_check_mark")
+	}
+	req.Header.Set("Tags", strings.Join(tags, ","))
+	if alert.RunbookURL != "" {
+		req.Header.Set("Click", alert.RunbookURL)
+	}
+	if channel.Secret != "" {
+		req.Header.Set("Authorization", "Bearer "+channel.Secret)
+	}
+	return n.doPush(req, "ntfy")
+}
+
+// gotifyMessage is the body of a Gotify POST /message
+type gotifyMessage struct {
+	Title    string                 `json:"title"`
+	Message  string                 `json:"message"`
+	Priority int                    `json:"priority"`
+	Extras   map[string]interface{} `json:"extras,omitempty"`
+}
+
+// sendGotify posts the alert to a Gotify server. The channel URL is the
+// server base URL and the channel secret is the application token.
+func (n *Notifier) sendGotify(channel Channel, alert *types.Alert) error {
+	msg := gotifyMessage{
+		Title:    pushTitle(alert),
+		Message:  n.formatMessage(alert),
+		Priority: n.pushPriority(channel, alert, gotifyPriorities, gotifyLowPriority),
+	}
+	if alert.RunbookURL != "" {
+		msg.Extras = map[string]interface{}{
+			"client::notification": map[string]interface{}{
+				"click": map[string]string{"url": alert.RunbookURL},
+			},
+		}
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimSuffix(channel.URL, "/")+"/message", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", channel.Secret)
+	return n.doPush(req, "gotify")
+}
+
+// doPush sends a request to a push or SMS service and turns error statuses
+// into errors
+func (n *Notifier) doPush(req *http.Request, service string) error {
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s error: %d - %s", service, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
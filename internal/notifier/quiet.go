@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// AlertTypeQuietDigest is the alert type of the digest sent when a
+// channel's quiet hours end
+const AlertTypeQuietDigest = "quiet_hours_digest"
+
+// quietCheckInterval is how often RunQuietHours looks for quiet hours that
+// have ended
+const quietCheckInterval = 30 * time.Second
+
+// quietDigest collects the notifications a channel held during quiet hours
+type quietDigest struct {
+	since   time.Time
+	order   []string // dedup keys in the order first held
+	entries map[string]*heldAlert
+	total   int // notifications held, counting repeats for the same alert
+}
+
+// heldAlert is the latest held notification of one alert and what happened
+// to it while held
+type heldAlert struct {
+	alert  types.Alert
+	events []string // e.g. "fired 02:14", "resolved 03:40"
+}
+
+// holdQuiet adds an alert to a channel's digest. Notifications for the same
+// alert collapse into one entry, so a flap overnight is one line.
+func (n *Notifier) holdQuiet(channelName string, alert *types.Alert, now time.Time) {
+	n.heldMu.Lock()
+	defer n.heldMu.Unlock()
+
+	digest, ok := n.held[channelName]
+	if !ok {
+		digest = &quietDigest{since: now, entries: make(map[string]*heldAlert)}
+		n.held[channelName] = digest
+	}
+	key := alert.DedupKey
+	if key == "" {
+		key = alert.ID
+	}
+	entry, ok := digest.entries[key]
+	if !ok {
+		entry = &heldAlert{}
+		digest.entries[key] = entry
+		digest.order = append(digest.order, key)
+	}
+	event := "fired"
+	if alert.State == "resolved" {
+		event = "resolved"
+	}
+	entry.alert = *alert
+	entry.events = append(entry.events, event+" "+now.Format("15:04"))
+	digest.total++
+
+	n.logger.Debug().
+		Str("channel", channelName).
+		Str("alert_id", alert.ID).
+		Msg("Quiet hours, holding notification for digest")
+}
+
+// flushQuiet sends a channel's digest, if it has one, and clears it
+func (n *Notifier) flushQuiet(alerts *config.AlertsConfig, channelName string) {
+	n.heldMu.Lock()
+	digest, ok := n.held[channelName]
+	delete(n.held, channelName)
+	n.heldMu.Unlock()
+	if !ok {
+		return
+	}
+
+	chCfg, ok := alerts.Channels[channelName]
+	if !ok {
+		n.logger.Warn().
+			Str("channel", channelName).
+			Int("held", digest.total).
+			Msg("Channel removed during quiet hours, dropping held notifications")
+		return
+	}
+	channel, ok := channelFromConfig(channelName, chCfg)
+	if !ok {
+		n.logger.Warn().
+			Str("channel", channelName).
+			Str("url_env", chCfg.URLEnv).
+			Int("held", digest.total).
+			Msg("Channel URL not set, dropping quiet hours digest")
+		return
+	}
+	alert := digest.alert(alerts, channelName, time.Now())
+	channel.Tag = appriseTag(alerts, chCfg, alert.Severity)
+	n.deliver([]Channel{channel}, alert)
+}
+
+// RunQuietHours sends each channel's digest once its quiet hours end, or
+// the channel loses its quiet hours in a reload, until ctx is cancelled.
+// A notification sent to the channel after quiet hours sends it sooner.
+func (n *Notifier) RunQuietHours(ctx context.Context) {
+	ticker := time.NewTicker(quietCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n.mu.RLock()
+			alerts := n.alerts
+			n.mu.RUnlock()
+
+			n.heldMu.Lock()
+			var due []string
+			for name := range n.held {
+				chCfg, ok := alerts.Channels[name]
+				if !ok || chCfg.QuietHours == nil || !chCfg.QuietHours.Active(now) {
+					due = append(due, name)
+				}
+			}
+			n.heldMu.Unlock()
+
+			sort.Strings(due)
+			for _, name := range due {
+				n.flushQuiet(&alerts, name)
+			}
+		}
+	}
+}
+
+// alert builds the digest notification: one line per held alert with what
+// happened to it, at the highest severity held. It counts as resolved only
+// when everything held has resolved.
+func (d *quietDigest) alert(alerts *config.AlertsConfig, channelName string, now time.Time) *types.Alert {
+	var (
+		severity string
+		devices  []string
+		seen     = make(map[string]bool)
+		lines    []string
+		firing   int
+	)
+	for _, key := range d.order {
+		entry := d.entries[key]
+		a := entry.alert
+		if severity == "" || alerts.SeverityRank(a.Severity) < alerts.SeverityRank(severity) {
+			severity = a.Severity
+		}
+		if !seen[a.Device] {
+			seen[a.Device] = true
+			devices = append(devices, a.Device)
+		}
+		if a.State != "resolved" {
+			firing++
+		}
+		lines = append(lines, fmt.Sprintf("- %s %s %s (%s): %s",
+			a.Device, a.Entity, a.AlertType, a.Severity, strings.Join(entry.events, ", ")))
+	}
+
+	device := strings.Join(devices, ", ")
+	if len(devices) > 3 {
+		device = fmt.Sprintf("%d devices", len(devices))
+	}
+	state := "firing"
+	var resolvedAt *time.Time
+	if firing == 0 {
+		state = "resolved"
+		resolvedAt = &now
+	}
+
+	return &types.Alert{
+		ID:         fmt.Sprintf("%s-%s-%d", AlertTypeQuietDigest, channelName, now.Unix()),
+		Device:     device,
+		Entity:     "quiet hours digest",
+		AlertType:  AlertTypeQuietDigest,
+		Severity:   severity,
+		State:      state,
+		FiredAt:    d.since,
+		ResolvedAt: resolvedAt,
+		Message: fmt.Sprintf("%d notifications held during quiet hours since %s, %d still firing:\n%s",
+			d.total, d.since.Format("2006-01-02 15:04"), firing, strings.Join(lines, "\n")),
+		RelatedState: map[string]string{
+			"held_notifications": strconv.Itoa(d.total),
+			"held_alerts":        strconv.Itoa(len(d.order)),
+			"still_firing":       strconv.Itoa(firing),
+		},
+		DedupKey: AlertTypeQuietDigest + "|" + channelName,
+	}
+}
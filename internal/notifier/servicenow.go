@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// openServiceNowRecord creates a record, an incident by default, for a
+// firing alert through the Table API. Urgency and impact follow the
+// severity rank (1 for the most severe, at most 3) and the dedup key is
+// kept as the correlation ID.
+func (n *Notifier) openServiceNowRecord(channel Channel, cfg config.TicketConfig, alert *types.Alert) (types.Ticket, error) {
+	n.mu.RLock()
+	level := n.alerts.SeverityRank(alert.Severity) + 1
+	n.mu.RUnlock()
+	if level > 3 {
+		level = 3
+	}
+
+	body := map[string]string{
+		"short_description": ticketSummary(alert),
+		"description":       n.ticketText(alert),
+		"urgency":           strconv.Itoa(level),
+		"impact":            strconv.Itoa(level),
+		"correlation_id":    alert.DedupKey,
+	}
+	if cfg.AssignmentGroup != "" {
+		body["assignment_group"] = cfg.AssignmentGroup
+	}
+	var created struct {
+		Result struct {
+			SysID  string `json:"sys_id"`
+			Number string `json:"number"`
+		} `json:"result"`
+	}
+	if err := n.ticketRequest(channel, "POST", "/api/now/table/"+url.PathEscape(cfg.Table), body, &created); err != nil {
+		return types.Ticket{}, err
+	}
+	if created.Result.SysID == "" {
+		return types.Ticket{}, fmt.Errorf("servicenow returned no sys_id")
+	}
+	ticket := types.Ticket{
+		ID:  created.Result.Number,
+		Ref: cfg.Table + "/" + created.Result.SysID,
+		URL: strings.TrimSuffix(channel.URL, "/") + "/nav_to.do?uri=" +
+			url.QueryEscape(cfg.Table+".do?sys_id="+created.Result.SysID),
+	}
+	if ticket.ID == "" {
+		ticket.ID = created.Result.SysID
+	}
+	return ticket, nil
+}
+
+// updateServiceNowRecord sets fields on a record, such as work notes or
+// its resolution
+func (n *Notifier) updateServiceNowRecord(channel Channel, ticket types.Ticket, fields map[string]string) error {
+	table, sysID, ok := strings.Cut(ticket.Ref, "/")
+	if !ok {
+		return fmt.Errorf("ticket %s has no record reference", ticket.ID)
+	}
+	path := "/api/now/table/" + url.PathEscape(table) + "/" + url.PathEscape(sysID)
+	return n.ticketRequest(channel, "PATCH", path, fields, nil)
+}
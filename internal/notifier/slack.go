@@ -0,0 +1,149 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// Slack limits section text to 3000 characters, field text to 2000 and
+// header text to 150; a section holds at most 10 fields
+const (
+	slackMaxText      = 3000
+	slackMaxFieldText = 2000
+	slackMaxHeader    = 150
+	slackMaxFields    = 10
+)
+
+// slackPayload is the body of a Slack incoming webhook message. The blocks
+// sit in an attachment so its sidebar can carry the severity color; text is
+// the fallback shown in notifications.
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string        `json:"type"`
+	Text     *slackText    `json:"text,omitempty"`
+	Fields   []slackText   `json:"fields,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"` // context texts or action buttons
+	URL      string        `json:"url,omitempty"`      // button
+}
+
+type slackText struct {
+	Type string `json:"type"` // plain_text or mrkdwn
+	Text string `json:"text"`
+}
+
+// sendSlack posts the alert to a Slack incoming webhook as Block Kit blocks
+// in an attachment colored by the severity's badge color (green once
+// resolved), with related state as fields and, when external_url is set,
+// buttons back to the device and alert pages
+func (n *Notifier) sendSlack(channel Channel, alert *types.Alert) error {
+	n.mu.RLock()
+	emoji := n.alerts.SeverityEmoji(alert.Severity)
+	color := badgeColors[n.alerts.SeverityColor(alert.Severity)]
+	deviceURL, alertURL := uiLinks(n.alerts.ExternalURL, alert)
+	n.mu.RUnlock()
+
+	status := "Firing"
+	if alert.State == "resolved" {
+		emoji = "🟢"
+		color = resolvedColor
+		status = "Resolved"
+	}
+	title := fmt.Sprintf("%s %s: %s", emoji, status, alert.AlertType)
+
+	device := slackEscape(alert.Device)
+	if deviceURL != "" {
+		device = fmt.Sprintf("<%s|%s>", deviceURL, device)
+	}
+	fields := []slackText{
+		{Type: "mrkdwn", Text: "*Device*\n" + orDash(device)},
+		{Type: "mrkdwn", Text: "*Interface*\n" + orDash(slackEscape(alert.Entity))},
+		{Type: "mrkdwn", Text: "*Severity*\n" + orDash(slackEscape(alert.Severity))},
+	}
+	if alert.Acknowledged {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: "*Acknowledged by*\n" + orDash(slackEscape(alert.AcknowledgedBy))})
+	}
+	for _, pair := range relatedPairs(alert.RelatedState) {
+		if len(fields) == slackMaxFields {
+			break
+		}
+		fields = append(fields, slackText{Type: "mrkdwn", Text: truncate("*"+slackEscape(pair.Key)+"*\n"+orDash(slackEscape(pair.Value)), slackMaxFieldText)})
+	}
+
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: truncate(title, slackMaxHeader)}},
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: truncate(orDash(slackEscape(alert.Message)), slackMaxText)}},
+		{Type: "section", Fields: fields},
+	}
+	if line := assetLine(alert.Asset); line != "" {
+		blocks = append(blocks, slackBlock{Type: "context", Elements: []interface{}{
+			slackText{Type: "mrkdwn", Text: truncate(slackEscape(line), slackMaxFieldText)},
+		}})
+	}
+	var buttons []interface{}
+	for _, link := range []struct{ label, url string }{
+		{"Device", deviceURL},
+		{"Alert", alertURL},
+		{"Runbook", alert.RunbookURL},
+	} {
+		if link.url != "" {
+			buttons = append(buttons, slackBlock{Type: "button", Text: &slackText{Type: "plain_text", Text: link.label}, URL: link.url})
+		}
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, slackBlock{Type: "actions", Elements: buttons})
+	}
+
+	payload := slackPayload{
+		Text:        fmt.Sprintf("%s %s %s", title, alert.Device, alert.Entity),
+		Attachments: []slackAttachment{{Color: fmt.Sprintf("#%06x", color), Blocks: blocks}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return n.postJSON(channel.URL, body, "slack")
+}
+
+// slackEscape escapes the characters Slack reserves for links and mentions
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// postJSON posts a JSON body to a chat webhook, reporting error statuses
+// with the start of the response
+func (n *Notifier) postJSON(url string, body []byte, service string) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s error: %d - %s", service, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
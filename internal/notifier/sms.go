@@ -0,0 +1,121 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// smsMaxLength keeps texts within two concatenated SMS segments
+const smsMaxLength = 306
+
+// smsText is the short form of an alert sent by text message
+func smsText(alert *types.Alert) string {
+	status := strings.ToUpper(alert.Severity)
+	if alert.State == "resolved" {
+		status = "RESOLVED"
+	}
+	text := fmt.Sprintf("NetSpec %s %s %s %s", status, alert.Device, alert.Entity, alert.AlertType)
+	if alert.Message != "" && alert.State != "resolved" {
+		text += ": " + alert.Message
+	}
+	return truncate(text, smsMaxLength)
+}
+
+// sendSMS texts the alert to every configured recipient through Twilio or a
+// generic HTTP gateway
+func (n *Notifier) sendSMS(channel Channel, alert *types.Alert) error {
+	if channel.SMS == nil || len(channel.SMS.To) == 0 {
+		return fmt.Errorf("sms channel has no recipients")
+	}
+	text := smsText(alert)
+	if channel.SMS.Provider == "http" {
+		return n.sendSMSGateway(channel, text)
+	}
+
+	// Twilio accepts one recipient per message; keep going past failures so
+	// one bad number doesn't silence the rest
+	var failed []string
+	for _, to := range channel.SMS.To {
+		if err := n.sendTwilio(channel, to, text); err != nil {
+			n.logger.Error().
+				Err(err).
+				Str("channel", channel.Name).
+				Str("to", to).
+				Msg("Failed to send SMS")
+			failed = append(failed, to)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("sms failed for %d of %d recipients", len(failed), len(channel.SMS.To))
+	}
+	return nil
+}
+
+// sendTwilio creates one message through the Twilio Messages API. The
+// account SID for basic auth is taken from the endpoint URL.
+func (n *Notifier) sendTwilio(channel Channel, to, text string) error {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", channel.SMS.From)
+	form.Set("Body", text)
+
+	req, err := http.NewRequest("POST", channel.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(twilioAccountSID(channel.URL), channel.Secret)
+	return n.doPush(req, "twilio")
+}
+
+// twilioAccountSID extracts the account SID from a Messages endpoint URL
+// (.../Accounts/<SID>/Messages.json)
+func twilioAccountSID(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "Accounts" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// smsGatewayPayload is the body posted to a generic HTTP SMS gateway
+type smsGatewayPayload struct {
+	From    string   `json:"from,omitempty"`
+	To      []string `json:"to"`
+	Message string   `json:"message"`
+}
+
+// sendSMSGateway posts the text for all recipients to a generic HTTP gateway
+// in one request
+func (n *Notifier) sendSMSGateway(channel Channel, text string) error {
+	body, err := json.Marshal(smsGatewayPayload{
+		From:    channel.SMS.From,
+		To:      channel.SMS.To,
+		Message: text,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if channel.Secret != "" {
+		req.Header.Set("Authorization", "Bearer "+channel.Secret)
+	}
+	return n.doPush(req, "sms gateway")
+}
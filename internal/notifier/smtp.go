@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// smtpTimeout bounds the whole exchange with the mail relay
+const smtpTimeout = 30 * time.Second
+
+// smtpMessage builds the email for an alert: the push title as subject and
+// the notification text as body
+func (n *Notifier) smtpMessage(from string, to []string, alert *types.Alert, at time.Time) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: NetSpec %s\r\n", pushTitle(alert))
+	fmt.Fprintf(&msg, "Date: %s\r\n", at.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	body := strings.ReplaceAll(n.formatMessage(alert), "\r\n", "\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+	return msg.Bytes()
+}
+
+// sendSMTP emails the alert through the relay at smtp://[user@]host[:port].
+// STARTTLS is used when offered; the channel secret, with the URL's user,
+// authenticates.
+func (n *Notifier) sendSMTP(channel Channel, alert *types.Alert) error {
+	if channel.SMTP == nil || len(channel.SMTP.To) == 0 {
+		return fmt.Errorf("smtp channel has no recipients")
+	}
+	u, err := url.Parse(channel.URL)
+	if err != nil || u.Scheme != "smtp" || u.Host == "" {
+		return fmt.Errorf("smtp url must be smtp://[user@]host[:port]")
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "25")
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, smtpTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mail relay: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+	client, err := smtp.NewClient(conn, u.Hostname())
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: u.Hostname()}); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if channel.Secret != "" && u.User != nil {
+		if err := client.Auth(smtp.PlainAuth("", u.User.Username(), channel.Secret, u.Hostname())); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(channel.SMTP.From); err != nil {
+		return fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	for _, to := range channel.SMTP.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("RCPT TO %s rejected: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := w.Write(n.smtpMessage(channel.SMTP.From, channel.SMTP.To, alert, time.Now())); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"sort"
+)
+
+// InterfaceSnapshot is one interface's observed state in a snapshot image
+type InterfaceSnapshot struct {
+	Name        string
+	OperStatus  string // "up", "down" or empty if not yet reported
+	AdminStatus string // "enabled", "disabled" or empty
+}
+
+// SnapshotFunc returns the current interface states of a device
+type SnapshotFunc func(device string) []InterfaceSnapshot
+
+// Snapshot layout: one square cell per interface in name order, wrapping
+// after snapshotColumns cells
+const (
+	snapshotCell    = 14
+	snapshotGap     = 2
+	snapshotColumns = 24
+)
+
+var (
+	snapshotBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	snapshotUp         = color.RGBA{0x2e, 0xa0, 0x43, 0xff}
+	snapshotDown       = color.RGBA{0xd7, 0x3a, 0x49, 0xff}
+	snapshotAdminDown  = color.RGBA{0xe3, 0xb3, 0x41, 0xff}
+	snapshotUnknown    = color.RGBA{0xb0, 0xb0, 0xb0, 0xff}
+	snapshotHighlight  = color.RGBA{0x00, 0x00, 0x00, 0xff}
+)
+
+// SetSnapshotFunc sets the source of interface states for channels with
+// attach_snapshot enabled
+func (n *Notifier) SetSnapshotFunc(fn SnapshotFunc) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.snapshot = fn
+}
+
+// renderSnapshot draws a device's interfaces as a grid of colored cells:
+// green up, red down, amber admin-disabled, gray not yet reported. The cell
+// of the highlighted interface is outlined.
+func renderSnapshot(states []InterfaceSnapshot, highlight string) ([]byte, error) {
+	states = append([]InterfaceSnapshot(nil), states...)
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+
+	columns := snapshotColumns
+	if len(states) < columns {
+		columns = len(states)
+	}
+	if columns == 0 {
+		columns = 1
+	}
+	rows := (len(states) + columns - 1) / columns
+	if rows == 0 {
+		rows = 1
+	}
+	pitch := snapshotCell + snapshotGap
+	img := image.NewRGBA(image.Rect(0, 0, columns*pitch+snapshotGap, rows*pitch+snapshotGap))
+	fill(img, img.Bounds(), snapshotBackground)
+
+	for i, state := range states {
+		x := snapshotGap + (i%columns)*pitch
+		y := snapshotGap + (i/columns)*pitch
+		cell := image.Rect(x, y, x+snapshotCell, y+snapshotCell)
+		fill(img, cell, snapshotColor(state))
+		if state.Name == highlight {
+			outline(img, cell, snapshotHighlight)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// snapshotColor picks a cell color for an interface state
+func snapshotColor(state InterfaceSnapshot) color.RGBA {
+	switch {
+	case state.AdminStatus == "disabled":
+		return snapshotAdminDown
+	case state.OperStatus == "up":
+		return snapshotUp
+	case state.OperStatus == "down":
+		return snapshotDown
+	}
+	return snapshotUnknown
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func outline(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		img.SetRGBA(x, r.Min.Y, c)
+		img.SetRGBA(x, r.Max.Y-1, c)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		img.SetRGBA(r.Min.X, y, c)
+		img.SetRGBA(r.Max.X-1, y, c)
+	}
+}
@@ -0,0 +1,99 @@
+package notifier
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+// Syslog facility local0 and the severities alerts are sent at
+const (
+	syslogFacility = 16
+	syslogCrit     = 2
+	syslogWarning  = 4
+	syslogNotice   = 5
+	syslogInfo     = 6
+)
+
+// syslogSDID names the structured data element carrying the alert fields
+// (32473 is the documentation enterprise number)
+const syslogSDID = "netspec@32473"
+
+// syslogSeverity maps an alert onto a syslog severity: the most severe
+// level is crit, the next warning, the rest info, and resolutions notice
+func (n *Notifier) syslogSeverity(alert *types.Alert) int {
+	if alert.State == "resolved" {
+		return syslogNotice
+	}
+	n.mu.RLock()
+	rank := n.alerts.SeverityRank(alert.Severity)
+	n.mu.RUnlock()
+	switch rank {
+	case 0:
+		return syslogCrit
+	case 1:
+		return syslogWarning
+	}
+	return syslogInfo
+}
+
+// syslogMessage formats an alert as an RFC 5424 message with the alert's
+// fields as structured data
+func syslogMessage(severity int, hostname string, alert *types.Alert, at time.Time) string {
+	sd := fmt.Sprintf(`[%s device="%s" entity="%s" type="%s" severity="%s" state="%s" dedup_key="%s"]`,
+		syslogSDID, sdEscape(alert.Device), sdEscape(alert.Entity), sdEscape(alert.AlertType),
+		sdEscape(alert.Severity), sdEscape(alert.State), sdEscape(alert.DedupKey))
+	text := strings.Join(strings.Fields(alert.Message), " ")
+	if text == "" {
+		text = fmt.Sprintf("%s %s %s", alert.Device, alert.Entity, alert.AlertType)
+	}
+	return fmt.Sprintf("<%d>1 %s %s netspec %d %s %s %s",
+		syslogFacility*8+severity, at.UTC().Format(time.RFC3339), hostname, os.Getpid(), alert.AlertType, sd, text)
+}
+
+// sdEscape escapes a structured data parameter value
+func sdEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+// sendSyslog sends the alert to a syslog collector at udp://host[:514] or
+// tcp://host[:514]; TCP messages use octet-counting framing (RFC 6587)
+func (n *Notifier) sendSyslog(channel Channel, alert *types.Alert) error {
+	u, err := url.Parse(channel.URL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("syslog url must be udp://host[:port] or tcp://host[:port]")
+	}
+	network := u.Scheme
+	if network != "udp" && network != "tcp" {
+		return fmt.Errorf("syslog url scheme must be udp or tcp, got %q", network)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "514")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	msg := syslogMessage(n.syslogSeverity(alert), hostname, alert, time.Now())
+
+	conn, err := net.DialTimeout(network, addr, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog collector: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if network == "tcp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("failed to send syslog message: %w", err)
+	}
+	return nil
+}
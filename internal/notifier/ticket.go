@@ -0,0 +1,221 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+)
+
+// ticketQueueSize bounds the ticket operations waiting for the worker
+const ticketQueueSize = 256
+
+// TicketHook receives a ticket opened for the alert with the given ID
+type TicketHook func(alertID string, ticket types.Ticket)
+
+// ticketJob is a notification for a jira or servicenow channel
+type ticketJob struct {
+	channel Channel
+	alert   types.Alert
+}
+
+// SetTicketHook registers a function receiving every ticket opened, so it
+// can be recorded on its alert
+func (n *Notifier) SetTicketHook(fn TicketHook) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.ticketHook = fn
+}
+
+// queueTicket hands a ticket channel's notification to the ticket worker.
+// Alerts are sent with the alert engine's lock held and ticketing APIs can
+// be slow, so tickets are worked on in the background, one at a time so a
+// resolution never overtakes the ticket it closes.
+func (n *Notifier) queueTicket(channel Channel, alert *types.Alert) {
+	select {
+	case n.ticketJobs <- ticketJob{channel: channel, alert: *alert}:
+	default:
+		n.logger.Error().
+			Str("channel", channel.Name).
+			Str("alert_id", alert.ID).
+			Msg("Ticket queue full, dropping notification")
+	}
+}
+
+// runTickets works through queued ticket notifications
+func (n *Notifier) runTickets() {
+	for job := range n.ticketJobs {
+		event, err := n.handleTicket(job.channel, &job.alert)
+		if err != nil {
+			n.logger.Error().
+				Err(err).
+				Str("channel", job.channel.Name).
+				Str("alert_id", job.alert.ID).
+				Msg("Failed to update ticket")
+		} else if event != "" {
+			n.logger.Info().
+				Str("channel", job.channel.Name).
+				Str("alert_id", job.alert.ID).
+				Msg("Ticket " + event)
+		}
+	}
+}
+
+// handleTicket opens a ticket when an alert fires, comments on it when the
+// alert is notified again, e.g. escalated, and comments on and closes it
+// when the alert resolves. Open tickets are looked up on the alert, which
+// keeps them across restarts, and in openTickets for those the alert engine
+// has not been told about yet. It returns what was done, if anything.
+func (n *Notifier) handleTicket(channel Channel, alert *types.Alert) (string, error) {
+	var cfg config.TicketConfig
+	if channel.Ticket != nil {
+		cfg = *channel.Ticket
+	}
+	cfg = cfg.WithDefaults()
+
+	key := alert.DedupKey + "|" + channel.Name
+	ticket, open := n.openTickets[key]
+	for _, t := range alert.Tickets {
+		if !open && t.Channel == channel.Name {
+			ticket, open = t, true
+		}
+	}
+
+	if alert.State == "resolved" {
+		if !open {
+			// Never opened, e.g. the channel was added while it fired
+			return "", nil
+		}
+		delete(n.openTickets, key)
+		if cfg.OnResolve == config.TicketResolveComment {
+			return "commented on " + ticket.ID, n.commentTicket(channel, ticket, alert)
+		}
+		return "closed " + ticket.ID, n.closeTicket(channel, cfg, ticket, alert)
+	}
+	if open {
+		return "commented on " + ticket.ID, n.commentTicket(channel, ticket, alert)
+	}
+
+	var err error
+	switch channel.Type {
+	case "jira":
+		ticket, err = n.openJiraIssue(channel, cfg, alert)
+	case "servicenow":
+		ticket, err = n.openServiceNowRecord(channel, cfg, alert)
+	}
+	if err != nil {
+		return "", err
+	}
+	ticket.Channel = channel.Name
+	n.openTickets[key] = ticket
+
+	n.mu.RLock()
+	hook := n.ticketHook
+	n.mu.RUnlock()
+	if hook != nil {
+		hook(alert.ID, ticket)
+	}
+	return "opened " + ticket.ID, nil
+}
+
+// commentTicket adds the alert's current message to its ticket
+func (n *Notifier) commentTicket(channel Channel, ticket types.Ticket, alert *types.Alert) error {
+	if channel.Type == "jira" {
+		return n.commentJiraIssue(channel, ticket, n.ticketText(alert))
+	}
+	return n.updateServiceNowRecord(channel, ticket, map[string]string{"work_notes": n.ticketText(alert)})
+}
+
+// closeTicket comments on the ticket with the resolution and closes it
+func (n *Notifier) closeTicket(channel Channel, cfg config.TicketConfig, ticket types.Ticket, alert *types.Alert) error {
+	if channel.Type == "jira" {
+		if err := n.commentJiraIssue(channel, ticket, n.ticketText(alert)); err != nil {
+			return err
+		}
+		return n.transitionJiraIssue(channel, ticket, cfg.CloseTransition)
+	}
+	return n.updateServiceNowRecord(channel, ticket, map[string]string{
+		"state":       cfg.ResolveState,
+		"close_code":  cfg.CloseCode,
+		"close_notes": n.ticketText(alert),
+	})
+}
+
+// ticketSummary is a ticket's one-line title
+func ticketSummary(alert *types.Alert) string {
+	summary := fmt.Sprintf("[%s] %s %s: %s", strings.ToUpper(alert.Severity), alert.Device, alert.Entity, alert.AlertType)
+	if len(summary) > 250 {
+		summary = summary[:250]
+	}
+	return summary
+}
+
+// ticketText is a ticket's description or comment: the notification
+// message, the related state and a link to the alert page
+func (n *Notifier) ticketText(alert *types.Alert) string {
+	text := n.formatMessage(alert)
+	if pairs := relatedPairs(alert.RelatedState); len(pairs) > 0 {
+		text += "\n"
+		for _, p := range pairs {
+			text += fmt.Sprintf("\n%s: %s", p.Key, p.Value)
+		}
+	}
+	n.mu.RLock()
+	_, alertURL := uiLinks(n.alerts.ExternalURL, alert)
+	n.mu.RUnlock()
+	if alertURL != "" {
+		text += "\n\nNetSpec: " + alertURL
+	}
+	return text
+}
+
+// ticketRequest calls a ticketing API below the channel's base URL,
+// authenticating with the channel secret: basic auth when it is user:token,
+// a bearer token otherwise. The response, if out is not nil, is decoded
+// into out.
+func (n *Notifier) ticketRequest(channel Channel, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(channel.URL, "/")+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if strings.Contains(channel.Secret, ":") {
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(channel.Secret)))
+	} else if channel.Secret != "" {
+		req.Header.Set("Authorization", "Bearer "+channel.Secret)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s error: %d - %s", channel.Type, resp.StatusCode, string(respBody))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", channel.Type, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/netspec/netspec/internal/types"
+)
+
+const (
+	// SignatureHeader carries "sha256=<hex HMAC of the request body>"
+	SignatureHeader = "X-NetSpec-Signature"
+	// IdempotencyHeader carries the notification's idempotency key
+	IdempotencyHeader = "Idempotency-Key"
+)
+
+// WebhookPayload is the JSON body posted to webhook channels
+type WebhookPayload struct {
+	IdempotencyKey string       `json:"idempotency_key"`
+	SentAt         time.Time    `json:"sent_at"`
+	Alert          WebhookAlert `json:"alert"`
+}
+
+// WebhookAlert is the alert as exposed to webhook receivers
+type WebhookAlert struct {
+	ID           string            `json:"id"`
+	DedupKey     string            `json:"dedup_key"`
+	Device       string            `json:"device"`
+	Entity       string            `json:"entity"`
+	AlertType    string            `json:"alert_type"`
+	Severity     string            `json:"severity"`
+	State        string            `json:"state"`
+	Message      string            `json:"message"`
+	FiredAt      time.Time         `json:"fired_at"`
+	ResolvedAt   *time.Time        `json:"resolved_at,omitempty"`
+	RelatedState map[string]string `json:"related_state,omitempty"`
+	Asset        map[string]string `json:"asset,omitempty"`
+	RunbookURL   string            `json:"runbook_url,omitempty"`
+}
+
+// WebhookTemplateData is what a webhook channel's template is rendered
+// against: the alert, with the notification's idempotency key and send time
+type WebhookTemplateData struct {
+	*types.Alert
+	IdempotencyKey string
+	SentAt         time.Time
+}
+
+// webhookJob is a webhook notification being retried, or waiting behind one
+type webhookJob struct {
+	channel  Channel
+	alert    types.Alert
+	attempts int
+	err      error // of the last attempt
+}
+
+// statusError is a webhook receiver's error response
+type statusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // as asked by a Retry-After header
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("webhook error: %d - %s", e.StatusCode, e.Body)
+}
+
+// sendWebhook posts the alert, as the default JSON payload or rendered from
+// the channel's template. When the channel has a secret the body is signed
+// with HMAC-SHA256 so receivers can verify it came from NetSpec.
+func (n *Notifier) sendWebhook(channel Channel, alert *types.Alert) error {
+	key := IdempotencyKey(alert)
+	sentAt := time.Now().UTC()
+	body, err := webhookBody(channel, alert, key, sentAt)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range channel.Headers {
+		req.Header.Set(name, value)
+	}
+	contentType := "application/json"
+	if channel.Webhook != nil && channel.Webhook.ContentType != "" {
+		contentType = channel.Webhook.ContentType
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(IdempotencyHeader, key)
+	if channel.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(channel.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &statusError{
+			StatusCode: resp.StatusCode,
+			Body:       string(respBody),
+			RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return nil
+}
+
+// webhookBody returns the request body of a webhook notification
+func webhookBody(channel Channel, alert *types.Alert, key string, sentAt time.Time) ([]byte, error) {
+	if channel.Webhook != nil && channel.Webhook.Template != "" {
+		tmpl, err := channel.Webhook.ParseTemplate(channel.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, WebhookTemplateData{Alert: alert, IdempotencyKey: key, SentAt: sentAt}); err != nil {
+			return nil, fmt.Errorf("failed to render webhook template: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	payload := WebhookPayload{
+		IdempotencyKey: key,
+		SentAt:         sentAt,
+		Alert: WebhookAlert{
+			ID:           alert.ID,
+			DedupKey:     alert.DedupKey,
+			Device:       alert.Device,
+			Entity:       alert.Entity,
+			AlertType:    alert.AlertType,
+			Severity:     alert.Severity,
+			State:        alert.State,
+			Message:      alert.Message,
+			FiredAt:      alert.FiredAt,
+			ResolvedAt:   alert.ResolvedAt,
+			RelatedState: alert.RelatedState,
+			Asset:        alert.Asset,
+			RunbookURL:   alert.RunbookURL,
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return body, nil
+}
+
+// deliverWebhook sends a webhook notification, reporting whether it was
+// left to the retry worker: after a failed attempt on a channel with
+// retry, or behind a notification of the channel still being retried, so
+// a resolution never overtakes the alert it resolves. Alerts are sent with
+// the alert engine's lock held, so the backoff happens in the background.
+func (n *Notifier) deliverWebhook(channel Channel, alert *types.Alert) (bool, error) {
+	if channel.Webhook == nil || channel.Webhook.Retry == nil {
+		return false, n.sendWebhook(channel, alert)
+	}
+	n.webhookMu.Lock()
+	if queue, ok := n.webhookRetries[channel.Name]; ok {
+		n.webhookRetries[channel.Name] = append(queue, &webhookJob{channel: channel, alert: *alert})
+		n.webhookMu.Unlock()
+		return true, nil
+	}
+	n.webhookMu.Unlock()
+
+	err := n.sendWebhook(channel, alert)
+	if err == nil || !retryable(err) || channel.Webhook.Retry.WithDefaults().Attempts <= 1 {
+		return false, err
+	}
+	job := &webhookJob{channel: channel, alert: *alert, attempts: 1, err: err}
+	n.logger.Warn().
+		Err(err).
+		Str("channel", channel.Name).
+		Str("alert_id", alert.ID).
+		Msg("Failed to send notification, retrying")
+	n.webhookMu.Lock()
+	defer n.webhookMu.Unlock()
+	queue, running := n.webhookRetries[channel.Name]
+	n.webhookRetries[channel.Name] = append(queue, job)
+	if !running {
+		go n.runWebhookRetries(channel.Name)
+	}
+	return true, nil
+}
+
+// runWebhookRetries works through a webhook channel's waiting notifications
+// in order, retrying each with backoff until sent, failed with an error a
+// retry would not fix, or out of attempts
+func (n *Notifier) runWebhookRetries(name string) {
+	for {
+		n.webhookMu.Lock()
+		queue := n.webhookRetries[name]
+		if len(queue) == 0 {
+			delete(n.webhookRetries, name)
+			n.webhookMu.Unlock()
+			return
+		}
+		job := queue[0]
+		n.webhookMu.Unlock()
+
+		retry := job.channel.Webhook.Retry.WithDefaults()
+		if job.attempts > 0 {
+			delay := retry.Delay(job.attempts)
+			var status *statusError
+			if errors.As(job.err, &status) && status.RetryAfter > delay {
+				delay = min(status.RetryAfter, retry.MaxBackoff)
+			}
+			time.Sleep(delay)
+		}
+		job.err = n.sendWebhook(job.channel, &job.alert)
+		job.attempts++
+
+		if job.err != nil && retryable(job.err) && job.attempts < retry.Attempts {
+			n.logger.Warn().
+				Err(job.err).
+				Str("channel", name).
+				Str("alert_id", job.alert.ID).
+				Int("attempt", job.attempts).
+				Msg("Failed to send notification, retrying")
+			continue
+		}
+		if job.err != nil {
+			n.logger.Error().
+				Err(job.err).
+				Str("channel", name).
+				Str("alert_id", job.alert.ID).
+				Int("attempts", job.attempts).
+				Msg("Failed to send notification")
+		} else {
+			n.logger.Info().
+				Str("channel", name).
+				Str("alert_id", job.alert.ID).
+				Int("attempts", job.attempts).
+				Msg("Notification sent")
+		}
+		n.webhookMu.Lock()
+		n.webhookRetries[name] = n.webhookRetries[name][1:]
+		n.webhookMu.Unlock()
+	}
+}
+
+// retryable reports whether a failed webhook notification may succeed when
+// sent again: the receiver was unreachable, overloaded or failing
+func retryable(err error) bool {
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= 500 || status.StatusCode == http.StatusTooManyRequests
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// Sign returns the X-NetSpec-Signature value for a request body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IdempotencyKey identifies a single notification. It is stable across
+// retries of the same notification, but differs between firing, resolving
+// and escalating the same alert.
+func IdempotencyKey(alert *types.Alert) string {
+	sum := sha256.Sum256([]byte(alert.ID + "\x00" + alert.State + "\x00" + alert.Message))
+	return hex.EncodeToString(sum[:16])
+}
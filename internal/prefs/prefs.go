@@ -0,0 +1,155 @@
+// Package prefs keeps each web UI user's preferences on the server, so a
+// shared NOC workstation and a personal laptop show the same user the same
+// dashboard.
+package prefs
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/netspec/netspec/internal/store"
+)
+
+// storeSection is the state file section preferences persist to
+const storeSection = "preferences"
+
+// DefaultUser holds the preferences of requests without a user identity,
+// shared by everyone when no user_header is configured
+const DefaultUser = "default"
+
+// Preferences are one user's web UI settings
+type Preferences struct {
+	Theme           string   `json:"theme,omitempty"`       // "dark" (default) or "light"
+	SiteFilter      string   `json:"site_filter,omitempty"` // dashboard shows only this site's devices and alerts
+	AlertSound      bool     `json:"alert_sound"`           // chime when new alerts fire while the dashboard is open
+	FavoriteDevices []string `json:"favorite_devices"`      // listed first on the dashboard
+	Watching        []Watch  `json:"watching"`              // pinned to the dashboard's Watching card
+}
+
+// Watch pins a device, or one of its interfaces, to the Watching card
+type Watch struct {
+	Device    string `json:"device"`
+	Interface string `json:"interface,omitempty"` // the whole device when empty
+}
+
+// Validate checks the theme and watches and normalizes the favorites.
+// Watches keep the order they were pinned in, duplicates dropped.
+func (p *Preferences) Validate() error {
+	if p.Theme != "" && p.Theme != "dark" && p.Theme != "light" {
+		return fmt.Errorf("theme must be 'dark' or 'light'")
+	}
+	p.FavoriteDevices = uniqueSorted(p.FavoriteDevices)
+	watching := make([]Watch, 0, len(p.Watching))
+	for _, w := range p.Watching {
+		if w.Device == "" {
+			return fmt.Errorf("watching: device is required")
+		}
+		if !containsWatch(watching, w) {
+			watching = append(watching, w)
+		}
+	}
+	p.Watching = watching
+	return nil
+}
+
+// IsFavorite reports whether a device is one of the user's favorites
+func (p Preferences) IsFavorite(device string) bool {
+	for _, d := range p.FavoriteDevices {
+		if d == device {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWatching reports whether a device, or with iface one of its
+// interfaces, is pinned to the user's Watching card
+func (p Preferences) IsWatching(device, iface string) bool {
+	return containsWatch(p.Watching, Watch{Device: device, Interface: iface})
+}
+
+// Store holds every user's preferences
+type Store struct {
+	mu    sync.RWMutex
+	users map[string]Preferences
+	state *store.Store
+}
+
+// NewStore creates an empty preference store
+func NewStore() *Store {
+	return &Store{users: make(map[string]Preferences)}
+}
+
+// SetStore restores preferences from st and persists every change there
+// from now on
+func (s *Store) SetStore(st *store.Store) error {
+	var users map[string]Preferences
+	if _, err := st.Load(storeSection, &users); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = st
+	for user, p := range users {
+		if _, ok := s.users[user]; !ok {
+			s.users[user] = p
+		}
+	}
+	return nil
+}
+
+// Get returns a user's preferences, the zero value for a new user
+func (s *Store) Get(user string) Preferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p := s.users[user]
+	p.FavoriteDevices = append([]string{}, p.FavoriteDevices...)
+	p.Watching = append([]Watch{}, p.Watching...)
+	return p
+}
+
+// Set validates and replaces a user's preferences, persisting them when a
+// state store is set
+func (s *Store) Set(user string, p Preferences) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.users[user] = p
+	st := s.state
+	var snapshot map[string]Preferences
+	if st != nil {
+		snapshot = make(map[string]Preferences, len(s.users))
+		for u, up := range s.users {
+			snapshot[u] = up
+		}
+	}
+	s.mu.Unlock()
+	if st != nil {
+		return st.Save(storeSection, snapshot)
+	}
+	return nil
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func containsWatch(watching []Watch, w Watch) bool {
+	for _, existing := range watching {
+		if existing == w {
+			return true
+		}
+	}
+	return false
+}
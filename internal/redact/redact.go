@@ -0,0 +1,256 @@
+// Package redact removes secrets from text bound for logs and API
+// responses: values registered as secrets, such as passwords and tokens
+// resolved from the environment, and anything shaped like a credential
+// (URL passwords, Apprise notification URLs, authorization headers and
+// secret query parameters) whether or not it was registered.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every redacted value
+const Mask = "[REDACTED]"
+
+// minSecretLength is the shortest registered value redacted verbatim;
+// shorter ones would mask ordinary words
+const minSecretLength = 6
+
+// maxPendingLine bounds the text a Writer holds back waiting for the end
+// of a line
+const maxPendingLine = 64 * 1024
+
+// credentialSchemes are the Apprise notification URL schemes, such as
+// slack://T0/B0/XXXX, that carry their tokens in the URL itself and are
+// masked after the scheme. URLs of any other scheme are left alone apart
+// from their userinfo and query.
+var credentialSchemes = map[string]bool{
+	"apprise": true, "apprises": true, "bark": true, "barks": true,
+	"bluesky": true, "boxcar": true, "clicksend": true, "dapnet": true,
+	"dingtalk": true, "discord": true, "emby": true, "embys": true,
+	"fcm": true, "feishu": true, "flock": true, "gchat": true,
+	"gotify": true, "gotifys": true, "growl": true, "guilded": true,
+	"hassio": true, "hassios": true, "ifttt": true, "join": true,
+	"kumulos": true, "lametric": true, "line": true, "mailgun": true,
+	"mailto": true, "mailtos": true, "mastodon": true, "matrix": true,
+	"matrixs": true, "misskey": true, "mmost": true, "mmosts": true,
+	"msg91": true, "msteams": true, "nctalk": true, "nctalks": true,
+	"ncloud": true, "nclouds": true, "ntfy": true, "ntfys": true,
+	"o365": true, "onesignal": true, "opsgenie": true, "pagerduty": true,
+	"pagertree": true, "pbul": true, "pjet": true, "pjets": true,
+	"popcorn": true, "pover": true, "prowl": true, "psafer": true,
+	"psafers": true, "pushed": true, "pushme": true, "pushy": true,
+	"reddit": true, "rocket": true, "rockets": true, "ryver": true,
+	"sendgrid": true, "ses": true, "signal": true, "signals": true,
+	"simplepush": true, "sinch": true, "slack": true, "smseagle": true,
+	"smseagles": true, "sns": true, "spush": true, "strmlabs": true,
+	"tgram": true, "threema": true, "toot": true,
+	"toots": true, "twilio": true, "twist": true, "twitter": true,
+	"whatsapp": true, "wxpusher": true, "zulip": true,
+}
+
+var (
+	userinfoPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@"'<>]*):[^/\s@"'<>]+@`)
+	schemePattern   = regexp.MustCompile(`\b([a-zA-Z][a-zA-Z0-9+.-]*)://[^\s"'<>\\]+`)
+	queryPattern    = regexp.MustCompile(`(?i)([?&](?:token|access_token|api_key|apikey|key|secret|password|passwd|pwd|sig|signature|auth)=)[^&\s"'<>#\\]+`)
+	bearerPattern   = regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/=-]{8,}`)
+	headerPattern   = regexp.MustCompile(`(?i)(authorization\\?"?\s*[:=]\s*\\?"?(?:basic\s+|bearer\s+)?)[^"\s,}\\]+`)
+)
+
+// Redactor masks registered secrets and credential-shaped text
+type Redactor struct {
+	mu      sync.RWMutex
+	secrets []string // longest first, so a secret containing another is masked whole
+	known   map[string]bool
+}
+
+// Default is the redactor applied to the log output and API responses
+var Default = New()
+
+// New creates a redactor with no registered secrets
+func New() *Redactor {
+	return &Redactor{known: make(map[string]bool)}
+}
+
+// Register adds secret values to mask wherever they appear, also in their
+// JSON and HTML escaped forms. Secrets stay registered for the life of the
+// process, so a rotated-out password is still masked.
+func (r *Redactor) Register(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	added := false
+	for _, secret := range secrets {
+		secret = strings.TrimSpace(secret)
+		if len(secret) < minSecretLength {
+			continue
+		}
+		quoted, _ := json.Marshal(secret)
+		for _, form := range []string{secret, string(quoted[1 : len(quoted)-1]), html.EscapeString(secret)} {
+			if !r.known[form] {
+				r.known[form] = true
+				r.secrets = append(r.secrets, form)
+				added = true
+			}
+		}
+	}
+	if added {
+		sort.Slice(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+	}
+}
+
+// String returns s with registered secrets and credential-shaped text
+// replaced by Mask
+func (r *Redactor) String(s string) string {
+	r.mu.RLock()
+	for _, secret := range r.secrets {
+		if strings.Contains(s, secret) {
+			s = strings.ReplaceAll(s, secret, Mask)
+		}
+	}
+	r.mu.RUnlock()
+
+	if strings.Contains(s, "://") {
+		s = userinfoPattern.ReplaceAllString(s, "${1}:"+Mask+"@")
+		s = maskSchemes(s)
+	}
+	if strings.Contains(s, "=") {
+		s = queryPattern.ReplaceAllString(s, "${1}"+Mask)
+	}
+	lower := strings.ToLower(s)
+	if strings.Contains(lower, "bearer") {
+		s = bearerPattern.ReplaceAllString(s, "${1}"+Mask)
+	}
+	if strings.Contains(lower, "authorization") {
+		s = headerPattern.ReplaceAllString(s, "${1}"+Mask)
+	}
+	return s
+}
+
+// maskSchemes masks everything after the scheme of Apprise URLs,
+// including ones nested in a web URL as in Apprise API requests
+// (http://apprise:8000/notify/slack://T0/B0/XXXX)
+func maskSchemes(s string) string {
+	return schemePattern.ReplaceAllStringFunc(s, func(url string) string {
+		scheme, rest, _ := strings.Cut(url, "://")
+		if rest == Mask {
+			return url
+		}
+		if credentialSchemes[strings.ToLower(scheme)] {
+			return scheme + "://" + Mask
+		}
+		return scheme + "://" + maskSchemes(rest)
+	})
+}
+
+// Writer returns a writer passing everything written through String
+// before writing it to w. Text is redacted a line at a time, so a secret
+// split across writes is still masked; a line is held back until its end
+// is written, or until maxPendingLine is reached.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return &writer{r: r, w: w}
+}
+
+type writer struct {
+	r       *Redactor
+	w       io.Writer
+	mu      sync.Mutex
+	pending []byte // the start of a line not yet ended
+}
+
+// Write redacts and writes the lines p completes, and reports p written in
+// full
+func (w *writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, p...)
+	end := bytes.LastIndexByte(w.pending, '\n') + 1
+	if end == 0 {
+		if len(w.pending) < maxPendingLine {
+			return len(p), nil
+		}
+		end = len(w.pending)
+	}
+	out := w.r.String(string(w.pending[:end]))
+	w.pending = append(w.pending[:0], w.pending[end:]...)
+	if _, err := io.WriteString(w.w, out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Handler redacts the text responses of h: JSON, HTML, YAML and plain
+// text. A text response is buffered whole and redacted once h returns, so
+// a secret split across writes is still masked. Other content, such as
+// images, is passed through unchanged.
+func (r *Redactor) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rw := &responseWriter{ResponseWriter: w, r: r}
+		h.ServeHTTP(rw, req)
+		rw.finish()
+	})
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	r       *Redactor
+	decided bool // whether the response is text, set at the first write
+	text    bool
+	body    bytes.Buffer // text written so far
+}
+
+// WriteHeader drops a Content-Length set for a text response, which
+// redaction may change
+func (w *responseWriter) WriteHeader(code int) {
+	if textual(w.Header().Get("Content-Type")) {
+		w.Header().Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write buffers p when the response is text and passes it through
+// otherwise
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.text = textual(w.Header().Get("Content-Type"))
+		if w.text {
+			w.Header().Del("Content-Length")
+		}
+	}
+	if !w.text {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.body.Write(p)
+}
+
+// finish writes the redacted text response
+func (w *responseWriter) finish() {
+	if w.body.Len() > 0 {
+		io.WriteString(w.ResponseWriter, w.r.String(w.body.String()))
+	}
+}
+
+// textual reports whether a content type is redacted; an unset one is
+// treated as text, as every binary response here declares its type
+func textual(contentType string) bool {
+	return contentType == "" || strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "json") || strings.Contains(contentType, "yaml")
+}
+
+// Register adds secrets to the Default redactor
+func Register(secrets ...string) {
+	Default.Register(secrets...)
+}
+
+// String redacts s with the Default redactor
+func String(s string) string {
+	return Default.String(s)
+}
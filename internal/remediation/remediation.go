@@ -0,0 +1,316 @@
+// Package remediation runs the fixes configured for alert types when their
+// alerts fire: a webhook, a script or a gNMI port bounce. Attempts are
+// limited per alert by max_attempts and cooldown, can be dry runs, and
+// every attempt, run or skipped, is audit logged.
+package remediation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netspec/netspec/internal/config"
+	"github.com/netspec/netspec/internal/types"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// attemptWindow is the period max_attempts counts attempts over
+	attemptWindow = 24 * time.Hour
+	// maxRecent is how many attempts are kept for the API
+	maxRecent = 200
+	// maxOutput bounds the script output kept with an attempt
+	maxOutput = 512
+)
+
+// Attempt results
+const (
+	ResultOK      = "ok"
+	ResultFailed  = "failed"
+	ResultDryRun  = "dry_run"
+	ResultSkipped = "skipped"
+)
+
+// PortBouncer disables an interface and re-enables it after downFor
+type PortBouncer interface {
+	BouncePort(ctx context.Context, ifaceName string, downFor time.Duration) error
+}
+
+// BouncerFunc returns the port bouncer of a device, or nil when it has no
+// collector
+type BouncerFunc func(device string) PortBouncer
+
+// Attempt is one remediation attempt, run or skipped
+type Attempt struct {
+	At        time.Time `json:"at"`
+	AlertID   string    `json:"alert_id"`
+	DedupKey  string    `json:"dedup_key"`
+	Device    string    `json:"device"`
+	Entity    string    `json:"entity"`
+	AlertType string    `json:"alert_type"`
+	Action    string    `json:"action"`
+	Attempt   int       `json:"attempt"` // within the attempt window; 0 when skipped
+	Result    string    `json:"result"`  // ok, failed, dry_run or skipped
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// Remediator runs remediation actions for firing alerts
+type Remediator struct {
+	mu       sync.Mutex
+	cfg      config.RemediationConfig
+	logger   zerolog.Logger
+	bouncer  BouncerFunc
+	onResult func(Attempt)
+	attempts map[string][]time.Time // dedup key -> attempt start times, oldest first
+	recent   []Attempt              // oldest first
+	client   *http.Client
+}
+
+// New creates a remediator for the given configuration
+func New(cfg config.RemediationConfig, logger zerolog.Logger) *Remediator {
+	return &Remediator{
+		cfg:      cfg,
+		logger:   logger,
+		attempts: make(map[string][]time.Time),
+		client:   &http.Client{},
+	}
+}
+
+// SetConfig replaces the configuration, e.g. after a reload. Attempt
+// counts are kept.
+func (r *Remediator) SetConfig(cfg config.RemediationConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cfg = cfg
+}
+
+// SetPortBouncer registers the source of device port bouncers for
+// bounce_port actions
+func (r *Remediator) SetPortBouncer(fn BouncerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bouncer = fn
+}
+
+// SetResultHook registers a function receiving every attempt once it
+// completes or is skipped
+func (r *Remediator) SetResultHook(fn func(Attempt)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onResult = fn
+}
+
+// Config returns the current configuration
+func (r *Remediator) Config() config.RemediationConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cfg
+}
+
+// Recent returns the latest attempts, newest first
+func (r *Remediator) Recent() []Attempt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recent := make([]Attempt, len(r.recent))
+	for i, a := range r.recent {
+		recent[len(r.recent)-1-i] = a
+	}
+	return recent
+}
+
+// Handle is an alert lifecycle hook: when an alert fires and its type has
+// an action, the action runs in the background unless the alert is out of
+// attempts or in its cooldown. It never blocks.
+func (r *Remediator) Handle(event string, alert types.Alert) {
+	if event != "fired" {
+		return
+	}
+	r.mu.Lock()
+	action, ok := r.cfg.Actions[alert.AlertType]
+	if !r.cfg.Enabled || !ok {
+		r.mu.Unlock()
+		return
+	}
+	dryRun := r.cfg.DryRun || action.DryRun
+	timeout, maxAttempts, cooldown, downFor := action.Limits()
+
+	now := time.Now()
+	attempt := Attempt{
+		At:        now,
+		AlertID:   alert.ID,
+		DedupKey:  alert.DedupKey,
+		Device:    alert.Device,
+		Entity:    alert.Entity,
+		AlertType: alert.AlertType,
+		Action:    action.Type,
+	}
+	times := r.attempts[alert.DedupKey]
+	for len(times) > 0 && now.Sub(times[0]) >= attemptWindow {
+		times = times[1:]
+	}
+	switch {
+	case len(times) >= maxAttempts:
+		attempt.Result = ResultSkipped
+		attempt.Detail = fmt.Sprintf("%d attempts in the last %s", len(times), attemptWindow)
+	case len(times) > 0 && now.Sub(times[len(times)-1]) < cooldown:
+		attempt.Result = ResultSkipped
+		attempt.Detail = fmt.Sprintf("in cooldown until %s", times[len(times)-1].Add(cooldown).Format(time.RFC3339))
+	default:
+		times = append(times, now)
+		attempt.Attempt = len(times)
+	}
+	if len(times) == 0 {
+		delete(r.attempts, alert.DedupKey)
+	} else {
+		r.attempts[alert.DedupKey] = times
+	}
+	bouncer := r.bouncer
+	r.mu.Unlock()
+
+	// Handle runs inside the alert engine's lock, which the result hook
+	// may need, so even skipped attempts finish in the background
+	if attempt.Result == ResultSkipped {
+		go r.finish(attempt)
+		return
+	}
+	if dryRun {
+		attempt.Result = ResultDryRun
+		attempt.Detail = "would run " + describe(action)
+		go r.finish(attempt)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		detail, err := r.run(ctx, action, alert, bouncer, downFor)
+		attempt.Result, attempt.Detail = ResultOK, detail
+		if err != nil {
+			attempt.Result, attempt.Detail = ResultFailed, err.Error()
+		}
+		r.finish(attempt)
+	}()
+}
+
+// run performs one action
+func (r *Remediator) run(ctx context.Context, action config.RemediationAction, alert types.Alert, bouncer BouncerFunc, downFor time.Duration) (string, error) {
+	switch action.Type {
+	case config.RemediationWebhook:
+		url := os.Getenv(action.URLEnv)
+		if url == "" {
+			return "", fmt.Errorf("%s is not set", action.URLEnv)
+		}
+		body, err := json.Marshal(map[string]interface{}{
+			"event": "remediation",
+			"alert": alert,
+		})
+		if err != nil {
+			return "", err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return "", fmt.Errorf("webhook returned %s", resp.Status)
+		}
+		return "webhook returned " + resp.Status, nil
+
+	case config.RemediationScript:
+		input, err := json.Marshal(alert)
+		if err != nil {
+			return "", err
+		}
+		cmd := exec.CommandContext(ctx, action.Command[0], action.Command[1:]...)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Env = append(os.Environ(),
+			"NETSPEC_DEVICE="+alert.Device,
+			"NETSPEC_ENTITY="+alert.Entity,
+			"NETSPEC_ALERT_TYPE="+alert.AlertType,
+			"NETSPEC_SEVERITY="+alert.Severity,
+			"NETSPEC_DEDUP_KEY="+alert.DedupKey,
+		)
+		output, err := cmd.CombinedOutput()
+		detail := strings.TrimSpace(string(output))
+		if len(detail) > maxOutput {
+			detail = detail[:maxOutput] + "..."
+		}
+		if err != nil {
+			if detail != "" {
+				return "", fmt.Errorf("%w: %s", err, detail)
+			}
+			return "", err
+		}
+		return detail, nil
+
+	case config.RemediationBouncePort:
+		if alert.Entity == "" || alert.Entity == "device" {
+			return "", fmt.Errorf("alert is not on an interface")
+		}
+		var b PortBouncer
+		if bouncer != nil {
+			b = bouncer(alert.Device)
+		}
+		if b == nil {
+			return "", fmt.Errorf("no collector for %s", alert.Device)
+		}
+		if err := b.BouncePort(ctx, alert.Entity, downFor); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("bounced %s (down %s)", alert.Entity, downFor), nil
+	}
+	return "", fmt.Errorf("unknown action type %q", action.Type)
+}
+
+// finish audit logs an attempt, keeps it for the API and reports it
+func (r *Remediator) finish(attempt Attempt) {
+	r.logger.Info().
+		Str("component", "audit").
+		Str("action", "remediation").
+		Str("device", attempt.Device).
+		Str("entity", attempt.Entity).
+		Str("alert_type", attempt.AlertType).
+		Str("remediation", attempt.Action).
+		Int("attempt", attempt.Attempt).
+		Str("result", attempt.Result).
+		Str("detail", attempt.Detail).
+		Msg("Remediation " + attempt.Result)
+
+	r.mu.Lock()
+	r.recent = append(r.recent, attempt)
+	if len(r.recent) > maxRecent {
+		r.recent = r.recent[len(r.recent)-maxRecent:]
+	}
+	onResult := r.onResult
+	r.mu.Unlock()
+	if onResult != nil {
+		onResult(attempt)
+	}
+}
+
+// describe summarizes an action for dry-run logs
+func describe(action config.RemediationAction) string {
+	switch action.Type {
+	case config.RemediationWebhook:
+		return "webhook to $" + action.URLEnv
+	case config.RemediationScript:
+		return "script " + strings.Join(action.Command, " ")
+	case config.RemediationBouncePort:
+		_, _, _, downFor := action.Limits()
+		return fmt.Sprintf("bounce_port (down %s)", downFor)
+	}
+	return action.Type
+}
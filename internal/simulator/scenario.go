@@ -0,0 +1,185 @@
+package simulator
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario actions
+const (
+	ActionDown      = "down"
+	ActionUp        = "up"
+	ActionAdminDown = "admin_down"
+	ActionAdminUp   = "admin_up"
+	ActionFlap      = "flap"
+)
+
+const (
+	defaultSampleInterval = 10 * time.Second
+	defaultFlapCount      = 3
+	defaultFlapPeriod     = 5 * time.Second
+)
+
+// Scenario describes the interfaces a simulated target exposes and a
+// timeline of state changes relative to the moment the target starts
+type Scenario struct {
+	SampleInterval time.Duration   `yaml:"sample_interval,omitempty"` // used when the client does not request one
+	Interfaces     []InterfaceSpec `yaml:"interfaces"`
+	Events         []Event         `yaml:"events,omitempty"`
+	Repeat         time.Duration   `yaml:"repeat,omitempty"` // restart the timeline from the initial state after this long
+}
+
+// InterfaceSpec is the initial state of one simulated interface
+type InterfaceSpec struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Oper        string `yaml:"oper,omitempty"`  // default UP
+	Admin       string `yaml:"admin,omitempty"` // default UP
+}
+
+// Event is one scripted change. Flap takes the interface down and back up
+// Count times, spending Period in each state.
+type Event struct {
+	At        time.Duration `yaml:"at"`
+	Interface string        `yaml:"interface"`
+	Action    string        `yaml:"action"` // down, up, admin_down, admin_up, flap
+	Count     int           `yaml:"count,omitempty"`
+	Period    time.Duration `yaml:"period,omitempty"`
+}
+
+// step is a single leaf change on the expanded timeline
+type step struct {
+	at        time.Duration
+	iface     string
+	leaf      string
+	value     string
+	operValue string // set alongside an admin change, empty otherwise
+}
+
+// LoadScenario reads and validates a scenario file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	var s Scenario
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DefaultScenario builds a demo scenario for the given interfaces: all start
+// up, the first goes down at T+30s and recovers at T+90s, the last flaps three
+// times from T+120s, and the timeline repeats every 180s
+func DefaultScenario(interfaces []string) *Scenario {
+	s := &Scenario{Repeat: 180 * time.Second}
+	for _, name := range interfaces {
+		s.Interfaces = append(s.Interfaces, InterfaceSpec{Name: name})
+	}
+	if len(interfaces) > 0 {
+		first, last := interfaces[0], interfaces[len(interfaces)-1]
+		s.Events = []Event{
+			{At: 30 * time.Second, Interface: first, Action: ActionDown},
+			{At: 90 * time.Second, Interface: first, Action: ActionUp},
+			{At: 120 * time.Second, Interface: last, Action: ActionFlap},
+		}
+	}
+	return s
+}
+
+// Validate checks the scenario and fills in defaults
+func (s *Scenario) Validate() error {
+	if len(s.Interfaces) == 0 {
+		return fmt.Errorf("scenario declares no interfaces")
+	}
+	if s.SampleInterval <= 0 {
+		s.SampleInterval = defaultSampleInterval
+	}
+
+	known := make(map[string]bool, len(s.Interfaces))
+	for i := range s.Interfaces {
+		iface := &s.Interfaces[i]
+		if iface.Name == "" {
+			return fmt.Errorf("interfaces[%d]: name is required", i)
+		}
+		if known[iface.Name] {
+			return fmt.Errorf("interface %s declared twice", iface.Name)
+		}
+		known[iface.Name] = true
+		if iface.Oper == "" {
+			iface.Oper = "UP"
+		}
+		if iface.Admin == "" {
+			iface.Admin = "UP"
+		}
+		iface.Oper = strings.ToUpper(iface.Oper)
+		iface.Admin = strings.ToUpper(iface.Admin)
+	}
+
+	for i := range s.Events {
+		ev := &s.Events[i]
+		if !known[ev.Interface] {
+			return fmt.Errorf("events[%d]: unknown interface %q", i, ev.Interface)
+		}
+		if ev.At < 0 {
+			return fmt.Errorf("events[%d]: at must not be negative", i)
+		}
+		switch ev.Action {
+		case ActionDown, ActionUp, ActionAdminDown, ActionAdminUp:
+		case ActionFlap:
+			if ev.Count <= 0 {
+				ev.Count = defaultFlapCount
+			}
+			if ev.Period <= 0 {
+				ev.Period = defaultFlapPeriod
+			}
+		default:
+			return fmt.Errorf("events[%d]: unknown action %q (expected down, up, admin_down, admin_up or flap)", i, ev.Action)
+		}
+	}
+
+	if s.Repeat > 0 {
+		if end := s.timeline(); len(end) > 0 && end[len(end)-1].at >= s.Repeat {
+			return fmt.Errorf("repeat %s must be longer than the last event", s.Repeat)
+		}
+	}
+	return nil
+}
+
+// timeline expands events into individual leaf changes ordered by time
+func (s *Scenario) timeline() []step {
+	var steps []step
+	for _, ev := range s.Events {
+		switch ev.Action {
+		case ActionDown:
+			steps = append(steps, step{at: ev.At, iface: ev.Interface, leaf: "oper-status", value: "DOWN"})
+		case ActionUp:
+			steps = append(steps, step{at: ev.At, iface: ev.Interface, leaf: "oper-status", value: "UP"})
+		case ActionAdminDown:
+			steps = append(steps, step{at: ev.At, iface: ev.Interface, leaf: "admin-status", value: "DOWN", operValue: "DOWN"})
+		case ActionAdminUp:
+			steps = append(steps, step{at: ev.At, iface: ev.Interface, leaf: "admin-status", value: "UP", operValue: "UP"})
+		case ActionFlap:
+			for n := 0; n < ev.Count; n++ {
+				down := ev.At + time.Duration(2*n)*ev.Period
+				steps = append(steps,
+					step{at: down, iface: ev.Interface, leaf: "oper-status", value: "DOWN"},
+					step{at: down + ev.Period, iface: ev.Interface, leaf: "oper-status", value: "UP"},
+				)
+			}
+		}
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].at < steps[j].at
+	})
+	return steps
+}
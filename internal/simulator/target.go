@@ -0,0 +1,334 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Target is a fake gNMI target serving the interface state of a scenario.
+// It answers Capabilities, Get and Subscribe (STREAM, ONCE and POLL); STREAM
+// subscriptions receive a full sample every interval plus every scripted
+// change as it happens.
+type Target struct {
+	gnmi.UnimplementedGNMIServer
+
+	scenario *Scenario
+	logger   zerolog.Logger
+
+	mu          sync.RWMutex
+	state       map[string]InterfaceSpec
+	subscribers map[chan *gnmi.Notification]struct{}
+}
+
+// NewTarget creates a target in the scenario's initial state. The scenario
+// must already be validated.
+func NewTarget(scenario *Scenario, logger zerolog.Logger) *Target {
+	t := &Target{
+		scenario:    scenario,
+		logger:      logger.With().Str("component", "simulator").Logger(),
+		state:       make(map[string]InterfaceSpec, len(scenario.Interfaces)),
+		subscribers: make(map[chan *gnmi.Notification]struct{}),
+	}
+	for _, iface := range scenario.Interfaces {
+		t.state[iface.Name] = iface
+	}
+	return t
+}
+
+// Serve listens on addr and serves gNMI while playing the scenario timeline
+// until ctx is cancelled
+func (t *Target) Serve(ctx context.Context, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	server := grpc.NewServer()
+	gnmi.RegisterGNMIServer(server, t)
+
+	go t.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		server.Stop()
+	}()
+
+	t.logger.Info().
+		Str("address", lis.Addr().String()).
+		Int("interfaces", len(t.scenario.Interfaces)).
+		Int("events", len(t.scenario.Events)).
+		Msg("Simulated gNMI target listening")
+
+	if err := server.Serve(lis); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// Run plays the scenario timeline until ctx is cancelled, restarting it from
+// the initial state every Repeat when set
+func (t *Target) Run(ctx context.Context) {
+	steps := t.scenario.timeline()
+	for {
+		start := time.Now()
+		for _, st := range steps {
+			if !sleepUntil(ctx, start.Add(st.at)) {
+				return
+			}
+			t.apply(st)
+		}
+		if t.scenario.Repeat <= 0 {
+			<-ctx.Done()
+			return
+		}
+		if !sleepUntil(ctx, start.Add(t.scenario.Repeat)) {
+			return
+		}
+		t.logger.Info().Msg("Restarting scenario")
+		t.reset()
+	}
+}
+
+// apply performs one timeline step and pushes it to streaming subscribers
+func (t *Target) apply(st step) {
+	t.mu.Lock()
+	iface := t.state[st.iface]
+	var updates []*gnmi.Update
+	switch st.leaf {
+	case "oper-status":
+		iface.Oper = st.value
+		updates = append(updates, leafUpdate(st.iface, "oper-status", st.value))
+	case "admin-status":
+		iface.Admin = st.value
+		updates = append(updates, leafUpdate(st.iface, "admin-status", st.value))
+		if st.operValue != "" {
+			iface.Oper = st.operValue
+			updates = append(updates, leafUpdate(st.iface, "oper-status", st.operValue))
+		}
+	}
+	t.state[st.iface] = iface
+	t.mu.Unlock()
+
+	t.logger.Info().
+		Str("interface", st.iface).
+		Str("oper", iface.Oper).
+		Str("admin", iface.Admin).
+		Msg("Interface state changed")
+	t.broadcast(&gnmi.Notification{Timestamp: time.Now().UnixNano(), Update: updates})
+}
+
+// reset restores the initial state, pushing only the leaves that differ
+func (t *Target) reset() {
+	t.mu.Lock()
+	var updates []*gnmi.Update
+	for _, initial := range t.scenario.Interfaces {
+		current := t.state[initial.Name]
+		if current.Admin != initial.Admin {
+			updates = append(updates, leafUpdate(initial.Name, "admin-status", initial.Admin))
+		}
+		if current.Oper != initial.Oper {
+			updates = append(updates, leafUpdate(initial.Name, "oper-status", initial.Oper))
+		}
+		t.state[initial.Name] = initial
+	}
+	t.mu.Unlock()
+
+	if len(updates) > 0 {
+		t.broadcast(&gnmi.Notification{Timestamp: time.Now().UnixNano(), Update: updates})
+	}
+}
+
+// broadcast queues a notification for every streaming subscriber, dropping
+// it for subscribers that are not keeping up
+func (t *Target) broadcast(n *gnmi.Notification) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for ch := range t.subscribers {
+		select {
+		case ch <- n:
+		default:
+			t.logger.Warn().Msg("Subscriber not keeping up, dropping update")
+		}
+	}
+}
+
+// snapshot returns the current oper and admin status of every interface,
+// optionally with descriptions
+func (t *Target) snapshot(withDescription bool) *gnmi.Notification {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	n := &gnmi.Notification{Timestamp: time.Now().UnixNano()}
+	for _, spec := range t.scenario.Interfaces {
+		iface := t.state[spec.Name]
+		n.Update = append(n.Update,
+			leafUpdate(iface.Name, "oper-status", iface.Oper),
+			leafUpdate(iface.Name, "admin-status", iface.Admin),
+		)
+		if withDescription && iface.Description != "" {
+			n.Update = append(n.Update, leafUpdate(iface.Name, "description", iface.Description))
+		}
+	}
+	return n
+}
+
+// Capabilities reports the single model the simulator serves
+func (t *Target) Capabilities(ctx context.Context, req *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	return &gnmi.CapabilityResponse{
+		SupportedModels: []*gnmi.ModelData{
+			{Name: "openconfig-interfaces", Organization: "OpenConfig working group", Version: "2.4.3"},
+		},
+		SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_JSON_IETF, gnmi.Encoding_PROTO},
+		GNMIVersion:        "0.10.0",
+	}, nil
+}
+
+// Get returns the current interface state as scalar leaves regardless of the
+// requested paths
+func (t *Target) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	return &gnmi.GetResponse{Notification: []*gnmi.Notification{t.snapshot(true)}}, nil
+}
+
+// Subscribe serves a subscription. Every mode starts with a full snapshot
+// followed by sync_response.
+func (t *Target) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	list := req.GetSubscribe()
+	if list == nil {
+		return status.Error(codes.InvalidArgument, "first message must be a SubscriptionList")
+	}
+
+	logger := t.logger.With().Str("peer", peerAddress(stream.Context())).Str("mode", list.Mode.String()).Logger()
+	logger.Info().Msg("Subscription opened")
+	defer logger.Info().Msg("Subscription closed")
+
+	if err := t.sendSnapshot(stream); err != nil {
+		return err
+	}
+
+	switch list.Mode {
+	case gnmi.SubscriptionList_ONCE:
+		return nil
+	case gnmi.SubscriptionList_POLL:
+		for {
+			req, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if req.GetPoll() != nil {
+				if err := t.sendSnapshot(stream); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	updates := make(chan *gnmi.Notification, 64)
+	t.mu.Lock()
+	t.subscribers[updates] = struct{}{}
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.subscribers, updates)
+		t.mu.Unlock()
+	}()
+
+	var samples <-chan time.Time
+	if interval := sampleInterval(list, t.scenario.SampleInterval); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		samples = ticker.C
+	}
+
+	for {
+		var n *gnmi.Notification
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case n = <-updates:
+		case <-samples:
+			n = t.snapshot(false)
+		}
+		if err := stream.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: n}}); err != nil {
+			return err
+		}
+	}
+}
+
+// sendSnapshot sends the full state followed by sync_response
+func (t *Target) sendSnapshot(stream gnmi.GNMI_SubscribeServer) error {
+	if err := stream.Send(&gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{Update: t.snapshot(false)},
+	}); err != nil {
+		return err
+	}
+	return stream.Send(&gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true},
+	})
+}
+
+// sampleInterval picks the shortest interval requested by SAMPLE (or
+// TARGET_DEFINED) subscriptions, or zero when only ON_CHANGE was requested
+func sampleInterval(list *gnmi.SubscriptionList, fallback time.Duration) time.Duration {
+	var interval time.Duration
+	for _, sub := range list.Subscription {
+		if sub.Mode == gnmi.SubscriptionMode_ON_CHANGE {
+			continue
+		}
+		d := time.Duration(sub.SampleInterval)
+		if d <= 0 {
+			d = fallback
+		}
+		if interval == 0 || d < interval {
+			interval = d
+		}
+	}
+	return interval
+}
+
+// leafUpdate builds an update for /interfaces/interface[name]/state/<leaf>
+func leafUpdate(ifaceName, leaf, value string) *gnmi.Update {
+	return &gnmi.Update{
+		Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+			{Name: "interfaces"},
+			{Name: "interface", Key: map[string]string{"name": ifaceName}},
+			{Name: "state"},
+			{Name: leaf},
+		}},
+		Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: value}},
+	}
+}
+
+// peerAddress returns the remote address of an RPC for logging
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// sleepUntil waits until deadline, returning false if ctx is cancelled first
+func sleepUntil(ctx context.Context, deadline time.Time) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,87 @@
+// Package store persists small pieces of runtime state (such as alert
+// deduplication timestamps) to a single JSON file so they survive restarts.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store is a JSON file of named sections. Each section is decoded and
+// encoded independently, so components can share one state file without
+// knowing about each other.
+type Store struct {
+	path     string
+	mu       sync.Mutex
+	sections map[string]json.RawMessage
+}
+
+// Open loads the state file at path. A missing file is not an error; it is
+// created on the first Save.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, sections: make(map[string]json.RawMessage)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s.sections); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Path returns the file the store is persisted to
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load decodes the named section into v. It reports false if the section
+// does not exist.
+func (s *Store) Load(section string, v interface{}) (bool, error) {
+	s.mu.Lock()
+	raw, ok := s.sections[section]
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("decoding %s: %w", section, err)
+	}
+	return true, nil
+}
+
+// Save replaces the named section with v and rewrites the state file. The
+// file is written to a temporary name and renamed so a crash mid-write never
+// leaves it truncated.
+func (s *Store) Save(section string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", section, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sections[section] = raw
+	data, err := json.MarshalIndent(s.sections, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -14,4 +14,22 @@ type Alert struct {
 	ResolvedAt  *time.Time
 	Message     string
 	RelatedState map[string]string
+	Asset       map[string]string // chassis and optic details for tickets, when inventory was read
+	RunbookURL  string
+	DedupKey    string // device|entity|alert_type, stable across re-fires
+	DependencyAffected []string // upstream devices (depends_on) down when it fired
+	Maintenance string // maintenance window suppressing its notifications, while open
+	Silenced    bool // a silence matches it, suppressing its notifications
+	Tickets     []Ticket // opened for it by jira and servicenow channels
+	Acknowledged   bool
+	AcknowledgedAt *time.Time
+	AcknowledgedBy string
+}
+
+// Ticket is an issue or incident opened for an alert in a ticketing system
+type Ticket struct {
+	Channel string // the jira or servicenow channel that opened it
+	ID      string // issue key or incident number, as people refer to it
+	Ref     string // what the API addresses it by, when not the ID (ServiceNow sys_id)
+	URL     string
 }
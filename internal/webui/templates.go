@@ -2,10 +2,22 @@ package webui
 
 import (
 	"html/template"
+	"sync/atomic"
 )
 
+// offline is set in offline mode, when pages must not load anything from
+// outside NetSpec
+var offline atomic.Bool
+
+// SetOffline enables or disables offline mode. Pages then fall back to
+// local system fonts instead of loading web fonts.
+func SetOffline(enabled bool) {
+	offline.Store(enabled)
+}
+
 // Templates contains all HTML templates for the web UI
 var Templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"offline": offline.Load,
 	"levelClass": func(level string) string {
 		switch level {
 		case "error", "fatal":
@@ -26,9 +38,8 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>NetSpec Status</title>
-    <link rel="preconnect" href="https://fonts.googleapis.com">
-    <link rel="preconnect" href="https://fonts.gstatic.com" crossorigin>
-    <link href="https://fonts.googleapis.com/css2?family=JetBrains+Mono:wght@400;500;600&family=Outfit:wght@400;500;600;700&display=swap" rel="stylesheet">
+    {{template "fonts"}}
+    {{template "theme"}}
     <style>
         :root {
             --bg-primary: #0d1117;
@@ -144,6 +155,7 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
             font-weight: 500;
             cursor: pointer;
             transition: all 0.2s ease;
+            text-decoration: none;
         }
 
         .btn-primary {
@@ -214,6 +226,27 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
             list-style: none;
         }
 
+        .dependency-tree, .dependency-tree ul {
+            list-style: none;
+            font-family: 'JetBrains Mono', monospace;
+            font-size: 0.875rem;
+        }
+
+        .dependency-tree ul {
+            margin-left: 0.75rem;
+            padding-left: 1rem;
+            border-left: 1px solid var(--border-color);
+        }
+
+        .dependency-tree li {
+            padding: 0.25rem 0;
+        }
+
+        .dependency-tree a {
+            color: var(--text-primary);
+            text-decoration: none;
+        }
+
         .device-item {
             display: flex;
             justify-content: space-between;
@@ -334,6 +367,36 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
             color: var(--accent-blue);
         }
 
+        .alert-severity.red {
+            background: rgba(248, 81, 73, 0.15);
+            color: var(--accent-red);
+        }
+
+        .alert-severity.orange {
+            background: rgba(219, 109, 40, 0.15);
+            color: #db6d28;
+        }
+
+        .alert-severity.yellow {
+            background: rgba(210, 153, 34, 0.15);
+            color: var(--accent-yellow);
+        }
+
+        .alert-severity.blue {
+            background: rgba(88, 166, 255, 0.15);
+            color: var(--accent-blue);
+        }
+
+        .alert-severity.purple {
+            background: rgba(163, 113, 247, 0.15);
+            color: var(--accent-purple);
+        }
+
+        .alert-severity.gray {
+            background: rgba(139, 148, 158, 0.15);
+            color: var(--text-secondary);
+        }
+
         .alert-content h4 {
             font-size: 0.875rem;
             font-weight: 500;
@@ -462,11 +525,40 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
             setTimeout(() => toast.className = 'toast', 3000);
         }
 
+        function describePreview(data) {
+            const lines = [];
+            const fmt = c => '  ' + c.severity + ' ' + c.device + ' ' + c.interface + ': ' + c.message;
+            if (data.would_fire.length) {
+                lines.push(data.would_fire.length + ' alert(s) would fire:');
+                data.would_fire.slice(0, 10).forEach(c => lines.push(fmt(c)));
+            }
+            if (data.would_resolve.length) {
+                lines.push(data.would_resolve.length + ' alert(s) would resolve:');
+                data.would_resolve.slice(0, 10).forEach(c => lines.push(fmt(c)));
+            }
+            return lines.join('\n');
+        }
+
         async function reloadConfig() {
             const btn = event.target;
             btn.disabled = true;
-            btn.textContent = 'Reloading...';
+            btn.textContent = 'Previewing...';
             try {
+                const preview = await fetch('/api/reload?dry_run=true', { method: 'POST' });
+                const previewData = await preview.json();
+                if (!previewData.success) {
+                    showToast(previewData.error || 'Configuration is invalid', true);
+                    btn.disabled = false;
+                    btn.textContent = '↻ Reload Config';
+                    return;
+                }
+                const summary = describePreview(previewData);
+                if (summary && !confirm(summary + '\n\nApply this configuration?')) {
+                    btn.disabled = false;
+                    btn.textContent = '↻ Reload Config';
+                    return;
+                }
+                btn.textContent = 'Reloading...';
                 const res = await fetch('/api/reload', { method: 'POST' });
                 const data = await res.json();
                 if (res.ok) {
@@ -502,12 +594,70 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                 });
         }, 5000);
 
+        async function savePreferences() {
+            try {
+                const prefs = await loadPreferences();
+                prefs.theme = document.getElementById('pref-theme').value;
+                prefs.site_filter = document.getElementById('pref-site').value;
+                prefs.alert_sound = document.getElementById('pref-sound').checked;
+                await putPreferences(prefs);
+                showToast('Preferences saved');
+                setTimeout(() => window.location.href = '/', 500);
+            } catch (e) {
+                showToast(e.message, true);
+            }
+        }
+
+        async function toggleFavorite(device) {
+            try {
+                const prefs = await loadPreferences();
+                const favorites = prefs.favorite_devices || [];
+                const i = favorites.indexOf(device);
+                if (i >= 0) favorites.splice(i, 1); else favorites.push(device);
+                prefs.favorite_devices = favorites;
+                await putPreferences(prefs);
+                location.reload();
+            } catch (e) {
+                showToast(e.message, true);
+            }
+        }
+
+        // With alert sound on, chime when a new alert fires while the
+        // dashboard is open
+        let knownAlerts = null;
+        function chime() {
+            try {
+                const ctx = new (window.AudioContext || window.webkitAudioContext)();
+                const osc = ctx.createOscillator();
+                const gain = ctx.createGain();
+                osc.frequency.value = 880;
+                gain.gain.setValueAtTime(0.2, ctx.currentTime);
+                gain.gain.exponentialRampToValueAtTime(0.001, ctx.currentTime + 0.6);
+                osc.connect(gain).connect(ctx.destination);
+                osc.start();
+                osc.stop(ctx.currentTime + 0.6);
+            } catch (e) {}
+        }
+        if (typeof alertSound !== 'undefined' && alertSound) {
+            setInterval(() => {
+                fetch('/alerts')
+                    .then(r => r.json())
+                    .then(data => {
+                        const ids = new Set((data.alerts || []).map(a => a.ID));
+                        if (knownAlerts && [...ids].some(id => !knownAlerts.has(id))) chime();
+                        knownAlerts = ids;
+                    })
+                    .catch(() => {});
+            }, 15000);
+        }
+
         function escapeHtml(text) {
             const div = document.createElement('div');
             div.textContent = text;
             return div.innerHTML;
         }
     </script>
+    {{template "palette"}}
 </body>
 </html>
 {{end}}
@@ -528,6 +678,10 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                     <span class="status-dot"></span>
                     Running
                 </div>
+                <a href="/deviations" class="btn btn-secondary">⚠ Deviations</a>
+                <a href="/capacity" class="btn btn-secondary">📈 Capacity</a>
+                <a href="/alerts" class="btn btn-secondary">🕑 Alert history</a>
+                <span style="font-size: 0.75rem; color: var(--text-muted);" title="Command palette">Ctrl+K</span>
                 <button class="btn btn-primary" onclick="reloadConfig()">↻ Reload Config</button>
             </div>
         </header>
@@ -551,10 +705,86 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
             </div>
         </div>
 
+        {{if .Watching}}
+        <div class="card" style="margin-bottom: 1.5rem;">
+            <div class="card-header">
+                <span class="card-title">📌 Watching</span>
+                <span style="font-size: 0.8125rem; color: var(--text-secondary);">Pin devices and interfaces from their device page</span>
+            </div>
+            <div class="card-body no-padding">
+                <ul class="device-list">
+                    {{range .Watching}}
+                    <li class="device-item" onclick="window.location.href='/device/{{.Device}}'" style="cursor: pointer;">
+                        <div class="device-info">
+                            <h3>{{.Device}}{{if .Interface}} - {{.Interface}}{{end}}</h3>
+                            <div class="device-meta">
+                                {{if .Missing}}
+                                <span>No longer in the configuration</span>
+                                {{else}}
+                                <span>{{if .Connected}}Connected{{else}}Disconnected{{end}}</span>
+                                {{if .Interface}}
+                                <span>Desired: {{.DesiredState}}</span>
+                                <span>Actual: {{if .ActualOper}}{{.ActualOper}}{{else}}unknown{{end}}</span>
+                                {{if .DeviatedSince}}<span>Deviating since {{.DeviatedSince.Format "15:04:05"}}</span>{{end}}
+                                {{else}}
+                                <span>{{.Deviations}} deviating</span>
+                                {{end}}
+                                {{if not .LastUpdate.IsZero}}<span>Updated {{.LastUpdate.Format "15:04:05"}}</span>{{end}}
+                                {{end}}
+                            </div>
+                        </div>
+                        {{if .AlertCount}}
+                        <span class="alert-severity {{.SeverityColor}}">{{.AlertCount}} {{.Severity}}</span>
+                        {{else if .Deviating}}
+                        <span class="interface-count" style="color: var(--accent-yellow);">deviating</span>
+                        {{else if not .Missing}}
+                        <span class="interface-count" style="color: var(--accent-green);">OK</span>
+                        {{end}}
+                        <button class="btn btn-secondary" onclick="event.stopPropagation(); toggleWatch('{{.Device}}', '{{.Interface}}')" title="Unpin" style="margin-left: 0.5rem; padding: 0.25rem 0.5rem;">✕</button>
+                    </li>
+                    {{end}}
+                </ul>
+            </div>
+        </div>
+        {{end}}
+
+        {{if .Silences}}
+        <div class="card" style="margin-bottom: 1.5rem;">
+            <div class="card-header">
+                <span class="card-title">🔕 Silences</span>
+                <span style="font-size: 0.8125rem; color: var(--text-secondary);">Matching alerts are tracked without notifications</span>
+            </div>
+            <div class="card-body no-padding">
+                <ul class="device-list">
+                    {{range .Silences}}
+                    <li class="device-item">
+                        <div class="device-info">
+                            <h3>{{if .Device}}{{.Device}}{{else}}Any device{{end}}{{if .Entity}} - {{.Entity}}{{end}}</h3>
+                            <div class="device-meta">
+                                {{if .AlertType}}<span>Type: {{.AlertType}}</span>{{end}}
+                                {{if .Severity}}<span>Severity: {{.Severity}}</span>{{end}}
+                                <span>By {{.CreatedBy}}</span>
+                                <span>Until {{.ExpiresAt.Format "2006-01-02 15:04"}}</span>
+                            </div>
+                        </div>
+                        <button class="btn btn-secondary" onclick="endSilence('{{.ID}}')" title="End silence" style="margin-left: auto; padding: 0.25rem 0.5rem;">End</button>
+                    </li>
+                    {{end}}
+                </ul>
+            </div>
+        </div>
+        {{end}}
+
         <div class="grid">
             <div class="card">
                 <div class="card-header">
                     <span class="card-title">📡 Monitored Devices</span>
+                    {{if .Sites}}
+                    <select onchange="window.location.href='/?site=' + encodeURIComponent(this.value)" style="background: var(--bg-tertiary); color: var(--text-primary); border: 1px solid var(--border-color); border-radius: 6px; padding: 0.25rem 0.5rem;">
+                        <option value=""{{if not .SiteFilter}} selected{{end}}>All sites</option>
+                        {{range .Sites}}<option value="{{.}}"{{if eq . $.SiteFilter}} selected{{end}}>{{.}}</option>{{end}}
+                    </select>
+                    {{end}}
                 </div>
                 <div class="card-body no-padding">
                     {{if .Devices}}
@@ -565,10 +795,12 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                                 <h3>{{.Name}}</h3>
                                 <div class="device-meta">
                                     <span>{{.Address}}</span>
+                                    {{if .Site}}<span>{{.Site}}</span>{{end}}
                                     {{if .Description}}<span>{{.Description}}</span>{{end}}
                                 </div>
                             </div>
                             <span class="interface-count">{{.InterfaceCount}} ifaces</span>
+                            <button class="btn btn-secondary" onclick="event.stopPropagation(); toggleFavorite('{{.Name}}')" title="{{if .Favorite}}Remove from favorites{{else}}Add to favorites{{end}}" style="margin-left: 0.5rem; padding: 0.25rem 0.5rem;{{if .Favorite}} color: var(--accent-yellow);{{end}}">{{if .Favorite}}★{{else}}☆{{end}}</button>
                         </li>
                         {{end}}
                     </ul>
@@ -580,7 +812,7 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                 </div>
             </div>
 
-            <div class="card">
+            <div class="card" id="alerts-card">
                 <div class="card-header">
                     <span class="card-title">🚨 Active Alerts</span>
                 </div>
@@ -589,11 +821,19 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                     <ul class="alert-list">
                         {{range .Alerts}}
                         <li class="alert-item">
-                            <span class="alert-severity {{.Severity}}">{{.Severity}}</span>
+                            <span class="alert-severity {{.SeverityColor}}">{{.Severity}}</span>
                             <div class="alert-content">
-                                <h4>{{.Device}} - {{.Entity}}</h4>
+                                <h4><a href="/alert/{{.ID}}" style="color: inherit; text-decoration: none;">{{.Device}} - {{.Entity}}</a></h4>
                                 <p>{{.Message}}</p>
+                                {{if .Acknowledged}}<p style="color: var(--accent-green);">✔ Acknowledged by {{.AcknowledgedBy}}</p>{{end}}
+                                {{if .DependencyAffected}}<p style="color: var(--accent-yellow);">⛓ Dependency affected: upstream {{range $i, $d := .DependencyAffected}}{{if $i}}, {{end}}{{$d}}{{end}} down</p>{{end}}
+                                {{if .Maintenance}}<p style="color: var(--text-secondary);">🔧 Suppressed by maintenance: {{.Maintenance}}</p>{{end}}
+                                {{if .Silenced}}<p style="color: var(--text-secondary);">🔕 Silenced</p>{{end}}
+                                {{if .Tickets}}<p style="color: var(--text-secondary);">🎫 {{range $i, $t := .Tickets}}{{if $i}}, {{end}}{{if $t.URL}}<a href="{{$t.URL}}" target="_blank" rel="noopener">{{$t.ID}}</a>{{else}}{{$t.ID}}{{end}}{{end}}</p>{{end}}
                             </div>
+                            {{if .RunbookURL}}
+                            <a href="{{.RunbookURL}}" target="_blank" rel="noopener" class="btn btn-secondary" style="margin-left: auto; padding: 0.375rem 0.75rem;">📖 Runbook</a>
+                            {{end}}
                         </li>
                         {{end}}
                     </ul>
@@ -606,6 +846,54 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
             </div>
         </div>
 
+        <div class="card" style="margin-bottom: 1.5rem;">
+            <div class="card-header">
+                <span class="card-title">🔥 Hotspots</span>
+                <div style="display: flex; gap: 0.5rem;">
+                    {{with .Hotspots}}
+                    <a href="/?hotspot=utilization&window={{.Window}}" class="btn btn-secondary" style="padding: 0.375rem 0.75rem;{{if eq .Metric "utilization"}} border-color: var(--accent-blue);{{end}}">Busiest</a>
+                    <a href="/?hotspot=errors&window={{.Window}}" class="btn btn-secondary" style="padding: 0.375rem 0.75rem;{{if eq .Metric "errors"}} border-color: var(--accent-blue);{{end}}">Errors</a>
+                    <a href="/?hotspot=flaps&window={{.Window}}" class="btn btn-secondary" style="padding: 0.375rem 0.75rem;{{if eq .Metric "flaps"}} border-color: var(--accent-blue);{{end}}">Flaps</a>
+                    {{range .Windows}}
+                    <a href="/?hotspot={{$.Hotspots.Metric}}&window={{.}}" class="btn btn-secondary" style="padding: 0.375rem 0.75rem;{{if eq . $.Hotspots.Window}} border-color: var(--accent-blue);{{end}}">{{.}}</a>
+                    {{end}}
+                    {{end}}
+                </div>
+            </div>
+            <div class="card-body no-padding">
+                {{if .Hotspots.Entries}}
+                <ul class="device-list">
+                    {{range .Hotspots.Entries}}
+                    <li class="device-item" onclick="window.location.href='/device/{{.Device}}'" style="cursor: pointer;">
+                        <div class="device-info">
+                            <h3>{{.Device}} - {{.Interface}}</h3>
+                        </div>
+                        <span class="interface-count">{{.Display}}</span>
+                    </li>
+                    {{end}}
+                </ul>
+                {{else}}
+                <div class="empty-state">
+                    <p>No interface activity in the last {{.Hotspots.Window}}</p>
+                </div>
+                {{end}}
+            </div>
+        </div>
+
+        {{if .Dependencies}}
+        <div class="card" style="margin-bottom: 1.5rem;">
+            <div class="card-header">
+                <span class="card-title">🔗 Dependencies</span>
+                <span style="font-size: 0.8125rem; color: var(--text-secondary);">Devices under an upstream declare it in depends_on</span>
+            </div>
+            <div class="card-body">
+                <ul class="dependency-tree">
+                    {{range .Dependencies}}{{template "dependency-node" .}}{{end}}
+                </ul>
+            </div>
+        </div>
+        {{end}}
+
         <div class="grid">
             <div class="card">
                 <div class="card-header">
@@ -651,6 +939,40 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                 </div>
             </div>
 
+            <div class="card">
+                <div class="card-header">
+                    <span class="card-title">👤 Preferences · {{.User}}</span>
+                    <button class="btn btn-primary" onclick="savePreferences()">Save</button>
+                </div>
+                <div class="card-body">
+                    <div class="config-details">
+                        <div class="config-row">
+                            <span class="config-key">Theme</span>
+                            <select id="pref-theme" class="config-value">
+                                <option value="dark"{{if ne .Preferences.Theme "light"}} selected{{end}}>Dark</option>
+                                <option value="light"{{if eq .Preferences.Theme "light"}} selected{{end}}>Light</option>
+                            </select>
+                        </div>
+                        <div class="config-row">
+                            <span class="config-key">Default Site</span>
+                            <select id="pref-site" class="config-value">
+                                <option value="">All sites</option>
+                                {{range .Sites}}<option value="{{.}}"{{if eq . $.Preferences.SiteFilter}} selected{{end}}>{{.}}</option>{{end}}
+                            </select>
+                        </div>
+                        <div class="config-row">
+                            <span class="config-key">Alert Sound</span>
+                            <input id="pref-sound" type="checkbox"{{if .Preferences.AlertSound}} checked{{end}}>
+                        </div>
+                        <div class="config-row">
+                            <span class="config-key">Favorites</span>
+                            <span class="config-value">{{if .Preferences.FavoriteDevices}}{{range $i, $d := .Preferences.FavoriteDevices}}{{if $i}}, {{end}}{{$d}}{{end}}{{else}}none{{end}}</span>
+                        </div>
+                    </div>
+                </div>
+            </div>
+            <script>const alertSound = {{.Preferences.AlertSound}};</script>
+
             <div class="card">
                 <div class="card-header">
                     <span class="card-title">📋 Recent Logs</span>
@@ -671,6 +993,14 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
         </div>
 {{end}}
 
+{{define "dependency-node"}}
+<li>
+    <a href="/device/{{.Device}}">{{.Device}}</a>
+    {{if .Down}}<span style="color: var(--accent-red);">down</span>{{else if .Affected}}<span style="color: var(--accent-yellow);">dependency affected</span>{{end}}
+    {{if .Children}}<ul>{{range .Children}}{{template "dependency-node" .}}{{end}}</ul>{{end}}
+</li>
+{{end}}
+
 {{define "device"}}
 <!DOCTYPE html>
 <html lang="en">
@@ -678,9 +1008,8 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{.Device.Name}} - NetSpec</title>
-    <link rel="preconnect" href="https://fonts.googleapis.com">
-    <link rel="preconnect" href="https://fonts.gstatic.com" crossorigin>
-    <link href="https://fonts.googleapis.com/css2?family=JetBrains+Mono:wght@400;500;600&family=Outfit:wght@400;500;600;700&display=swap" rel="stylesheet">
+    {{template "fonts"}}
+    {{template "theme"}}
     <style>
         :root {
             --bg-primary: #0d1117;
@@ -961,7 +1290,9 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                     </div>
                 </div>
             </div>
-            <div>
+            <div style="display: flex; gap: 0.5rem;">
+                <a href="/device/{{.Device.Name}}/report" class="btn btn-secondary" target="_blank" rel="noopener">📄 Export report</a>
+                <button class="btn btn-secondary" onclick="toggleWatch('{{.Device.Name}}', '')">{{if .Preferences.IsWatching .Device.Name ""}}📌 Unpin{{else}}📌 Pin to Watching{{end}}</button>
                 <a href="/" class="btn btn-secondary">← Back to Dashboard</a>
             </div>
         </header>
@@ -970,9 +1301,9 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
             <div class="card-header">
                 <span class="card-title">📡 Connection Status</span>
                 <div style="display: flex; gap: 0.75rem; align-items: center;">
-                    <span class="status-badge {{if .Device.Connected}}connected{{else}}disconnected{{end}}">
+                    <span id="live-connection" class="status-badge {{if .Device.Connected}}connected{{else}}disconnected{{end}}">
                         <span class="status-dot {{if .Device.Connected}}connected{{else}}disconnected{{end}}"></span>
-                        {{if .Device.Connected}}Connected{{else}}Disconnected{{end}}
+                        <span class="live-label">{{if .Device.Connected}}Connected{{else}}Disconnected{{end}}</span>
                     </span>
                     <button class="btn btn-secondary" onclick="testConnection()" id="test-btn">🔍 Test Connection</button>
                 </div>
@@ -985,21 +1316,67 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                     </div>
                     <div class="info-item">
                         <span class="info-label">Connected Since</span>
-                        <span class="info-value">
+                        <span class="info-value" id="live-connected-since">
                             {{if .Device.ConnectedSince.IsZero}}Never{{else}}{{.Device.ConnectedSince.Format "2006-01-02 15:04:05"}}{{end}}
                         </span>
                     </div>
                     <div class="info-item">
                         <span class="info-label">Reconnect Count</span>
-                        <span class="info-value">{{.Device.ReconnectCount}}</span>
+                        <span class="info-value" id="live-reconnect-count">{{.Device.ReconnectCount}}</span>
+                    </div>
+                    <div class="info-item">
+                        <span class="info-label">gNMI Sessions</span>
+                        <span class="info-value" title="Open now, and the most held at once since the collector started{{if .Device.SessionLimit}}, against the limit of the device's profile{{end}}"{{if .Device.SessionsHigh}} style="color: var(--accent-yellow);"{{end}}>
+                            <span id="live-sessions">{{.Device.Sessions}}</span> open, peak <span id="live-peak-sessions">{{.Device.PeakSessions}}</span>{{if .Device.SessionLimit}} of {{.Device.SessionLimit}}{{end}}
+                        </span>
                     </div>
+                    <div class="info-item">
+                        <span class="info-label">Collection</span>
+                        <span class="info-value">{{if .Device.PollInterval}}gNMI Get every {{.Device.PollInterval}}{{else}}gNMI Subscribe{{end}}</span>
+                    </div>
+                    {{with .Device.Capabilities}}
+                    <div class="info-item">
+                        <span class="info-label">gNMI Capabilities</span>
+                        <span class="info-value" title="Encodings {{range $i, $enc := .Encodings}}{{if $i}}, {{end}}{{$enc}}{{end}}, read {{.CollectedAt.Format "2006-01-02 15:04:05"}}">gNMI {{.GNMIVersion}} · {{len .Models}} models</span>
+                    </div>
+                    {{end}}
+                    {{with .Device.CapabilityChange}}
+                    <div class="info-item">
+                        <span class="info-label">Capabilities Changed</span>
+                        <span class="info-value" title="{{.Summary}}" style="color: var(--accent-yellow);">{{.At.Format "2006-01-02 15:04"}}: {{.Summary}}</span>
+                    </div>
+                    {{end}}
+                    <div class="info-item">
+                        <span class="info-label">Stream Skew</span>
+                        <span class="info-value" title="Receive time minus notification timestamp, averaged over recent notifications"{{if .Device.StreamSkewHigh}} style="color: var(--accent-yellow);"{{end}}>
+                            {{if .Device.StreamSkewMeasured}}{{.Device.StreamSkew}}{{else}}Not measured{{end}}
+                        </span>
+                    </div>
+                    {{if not .Device.CertNotAfter.IsZero}}
+                    <div class="info-item">
+                        <span class="info-label">TLS Certificate Expires</span>
+                        <span class="info-value" title="{{.Device.CertSubject}}">{{.Device.CertNotAfter.Format "2006-01-02"}}</span>
+                    </div>
+                    {{end}}
                 </div>
-                {{if .Device.LastError}}
-                <div style="margin-top: 1rem; padding: 0.75rem; background: rgba(248, 81, 73, 0.1); border-left: 3px solid var(--accent-red); border-radius: 4px;">
-                    <strong style="color: var(--accent-red);">Last Error:</strong>
-                    <span style="color: var(--text-secondary); margin-left: 0.5rem;">{{.Device.LastError}}</span>
+                {{if .Device.MgmtChecks}}
+                <div style="margin-top: 1rem; display: flex; gap: 0.5rem; flex-wrap: wrap;">
+                    {{range .Device.MgmtChecks}}
+                    <span class="status-badge {{if .OK}}connected{{else}}disconnected{{end}}" title="{{.Detail}} ({{.CheckedAt.Format "15:04:05"}})">
+                        <span class="status-dot {{if .OK}}connected{{else}}disconnected{{end}}"></span>
+                        {{if eq .Name "dns"}}DNS{{else if eq .Name "ssh"}}SSH{{else}}HTTPS{{end}}
+                    </span>
+                    {{end}}
                 </div>
                 {{end}}
+                <div id="live-error" style="{{if not .Device.LastError}}display: none; {{end}}margin-top: 1rem; padding: 0.75rem; background: rgba(248, 81, 73, 0.1); border-left: 3px solid var(--accent-red); border-radius: 4px;">
+                    <strong style="color: var(--accent-red);">Last Error:</strong>
+                    <span class="live-error-message" style="color: var(--text-secondary); margin-left: 0.5rem;">{{.Device.LastError}}</span>
+                    <div class="live-subscribe-error" style="{{if not .Device.SubscribeError}}display: none; {{end}}margin-top: 0.5rem;">
+                        <strong>Subscription error (<span class="live-subscribe-class">{{.Device.SubscribeError}}</span>):</strong>
+                        <span class="live-subscribe-hint" style="color: var(--text-secondary); margin-left: 0.5rem;">{{.Device.SubscribeHint}}</span>
+                    </div>
+                </div>
                 <div id="test-result" style="display: none; margin-top: 1rem; padding: 0.75rem; border-left: 3px solid var(--accent-blue); border-radius: 4px;"></div>
             </div>
         </div>
@@ -1007,7 +1384,7 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
         <div class="card">
             <div class="card-header">
                 <span class="card-title">📊 Subscription Status</span>
-                <span class="status-badge {{if .Device.SyncReceived}}connected{{else}}disconnected{{end}}">
+                <span id="live-sync" class="status-badge {{if .Device.SyncReceived}}connected{{else}}disconnected{{end}}">
                     {{if .Device.SyncReceived}}Synced{{else}}Waiting{{end}}
                 </span>
             </div>
@@ -1015,52 +1392,94 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                 <div class="info-grid">
                     <div class="info-item">
                         <span class="info-label">Updates Received</span>
-                        <span class="info-value" style="{{if gt .Device.UpdateCount 0}}color: var(--accent-green);{{else}}color: var(--accent-yellow);{{end}}">{{.Device.UpdateCount}}</span>
+                        <span class="info-value" id="live-update-count" style="{{if gt .Device.UpdateCount 0}}color: var(--accent-green);{{else}}color: var(--accent-yellow);{{end}}">{{.Device.UpdateCount}}</span>
                     </div>
                     <div class="info-item">
                         <span class="info-label">Last Update</span>
-                        <span class="info-value">
+                        <span class="info-value" id="live-last-update">
                             {{if .Device.LastUpdate.IsZero}}Never{{else}}{{.Device.LastUpdate.Format "2006-01-02 15:04:05"}}{{end}}
                         </span>
                     </div>
                     <div class="info-item">
                         <span class="info-label">Sync Received</span>
-                        <span class="info-value">{{if .Device.SyncReceived}}Yes{{else}}No{{end}}</span>
+                        <span class="info-value" id="live-sync-received">{{if .Device.SyncReceived}}Yes{{else}}No{{end}}</span>
                     </div>
                 </div>
-                {{if .Device.LastPath}}
-                <div style="margin-top: 1rem; padding: 0.75rem; background: var(--bg-primary); border-radius: 4px; font-family: 'JetBrains Mono', monospace; font-size: 0.8125rem;">
+                <div id="live-last-path" style="{{if not .Device.LastPath}}display: none; {{end}}margin-top: 1rem; padding: 0.75rem; background: var(--bg-primary); border-radius: 4px; font-family: 'JetBrains Mono', monospace; font-size: 0.8125rem;">
                     <div style="color: var(--text-secondary); margin-bottom: 0.25rem;">Last received path:</div>
-                    <div style="color: var(--accent-blue);">{{.Device.LastPath}}</div>
-                    <div style="color: var(--accent-green); margin-top: 0.25rem;">= {{.Device.LastValue}}</div>
+                    <div class="live-path" style="color: var(--accent-blue);">{{.Device.LastPath}}</div>
+                    <div style="color: var(--accent-green); margin-top: 0.25rem;">= <span class="live-value">{{.Device.LastValue}}</span></div>
                 </div>
-                {{else}}
-                <div style="margin-top: 1rem; padding: 0.75rem; background: rgba(210, 153, 34, 0.1); border-left: 3px solid var(--accent-yellow); border-radius: 4px; color: var(--text-secondary);">
+                <div id="live-no-updates" style="{{if .Device.LastPath}}display: none; {{end}}margin-top: 1rem; padding: 0.75rem; background: rgba(210, 153, 34, 0.1); border-left: 3px solid var(--accent-yellow); border-radius: 4px; color: var(--text-secondary);">
                     No gNMI updates received yet. If the connection is established, interface state changes will appear here.
                 </div>
+            </div>
+        </div>
+
+        {{with .Device.Inventory}}
+        <div class="card">
+            <div class="card-header">
+                <span class="card-title">🧾 Inventory</span>
+                <span style="font-size: 0.8125rem; color: var(--text-secondary);">read {{.CollectedAt.Format "2006-01-02 15:04:05"}}</span>
+            </div>
+            <div class="card-body">
+                <div class="info-grid">
+                    {{range .Chassis}}
+                    <div class="info-item">
+                        <span class="info-label">Chassis{{if .Manufacturer}} ({{.Manufacturer}}){{end}}</span>
+                        <span class="info-value">{{if .Model}}{{.Model}}{{else}}{{.Name}}{{end}}{{if .Serial}} · S/N {{.Serial}}{{end}}</span>
+                    </div>
+                    {{end}}
+                    {{if .SoftwareVersion}}
+                    <div class="info-item">
+                        <span class="info-label">Software Version</span>
+                        <span class="info-value">{{.SoftwareVersion}}</span>
+                    </div>
+                    {{end}}
+                </div>
+                {{if .Modules}}
+                <div style="margin-top: 1rem; font-family: 'JetBrains Mono', monospace; font-size: 0.8125rem;">
+                    {{range .Modules}}
+                    <div style="padding: 0.25rem 0; border-bottom: 1px solid var(--border-color);">
+                        <span style="color: var(--accent-blue);">{{.Name}}</span>
+                        <span style="color: var(--text-muted); margin-left: 0.5rem;">{{.Type}}</span>
+                        {{if .Model}}<span style="color: var(--text-secondary); margin-left: 0.5rem;">{{.Model}}</span>{{end}}
+                        {{if .Serial}}<span style="color: var(--text-secondary); margin-left: 0.5rem;">S/N {{.Serial}}</span>{{end}}
+                    </div>
+                    {{end}}
+                </div>
                 {{end}}
             </div>
         </div>
+        {{end}}
 
         <div class="card">
             <div class="card-header">
                 <span class="card-title">🔌 Monitored Interfaces</span>
-                <span style="font-size: 0.8125rem; color: var(--text-secondary);">{{len .Device.Interfaces}} interfaces</span>
+                <span style="font-size: 0.8125rem; color: var(--text-secondary);">{{len .Device.Interfaces}} interfaces<span id="live-deviating"></span></span>
             </div>
             <div class="card-body" style="padding: 0;">
                 {{if .Device.Interfaces}}
                 <ul class="interface-list">
                     {{range .Device.Interfaces}}
-                    <li class="interface-item">
+                    <li class="interface-item" id="iface-{{.Name}}">
                         <div class="interface-info">
                             <h4>{{.Name}}</h4>
                             <div class="interface-meta">
                                 {{if .Description}}<span>{{.Description}}</span>{{end}}
+                                {{if .Role}}<span>Role: {{.Role}}</span>{{end}}
                                 <span>Desired: {{.DesiredState}}</span>
                                 <span>Admin: {{.AdminState}}</span>
+                                <span class="live-actual">{{if .ActualOper}}Actual: {{.ActualOper}}{{if .ActualAdmin}} / {{.ActualAdmin}}{{end}}{{end}}</span>
+                                {{with .Optic}}<span title="{{.Component}}">Optic: {{.Vendor}} {{.PartNumber}}{{if .FormFactor}} {{.FormFactor}}{{end}}{{if .Wavelength}} {{.Wavelength}} nm{{end}}{{if .Serial}} (S/N {{.Serial}}){{end}}</span>{{end}}
                             </div>
                         </div>
-                        <span class="interface-state {{.DesiredState}}">{{.DesiredState}}</span>
+                        <div style="display: flex; gap: 0.75rem; align-items: center;">
+                            <button class="btn btn-secondary" onclick="toggleWatch('{{$.Device.Name}}', '{{.Name}}')" title="{{if $.Preferences.IsWatching $.Device.Name .Name}}Unpin from{{else}}Pin to{{end}} the dashboard's Watching card" style="padding: 0.25rem 0.5rem;{{if $.Preferences.IsWatching $.Device.Name .Name}} border-color: var(--accent-blue);{{end}}">📌</button>
+                            <button class="btn btn-secondary live-deviating" onclick="adoptInterface('{{.Name}}', this)" title="Update desired state in config to match the observed state"{{if not .Deviating}} style="display: none;"{{end}}>⤓ Adopt current state</button>
+                            <span class="interface-state down live-deviating"{{if not .Deviating}} style="display: none;"{{end}}>deviating</span>
+                            <span class="interface-state {{.DesiredState}}">{{.DesiredState}}</span>
+                        </div>
                     </li>
                     {{end}}
                 </ul>
@@ -1095,7 +1514,7 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
         </div>
     </div>
     <script>
-        // Auto-refresh device data every 5 seconds
+        // Refresh device logs every 5 seconds
         setInterval(() => {
             fetch('/api/devices/{{.Device.Name}}')
                 .then(r => r.json())
@@ -1113,20 +1532,104 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
                         ).join('');
                         if (wasAtBottom) container.scrollTop = container.scrollHeight;
                     }
-
-                    // Update health stats from API response
-                    if (data.health) {
-                        const h = data.health;
-                        // Reload page if connection state changed significantly
-                        // (simpler than updating all DOM elements individually)
-                        const currentConnected = document.querySelector('.status-dot.connected') !== null;
-                        if (h.connected !== currentConnected) {
-                            location.reload();
-                        }
-                    }
                 });
         }, 5000);
 
+        // Refresh connection state, counters and interface compliance from
+        // the live feed every 5 seconds, so a reconnect shows without a reload
+        setInterval(refreshLive, 5000);
+
+        async function refreshLive() {
+            let data;
+            try {
+                const res = await fetch('/api/devices/{{.Device.Name}}/live');
+                if (!res.ok) return;
+                data = await res.json();
+            } catch (e) {
+                return;
+            }
+            const h = data.health;
+
+            const conn = document.getElementById('live-connection');
+            const state = h.connected ? 'connected' : 'disconnected';
+            conn.className = 'status-badge ' + state;
+            conn.querySelector('.status-dot').className = 'status-dot ' + state;
+            conn.querySelector('.live-label').textContent = h.connected ? 'Connected' : 'Disconnected';
+            document.getElementById('live-connected-since').textContent = formatTime(h.connected_since);
+            document.getElementById('live-reconnect-count').textContent = h.reconnect_count;
+            document.getElementById('live-sessions').textContent = h.sessions;
+            document.getElementById('live-peak-sessions').textContent = h.peak_sessions;
+
+            const errBox = document.getElementById('live-error');
+            errBox.style.display = h.last_error ? '' : 'none';
+            errBox.querySelector('.live-error-message').textContent = h.last_error || '';
+            const subErr = errBox.querySelector('.live-subscribe-error');
+            subErr.style.display = h.subscribe_error ? '' : 'none';
+            subErr.querySelector('.live-subscribe-class').textContent = h.subscribe_error || '';
+            subErr.querySelector('.live-subscribe-hint').textContent = h.subscribe_hint || '';
+
+            const sync = document.getElementById('live-sync');
+            sync.className = 'status-badge ' + (h.sync_received ? 'connected' : 'disconnected');
+            sync.textContent = h.sync_received ? 'Synced' : 'Waiting';
+            document.getElementById('live-sync-received').textContent = h.sync_received ? 'Yes' : 'No';
+            const count = document.getElementById('live-update-count');
+            count.textContent = h.update_count;
+            count.style.color = h.update_count > 0 ? 'var(--accent-green)' : 'var(--accent-yellow)';
+            document.getElementById('live-last-update').textContent = formatTime(h.last_update);
+
+            const lastPath = document.getElementById('live-last-path');
+            lastPath.style.display = h.last_path ? '' : 'none';
+            document.getElementById('live-no-updates').style.display = h.last_path ? 'none' : '';
+            lastPath.querySelector('.live-path').textContent = h.last_path || '';
+            lastPath.querySelector('.live-value').textContent = h.last_value || '';
+
+            document.getElementById('live-deviating').textContent = data.deviating > 0 ? ', ' + data.deviating + ' deviating' : '';
+            for (const iface of data.interfaces) {
+                const item = document.getElementById('iface-' + iface.interface);
+                if (!item) continue;
+                let actual = '';
+                if (iface.actual_oper) {
+                    actual = 'Actual: ' + iface.actual_oper + (iface.actual_admin ? ' / ' + iface.actual_admin : '');
+                }
+                item.querySelector('.live-actual').textContent = actual;
+                const deviating = iface.status === 'deviating';
+                item.querySelectorAll('.live-deviating').forEach(el => el.style.display = deviating ? '' : 'none');
+            }
+        }
+
+        // Format an API timestamp like the server-rendered ones
+        function formatTime(ts) {
+            if (!ts) return 'Never';
+            const d = new Date(ts);
+            const pad = n => String(n).padStart(2, '0');
+            return d.getFullYear() + '-' + pad(d.getMonth() + 1) + '-' + pad(d.getDate()) + ' ' +
+                pad(d.getHours()) + ':' + pad(d.getMinutes()) + ':' + pad(d.getSeconds());
+        }
+
+        // Adopt the observed state of an interface as its desired state
+        async function adoptInterface(name, btn) {
+            if (!confirm('Update desired state of ' + name + ' to match its current state on the device?')) {
+                return;
+            }
+            btn.disabled = true;
+            try {
+                const res = await fetch('/api/devices/{{.Device.Name}}/adopt', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({ interface: name })
+                });
+                const data = await res.json();
+                if (data.success) {
+                    location.reload();
+                    return;
+                }
+                alert('Adopt failed: ' + data.error);
+            } catch (e) {
+                alert('Adopt failed: ' + e.message);
+            }
+            btn.disabled = false;
+        }
+
         // Test connection button handler
         async function testConnection() {
             const btn = document.getElementById('test-btn');
@@ -1172,6 +1675,1133 @@ var Templates = template.Must(template.New("").Funcs(template.FuncMap{
             return div.innerHTML;
         }
     </script>
+    {{template "palette"}}
+</body>
+</html>
+{{end}}
+
+{{define "report"}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Device}} - NetSpec device report</title>
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', sans-serif;
+            color: #1f2328;
+            background: #ffffff;
+            margin: 2rem auto;
+            max-width: 1100px;
+            padding: 0 1.5rem;
+            font-size: 0.875rem;
+            line-height: 1.5;
+        }
+        h1 { font-size: 1.5rem; margin: 0 0 0.25rem; }
+        h2 { font-size: 1.0625rem; margin: 2rem 0 0.5rem; border-bottom: 1px solid #d0d7de; padding-bottom: 0.25rem; }
+        table { width: 100%; border-collapse: collapse; }
+        th, td { text-align: left; padding: 0.375rem 0.5rem; border-bottom: 1px solid #eaeef2; vertical-align: top; }
+        th { background: #f6f8fa; font-weight: 600; }
+        .muted { color: #57606a; }
+        .mono { font-family: 'SF Mono', Menlo, Consolas, monospace; font-size: 0.8125rem; }
+        .summary { display: flex; gap: 2rem; flex-wrap: wrap; margin-top: 1rem; }
+        .summary div { min-width: 8rem; }
+        .summary strong { display: block; font-size: 1.25rem; }
+        .compliant { color: #1a7f37; }
+        .deviating { color: #cf222e; font-weight: 600; }
+        .actions { margin-bottom: 1.5rem; display: flex; gap: 0.5rem; }
+        .actions a, .actions button {
+            font: inherit;
+            padding: 0.375rem 0.75rem;
+            border: 1px solid #d0d7de;
+            border-radius: 6px;
+            background: #f6f8fa;
+            color: #1f2328;
+            text-decoration: none;
+            cursor: pointer;
+        }
+        @media print {
+            body { margin: 0; max-width: none; }
+            .actions { display: none; }
+            tr { page-break-inside: avoid; }
+        }
+    </style>
+</head>
+<body>
+    <div class="actions">
+        <button onclick="window.print()">🖨 Print / Save as PDF</button>
+        <a href="/device/{{.Device}}/report?download=1">⤓ Download HTML</a>
+        <a href="/device/{{.Device}}">← Back to device</a>
+    </div>
+
+    <h1>{{.Device}}</h1>
+    <div class="muted">
+        {{.Address}}{{if .Site}} · {{.Site}}{{end}}{{if .Description}} · {{.Description}}{{end}}
+    </div>
+    <div class="muted">
+        Generated {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}} by {{.GeneratedBy}} · NetSpec {{if .Version}}{{.Version}}{{else}}dev{{end}}{{if and .Commit (ne .Commit "unknown")}} ({{.Commit | printf "%.7s"}}){{end}}
+    </div>
+
+    <div class="summary">
+        <div><span class="muted">Connection</span><strong>{{if .Connected}}Connected{{else}}Disconnected{{end}}</strong></div>
+        <div><span class="muted">Compliance</span><strong>{{if .Compliance}}{{.Compliance}}{{else}}n/a{{end}}</strong></div>
+        <div><span class="muted">Compliant</span><strong class="compliant">{{.Compliant}}</strong></div>
+        <div><span class="muted">Deviating</span><strong{{if .Deviating}} class="deviating"{{end}}>{{.Deviating}}</strong></div>
+        <div><span class="muted">No data</span><strong>{{.NoData}}</strong></div>
+        <div><span class="muted">Open alerts</span><strong>{{len .Alerts}}</strong></div>
+    </div>
+    <p class="muted">
+        {{if not .ConnectedSince.IsZero}}Connected since {{.ConnectedSince.Format "2006-01-02 15:04:05"}}. {{end}}
+        {{if not .LastUpdate.IsZero}}Last update {{.LastUpdate.Format "2006-01-02 15:04:05"}}. {{end}}
+        {{if .LastError}}Last error: {{.LastError}}{{end}}
+    </p>
+
+    <h2>Intent and compliance</h2>
+    {{if .Interfaces}}
+    <table>
+        <tr>
+            <th>Interface</th>
+            <th>Desired</th>
+            <th>Admin</th>
+            <th>Observed</th>
+            <th>Status</th>
+            <th>Flaps (24h)</th>
+            <th>Members</th>
+        </tr>
+        {{range .Interfaces}}
+        <tr>
+            <td>
+                <span class="mono">{{.Name}}</span>
+                {{if .Description}}<div class="muted">{{.Description}}</div>{{end}}
+                {{if .Role}}<div class="muted">Role: {{.Role}}</div>{{end}}
+            </td>
+            <td>{{.DesiredState}}</td>
+            <td>{{.AdminState}}</td>
+            <td>{{if .ActualOper}}{{.ActualOper}}{{if .ActualAdmin}} / {{.ActualAdmin}}{{end}}{{else}}<span class="muted">-</span>{{end}}</td>
+            <td class="{{if eq .Status "compliant"}}compliant{{else if eq .Status "deviating"}}deviating{{else}}muted{{end}}">
+                {{.Status}}{{with .Since}}<div class="muted">since {{.Format "2006-01-02 15:04"}}</div>{{end}}
+            </td>
+            <td>{{if .Flaps}}{{.Flaps}}{{end}}</td>
+            <td>
+                {{if .Members}}<span class="mono">{{range $i, $m := .Members}}{{if $i}}, {{end}}{{$m}}{{end}}</span>{{end}}
+                {{if .MemberPolicy}}<div class="muted">{{.MemberPolicy}}</div>{{end}}
+            </td>
+        </tr>
+        {{end}}
+    </table>
+    {{else}}
+    <p class="muted">No interfaces declared.</p>
+    {{end}}
+
+    <h2>Open alerts</h2>
+    {{if .Alerts}}
+    <table>
+        <tr>
+            <th>Severity</th>
+            <th>Entity</th>
+            <th>Type</th>
+            <th>Fired</th>
+            <th>Message</th>
+        </tr>
+        {{range .Alerts}}
+        <tr>
+            <td>{{.Severity}}</td>
+            <td class="mono">{{.Entity}}</td>
+            <td>{{.AlertType}}</td>
+            <td>{{.FiredAt.Format "2006-01-02 15:04:05"}}</td>
+            <td>{{.Message}}{{if .AcknowledgedBy}}<div class="muted">Acknowledged by {{.AcknowledgedBy}}</div>{{end}}</td>
+        </tr>
+        {{end}}
+    </table>
+    {{else}}
+    <p class="muted">No open alerts.</p>
+    {{end}}
+    {{if .Silences}}
+    <p class="muted">
+        {{range .Silences}}Silenced by {{.CreatedBy}} until {{.ExpiresAt.Format "2006-01-02 15:04"}}. {{end}}
+    </p>
+    {{end}}
+
+    <h2>Recent history (24h)</h2>
+    {{if .History}}
+    <table>
+        <tr>
+            <th>Time</th>
+            <th>Level</th>
+            <th>Message</th>
+        </tr>
+        {{range .History}}
+        <tr>
+            <td class="mono">{{.Timestamp.Format "2006-01-02 15:04:05"}}</td>
+            <td>{{.Level}}</td>
+            <td>{{.Message}}</td>
+        </tr>
+        {{end}}
+    </table>
+    {{else}}
+    <p class="muted">No events logged for this device in the last 24 hours.</p>
+    {{end}}
+</body>
+</html>
+{{end}}
+
+{{define "palette"}}
+    <div id="palette" class="palette-overlay" onclick="if (event.target === this) closePalette()">
+        <div class="palette">
+            <input id="palette-input" type="text" placeholder="Jump to a device, filter alerts, maintenance, reload… (append e.g. 4h to set a maintenance duration)" autocomplete="off" spellcheck="false">
+            <ul id="palette-results"></ul>
+            <div class="palette-footer">↑↓ to select · Enter to run · Esc to close</div>
+        </div>
+    </div>
+    <style>
+        .palette-overlay {
+            display: none;
+            position: fixed;
+            inset: 0;
+            background: rgba(0, 0, 0, 0.5);
+            z-index: 1000;
+            justify-content: center;
+            align-items: flex-start;
+            padding-top: 12vh;
+        }
+        .palette-overlay.open { display: flex; }
+        .palette {
+            width: min(640px, 90vw);
+            background: var(--bg-secondary);
+            border: 1px solid var(--border-color);
+            border-radius: 12px;
+            box-shadow: 0 16px 48px rgba(0, 0, 0, 0.4);
+            overflow: hidden;
+        }
+        .palette input {
+            width: 100%;
+            padding: 1rem 1.25rem;
+            background: transparent;
+            border: none;
+            border-bottom: 1px solid var(--border-color);
+            color: var(--text-primary);
+            font-size: 1rem;
+            outline: none;
+        }
+        .palette ul {
+            list-style: none;
+            max-height: 50vh;
+            overflow-y: auto;
+            margin: 0;
+            padding: 0.25rem 0;
+        }
+        .palette li {
+            display: flex;
+            justify-content: space-between;
+            gap: 1rem;
+            padding: 0.5rem 1.25rem;
+            cursor: pointer;
+            color: var(--text-primary);
+            font-size: 0.875rem;
+        }
+        .palette li.selected { background: var(--bg-tertiary); }
+        .palette li .hint { color: var(--text-muted); font-size: 0.75rem; }
+        .palette-footer {
+            padding: 0.5rem 1.25rem;
+            border-top: 1px solid var(--border-color);
+            color: var(--text-muted);
+            font-size: 0.75rem;
+        }
+    </style>
+    <script>
+        // Ctrl+K (Cmd+K on macOS) command palette: jump to a device or page,
+        // filter the dashboard's alerts, start or end a device's maintenance
+        // silence and reload the configuration
+        (function () {
+            const overlay = document.getElementById('palette');
+            const input = document.getElementById('palette-input');
+            const results = document.getElementById('palette-results');
+            let devices = [];
+            let silenced = {};
+            let shown = [];
+            let selected = 0;
+
+            function esc(text) {
+                const div = document.createElement('div');
+                div.textContent = text;
+                return div.innerHTML;
+            }
+
+            function notify(message, isError) {
+                if (typeof showToast === 'function') showToast(message, isError);
+                else if (isError) alert(message);
+            }
+
+            async function refresh() {
+                try {
+                    const [devRes, silRes] = await Promise.all([fetch('/api/devices'), fetch('/api/silences')]);
+                    devices = ((await devRes.json()).devices || []).map(d => d.name).sort();
+                    silenced = {};
+                    ((await silRes.json()).silences || []).filter(s => s.Device && !s.Entity && !s.AlertType && !s.Severity).forEach(s => silenced[s.Device] = s.ExpiresAt);
+                } catch (e) {}
+            }
+
+            async function setMaintenance(device, duration) {
+                const res = silenced[device]
+                    ? await fetch('/api/silences?device=' + encodeURIComponent(device), { method: 'DELETE' })
+                    : await fetch('/api/silences', {
+                        method: 'POST',
+                        headers: { 'Content-Type': 'application/json' },
+                        body: JSON.stringify({ device: device, duration: duration })
+                    });
+                const data = await res.json();
+                if (!res.ok) {
+                    notify(data.error || 'Failed to change maintenance', true);
+                    return;
+                }
+                notify(silenced[device] ? 'Maintenance ended on ' + device : 'Alerts on ' + device + ' silenced for ' + duration);
+                await refresh();
+            }
+
+            async function reload() {
+                if (typeof reloadConfig === 'function') {
+                    reloadConfig();
+                    return;
+                }
+                const res = await fetch('/api/reload', { method: 'POST' });
+                const data = await res.json();
+                if (!res.ok || !data.success) {
+                    notify(data.error || 'Failed to reload', true);
+                    return;
+                }
+                location.reload();
+            }
+
+            window.filterAlerts = function (query) {
+                const items = document.querySelectorAll('.alert-list .alert-item');
+                const card = document.getElementById('alerts-card');
+                if (!card) {
+                    window.location.href = '/?alerts=' + encodeURIComponent(query);
+                    return;
+                }
+                const q = query.toLowerCase();
+                items.forEach(item => {
+                    item.style.display = item.textContent.toLowerCase().includes(q) ? '' : 'none';
+                });
+                card.scrollIntoView({ behavior: 'smooth', block: 'center' });
+            };
+
+            // Subsequence match, so "csw01" finds core-sw-01
+            function matches(label, query) {
+                let i = 0;
+                for (const c of label.toLowerCase()) {
+                    if (i < query.length && c === query[i]) i++;
+                }
+                return i === query.length;
+            }
+
+            function commands(query) {
+                let duration = '2h';
+                const m = query.match(/\s(\d+[smhd])$/);
+                if (m) {
+                    duration = m[1];
+                    query = query.slice(0, m.index);
+                }
+                const q = query.trim().toLowerCase().replace(/\s+/g, '');
+                const list = [
+                    { label: 'Dashboard', hint: 'page', run: () => window.location.href = '/' },
+                    { label: 'Deviations', hint: 'page', run: () => window.location.href = '/deviations' },
+                    { label: 'Capacity', hint: 'page', run: () => window.location.href = '/capacity' },
+                    { label: 'Alert history', hint: 'page', run: () => window.location.href = '/alerts' },
+                    { label: 'Reload configuration', hint: 'action', run: reload },
+                    { label: 'Clear alert filter', hint: 'alerts', run: () => filterAlerts('') }
+                ];
+                devices.forEach(d => list.push({ label: 'Go to ' + d, hint: 'device', run: () => window.location.href = '/device/' + encodeURIComponent(d) }));
+                devices.forEach(d => list.push(silenced[d]
+                    ? { label: 'End maintenance on ' + d, hint: 'silenced until ' + new Date(silenced[d]).toLocaleTimeString(), run: () => setMaintenance(d) }
+                    : { label: 'Start maintenance on ' + d, hint: 'silence ' + duration, run: () => setMaintenance(d, duration) }));
+                const found = list.filter(c => matches(c.label.replace(/\s+/g, ''), q));
+                if (query.trim()) {
+                    found.push({ label: 'Filter alerts: ' + query.trim(), hint: 'alerts', run: () => filterAlerts(query.trim()) });
+                }
+                return found.slice(0, 50);
+            }
+
+            function render() {
+                shown = commands(input.value);
+                selected = Math.min(selected, Math.max(shown.length - 1, 0));
+                results.innerHTML = '';
+                shown.forEach((c, i) => {
+                    const li = document.createElement('li');
+                    li.className = i === selected ? 'selected' : '';
+                    li.innerHTML = '<span>' + esc(c.label) + '</span><span class="hint">' + esc(c.hint) + '</span>';
+                    li.onclick = () => run(i);
+                    results.appendChild(li);
+                });
+                const current = results.children[selected];
+                if (current) current.scrollIntoView({ block: 'nearest' });
+            }
+
+            function run(i) {
+                const c = shown[i];
+                closePalette();
+                if (c) c.run();
+            }
+
+            async function openPalette() {
+                overlay.classList.add('open');
+                input.value = '';
+                selected = 0;
+                input.focus();
+                render();
+                await refresh();
+                render();
+            }
+
+            window.closePalette = function () {
+                overlay.classList.remove('open');
+            };
+
+            document.addEventListener('keydown', e => {
+                if ((e.ctrlKey || e.metaKey) && e.key.toLowerCase() === 'k') {
+                    e.preventDefault();
+                    overlay.classList.contains('open') ? closePalette() : openPalette();
+                    return;
+                }
+                if (!overlay.classList.contains('open')) return;
+                if (e.key === 'Escape') {
+                    closePalette();
+                } else if (e.key === 'ArrowDown') {
+                    e.preventDefault();
+                    selected = Math.min(selected + 1, shown.length - 1);
+                    render();
+                } else if (e.key === 'ArrowUp') {
+                    e.preventDefault();
+                    selected = Math.max(selected - 1, 0);
+                    render();
+                } else if (e.key === 'Enter') {
+                    e.preventDefault();
+                    run(selected);
+                }
+            });
+            input.addEventListener('input', () => {
+                selected = 0;
+                render();
+            });
+
+            const initial = new URLSearchParams(window.location.search).get('alerts');
+            if (initial) window.addEventListener('load', () => filterAlerts(initial));
+        })();
+    </script>
+{{end}}
+
+{{define "theme"}}
+    <style>
+        html[data-theme="light"] {
+            --bg-primary: #ffffff;
+            --bg-secondary: #f6f8fa;
+            --bg-tertiary: #eaeef2;
+            --border-color: #d0d7de;
+            --text-primary: #1f2328;
+            --text-secondary: #57606a;
+            --text-muted: #6e7781;
+            --accent-green: #1a7f37;
+            --accent-green-dim: #2da44e;
+            --accent-red: #cf222e;
+            --accent-yellow: #9a6700;
+            --accent-blue: #0969da;
+            --accent-purple: #8250df;
+        }
+    </style>
+    <script>
+        // Apply the last theme seen at once, then the user's saved preference
+        (function () {
+            const cached = localStorage.getItem('netspec-theme');
+            if (cached) document.documentElement.dataset.theme = cached;
+            fetch('/api/preferences').then(r => r.json()).then(data => {
+                const theme = (data.preferences && data.preferences.theme) || 'dark';
+                document.documentElement.dataset.theme = theme;
+                localStorage.setItem('netspec-theme', theme);
+            }).catch(() => {});
+        })();
+
+        async function loadPreferences() {
+            const res = await fetch('/api/preferences');
+            return (await res.json()).preferences;
+        }
+
+        async function putPreferences(prefs) {
+            const res = await fetch('/api/preferences', {
+                method: 'PUT',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(prefs)
+            });
+            const data = await res.json();
+            if (!res.ok) throw new Error(data.error || 'Failed to save preferences');
+            localStorage.setItem('netspec-theme', data.preferences.theme || 'dark');
+            return data.preferences;
+        }
+
+        // Pin a device, or one of its interfaces, to the dashboard's
+        // Watching card, or unpin it
+        async function endSilence(id) {
+            try {
+                const res = await fetch('/api/silences?id=' + encodeURIComponent(id), { method: 'DELETE' });
+                if (!res.ok) throw new Error((await res.json()).error || res.statusText);
+                location.reload();
+            } catch (e) {
+                alert(e.message);
+            }
+        }
+
+        async function toggleWatch(device, iface) {
+            try {
+                const prefs = await loadPreferences();
+                const watching = prefs.watching || [];
+                const i = watching.findIndex(w => w.device === device && (w.interface || '') === (iface || ''));
+                if (i >= 0) watching.splice(i, 1); else watching.push({ device: device, interface: iface || '' });
+                prefs.watching = watching;
+                await putPreferences(prefs);
+                location.reload();
+            } catch (e) {
+                alert(e.message);
+            }
+        }
+    </script>
+{{end}}
+
+{{define "fonts"}}{{if not offline}}
+    <link rel="preconnect" href="https://fonts.googleapis.com">
+    <link rel="preconnect" href="https://fonts.gstatic.com" crossorigin>
+    <link href="https://fonts.googleapis.com/css2?family=JetBrains+Mono:wght@400;500;600&family=Outfit:wght@400;500;600;700&display=swap" rel="stylesheet">
+{{end}}{{end}}
+
+{{define "page-style"}}
+    {{template "fonts"}}
+    {{template "theme"}}
+    <style>
+        :root {
+            --bg-primary: #0d1117;
+            --bg-secondary: #161b22;
+            --bg-tertiary: #21262d;
+            --border-color: #30363d;
+            --text-primary: #e6edf3;
+            --text-secondary: #8b949e;
+            --text-muted: #6e7681;
+            --accent-green: #3fb950;
+            --accent-green-dim: #238636;
+            --accent-red: #f85149;
+            --accent-yellow: #d29922;
+            --accent-blue: #58a6ff;
+            --accent-purple: #a371f7;
+        }
+
+        * {
+            margin: 0;
+            padding: 0;
+            box-sizing: border-box;
+        }
+
+        body {
+            font-family: 'Outfit', -apple-system, BlinkMacSystemFont, sans-serif;
+            background: var(--bg-primary);
+            color: var(--text-primary);
+            line-height: 1.6;
+            min-height: 100vh;
+        }
+
+        a {
+            color: var(--accent-blue);
+        }
+
+        .container {
+            max-width: 1400px;
+            margin: 0 auto;
+            padding: 2rem;
+        }
+
+        header {
+            display: flex;
+            justify-content: space-between;
+            align-items: center;
+            margin-bottom: 2rem;
+            padding-bottom: 1.5rem;
+            border-bottom: 1px solid var(--border-color);
+        }
+
+        .logo {
+            display: flex;
+            align-items: center;
+            gap: 0.75rem;
+        }
+
+        .logo-icon {
+            width: 40px;
+            height: 40px;
+            background: linear-gradient(135deg, var(--accent-green) 0%, var(--accent-blue) 100%);
+            border-radius: 10px;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            font-weight: 700;
+            font-size: 1.2rem;
+        }
+
+        h1 {
+            font-size: 1.75rem;
+            font-weight: 600;
+        }
+
+        .btn {
+            display: inline-flex;
+            align-items: center;
+            gap: 0.5rem;
+            padding: 0.625rem 1.25rem;
+            border: none;
+            border-radius: 8px;
+            font-family: inherit;
+            font-size: 0.875rem;
+            font-weight: 500;
+            cursor: pointer;
+            transition: all 0.2s ease;
+            text-decoration: none;
+        }
+
+        .btn-secondary {
+            background: var(--bg-tertiary);
+            color: var(--text-primary);
+            border: 1px solid var(--border-color);
+        }
+
+        .btn-secondary:hover {
+            background: var(--border-color);
+        }
+
+        .btn-secondary.active {
+            border-color: var(--accent-blue);
+            color: var(--accent-blue);
+        }
+
+        .card {
+            background: var(--bg-secondary);
+            border: 1px solid var(--border-color);
+            border-radius: 12px;
+            overflow: hidden;
+            margin-bottom: 1.5rem;
+        }
+
+        .card-header {
+            display: flex;
+            justify-content: space-between;
+            align-items: center;
+            padding: 1rem 1.25rem;
+            background: var(--bg-tertiary);
+            border-bottom: 1px solid var(--border-color);
+        }
+
+        .card-title {
+            font-size: 1rem;
+            font-weight: 600;
+        }
+
+        .card-body {
+            padding: 1rem 1.25rem;
+        }
+
+        .data-table {
+            width: 100%;
+            border-collapse: collapse;
+            font-size: 0.875rem;
+        }
+
+        .data-table th {
+            text-align: left;
+            padding: 0.75rem 1.25rem;
+            color: var(--text-secondary);
+            font-weight: 500;
+            border-bottom: 1px solid var(--border-color);
+        }
+
+        .data-table td {
+            padding: 0.75rem 1.25rem;
+            border-bottom: 1px solid var(--border-color);
+            vertical-align: top;
+        }
+
+        .data-table tr:last-child td {
+            border-bottom: none;
+        }
+
+        .mono {
+            font-family: 'JetBrains Mono', monospace;
+        }
+
+        .muted {
+            color: var(--text-muted);
+        }
+
+        .state-pill {
+            padding: 0.25rem 0.625rem;
+            border-radius: 4px;
+            font-size: 0.75rem;
+            font-weight: 600;
+            text-transform: uppercase;
+        }
+
+        .state-pill.good, .state-pill.up, .state-pill.enabled {
+            background: rgba(63, 185, 80, 0.15);
+            color: var(--accent-green);
+        }
+
+        .state-pill.bad, .state-pill.down, .state-pill.disabled, .state-pill.critical {
+            background: rgba(248, 81, 73, 0.15);
+            color: var(--accent-red);
+        }
+
+        .state-pill.warning {
+            background: rgba(210, 153, 34, 0.15);
+            color: var(--accent-yellow);
+        }
+
+        .state-pill.info {
+            background: rgba(88, 166, 255, 0.15);
+            color: var(--accent-blue);
+        }
+
+        .empty-state {
+            padding: 3rem 2rem;
+            text-align: center;
+            color: var(--text-muted);
+        }
+    </style>
+{{end}}
+
+{{define "alert"}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Alert.AlertType}} on {{.Alert.Device}} - NetSpec</title>
+    {{template "page-style"}}
+</head>
+<body>
+    <div class="container">
+        <header>
+            <div class="logo">
+                <div class="logo-icon">N</div>
+                <div>
+                    <h1>{{.Alert.AlertType}}</h1>
+                    <div style="font-size: 0.75rem; color: var(--text-muted); margin-top: 0.25rem;">
+                        {{.Alert.Device}} · {{.Alert.Entity}}
+                    </div>
+                </div>
+            </div>
+            <div style="display: flex; gap: 0.75rem;">
+                <a href="/device/{{.Alert.Device}}" class="btn btn-secondary">📡 Device</a>
+                {{if .IsInterface}}<a href="/device/{{.Alert.Device}}#iface-{{.Alert.Entity}}" class="btn btn-secondary">🔌 Interface</a>{{end}}
+                {{if .Alert.RunbookURL}}<a href="{{.Alert.RunbookURL}}" target="_blank" rel="noopener" class="btn btn-secondary">📖 Runbook</a>{{end}}
+                <a href="/" class="btn btn-secondary">← Back to Dashboard</a>
+            </div>
+        </header>
+
+        <div class="card">
+            <div class="card-header">
+                <span class="card-title">🚨 {{.Alert.Message}}</span>
+                <span class="state-pill {{if eq .Alert.State "resolved"}}good{{else}}{{.SeverityColor}} bad{{end}}">{{if eq .Alert.State "resolved"}}resolved{{else}}{{.Alert.Severity}}{{end}}</span>
+            </div>
+            <div class="card-body" style="padding: 0;">
+                <table class="data-table">
+                    <tbody>
+                        <tr><td class="muted">Severity</td><td>{{.Alert.Severity}}</td></tr>
+                        <tr><td class="muted">State</td><td>{{.Alert.State}}</td></tr>
+                        <tr><td class="muted">Fired</td><td class="mono">{{.Alert.FiredAt.Format "2006-01-02 15:04:05"}}</td></tr>
+                        {{with .Alert.ResolvedAt}}<tr><td class="muted">Resolved</td><td class="mono">{{.Format "2006-01-02 15:04:05"}}</td></tr>{{end}}
+                        <tr><td class="muted">{{if .Alert.ResolvedAt}}Lasted{{else}}Firing for{{end}}</td><td class="mono">{{.Duration}}</td></tr>
+                        {{if .Alert.DependencyAffected}}<tr><td class="muted">Dependency affected</td><td>upstream {{range $i, $d := .Alert.DependencyAffected}}{{if $i}}, {{end}}<a href="/device/{{$d}}">{{$d}}</a>{{end}} down when it fired</td></tr>{{end}}
+                        {{if .Alert.Maintenance}}<tr><td class="muted">Maintenance</td><td>suppressed by maintenance: {{.Alert.Maintenance}}</td></tr>{{end}}
+                        {{if .Alert.Silenced}}<tr><td class="muted">Silenced</td><td>notifications suppressed by a silence</td></tr>{{end}}
+                        {{if .Alert.Tickets}}<tr><td class="muted">Tickets</td><td>{{range $i, $t := .Alert.Tickets}}{{if $i}}, {{end}}{{if $t.URL}}<a href="{{$t.URL}}" target="_blank" rel="noopener">{{$t.ID}}</a>{{else}}{{$t.ID}}{{end}} <span class="muted">({{$t.Channel}})</span>{{end}}</td></tr>{{end}}
+                        {{if .Alert.Acknowledged}}<tr><td class="muted">Acknowledged</td><td>by {{.Alert.AcknowledgedBy}}{{with .Alert.AcknowledgedAt}} at <span class="mono">{{.Format "2006-01-02 15:04:05"}}</span>{{end}}</td></tr>{{end}}
+                        {{if .ObservedOper}}<tr><td class="muted">Interface now</td><td><span class="state-pill {{.ObservedOper}}">{{.ObservedOper}}</span>{{if .ObservedAdmin}} <span class="state-pill {{.ObservedAdmin}}">admin {{.ObservedAdmin}}</span>{{end}}</td></tr>{{end}}
+                        <tr><td class="muted">Dedup key</td><td class="mono">{{.Alert.DedupKey}}</td></tr>
+                    </tbody>
+                </table>
+            </div>
+        </div>
+
+        <div class="card">
+            <div class="card-header">
+                <span class="card-title">🔍 Related State</span>
+            </div>
+            <div class="card-body" style="padding: 0;">
+                {{if .Related}}
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th></th>
+                            <th>Expected</th>
+                            <th>Actual</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range .Related}}
+                        <tr>
+                            <td class="muted">{{.Label}}</td>
+                            {{if .Paired}}
+                            <td><span class="state-pill {{.Expected}}">{{.Expected}}</span></td>
+                            <td>{{if .Actual}}<span class="state-pill {{if eq .Actual .Expected}}good{{else}}bad{{end}}">{{.Actual}}</span>{{else}}<span class="muted">unknown</span>{{end}}</td>
+                            {{else if .Members}}
+                            <td colspan="2">{{range .Members}}<span class="state-pill mono">{{.}}</span> {{end}}</td>
+                            {{else if .Time}}
+                            <td colspan="2" class="mono">{{.Time.Format "2006-01-02 15:04:05 MST"}}</td>
+                            {{else}}
+                            <td colspan="2" class="mono">{{.Value}}</td>
+                            {{end}}
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+                {{else}}
+                <div class="empty-state">
+                    <p>No related state was recorded for this alert</p>
+                </div>
+                {{end}}
+            </div>
+        </div>
+
+        <div class="card">
+            <div class="card-header">
+                <span class="card-title">🕓 Timeline</span>
+                <span style="font-size: 0.8125rem; color: var(--text-secondary);">notifications, suppressions and escalations</span>
+            </div>
+            <div class="card-body" style="padding: 0;">
+                {{if .History}}
+                <table class="data-table">
+                    <tbody>
+                        {{range .History}}
+                        <tr>
+                            <td class="mono" style="white-space: nowrap;">{{.At.Format "2006-01-02 15:04:05"}}</td>
+                            <td><span class="state-pill {{if or (eq .Event "fired") (eq .Event "escalated")}}bad{{else if eq .Event "resolved"}}good{{else if or (eq .Event "deduplicated") (eq .Event "suppressed") (eq .Event "silenced")}}warning{{else}}info{{end}}">{{.Event}}</span></td>
+                            <td>{{.Detail}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+                {{else}}
+                <div class="empty-state">
+                    <p>No events recorded</p>
+                </div>
+                {{end}}
+            </div>
+        </div>
+
+        {{if .Asset}}
+        <div class="card">
+            <div class="card-header">
+                <span class="card-title">🏷 Asset</span>
+            </div>
+            <div class="card-body" style="padding: 0;">
+                <table class="data-table">
+                    <tbody>
+                        {{range .Asset}}
+                        <tr><td class="muted">{{.Label}}</td><td class="mono">{{.Value}}</td></tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+        </div>
+        {{end}}
+    </div>
+    {{template "palette"}}
+</body>
+</html>
+{{end}}
+
+{{define "deviations"}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Deviations - NetSpec</title>
+    {{template "page-style"}}
+</head>
+<body>
+    <div class="container">
+        <header>
+            <div class="logo">
+                <div class="logo-icon">N</div>
+                <div>
+                    <h1>Deviations</h1>
+                    <div style="font-size: 0.75rem; color: var(--text-muted); margin-top: 0.25rem;">
+                        {{.Total}} interface{{if ne .Total 1}}s{{end}} out of desired state
+                    </div>
+                </div>
+            </div>
+            <div style="display: flex; gap: 0.75rem;">
+                <a href="/deviations?sort=longest" class="btn btn-secondary{{if eq .Sort "longest"}} active{{end}}">Longest first</a>
+                <a href="/deviations?sort=newest" class="btn btn-secondary{{if eq .Sort "newest"}} active{{end}}">Newest first</a>
+                <a href="/" class="btn btn-secondary">← Back to Dashboard</a>
+            </div>
+        </header>
+
+        {{range .Groups}}
+        <div class="card">
+            <div class="card-header">
+                <span class="card-title">📍 {{.Site}}</span>
+                <span style="font-size: 0.8125rem; color: var(--text-secondary);">{{len .Deviations}} deviating</span>
+            </div>
+            <div class="card-body" style="padding: 0;">
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th>Device</th>
+                            <th>Interface</th>
+                            <th>Oper (desired / actual)</th>
+                            <th>Admin (desired / actual)</th>
+                            <th>Deviating for</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range .Deviations}}
+                        <tr>
+                            <td><a href="/device/{{.Device}}">{{.Device}}</a></td>
+                            <td>
+                                <span class="mono">{{.Interface}}</span>
+                                {{if .Description}}<div class="muted" style="font-size: 0.8125rem;">{{.Description}}</div>{{end}}
+                            </td>
+                            <td>
+                                <span class="state-pill {{.ExpectedOper}}">{{.ExpectedOper}}</span>
+                                {{if .ActualOper}}<span class="state-pill {{if eq .ActualOper .ExpectedOper}}good{{else}}bad{{end}}">{{.ActualOper}}</span>{{else}}<span class="muted">unknown</span>{{end}}
+                            </td>
+                            <td>
+                                {{if .ExpectedAdmin}}
+                                <span class="state-pill {{.ExpectedAdmin}}">{{.ExpectedAdmin}}</span>
+                                {{if .ActualAdmin}}<span class="state-pill {{if eq .ActualAdmin .ExpectedAdmin}}good{{else}}bad{{end}}">{{.ActualAdmin}}</span>{{else}}<span class="muted">unknown</span>{{end}}
+                                {{else}}<span class="muted">not declared</span>{{end}}
+                            </td>
+                            <td class="mono" title="{{.Since.Format "2006-01-02 15:04:05"}}">{{.Duration}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+        </div>
+        {{else}}
+        <div class="card">
+            <div class="empty-state">
+                <p>✓ Every observed interface matches its desired state</p>
+            </div>
+        </div>
+        {{end}}
+    </div>
+    {{template "palette"}}
+</body>
+</html>
+{{end}}
+{{define "capacity"}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Capacity - NetSpec</title>
+    {{template "page-style"}}
+</head>
+<body>
+    <div class="container">
+        <header>
+            <div class="logo">
+                <div class="logo-icon">N</div>
+                <div>
+                    <h1>Capacity</h1>
+                    <div style="font-size: 0.75rem; color: var(--text-muted); margin-top: 0.25rem;">
+                        {{.Advisories}} uplink direction{{if ne .Advisories 1}}s{{end}} projected to exceed threshold
+                    </div>
+                </div>
+            </div>
+            <div style="display: flex; gap: 0.75rem;">
+                <a href="/" class="btn btn-secondary">← Back to Dashboard</a>
+            </div>
+        </header>
+
+        <div class="card">
+            <div class="card-header">
+                <span class="card-title">📈 Uplink trends</span>
+                <span style="font-size: 0.8125rem; color: var(--text-secondary);">daily peak hourly utilization, linear fit</span>
+            </div>
+            {{if .Forecasts}}
+            <div class="card-body" style="padding: 0;">
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th>Device</th>
+                            <th>Interface</th>
+                            <th>Direction</th>
+                            <th>Daily peak</th>
+                            <th>Trend</th>
+                            <th>Threshold</th>
+                            <th>Projected</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range .Forecasts}}
+                        <tr>
+                            <td><a href="/device/{{.Device}}">{{.Device}}</a></td>
+                            <td class="mono">{{.Interface}}</td>
+                            <td>{{.Direction}}</td>
+                            <td class="mono">{{if .Days}}{{printf "%.1f" .PeakPercent}}%{{else}}<span class="muted">—</span>{{end}}</td>
+                            <td class="mono">{{if .Ready}}{{printf "%+.2f" .TrendPercent}}%/day{{else}}<span class="muted">—</span>{{end}}</td>
+                            <td class="mono">{{printf "%.0f" .ThresholdPercent}}%</td>
+                            <td>{{if .Advisory}}<span class="state-pill warning">{{.ETA}}</span>{{else}}<span class="muted">{{.ETA}}</span>{{end}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+            {{else}}
+            <div class="empty-state">
+                <p>No uplinks declare a capacity block</p>
+            </div>
+            {{end}}
+        </div>
+    </div>
+    {{template "palette"}}
+</body>
+</html>
+{{end}}
+{{define "alert-history"}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Alert History - NetSpec</title>
+    {{template "page-style"}}
+    <style>
+        .history-filters {
+            display: flex;
+            flex-wrap: wrap;
+            gap: 0.75rem;
+            align-items: flex-end;
+        }
+
+        .history-filters label {
+            display: flex;
+            flex-direction: column;
+            gap: 0.25rem;
+            font-size: 0.75rem;
+            color: var(--text-muted);
+        }
+
+        .history-filters select, .history-filters input {
+            background: var(--bg-tertiary);
+            color: var(--text-primary);
+            border: 1px solid var(--border-color);
+            border-radius: 6px;
+            padding: 0.375rem 0.5rem;
+            font-size: 0.8125rem;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <header>
+            <div class="logo">
+                <div class="logo-icon">N</div>
+                <div>
+                    <h1>Alert History</h1>
+                    <div style="font-size: 0.75rem; color: var(--text-muted); margin-top: 0.25rem;">
+                        {{.Fired}} fired · {{.Resolved}} resolved{{if .MeanTTR}} · mean time to resolve {{.MeanTTR}}{{end}}
+                    </div>
+                </div>
+            </div>
+            <div style="display: flex; gap: 0.75rem;">
+                <a href="/" class="btn btn-secondary">← Back to Dashboard</a>
+            </div>
+        </header>
+
+        <div class="card">
+            <div class="card-body">
+                <form method="get" action="/alerts" class="history-filters">
+                    <label>Device
+                        <select name="device">
+                            <option value="">All devices</option>
+                            {{range .Devices}}<option value="{{.}}"{{if eq . $.Device}} selected{{end}}>{{.}}</option>{{end}}
+                        </select>
+                    </label>
+                    <label>Severity
+                        <select name="severity">
+                            <option value="">All severities</option>
+                            {{range .Severities}}<option value="{{.}}"{{if eq . $.Severity}} selected{{end}}>{{.}}</option>{{end}}
+                        </select>
+                    </label>
+                    <label>Type
+                        <input type="text" name="alert_type" value="{{.AlertType}}" list="history-types" placeholder="any">
+                        <datalist id="history-types">{{range .AlertTypes}}<option value="{{.}}">{{end}}</datalist>
+                    </label>
+                    <label>Since
+                        <input type="text" name="since" value="{{.Since}}" placeholder="24h or RFC 3339">
+                    </label>
+                    <label>Until
+                        <input type="text" name="until" value="{{.Until}}" placeholder="now">
+                    </label>
+                    <button type="submit" class="btn btn-secondary">Filter</button>
+                </form>
+            </div>
+        </div>
+
+        <div class="card">
+            <div class="card-header">
+                <span class="card-title">🕑 Events</span>
+                <span style="font-size: 0.8125rem; color: var(--text-secondary);">{{len .Rows}} event{{if ne (len .Rows) 1}}s{{end}}, newest first{{if .Truncated}} (limited to the newest 1000){{end}}</span>
+            </div>
+            {{if .Error}}
+            <div class="empty-state">
+                <p>{{.Error}}</p>
+            </div>
+            {{else if .NoStore}}
+            <div class="empty-state">
+                <p>Alert history is not persisted; enable state_persistence to record it</p>
+            </div>
+            {{else if .Rows}}
+            <div class="card-body" style="padding: 0;">
+                <table class="data-table">
+                    <thead>
+                        <tr>
+                            <th>Time</th>
+                            <th>Event</th>
+                            <th>Severity</th>
+                            <th>Device</th>
+                            <th>Alert</th>
+                            <th>Time to resolve</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        {{range .Rows}}
+                        <tr>
+                            <td class="mono">{{.At.Format "2006-01-02 15:04:05"}}</td>
+                            <td>
+                                <span class="state-pill {{.EventClass}}">{{.Event}}</span>
+                                {{if .Detail}}<div class="muted" style="font-size: 0.8125rem;">{{.Detail}}</div>{{end}}
+                            </td>
+                            <td>{{.Severity}}</td>
+                            <td><a href="/device/{{.Device}}">{{.Device}}</a>{{if .Entity}} <span class="mono muted">{{.Entity}}</span>{{end}}</td>
+                            <td>
+                                <a href="/alert/{{.AlertID}}" class="mono">{{.AlertType}}</a>
+                                {{if .Message}}<div class="muted" style="font-size: 0.8125rem;">{{.Message}}</div>{{end}}
+                            </td>
+                            <td class="mono">{{if .Resolved}}{{.TimeToResolve}}{{else if .TimeToResolve}}<span class="state-pill warning">{{.TimeToResolve}}</span>{{else}}<span class="muted">—</span>{{end}}</td>
+                        </tr>
+                        {{end}}
+                    </tbody>
+                </table>
+            </div>
+            {{else}}
+            <div class="empty-state">
+                <p>No alert events match these filters</p>
+            </div>
+            {{end}}
+        </div>
+    </div>
+    {{template "palette"}}
 </body>
 </html>
 {{end}}